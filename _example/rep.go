@@ -28,9 +28,9 @@ func main() {
 	fmt.Printf("Tracker events: %d\n", len(r.TrackerEvts.Evts))
 
 	fmt.Println("Players:")
-	for _, p := range r.Details.Players() {
+	for i, p := range r.Details.Players() {
 		fmt.Printf("\tName: %-20s, Race: %c, Team: %d, Result: %v\n",
-			p.Name, p.Race().Letter, p.TeamID()+1, p.Result())
+			p.Name, p.Race().Letter, r.PlayerTeamID(i)+1, p.Result())
 	}
 	fmt.Printf("Full Header:\n%v\n", r.Header)
 
@@ -2,6 +2,7 @@ package s2prot
 
 import (
 	"bytes"
+	"io"
 	"testing"
 )
 
@@ -216,3 +217,33 @@ func TestReadUnaligned(t *testing.T) {
 		t.Error("Unexpected value!")
 	}
 }
+
+func TestBitReader(t *testing.T) {
+	r := NewBitReader([]byte{0x01, 0x02, 0x03, 0x04, 0x06}, true)
+
+	if !r.BigEndian() || r.Remaining() != 40 {
+		t.Errorf("Unexpected state!")
+	}
+	if !r.ReadBit() || r.ReadBits(7) != 0 {
+		t.Error("Unexpected value!")
+	}
+	if r.ReadUint8() != 2 || r.Remaining() != 24 {
+		t.Error("Unexpected value!")
+	}
+	r.ReadBits(3)
+	if !bytes.Equal([]byte{4}, r.ReadAligned(1)) {
+		t.Error("Unexpected value!")
+	}
+	if r.ReadVarInt() != 3 || !r.EOF() {
+		t.Error("Unexpected value!")
+	}
+
+	func() {
+		defer func() {
+			if rec := recover(); rec != io.ErrUnexpectedEOF {
+				t.Errorf("Expected panic with %v, got: %v", io.ErrUnexpectedEOF, rec)
+			}
+		}()
+		r.ReadBits(1)
+	}()
+}
@@ -0,0 +1,65 @@
+/*
+
+Implementation of a byte buffer which can be written by bits, the inverse of bitPackedBuff.
+
+*/
+
+package s2prot
+
+// bitPackedWriter builds a byte slice by arbitrary number of bits.
+// Bits of a byte are filled from the lowest bit, numbers are written in big endian byte order
+// (as read by bitPackedBuff with bigEndian).
+type bitPackedWriter struct {
+	contents  []byte // Written, complete bytes
+	cache     byte   // Byte being filled
+	cacheBits byte   // Used bits in cache
+}
+
+// byteAlign aligns the buffer to byte boundary: the partially filled byte (if any) is completed with zero bits.
+func (w *bitPackedWriter) byteAlign() {
+	if w.cacheBits > 0 {
+		w.contents = append(w.contents, w.cache)
+		w.cache, w.cacheBits = 0, 0
+	}
+}
+
+// bytes returns the written bytes, including the partially filled last byte.
+func (w *bitPackedWriter) bytes() []byte {
+	w.byteAlign()
+	return w.contents
+}
+
+// writeBits writes the lowest n bits of value, starting with the highest of them.
+func (w *bitPackedWriter) writeBits(value int64, n int) {
+	for n > 0 {
+		k := 8 - int(w.cacheBits) // Free bits in cache
+		if k > n {
+			k = n
+		}
+		chunk := byte(uint64(value)>>uint(n-k)) & bitMasks[k]
+		w.cache |= chunk << w.cacheBits
+		w.cacheBits += byte(k)
+		n -= k
+		if w.cacheBits == 8 {
+			w.contents = append(w.contents, w.cache)
+			w.cache, w.cacheBits = 0, 0
+		}
+	}
+}
+
+// writeAligned first aligns to a byte and writes the bytes.
+func (w *bitPackedWriter) writeAligned(data []byte) {
+	w.byteAlign()
+	w.contents = append(w.contents, data...)
+}
+
+// writeUnaligned writes the bytes (or more precisely len(data)*8 bits).
+func (w *bitPackedWriter) writeUnaligned(data []byte) {
+	if w.cacheBits == 0 {
+		w.contents = append(w.contents, data...)
+		return
+	}
+	for _, b := range data {
+		w.writeBits(int64(b), 8)
+	}
+}
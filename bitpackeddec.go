@@ -10,6 +10,7 @@ package s2prot
 type bitPackedDec struct {
 	*bitPackedBuff            // Data source: bit-packed buffer
 	typeInfos      []typeInfo // Type descriptors
+	strict         bool       // Tells if strict validation mode is on
 }
 
 // newBitPackedDec creates a new bit-packed decoder.
@@ -60,7 +61,10 @@ func (d *bitPackedDec) instance(typeid int) interface{} {
 		return s
 	case s2pChoice:
 		tag := int(readInt())
-		if tag > len(ti.fields) {
+		if tag >= len(ti.fields) {
+			if d.strict {
+				panic(newValidationError(typeid, b, "unknown choice tag %d", tag))
+			}
 			return nil
 		}
 		f := ti.fields[tag]
@@ -99,3 +103,22 @@ func (d *bitPackedDec) instance(typeid int) interface{} {
 
 	return nil
 }
+
+// choiceInt decodes a choice of ints (such as the SVarUint32 of gameloop deltas) specified by its type id,
+// and returns the chosen int value. It is an allocation-free fast path of instance():
+// the value is not wrapped into a Struct.
+// Unknown choice tags and non-int choices are reported with a *ValidationError panic.
+func (d *bitPackedDec) choiceInt(typeid int) int64 {
+	b := d.bitPackedBuff // Local var for efficiency and more compact code
+
+	ti := &d.typeInfos[typeid] // Pointer to avoid copying the struct
+	tag := int(ti.offset64 + b.readBits(byte(ti.bits)))
+	if tag >= len(ti.fields) {
+		panic(newValidationError(typeid, b, "unknown choice tag %d", tag))
+	}
+	fti := &d.typeInfos[ti.fields[tag].typeid]
+	if fti.s2pType != s2pInt {
+		panic(newValidationError(typeid, b, "not an int choice"))
+	}
+	return fti.offset64 + b.readBits(byte(fti.bits))
+}
@@ -0,0 +1,147 @@
+/*
+
+Implementation of the bit-packed encoder, the inverse of the bit-packed decoder.
+
+*/
+
+package s2prot
+
+import "fmt"
+
+// Bit-packed encoder.
+type bitPackedEnc struct {
+	*bitPackedWriter            // Data target: bit-packed writer
+	typeInfos        []typeInfo // Type descriptors
+}
+
+// newBitPackedEnc creates a new bit-packed encoder.
+func newBitPackedEnc(typeInfos []typeInfo) *bitPackedEnc {
+	return &bitPackedEnc{bitPackedWriter: &bitPackedWriter{}, typeInfos: typeInfos}
+}
+
+// instance encodes a value specified by its type id.
+func (e *bitPackedEnc) instance(typeid int, v interface{}) error {
+	w := e.bitPackedWriter // Local var for more compact code
+
+	ti := &e.typeInfos[typeid] // Pointer to avoid copying the struct
+
+	// Helper function to write an integer specified by the type info
+	writeInt := func(n int64) error {
+		if !fitsInt(ti, n) {
+			return fmt.Errorf("value %d out of range (typeid %d)", n, typeid)
+		}
+		w.writeBits(n-ti.offset64, ti.bits)
+		return nil
+	}
+
+	switch ti.s2pType {
+	case s2pInt:
+		n, ok := toInt(v)
+		if !ok {
+			return typeMismatch(typeid, v)
+		}
+		return writeInt(n)
+	case s2pStruct:
+		if parent, ok := soleParent(e.typeInfos, ti); ok {
+			if _, isStruct := v.(Struct); !isStruct {
+				return e.instance(parent.typeid, v)
+			}
+		}
+		s := asStruct(v)
+		if s == nil {
+			return typeMismatch(typeid, v)
+		}
+		for _, f := range ti.fields {
+			var err error
+			if f.isNameParent && e.typeInfos[f.typeid].s2pType == s2pStruct {
+				err = e.instance(f.typeid, s)
+			} else if fv, ok := s[f.name]; ok {
+				err = e.instance(f.typeid, fv)
+			} else {
+				err = fmt.Errorf("missing field %q (typeid %d)", f.name, typeid)
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	case s2pChoice:
+		tag, fv, err := choiceOf(ti, typeid, v)
+		if err != nil {
+			return err
+		}
+		if err := writeInt(int64(tag)); err != nil {
+			return err
+		}
+		return e.instance(ti.fields[tag].typeid, fv)
+	case s2pArr:
+		arr, ok := v.([]interface{})
+		if !ok {
+			return typeMismatch(typeid, v)
+		}
+		if err := writeInt(int64(len(arr))); err != nil {
+			return err
+		}
+		for _, elem := range arr {
+			if err := e.instance(ti.typeid, elem); err != nil {
+				return err
+			}
+		}
+		return nil
+	case s2pBitArr:
+		ba, ok := v.(BitArr)
+		if !ok {
+			return typeMismatch(typeid, v)
+		}
+		if err := writeInt(int64(ba.Count)); err != nil {
+			return err
+		}
+		if len(ba.Data) < (ba.Count+7)/8 {
+			return fmt.Errorf("bit array data too short (typeid %d)", typeid)
+		}
+		w.writeUnaligned(ba.Data[:ba.Count/8])
+		if remaining := ba.Count % 8; remaining != 0 {
+			w.writeBits(int64(ba.Data[ba.Count/8]), remaining)
+		}
+		return nil
+	case s2pBlob:
+		data, ok := toBytes(v)
+		if !ok {
+			return typeMismatch(typeid, v)
+		}
+		if err := writeInt(int64(len(data))); err != nil {
+			return err
+		}
+		w.writeAligned(data)
+		return nil
+	case s2pOptional:
+		if v == nil {
+			w.writeBits(0, 1)
+			return nil
+		}
+		w.writeBits(1, 1)
+		return e.instance(ti.typeid, v)
+	case s2pBool:
+		b, ok := v.(bool)
+		if !ok {
+			return typeMismatch(typeid, v)
+		}
+		if b {
+			w.writeBits(1, 1)
+		} else {
+			w.writeBits(0, 1)
+		}
+		return nil
+	case s2pFourCC:
+		data, ok := toBytes(v)
+		if !ok || len(data) != 4 {
+			return typeMismatch(typeid, v)
+		}
+		w.writeUnaligned(data)
+		return nil
+	case s2pNull:
+		return nil
+	}
+
+	return fmt.Errorf("unknown type (typeid %d)", typeid)
+}
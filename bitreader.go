@@ -0,0 +1,108 @@
+/*
+
+The exported BitReader type, the public API of the bit-packed buffer.
+
+*/
+
+package s2prot
+
+import "io"
+
+// BitReader reads data from a byte slice by arbitrary number of bits.
+// This is the same bit reader the protocol decoders use, and it can be used
+// to decode other SC2-related formats (e.g. bank files, battle lobby data).
+//
+// Bits of a byte are consumed from the lowest bit. Numbers constructed from multiple
+// bytes are either in big endian (used by the bit-packed and versioned decoders)
+// or in little endian (used by the attributes events) byte order.
+//
+// Read methods panic with io.ErrUnexpectedEOF if there is not enough data;
+// Remaining or EOF may be used to check that beforehand.
+type BitReader struct {
+	b bitPackedBuff
+}
+
+// NewBitReader creates a new BitReader reading from data.
+// bigEndian tells the byte order of numbers constructed from multiple bytes.
+func NewBitReader(data []byte, bigEndian bool) *BitReader {
+	return &BitReader{b: bitPackedBuff{contents: data, bigEndian: bigEndian}}
+}
+
+// BigEndian tells if numbers are read in big endian byte order.
+func (r *BitReader) BigEndian() bool {
+	return r.b.bigEndian
+}
+
+// EOF tells if all data has been read.
+func (r *BitReader) EOF() bool {
+	return r.b.EOF()
+}
+
+// Remaining returns the number of remaining (unread) bits.
+func (r *BitReader) Remaining() int {
+	return (len(r.b.contents)-r.b.idx)*8 + int(r.b.cacheBits)
+}
+
+// ByteAlign aligns to the next byte boundary, unread bits of the current byte are discarded.
+func (r *BitReader) ByteAlign() {
+	r.b.byteAlign()
+}
+
+// need panics with io.ErrUnexpectedEOF if there are less than n bits remaining.
+func (r *BitReader) need(n int) {
+	if n > r.Remaining() {
+		panic(io.ErrUnexpectedEOF)
+	}
+}
+
+// needAligned panics with io.ErrUnexpectedEOF if there are less than n whole bytes remaining
+// after aligning to byte boundary.
+func (r *BitReader) needAligned(n int) {
+	if n > len(r.b.contents)-r.b.idx {
+		panic(io.ErrUnexpectedEOF)
+	}
+}
+
+// ReadBit reads 1 bit and tells if it is 1.
+func (r *BitReader) ReadBit() bool {
+	r.need(1)
+	return r.b.readBits1()
+}
+
+// ReadBits reads n bits (0 <= n <= 64) and returns the number constructed from them.
+func (r *BitReader) ReadBits(n int) int64 {
+	if n < 0 || n > 64 {
+		panic("invalid number of bits")
+	}
+	r.need(n)
+	return r.b.readBits(byte(n))
+}
+
+// ReadUint8 reads 8 bits and returns them as a byte.
+func (r *BitReader) ReadUint8() byte {
+	r.need(8)
+	return r.b.readBits8()
+}
+
+// ReadAligned aligns to byte boundary, and reads n bytes.
+func (r *BitReader) ReadAligned(n int) []byte {
+	r.needAligned(n)
+	return r.b.readAligned(n)
+}
+
+// ReadUnaligned reads n bytes (n*8 bits) without aligning to byte boundary.
+func (r *BitReader) ReadUnaligned(n int) []byte {
+	r.need(n * 8)
+	return r.b.readUnaligned(n)
+}
+
+// ReadVarInt reads a variable-length integer, the way the versioned decoder encodes integers.
+// Must be at a byte boundary.
+func (r *BitReader) ReadVarInt() int64 {
+	defer func() {
+		if rec := recover(); rec != nil {
+			panic(io.ErrUnexpectedEOF)
+		}
+	}()
+	return readVarInt(&r.b)
+}
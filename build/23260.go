@@ -1,3 +1,5 @@
+//go:build !s2prot_nobuilds
+
 package build
 
 func init() {
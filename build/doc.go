@@ -2,5 +2,15 @@
 
 Package build contains the python source codes of different builds of s2protocol emedded in Go.
 
+New protocol versions can be imported from Blizzard's s2protocol repository by running go generate,
+see cmd/genbuilds for details.
+
+The embedded protocols make up most of the size of binaries using s2prot. They can be excluded
+with the s2prot_nobuilds build tag (e.g. for size-reduced js/wasm builds), in which case protocols
+must be provided at runtime, e.g. parsed from fetched data with s2prot.ParseProtocol
+and set with s2prot.Game.SetProtocol.
+
 */
 package build
+
+//go:generate go run ../cmd/genbuilds
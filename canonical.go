@@ -0,0 +1,158 @@
+/*
+
+Canonical JSON encoding of Structs and other decoded values.
+
+*/
+
+package s2prot
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"unicode/utf8"
+)
+
+// MarshalCanonical returns the canonical JSON encoding of v.
+//
+// The canonical encoding is byte-stable across runs and Go versions, suitable for golden-file testing
+// and content-addressed caching:
+//   - it is compact (contains no insignificant whitespace)
+//   - keys of Structs and maps are sorted
+//   - floating point numbers are formatted with the shortest representation that round-trips
+//   - byte slices and strings that are not valid UTF-8 (binary blobs) are encoded in hex form, e.g. "0x1fa4"
+//   - HTML characters are not escaped
+//
+// Values of other types are first marshaled with the encoding/json package, then canonicalized.
+func MarshalCanonical(v interface{}) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	if err := writeCanonical(buf, v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// CanonicalString returns the indented, canonical JSON representation of the Struct.
+// See MarshalCanonical for details.
+func (s Struct) CanonicalString() string {
+	b, err := MarshalCanonical(s)
+	if err != nil {
+		return ""
+	}
+	buf := &bytes.Buffer{}
+	json.Indent(buf, b, "", "  ")
+	return buf.String()
+}
+
+// writeCanonical writes the canonical JSON encoding of v.
+func writeCanonical(buf *bytes.Buffer, v interface{}) error {
+	switch x := v.(type) {
+	case nil:
+		buf.WriteString("null")
+	case bool:
+		buf.WriteString(strconv.FormatBool(x))
+	case int64:
+		buf.WriteString(strconv.FormatInt(x, 10))
+	case int:
+		buf.WriteString(strconv.Itoa(x))
+	case int32:
+		buf.WriteString(strconv.FormatInt(int64(x), 10))
+	case float64:
+		return writeCanonicalFloat(buf, x, 64)
+	case float32:
+		return writeCanonicalFloat(buf, float64(x), 32)
+	case json.Number:
+		buf.WriteString(string(x))
+	case string:
+		if !utf8.ValidString(x) {
+			writeHexString(buf, []byte(x))
+		} else {
+			writeString(buf, x)
+		}
+	case []byte:
+		writeHexString(buf, x)
+	case BitArr:
+		fmt.Fprintf(buf, `{"Count":%d,"Data":"0x%s"}`, x.Count, hex.EncodeToString(x.Data))
+	case Struct:
+		return writeCanonicalMap(buf, x)
+	case map[string]interface{}:
+		return writeCanonicalMap(buf, x)
+	case []interface{}:
+		buf.WriteByte('[')
+		for i, e := range x {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := writeCanonical(buf, e); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+	default:
+		// Marshal with encoding/json, and canonicalize the generic result
+		b, err := json.Marshal(x)
+		if err != nil {
+			return err
+		}
+		dec := json.NewDecoder(bytes.NewReader(b))
+		dec.UseNumber()
+		var generic interface{}
+		if err := dec.Decode(&generic); err != nil {
+			return err
+		}
+		return writeCanonical(buf, generic)
+	}
+	return nil
+}
+
+// writeCanonicalMap writes the canonical JSON encoding of a map, keys sorted.
+func writeCanonicalMap(buf *bytes.Buffer, m map[string]interface{}) error {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	buf.WriteByte('{')
+	for i, k := range keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		writeString(buf, k)
+		buf.WriteByte(':')
+		if err := writeCanonical(buf, m[k]); err != nil {
+			return err
+		}
+	}
+	buf.WriteByte('}')
+	return nil
+}
+
+// writeCanonicalFloat writes the canonical JSON encoding of a floating point number.
+// bitSize is the size of the original floating point type (32 or 64).
+func writeCanonicalFloat(buf *bytes.Buffer, f float64, bitSize int) error {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return fmt.Errorf("unsupported float value: %v", f)
+	}
+	buf.WriteString(strconv.FormatFloat(f, 'g', -1, bitSize))
+	return nil
+}
+
+// writeString writes a JSON string, without escaping HTML characters.
+func writeString(buf *bytes.Buffer, s string) {
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+	enc.Encode(s)               // Can't fail for strings
+	buf.Truncate(buf.Len() - 1) // Encode appends a newline
+}
+
+// writeHexString writes a JSON string containing the hex representation of the data, e.g. "0x1fa4".
+func writeHexString(buf *bytes.Buffer, data []byte) {
+	buf.WriteString(`"0x`)
+	buf.WriteString(hex.EncodeToString(data))
+	buf.WriteByte('"')
+}
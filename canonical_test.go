@@ -0,0 +1,51 @@
+package s2prot
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestMarshalCanonical(t *testing.T) {
+	s := Struct{
+		"z":     int64(-3),
+		"a":     []interface{}{1.5, 1e21, float32(0.1), nil, true},
+		"m":     map[string]interface{}{"b": "<&>", "a": "x"},
+		"blob":  "\xff\x00\x01",
+		"bytes": []byte{0x1f, 0xa4},
+		"bits":  BitArr{Count: 9, Data: []byte{0xff, 0x01}},
+		"evt":   struct{ B, A int }{2, 1},
+	}
+
+	exp := `{"a":[1.5,1e+21,0.1,null,true],"bits":{"Count":9,"Data":"0xff01"},` +
+		`"blob":"0xff0001","bytes":"0x1fa4","evt":{"A":1,"B":2},"m":{"a":"x","b":"<&>"},"z":-3}`
+	for i := 0; i < 3; i++ {
+		got, err := MarshalCanonical(s)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if string(got) != exp {
+			t.Errorf("Expected: %s, got: %s", exp, got)
+		}
+	}
+}
+
+func TestStructMarshalJSON(t *testing.T) {
+	s := Struct{
+		"name":   "<sp/>x",
+		"blob":   "\xff\x01",
+		"blobs":  []interface{}{"a", "\xff", []interface{}{"\x80"}},
+		"nested": Struct{"data": "\xfe"},
+	}
+
+	exp := `{"blob":"0xff01","blobs":["a","0xff",["0x80"]],"name":"\u003csp/\u003ex","nested":{"data":"0xfe"}}`
+	got, err := json.Marshal(s)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if string(got) != exp {
+		t.Errorf("Expected: %s, got: %s", exp, got)
+	}
+	if s.Stringv("blob") != "\xff\x01" || string(s.Blob("blob")) != "\xff\x01" {
+		t.Error("Marshaling must not modify the Struct!")
+	}
+}
@@ -0,0 +1,200 @@
+/*
+Package main is a CLI app that imports new protocol versions from Blizzard's
+s2protocol repository (https://github.com/Blizzard/s2protocol) into the build package.
+
+For each protocol python source (protocolNNNNN.py) not yet known by the build package
+it writes a NNNNN.go file: if the source is identical to an already known one,
+the file only registers an entry in the build.Duplicates map, else it embeds the source
+in the build.Builds map (the same way the build tests require).
+
+It is intended to be run with go generate from the build folder:
+
+	go generate github.com/icza/s2prot/build
+
+Protocol sources may also be imported from a local folder (e.g. a checkout of the s2protocol repo):
+
+	genbuilds -src ~/s2protocol/s2protocol/versions
+*/
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/icza/s2prot/build"
+)
+
+// Flag variables
+var (
+	outDir = flag.String("out", ".", "output folder (the build package's folder)")
+	srcDir = flag.String("src", "", "optional local folder of protocol sources to use instead of downloading them")
+	apiURL = flag.String("url", "https://api.github.com/repos/Blizzard/s2protocol/contents/s2protocol/versions",
+		"GitHub API URL listing the protocol sources")
+	dryRun = flag.Bool("dry", false, "only print what would be done, do not write files")
+)
+
+// client is the HTTP client used to download the protocol sources.
+var client = &http.Client{Timeout: 30 * time.Second}
+
+// protocolFileRegexp matches protocol source file names, the capturing group is the base build.
+var protocolFileRegexp = regexp.MustCompile(`^protocol(\d+)\.py$`)
+
+// buildConstraint is the build constraint of the generated files, allowing to exclude the embedded protocols.
+const buildConstraint = "//go:build !s2prot_nobuilds"
+
+// protSrc describes a protocol source to import.
+type protSrc struct {
+	baseBuild int
+	load      func() (string, error) // Loads the python source
+}
+
+func main() {
+	flag.Parse()
+
+	var srcs []protSrc
+	var err error
+	if *srcDir != "" {
+		srcs, err = listLocal(*srcDir)
+	} else {
+		srcs, err = listRemote(*apiURL)
+	}
+	if err != nil {
+		fmt.Println("Failed to list protocol sources:", err)
+		os.Exit(1)
+	}
+
+	sort.Slice(srcs, func(i, j int) bool { return srcs[i].baseBuild < srcs[j].baseBuild })
+
+	// Known sources, mapped from embedded source to base build
+	known := make(map[string]int, len(build.Builds))
+	for bb, src := range build.Builds {
+		known[src] = bb
+	}
+
+	imported := 0
+	for _, ps := range srcs {
+		if build.Builds[ps.baseBuild] != "" || build.Duplicates[ps.baseBuild] != 0 {
+			continue // Already known
+		}
+
+		src, err := ps.load()
+		if err != nil {
+			fmt.Printf("Failed to load protocol %d: %v\n", ps.baseBuild, err)
+			os.Exit(2)
+		}
+		if strings.Contains(src, "`") {
+			fmt.Printf("Protocol %d contains a backtick, can't embed it in a raw string literal!\n", ps.baseBuild)
+			os.Exit(3)
+		}
+		// Sources are embedded with a leading newline:
+		src = "\n" + src
+
+		var content string
+		if orig, ok := known[src]; ok {
+			fmt.Printf("%d: duplicate of %d\n", ps.baseBuild, orig)
+			content = fmt.Sprintf("%s\n\npackage build\n\nfunc init() {\n\tDuplicates[%d] = %d\n}\n", buildConstraint, ps.baseBuild, orig)
+		} else {
+			fmt.Printf("%d: new protocol\n", ps.baseBuild)
+			known[src] = ps.baseBuild
+			content = fmt.Sprintf("%s\n\npackage build\n\nfunc init() {\n\tBuilds[%d] = `%s`\n}\n", buildConstraint, ps.baseBuild, src)
+		}
+
+		imported++
+		if *dryRun {
+			continue
+		}
+		name := filepath.Join(*outDir, strconv.Itoa(ps.baseBuild)+".go")
+		if err := ioutil.WriteFile(name, []byte(content), 0644); err != nil {
+			fmt.Printf("Failed to write %s: %v\n", name, err)
+			os.Exit(4)
+		}
+	}
+
+	fmt.Printf("Imported %d new protocol(s).\n", imported)
+}
+
+// listLocal lists the protocol sources found in a local folder.
+func listLocal(dir string) (srcs []protSrc, err error) {
+	fis, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, fi := range fis {
+		bb, ok := parseBaseBuild(fi.Name())
+		if !ok {
+			continue
+		}
+		name := filepath.Join(dir, fi.Name())
+		srcs = append(srcs, protSrc{baseBuild: bb, load: func() (string, error) {
+			data, err := ioutil.ReadFile(name)
+			return string(data), err
+		}})
+	}
+
+	return
+}
+
+// listRemote lists the protocol sources using the GitHub contents API.
+func listRemote(url string) (srcs []protSrc, err error) {
+	data, err := download(url)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []struct {
+		Name        string `json:"name"`
+		DownloadURL string `json:"download_url"`
+	}
+	if err = json.Unmarshal(data, &entries); err != nil {
+		return nil, err
+	}
+
+	for _, e := range entries {
+		bb, ok := parseBaseBuild(e.Name)
+		if !ok {
+			continue
+		}
+		downloadURL := e.DownloadURL
+		srcs = append(srcs, protSrc{baseBuild: bb, load: func() (string, error) {
+			data, err := download(downloadURL)
+			return string(data), err
+		}})
+	}
+
+	return
+}
+
+// parseBaseBuild parses the base build from a protocol source file name.
+func parseBaseBuild(name string) (int, bool) {
+	m := protocolFileRegexp.FindStringSubmatch(name)
+	if m == nil {
+		return 0, false
+	}
+	bb, err := strconv.Atoi(m[1])
+	return bb, err == nil
+}
+
+// download downloads the content of the specified URL.
+func download(url string) ([]byte, error) {
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected response status: %s", resp.Status)
+	}
+	return ioutil.ReadAll(resp.Body)
+}
@@ -0,0 +1,191 @@
+/*
+Package main is a CLI app that generates the event name and event id constants
+and the event support tables of the rep package from the protocols of the build package.
+
+For each game, message and tracker event type found in any of the protocols it generates:
+  - an event name constant (e.g. GmEvtCmd, MsgEvtChat, TrEvtPlayerSetup),
+  - an event id constant (e.g. GmEIdCmd) if the event type has the same id in all base builds supporting it,
+  - entries in the GameEvtSupports, MessageEvtSupports and TrackerEvtSupports tables,
+    describing the base build ranges supporting the event type (and its id in them).
+
+It is intended to be run with go generate from the rep folder (after importing new protocols):
+
+	go generate github.com/icza/s2prot/rep
+*/
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/format"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/icza/s2prot"
+	"github.com/icza/s2prot/build"
+)
+
+// Flag variables
+var (
+	outDir = flag.String("out", ".", "output folder (the rep package's folder)")
+	dryRun = flag.Bool("dry", false, "only print the generated source, do not write the file")
+)
+
+// outName is the name of the generated file.
+const outName = "evtnames_gen.go"
+
+// evtKind describes a kind of events (game, message or tracker).
+type evtKind struct {
+	name       string // Name of the kind used in comments, e.g. "game"
+	namePrefix string // Prefix of the name constants, e.g. "GmEvt"
+	idPrefix   string // Prefix of the id constants, e.g. "GmEId"
+	tableName  string // Name of the support table, e.g. "GameEvtSupports"
+
+	evtTypes func(p *s2prot.Protocol) []s2prot.EvtType // Returns the event types of the kind
+}
+
+var evtKinds = []*evtKind{
+	{"game", "GmEvt", "GmEId", "GameEvtSupports", (*s2prot.Protocol).GameEvtTypes},
+	{"message", "MsgEvt", "MsgEId", "MessageEvtSupports", (*s2prot.Protocol).MessageEvtTypes},
+	{"tracker", "TrEvt", "TrEId", "TrackerEvtSupports", (*s2prot.Protocol).TrackerEvtTypes},
+}
+
+// support is an entry of a support table.
+type support struct {
+	name       string
+	id         int
+	min, max   int // Base build range, max is 0 if supported by the latest base build
+	lastActive int // Last base build the entry was extended with
+}
+
+func main() {
+	flag.Parse()
+
+	// All known base builds, duplicates included:
+	var baseBuilds []int
+	for bb := range build.Builds {
+		baseBuilds = append(baseBuilds, bb)
+	}
+	for bb := range build.Duplicates {
+		baseBuilds = append(baseBuilds, bb)
+	}
+	sort.Ints(baseBuilds)
+	latest := baseBuilds[len(baseBuilds)-1]
+
+	buf := &bytes.Buffer{}
+	buf.WriteString("// Code generated by genevtnames from the protocols of the build package; DO NOT EDIT.\n\npackage rep\n")
+
+	for _, kind := range evtKinds {
+		var supports []*support
+		open := map[string]*support{} // Currently open entries mapped from event name
+		for _, bb := range baseBuilds {
+			p := s2prot.GetProtocol(bb)
+			if p == nil {
+				fmt.Printf("Failed to get protocol %d!\n", bb)
+				os.Exit(1)
+			}
+			for id, et := range kind.evtTypes(p) {
+				if et.Name == "" {
+					continue
+				}
+				if s := open[et.Name]; s != nil && s.id == id && s.lastActive == prevBuild(baseBuilds, bb) {
+					s.lastActive = bb
+					continue
+				}
+				s := &support{name: et.Name, id: id, min: bb, lastActive: bb}
+				supports = append(supports, s)
+				open[et.Name] = s
+			}
+		}
+		for _, s := range supports {
+			if s.lastActive != latest {
+				s.max = s.lastActive
+			}
+		}
+		sort.SliceStable(supports, func(i, j int) bool {
+			if supports[i].id != supports[j].id {
+				return supports[i].id < supports[j].id
+			}
+			return supports[i].min < supports[j].min
+		})
+		writeKind(buf, kind, supports, baseBuilds[0])
+	}
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		fmt.Println("Failed to format generated source:", err)
+		os.Exit(2)
+	}
+
+	if *dryRun {
+		fmt.Print(string(src))
+		return
+	}
+	name := filepath.Join(*outDir, outName)
+	if err := ioutil.WriteFile(name, src, 0644); err != nil {
+		fmt.Printf("Failed to write %s: %v\n", name, err)
+		os.Exit(3)
+	}
+}
+
+// prevBuild returns the base build preceding bb in the sorted baseBuilds, 0 if bb is the first.
+func prevBuild(baseBuilds []int, bb int) int {
+	if i := sort.SearchInts(baseBuilds, bb); i > 0 {
+		return baseBuilds[i-1]
+	}
+	return 0
+}
+
+// writeKind writes the name and id constants and the support table of an event kind.
+// first is the first known base build.
+func writeKind(buf *bytes.Buffer, kind *evtKind, supports []*support, first int) {
+	// Supports mapped from event name
+	byName := map[string][]*support{}
+	var names []string
+	for _, s := range supports {
+		if byName[s.name] == nil {
+			names = append(names, s.name)
+		}
+		byName[s.name] = append(byName[s.name], s)
+	}
+	sort.Strings(names)
+
+	fmt.Fprintf(buf, "\n// Names of the %s events\nconst (\n", kind.name)
+	for _, name := range names {
+		fmt.Fprintf(buf, "\t%s%s = %q\n", kind.namePrefix, name, name)
+	}
+	buf.WriteString(")\n")
+
+	fmt.Fprintf(buf, "\n// Ids of the %s events that have the same id in all base builds supporting them\nconst (\n", kind.name)
+	for _, s := range supports {
+		ss := byName[s.name]
+		if len(ss) != 1 {
+			continue // Id changed over the builds
+		}
+		fmt.Fprintf(buf, "\t%s%s = %d // %s %s event id%s\n", kind.idPrefix, s.name, s.id, s.name, kind.name, rangeNote(s, first))
+	}
+	buf.WriteString(")\n")
+
+	fmt.Fprintf(buf, "\n// %s describes the base builds supporting the %s events and their ids.\nvar %s = []EvtSupport{\n",
+		kind.tableName, kind.name, kind.tableName)
+	for _, s := range supports {
+		fmt.Fprintf(buf, "\t{%s%s, %d, %d, %d},\n", kind.namePrefix, s.name, s.id, s.min, s.max)
+	}
+	buf.WriteString("}\n")
+}
+
+// rangeNote returns a note about the base build range of the support, empty string if all base builds support it.
+func rangeNote(s *support, first int) string {
+	switch {
+	case s.min == first && s.max == 0:
+		return ""
+	case s.max == 0:
+		return fmt.Sprintf(" [ONLY FROM BASEBUILD %d]", s.min)
+	case s.min == first:
+		return fmt.Sprintf(" [ONLY UP TO BASEBUILD %d]", s.max)
+	}
+	return fmt.Sprintf(" [ONLY FROM BASEBUILD %d UP TO BASEBUILD %d]", s.min, s.max)
+}
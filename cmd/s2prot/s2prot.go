@@ -1,18 +1,40 @@
 /*
 Package main is a simple CLI app to parse and display information about
 a StarCraft II replay passed as a CLI argument.
+
+In career mode (-career flag) it displays the career timeline of a player
+across the replays passed as CLI arguments.
+
+In dedup mode (-dedup flag) it finds (and optionally prunes) duplicate replays
+in the files and directories passed as CLI arguments.
+
+In verify mode (-verify flag) it verifies that all sections of the replays
+in the files and directories passed as CLI arguments decode cleanly.
+
+In bench mode (-bench flag) it parses the replays passed as CLI arguments repeatedly,
+and prints the average timings and allocations of parsing them, section by section.
 */
 package main
 
 import (
+	"bytes"
 	"encoding/json"
 	"flag"
 	"fmt"
+	"io"
 	"os"
+	"regexp"
 	"runtime"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
 
 	"github.com/icza/s2prot"
 	"github.com/icza/s2prot/rep"
+	"github.com/icza/s2prot/rep/aggregate"
+	"github.com/icza/s2prot/rep/dedup"
+	"github.com/icza/s2prot/rep/verify"
 )
 
 const (
@@ -25,18 +47,32 @@ const (
 // Flag variables
 var (
 	version = flag.Bool("version", false, "print version info and exit")
+	diff    = flag.String("diff", "", "compare 2 base builds (e.g. \"80669,80949\"), print their differences and exit")
+
+	career   = flag.String("career", "", "print the career of the player specified by toon handle (e.g. \"2-S2-1-12345\") across the replays")
+	classify = flag.Bool("classify", false, "classify builds in the career")
+
+	dedupMode = flag.Bool("dedup", false, "find duplicate replays in the files and directories (keeping the most complete copies)")
+	prune     = flag.Bool("prune", false, "delete the duplicates found in dedup mode")
+
+	verifyMode = flag.Bool("verify", false, "verify that all sections of the replays in the files and directories decode cleanly, print a report per base build")
+
+	bench = flag.Int("bench", 0, "parse the replays the given times, and print the average timings and allocations of the sections")
 
 	header      = flag.Bool("header", true, "print replay header")
 	details     = flag.Bool("details", false, "print replay details")
 	initData    = flag.Bool("initdata", false, "print replay init data")
 	attrEvts    = flag.Bool("attrevts", false, "print attributes events")
 	metadata    = flag.Bool("metadata", true, "print game metadata")
+	players     = flag.Bool("players", false, "print computed player data")
 	gameEvts    = flag.Bool("gameevts", false, "print game events")
 	msgEvts     = flag.Bool("msgevts", false, "print message events")
+	dropChat    = flag.String("dropchat", "", "optional regexp, chat messages matching it are dropped from the message events")
 	trackerEvts = flag.Bool("trackerevts", false, "print tracker events")
 	outFile     = flag.String("outfile", "", "optional output file name")
 
 	indent = flag.Bool("indent", true, "use indentation when formatting output")
+	format = flag.String("format", "json", "output format: json, yaml, toml or msgpack (toml requires an object, e.g. a replay)")
 )
 
 func main() {
@@ -47,66 +83,270 @@ func main() {
 		return
 	}
 
+	if *diff != "" {
+		if err := printDiff(*diff); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	args := flag.Args()
 	if len(args) < 1 {
 		printUsage()
 		os.Exit(1)
 	}
 
-	r, err := rep.NewFromFileEvts(args[0], *gameEvts, *msgEvts, *trackerEvts)
-	if err != nil {
-		fmt.Printf("Failed to parse replay: %v\n", err)
-		os.Exit(2)
+	if *career != "" {
+		printCareer(*career, args)
+		return
 	}
 
-	// Zero values in replay the user do not wish to see:
-	if !*header {
-		r.Header.Struct = nil
-	}
-	if !*details {
-		r.Details.Struct = nil
+	if *dedupMode {
+		if exitCode := printDups(args); exitCode != 0 {
+			os.Exit(exitCode)
+		}
+		return
 	}
-	if !*initData {
-		r.InitData.Struct = nil
+
+	if *verifyMode {
+		printVerify(args)
+		return
 	}
-	if !*attrEvts {
-		r.AttrEvts.Struct = nil
+
+	if *bench > 0 {
+		printBench(*bench, args)
+		return
 	}
-	if !*metadata {
-		r.Metadata.Struct = nil
+
+	r, err := rep.NewFromFileEvts(args[0], *gameEvts, *msgEvts, *trackerEvts)
+	if err != nil {
+		fmt.Printf("Failed to parse replay: %v\n", err)
+		if err == rep.ErrUnsupportedRepVersion {
+			printSniffedVersion(args[0])
+		}
+		os.Exit(2)
 	}
-	if !*gameEvts {
-		r.GameEvts = nil
+
+	// Sections the user wishes to see:
+	sections := rep.JSONSections{
+		Header:      *header,
+		Details:     *details,
+		InitData:    *initData,
+		AttrEvts:    *attrEvts,
+		Metadata:    *metadata,
+		Players:     *players,
+		GameEvts:    *gameEvts,
+		MessageEvts: *msgEvts,
+		TrackerEvts: *trackerEvts,
 	}
-	if !*msgEvts {
-		r.MessageEvts = nil
+
+	doc := r.JSONDoc(sections)
+	if *dropChat != "" {
+		re, err := regexp.Compile(*dropChat)
+		if err != nil {
+			fmt.Printf("Invalid dropchat regexp: %v\n", err)
+			os.Exit(1)
+		}
+		doc.FilterChat(rep.ChatFilter{rep.ChatDropMatching(re)})
 	}
-	if !*trackerEvts {
-		r.TrackerEvts = nil
+
+	enc, closeOut := newEncoder()
+	defer closeOut()
+	enc.Encode(doc)
+}
+
+// encoder encodes values to the output.
+type encoder interface {
+	Encode(v interface{}) error
+}
+
+// marshalEncoder is an encoder using a marshal function.
+type marshalEncoder struct {
+	w       io.Writer
+	marshal func(v interface{}) ([]byte, error)
+}
+
+// Encode marshals v and writes it to the output.
+func (me marshalEncoder) Encode(v interface{}) error {
+	data, err := me.marshal(v)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to encode output: %v\n", err)
+		return err
 	}
+	_, err = me.w.Write(data)
+	return err
+}
 
-	var enc *json.Encoder
+// newEncoder returns an encoder of the output format writing to the output file or to the standard output,
+// and a function to close the output file.
+func newEncoder() (enc encoder, closeOut func()) {
+	closeOut = func() {}
 
-	if *outFile == "" {
-		enc = json.NewEncoder(os.Stdout)
-	} else {
+	var w io.Writer = os.Stdout
+	if *outFile != "" {
 		fp, err := os.Create(*outFile)
 		if err != nil {
 			fmt.Printf("Failed to create output file: %v\n", err)
 			os.Exit(3)
 		}
-		defer func() {
+		closeOut = func() {
 			if err := fp.Close(); err != nil {
 				panic(err)
 			}
-		}()
-		enc = json.NewEncoder(fp)
+		}
+		w = fp
 	}
 
-	if *indent {
-		enc.SetIndent("", "  ")
+	switch strings.ToLower(*format) {
+	case "json":
+		jenc := json.NewEncoder(w)
+		if *indent {
+			jenc.SetIndent("", "  ")
+		}
+		enc = jenc
+	case "yaml":
+		enc = marshalEncoder{w, s2prot.MarshalYAML}
+	case "toml":
+		enc = marshalEncoder{w, s2prot.MarshalTOML}
+	case "msgpack":
+		enc = marshalEncoder{w, s2prot.MarshalMsgpack}
+	default:
+		closeOut()
+		fmt.Printf("Invalid output format: %s\n", *format)
+		os.Exit(1)
+	}
+	return
+}
+
+// printCareer prints the career of the player specified by its toon handle across the replay files.
+func printCareer(toon string, names []string) {
+	a := aggregate.New()
+	if *classify {
+		a.BuildClassifier = rep.DefaultBuildClassifier
+	}
+	for _, fe := range a.AddFiles(names, 0) {
+		fmt.Fprintf(os.Stderr, "Failed to parse replay: %v\n", fe)
+	}
+
+	c := a.Career(toon)
+	if c == nil {
+		fmt.Printf("No games found for player: %s\n", toon)
+		os.Exit(2)
+	}
+
+	enc, closeOut := newEncoder()
+	defer closeOut()
+	enc.Encode(struct {
+		*aggregate.Career
+		Stats *aggregate.CareerStats `json:"stats"`
+	}{c, c.Stats()})
+}
+
+// printDups prints the groups of duplicate replays in the files and directories, and prunes them if requested.
+// The exit code is returned (so the output is closed before exiting).
+func printDups(paths []string) (exitCode int) {
+	files, errs := dedup.Scan(paths, 0)
+	for _, fe := range errs {
+		fmt.Fprintf(os.Stderr, "Failed to scan replay: %v\n", fe)
+	}
+	groups := dedup.Find(files)
+
+	enc, closeOut := newEncoder()
+	defer closeOut()
+	enc.Encode(groups)
+
+	if *prune {
+		if err := dedup.Prune(groups); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to prune duplicates: %v\n", err)
+			return 4
+		}
+	}
+	return 0
+}
+
+// printVerify verifies the replays in the files and directories, and prints the report.
+func printVerify(paths []string) {
+	report := verify.Corpus(paths, 0)
+	for _, fe := range report.Errs {
+		fmt.Fprintf(os.Stderr, "Failed to verify replay: %v\n", fe)
+	}
+
+	enc, closeOut := newEncoder()
+	enc.Encode(report)
+	closeOut()
+
+	if !report.OK() {
+		os.Exit(5)
+	}
+}
+
+// printBench parses the replay files runs times, and prints the average timings and allocations
+// of parsing them as a whole and section by section (see rep.ProfileSections).
+func printBench(runs int, names []string) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	defer w.Flush()
+
+	for _, name := range names {
+		data, err := os.ReadFile(name)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to read replay: %v\n", err)
+			continue
+		}
+
+		var sums []*rep.SectionProfile
+		total := &rep.SectionProfile{Section: "total (rep.New)", Size: len(data)}
+		for i := 0; i < runs; i++ {
+			profs, err := rep.ProfileSections(bytes.NewReader(data))
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Failed to parse replay %s: %v\n", name, err)
+				break
+			}
+			for j, sp := range profs {
+				if j == len(sums) {
+					sums = append(sums, &rep.SectionProfile{Section: sp.Section, Size: sp.Size})
+				}
+				sum := sums[j]
+				sum.Read += sp.Read
+				sum.Decode += sp.Decode
+				sum.Allocs += sp.Allocs
+				sum.AllocBytes += sp.AllocBytes
+			}
+
+			var before, after runtime.MemStats
+			runtime.ReadMemStats(&before)
+			start := time.Now()
+			if r, err := rep.New(bytes.NewReader(data)); err == nil {
+				r.Close()
+			}
+			total.Decode += time.Since(start)
+			runtime.ReadMemStats(&after)
+			total.Allocs += after.Mallocs - before.Mallocs
+			total.AllocBytes += after.TotalAlloc - before.TotalAlloc
+		}
+
+		fmt.Fprintf(w, "%s (%d runs):\n", name, runs)
+		fmt.Fprintln(w, "Section\tSize\tRead\tDecode\tAllocs\tAlloc bytes")
+		for _, sp := range append(sums, total) {
+			n := time.Duration(runs)
+			fmt.Fprintf(w, "%s\t%d\t%v\t%v\t%d\t%d\n", sp.Section, sp.Size, sp.Read/n, sp.Decode/n,
+				sp.Allocs/uint64(runs), sp.AllocBytes/uint64(runs))
+		}
+	}
+}
+
+// printSniffedVersion prints the version of the replay file, see rep.Sniff.
+func printSniffedVersion(name string) {
+	f, err := os.Open(name)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+
+	if res, _ := rep.Sniff(f); res.Kind == rep.FileKindReplay {
+		fmt.Printf("Replay version: %d.%d.%d.%d, base build: %d (supported: %d .. %d), type: %d\n",
+			res.Major, res.Minor, res.Revision, res.Build, res.BaseBuild, s2prot.MinBaseBuild, s2prot.MaxBaseBuild, res.Type)
 	}
-	enc.Encode(r)
 }
 
 func printVersion() {
@@ -119,9 +359,36 @@ func printVersion() {
 	fmt.Println("Home page:", appHome)
 }
 
+// printDiff prints the differences of the protocols of 2 base builds
+// specified in the form of "oldBaseBuild,newBaseBuild".
+func printDiff(builds string) error {
+	parts := strings.Split(builds, ",")
+	if len(parts) != 2 {
+		return fmt.Errorf("Invalid diff builds: %q", builds)
+	}
+
+	var ps [2]*s2prot.Protocol
+	for i, part := range parts {
+		bb, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil {
+			return fmt.Errorf("Invalid base build: %q", part)
+		}
+		if ps[i] = s2prot.GetProtocol(bb); ps[i] == nil {
+			return fmt.Errorf("Unknown base build: %d", bb)
+		}
+	}
+
+	fmt.Print(s2prot.DiffProtocols(ps[0], ps[1]))
+	return nil
+}
+
 func printUsage() {
 	fmt.Println("Usage:")
 	name := os.Args[0]
 	fmt.Printf("\t%s [FLAGS] repfile.SC2Replay\n", name)
+	fmt.Printf("\t%s -career=toon [FLAGS] repfile.SC2Replay...\n", name)
+	fmt.Printf("\t%s -dedup [-prune] [FLAGS] dir-or-repfile...\n", name)
+	fmt.Printf("\t%s -verify [FLAGS] dir-or-repfile...\n", name)
+	fmt.Printf("\t%s -bench=runs repfile.SC2Replay...\n", name)
 	fmt.Println("\tRun with '-h' to see a list of available flags.")
 }
@@ -0,0 +1,72 @@
+//go:build js && wasm
+
+package main
+
+import (
+	"syscall/js"
+)
+
+func main() {
+	js.Global().Set("s2prot", js.ValueOf(map[string]interface{}{
+		"parseReplay": js.FuncOf(jsParseReplay),
+		"summary":     js.FuncOf(jsSummary),
+		"setProtocol": js.FuncOf(jsSetProtocol),
+	}))
+
+	select {} // Keep the functions available
+}
+
+// jsParseReplay implements s2prot.parseReplay(bytes, options).
+func jsParseReplay(this js.Value, args []js.Value) interface{} {
+	if len(args) < 1 {
+		return jsError("missing replay data")
+	}
+	options := map[string]bool{}
+	if len(args) > 1 && args[1].Type() == js.TypeObject {
+		keys := js.Global().Get("Object").Call("keys", args[1])
+		for i := 0; i < keys.Length(); i++ {
+			key := keys.Index(i).String()
+			options[key] = args[1].Get(key).Truthy()
+		}
+	}
+	return jsResult(parseReplay(bytesOf(args[0]), sectionsOf(options)))
+}
+
+// jsSummary implements s2prot.summary(bytes).
+func jsSummary(this js.Value, args []js.Value) interface{} {
+	if len(args) < 1 {
+		return jsError("missing replay data")
+	}
+	return jsResult(summary(bytesOf(args[0])))
+}
+
+// jsSetProtocol implements s2prot.setProtocol(baseBuild, bytes).
+func jsSetProtocol(this js.Value, args []js.Value) interface{} {
+	if len(args) < 2 {
+		return jsError("missing base build or protocol data")
+	}
+	if err := setProtocol(args[0].Int(), bytesOf(args[1])); err != nil {
+		return jsError(err.Error())
+	}
+	return js.Null()
+}
+
+// bytesOf copies the content of a JavaScript Uint8Array.
+func bytesOf(v js.Value) []byte {
+	data := make([]byte, v.Get("length").Int())
+	js.CopyBytesToGo(data, v)
+	return data
+}
+
+// jsResult returns the JSON data as a string, or an Error object if err is not nil.
+func jsResult(data []byte, err error) interface{} {
+	if err != nil {
+		return jsError(err.Error())
+	}
+	return string(data)
+}
+
+// jsError returns a JavaScript Error object with the message.
+func jsError(msg string) js.Value {
+	return js.Global().Get("Error").New(msg)
+}
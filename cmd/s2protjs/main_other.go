@@ -0,0 +1,13 @@
+//go:build !(js && wasm)
+
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+func main() {
+	fmt.Println("This app must be built for js/wasm (GOOS=js GOARCH=wasm).")
+	os.Exit(1)
+}
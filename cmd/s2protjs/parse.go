@@ -0,0 +1,99 @@
+/*
+Package main is a js/wasm wrapper of s2prot, enabling fully client-side (browser) replay analyzers.
+
+Build it with:
+
+	GOOS=js GOARCH=wasm go build -tags s2prot_nobuilds -o s2prot.wasm github.com/icza/s2prot/cmd/s2protjs
+
+The s2prot_nobuilds build tag is optional, it excludes the embedded protocols to reduce the size of the binary,
+in which case the protocols of the replays must be set before parsing (see setProtocol below).
+Load s2prot.wasm with wasm_exec.js of the Go distribution, which registers the global s2prot object:
+
+	// Parses the replay given as an Uint8Array, returns its JSON document as a string.
+	// Optional options tell the sections to include, keys are the names of the CLI flags of the s2prot app,
+	// e.g. {gameevts: true, initdata: false}. By default all sections but the events are included.
+	s2prot.parseReplay(bytes, options)
+
+	// Parses the replay given as an Uint8Array, returns its summary (see rep.Summary) as a JSON string.
+	s2prot.summary(bytes)
+
+	// Sets the protocol of a base build from its python source (from Blizzard's s2protocol)
+	// given as an Uint8Array, e.g. fetched from a server.
+	s2prot.setProtocol(baseBuild, bytes)
+
+On failure the functions return an Error object instead of the result.
+*/
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/icza/s2prot"
+	"github.com/icza/s2prot/rep"
+)
+
+// defaultSections are the sections included in the JSON document by default.
+var defaultSections = rep.JSONSections{
+	Header:   true,
+	Details:  true,
+	InitData: true,
+	AttrEvts: true,
+	Metadata: true,
+	Players:  true,
+}
+
+// sectionsOf returns the sections to include specified by options mapped from CLI flag name.
+// Sections not present in options are included as in defaultSections.
+func sectionsOf(options map[string]bool) rep.JSONSections {
+	s := defaultSections
+	for name, p := range map[string]*bool{
+		"header":      &s.Header,
+		"details":     &s.Details,
+		"initdata":    &s.InitData,
+		"attrevts":    &s.AttrEvts,
+		"metadata":    &s.Metadata,
+		"players":     &s.Players,
+		"gameevts":    &s.GameEvts,
+		"msgevts":     &s.MessageEvts,
+		"trackerevts": &s.TrackerEvts,
+	} {
+		if v, ok := options[name]; ok {
+			*p = v
+		}
+	}
+	return s
+}
+
+// parseReplay parses the replay and returns the JSON document of the specified sections.
+// Only the events included in sections are decoded.
+func parseReplay(data []byte, sections rep.JSONSections) ([]byte, error) {
+	r, err := rep.NewEvts(bytes.NewReader(data), sections.GameEvts, sections.MessageEvts, sections.TrackerEvts)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	return json.Marshal(r.JSONDoc(sections))
+}
+
+// summary parses the replay and returns its summary as JSON.
+func summary(data []byte) ([]byte, error) {
+	r, err := rep.New(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	return json.Marshal(r.Summary())
+}
+
+// setProtocol sets the StarCraft II protocol of the base build from its description.
+func setProtocol(baseBuild int, data []byte) error {
+	p, err := s2prot.ParseProtocol(data, baseBuild)
+	if err != nil {
+		return err
+	}
+	s2prot.GameSC2.SetProtocol(baseBuild, p)
+	return nil
+}
@@ -0,0 +1,273 @@
+/*
+
+Public decoders for custom payloads defined by a user-supplied type table.
+
+*/
+
+package s2prot
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// TypeTable is a table of type descriptors (decoding instructions), the typeid of a type
+// is its index in the table. It is the equivalent of the typeinfos list of s2protocol.
+type TypeTable struct {
+	typeInfos []typeInfo
+}
+
+// ParseTypeTable parses a type table from its python source representation,
+// one type descriptor per element, in the format s2protocol uses, e.g.
+//
+//	('_int',[(0,7)]),  #0
+//	('_struct',[[('m_x',0,0),('m_y',0,1)]]),  #1
+func ParseTypeTable(typeInfos []string) (t *TypeTable, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			t, err = nil, fmt.Errorf("invalid type table: %v", r)
+		}
+	}()
+
+	t = &TypeTable{typeInfos: make([]typeInfo, len(typeInfos))}
+	for i, src := range typeInfos {
+		t.typeInfos[i] = parseTypeInfo(src)
+	}
+	t.check()
+
+	return t, nil
+}
+
+// ParseTypeTableJSON parses a type table from its JSON representation,
+// which is a JSON array of type descriptors: the python source representation
+// with tuples as JSON arrays and the keys of dicts (choice tags) as strings, e.g.
+//
+//	[["_int", [[0, 7]]], ["_struct", [[["m_x", 0, 0], ["m_y", 0, 1]]]]]
+func ParseTypeTableJSON(data []byte) (t *TypeTable, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			t, err = nil, fmt.Errorf("invalid type table: %v", r)
+		}
+	}()
+
+	var raws []json.RawMessage
+	if err = json.Unmarshal(data, &raws); err != nil {
+		return nil, err
+	}
+
+	t = &TypeTable{typeInfos: make([]typeInfo, len(raws))}
+	for i, raw := range raws {
+		t.typeInfos[i] = typeInfoFromJSON(raw)
+	}
+	t.check()
+
+	return t, nil
+}
+
+// check panics if a type descriptor refers to an invalid typeid.
+func (t *TypeTable) check() {
+	(&Protocol{typeInfos: t.typeInfos}).checkTypeInfos()
+}
+
+// TypeTable returns the type table of the protocol.
+// Typeids of the protocol (e.g. the ones in the python source) are valid in the returned table.
+func (p *Protocol) TypeTable() *TypeTable {
+	return &TypeTable{typeInfos: p.typeInfos}
+}
+
+// Len returns the number of types in the table.
+func (t *TypeTable) Len() int {
+	return len(t.typeInfos)
+}
+
+// Decoder decodes values of a type table from binary data.
+type Decoder struct {
+	d         decoder
+	typeCount int
+}
+
+// NewBitPackedDecoder creates a new Decoder using the bit-packed encoding
+// (which is used e.g. by the game events and the init data).
+func NewBitPackedDecoder(contents []byte, t *TypeTable) *Decoder {
+	return &Decoder{d: newBitPackedDec(contents, t.typeInfos), typeCount: len(t.typeInfos)}
+}
+
+// NewVersionedDecoder creates a new Decoder using the versioned encoding
+// (which is used e.g. by the replay header, the game details and the tracker events).
+func NewVersionedDecoder(contents []byte, t *TypeTable) *Decoder {
+	return &Decoder{d: newVersionedDec(contents, t.typeInfos), typeCount: len(t.typeInfos)}
+}
+
+// Decode decodes a value of the type specified by its typeid.
+// Structs are decoded into Struct values, just like in the case of replay data.
+func (d *Decoder) Decode(typeid int) (v interface{}, err error) {
+	if typeid < 0 || typeid >= d.typeCount {
+		return nil, fmt.Errorf("invalid typeid: %d", typeid)
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			if ve, ok := r.(*ValidationError); ok {
+				v, err = nil, ve
+			} else {
+				v, err = nil, fmt.Errorf("failed to decode typeid %d: %v", typeid, r)
+			}
+		}
+	}()
+
+	return d.d.instance(typeid), nil
+}
+
+// SetStrict sets the validation mode of the decoder.
+// In strict mode unknown choice tags and unknown struct field tags (in case of the versioned encoding)
+// cause Decode to return a *ValidationError instead of being silently ignored.
+func (d *Decoder) SetStrict(strict bool) {
+	switch dd := d.d.(type) {
+	case *bitPackedDec:
+		dd.strict = strict
+	case *versionedDec:
+		dd.strict = strict
+	}
+}
+
+// ByteAlign aligns to the next byte boundary, unread bits of the current byte are discarded.
+// Consecutive values (e.g. events) are usually byte-aligned.
+func (d *Decoder) ByteAlign() {
+	d.d.byteAlign()
+}
+
+// EOF tells if all data has been decoded.
+func (d *Decoder) EOF() bool {
+	return d.d.EOF()
+}
+
+// checkTypeInfos panics if a type info refers to an invalid typeid.
+func (p *Protocol) checkTypeInfos() {
+	for i := range p.typeInfos {
+		ti := &p.typeInfos[i]
+		for _, f := range ti.fields {
+			p.checkTypeid(f.typeid)
+		}
+		if ti.s2pType == s2pArr || ti.s2pType == s2pOptional {
+			p.checkTypeid(ti.typeid)
+		}
+	}
+}
+
+// checkTypeid panics if the specified typeid is invalid.
+func (p *Protocol) checkTypeid(typeid int) {
+	if typeid < 0 || typeid >= len(p.typeInfos) {
+		panic(fmt.Sprintf("invalid typeid: %d", typeid))
+	}
+}
+
+// typeInfoFromJSON creates a typeInfo from its JSON representation.
+// Panics if input is in invalid format.
+func typeInfoFromJSON(raw json.RawMessage) typeInfo {
+	var tiJSON []json.RawMessage // [name, params]
+	mustUnmarshal(raw, &tiJSON)
+	if len(tiJSON) != 2 {
+		panic("invalid type info: " + string(raw))
+	}
+
+	var name string
+	mustUnmarshal(tiJSON[0], &name)
+	name = strings.TrimPrefix(name, "_")
+	if len(name) < 2 {
+		panic("invalid type name: " + name)
+	}
+	s2pt, ok := nameS2pTypes[name[:2]]
+	if !ok {
+		panic("unknown type name: " + name)
+	}
+	ti := typeInfo{s2pType: s2pt}
+
+	var params []json.RawMessage
+	mustUnmarshal(tiJSON[1], &params)
+
+	// Helper function to read int bounds specified in the form of "[0,7]"
+	readBounds := func(raw json.RawMessage) {
+		var bounds []json.Number
+		mustUnmarshal(raw, &bounds)
+		if len(bounds) != 2 {
+			panic("invalid bounds: " + string(raw))
+		}
+		ti.offset64 = mustInt64(bounds[0])
+		ti.bits = int(mustInt64(bounds[1]))
+		if ti.bits <= 32 {
+			ti.offset32 = int32(ti.offset64)
+		}
+	}
+
+	// Helper function to get a param
+	param := func(i int) json.RawMessage {
+		if i >= len(params) {
+			panic(fmt.Sprintf("missing param %d of type %s", i, name))
+		}
+		return params[i]
+	}
+
+	switch ti.s2pType {
+	case s2pInt, s2pBitArr, s2pBlob: // ["_int",[[0,7]]]
+		readBounds(param(0))
+	case s2pStruct: // ["_struct",[[["m_name",71,-3],["m_type",6,-2]]]]
+		var fieldsJSON [][]json.RawMessage
+		mustUnmarshal(param(0), &fieldsJSON)
+		ti.fields = make([]field, len(fieldsJSON))
+		for i, fj := range fieldsJSON {
+			if len(fj) != 3 {
+				panic("invalid struct field: " + name)
+			}
+			f := &ti.fields[i]
+			mustUnmarshal(fj[0], &f.name)
+			f.isNameParent = f.name == "__parent"
+			f.name = strings.TrimPrefix(f.name, "m_")
+			mustUnmarshal(fj[1], &f.typeid)
+			mustUnmarshal(fj[2], &f.tag)
+		}
+	case s2pChoice: // ["_choice",[[0,2],{"0":["None",91],"1":["TargetPoint",93]}]]
+		readBounds(param(0))
+		var fieldsJSON map[string][]json.RawMessage
+		mustUnmarshal(param(1), &fieldsJSON)
+		// Choice fields are indexed by tag, so order them by tag:
+		ti.fields = make([]field, len(fieldsJSON))
+		for stag, fj := range fieldsJSON {
+			tag, err := strconv.Atoi(stag)
+			if err != nil || tag < 0 || tag >= len(ti.fields) || len(fj) != 2 {
+				panic("invalid choice field: " + stag)
+			}
+			f := &ti.fields[tag]
+			f.tag = tag
+			mustUnmarshal(fj[0], &f.name)
+			mustUnmarshal(fj[1], &f.typeid)
+		}
+	case s2pArr: // ["_array",[[16,0],10]]
+		readBounds(param(0))
+		mustUnmarshal(param(1), &ti.typeid)
+	case s2pOptional: // ["_optional",[14]]
+		mustUnmarshal(param(0), &ti.typeid)
+	}
+
+	return ti
+}
+
+// mustUnmarshal unmarshals the JSON data into v, and panics on error.
+func mustUnmarshal(data []byte, v interface{}) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(v); err != nil {
+		panic(err)
+	}
+}
+
+// mustInt64 returns the int64 value of the JSON number, and panics if it's not an integer.
+func mustInt64(n json.Number) int64 {
+	i, err := n.Int64()
+	if err != nil {
+		panic(err)
+	}
+	return i
+}
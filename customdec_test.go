@@ -0,0 +1,182 @@
+package s2prot
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCustomDecoders(t *testing.T) {
+	tt, err := ParseTypeTable([]string{
+		"('_int',[(0,8)]),  #0",
+		"('_struct',[[('m_x',0,0),('m_y',0,1)]]),  #1",
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	ttJSON, err := ParseTypeTableJSON([]byte(`[["_int", [[0, 8]]], ["_struct", [[["m_x", 0, 0], ["m_y", 0, 1]]]]]`))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(tt, ttJSON) {
+		t.Errorf("Python and JSON type tables differ!")
+	}
+
+	d := NewVersionedDecoder([]byte{0x05, 0x04, 0x00, 0x09, 0x06, 0x02, 0x09, 0x03}, tt)
+	v, err := d.Decode(1)
+	if exp := (Struct{"x": int64(3), "y": int64(-1)}); err != nil || !reflect.DeepEqual(v, exp) {
+		t.Errorf("Expected: %v, got: %v (err: %v)", exp, v, err)
+	}
+	if !d.EOF() {
+		t.Errorf("Expected EOF!")
+	}
+
+	d = NewBitPackedDecoder([]byte{0x2a, 0x07}, tt)
+	v, err = d.Decode(1)
+	if exp := (Struct{"x": int64(0x2a), "y": int64(0x07)}); err != nil || !reflect.DeepEqual(v, exp) {
+		t.Errorf("Expected: %v, got: %v (err: %v)", exp, v, err)
+	}
+	if _, err = d.Decode(0); err == nil {
+		t.Errorf("Expected error decoding past EOF!")
+	}
+	if _, err = d.Decode(2); err == nil {
+		t.Errorf("Expected error for invalid typeid!")
+	}
+
+	if _, err = ParseTypeTable([]string{"('_optional',[3]),  #0"}); err == nil {
+		t.Errorf("Expected error for invalid typeid reference!")
+	}
+}
+
+func TestParseTypeTableJSON(t *testing.T) {
+	tt, err := ParseTypeTable([]string{
+		"('_int',[(0,7)]),  #0",
+		"('_choice',[(0,2),{0:('m_a',0),1:('m_b',2)}]),  #1",
+		"('_array',[(0,5),0]),  #2",
+		"('_optional',[1]),  #3",
+		"('_blob',[(0,8)]),  #4",
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	ttJSON, err := ParseTypeTableJSON([]byte(`[
+		["_int", [[0, 7]]],
+		["_choice", [[0, 2], {"1": ["m_b", 2], "0": ["m_a", 0]}]],
+		["_array", [[0, 5], 0]],
+		["_optional", [1]],
+		["_blob", [[0, 8]]]
+	]`))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(tt, ttJSON) {
+		t.Errorf("Python and JSON type tables differ!")
+	}
+
+	cases := []string{
+		``,
+		`{}`,
+		`[["_kitty", []]]`,
+		`[["_optional", [1]]]`,
+		`[["_choice", [[0, 2], {"5": ["m_a", 0]}]]]`,
+	}
+	for _, c := range cases {
+		if tt, err := ParseTypeTableJSON([]byte(c)); tt != nil || err == nil {
+			t.Errorf("Expected error for: %s", c)
+		}
+	}
+}
+
+func TestStrictMode(t *testing.T) {
+	tt, err := ParseTypeTable([]string{
+		"('_int',[(0,8)]),  #0",
+		"('_struct',[[('m_x',0,0)]]),  #1",
+		"('_choice',[(0,2),{0:('m_a',0),1:('m_b',0)}]),  #2",
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	cases := []struct {
+		name      string
+		newDec    func([]byte, *TypeTable) *Decoder
+		data      []byte
+		typeid    int
+		nonStrict interface{} // Expected result in non-strict mode
+		eof       bool        // Tells if all data must be consumed in non-strict mode
+	}{
+		// Choice tag 2 == number of choice fields: out of range
+		{"bit-packed choice", NewBitPackedDecoder, []byte{0x02, 0x00}, 2, nil, false},
+		{"versioned choice", NewVersionedDecoder, []byte{0x03, 0x04, 0x09, 0x02}, 2, nil, true},
+		// Choice with a negative tag -1
+		{"versioned negative choice", NewVersionedDecoder, []byte{0x03, 0x03, 0x09, 0x02}, 2, nil, true},
+		// Struct with an unknown field tag 1
+		{"versioned struct", NewVersionedDecoder, []byte{0x05, 0x02, 0x02, 0x09, 0x02}, 1, Struct{}, true},
+	}
+
+	for _, c := range cases {
+		d := c.newDec(c.data, tt)
+		v, err := d.Decode(c.typeid)
+		if err != nil || !reflect.DeepEqual(v, c.nonStrict) {
+			t.Errorf("[%s] Expected: %v, got: %v (err: %v)", c.name, c.nonStrict, v, err)
+		}
+		if c.eof && !d.EOF() {
+			t.Errorf("[%s] Expected all data to be consumed!", c.name)
+		}
+
+		d = c.newDec(c.data, tt)
+		d.SetStrict(true)
+		if _, err := d.Decode(c.typeid); err == nil {
+			t.Errorf("[%s] Expected validation error!", c.name)
+		} else if _, ok := err.(*ValidationError); !ok {
+			t.Errorf("[%s] Expected *ValidationError, got: %T", c.name, err)
+		}
+	}
+}
+
+func TestStrictTrailing(t *testing.T) {
+	p := minHeaderProtocol.WithStrict(true)
+	if !p.Strict() || minHeaderProtocol.Strict() {
+		t.Errorf("WithStrict must not modify the original protocol!")
+	}
+
+	tt := p.TypeTable()
+	if tt.Len() != len(headerTypeInfoSrcs) {
+		t.Errorf("Expected %d types, got: %d", len(headerTypeInfoSrcs), tt.Len())
+	}
+
+	// Empty struct followed by a trailing byte (first 4 bytes are skipped):
+	data := []byte{0x3c, 0, 0, 0, 0x05, 0x00, 0xff}
+	padded := []byte{0x3c, 0, 0, 0, 0x05, 0x00, 0x00, 0x00}
+	if s := DecodeHeaderWith(minHeaderProtocol, data); s == nil {
+		t.Errorf("Expected non-nil header!")
+	}
+
+	func() {
+		defer func() {
+			if _, ok := recover().(*ValidationError); !ok {
+				t.Errorf("Expected panic with *ValidationError!")
+			}
+		}()
+		DecodeHeaderWith(p, data)
+	}()
+
+	// Zero padding of the user data is allowed:
+	if s := DecodeHeaderWith(p, padded); s == nil {
+		t.Errorf("Expected non-nil header!")
+	}
+
+	// As in real replays: a full header in the user data padded to 512 bytes:
+	full := GetProtocol(80949)
+	userData, err := EncodeHeaderWith(full, Struct{
+		"signature": "StarCraft II replay\x1b11",
+		"version":   Struct{"major": int64(5), "baseBuild": int64(80949)},
+		"type":      int64(2),
+	})
+	if err != nil {
+		t.Fatalf("Failed to encode header: %v", err)
+	}
+	userData = append(userData, make([]byte, 512-len(userData))...)
+	if s := DecodeHeaderWith(full.WithStrict(true), userData); s.Int("version", "baseBuild") != 80949 {
+		t.Errorf("Unexpected header: %v", s)
+	}
+}
@@ -0,0 +1,183 @@
+/*
+
+Comparing protocols of different base builds.
+
+*/
+
+package s2prot
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ProtocolDiff describes the differences between 2 protocols.
+type ProtocolDiff struct {
+	OldBaseBuild int // Base build of the old protocol
+	NewBaseBuild int // Base build of the new protocol
+
+	GameEvts    EvtTypesDiff // Differences of the game event types
+	MessageEvts EvtTypesDiff // Differences of the message event types
+	TrackerEvts EvtTypesDiff // Differences of the tracker event types
+}
+
+// EvtTypesDiff describes the differences of a group of event types (e.g. game events).
+// Event types are matched by event id.
+type EvtTypesDiff struct {
+	Added   []EvtType       // Event types only present in the new protocol
+	Removed []EvtType       // Event types only present in the old protocol
+	Renamed []EvtTypeRename // Event types whose name changed
+	Changed []EvtTypeChange // Event types whose data structure changed
+}
+
+// EvtTypeRename describes an event id whose name changed.
+type EvtTypeRename struct {
+	ID      int    // Id of the event
+	OldName string // Name in the old protocol
+	NewName string // Name in the new protocol
+}
+
+// EvtTypeChange describes the changed fields of an event type.
+type EvtTypeChange struct {
+	ID   int    // Id of the event
+	Name string // Name of the event (in the new protocol)
+
+	AddedFields   []FieldInfo // Fields only present in the new protocol
+	RemovedFields []FieldInfo // Fields only present in the old protocol
+	ChangedFields []FieldInfo // Fields whose type changed, Type is the type in the new protocol
+}
+
+// Empty tells if there are no differences.
+func (d *EvtTypesDiff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Renamed) == 0 && len(d.Changed) == 0
+}
+
+// Empty tells if the protocols are equivalent regarding their events.
+func (d *ProtocolDiff) Empty() bool {
+	return d.GameEvts.Empty() && d.MessageEvts.Empty() && d.TrackerEvts.Empty()
+}
+
+// DiffProtocols compares 2 protocols and returns their differences.
+func DiffProtocols(oldp, newp *Protocol) *ProtocolDiff {
+	return &ProtocolDiff{
+		OldBaseBuild: oldp.baseBuild,
+		NewBaseBuild: newp.baseBuild,
+		GameEvts:     diffEvtTypes(oldp, newp, oldp.gameEvtTypes, newp.gameEvtTypes),
+		MessageEvts:  diffEvtTypes(oldp, newp, oldp.messageEvtTypes, newp.messageEvtTypes),
+		TrackerEvts:  diffEvtTypes(oldp, newp, oldp.trackerEvtTypes, newp.trackerEvtTypes),
+	}
+}
+
+// diffEvtTypes compares 2 event type slices where index is the event id.
+func diffEvtTypes(oldp, newp *Protocol, olds, news []EvtType) (d EvtTypesDiff) {
+	count := len(olds)
+	if len(news) > count {
+		count = len(news)
+	}
+
+	for id := 0; id < count; id++ {
+		var o, n *EvtType
+		if id < len(olds) && olds[id].Name != "" {
+			o = &olds[id]
+		}
+		if id < len(news) && news[id].Name != "" {
+			n = &news[id]
+		}
+
+		switch {
+		case o == nil && n == nil:
+			continue
+		case o == nil:
+			d.Added = append(d.Added, *n)
+			continue
+		case n == nil:
+			d.Removed = append(d.Removed, *o)
+			continue
+		case o.Name != n.Name:
+			d.Renamed = append(d.Renamed, EvtTypeRename{ID: id, OldName: o.Name, NewName: n.Name})
+		}
+
+		if c := diffFields(oldp.EvtFields(o), newp.EvtFields(n)); c != nil {
+			c.ID, c.Name = id, n.Name
+			d.Changed = append(d.Changed, *c)
+		}
+	}
+
+	return
+}
+
+// diffFields compares 2 field lists. Fields are matched by name.
+// nil is returned if there are no differences.
+func diffFields(olds, news []FieldInfo) *EvtTypeChange {
+	c := &EvtTypeChange{}
+
+	oldTypes := make(map[string]string, len(olds))
+	for _, f := range olds {
+		oldTypes[f.Name] = f.Type
+	}
+	newNames := make(map[string]bool, len(news))
+	for _, f := range news {
+		newNames[f.Name] = true
+		if t, ok := oldTypes[f.Name]; !ok {
+			c.AddedFields = append(c.AddedFields, f)
+		} else if t != f.Type {
+			c.ChangedFields = append(c.ChangedFields, f)
+		}
+	}
+	for _, f := range olds {
+		if !newNames[f.Name] {
+			c.RemovedFields = append(c.RemovedFields, f)
+		}
+	}
+
+	if len(c.AddedFields) == 0 && len(c.RemovedFields) == 0 && len(c.ChangedFields) == 0 {
+		return nil
+	}
+	return c
+}
+
+// String returns a human-readable report of the differences.
+func (d *ProtocolDiff) String() string {
+	sb := &strings.Builder{}
+	fmt.Fprintf(sb, "Protocol differences %d => %d\n", d.OldBaseBuild, d.NewBaseBuild)
+	if d.Empty() {
+		sb.WriteString("No differences.\n")
+		return sb.String()
+	}
+
+	d.GameEvts.writeReport(sb, "Game events")
+	d.MessageEvts.writeReport(sb, "Message events")
+	d.TrackerEvts.writeReport(sb, "Tracker events")
+
+	return sb.String()
+}
+
+// writeReport writes a human-readable report of the differences.
+func (d *EvtTypesDiff) writeReport(sb *strings.Builder, title string) {
+	if d.Empty() {
+		return
+	}
+
+	fmt.Fprintf(sb, "%s:\n", title)
+	for _, e := range d.Added {
+		fmt.Fprintf(sb, "\t+ %d %s\n", e.ID, e.Name)
+	}
+	for _, e := range d.Removed {
+		fmt.Fprintf(sb, "\t- %d %s\n", e.ID, e.Name)
+	}
+	for _, r := range d.Renamed {
+		fmt.Fprintf(sb, "\t~ %d %s => %s\n", r.ID, r.OldName, r.NewName)
+	}
+	for _, c := range d.Changed {
+		fmt.Fprintf(sb, "\t* %d %s\n", c.ID, c.Name)
+		for _, f := range c.AddedFields {
+			fmt.Fprintf(sb, "\t\t+ %s: %s\n", f.Name, f.Type)
+		}
+		for _, f := range c.RemovedFields {
+			fmt.Fprintf(sb, "\t\t- %s: %s\n", f.Name, f.Type)
+		}
+		for _, f := range c.ChangedFields {
+			fmt.Fprintf(sb, "\t\t* %s: %s\n", f.Name, f.Type)
+		}
+	}
+}
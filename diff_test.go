@@ -0,0 +1,19 @@
+package s2prot
+
+import "testing"
+
+func TestDiffProtocols(t *testing.T) {
+	p := GetProtocol(80949)
+	if d := DiffProtocols(p, p); !d.Empty() {
+		t.Errorf("Expected no differences, got:\n%v", d)
+	}
+
+	// Tracker events were introduced in base build 24944:
+	d := DiffProtocols(GetProtocol(23260), GetProtocol(24944))
+	if len(d.TrackerEvts.Added) == 0 {
+		t.Errorf("Expected added tracker events, got:\n%v", d)
+	}
+	if len(d.TrackerEvts.Removed) != 0 {
+		t.Errorf("Expected no removed tracker events, got:\n%v", d)
+	}
+}
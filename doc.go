@@ -69,10 +69,10 @@ Output:
 	  "dataBuildNum": 42253,
 	  "elapsedGameLoops": 13804,
 	  "ngdpRootKey": {
-	    "data": "\ufffd \ufffd\ufffd\ufffd\ufffd]\ufffd\ufffd\ufffd\ufffd\ufffd\ufffd..."
+	    "data": "0xe120b5a488875df1e08ebdf3adf2a2ef"
 	  },
 	  "replayCompatibilityHash": {
-	    "data": "\ufffd\ufffd\ufffd'⌂\u001fv\ufffd%\rEĪѓX"
+	    "data": "0x9b9591277f1f76b5250d45c4aad19358"
 	  },
 	  "signature": "StarCraft II replay\u001b11",
 	  "type": 2,
@@ -139,6 +139,22 @@ Tip: We can of course print the whole decoded header which is a Struct:
 
 Which yields a JSON text similar to the one posted above (at High-level Usage).
 
+Decoded data can also be encoded back using the same Protocol, e.g. after modifying the Details:
+
+	details["title"] = "Renamed map"
+	detailsData, err = p.EncodeDetails(details)
+
+Encoding is the exact inverse of decoding: re-encoding unmodified data yields the original bytes
+(except for attributes events whose order is not preserved by decoding).
+
+Other games using the same framework (e.g. Heroes of the Storm, .StormReplay files) are supported through Game:
+the game of a replay is detected from the header signature by GameOf, and Game.GetProtocol returns
+the protocols of the game from its own set of base builds. Python protocol sources of such games
+are not embedded, they have to be added to Game.Builds (e.g. by a game specific package):
+
+	s2prot.GameHeroes.Builds[83716] = heroesProtocolSrc
+	p := s2prot.GameOf(header).GetProtocol(83716)
+
 
 Information sources
 
@@ -0,0 +1,281 @@
+/*
+
+Encoding Structs and events back to their binary form, the inverse of decoding.
+
+*/
+
+package s2prot
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+)
+
+// Type encoder defines the methods an encoder must support.
+type encoder interface {
+	byteAlign()
+	bytes() []byte
+	instance(typeid int, v interface{}) error
+}
+
+// EncodeHeader encodes the replay header, the inverse of DecodeHeader.
+// The protocol used for encoding can be configured with SetHeaderProtocol.
+func EncodeHeader(header Struct) ([]byte, error) {
+	protMux.Lock()
+	p := headerProtocol
+	protMux.Unlock()
+
+	if p == nil {
+		p = minHeaderProtocol
+	}
+
+	return EncodeHeaderWith(p, header)
+}
+
+// EncodeHeaderWith encodes the replay header using the specified protocol, the inverse of DecodeHeaderWith.
+// The result is prefixed with the length of the encoded header (just like the MPQ user data of replays),
+// so it can be used as the MPQ user data.
+func EncodeHeaderWith(p *Protocol, header Struct) ([]byte, error) {
+	data, err := p.encode(newVersionedEnc(p.typeInfos), p.replayHeaderTypeid, header)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]byte, 4, 4+len(data))
+	binary.LittleEndian.PutUint32(res, uint32(len(data)))
+	return append(res, data...), nil
+}
+
+// EncodeDetails encodes the game details, the inverse of DecodeDetails.
+func (p *Protocol) EncodeDetails(details Struct) ([]byte, error) {
+	return p.encode(newVersionedEnc(p.typeInfos), p.gameDetailsTypeid, details)
+}
+
+// EncodeInitData encodes the replay init data, the inverse of DecodeInitData.
+func (p *Protocol) EncodeInitData(initData Struct) ([]byte, error) {
+	return p.encode(newBitPackedEnc(p.typeInfos), p.replayInitdataTypeid, initData)
+}
+
+// EncodeAttributesEvts encodes the attributes events, the inverse of DecodeAttributesEvts.
+// Attributes are written ordered by scope and attribute id.
+func (p *Protocol) EncodeAttributesEvts(attrEvts Struct) ([]byte, error) {
+	// Helper function to sort the numeric keys of a Struct
+	sortedKeys := func(s Struct) (keys []int64, err error) {
+		for k := range s {
+			n, err := strconv.ParseInt(k, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid key: %q", k)
+			}
+			keys = append(keys, n)
+		}
+		sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+		return
+	}
+
+	scopes := attrEvts.Structv("scopes")
+	scopeIDs, err := sortedKeys(scopes)
+	if err != nil {
+		return nil, err
+	}
+
+	var data, attrsData []byte
+	var count uint32
+	for _, scopeID := range scopeIDs {
+		scope := scopes.Structv(strconv.FormatInt(scopeID, 10))
+		attrIDs, err := sortedKeys(scope)
+		if err != nil {
+			return nil, err
+		}
+		for _, attrID := range attrIDs {
+			attr := scope.Structv(strconv.FormatInt(attrID, 10))
+			value := attr.Stringv("value")
+			if len(value) > 4 {
+				return nil, fmt.Errorf("attribute value too long: %q", value)
+			}
+			// Value is stored reversed, padded with zeros:
+			var vb [4]byte
+			for i := 0; i < len(value); i++ {
+				vb[i] = value[len(value)-1-i]
+			}
+			attrsData = appendUint32(attrsData, uint32(attr.Int("namespace")))
+			attrsData = appendUint32(attrsData, uint32(attrID))
+			attrsData = append(attrsData, byte(scopeID))
+			attrsData = append(attrsData, vb[:]...)
+			count++
+		}
+	}
+
+	// Source is only present from 1.2 and onward (base build 17326)
+	if p.baseBuild >= 17326 {
+		data = append(data, byte(attrEvts.Int("source")))
+	}
+	data = appendUint32(data, uint32(attrEvts.Int("mapNamespace")))
+	data = appendUint32(data, count)
+
+	return append(data, attrsData...), nil
+}
+
+// EncodeGameEvts encodes the game events, the inverse of DecodeGameEvts.
+// Events must be in chronological order.
+func (p *Protocol) EncodeGameEvts(events []Event) ([]byte, error) {
+	return p.encodeEvts(newBitPackedEnc(p.typeInfos), p.gameEventidTypeid, p.gameEvtTypes, true, events)
+}
+
+// EncodeMessageEvts encodes the message events, the inverse of DecodeMessageEvts.
+// Events must be in chronological order.
+func (p *Protocol) EncodeMessageEvts(events []Event) ([]byte, error) {
+	return p.encodeEvts(newBitPackedEnc(p.typeInfos), p.messageEventidTypeid, p.messageEvtTypes, true, events)
+}
+
+// EncodeTrackerEvts encodes the tracker events, the inverse of DecodeTrackerEvts.
+// Events must be in chronological order.
+func (p *Protocol) EncodeTrackerEvts(events []Event) ([]byte, error) {
+	if !p.hasTrackerEvents {
+		return nil, fmt.Errorf("protocol %d has no tracker events", p.baseBuild)
+	}
+	return p.encodeEvts(newVersionedEnc(p.typeInfos), p.trackerEventidTypeid, p.trackerEvtTypes, false, events)
+}
+
+// encode encodes a single value.
+func (p *Protocol) encode(e encoder, typeid int, v interface{}) ([]byte, error) {
+	if err := e.instance(typeid, v); err != nil {
+		return nil, err
+	}
+	return e.bytes(), nil
+}
+
+// encodeEvts encodes a series of events.
+// The event type of an event is looked up by name in etypes, so events do not have to originate from this protocol.
+func (p *Protocol) encodeEvts(e encoder, evtidTypeid int, etypes []EvtType, encUserID bool, events []Event) ([]byte, error) {
+	evtids := make(map[string]int, len(etypes))
+	for i := range etypes {
+		if etypes[i].Name != "" {
+			evtids[etypes[i].Name] = i
+		}
+	}
+
+	var loop int64
+	for i, evt := range events {
+		if evt.EvtType == nil {
+			return nil, fmt.Errorf("event #%d has no type", i)
+		}
+		evtid, ok := evtids[evt.Name]
+		if !ok {
+			return nil, fmt.Errorf("event #%d: unknown event type %q", i, evt.Name)
+		}
+
+		evtLoop := evt.Loop()
+		if evtLoop < loop {
+			return nil, fmt.Errorf("event #%d: loop %d precedes loop of previous event %d", i, evtLoop, loop)
+		}
+		delta, err := p.deltaChoice(evtLoop - loop)
+		if err != nil {
+			return nil, fmt.Errorf("event #%d: %v", i, err)
+		}
+		loop = evtLoop
+
+		if err = e.instance(p.svaruint32Typeid, delta); err == nil && encUserID {
+			err = e.instance(p.replayUseridTypeid, evt.UserIDValue())
+		}
+		if err == nil {
+			err = e.instance(evtidTypeid, int64(evtid))
+		}
+		if err == nil {
+			err = e.instance(etypes[evtid].typeid, evt.Struct)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("event #%d (%s): %v", i, evt.Name, err)
+		}
+
+		// The next event is byte-aligned:
+		e.byteAlign()
+	}
+
+	return e.bytes(), nil
+}
+
+// deltaChoice returns the loop delta value in the smallest fitting form of the svaruint32 choice type.
+func (p *Protocol) deltaChoice(delta int64) (Struct, error) {
+	for _, f := range p.typeInfos[p.svaruint32Typeid].fields {
+		if ti := &p.typeInfos[f.typeid]; fitsInt(ti, delta) {
+			return Struct{f.name: delta}, nil
+		}
+	}
+	return nil, fmt.Errorf("loop delta %d out of range", delta)
+}
+
+// fitsInt tells if n can be encoded as an int of the specified type (by the bit-packed encoder).
+func fitsInt(ti *typeInfo, n int64) bool {
+	if ti.bits >= 64 {
+		return true // All bits are stored, any value fits (decoded the same way)
+	}
+	n -= ti.offset64
+	return n >= 0 && n < 1<<uint(ti.bits)
+}
+
+// soleParent returns the only field of a struct type if it is a "__parent" field of non-struct type.
+// Such structs are decoded to the value of the parent.
+func soleParent(typeInfos []typeInfo, ti *typeInfo) (*field, bool) {
+	if len(ti.fields) != 1 || !ti.fields[0].isNameParent {
+		return nil, false
+	}
+	if typeInfos[ti.fields[0].typeid].s2pType == s2pStruct {
+		return nil, false
+	}
+	return &ti.fields[0], true
+}
+
+// choiceOf returns the tag and the value of a choice value (a Struct with a single key).
+func choiceOf(ti *typeInfo, typeid int, v interface{}) (tag int, fv interface{}, err error) {
+	s := asStruct(v)
+	if len(s) != 1 {
+		return 0, nil, typeMismatch(typeid, v)
+	}
+	for i, f := range ti.fields {
+		if fv, ok := s[f.name]; ok {
+			return i, fv, nil
+		}
+	}
+	return 0, nil, fmt.Errorf("unknown choice in %v (typeid %d)", s, typeid)
+}
+
+// appendUint32 appends a uint32 in little endian byte order.
+func appendUint32(data []byte, v uint32) []byte {
+	var b [4]byte
+	binary.LittleEndian.PutUint32(b[:], v)
+	return append(data, b[:]...)
+}
+
+// toInt converts a numeric value to int64. Floating point numbers are only accepted if they hold an integer.
+func toInt(v interface{}) (int64, bool) {
+	switch n := v.(type) {
+	case int64:
+		return n, true
+	case int:
+		return int64(n), true
+	case float64:
+		if n == math.Trunc(n) && math.Abs(n) < 1<<63 {
+			return int64(n), true
+		}
+	}
+	return 0, false
+}
+
+// toBytes converts a blob value (string or []byte) to []byte.
+func toBytes(v interface{}) ([]byte, bool) {
+	switch b := v.(type) {
+	case string:
+		return []byte(b), true
+	case []byte:
+		return b, true
+	}
+	return nil, false
+}
+
+// typeMismatch returns an error reporting that a value cannot be encoded as the specified type.
+func typeMismatch(typeid int, v interface{}) error {
+	return fmt.Errorf("cannot encode value of type %T (typeid %d)", v, typeid)
+}
@@ -0,0 +1,336 @@
+package s2prot
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestBitPackedWriter(t *testing.T) {
+	w := &bitPackedWriter{}
+	w.writeBits(1, 1)
+	w.writeBits(0x5a3, 11)
+	w.writeUnaligned([]byte{0xc3, 0x17})
+	w.writeBits(-1, 3)
+	w.writeAligned([]byte{0x42})
+	w.writeBits(0x12345678, 32)
+
+	b := &bitPackedBuff{contents: w.bytes(), bigEndian: true}
+	if v := b.readBits(1); v != 1 {
+		t.Errorf("Expected: %x, got: %x", 1, v)
+	}
+	if v := b.readBits(11); v != 0x5a3 {
+		t.Errorf("Expected: %x, got: %x", 0x5a3, v)
+	}
+	if v := b.readUnaligned(2); !reflect.DeepEqual(v, []byte{0xc3, 0x17}) {
+		t.Errorf("Expected: %x, got: %x", []byte{0xc3, 0x17}, v)
+	}
+	if v := b.readBits(3); v != 7 {
+		t.Errorf("Expected: %x, got: %x", 7, v)
+	}
+	if v := b.readAligned(1); !reflect.DeepEqual(v, []byte{0x42}) {
+		t.Errorf("Expected: %x, got: %x", []byte{0x42}, v)
+	}
+	if v := b.readBits(32); v != 0x12345678 {
+		t.Errorf("Expected: %x, got: %x", 0x12345678, v)
+	}
+	if !b.EOF() {
+		t.Error("Expected EOF!")
+	}
+}
+
+func TestWriteVarInt(t *testing.T) {
+	for _, n := range []int64{0, 1, -1, 63, 64, -64, 1000, -123456, 1 << 40, -(1 << 61)} {
+		w := &bitPackedWriter{}
+		writeVarInt(w, n)
+		if v := readVarInt(&bitPackedBuff{contents: w.bytes(), bigEndian: true}); v != n {
+			t.Errorf("Expected: %d, got: %d", n, v)
+		}
+	}
+}
+
+// instanceGen generates random instances of types, decoding to the same value as generated.
+type instanceGen struct {
+	p *Protocol
+	r *rand.Rand
+}
+
+// intOf returns a random int of the type, at most max above the offset.
+func (g *instanceGen) intOf(ti *typeInfo, max int64) int64 {
+	if ti.bits > 62 {
+		return g.r.Int63n(max+1) - max/2 // Full int64 range: values around 0
+	}
+	limit := int64(1) << uint(ti.bits)
+	if max < limit {
+		limit = max + 1
+	}
+	return ti.offset64 + g.r.Int63n(limit)
+}
+
+// instance returns a random instance of the type. Arrays are kept short in deeper levels.
+func (g *instanceGen) instance(typeid, depth int) interface{} {
+	ti := &g.p.typeInfos[typeid]
+	switch ti.s2pType {
+	case s2pInt:
+		return g.intOf(ti, 1<<40)
+	case s2pStruct:
+		s := Struct{}
+		for _, f := range ti.fields {
+			v := g.instance(f.typeid, depth+1)
+			if f.isNameParent {
+				if s2, ok := v.(Struct); ok {
+					for k, v := range s2 {
+						s[k] = v
+					}
+					continue
+				} else if len(ti.fields) == 1 {
+					return v
+				}
+			}
+			s[f.name] = v
+		}
+		return s
+	case s2pChoice:
+		f := ti.fields[g.r.Intn(len(ti.fields))]
+		return Struct{f.name: g.instance(f.typeid, depth+1)}
+	case s2pArr:
+		max := int64(3)
+		if depth > 4 {
+			max = 0
+		}
+		arr := make([]interface{}, g.intOf(ti, max))
+		for i := range arr {
+			arr[i] = g.instance(ti.typeid, depth+1)
+		}
+		return arr
+	case s2pBitArr:
+		count := int(g.intOf(ti, 20))
+		data := make([]byte, (count+7)/8)
+		g.r.Read(data)
+		if remaining := count % 8; remaining != 0 {
+			data[len(data)-1] &= bitMasks[remaining]
+		}
+		return BitArr{Count: count, Data: data}
+	case s2pBlob:
+		data := make([]byte, g.intOf(ti, 8))
+		g.r.Read(data)
+		return string(data)
+	case s2pOptional:
+		if g.r.Intn(2) == 0 {
+			return nil
+		}
+		return g.instance(ti.typeid, depth+1)
+	case s2pBool:
+		return g.r.Intn(2) == 0
+	case s2pFourCC:
+		data := make([]byte, 4)
+		g.r.Read(data)
+		return string(data)
+	}
+	return nil
+}
+
+// Base builds of the protocols used for testing the encoders.
+var encodeTestBuilds = []int{15405, 39576, 47185, 80949}
+
+func TestEncodeRoundTrip(t *testing.T) {
+	for _, bb := range encodeTestBuilds {
+		p := GetProtocol(bb)
+		g := &instanceGen{p: p, r: rand.New(rand.NewSource(int64(bb)))}
+
+		for i := 0; i < 20; i++ {
+			details := g.instance(p.gameDetailsTypeid, 0).(Struct)
+			data, err := p.EncodeDetails(details)
+			if err != nil {
+				t.Fatalf("[%d] Failed to encode details: %v", bb, err)
+			}
+			if got := p.DecodeDetails(data); !reflect.DeepEqual(got, details) {
+				t.Errorf("[%d] Details mismatch, expected: %v, got: %v", bb, details, got)
+			}
+
+			initData := g.instance(p.replayInitdataTypeid, 0).(Struct)
+			if data, err = p.EncodeInitData(initData); err != nil {
+				t.Fatalf("[%d] Failed to encode init data: %v", bb, err)
+			}
+			if got := p.DecodeInitData(data); !reflect.DeepEqual(got, initData) {
+				t.Errorf("[%d] Init data mismatch, expected: %v, got: %v", bb, initData, got)
+			}
+		}
+	}
+}
+
+func TestEncodeHeader(t *testing.T) {
+	for _, bb := range encodeTestBuilds {
+		p := GetProtocol(bb)
+		g := &instanceGen{p: p, r: rand.New(rand.NewSource(int64(bb)))}
+
+		header := g.instance(p.replayHeaderTypeid, 0).(Struct)
+		data, err := EncodeHeaderWith(p, header)
+		if err != nil {
+			t.Fatalf("[%d] Failed to encode header: %v", bb, err)
+		}
+		if got := DecodeHeaderWith(p, data); !reflect.DeepEqual(got, header) {
+			t.Errorf("[%d] Header mismatch, expected: %v, got: %v", bb, header, got)
+		}
+		if got := int(data[0]) | int(data[1])<<8; got != len(data)-4 {
+			t.Errorf("[%d] Expected length prefix: %d, got: %d", bb, len(data)-4, got)
+		}
+	}
+}
+
+// randomEvts returns random events of all types of an event group.
+func (g *instanceGen) randomEvts(etypes []EvtType, userID bool) (evts []Event) {
+	var loop int64
+	for round := 0; round < 5; round++ {
+		for id := range etypes {
+			et := &etypes[id]
+			if et.Name == "" {
+				continue
+			}
+			loop += g.r.Int63n(5000)
+			s := g.instance(et.typeid, 0).(Struct)
+			s["id"] = int64(id)
+			s["evtTypeName"] = et.Name
+			s["loop"] = loop
+			if userID {
+				s["userid"] = g.instance(g.p.replayUseridTypeid, 0)
+			}
+			evts = append(evts, Event{Struct: s, EvtType: et})
+		}
+	}
+	return
+}
+
+func TestEncodeEvts(t *testing.T) {
+	for _, bb := range encodeTestBuilds {
+		p := GetProtocol(bb)
+		g := &instanceGen{p: p, r: rand.New(rand.NewSource(int64(bb)))}
+
+		type evtsCase struct {
+			name   string
+			evts   []Event
+			encode func([]Event) ([]byte, error)
+			decode func([]byte) ([]Event, error)
+		}
+		cases := []evtsCase{
+			{"game", g.randomEvts(p.gameEvtTypes, true), p.EncodeGameEvts, p.DecodeGameEvts},
+			{"message", g.randomEvts(p.messageEvtTypes, true), p.EncodeMessageEvts, p.DecodeMessageEvts},
+		}
+		if p.HasTrackerEvents() {
+			cases = append(cases, evtsCase{"tracker", g.randomEvts(p.trackerEvtTypes, false), p.EncodeTrackerEvts, p.DecodeTrackerEvts})
+		}
+
+		for _, c := range cases {
+			data, err := c.encode(c.evts)
+			if err != nil {
+				t.Fatalf("[%d] Failed to encode %s events: %v", bb, c.name, err)
+			}
+			got, err := c.decode(data)
+			if err != nil {
+				t.Fatalf("[%d] Failed to decode %s events: %v", bb, c.name, err)
+			}
+			if !reflect.DeepEqual(got, c.evts) {
+				t.Errorf("[%d] %s events mismatch", bb, c.name)
+			}
+		}
+	}
+}
+
+func TestChoiceInt(t *testing.T) {
+	p := GetProtocol(80949)
+	typeid := p.svaruint32Typeid
+	for _, delta := range []int64{0, 1, 63, 64, 16383, 16384, 1 << 22, 1<<32 - 1} {
+		v, err := p.deltaChoice(delta)
+		if err != nil {
+			t.Fatalf("[%d] Failed to create delta: %v", delta, err)
+		}
+
+		be := newBitPackedEnc(p.typeInfos)
+		ve := newVersionedEnc(p.typeInfos)
+		if err := be.instance(typeid, v); err != nil {
+			t.Fatal(err)
+		}
+		if err := ve.instance(typeid, v); err != nil {
+			t.Fatal(err)
+		}
+		decs := []decoder{newBitPackedDec(be.bytes(), p.typeInfos), newVersionedDec(ve.bytes(), p.typeInfos)}
+		for i, d := range decs {
+			got := d.choiceInt(typeid)
+			d.byteAlign()
+			if got != delta || !d.EOF() {
+				t.Errorf("[%d, %d] Expected: %d, got: %d", delta, i, delta, got)
+			}
+		}
+	}
+}
+
+func TestChoiceIntInvalid(t *testing.T) {
+	p := GetProtocol(80949)
+	for _, c := range []struct {
+		name string
+		data []byte
+	}{
+		{"negative tag", []byte{0x03, 0x03, 0x09, 0x02}},
+		{"unknown tag", []byte{0x03, 0x7e, 0x09, 0x02}},
+		{"not a choice", []byte{0x09, 0x00, 0x09, 0x02}},
+		{"not an int", []byte{0x03, 0x00, 0x02, 0x02}},
+	} {
+		func() {
+			defer func() {
+				if _, ok := recover().(*ValidationError); !ok {
+					t.Errorf("[%s] Expected *ValidationError panic", c.name)
+				}
+			}()
+			newVersionedDec(c.data, p.typeInfos).choiceInt(p.svaruint32Typeid)
+		}()
+	}
+}
+
+func TestEncodeAttributesEvts(t *testing.T) {
+	for _, bb := range []int{15405, 80949} {
+		p := GetProtocol(bb)
+		attrEvts := Struct{
+			"mapNamespace": int64(999),
+			"scopes": Struct{
+				"1": Struct{
+					"500":  Struct{"namespace": int64(999), "attrid": int64(500), "value": "Humn"},
+					"3001": Struct{"namespace": int64(999), "attrid": int64(3001), "value": "Prot"},
+				},
+				"16": Struct{
+					"2001": Struct{"namespace": int64(999), "attrid": int64(2001), "value": "1v1"},
+					"3000": Struct{"namespace": int64(999), "attrid": int64(3000), "value": ""},
+				},
+			},
+		}
+		if bb >= 17326 {
+			attrEvts["source"] = int64(0)
+		}
+
+		data, err := p.EncodeAttributesEvts(attrEvts)
+		if err != nil {
+			t.Fatalf("[%d] Failed to encode attributes events: %v", bb, err)
+		}
+		if got := p.DecodeAttributesEvts(data); !reflect.DeepEqual(got, attrEvts) {
+			t.Errorf("[%d] Attributes events mismatch, expected: %v, got: %v", bb, attrEvts, got)
+		}
+	}
+}
+
+func TestEncodeErrors(t *testing.T) {
+	p := GetProtocol(80949)
+
+	if _, err := p.EncodeInitData(Struct{}); err == nil {
+		t.Error("Expected error for missing fields!")
+	}
+
+	evts := []Event{
+		{Struct: Struct{"loop": int64(10)}, EvtType: &EvtType{Name: "Ping"}},
+		{Struct: Struct{"loop": int64(5)}, EvtType: &EvtType{Name: "Ping"}},
+	}
+	if _, err := p.EncodeMessageEvts(evts); err == nil {
+		t.Error("Expected error for decreasing loops!")
+	}
+	if _, err := p.EncodeMessageEvts([]Event{{Struct: Struct{}, EvtType: &EvtType{Name: "NoSuchEvent"}}}); err == nil {
+		t.Error("Expected error for unknown event type!")
+	}
+}
@@ -0,0 +1,111 @@
+/*
+
+Games using the s2protocol framework, each having its own set of protocols.
+
+*/
+
+package s2prot
+
+import (
+	"strings"
+
+	"github.com/icza/s2prot/build"
+)
+
+// Game describes a game whose replays are encoded with the s2protocol framework,
+// e.g. StarCraft II or Heroes of the Storm. Each game has its own set of protocols (base builds).
+//
+// Support of other games can be added by registering a Game with RegisterGame, e.g. in a game specific package.
+// All the decoders and encoders of Protocol can be used with protocols of any game.
+type Game struct {
+	Name       string         // Name of the game, e.g. "StarCraft II"
+	Signature  string         // Prefix of the signature in the replay header, e.g. "StarCraft II replay"
+	Builds     map[int]string // Python protocol sources (from Blizzard's s2protocol), mapped from base build
+	Duplicates map[int]int    // Base builds having identical protocol as another one, mapped to the original base build
+
+	protocols map[int]*Protocol // Already parsed protocols mapped from base build, protected by protMux
+}
+
+// Known games.
+var (
+	// GameSC2 is StarCraft II, its protocols are embedded in the build package.
+	GameSC2 = &Game{
+		Name:       "StarCraft II",
+		Signature:  "StarCraft II replay",
+		Builds:     build.Builds,
+		Duplicates: build.Duplicates,
+	}
+
+	// GameHeroes is Heroes of the Storm. No protocols are embedded for it,
+	// add protocol sources to its Builds before use (before the first GetProtocol call for a base build).
+	GameHeroes = &Game{
+		Name:       "Heroes of the Storm",
+		Signature:  "Heroes of the Storm replay",
+		Builds:     map[int]string{},
+		Duplicates: map[int]int{},
+	}
+)
+
+// Registered games, protected by protMux.
+var games = []*Game{GameSC2, GameHeroes}
+
+// RegisterGame registers a game, so it can be detected by GameOf.
+// A game already registered with the same signature is replaced.
+func RegisterGame(g *Game) {
+	protMux.Lock()
+	defer protMux.Unlock()
+
+	for i, g2 := range games {
+		if g2.Signature == g.Signature {
+			games[i] = g
+			return
+		}
+	}
+	games = append(games, g)
+}
+
+// GameOf returns the game of the specified decoded replay header, detected by its signature.
+// nil is returned if the game is unknown.
+func GameOf(header Struct) *Game {
+	signature := header.Stringv("signature")
+
+	protMux.Lock()
+	defer protMux.Unlock()
+
+	for _, g := range games {
+		if strings.HasPrefix(signature, g.Signature) {
+			return g
+		}
+	}
+	return nil
+}
+
+// GetProtocol returns the Protocol of the game for the specified base build.
+// nil return value indicates unknown/unsupported base build.
+func (g *Game) GetProtocol(baseBuild int) *Protocol {
+	protMux.Lock()
+	defer protMux.Unlock()
+
+	return g.getProtocol(baseBuild)
+}
+
+// SetProtocol sets the Protocol of the game for the specified base build, overriding the embedded one (if any).
+// This allows supporting base builds without embedded protocols and without filesystem access,
+// e.g. using a protocol parsed from data fetched over the network (see ParseProtocol)
+// in builds excluding the embedded protocols (see the s2prot_nobuilds build tag of the build package).
+// A nil p removes the override.
+//
+// Note that SetProtocolDir clears the protocols set for StarCraft II.
+func (g *Game) SetProtocol(baseBuild int, p *Protocol) {
+	protMux.Lock()
+	defer protMux.Unlock()
+
+	if g.protocols == nil {
+		g.protocols = make(map[int]*Protocol)
+	}
+	if p == nil {
+		delete(g.protocols, baseBuild)
+		return
+	}
+	g.protocols[baseBuild] = p
+}
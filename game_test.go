@@ -0,0 +1,76 @@
+package s2prot
+
+import (
+	"testing"
+
+	"github.com/icza/s2prot/build"
+)
+
+func TestGameOf(t *testing.T) {
+	cases := []struct {
+		signature string
+		exp       *Game
+	}{
+		{"StarCraft II replay\x1b11", GameSC2},
+		{"Heroes of the Storm replay\x1b11", GameHeroes},
+		{"Unknown replay\x1b11", nil},
+		{"", nil},
+	}
+	for _, c := range cases {
+		if got := GameOf(Struct{"signature": c.signature}); got != c.exp {
+			t.Errorf("[%q] Expected: %v, got: %v", c.signature, c.exp, got)
+		}
+	}
+}
+
+func TestRegisterGame(t *testing.T) {
+	g := &Game{
+		Name:       "Test Game",
+		Signature:  "Test Game replay",
+		Builds:     map[int]string{12345: build.Builds[80949]},
+		Duplicates: map[int]int{12346: 12345},
+	}
+	RegisterGame(g)
+
+	header, err := EncodeHeaderWith(GetProtocol(80949), Struct{
+		"signature": "Test Game replay\x1b11",
+		"version":   Struct{"baseBuild": int64(12345)},
+	})
+	if err != nil {
+		t.Fatalf("Failed to encode header: %v", err)
+	}
+	if got := GameOf(DecodeHeader(header)); got != g {
+		t.Errorf("Expected: %v, got: %v", g, got)
+	}
+
+	for _, bb := range []int{12345, 12346} {
+		p := g.GetProtocol(bb)
+		if p == nil || p.BaseBuild() != bb {
+			t.Errorf("Expected protocol of base build %d, got: %v", bb, p)
+		}
+		if GameSC2.GetProtocol(bb) != nil {
+			t.Errorf("Expected no StarCraft II protocol for base build %d!", bb)
+		}
+	}
+}
+
+func TestSetProtocol(t *testing.T) {
+	const bb = 99999
+	if GameSC2.GetProtocol(bb) != nil {
+		t.Fatalf("Expected no protocol for base build %d!", bb)
+	}
+
+	p, err := ParseProtocol([]byte(build.Builds[80949]), bb)
+	if err != nil {
+		t.Fatalf("Failed to parse protocol: %v", err)
+	}
+	GameSC2.SetProtocol(bb, p)
+	if got := GetProtocol(bb); got != p {
+		t.Errorf("Expected: %v, got: %v", p, got)
+	}
+
+	GameSC2.SetProtocol(bb, nil)
+	if got := GetProtocol(bb); got != nil {
+		t.Errorf("Expected no protocol, got: %v", got)
+	}
+}
@@ -0,0 +1,19 @@
+/*
+
+Generic accessor of Struct values.
+
+*/
+
+package s2prot
+
+// Get returns the value specified by the path, as type T.
+// ok tells if the value exists and is of type T; if not, zero value of T is returned.
+//
+// Example:
+//
+//	baseBuild, ok := s2prot.Get[int64](header, "version", "baseBuild")
+//	name, ok := s2prot.Get[string](details, "title")
+func Get[T any](s Struct, path ...string) (v T, ok bool) {
+	v, ok = s.Value(path...).(T)
+	return
+}
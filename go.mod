@@ -1,5 +1,5 @@
 module github.com/icza/s2prot
 
-go 1.13
+go 1.18
 
 require github.com/icza/mpq v0.0.0-20170726141842-266342679beb
@@ -0,0 +1,105 @@
+/*
+
+Detection of the replay header in the MPQ user data, and validation of its signature.
+
+*/
+
+package s2prot
+
+import "fmt"
+
+// HeaderError is returned by ParseHeader if the MPQ user data is not a valid replay header.
+type HeaderError struct {
+	Prefix    []byte // First bytes of the user data (at most 8)
+	Prefixed  bool   // Tells if the user data has a size prefix (which was skipped)
+	Signature string // Signature found in the header, empty if the header could not be decoded
+	Reason    string // Description of the problem
+}
+
+// Error returns the error message, including what was found.
+func (e *HeaderError) Error() string {
+	msg := "invalid replay header: " + e.Reason
+	if e.Signature != "" {
+		msg += fmt.Sprintf(", signature: %q", e.Signature)
+	}
+	return msg + fmt.Sprintf(", user data prefix: % x", e.Prefix)
+}
+
+// headerData returns the versioned-encoded header struct from the MPQ user data,
+// and tells if the user data has a size prefix.
+//
+// The user data of replays starts with a 4-byte, little endian size prefix (e.g. 3c 00 00 00)
+// followed by the header struct (whose first byte is the struct field type). Some repaired or
+// third-party-generated replays have altered prefixes or no prefix at all, so the prefix is detected
+// by the position of the struct field type, and it is only skipped if present.
+func headerData(contents []byte) (data []byte, prefixed bool) {
+	switch {
+	case len(contents) > 4 && contents[4] == vfStruct:
+		return contents[4:], true
+	case len(contents) > 0 && contents[0] == vfStruct:
+		return contents, false
+	case len(contents) >= 4:
+		return contents[4:], true // Unknown format, assume the prefix
+	}
+	return contents, false
+}
+
+// ParseHeader decodes and returns the replay header, and validates its signature:
+// it must be the signature of a known game (e.g. "StarCraft II replay", see GameOf and RegisterGame).
+// The protocol used for decoding can be configured with SetHeaderProtocol.
+//
+// Unlike DecodeHeader, ParseHeader never panics: if the user data is not a valid replay header,
+// a *HeaderError is returned describing what was found.
+func ParseHeader(contents []byte) (Struct, error) {
+	protMux.Lock()
+	p := headerProtocol
+	protMux.Unlock()
+
+	if p == nil {
+		p = minHeaderProtocol
+	}
+
+	return ParseHeaderWith(p, contents)
+}
+
+// ParseHeaderWith decodes and returns the replay header using the specified protocol, and validates its signature.
+// See ParseHeader.
+func ParseHeaderWith(p *Protocol, contents []byte) (header Struct, err error) {
+	data, prefixed := headerData(contents)
+	he := &HeaderError{Prefixed: prefixed}
+	he.Prefix = append(he.Prefix, contents[:minInt(len(contents), 8)]...)
+
+	switch {
+	case len(contents) == 0:
+		he.Reason = "no user data"
+		return nil, he
+	case len(data) == 0 || data[0] != vfStruct:
+		he.Reason = "no header struct found"
+		return nil, he
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			header, he.Reason = nil, fmt.Sprint("decoding failed: ", r)
+			err = he
+		}
+	}()
+
+	header = decodeHeaderData(p, data)
+	if header == nil {
+		he.Reason = "decoding failed"
+		return nil, he
+	}
+
+	he.Signature = header.Stringv("signature")
+	switch {
+	case he.Signature == "":
+		he.Reason = "missing signature"
+		return nil, he
+	case GameOf(header) == nil:
+		he.Reason = "unknown signature"
+		return nil, he
+	}
+
+	return header, nil
+}
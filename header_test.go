@@ -0,0 +1,80 @@
+package s2prot
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseHeader(t *testing.T) {
+	header := Struct{
+		"signature": "StarCraft II replay\x1b11",
+		"version":   Struct{"flags": int64(1), "major": int64(2), "minor": int64(0), "revision": int64(8), "build": int64(25604), "baseBuild": int64(25604)},
+	}
+	data, err := EncodeHeader(header)
+	if err != nil {
+		t.Fatalf("Failed to encode header: %v", err)
+	}
+	encodeWithSignature := func(signature string) []byte {
+		h := Struct{"signature": signature, "version": header["version"]}
+		d, err := EncodeHeader(h)
+		if err != nil {
+			t.Fatalf("Failed to encode header: %v", err)
+		}
+		return d
+	}
+
+	altered := append([]byte{0xff, 0xff, 0xff, 0xff}, data[4:]...)
+	for _, c := range []struct {
+		name     string
+		data     []byte
+		prefixed bool
+	}{
+		{"standard", data, true},
+		{"altered prefix", altered, true},
+		{"no prefix", data[4:], false},
+	} {
+		got, err := ParseHeader(c.data)
+		if err != nil {
+			t.Errorf("[%s] Unexpected error: %v", c.name, err)
+			continue
+		}
+		if got.Stringv("signature") != header.Stringv("signature") || got.Int("version", "baseBuild") != 25604 {
+			t.Errorf("[%s] Unexpected header: %v", c.name, got)
+		}
+		if got := DecodeHeader(c.data); got.Int("version", "baseBuild") != 25604 {
+			t.Errorf("[%s] DecodeHeader: unexpected header: %v", c.name, got)
+		}
+		if _, prefixed := headerData(c.data); prefixed != c.prefixed {
+			t.Errorf("[%s] Expected prefixed: %v, got: %v", c.name, c.prefixed, prefixed)
+		}
+		if v, err := ProbeVersion(c.data); err != nil || v.BaseBuild != 25604 {
+			t.Errorf("[%s] ProbeVersion: unexpected version: %v, %v", c.name, v, err)
+		}
+	}
+
+	for _, c := range []struct {
+		name      string
+		data      []byte
+		reason    string
+		signature string
+	}{
+		{"empty", nil, "no user data", ""},
+		{"no struct", []byte{0x3c, 0, 0, 0, 0x09, 0x02}, "no header struct found", ""},
+		{"truncated", data[:20], "decoding failed", ""},
+		{"unknown signature", encodeWithSignature("Some other replay"), "unknown signature", "Some other replay"},
+		{"missing signature", encodeWithSignature(""), "missing signature", ""},
+	} {
+		_, err := ParseHeader(c.data)
+		var he *HeaderError
+		if !errors.As(err, &he) {
+			t.Errorf("[%s] Expected *HeaderError, got: %v", c.name, err)
+			continue
+		}
+		if len(he.Reason) < len(c.reason) || he.Reason[:len(c.reason)] != c.reason || he.Signature != c.signature {
+			t.Errorf("[%s] Expected reason: %q, signature: %q, got: %q, %q", c.name, c.reason, c.signature, he.Reason, he.Signature)
+		}
+		if len(c.data) > 0 && he.Prefix[0] != c.data[0] {
+			t.Errorf("[%s] Unexpected prefix: % x", c.name, he.Prefix)
+		}
+	}
+}
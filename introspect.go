@@ -0,0 +1,135 @@
+/*
+
+Introspection of the Protocol: accessing the event types and the type infos in a read-only way.
+
+*/
+
+package s2prot
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Names of the s2protocol types, index is the s2pType value.
+var s2pTypeNames = [...]string{"int", "struct", "choice", "array", "bitarray", "blob", "optional", "bool", "fourcc", "null"}
+
+// String returns the s2protocol name of the type.
+func (t s2pType) String() string {
+	if t >= 0 && int(t) < len(s2pTypeNames) {
+		return s2pTypeNames[t]
+	}
+	return "unknown"
+}
+
+// FieldInfo describes a field of a data structure defined by the protocol.
+type FieldInfo struct {
+	Name string // Name of the field (as it appears in decoded Structs)
+	Type string // Signature of the field's type, e.g. "int(0,7)" or "array(0,5)[bool]"
+}
+
+// BaseBuild returns the base build of the protocol.
+func (p *Protocol) BaseBuild() int {
+	return p.baseBuild
+}
+
+// HasTrackerEvents tells if the protocol has/handles tracker events.
+func (p *Protocol) HasTrackerEvents() bool {
+	return p.hasTrackerEvents
+}
+
+// GameEvtTypes returns the game event types. Index is the event id.
+// Ids not used by the protocol have an EvtType with empty Name.
+// The returned slice is a copy, it may be freely modified.
+func (p *Protocol) GameEvtTypes() []EvtType {
+	return append([]EvtType(nil), p.gameEvtTypes...)
+}
+
+// MessageEvtTypes returns the message event types. Index is the event id.
+// Ids not used by the protocol have an EvtType with empty Name.
+// The returned slice is a copy, it may be freely modified.
+func (p *Protocol) MessageEvtTypes() []EvtType {
+	return append([]EvtType(nil), p.messageEvtTypes...)
+}
+
+// TrackerEvtTypes returns the tracker event types. Index is the event id.
+// Ids not used by the protocol have an EvtType with empty Name.
+// nil is returned if the protocol has no tracker events.
+// The returned slice is a copy, it may be freely modified.
+func (p *Protocol) TrackerEvtTypes() []EvtType {
+	return append([]EvtType(nil), p.trackerEvtTypes...)
+}
+
+// EvtFields returns the fields of the data structure of the specified event type.
+// Fields of "__parent" structures are inlined (just like when decoding).
+// The event type must be one obtained from this protocol.
+func (p *Protocol) EvtFields(e *EvtType) []FieldInfo {
+	return p.structFields(e.typeid)
+}
+
+// structFields returns the fields of the struct type specified by its typeid.
+// nil is returned if the type is not a struct.
+func (p *Protocol) structFields(typeid int) (fis []FieldInfo) {
+	ti := &p.typeInfos[typeid]
+	if ti.s2pType != s2pStruct {
+		return nil
+	}
+
+	for _, f := range ti.fields {
+		if f.isNameParent && p.typeInfos[f.typeid].s2pType == s2pStruct {
+			fis = append(fis, p.structFields(f.typeid)...)
+			continue
+		}
+		fis = append(fis, FieldInfo{Name: f.name, Type: p.TypeSignature(f.typeid)})
+	}
+	return
+}
+
+// Max depth of type signatures. Deeper types are abbreviated.
+const maxSignatureDepth = 8
+
+// TypeSignature returns a textual signature of the type specified by its typeid.
+// The signature contains all decoding instructions of the type (and its element / field types),
+// so 2 types are decoded the same way if their signatures are equal.
+func (p *Protocol) TypeSignature(typeid int) string {
+	sb := &strings.Builder{}
+	p.writeSignature(sb, typeid, 0)
+	return sb.String()
+}
+
+// writeSignature writes the signature of the type specified by its typeid.
+func (p *Protocol) writeSignature(sb *strings.Builder, typeid, depth int) {
+	ti := &p.typeInfos[typeid]
+
+	sb.WriteString(ti.s2pType.String())
+	if depth >= maxSignatureDepth {
+		sb.WriteString("...")
+		return
+	}
+
+	switch ti.s2pType {
+	case s2pInt, s2pBitArr, s2pBlob:
+		fmt.Fprintf(sb, "(%d,%d)", ti.offset64, ti.bits)
+	case s2pArr:
+		fmt.Fprintf(sb, "(%d,%d)[", ti.offset64, ti.bits)
+		p.writeSignature(sb, ti.typeid, depth+1)
+		sb.WriteByte(']')
+	case s2pOptional:
+		sb.WriteByte('[')
+		p.writeSignature(sb, ti.typeid, depth+1)
+		sb.WriteByte(']')
+	case s2pStruct, s2pChoice:
+		if ti.s2pType == s2pChoice {
+			fmt.Fprintf(sb, "(%d,%d)", ti.offset64, ti.bits)
+		}
+		sb.WriteByte('{')
+		for i, f := range ti.fields {
+			if i > 0 {
+				sb.WriteByte(',')
+			}
+			fmt.Fprintf(sb, "%s#%d:", f.name, f.tag)
+			p.writeSignature(sb, f.typeid, depth+1)
+		}
+		sb.WriteByte('}')
+	}
+}
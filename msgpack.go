@@ -0,0 +1,494 @@
+/*
+
+MessagePack encoding of Structs and events.
+
+*/
+
+package s2prot
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"unicode/utf8"
+)
+
+// MsgpackExtBitArr is the MessagePack extension type of BitArr values.
+// The extension data is the bit count (4 bytes, big endian) followed by the Data of the BitArr.
+const MsgpackExtBitArr = 1
+
+// MarshalMsgpack returns the MessagePack encoding of v.
+//
+// The encoding is a compact binary alternative of the canonical JSON encoding (see MarshalCanonical),
+// suitable for archiving decoded replay data:
+//   - keys of Structs and maps are sorted, so the encoding is byte-stable
+//   - integers are encoded in the smallest form that holds them
+//   - strings that are not valid UTF-8 (binary blobs) and byte slices are encoded as bin values
+//   - BitArr values are encoded as extension values of type MsgpackExtBitArr
+//
+// Values of other types are first marshaled with the encoding/json package.
+// Events should be written with MsgpackWriter.WriteEvent() to include their omitted bookkeeping keys.
+func MarshalMsgpack(v interface{}) ([]byte, error) {
+	return appendMsgpack(nil, v)
+}
+
+// UnmarshalMsgpack decodes a single MessagePack value, see MsgpackReader.Read for the types of the result.
+func UnmarshalMsgpack(data []byte) (interface{}, error) {
+	mr := NewMsgpackReader(bytes.NewReader(data))
+	v, err := mr.Read()
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	return v, err
+}
+
+// MsgpackWriter writes a stream of MessagePack values (e.g. the events of a replay) to an io.Writer.
+// Output is buffered, Flush must be called after the last value.
+type MsgpackWriter struct {
+	w   *bufio.Writer
+	buf []byte // Reused encoding buffer
+}
+
+// NewMsgpackWriter creates a new MsgpackWriter writing to w.
+func NewMsgpackWriter(w io.Writer) *MsgpackWriter {
+	return &MsgpackWriter{w: bufio.NewWriter(w)}
+}
+
+// Write writes the MessagePack encoding of v, see MarshalMsgpack.
+func (mw *MsgpackWriter) Write(v interface{}) error {
+	var err error
+	if mw.buf, err = appendMsgpack(mw.buf[:0], v); err != nil {
+		return err
+	}
+	_, err = mw.w.Write(mw.buf)
+	return err
+}
+
+// WriteEvent writes the Struct of the event, including the bookkeeping keys
+// even if they were omitted during decoding (see Protocol.WithEvtKeys).
+func (mw *MsgpackWriter) WriteEvent(e *Event) error {
+	if e.keysOmitted {
+		c := *e
+		c.Struct = make(Struct, len(e.Struct)+4)
+		for k, v := range e.Struct {
+			c.Struct[k] = v
+		}
+		c.EmbedKeys()
+		return mw.Write(c.Struct)
+	}
+	return mw.Write(e.Struct)
+}
+
+// WriteEvts writes the events, see WriteEvent.
+func (mw *MsgpackWriter) WriteEvts(evts []Event) error {
+	for i := range evts {
+		if err := mw.WriteEvent(&evts[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Flush writes any buffered data to the underlying io.Writer.
+func (mw *MsgpackWriter) Flush() error {
+	return mw.w.Flush()
+}
+
+// appendMsgpack appends the MessagePack encoding of v to b.
+func appendMsgpack(b []byte, v interface{}) ([]byte, error) {
+	switch x := v.(type) {
+	case nil:
+		return append(b, 0xc0), nil
+	case bool:
+		if x {
+			return append(b, 0xc3), nil
+		}
+		return append(b, 0xc2), nil
+	case int64:
+		return appendMsgpackInt(b, x), nil
+	case int:
+		return appendMsgpackInt(b, int64(x)), nil
+	case int32:
+		return appendMsgpackInt(b, int64(x)), nil
+	case float64:
+		return appendUint(append(b, 0xcb), math.Float64bits(x), 8), nil
+	case float32:
+		return appendUint(append(b, 0xca), uint64(math.Float32bits(x)), 4), nil
+	case json.Number:
+		if i, err := x.Int64(); err == nil {
+			return appendMsgpackInt(b, i), nil
+		}
+		f, err := x.Float64()
+		if err != nil {
+			return nil, err
+		}
+		return appendMsgpack(b, f)
+	case string:
+		if !utf8.ValidString(x) {
+			return append(appendMsgpackLen(b, len(x), 0xc4, 0xc5, 0xc6), x...), nil
+		}
+		if len(x) < 32 {
+			b = append(b, 0xa0|byte(len(x)))
+		} else {
+			b = appendMsgpackLen(b, len(x), 0xd9, 0xda, 0xdb)
+		}
+		return append(b, x...), nil
+	case []byte:
+		return append(appendMsgpackLen(b, len(x), 0xc4, 0xc5, 0xc6), x...), nil
+	case BitArr:
+		b = appendMsgpackLen(b, 4+len(x.Data), 0xc7, 0xc8, 0xc9)
+		b = append(b, MsgpackExtBitArr)
+		b = appendUint(b, uint64(x.Count), 4)
+		return append(b, x.Data...), nil
+	case Struct:
+		return appendMsgpackMap(b, x)
+	case map[string]interface{}:
+		return appendMsgpackMap(b, x)
+	case []interface{}:
+		if len(x) < 16 {
+			b = append(b, 0x90|byte(len(x)))
+		} else {
+			b = appendMsgpackLen16(b, len(x), 0xdc, 0xdd)
+		}
+		var err error
+		for _, e := range x {
+			if b, err = appendMsgpack(b, e); err != nil {
+				return nil, err
+			}
+		}
+		return b, nil
+	default:
+		// Marshal with encoding/json, and encode the generic result
+		data, err := json.Marshal(x)
+		if err != nil {
+			return nil, err
+		}
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.UseNumber()
+		var generic interface{}
+		if err := dec.Decode(&generic); err != nil {
+			return nil, err
+		}
+		return appendMsgpack(b, generic)
+	}
+}
+
+// appendMsgpackMap appends the MessagePack encoding of a map, keys sorted.
+func appendMsgpackMap(b []byte, m map[string]interface{}) ([]byte, error) {
+	if len(m) < 16 {
+		b = append(b, 0x80|byte(len(m)))
+	} else {
+		b = appendMsgpackLen16(b, len(m), 0xde, 0xdf)
+	}
+	var err error
+	for _, k := range sortedKeys(m) {
+		if b, err = appendMsgpack(b, k); err != nil {
+			return nil, err
+		}
+		if b, err = appendMsgpack(b, m[k]); err != nil {
+			return nil, err
+		}
+	}
+	return b, nil
+}
+
+// appendMsgpackInt appends an integer in the smallest form that holds it.
+func appendMsgpackInt(b []byte, i int64) []byte {
+	switch {
+	case i >= 0 && i <= math.MaxInt8:
+		return append(b, byte(i)) // positive fixint
+	case i >= -32 && i < 0:
+		return append(b, byte(i)) // negative fixint
+	case i >= 0 && i <= math.MaxUint8:
+		return append(b, 0xcc, byte(i))
+	case i >= 0 && i <= math.MaxUint16:
+		return appendUint(append(b, 0xcd), uint64(i), 2)
+	case i >= 0 && i <= math.MaxUint32:
+		return appendUint(append(b, 0xce), uint64(i), 4)
+	case i >= 0:
+		return appendUint(append(b, 0xcf), uint64(i), 8)
+	case i >= math.MinInt8:
+		return append(b, 0xd0, byte(i))
+	case i >= math.MinInt16:
+		return appendUint(append(b, 0xd1), uint64(i), 2)
+	case i >= math.MinInt32:
+		return appendUint(append(b, 0xd2), uint64(i), 4)
+	}
+	return appendUint(append(b, 0xd3), uint64(i), 8)
+}
+
+// appendUint appends the lowest size bytes of u in big endian byte order.
+func appendUint(b []byte, u uint64, size int) []byte {
+	for shift := 8 * (size - 1); shift >= 0; shift -= 8 {
+		b = append(b, byte(u>>shift))
+	}
+	return b
+}
+
+// appendMsgpackLen appends the format byte and the length of a str, bin or ext value
+// using an 8, 16 or 32-bit length.
+func appendMsgpackLen(b []byte, n int, f8, f16, f32 byte) []byte {
+	if n <= math.MaxUint8 {
+		return append(b, f8, byte(n))
+	}
+	return appendMsgpackLen16(b, n, f16, f32)
+}
+
+// appendMsgpackLen16 appends the format byte and the length of a value using a 16 or 32-bit length.
+func appendMsgpackLen16(b []byte, n int, f16, f32 byte) []byte {
+	if n <= math.MaxUint16 {
+		return appendUint(append(b, f16), uint64(n), 2)
+	}
+	return appendUint(append(b, f32), uint64(n), 4)
+}
+
+// MsgpackReader reads a stream of MessagePack values from an io.Reader.
+type MsgpackReader struct {
+	r *bufio.Reader
+}
+
+// NewMsgpackReader creates a new MsgpackReader reading from r.
+func NewMsgpackReader(r io.Reader) *MsgpackReader {
+	return &MsgpackReader{r: bufio.NewReader(r)}
+}
+
+// msgpackMaxPrealloc is the max number of array elements and map entries preallocated,
+// so corrupt lengths can't cause huge allocations.
+const msgpackMaxPrealloc = 1024
+
+// msgpackMaxDepth is the max nesting depth of arrays and maps,
+// so corrupt streams can't overflow the stack.
+const msgpackMaxDepth = 64
+
+// errMsgpackMapKey is returned for map keys that are not strings.
+var errMsgpackMapKey = errors.New("msgpack: map key is not a string")
+
+// errMsgpackDepth is returned for arrays and maps nested deeper than msgpackMaxDepth.
+var errMsgpackDepth = errors.New("msgpack: too deeply nested value")
+
+// Read reads the next value. io.EOF is returned if there are no more values.
+//
+// Maps are decoded as Structs, arrays as []interface{}, integers as int64, floats as float64,
+// str and bin values as string, and extension values of type MsgpackExtBitArr as BitArr,
+// so values written by MsgpackWriter are read back as the decoders produce them.
+// Events are read back as their Structs.
+func (mr *MsgpackReader) Read() (interface{}, error) {
+	f, err := mr.r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	v, err := mr.read(f, 0)
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	return v, err
+}
+
+// ReadStruct reads the next value which must be a map (e.g. an event). io.EOF is returned if there are no more values.
+func (mr *MsgpackReader) ReadStruct() (Struct, error) {
+	v, err := mr.Read()
+	if err != nil {
+		return nil, err
+	}
+	s, ok := v.(Struct)
+	if !ok {
+		return nil, fmt.Errorf("msgpack: expected map, got: %T", v)
+	}
+	return s, nil
+}
+
+// read reads a value whose format byte is f, nested in depth arrays and maps.
+func (mr *MsgpackReader) read(f byte, depth int) (interface{}, error) {
+	switch {
+	case f <= 0x7f: // positive fixint
+		return int64(f), nil
+	case f >= 0xe0: // negative fixint
+		return int64(int8(f)), nil
+	case f&0xf0 == 0x80: // fixmap
+		return mr.readMap(int(f&0x0f), depth+1)
+	case f&0xf0 == 0x90: // fixarray
+		return mr.readArr(int(f&0x0f), depth+1)
+	case f&0xe0 == 0xa0: // fixstr
+		return mr.readString(int(f & 0x1f))
+	}
+
+	switch f {
+	case 0xc0:
+		return nil, nil
+	case 0xc2:
+		return false, nil
+	case 0xc3:
+		return true, nil
+	case 0xc4, 0xd9: // bin 8, str 8
+		n, err := mr.readUint(1)
+		if err != nil {
+			return nil, err
+		}
+		return mr.readString(int(n))
+	case 0xc5, 0xda: // bin 16, str 16
+		n, err := mr.readUint(2)
+		if err != nil {
+			return nil, err
+		}
+		return mr.readString(int(n))
+	case 0xc6, 0xdb: // bin 32, str 32
+		n, err := mr.readUint(4)
+		if err != nil {
+			return nil, err
+		}
+		return mr.readString(int(n))
+	case 0xc7, 0xc8, 0xc9: // ext 8, ext 16, ext 32
+		n, err := mr.readUint(1 << (f - 0xc7))
+		if err != nil {
+			return nil, err
+		}
+		return mr.readExt(int(n))
+	case 0xd4, 0xd5, 0xd6, 0xd7, 0xd8: // fixext 1, 2, 4, 8, 16
+		return mr.readExt(1 << (f - 0xd4))
+	case 0xca:
+		u, err := mr.readUint(4)
+		return float64(math.Float32frombits(uint32(u))), err
+	case 0xcb:
+		u, err := mr.readUint(8)
+		return math.Float64frombits(u), err
+	case 0xcc, 0xcd, 0xce, 0xcf: // uint 8, 16, 32, 64
+		u, err := mr.readUint(1 << (f - 0xcc))
+		if err == nil && u > math.MaxInt64 {
+			err = fmt.Errorf("msgpack: integer overflows int64: %d", u)
+		}
+		return int64(u), err
+	case 0xd0:
+		u, err := mr.readUint(1)
+		return int64(int8(u)), err
+	case 0xd1:
+		u, err := mr.readUint(2)
+		return int64(int16(u)), err
+	case 0xd2:
+		u, err := mr.readUint(4)
+		return int64(int32(u)), err
+	case 0xd3:
+		u, err := mr.readUint(8)
+		return int64(u), err
+	case 0xdc, 0xdd: // array 16, 32
+		n, err := mr.readUint(2 << (f - 0xdc))
+		if err != nil {
+			return nil, err
+		}
+		return mr.readArr(int(n), depth+1)
+	case 0xde, 0xdf: // map 16, 32
+		n, err := mr.readUint(2 << (f - 0xde))
+		if err != nil {
+			return nil, err
+		}
+		return mr.readMap(int(n), depth+1)
+	}
+
+	return nil, fmt.Errorf("msgpack: invalid format byte: 0x%02x", f)
+}
+
+// readUint reads a big endian unsigned integer of size bytes.
+func (mr *MsgpackReader) readUint(size int) (uint64, error) {
+	var u uint64
+	for i := 0; i < size; i++ {
+		c, err := mr.r.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		u = u<<8 | uint64(c)
+	}
+	return u, nil
+}
+
+// readBytes reads n bytes.
+// The buffer is not preallocated, so corrupt lengths can't cause huge allocations.
+func (mr *MsgpackReader) readBytes(n int) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(mr.r, int64(n)))
+	if err == nil && len(data) < n {
+		err = io.ErrUnexpectedEOF
+	}
+	return data, err
+}
+
+// readString reads a string of n bytes.
+func (mr *MsgpackReader) readString(n int) (interface{}, error) {
+	data, err := mr.readBytes(n)
+	return string(data), err
+}
+
+// readExt reads an extension value having n bytes of data.
+func (mr *MsgpackReader) readExt(n int) (interface{}, error) {
+	typ, err := mr.r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	data, err := mr.readBytes(n)
+	if err != nil {
+		return nil, err
+	}
+	if typ != MsgpackExtBitArr || n < 4 {
+		return nil, fmt.Errorf("msgpack: unsupported extension type: %d", int8(typ))
+	}
+	return BitArr{Count: int(binary.BigEndian.Uint32(data)), Data: data[4:]}, nil
+}
+
+// readArr reads an array of n elements at the given nesting depth.
+func (mr *MsgpackReader) readArr(n, depth int) (interface{}, error) {
+	if depth > msgpackMaxDepth {
+		return nil, errMsgpackDepth
+	}
+	arr := make([]interface{}, 0, minInt(n, msgpackMaxPrealloc))
+	for i := 0; i < n; i++ {
+		f, err := mr.r.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+		v, err := mr.read(f, depth)
+		if err != nil {
+			return nil, err
+		}
+		arr = append(arr, v)
+	}
+	return arr, nil
+}
+
+// readMap reads a map of n entries at the given nesting depth.
+func (mr *MsgpackReader) readMap(n, depth int) (interface{}, error) {
+	if depth > msgpackMaxDepth {
+		return nil, errMsgpackDepth
+	}
+	s := make(Struct, minInt(n, msgpackMaxPrealloc))
+	for i := 0; i < n; i++ {
+		f, err := mr.r.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+		k, err := mr.read(f, depth)
+		if err != nil {
+			return nil, err
+		}
+		key, ok := k.(string)
+		if !ok {
+			return nil, errMsgpackMapKey
+		}
+		if f, err = mr.r.ReadByte(); err != nil {
+			return nil, err
+		}
+		if s[key], err = mr.read(f, depth); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// minInt returns the smaller of a and b.
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,164 @@
+package s2prot
+
+import (
+	"bytes"
+	"encoding/hex"
+	"io"
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMarshalMsgpack(t *testing.T) {
+	cases := []struct {
+		v   interface{}
+		exp string // hex
+	}{
+		{nil, "c0"},
+		{true, "c3"},
+		{false, "c2"},
+		{int64(0), "00"},
+		{int64(127), "7f"},
+		{int64(-1), "ff"},
+		{int64(-32), "e0"},
+		{int64(-33), "d0df"},
+		{int64(128), "cc80"},
+		{int64(256), "cd0100"},
+		{int64(1 << 16), "ce00010000"},
+		{int64(1 << 32), "cf0000000100000000"},
+		{int64(-129), "d1ff7f"},
+		{int64(-1 << 31), "d280000000"},
+		{int64(-1 << 32), "d3ffffffff00000000"},
+		{int(5), "05"},
+		{1.5, "cb3ff8000000000000"},
+		{float32(1.5), "ca3fc00000"},
+		{"abc", "a3616263"},
+		{strings.Repeat("x", 32), "d920" + strings.Repeat("78", 32)},
+		{"\xff\x00", "c402ff00"},
+		{[]byte{1, 2}, "c4020102"},
+		{BitArr{Count: 9, Data: []byte{0xff, 0x01}}, "c70601" + "00000009ff01"},
+		{[]interface{}{int64(1), "a"}, "9201a161"},
+		{Struct{"b": int64(1), "a": nil}, "82a161c0a16201"},
+		{struct{ B, A int }{2, 1}, "82a14101a14202"},
+	}
+	for _, c := range cases {
+		got, err := MarshalMsgpack(c.v)
+		if err != nil {
+			t.Errorf("[v: %v] Unexpected error: %v", c.v, err)
+			continue
+		}
+		if hex.EncodeToString(got) != c.exp {
+			t.Errorf("[v: %v] Expected: %s, got: %x", c.v, c.exp, got)
+		}
+	}
+}
+
+func TestUnmarshalMsgpack(t *testing.T) {
+	s := Struct{
+		"int":   int64(-70000),
+		"big":   int64(1 << 40),
+		"float": 0.25,
+		"blob":  "\xff\x00\x01",
+		"str":   strings.Repeat("s", 300),
+		"bits":  BitArr{Count: 9, Data: []byte{0xff, 0x01}},
+		"arr":   []interface{}{nil, true, Struct{}, []interface{}{}},
+		"long":  make([]interface{}, 20),
+		"m":     Struct{"x": "y"},
+	}
+	data, err := MarshalMsgpack(s)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	got, err := UnmarshalMsgpack(data)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(got, s) {
+		t.Errorf("Expected: %v, got: %v", s, got)
+	}
+
+	// Formats not produced by the encoder:
+	for _, c := range []struct {
+		data string // hex
+		exp  interface{}
+	}{
+		{"d4010000", nil},                 // fixext 1 with too short BitArr data
+		{"da0001" + "61", "a"},            // str 16
+		{"cf8000000000000000", nil},       // uint64 overflowing int64
+		{"81" + "01" + "01", nil},         // non-string map key
+		{"c1", nil},                       // never used format byte
+		{"d6050000000000", nil},           // unsupported extension type
+		{"92" + "01", nil},                // truncated
+		{"d60100000001" + "80", BitArr{}}, // fixext 4
+	} {
+		data, _ := hex.DecodeString(c.data)
+		got, err := UnmarshalMsgpack(data)
+		switch {
+		case c.exp == nil && err == nil:
+			t.Errorf("[data: %s] Expected error, got: %v", c.data, got)
+		case c.exp != nil && err != nil:
+			t.Errorf("[data: %s] Unexpected error: %v", c.data, err)
+		}
+	}
+	// Deeply nested arrays must not overflow the stack:
+	if _, err := UnmarshalMsgpack(bytes.Repeat([]byte{0x91}, 1000000)); err != errMsgpackDepth {
+		t.Errorf("Expected: %v, got: %v", errMsgpackDepth, err)
+	}
+	if _, err := UnmarshalMsgpack(bytes.Repeat([]byte{0x81, 0xa1, 'k'}, 1000000)); err != errMsgpackDepth {
+		t.Errorf("Expected: %v, got: %v", errMsgpackDepth, err)
+	}
+
+	if _, err := UnmarshalMsgpack(nil); err != io.ErrUnexpectedEOF {
+		t.Errorf("Expected: %v, got: %v", io.ErrUnexpectedEOF, err)
+	}
+}
+
+func TestMsgpackWriter(t *testing.T) {
+	p := GetProtocol(80949)
+	g := &instanceGen{p: p, r: rand.New(rand.NewSource(1))}
+	data, err := p.EncodeGameEvts(g.randomEvts(p.gameEvtTypes, true))
+	if err != nil {
+		t.Fatalf("Failed to encode events: %v", err)
+	}
+	embedded, err := p.DecodeGameEvts(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	omitted, err := p.WithEvtKeys(false).DecodeGameEvts(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, evts := range [][]Event{embedded, omitted} {
+		buf := &bytes.Buffer{}
+		mw := NewMsgpackWriter(buf)
+		if err := mw.WriteEvts(evts); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if err := mw.Flush(); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		mr := NewMsgpackReader(buf)
+		for i := range embedded {
+			s, err := mr.ReadStruct()
+			if err != nil {
+				t.Fatalf("[%d] Unexpected error: %v", i, err)
+			}
+			if !reflect.DeepEqual(s, embedded[i].Struct) {
+				t.Errorf("[%d] Expected: %v, got: %v", i, embedded[i].Struct, s)
+			}
+		}
+		if _, err := mr.Read(); err != io.EOF {
+			t.Errorf("Expected: %v, got: %v", io.EOF, err)
+		}
+	}
+
+	// Events must not be modified:
+	for i := range omitted {
+		if _, ok := omitted[i].Struct["loop"]; ok {
+			t.Errorf("[%d] Unexpected loop key", i)
+		}
+	}
+}
@@ -0,0 +1,227 @@
+/*
+
+Minimal, protocol independent probing of the replay version.
+
+*/
+
+package s2prot
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ProbedVersion is the version of a replay read by ProbeVersion.
+type ProbedVersion struct {
+	Signature string // Signature of the replay header, e.g. "StarCraft II replay\x1b11"
+	Major     int64  // Major part of the version
+	Minor     int64  // Minor part of the version
+	Revision  int64  // Revision part of the version
+	Build     int64  // Build part of the version
+	BaseBuild int64  // Base build, selects the protocol
+	Type      int64  // Type of the replay (the "type" field of the header), 0 if not present
+}
+
+// String returns the version in the form of "major.minor.revision.build".
+func (v ProbedVersion) String() string {
+	return fmt.Sprintf("%d.%d.%d.%d", v.Major, v.Minor, v.Revision, v.Build)
+}
+
+// errProbeTruncated is returned by ProbeVersion if the header data is truncated.
+var errProbeTruncated = errors.New("truncated replay header")
+
+// Field types of the versioned format.
+const (
+	vfArray    = 0
+	vfBitArray = 1
+	vfBlob     = 2
+	vfChoice   = 3
+	vfOptional = 4
+	vfStruct   = 5
+	vfUint8    = 6
+	vfUint32   = 7
+	vfUint64   = 8
+	vfVarInt   = 9
+)
+
+// ProbeVersion extracts the signature, the version and the type of a replay from the MPQ user data
+// (the content passed to DecodeHeader).
+//
+// It uses a hand-written minimal reader of the versioned format which is independent of
+// any (embedded or external) protocol and never panics, so the version of replays
+// can be reported even if their base build is not supported.
+func ProbeVersion(userData []byte) (v ProbedVersion, err error) {
+	data, _ := headerData(userData)
+	if len(data) == 0 {
+		return v, errProbeTruncated
+	}
+	pr := &prober{data: data}
+
+	if err = pr.expect(vfStruct); err != nil {
+		return
+	}
+	found, hasType := 0, false
+	for n := pr.varInt(); n > 0 && pr.err == nil; n-- {
+		switch pr.varInt() {
+		case 0: // m_signature
+			if err = pr.expect(vfBlob); err != nil {
+				return
+			}
+			v.Signature = string(pr.bytes(int(pr.varInt())))
+			found++
+		case 1: // m_version
+			if err = pr.expect(vfStruct); err != nil {
+				return
+			}
+			for m := pr.varInt(); m > 0 && pr.err == nil; m-- {
+				var dst *int64
+				switch pr.varInt() {
+				case 1:
+					dst = &v.Major
+				case 2:
+					dst = &v.Minor
+				case 3:
+					dst = &v.Revision
+				case 4:
+					dst = &v.Build
+				case 5:
+					dst = &v.BaseBuild
+				}
+				if dst == nil {
+					pr.skip(0) // m_flags or unknown field
+					continue
+				}
+				if err = pr.expect(vfVarInt); err != nil {
+					return
+				}
+				*dst = pr.varInt()
+			}
+			found++
+		case 2: // m_type
+			if err = pr.expect(vfVarInt); err != nil {
+				return
+			}
+			v.Type, hasType = pr.varInt(), true
+		default:
+			pr.skip(0)
+		}
+		if found == 2 && hasType {
+			break // Signature, version and type found, no need to read the rest
+		}
+	}
+
+	if pr.err != nil {
+		return v, pr.err
+	}
+	if found < 2 {
+		return v, errors.New("replay header has no signature or version")
+	}
+	return v, nil
+}
+
+// maxProbeDepth is the max nesting depth of values skipped by prober.
+const maxProbeDepth = 32
+
+// prober is a minimal reader of the versioned format.
+// The first error is recorded in err, after which all reads return zero values.
+type prober struct {
+	data []byte // Remaining data
+	err  error  // First error
+}
+
+// byte reads a byte.
+func (pr *prober) byte() byte {
+	if pr.err != nil {
+		return 0
+	}
+	if len(pr.data) == 0 {
+		pr.err = errProbeTruncated
+		return 0
+	}
+	b := pr.data[0]
+	pr.data = pr.data[1:]
+	return b
+}
+
+// bytes reads n bytes.
+func (pr *prober) bytes(n int) []byte {
+	if pr.err != nil {
+		return nil
+	}
+	if n < 0 || n > len(pr.data) {
+		pr.err = errProbeTruncated
+		return nil
+	}
+	b := pr.data[:n]
+	pr.data = pr.data[n:]
+	return b
+}
+
+// varInt reads a variable-length integer.
+func (pr *prober) varInt() int64 {
+	var value int64
+	for shift := uint(0); shift < 64; shift += 7 {
+		data := int64(pr.byte())
+		value |= (data & 0x7f) << shift
+		if data&0x80 == 0 {
+			if value&0x01 > 0 {
+				return -(value >> 1)
+			}
+			return value >> 1
+		}
+	}
+	if pr.err == nil {
+		pr.err = errors.New("invalid variable-length integer")
+	}
+	return 0
+}
+
+// expect reads a field type and checks if it is the expected one.
+func (pr *prober) expect(fieldType byte) error {
+	if ft := pr.byte(); pr.err == nil && ft != fieldType {
+		pr.err = fmt.Errorf("unexpected field type: %d, expected: %d", ft, fieldType)
+	}
+	return pr.err
+}
+
+// skip reads and discards a value whose type is deducted from the read field type, see skipInstance.
+func (pr *prober) skip(depth int) {
+	if depth > maxProbeDepth {
+		pr.err = errors.New("too deeply nested value")
+		return
+	}
+	switch ft := pr.byte(); ft {
+	case vfArray:
+		for i := pr.varInt(); i > 0 && pr.err == nil; i-- {
+			pr.skip(depth + 1)
+		}
+	case vfBitArray:
+		pr.bytes(int((pr.varInt() + 7) / 8))
+	case vfBlob:
+		pr.bytes(int(pr.varInt()))
+	case vfChoice:
+		pr.varInt() // tag
+		pr.skip(depth + 1)
+	case vfOptional:
+		if pr.byte() != 0 {
+			pr.skip(depth + 1)
+		}
+	case vfStruct:
+		for i := pr.varInt(); i > 0 && pr.err == nil; i-- {
+			pr.varInt() // tag
+			pr.skip(depth + 1)
+		}
+	case vfUint8:
+		pr.bytes(1)
+	case vfUint32:
+		pr.bytes(4)
+	case vfUint64:
+		pr.bytes(8)
+	case vfVarInt:
+		pr.varInt()
+	default:
+		if pr.err == nil {
+			pr.err = fmt.Errorf("invalid field type: %d", ft)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package s2prot
+
+import (
+	"testing"
+)
+
+func TestProbeVersion(t *testing.T) {
+	header := Struct{
+		"signature":        "StarCraft II replay\x1b11",
+		"version":          Struct{"flags": int64(1), "major": int64(5), "minor": int64(0), "revision": int64(11), "build": int64(81102), "baseBuild": int64(81009)},
+		"type":             int64(2),
+		"elapsedGameLoops": int64(1000),
+	}
+	userData, err := EncodeHeaderWith(GetProtocol(80949), header)
+	if err != nil {
+		t.Fatalf("Failed to encode header: %v", err)
+	}
+
+	v, err := ProbeVersion(userData)
+	if err != nil {
+		t.Fatalf("Failed to probe version: %v", err)
+	}
+	exp := ProbedVersion{Signature: "StarCraft II replay\x1b11", Major: 5, Revision: 11, Build: 81102, BaseBuild: 81009, Type: 2}
+	if v != exp {
+		t.Errorf("Expected: %+v, got: %+v", exp, v)
+	}
+	if s := v.String(); s != "5.0.11.81102" {
+		t.Errorf("Expected: %s, got: %s", "5.0.11.81102", s)
+	}
+
+	// Must match DecodeHeader:
+	decoded := DecodeHeader(userData)
+	if v.BaseBuild != decoded.Int("version", "baseBuild") || v.Signature != decoded.Stringv("signature") || v.Type != decoded.Int("type") {
+		t.Errorf("Probed version differs from decoded header: %+v, %v", v, decoded)
+	}
+
+	// Truncated data must not panic, only the signature, version and type are needed:
+	for i := 0; i < len(userData); i++ {
+		if v2, err := ProbeVersion(userData[:i]); err == nil && v2 != exp {
+			t.Errorf("[%d] Expected: %+v, got: %+v", i, exp, v2)
+		}
+	}
+	if _, err := ProbeVersion(userData[:20]); err == nil {
+		t.Errorf("Expected error for truncated data")
+	}
+	if _, err := ProbeVersion([]byte("\x3c\x00\x00\x00\x07invalid")); err == nil {
+		t.Errorf("Expected error for invalid data")
+	}
+	if _, err := ProbeVersion([]byte{0x3c, 0, 0, 0, vfStruct, 2, 4, vfBlob, 0}); err == nil {
+		t.Errorf("Expected error for header without version")
+	}
+
+	// Type is optional:
+	delete(header, "type")
+	if userData, err = EncodeHeaderWith(GetProtocol(80949), header); err != nil {
+		t.Fatalf("Failed to encode header: %v", err)
+	}
+	exp.Type = 0
+	if v, err := ProbeVersion(userData); err != nil || v != exp {
+		t.Errorf("Expected: %+v, got: %+v, %v", exp, v, err)
+	}
+}
@@ -10,6 +10,8 @@ import (
 	"bufio"
 	"fmt"
 	"log"
+	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"sync"
@@ -83,51 +85,107 @@ type Protocol struct {
 	replayHeaderTypeid   int // The typeid of NNet.Replay.SHeader (the type used to store replay game version and length)
 	gameDetailsTypeid    int // The typeid of NNet.Game.SDetails (the type used to store overall replay details)
 	replayInitdataTypeid int // The typeid of NNet.Replay.SInitData (the type used to store the initial lobby)
+
+	strict bool // Tells if strict validation mode is on
+
+	omitEvtKeys bool // Tells if bookkeeping keys are omitted from the Struct of decoded events
 }
 
 var (
-	// Holds the already parsed Protocols mapped from base build.
-	protocols = make(map[int]*Protocol)
-	// Mutex protecting access of the protocols map
+	// Optional external folder to look for protocols in before the embedded builds.
+	protocolDir string
+	// Mutex protecting access of the protocols maps of games, the registered games and protocolDir
 	protMux = &sync.Mutex{}
 )
 
-// GetProtocol returns the Protocol for the specified base build.
-// nil return value indicates unknown/unsupported base build.
-func GetProtocol(baseBuild int) *Protocol {
+// ProtocolDirEnvVar is the name of the environment variable that may specify
+// the initial value of the external protocol folder, see SetProtocolDir.
+const ProtocolDirEnvVar = "S2PROT_PROTOCOL_DIR"
+
+func init() {
+	protocolDir = os.Getenv(ProtocolDirEnvVar)
+}
+
+// SetProtocolDir sets an external folder where GetProtocol looks for protocols
+// before consulting the embedded builds. This allows supporting new base builds without recompiling.
+//
+// For a base build, the following files are looked for (in this order):
+//
+//	<baseBuild>.py
+//	protocol<baseBuild>.py
+//
+// Files must be python protocol sources from Blizzard's s2protocol repository.
+// If a file exists but parsing it fails, the embedded build is used (if any).
+//
+// Empty string disables the external folder. The initial value is taken from the
+// environment variable named by ProtocolDirEnvVar.
+// Protocols already returned by GetProtocol are cleared from the cache.
+//
+// Note that MinBaseBuild and MaxBaseBuild only reflect the embedded builds.
+// The external folder is only used for StarCraft II protocols (see GameSC2).
+func SetProtocolDir(dir string) {
 	protMux.Lock()
 	defer protMux.Unlock()
 
-	return getProtocol(baseBuild)
+	protocolDir = dir
+	GameSC2.protocols = make(map[int]*Protocol)
 }
 
-// getProtocol returns the Protocol for the specified base build.
+// ProtocolDir returns the external protocol folder, see SetProtocolDir.
+func ProtocolDir() string {
+	protMux.Lock()
+	defer protMux.Unlock()
+
+	return protocolDir
+}
+
+// GetProtocol returns the StarCraft II Protocol for the specified base build.
+// nil return value indicates unknown/unsupported base build.
+//
+// Protocols of other games can be acquired with Game.GetProtocol.
+func GetProtocol(baseBuild int) *Protocol {
+	return GameSC2.GetProtocol(baseBuild)
+}
+
+// getProtocol returns the Protocol of the game for the specified base build.
 // nil return value indicates unknown/unsupported base build.
 // protMux must be locked when this function is called.
-func getProtocol(baseBuild int) *Protocol {
+func (g *Game) getProtocol(baseBuild int) *Protocol {
+	if g.protocols == nil {
+		g.protocols = make(map[int]*Protocol)
+	}
+
 	// Check if protocol is already parsed:
-	p, ok := protocols[baseBuild]
+	p, ok := g.protocols[baseBuild]
 	if ok {
 		// Note that ok only means a value exists for baseBuild but it might be nil
 		// in case we didn't find it or failed to parse it in an earlier call.
 		return p
 	}
 
-	// Not yet parsed, check if an original base build (not duplicate):
-	src, ok := build.Builds[baseBuild]
+	// Not yet parsed, check the external folder first:
+	if protocolDir != "" && g == GameSC2 {
+		if p = loadDirProtocol(protocolDir, baseBuild); p != nil {
+			g.protocols[baseBuild] = p
+			return p
+		}
+	}
+
+	// Check if an original base build (not duplicate):
+	src, ok := g.Builds[baseBuild]
 	if ok {
 		p = parseProtocol(src, baseBuild)
-		protocols[baseBuild] = p
+		g.protocols[baseBuild] = p
 		return p
 	}
 
 	// Either a duplicate or an Unknown base build. Check for duplicate:
-	origBaseBuild, ok := build.Duplicates[baseBuild]
+	origBaseBuild, ok := g.Duplicates[baseBuild]
 	if ok {
 		// It's a duplicate. Get the original (will load original if needed).
 		// origBasebuild surely exists (build.Duplicates contains valid entries, ensured by test!)
 		// but parsing it may (still) fail, so check for nil:
-		if op := getProtocol(origBaseBuild); op != nil {
+		if op := g.getProtocol(origBaseBuild); op != nil {
 			// Copy / clone protocol with proper base build:
 			p = new(Protocol)
 			*p = *op
@@ -137,10 +195,34 @@ func getProtocol(baseBuild int) *Protocol {
 	// (else it's not a duplicate: it's an Unknown base build; p remains nil)
 
 	// Even if p is nil: still store nil value so we'll know this earlier next time
-	protocols[baseBuild] = p
+	g.protocols[baseBuild] = p
 	return p
 }
 
+// loadDirProtocol loads the Protocol for the specified base build from the specified folder.
+// nil is returned if the folder does not contain the protocol or if parsing it fails.
+func loadDirProtocol(dir string, baseBuild int) *Protocol {
+	sbb := strconv.Itoa(baseBuild)
+
+	for _, name := range []string{sbb + ".py", "protocol" + sbb + ".py"} {
+		if data, err := os.ReadFile(filepath.Join(dir, name)); err == nil {
+			return parseProtocol(string(data), baseBuild)
+		}
+	}
+
+	return nil
+}
+
+// ParseProtocol parses a Protocol from its python source from Blizzard's s2protocol repository.
+// It does not require filesystem access, so it can be used with protocol data fetched over the network
+// (e.g. in the browser), see Game.SetProtocol.
+func ParseProtocol(data []byte, baseBuild int) (*Protocol, error) {
+	if p := parseProtocol(string(data), baseBuild); p != nil {
+		return p, nil
+	}
+	return nil, fmt.Errorf("invalid protocol source %d", baseBuild)
+}
+
 // parseProtocol parses a Protocol from its python source.
 // nil is returned if parsing error occurs.
 func parseProtocol(src string, baseBuild int) *Protocol {
@@ -152,7 +234,9 @@ func parseProtocol(src string, baseBuild int) *Protocol {
 		// nil will be returned by parseProtocol()
 	}()
 
-	p := Protocol{baseBuild: baseBuild, hasTrackerEvents: baseBuild >= 24944}
+	// Features are detected from the source (and not from the base build), so protocols of other games work too.
+	// For StarCraft II, tracker events are present from base build 24944.
+	p := Protocol{baseBuild: baseBuild, hasTrackerEvents: strings.Contains(src, "\ntracker_event_types")}
 
 	scanner := bufio.NewScanner(strings.NewReader(src))
 
@@ -266,7 +350,7 @@ func parseProtocol(src string, baseBuild int) *Protocol {
 	p.svaruint32Typeid = parseInt()
 
 	// From basebuild 24764 user id is present, before that player id
-	if baseBuild >= 24764 {
+	if strings.Contains(src, "\nreplay_userid_typeid") {
 		seek("replay_userid_typeid")
 	} else {
 		seek("replay_playerid_typeid")
@@ -285,23 +369,80 @@ func parseProtocol(src string, baseBuild int) *Protocol {
 	return &p
 }
 
+// Type infos of the replay header, as defined by the latest protocols.
+// Type ids are local (they do not match any protocol's type ids).
+// Since the header is decoded with the versioned decoder which skips unknown fields,
+// this can decode the replay header of all base builds.
+var headerTypeInfoSrcs = []string{
+	"('_blob',[(0,8)]),  #0",
+	"('_int',[(0,8)]),  #1",
+	"('_int',[(0,32)]),  #2",
+	"('_struct',[[('m_flags',1,0),('m_major',1,1),('m_minor',1,2),('m_revision',1,3),('m_build',2,4),('m_baseBuild',2,5)]]),  #3",
+	"('_int',[(0,3)]),  #4",
+	"('_bool',[]),  #5",
+	"('_array',[(16,0),1]),  #6",
+	"('_optional',[6]),  #7",
+	"('_blob',[(16,0)]),  #8",
+	"('_struct',[[('m_dataDeprecated',7,0),('m_data',8,1)]]),  #9",
+	"('_struct',[[('m_signature',0,0),('m_version',3,1),('m_type',4,2),('m_elapsedGameLoops',2,3),('m_useScaledTime',5,4),('m_ngdpRootKey',9,5),('m_dataBuildNum',2,6),('m_replayCompatibilityHash',9,7),('m_ngdpRootKeyIsDevData',5,8)]]),  #10",
+}
+
+// minHeaderProtocol is a minimal protocol which is only capable of decoding replay headers.
+var minHeaderProtocol = func() *Protocol {
+	p := &Protocol{replayHeaderTypeid: len(headerTypeInfoSrcs) - 1}
+	for _, src := range headerTypeInfoSrcs {
+		p.typeInfos = append(p.typeInfos, parseTypeInfo(src))
+	}
+	return p
+}()
+
+// headerProtocol is the protocol used by DecodeHeader, nil means minHeaderProtocol.
+// Protected by protMux.
+var headerProtocol *Protocol
+
+// SetHeaderProtocol sets the protocol used by DecodeHeader.
+// nil means to use the built-in, minimal header type infos which do not depend on
+// the availability of any protocol (this is the default).
+func SetHeaderProtocol(p *Protocol) {
+	protMux.Lock()
+	defer protMux.Unlock()
+
+	headerProtocol = p
+}
+
 // DecodeHeader decodes and returns the replay header.
+// The protocol used for decoding can be configured with SetHeaderProtocol.
+// The signature of the header is not validated, use ParseHeader for that.
 // Panics if decoding fails.
 func DecodeHeader(contents []byte) Struct {
-	// Use max base build to decode replay headers as that is the one most likely always needed.
-	p := GetProtocol(MaxBaseBuild)
+	protMux.Lock()
+	p := headerProtocol
+	protMux.Unlock()
+
 	if p == nil {
-		panic("Default protocol is not available!")
+		p = minHeaderProtocol
 	}
 
-	contents = contents[4:] // 3c 00 00 00 (might be part of the MPQ header and not the user data)
+	return DecodeHeaderWith(p, contents)
+}
 
-	d := newVersionedDec(contents, p.typeInfos)
+// DecodeHeaderWith decodes and returns the replay header using the specified protocol.
+// Panics if decoding fails.
+func DecodeHeaderWith(p *Protocol, contents []byte) Struct {
+	data, _ := headerData(contents)
+	return decodeHeaderData(p, data)
+}
+
+// decodeHeaderData decodes the header struct (the user data without the size prefix, see headerData).
+// Panics if decoding fails.
+func decodeHeaderData(p *Protocol, contents []byte) Struct {
+	d := p.newVersionedDec(contents)
 
 	v, ok := d.instance(p.replayHeaderTypeid).(Struct)
 	if !ok {
 		return nil
 	}
+	p.checkTrailingPadding(d.bitPackedBuff, p.replayHeaderTypeid)
 
 	return v
 }
@@ -309,12 +450,13 @@ func DecodeHeader(contents []byte) Struct {
 // DecodeDetails decodes and returns the game details.
 // Panics if decoding fails.
 func (p *Protocol) DecodeDetails(contents []byte) Struct {
-	d := newVersionedDec(contents, p.typeInfos)
+	d := p.newVersionedDec(contents)
 
 	v, ok := d.instance(p.gameDetailsTypeid).(Struct)
 	if !ok {
 		return nil
 	}
+	p.checkTrailing(d.bitPackedBuff, p.gameDetailsTypeid)
 
 	return v
 }
@@ -322,12 +464,13 @@ func (p *Protocol) DecodeDetails(contents []byte) Struct {
 // DecodeInitData decodes and returns the replay init data.
 // Panics if decoding fails.
 func (p *Protocol) DecodeInitData(contents []byte) Struct {
-	d := newBitPackedDec(contents, p.typeInfos)
+	d := p.newBitPackedDec(contents)
 
 	v, ok := d.instance(p.replayInitdataTypeid).(Struct)
 	if !ok {
 		return nil
 	}
+	p.checkTrailing(d.bitPackedBuff, p.replayInitdataTypeid)
 
 	return v
 }
@@ -396,24 +539,45 @@ type decoder interface {
 	EOF() bool
 	byteAlign()
 	instance(typeid int) interface{}
+	choiceInt(typeid int) int64
+}
+
+// WithEvtKeys returns a copy of the protocol whose event decoding methods embed or omit
+// the bookkeeping keys in the Struct of events.
+//
+// By default (for compatibility) the "id", "evtTypeName", "loop" and "userid" keys are embedded
+// in the Struct of decoded events, duplicating the data of the Event wrapper (these keys also appear e.g.
+// in the JSON representation of events). If embed is false, these are only stored in the Event wrapper,
+// which reduces memory usage and the JSON size of events; use Event.Loop(), Event.UserIDValue(),
+// Event.EvtType to access them, or Event.EmbedKeys() to copy them into the Struct.
+func (p *Protocol) WithEvtKeys(embed bool) *Protocol {
+	p2 := new(Protocol)
+	*p2 = *p
+	p2.omitEvtKeys = !embed
+	return p2
+}
+
+// EvtKeys tells if bookkeeping keys are embedded in the Struct of decoded events, see WithEvtKeys.
+func (p *Protocol) EvtKeys() bool {
+	return !p.omitEvtKeys
 }
 
 // DecodeGameEvts decodes and returns the game events.
 // In case of a decoding error, successfully decoded events are still returned along with an error.
 func (p *Protocol) DecodeGameEvts(contents []byte) ([]Event, error) {
-	return p.decodeEvts(newBitPackedDec(contents, p.typeInfos), p.gameEventidTypeid, p.gameEvtTypes, true)
+	return p.decodeEvts(p.newBitPackedDec(contents), p.gameEventidTypeid, p.gameEvtTypes, true)
 }
 
 // DecodeMessageEvts decodes and returns the message events.
 // In case of a decoding error, successfully decoded events are still returned along with an error.
 func (p *Protocol) DecodeMessageEvts(contents []byte) ([]Event, error) {
-	return p.decodeEvts(newBitPackedDec(contents, p.typeInfos), p.messageEventidTypeid, p.messageEvtTypes, true)
+	return p.decodeEvts(p.newBitPackedDec(contents), p.messageEventidTypeid, p.messageEvtTypes, true)
 }
 
 // DecodeTrackerEvts decodes and returns the tracker events.
 // In case of a decoding error, successfully decoded events are still returned along with an error.
 func (p *Protocol) DecodeTrackerEvts(contents []byte) ([]Event, error) {
-	return p.decodeEvts(newVersionedDec(contents, p.typeInfos), p.trackerEventidTypeid, p.trackerEvtTypes, false)
+	return p.decodeEvts(p.newVersionedDec(contents), p.trackerEventidTypeid, p.trackerEvtTypes, false)
 }
 
 // decodeEvts decodes a series of events.
@@ -427,7 +591,11 @@ func (p *Protocol) decodeEvts(d decoder, evtidTypeid int, etypes []EvtType, decU
 	// Protect the events decoding:
 	defer func() {
 		if r := recover(); r != nil {
-			err = fmt.Errorf("failed to decode events: %v", r)
+			if ve, ok := r.(*ValidationError); ok {
+				err = ve
+			} else {
+				err = fmt.Errorf("failed to decode events: %v", r)
+			}
 			log.Println(err)
 		}
 		// Successfully decoded events will be returned
@@ -439,11 +607,7 @@ func (p *Protocol) decodeEvts(d decoder, evtidTypeid int, etypes []EvtType, decU
 	)
 
 	for !d.EOF() {
-		delta := d.instance(deltaTypeid).(Struct)
-		// delta has one key-value pair:
-		for _, v := range delta {
-			loop += v.(int64)
-		}
+		loop += d.choiceInt(deltaTypeid)
 
 		if decUserID {
 			userid = d.instance(useridTypeid)
@@ -454,12 +618,16 @@ func (p *Protocol) decodeEvts(d decoder, evtidTypeid int, etypes []EvtType, decU
 
 		// Decode the event data structure:
 		e := Event{Struct: d.instance(evtType.typeid).(Struct), EvtType: evtType}
-		// Copy to / duplicate data in Struct so Struct.String() includes them too
-		e.Struct["id"] = evtid
-		e.Struct["evtTypeName"] = evtType.Name
-		e.Struct["loop"] = loop
-		if decUserID {
-			e.Struct["userid"] = userid
+		if p.omitEvtKeys {
+			e.keysOmitted, e.loop, e.userid = true, loop, userid
+		} else {
+			// Copy to / duplicate data in Struct so Struct.String() includes them too
+			e.Struct["id"] = evtid
+			e.Struct["evtTypeName"] = evtType.Name
+			e.Struct["loop"] = loop
+			if decUserID {
+				e.Struct["userid"] = userid
+			}
 		}
 
 		events = append(events, e)
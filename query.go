@@ -0,0 +1,312 @@
+/*
+
+Querying values of Structs with path expressions.
+
+*/
+
+package s2prot
+
+import (
+	"strconv"
+	"strings"
+)
+
+// QueryResult is the result of a Struct query.
+type QueryResult struct {
+	Value  interface{} // The queried value
+	Exists bool        // Tells if the queried value exists
+}
+
+// Int returns the result as an integer. Floating point values are truncated.
+// zero value is returned if the result is not a number.
+func (r QueryResult) Int() int64 {
+	switch v := r.Value.(type) {
+	case int64:
+		return v
+	case float64:
+		return int64(v)
+	}
+	return 0
+}
+
+// Float returns the result as a floating point number. Integer values are converted.
+// zero value is returned if the result is not a number.
+func (r QueryResult) Float() float64 {
+	f, _ := toFloat(r.Value)
+	return f
+}
+
+// Bool returns the result as a bool.
+// zero value is returned if the result is not a bool.
+func (r QueryResult) Bool() (v bool) {
+	v, _ = r.Value.(bool)
+	return
+}
+
+// String returns the result as a string.
+// zero value is returned if the result is not a string.
+func (r QueryResult) String() (v string) {
+	v, _ = r.Value.(string)
+	return
+}
+
+// Struct returns the result as a Struct.
+// zero value is returned if the result is not a Struct.
+func (r QueryResult) Struct() Struct {
+	return asStruct(r.Value)
+}
+
+// Array returns the result as an array.
+// zero value is returned if the result is not an array.
+func (r QueryResult) Array() (v []interface{}) {
+	v, _ = r.Value.([]interface{})
+	return
+}
+
+// Query returns the value specified by a path expression.
+//
+// The path is a series of keys separated by dots, e.g. "gameDescription.gameOptions.amm".
+// Besides Struct keys, the following path elements are supported:
+//
+//	2                 index of an array element, e.g. "playerList.2.name"
+//	#                 length of an array if it's the last element, e.g. "playerList.#",
+//	                  else the rest of the path is applied to all elements, e.g. "playerList.#.name"
+//	#(cond)           the first array element matching the condition, e.g. "slots.#(teamId==1).toonHandle"
+//	#(cond)#          all array elements matching the condition, e.g. "slots.#(teamId==1)#.toonHandle"
+//
+// A condition is a path (relative to the array element, may be empty to denote the element itself),
+// an operator (one of ==, !=, <, <=, >, >=) and a value which may be a number, a quoted string, true or false,
+// e.g. "toon.region==2" or `name!="foo"`.
+//
+// Where multiple values are produced, the result value is of type []interface{}.
+// If the path is invalid, the result's Exists field is false.
+func (s *Struct) Query(path string) QueryResult {
+	if path == "" {
+		return QueryResult{}
+	}
+	v, ok := query(*s, splitPath(path))
+	return QueryResult{Value: v, Exists: ok}
+}
+
+// splitPath splits a path into elements separated by dots.
+// Dots inside parenthesis or quotes are not considered separators.
+func splitPath(path string) (parts []string) {
+	depth, quoted, start := 0, false, 0
+	for i := 0; i < len(path); i++ {
+		switch c := path[i]; {
+		case c == '\\' && quoted:
+			i++ // Skip escaped character
+		case c == '"':
+			quoted = !quoted
+		case quoted:
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+		case c == '.' && depth == 0:
+			parts = append(parts, path[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, path[start:])
+}
+
+// query returns the value specified by the path elements.
+func query(v interface{}, parts []string) (interface{}, bool) {
+	for i, part := range parts {
+		switch {
+		case part == "#":
+			arr, ok := v.([]interface{})
+			if !ok {
+				return nil, false
+			}
+			if i == len(parts)-1 {
+				return int64(len(arr)), true
+			}
+			return queryAll(arr, parts[i+1:]), true
+
+		case strings.HasPrefix(part, "#(") && (strings.HasSuffix(part, ")") || strings.HasSuffix(part, ")#")):
+			arr, ok := v.([]interface{})
+			if !ok {
+				return nil, false
+			}
+			all := strings.HasSuffix(part, "#")
+			c, ok := parseCond(strings.TrimSuffix(part[2:], "#"))
+			if !ok {
+				return nil, false
+			}
+			var matches []interface{}
+			for _, elem := range arr {
+				if c.matches(elem) {
+					if !all {
+						return query(elem, parts[i+1:])
+					}
+					matches = append(matches, elem)
+				}
+			}
+			if !all {
+				return nil, false
+			}
+			return queryAll(matches, parts[i+1:]), true
+
+		default:
+			if s := asStruct(v); s != nil {
+				var ok bool
+				if v, ok = s[part]; !ok {
+					return nil, false
+				}
+				continue
+			}
+			arr, ok := v.([]interface{})
+			if !ok {
+				return nil, false
+			}
+			idx, err := strconv.Atoi(part)
+			if err != nil || idx < 0 || idx >= len(arr) {
+				return nil, false
+			}
+			v = arr[idx]
+		}
+	}
+
+	return v, true
+}
+
+// queryAll applies the path elements to all elements of the array
+// and returns the existing results.
+func queryAll(arr []interface{}, parts []string) []interface{} {
+	res := make([]interface{}, 0, len(arr))
+	for _, elem := range arr {
+		if v, ok := query(elem, parts); ok {
+			res = append(res, v)
+		}
+	}
+	return res
+}
+
+// asStruct returns the value as a Struct.
+// Values unmarshaled from JSON (e.g. game metadata) are of type map[string]interface{}, those are also accepted.
+func asStruct(v interface{}) Struct {
+	switch s := v.(type) {
+	case Struct:
+		return s
+	case map[string]interface{}:
+		return Struct(s)
+	}
+	return nil
+}
+
+// toFloat converts a numeric value to float64.
+func toFloat(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case int64:
+		return float64(n), true
+	case float64:
+		return n, true
+	}
+	return 0, false
+}
+
+// cond is a parsed query condition.
+type cond struct {
+	path  []string    // Path of the compared value, relative to the array element
+	op    string      // Comparison operator
+	value interface{} // Value to compare to: float64, string or bool
+}
+
+// Comparison operators of conditions. 2-character operators must precede their 1-character prefixes.
+var condOps = []string{"==", "!=", "<=", ">=", "<", ">"}
+
+// parseCond parses a condition in the form of "path op value)".
+func parseCond(s string) (c cond, ok bool) {
+	if !strings.HasSuffix(s, ")") {
+		return
+	}
+	s = s[:len(s)-1]
+
+	opIdx := -1
+	for _, op := range condOps {
+		if i := strings.Index(s, op); i >= 0 && (opIdx < 0 || i < opIdx) {
+			opIdx, c.op = i, op
+		}
+	}
+	if opIdx < 0 {
+		return
+	}
+
+	if p := strings.TrimSpace(s[:opIdx]); p != "" {
+		c.path = splitPath(p)
+	}
+
+	sv := strings.TrimSpace(s[opIdx+len(c.op):])
+	switch {
+	case sv == "true" || sv == "false":
+		c.value = sv == "true"
+	case strings.HasPrefix(sv, `"`):
+		var err error
+		if c.value, err = strconv.Unquote(sv); err != nil {
+			return
+		}
+	default:
+		f, err := strconv.ParseFloat(sv, 64)
+		if err != nil {
+			return
+		}
+		c.value = f
+	}
+
+	return c, true
+}
+
+// matches tells if the array element matches the condition.
+func (c *cond) matches(elem interface{}) bool {
+	v, ok := query(elem, c.path)
+	if !ok {
+		return false
+	}
+
+	var cmp int // Result of the comparison: -1, 0 or 1
+	switch cv := c.value.(type) {
+	case float64:
+		f, ok := toFloat(v)
+		if !ok {
+			return false
+		}
+		switch {
+		case f < cv:
+			cmp = -1
+		case f > cv:
+			cmp = 1
+		}
+	case string:
+		s, ok := v.(string)
+		if !ok {
+			return false
+		}
+		cmp = strings.Compare(s, cv)
+	case bool:
+		b, ok := v.(bool)
+		if !ok || (c.op != "==" && c.op != "!=") {
+			return false
+		}
+		if b != cv {
+			cmp = 1
+		}
+	}
+
+	switch c.op {
+	case "==":
+		return cmp == 0
+	case "!=":
+		return cmp != 0
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	case ">":
+		return cmp > 0
+	case ">=":
+		return cmp >= 0
+	}
+	return false
+}
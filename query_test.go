@@ -0,0 +1,194 @@
+package s2prot
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestQuery(t *testing.T) {
+	s := Struct{
+		"title": "Magma Mines",
+		"amm":   true,
+		"playerList": []interface{}{
+			Struct{"name": "a", "teamId": int64(0), "toon": Struct{"region": int64(1)}},
+			Struct{"name": "b", "teamId": int64(1), "toon": Struct{"region": int64(2)}},
+			Struct{"name": "c.d", "teamId": int64(1), "toon": Struct{"region": int64(2)}},
+		},
+		"ints": []interface{}{int64(1), int64(5), int64(3)},
+		// As unmarshaled from JSON:
+		"meta": map[string]interface{}{"APM": 123.0},
+	}
+
+	cases := []struct {
+		path   string
+		value  interface{}
+		exists bool
+	}{
+		{"", nil, false},
+		{"title", "Magma Mines", true},
+		{"amm", true, true},
+		{"nothing", nil, false},
+		{"title.nothing", nil, false},
+		{"meta.APM", 123.0, true},
+		{"playerList.#", int64(3), true},
+		{"playerList.1.name", "b", true},
+		{"playerList.3.name", nil, false},
+		{"playerList.x.name", nil, false},
+		{"playerList.#.name", []interface{}{"a", "b", "c.d"}, true},
+		{"playerList.#.toon.region", []interface{}{int64(1), int64(2), int64(2)}, true},
+		{"playerList.#(teamId==1).name", "b", true},
+		{"playerList.#(teamId==1)#.name", []interface{}{"b", "c.d"}, true},
+		{"playerList.#(teamId!=1)#.name", []interface{}{"a"}, true},
+		{"playerList.#(teamId>=0)#.name", []interface{}{"a", "b", "c.d"}, true},
+		{"playerList.#(teamId==2).name", nil, false},
+		{"playerList.#(teamId==2)#.name", []interface{}{}, true},
+		{`playerList.#(name=="c.d").teamId`, int64(1), true},
+		{`playerList.#(name<"b")#.name`, []interface{}{"a"}, true},
+		{"playerList.#(toon.region==2)#.name", []interface{}{"b", "c.d"}, true},
+		{"playerList.#(teamId=1).name", nil, false},
+		{"ints.#(>2)#", []interface{}{int64(5), int64(3)}, true},
+		{"ints.#(<=1)", int64(1), true},
+		{"title.#", nil, false},
+	}
+
+	for _, c := range cases {
+		r := s.Query(c.path)
+		if r.Exists != c.exists || !reflect.DeepEqual(r.Value, c.value) {
+			t.Errorf("[path: %s] Expected: %v (%v), got: %v (%v)", c.path, c.value, c.exists, r.Value, r.Exists)
+		}
+	}
+
+	if r := s.Query("meta.APM"); r.Int() != 123 || r.Float() != 123 {
+		t.Errorf("Unexpected number conversions: %d, %f", r.Int(), r.Float())
+	}
+}
+
+func TestTypedArrays(t *testing.T) {
+	s := Struct{
+		"ints":    []interface{}{int64(1), "x", int64(3)},
+		"strings": []interface{}{"a", "b"},
+		"structs": []interface{}{Struct{"a": int64(1)}, nil},
+	}
+
+	if got, exp := s.Ints("ints"), []int64{1, 0, 3}; !reflect.DeepEqual(got, exp) {
+		t.Errorf("Expected: %v, got: %v", exp, got)
+	}
+	if got := s.Ints("nothing"); got != nil {
+		t.Errorf("Expected: nil, got: %v", got)
+	}
+	if got, exp := s.Strings("strings"), []string{"a", "b"}; !reflect.DeepEqual(got, exp) {
+		t.Errorf("Expected: %v, got: %v", exp, got)
+	}
+	if got, exp := s.Structs("structs"), []Struct{{"a": int64(1)}, nil}; !reflect.DeepEqual(got, exp) {
+		t.Errorf("Expected: %v, got: %v", exp, got)
+	}
+	for i, exp := range []int64{0, 1, 0, 3, 0} {
+		if got := s.IntAt(i-1, "ints"); got != exp {
+			t.Errorf("[index: %d] Expected: %v, got: %v", i-1, exp, got)
+		}
+	}
+}
+
+func TestGet(t *testing.T) {
+	s := Struct{"version": Struct{"baseBuild": int64(42253)}, "title": "Magma Mines", "zero": int64(0)}
+
+	if v, ok := Get[int64](s, "version", "baseBuild"); !ok || v != 42253 {
+		t.Errorf("Expected: %v, got: %v (%v)", 42253, v, ok)
+	}
+	if v, ok := Get[int64](s, "zero"); !ok || v != 0 {
+		t.Errorf("Expected: %v, got: %v (%v)", 0, v, ok)
+	}
+	if v, ok := Get[int64](s, "title"); ok || v != 0 {
+		t.Errorf("Expected missing, got: %v (%v)", v, ok)
+	}
+	if v, ok := Get[string](s, "title"); !ok || v != "Magma Mines" {
+		t.Errorf("Expected: %v, got: %v (%v)", "Magma Mines", v, ok)
+	}
+	if v, ok := Get[Struct](s, "nothing", "deeper"); ok || v != nil {
+		t.Errorf("Expected missing, got: %v (%v)", v, ok)
+	}
+}
+
+func TestLookup(t *testing.T) {
+	s := Struct{"a": Struct{"zero": int64(0), "none": nil, "b": false}}
+
+	if v, ok := s.LookupInt("a", "zero"); !ok || v != 0 {
+		t.Errorf("Expected: %v, got: %v (%v)", 0, v, ok)
+	}
+	if _, ok := s.LookupInt("a", "missing"); ok {
+		t.Error("Expected missing!")
+	}
+	if v, ok := s.LookupBool("a", "b"); !ok || v {
+		t.Errorf("Expected: %v, got: %v (%v)", false, v, ok)
+	}
+	for _, path := range [][]string{{"a"}, {"a", "zero"}, {"a", "none"}} {
+		if !s.Has(path...) {
+			t.Errorf("Expected %v to exist!", path)
+		}
+	}
+	for _, path := range [][]string{{}, {"b"}, {"a", "missing"}, {"a", "zero", "deeper"}} {
+		if s.Has(path...) {
+			t.Errorf("Expected %v to be missing!", path)
+		}
+	}
+}
+
+func TestMixedOrigin(t *testing.T) {
+	// Decoded integers and numbers (and objects) unmarshaled from JSON:
+	s := Struct{"loops": int64(16), "meta": map[string]interface{}{"APM": 123.5, "player": map[string]interface{}{"id": 2.0}}}
+
+	if v := s.Number("loops"); v != 16 {
+		t.Errorf("Expected: %v, got: %v", 16, v)
+	}
+	if v, ok := s.LookupNumber("meta", "APM"); !ok || v != 123.5 {
+		t.Errorf("Expected: %v, got: %v (%v)", 123.5, v, ok)
+	}
+	if v := s.Number("meta", "player", "id"); v != 2 {
+		t.Errorf("Expected: %v, got: %v", 2, v)
+	}
+	if v, ok := s.LookupNumber("meta"); ok || v != 0 {
+		t.Errorf("Expected not a number, got: %v (%v)", v, ok)
+	}
+	if v, ok := s.LookupStruct("meta", "player"); !ok || len(v) != 1 {
+		t.Errorf("Expected struct, got: %v (%v)", v, ok)
+	}
+	if s.Int("meta", "player", "id") != 0 || s.Float("loops") != 0 {
+		t.Errorf("Expected no coercion in Int() and Float()!")
+	}
+}
+
+func TestFlatten(t *testing.T) {
+	s := Struct{
+		"title": "Magma Mines",
+		"playerList": []interface{}{
+			Struct{"name": "a", "toon": Struct{"region": int64(1)}},
+			map[string]interface{}{"name": "b"},
+		},
+		"empty": Struct{},
+	}
+
+	exp := map[string]interface{}{
+		"title":                    "Magma Mines",
+		"playerList.0.name":        "a",
+		"playerList.0.toon.region": int64(1),
+		"playerList.1.name":        "b",
+	}
+	got := s.Flatten()
+	if !reflect.DeepEqual(got, exp) {
+		t.Errorf("Expected: %v, got: %v", exp, got)
+	}
+	for k, v := range got {
+		if r := s.Query(k); !reflect.DeepEqual(r.Value, v) {
+			t.Errorf("[path: %s] Query expected: %v, got: %v", k, v, r.Value)
+		}
+	}
+
+	var paths []string
+	s.Walk(func(path []string, value interface{}) {
+		paths = append(paths, strings.Join(path, "."))
+	})
+	if expPaths := []string{"playerList.0.name", "playerList.0.toon.region", "playerList.1.name", "title"}; !reflect.DeepEqual(paths, expPaths) {
+		t.Errorf("Expected: %v, got: %v", expPaths, paths)
+	}
+}
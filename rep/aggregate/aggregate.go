@@ -0,0 +1,352 @@
+/*
+Package aggregate implements computing corpus-level statistics from collections of replays,
+e.g. for replay pack reports.
+
+Replays are added as parsed replays, as summaries (see rep.Summary) or as replay files
+parsed concurrently by the aggregator itself. The report contains per-map win counts by matchup,
+race distribution, average game length and per-player aggregates keyed by toon.
+Career timelines of individual players can also be queried (see Aggregator.Career).
+
+Example:
+
+	a := aggregate.New()
+	for _, fe := range a.AddFiles(names, 0) {
+		log.Printf("Failed to parse %s: %v", fe.Name, fe.Err)
+	}
+	rep := a.Report()
+	fmt.Printf("%d games, average length: %v\n", rep.Games, rep.AvgDuration)
+	for mapName, ms := range rep.Maps {
+		if mu := ms.Matchups["PvT"]; mu != nil {
+			fmt.Printf("%s PvT: Protoss winrate %.1f%%\n", mapName, mu.WinRate("P")*100)
+		}
+	}
+*/
+package aggregate
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/icza/s2prot/rep"
+	"github.com/icza/s2prot/rep/corpus"
+)
+
+// Report holds the aggregated statistics of the added replays.
+type Report struct {
+	Games            int                     `json:"games"`            // Number of games
+	TotalDuration    time.Duration           `json:"totalDuration"`    // Total real duration of the games
+	AvgDuration      time.Duration           `json:"avgDuration"`      // Average real duration of the games
+	RaceDistribution map[string]int          `json:"raceDistribution"` // Number of players by assigned race name, e.g. "Zerg"
+	Matchups         map[string]int          `json:"matchups"`         // Number of games by matchup, see Matchup
+	Maps             map[string]*MapStats    `json:"maps"`             // Statistics by map name
+	Players          map[string]*PlayerStats `json:"players"`          // Statistics of human players by toon handle
+}
+
+// MapStats holds the statistics of a map.
+type MapStats struct {
+	Games    int                      `json:"games"`    // Number of games played on the map
+	Matchups map[string]*MatchupStats `json:"matchups"` // Statistics by matchup, see Matchup
+}
+
+// MatchupStats holds the statistics of a matchup.
+type MatchupStats struct {
+	Games int            `json:"games"` // Number of games
+	Wins  map[string]int `json:"wins"`  // Number of wins by the race letters of the winning team, e.g. "P" or "TZ"
+}
+
+// WinRate returns the win rate of the team specified by its race letters (e.g. "P" or "TZ") in the range 0..1.
+// Games without a known winner are counted in the total.
+// In mirror matchups (e.g. "ZvZ") the team wins all decided games.
+func (ms *MatchupStats) WinRate(team string) float64 {
+	if ms.Games == 0 {
+		return 0
+	}
+	return float64(ms.Wins[team]) / float64(ms.Games)
+}
+
+// PlayerStats holds the aggregated statistics of a human player.
+type PlayerStats struct {
+	Toon      string         `json:"toon"`      // Toon handle
+	Name      string         `json:"name"`      // Name in the latest game
+	ClanTag   string         `json:"clanTag"`   // Clan tag in the latest game
+	Games     int            `json:"games"`     // Number of games
+	Wins      int            `json:"wins"`      // Number of victories
+	Losses    int            `json:"losses"`    // Number of defeats
+	Races     map[string]int `json:"races"`     // Number of games by assigned race name
+	AvgAPM    float64        `json:"avgApm"`    // Average APM of the games where it is known
+	AvgMMR    float64        `json:"avgMmr"`    // Average MMR of the games where it is known
+	MaxMMR    int64          `json:"maxMmr"`    // Highest MMR, 0 if not known
+	FirstGame time.Time      `json:"firstGame"` // Date of the first game
+	LastGame  time.Time      `json:"lastGame"`  // Date of the last game
+
+	apmSum, mmrSum     float64 // Sum of the known APMs and MMRs
+	apmCount, mmrCount int     // Number of the known APMs and MMRs
+}
+
+// WinRate returns the win rate of the player in the range 0..1, games without a result are counted in the total.
+func (ps *PlayerStats) WinRate() float64 {
+	if ps.Games == 0 {
+		return 0
+	}
+	return float64(ps.Wins) / float64(ps.Games)
+}
+
+// Aggregator aggregates statistics of replays.
+// It is safe for concurrent use.
+type Aggregator struct {
+	// BuildClassifier enables classifying the builds of players of parsed replays added (see Career),
+	// which requires decoded tracker events. It must be set before adding replays.
+	BuildClassifier rep.BuildClassifier
+
+	mu      sync.Mutex              // Protects report and careers
+	report  Report                  // Aggregated statistics
+	careers map[string][]CareerGame // Career games of players by toon, in the order of adding
+}
+
+// New returns a new Aggregator.
+func New() *Aggregator {
+	return &Aggregator{
+		report: Report{
+			RaceDistribution: map[string]int{},
+			Matchups:         map[string]int{},
+			Maps:             map[string]*MapStats{},
+			Players:          map[string]*PlayerStats{},
+		},
+		careers: map[string][]CareerGame{},
+	}
+}
+
+// AddRep adds a parsed replay.
+// If BuildClassifier is set, builds of the players are classified.
+func (a *Aggregator) AddRep(r *rep.Rep) {
+	s := r.Summary()
+	var builds []string
+	if a.BuildClassifier != nil {
+		builds = make([]string, len(s.Players))
+		for i := range s.Players {
+			if s.Players[i].Toon != "" {
+				builds[i], _ = r.ClassifyBuild(i, a.BuildClassifier)
+			}
+		}
+	}
+	a.add(s, builds)
+}
+
+// AddSummary adds the summary of a replay.
+func (a *Aggregator) AddSummary(s *rep.Summary) {
+	a.add(s, nil)
+}
+
+// add adds the summary of a replay with the optional build labels of the players.
+func (a *Aggregator) add(s *rep.Summary, builds []string) {
+	matchup, winner := Matchup(s), winnerTeam(s)
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	rp := &a.report
+	rp.Games++
+	rp.TotalDuration += s.Duration
+	rp.Matchups[matchup]++
+
+	ms := rp.Maps[s.Map]
+	if ms == nil {
+		ms = &MapStats{Matchups: map[string]*MatchupStats{}}
+		rp.Maps[s.Map] = ms
+	}
+	ms.Games++
+	mus := ms.Matchups[matchup]
+	if mus == nil {
+		mus = &MatchupStats{Wins: map[string]int{}}
+		ms.Matchups[matchup] = mus
+	}
+	mus.Games++
+	if winner != "" {
+		mus.Wins[winner]++
+	}
+
+	for i := range s.Players {
+		sp := &s.Players[i]
+		rp.RaceDistribution[sp.Race]++
+		if sp.Toon == "" {
+			continue
+		}
+		ps := rp.Players[sp.Toon]
+		if ps == nil {
+			ps = &PlayerStats{Toon: sp.Toon, Races: map[string]int{}}
+			rp.Players[sp.Toon] = ps
+		}
+		ps.add(s, sp)
+
+		cg := CareerGame{
+			Date:     s.Date,
+			Map:      s.Map,
+			Matchup:  playerMatchup(s, i),
+			Name:     sp.Name,
+			Race:     sp.Race,
+			Result:   sp.Result,
+			Duration: s.Duration,
+			APM:      sp.APM,
+			MMR:      sp.MMR,
+		}
+		if builds != nil {
+			cg.Build = builds[i]
+		}
+		a.careers[sp.Toon] = append(a.careers[sp.Toon], cg)
+	}
+}
+
+// add adds a game of the player.
+func (ps *PlayerStats) add(s *rep.Summary, sp *rep.SummaryPlayer) {
+	ps.Games++
+	switch sp.Result {
+	case rep.ResultVictory.Name:
+		ps.Wins++
+	case rep.ResultDefeat.Name:
+		ps.Losses++
+	}
+	ps.Races[sp.Race]++
+	if sp.APM > 0 {
+		ps.apmSum += sp.APM
+		ps.apmCount++
+		ps.AvgAPM = ps.apmSum / float64(ps.apmCount)
+	}
+	if sp.MMR > 0 {
+		ps.mmrSum += float64(sp.MMR)
+		ps.mmrCount++
+		ps.AvgMMR = ps.mmrSum / float64(ps.mmrCount)
+		if sp.MMR > ps.MaxMMR {
+			ps.MaxMMR = sp.MMR
+		}
+	}
+	if ps.FirstGame.IsZero() || s.Date.Before(ps.FirstGame) {
+		ps.FirstGame = s.Date
+	}
+	if ps.LastGame.IsZero() || !s.Date.Before(ps.LastGame) {
+		ps.LastGame = s.Date
+		ps.Name, ps.ClanTag = sp.Name, sp.ClanTag
+	}
+}
+
+// AddFiles parses and adds the specified replay files, using the given number of concurrent workers
+// (0 means runtime.NumCPU()). Files that fail to parse are skipped and returned as FileErrors
+// in the order of the names.
+func (a *Aggregator) AddFiles(names []string, workers int) []*corpus.FileError {
+	return corpus.Process(names, workers, func(i int, name string) error {
+		return a.addFile(name)
+	})
+}
+
+// addFile parses and adds a replay file.
+func (a *Aggregator) addFile(name string) error {
+	r, err := rep.NewFromFile(name)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	a.AddRep(r)
+	return nil
+}
+
+// Report returns a snapshot of the aggregated statistics.
+// The returned report is not affected by replays added later.
+func (a *Aggregator) Report() *Report {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	src := &a.report
+	rp := &Report{
+		Games:            src.Games,
+		TotalDuration:    src.TotalDuration,
+		RaceDistribution: copyCounts(src.RaceDistribution),
+		Matchups:         copyCounts(src.Matchups),
+		Maps:             make(map[string]*MapStats, len(src.Maps)),
+		Players:          make(map[string]*PlayerStats, len(src.Players)),
+	}
+	if rp.Games > 0 {
+		rp.AvgDuration = rp.TotalDuration / time.Duration(rp.Games)
+	}
+	for name, ms := range src.Maps {
+		ms2 := &MapStats{Games: ms.Games, Matchups: make(map[string]*MatchupStats, len(ms.Matchups))}
+		for mu, mus := range ms.Matchups {
+			ms2.Matchups[mu] = &MatchupStats{Games: mus.Games, Wins: copyCounts(mus.Wins)}
+		}
+		rp.Maps[name] = ms2
+	}
+	for toon, ps := range src.Players {
+		ps2 := *ps
+		ps2.Races = copyCounts(ps.Races)
+		rp.Players[toon] = &ps2
+	}
+	return rp
+}
+
+// copyCounts returns a copy of the counts map.
+func copyCounts(m map[string]int) map[string]int {
+	m2 := make(map[string]int, len(m))
+	for k, v := range m {
+		m2[k] = v
+	}
+	return m2
+}
+
+// Matchup returns the normalized matchup of the game: the race letters of the teams
+// (sorted within teams) ordered alphabetically and joined by 'v', e.g. "PvT" or "PZvTT".
+// Unlike rep.Summary.Matchup, it does not depend on the order of players, so the same matchup
+// always has the same key.
+func Matchup(s *rep.Summary) string {
+	teams := teamRaces(s)
+	sides := make([]string, 0, len(teams))
+	for _, races := range teams {
+		sides = append(sides, races)
+	}
+	sort.Strings(sides)
+	return strings.Join(sides, "v")
+}
+
+// winnerTeam returns the race letters of the winning team (in the format of Matchup),
+// empty string if there is no single winning team.
+func winnerTeam(s *rep.Summary) string {
+	winnerID, found := int64(0), false
+	for i := range s.Players {
+		sp := &s.Players[i]
+		if sp.Result != rep.ResultVictory.Name {
+			continue
+		}
+		if found && sp.Team != winnerID {
+			return ""
+		}
+		winnerID, found = sp.Team, true
+	}
+	if !found {
+		return ""
+	}
+	return teamRaces(s)[winnerID]
+}
+
+// teamRaces returns the sorted race letters of the teams, mapped from team ID.
+func teamRaces(s *rep.Summary) map[int64]string {
+	letters := map[int64][]byte{}
+	for i := range s.Players {
+		sp := &s.Players[i]
+		letters[sp.Team] = append(letters[sp.Team], raceLetter(sp.Race))
+	}
+	teams := make(map[int64]string, len(letters))
+	for teamID, ls := range letters {
+		sort.Slice(ls, func(i, j int) bool { return ls[i] < ls[j] })
+		teams[teamID] = string(ls)
+	}
+	return teams
+}
+
+// raceLetter returns the letter of the race specified by its name, '-' for unknown races.
+func raceLetter(name string) byte {
+	for _, r := range rep.Races {
+		if r.Name == name {
+			return byte(r.Letter)
+		}
+	}
+	return byte(rep.RaceUnknown.Letter)
+}
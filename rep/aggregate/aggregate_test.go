@@ -0,0 +1,110 @@
+package aggregate
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/icza/s2prot/rep"
+)
+
+func TestAggregator(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2020, 1, d, 0, 0, 0, 0, time.UTC) }
+	a := New()
+	a.AddSummary(&rep.Summary{Map: "Ephemeron", Date: day(1), Duration: 10 * time.Minute, Players: []rep.SummaryPlayer{
+		{Name: "Alice", Toon: "2-S2-1-1", Team: 1, Race: "Terran", Result: "Defeat", APM: 100, MMR: 4000},
+		{Name: "Bob", Toon: "2-S2-1-2", Team: 2, Race: "Protoss", Result: "Victory", APM: 200},
+	}})
+	a.AddSummary(&rep.Summary{Map: "Ephemeron", Date: day(2), Duration: 20 * time.Minute, Players: []rep.SummaryPlayer{
+		{Name: "Bob", Toon: "2-S2-1-2", Team: 1, Race: "Protoss", Result: "Victory", APM: 300, MMR: 3000},
+		{Name: "Alicia", ClanTag: "Clan", Toon: "2-S2-1-1", Team: 2, Race: "Terran", Result: "Defeat", MMR: 5000},
+	}})
+	a.AddSummary(&rep.Summary{Map: "Triton", Date: day(3), Duration: 30 * time.Minute, Players: []rep.SummaryPlayer{
+		{Name: "Alice", Toon: "2-S2-1-1", Team: 1, Race: "Zerg", Result: "Unknown"},
+		{Name: "A.I. 1", Team: 2, Race: "Zerg", Result: "Unknown"},
+	}})
+
+	rp := a.Report()
+	a.AddSummary(&rep.Summary{Map: "Triton"}) // Must not affect rp
+
+	if rp.Games != 3 || rp.AvgDuration != 20*time.Minute {
+		t.Errorf("Expected: %d games, %v, got: %d games, %v", 3, 20*time.Minute, rp.Games, rp.AvgDuration)
+	}
+	if exp := (map[string]int{"Terran": 2, "Protoss": 2, "Zerg": 2}); !equalCounts(rp.RaceDistribution, exp) {
+		t.Errorf("Expected: %v, got: %v", exp, rp.RaceDistribution)
+	}
+	if exp := (map[string]int{"PvT": 2, "ZvZ": 1}); !equalCounts(rp.Matchups, exp) {
+		t.Errorf("Expected: %v, got: %v", exp, rp.Matchups)
+	}
+
+	if ms := rp.Maps["Ephemeron"]; ms == nil || ms.Games != 2 || ms.Matchups["PvT"] == nil {
+		t.Errorf("Unexpected map stats: %+v", ms)
+	} else if wr := ms.Matchups["PvT"].WinRate("P"); wr != 1 {
+		t.Errorf("Expected: %v, got: %v", 1, wr)
+	}
+	if mus := rp.Maps["Triton"].Matchups["ZvZ"]; mus.Games != 1 || len(mus.Wins) != 0 {
+		t.Errorf("Unexpected matchup stats: %+v", mus)
+	}
+
+	if len(rp.Players) != 2 {
+		t.Errorf("Expected: %d players, got: %d", 2, len(rp.Players))
+	}
+	alice := rp.Players["2-S2-1-1"]
+	if alice.Name != "Alice" || alice.Games != 3 || alice.Wins != 0 || alice.Losses != 2 ||
+		alice.AvgAPM != 100 || alice.AvgMMR != 4500 || alice.MaxMMR != 5000 ||
+		!alice.FirstGame.Equal(day(1)) || !alice.LastGame.Equal(day(3)) || alice.Races["Terran"] != 2 {
+		t.Errorf("Unexpected player stats: %+v", alice)
+	}
+	if bob := rp.Players["2-S2-1-2"]; bob.WinRate() != 1 || bob.AvgAPM != 250 {
+		t.Errorf("Unexpected player stats: %+v", bob)
+	}
+}
+
+func TestMatchup(t *testing.T) {
+	cases := []struct {
+		players []rep.SummaryPlayer
+		exp     string
+		winner  string
+	}{
+		{[]rep.SummaryPlayer{{Team: 1, Race: "Zerg"}, {Team: 2, Race: "Protoss", Result: "Victory"}}, "PvZ", "P"},
+		{[]rep.SummaryPlayer{
+			{Team: 2, Race: "Zerg", Result: "Victory"}, {Team: 1, Race: "Terran"},
+			{Team: 2, Race: "Protoss", Result: "Victory"}, {Team: 1, Race: "Terran"},
+		}, "PZvTT", "PZ"},
+		{[]rep.SummaryPlayer{{Team: 1, Race: "Terran", Result: "Victory"}, {Team: 2, Race: "Unknown", Result: "Victory"}}, "-vT", ""},
+		{nil, "", ""},
+	}
+	for _, c := range cases {
+		s := &rep.Summary{Players: c.players}
+		if got := Matchup(s); got != c.exp {
+			t.Errorf("Expected: %s, got: %s", c.exp, got)
+		}
+		if got := winnerTeam(s); got != c.winner {
+			t.Errorf("Expected: %s, got: %s", c.winner, got)
+		}
+	}
+}
+
+func TestAddFiles(t *testing.T) {
+	a := New()
+	errs := a.AddFiles([]string{filepath.Join(os.TempDir(), "non-existing.SC2Replay")}, 2)
+	if len(errs) != 1 || errs[0].Err == nil {
+		t.Errorf("Expected 1 error, got: %v", errs)
+	}
+	if rp := a.Report(); rp.Games != 0 {
+		t.Errorf("Expected: %d games, got: %d", 0, rp.Games)
+	}
+}
+
+func equalCounts(m1, m2 map[string]int) bool {
+	if len(m1) != len(m2) {
+		return false
+	}
+	for k, v := range m1 {
+		if m2[k] != v {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,178 @@
+/*
+
+Career timelines of players across a corpus.
+
+*/
+
+package aggregate
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/icza/s2prot/rep"
+)
+
+// CareerGame is a game in the career of a player.
+type CareerGame struct {
+	Date     time.Time     `json:"date"`            // Date+time of the game
+	Map      string        `json:"map"`             // Map name
+	Matchup  string        `json:"matchup"`         // Matchup from the player's perspective (own team first), e.g. "TvZ"
+	Name     string        `json:"name"`            // Name of the player in the game
+	Race     string        `json:"race"`            // Assigned race name
+	Result   string        `json:"result"`          // Result, e.g. "Victory"
+	Duration time.Duration `json:"duration"`        // Real duration of the game
+	APM      float64       `json:"apm"`             // APM, 0 if not known
+	MMR      int64         `json:"mmr"`             // MMR, 0 if not known
+	Build    string        `json:"build,omitempty"` // Build label, empty if not classified (see Aggregator.BuildClassifier)
+}
+
+// Career is the career timeline of a player (toon) across a corpus.
+type Career struct {
+	Toon  string       `json:"toon"`  // Toon handle
+	Games []CareerGame `json:"games"` // Games of the player ordered by date
+}
+
+// Record is a win-loss record.
+type Record struct {
+	Games  int `json:"games"`  // Number of games
+	Wins   int `json:"wins"`   // Number of victories
+	Losses int `json:"losses"` // Number of defeats
+}
+
+// WinRate returns the win rate in the range 0..1, games without a result are counted in the total.
+func (rc *Record) WinRate() float64 {
+	if rc.Games == 0 {
+		return 0
+	}
+	return float64(rc.Wins) / float64(rc.Games)
+}
+
+// add adds a game with the given result.
+func (rc *Record) add(result string) {
+	rc.Games++
+	switch result {
+	case rep.ResultVictory.Name:
+		rc.Wins++
+	case rep.ResultDefeat.Name:
+		rc.Losses++
+	}
+}
+
+// TrendPoint is a point of a trend over time.
+type TrendPoint struct {
+	Date  time.Time `json:"date"`  // Date of the game
+	Value float64   `json:"value"` // Value in the game
+}
+
+// BuildCount is the number of games played with a build.
+type BuildCount struct {
+	Build string `json:"build"` // Build label
+	Count int    `json:"count"` // Number of games
+}
+
+// CareerStats holds the statistics computed from a career.
+type CareerStats struct {
+	Record    Record             `json:"record"`    // Overall record
+	ByMatchup map[string]*Record `json:"byMatchup"` // Records by matchup (player's perspective)
+	ByMap     map[string]*Record `json:"byMap"`     // Records by map name
+	APMTrend  []TrendPoint       `json:"apmTrend"`  // APM of the games where it is known
+	MMRTrend  []TrendPoint       `json:"mmrTrend"`  // MMR of the games where it is known
+	Builds    []BuildCount       `json:"builds"`    // Classified builds, most common first
+}
+
+// Stats computes the statistics of the career.
+func (c *Career) Stats() *CareerStats {
+	cs := &CareerStats{
+		ByMatchup: map[string]*Record{},
+		ByMap:     map[string]*Record{},
+	}
+	buildCounts := map[string]int{}
+	for i := range c.Games {
+		g := &c.Games[i]
+		cs.Record.add(g.Result)
+		addRecord(cs.ByMatchup, g.Matchup, g.Result)
+		addRecord(cs.ByMap, g.Map, g.Result)
+		if g.APM > 0 {
+			cs.APMTrend = append(cs.APMTrend, TrendPoint{g.Date, g.APM})
+		}
+		if g.MMR > 0 {
+			cs.MMRTrend = append(cs.MMRTrend, TrendPoint{g.Date, float64(g.MMR)})
+		}
+		if g.Build != "" {
+			buildCounts[g.Build]++
+		}
+	}
+
+	for build, count := range buildCounts {
+		cs.Builds = append(cs.Builds, BuildCount{build, count})
+	}
+	sort.Slice(cs.Builds, func(i, j int) bool {
+		if cs.Builds[i].Count != cs.Builds[j].Count {
+			return cs.Builds[i].Count > cs.Builds[j].Count
+		}
+		return cs.Builds[i].Build < cs.Builds[j].Build
+	})
+
+	return cs
+}
+
+// addRecord adds a game with the given result to the record of the key.
+func addRecord(m map[string]*Record, key, result string) {
+	rc := m[key]
+	if rc == nil {
+		rc = &Record{}
+		m[key] = rc
+	}
+	rc.add(result)
+}
+
+// Filter returns a career containing the games for which keep returns true.
+func (c *Career) Filter(keep func(g *CareerGame) bool) *Career {
+	c2 := &Career{Toon: c.Toon}
+	for i := range c.Games {
+		if keep(&c.Games[i]) {
+			c2.Games = append(c2.Games, c.Games[i])
+		}
+	}
+	return c2
+}
+
+// Between returns a career containing the games played in the [from, to) time range.
+// A zero from or to means no limit.
+func (c *Career) Between(from, to time.Time) *Career {
+	return c.Filter(func(g *CareerGame) bool {
+		return (from.IsZero() || !g.Date.Before(from)) && (to.IsZero() || g.Date.Before(to))
+	})
+}
+
+// Career returns the career timeline of the player specified by its toon handle,
+// nil if the player has no games. The returned career is not affected by replays added later.
+func (a *Aggregator) Career(toon string) *Career {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	games := a.careers[toon]
+	if len(games) == 0 {
+		return nil
+	}
+	c := &Career{Toon: toon, Games: append([]CareerGame(nil), games...)}
+	sort.SliceStable(c.Games, func(i, j int) bool { return c.Games[i].Date.Before(c.Games[j].Date) })
+	return c
+}
+
+// playerMatchup returns the matchup of the game from the perspective of the player specified by its index:
+// the race letters of the player's team first, then the other teams as in Matchup, e.g. "TvZ" or "TvPvZ".
+func playerMatchup(s *rep.Summary, playerIdx int) string {
+	teams := teamRaces(s)
+	ownTeam := s.Players[playerIdx].Team
+	sides := make([]string, 0, len(teams))
+	for teamID, races := range teams {
+		if teamID != ownTeam {
+			sides = append(sides, races)
+		}
+	}
+	sort.Strings(sides)
+	return strings.Join(append([]string{teams[ownTeam]}, sides...), "v")
+}
@@ -0,0 +1,73 @@
+package aggregate
+
+import (
+	"testing"
+	"time"
+
+	"github.com/icza/s2prot/rep"
+)
+
+func TestCareer(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2020, 1, d, 0, 0, 0, 0, time.UTC) }
+	const toon = "2-S2-1-1"
+	a := New()
+	// Added out of order:
+	a.AddSummary(&rep.Summary{Map: "Triton", Date: day(3), Players: []rep.SummaryPlayer{
+		{Toon: "2-S2-1-2", Team: 1, Race: "Protoss", Result: "Victory"},
+		{Toon: toon, Team: 2, Race: "Zerg", Result: "Defeat", APM: 150, MMR: 4100},
+	}})
+	a.AddSummary(&rep.Summary{Map: "Ephemeron", Date: day(1), Players: []rep.SummaryPlayer{
+		{Toon: toon, Team: 1, Race: "Zerg", Result: "Victory", APM: 100, MMR: 4000},
+		{Toon: "2-S2-1-2", Team: 2, Race: "Protoss", Result: "Defeat"},
+	}})
+	a.AddSummary(&rep.Summary{Map: "Ephemeron", Date: day(2), Players: []rep.SummaryPlayer{
+		{Toon: "2-S2-1-3", Team: 1, Race: "Terran", Result: "Defeat"},
+		{Toon: toon, Team: 2, Race: "Zerg", Result: "Victory"},
+	}})
+
+	if c := a.Career("2-S2-1-9"); c != nil {
+		t.Errorf("Expected no career, got: %+v", c)
+	}
+
+	c := a.Career(toon)
+	if len(c.Games) != 3 {
+		t.Fatalf("Expected: %d games, got: %d", 3, len(c.Games))
+	}
+	for i, exp := range []string{"ZvP", "ZvT", "ZvP"} {
+		if g := c.Games[i]; !g.Date.Equal(day(i+1)) || g.Matchup != exp {
+			t.Errorf("[%d] Expected: %v %s, got: %v %s", i, day(i+1), exp, g.Date, g.Matchup)
+		}
+	}
+
+	cs := c.Stats()
+	if cs.Record != (Record{3, 2, 1}) {
+		t.Errorf("Expected: %+v, got: %+v", Record{3, 2, 1}, cs.Record)
+	}
+	if rc := cs.ByMatchup["ZvP"]; rc == nil || *rc != (Record{2, 1, 1}) || rc.WinRate() != 0.5 {
+		t.Errorf("Unexpected ZvP record: %+v", rc)
+	}
+	if rc := cs.ByMap["Ephemeron"]; rc == nil || *rc != (Record{2, 2, 0}) {
+		t.Errorf("Unexpected map record: %+v", rc)
+	}
+	if len(cs.APMTrend) != 2 || cs.APMTrend[0].Value != 100 || len(cs.MMRTrend) != 2 || cs.MMRTrend[1].Value != 4100 {
+		t.Errorf("Unexpected trends: %v %v", cs.APMTrend, cs.MMRTrend)
+	}
+
+	if c2 := c.Between(day(2), time.Time{}); len(c2.Games) != 2 || !c2.Games[0].Date.Equal(day(2)) {
+		t.Errorf("Unexpected filtered career: %+v", c2)
+	}
+}
+
+func TestCareerBuilds(t *testing.T) {
+	c := &Career{Games: []CareerGame{{Build: "12 Pool"}, {Build: "3 Hatch"}, {}, {Build: "3 Hatch"}}}
+	exp := []BuildCount{{"3 Hatch", 2}, {"12 Pool", 1}}
+	builds := c.Stats().Builds
+	if len(builds) != len(exp) {
+		t.Fatalf("Expected: %v, got: %v", exp, builds)
+	}
+	for i := range exp {
+		if builds[i] != exp[i] {
+			t.Errorf("Expected: %v, got: %v", exp, builds)
+		}
+	}
+}
@@ -0,0 +1,159 @@
+/*
+
+APM (actions per minute) calculation from game events.
+
+*/
+
+package rep
+
+import (
+	"time"
+
+	"github.com/icza/s2prot"
+)
+
+// apmEvtNames holds the names of game events that count as actions in APM calculations.
+// Repeated commands are also actions, see isAction.
+var apmEvtNames = map[string]bool{
+	"Cmd":                true,
+	"SelectionDelta":     true,
+	"ControlGroupUpdate": true,
+}
+
+// isAction tells if the game event counts as an action in APM calculations.
+// Repeated commands are counted by their CommandManagerState events, the CmdUpdateTargetPoint and
+// CmdUpdateTargetUnit events preceding them only update their targets (see Commands).
+func isAction(e s2prot.Event) bool {
+	return apmEvtNames[e.Name] || isRepeatCmd(e)
+}
+
+// evtUserID returns the user ID of a game event.
+// Old replays store the 1-based user ID under the "playerId" key.
+func evtUserID(e s2prot.Event) (userID int64, ok bool) {
+	userid, _ := e.UserIDValue().(s2prot.Struct)
+	if userID, ok = userid.LookupInt("userId"); ok {
+		return
+	}
+	if userID, ok = userid.LookupInt("playerId"); ok {
+		userID--
+	}
+	return
+}
+
+// userPlayerIdxs returns the index in Details.Players() of human players, mapped from user ID.
+func (r *Rep) userPlayerIdxs() map[int64]int {
+	sm := r.SlotMapping()
+	m := map[int64]int{}
+	for i := range sm.playerSlots {
+		if userID, ok := sm.PlayerUserID(i); ok {
+			m[userID] = i
+		}
+	}
+	return m
+}
+
+// PlayerAPM returns the average APM of the player specified by its index in Details.Players(),
+// calculated from the game events using real (wall clock) minutes.
+// ok is false if game events were not decoded or the player is not a human player.
+func (r *Rep) PlayerAPM(playerIdx int) (apm float64, ok bool) {
+	apms := r.apms()
+	apm, ok = apms[playerIdx]
+	return
+}
+
+// apms returns the average APM of human players mapped from player index.
+// nil is returned if game events were not decoded.
+func (r *Rep) apms() map[int]float64 {
+	if r.GameEvts == nil {
+		return nil
+	}
+
+	userPlayerIdxs := r.userPlayerIdxs()
+	actions := make(map[int]int, len(userPlayerIdxs))
+	for _, playerIdx := range userPlayerIdxs {
+		actions[playerIdx] = 0
+	}
+	for _, e := range r.GameEvts {
+		if !isAction(e) {
+			continue
+		}
+		if userID, ok := evtUserID(e); ok {
+			if playerIdx, ok := userPlayerIdxs[userID]; ok {
+				actions[playerIdx]++
+			}
+		}
+	}
+
+	apms := make(map[int]float64, len(actions))
+	mins := r.RealDuration().Minutes()
+	for playerIdx, count := range actions {
+		if mins > 0 {
+			apms[playerIdx] = float64(count) / mins
+		} else {
+			apms[playerIdx] = 0
+		}
+	}
+	return apms
+}
+
+// Default parameters of APM timelines.
+const (
+	DefaultAPMWindow = 60 * time.Second // Default sliding window size
+	DefaultAPMStep   = 10 * time.Second // Default step between data points
+)
+
+// APMPoint is a data point of an APM timeline.
+type APMPoint struct {
+	Time time.Duration // Real (wall clock) time of the data point, the end of the window
+	APM  float64       // APM calculated over the window ending at Time
+}
+
+// APMTimeline returns the APM of the player specified by its index in Details.Players()
+// as a time series: APM is calculated over a sliding window of the given size, sampled in the given steps
+// (use DefaultAPMWindow and DefaultAPMStep for values similar to the in-game APM chart).
+// Times are real (wall clock) times. At the beginning of the game where less time has elapsed than the window size,
+// the elapsed time is used as the window.
+//
+// nil is returned if game events were not decoded, the player is not a human player or window or step is not positive.
+func (r *Rep) APMTimeline(playerIdx int, window, step time.Duration) (points []APMPoint) {
+	if r.GameEvts == nil || window <= 0 || step <= 0 {
+		return nil
+	}
+	human := false
+	for _, idx := range r.userPlayerIdxs() {
+		if idx == playerIdx {
+			human = true
+		}
+	}
+	if !human {
+		return nil
+	}
+
+	var actions []s2prot.Event
+	for _, e := range r.GameEvtsOf(playerIdx) {
+		if isAction(e) {
+			actions = append(actions, e)
+		}
+	}
+	x := NewEventIndex(actions)
+
+	loopsPerSec := LoopsPerSecond(r.Details.GameSpeed())
+	toLoop := func(t time.Duration) int64 { return int64(t.Seconds() * loopsPerSec) }
+
+	duration := r.RealDuration()
+	for t := step; ; t += step {
+		if t > duration {
+			if t-step >= duration {
+				break
+			}
+			t = duration // Last data point at the end of the game
+		}
+		from := t - window
+		if from < 0 {
+			from = 0
+		}
+		count := len(x.Between(toLoop(from), toLoop(t)))
+		points = append(points, APMPoint{Time: t, APM: float64(count) / (t - from).Minutes()})
+	}
+	return
+}
@@ -0,0 +1,38 @@
+package rep
+
+import (
+	"testing"
+	"time"
+
+	"github.com/icza/s2prot"
+)
+
+func TestAPMTimeline(t *testing.T) {
+	r := newTestRep(humanSlot(0))
+	r.Header = Header{Struct: s2prot.Struct{"elapsedGameLoops": int64(16 * 1.4 * 25)}} // 25 real seconds on Faster
+	r.Details.Struct["gameSpeed"] = int64(4)
+	// 1 action per real second (22.4 loops), and some non-actions:
+	for i := 0; i < 25; i++ {
+		for _, name := range []string{"Cmd", "CameraUpdate"} {
+			r.GameEvts = append(r.GameEvts, gameEvt(int64(float64(i)*22.4), 0, name, nil))
+		}
+	}
+
+	points := r.APMTimeline(0, 20*time.Second, 10*time.Second)
+	exp := []APMPoint{{10 * time.Second, 60}, {20 * time.Second, 60}, {25 * time.Second, 60}}
+	if len(points) != len(exp) {
+		t.Fatalf("Expected %d points, got: %v", len(exp), points)
+	}
+	for i := range exp {
+		if points[i].Time != exp[i].Time || points[i].APM < exp[i].APM-0.01 || points[i].APM > exp[i].APM+0.01 {
+			t.Errorf("[%d] Expected: %v, got: %v", i, exp[i], points[i])
+		}
+	}
+
+	if points := r.APMTimeline(1, DefaultAPMWindow, DefaultAPMStep); points != nil {
+		t.Errorf("Expected no points for unknown player, got: %v", points)
+	}
+	if points := r.APMTimeline(0, 0, DefaultAPMStep); points != nil {
+		t.Errorf("Expected no points for invalid window, got: %v", points)
+	}
+}
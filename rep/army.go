@@ -0,0 +1,91 @@
+/*
+
+Army composition of the players.
+
+*/
+
+package rep
+
+import "time"
+
+// ArmyComposition is the army composition of a player at a given loop.
+// Army units are non-worker units having balance data; alternate forms (e.g. sieged tanks) are counted
+// as their base unit type.
+type ArmyComposition struct {
+	Units    map[string]int // Army unit counts mapped from unit type name
+	Supply   float64        // Total supply of the army
+	Minerals int            // Total mineral cost of the army
+	Gas      int            // Total gas cost of the army
+}
+
+// Value returns the total value (minerals plus gas) of the army.
+func (ac *ArmyComposition) Value() int {
+	return ac.Minerals + ac.Gas
+}
+
+// ArmySnapshot is the army composition of all players at a given time.
+type ArmySnapshot struct {
+	Loop   int64              // Game loop of the snapshot
+	Time   time.Duration      // Real (wall clock) time of the snapshot
+	Armies []*ArmyComposition // Army compositions, index is the same as in Details.Players()
+}
+
+// ArmyComposition returns the army compositions of the players at the given loop,
+// index is the same as in Details.Players().
+// nil is returned if tracker events were not decoded.
+func (r *Rep) ArmyComposition(loop int64) []*ArmyComposition {
+	units := r.Units()
+	if units == nil {
+		return nil
+	}
+
+	armies := make([]*ArmyComposition, len(r.Details.Players()))
+	for i := range armies {
+		armies[i] = &ArmyComposition{Units: map[string]int{}}
+	}
+	for _, u := range units {
+		if u.Born > loop {
+			break // Units are in the order of their birth
+		}
+		if !u.AliveAt(loop) {
+			continue
+		}
+		idx := int(u.OwnerAt(loop)) - 1
+		if idx < 0 || idx >= len(armies) {
+			continue
+		}
+		baseType, ub := balanceOf(u.TypeAt(loop))
+		if ub == nil || ub.worker {
+			continue
+		}
+		ac := armies[idx]
+		ac.Units[baseType]++
+		ac.Supply += ub.supply
+		ac.Minerals += ub.minerals
+		ac.Gas += ub.gas
+	}
+	return armies
+}
+
+// ArmySnapshots returns the army compositions of the players sampled in the given steps of real time,
+// starting at the first step and ending at the end of the game, e.g. for composition graphs.
+// nil is returned if tracker events were not decoded or step is not positive.
+func (r *Rep) ArmySnapshots(step time.Duration) (snapshots []ArmySnapshot) {
+	if r.TrackerEvts == nil || step <= 0 {
+		return nil
+	}
+
+	loopsPerSec := LoopsPerSecond(r.Details.GameSpeed())
+	duration := r.RealDuration()
+	for t := step; ; t += step {
+		if t > duration {
+			if t-step >= duration {
+				break
+			}
+			t = duration // Last snapshot at the end of the game
+		}
+		loop := int64(t.Seconds() * loopsPerSec)
+		snapshots = append(snapshots, ArmySnapshot{Loop: loop, Time: t, Armies: r.ArmyComposition(loop)})
+	}
+	return
+}
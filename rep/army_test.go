@@ -0,0 +1,71 @@
+package rep
+
+import (
+	"testing"
+	"time"
+
+	"github.com/icza/s2prot"
+)
+
+func TestArmyComposition(t *testing.T) {
+	r := &Rep{}
+	r.Header = Header{Struct: s2prot.Struct{"elapsedGameLoops": int64(2240)}} // 100 real seconds on Faster
+	r.Details = Details{Struct: s2prot.Struct{
+		"gameSpeed":  int64(4),
+		"playerList": []interface{}{s2prot.Struct{}, s2prot.Struct{}},
+	}}
+
+	r.TrackerEvts = &TrackerEvts{Evts: []s2prot.Event{
+		unitEvt(0, 1, "UnitBorn", s2prot.Struct{"unitTypeName": "SCV", "controlPlayerId": int64(1)}),
+		unitEvt(100, 2, "UnitBorn", s2prot.Struct{"unitTypeName": "Marine", "controlPlayerId": int64(1)}),
+		unitEvt(200, 3, "UnitBorn", s2prot.Struct{"unitTypeName": "SiegeTank", "controlPlayerId": int64(1)}),
+		unitEvt(300, 4, "UnitInit", s2prot.Struct{"unitTypeName": "Zealot", "controlPlayerId": int64(2)}),
+		unitEvt(400, 3, "UnitTypeChange", s2prot.Struct{"unitTypeName": "SiegeTankSieged"}),
+		unitEvt(412, 4, "UnitDone", nil),
+		unitEvt(500, 2, "UnitDied", nil),
+		unitEvt(600, 3, "UnitOwnerChange", s2prot.Struct{"controlPlayerId": int64(2)}),
+	}}
+
+	units := r.Units()
+	if len(units) != 4 {
+		t.Fatalf("Expected 4 units, got: %d", len(units))
+	}
+	if u := units[2]; u.TypeAt(300) != "SiegeTank" || u.TypeAt(400) != "SiegeTankSieged" || u.Type() != "SiegeTankSieged" ||
+		u.OwnerAt(599) != 1 || u.OwnerAt(600) != 2 {
+		t.Errorf("Unexpected unit history: %+v", u)
+	}
+	if u := units[3]; u.AliveAt(300) || !u.AliveAt(412) {
+		t.Errorf("Unexpected unit lifetime: %+v", u)
+	}
+
+	cases := []struct {
+		loop   int64
+		units  []map[string]int
+		supply []float64
+		value  []int
+	}{
+		{50, []map[string]int{{}, {}}, []float64{0, 0}, []int{0, 0}},
+		{300, []map[string]int{{"Marine": 1, "SiegeTank": 1}, {}}, []float64{4, 0}, []int{325, 0}},
+		{450, []map[string]int{{"Marine": 1, "SiegeTank": 1}, {"Zealot": 1}}, []float64{4, 2}, []int{325, 100}},
+		{700, []map[string]int{{}, {"Zealot": 1, "SiegeTank": 1}}, []float64{0, 5}, []int{0, 375}},
+	}
+	for _, c := range cases {
+		armies := r.ArmyComposition(c.loop)
+		for i, ac := range armies {
+			if len(ac.Units) != len(c.units[i]) || ac.Supply != c.supply[i] || ac.Value() != c.value[i] {
+				t.Errorf("[loop %d, player %d] Unexpected army: %+v", c.loop, i, ac)
+				continue
+			}
+			for name, count := range c.units[i] {
+				if ac.Units[name] != count {
+					t.Errorf("[loop %d, player %d] Unexpected army: %+v", c.loop, i, ac)
+				}
+			}
+		}
+	}
+
+	snapshots := r.ArmySnapshots(30 * time.Second)
+	if len(snapshots) != 4 || snapshots[3].Time != 100*time.Second || snapshots[0].Loop != 672 {
+		t.Errorf("Unexpected snapshots: %+v", snapshots)
+	}
+}
@@ -6,16 +6,85 @@ Type describing the attributes events.
 
 package rep
 
-import "github.com/icza/s2prot"
+import (
+	"sort"
+	"strconv"
+	"strings"
 
-// Attribute ID constants
+	"github.com/icza/s2prot"
+)
+
+// AttrID is the type of the attribute ids.
+type AttrID int
+
+// Known attribute ids.
 const (
-	// attrGameMode is the game mode attribute
-	attrGameMode = "3009"
+	AttrController       AttrID = 500  // Controller of the slot: "Humn", "Comp", "Open", "Clsd"
+	AttrRules            AttrID = 1000 // Rules: "Dflt"
+	AttrPremadeGame      AttrID = 1001 // Premade game: "yes", "no"
+	AttrTeams            AttrID = 2000 // Team setup: e.g. "t2"
+	AttrGameFormat       AttrID = 2001 // Game format: e.g. "1v1", "2v2", "FFA"
+	AttrGameSpeed        AttrID = 3000 // Game speed: "Slor", "Slow", "Norm", "Fast", "Fasr"
+	AttrRace             AttrID = 3001 // Chosen race: "Terr", "Zerg", "Prot", "RAND"
+	AttrColor            AttrID = 3002 // Color: "tc01".."tc15" (index in Colors)
+	AttrHandicap         AttrID = 3003 // Handicap: e.g. "100"
+	AttrDifficulty       AttrID = 3004 // AI difficulty: "VyEy", "Easy", "Medi", "HdVH", "Hard", "VyHd", "Insa", "ChRe", "ChVi", "ChIn"
+	AttrLobbyDelay       AttrID = 3006 // Lobby start delay in seconds: e.g. "10"
+	AttrParticipantRole  AttrID = 3007 // Participant role: "Part", "Watc"
+	AttrObserverType     AttrID = 3008 // Observer type: "Obs", "Ref"
+	AttrGameMode         AttrID = 3009 // Game mode: "Amm", "Priv", "Pub"
+	AttrLockedAlliances  AttrID = 3010 // Locked alliances: "yes", "no"
+	AttrPlayerLogo       AttrID = 3011 // Player logo index
+	AttrTandemLeader     AttrID = 3012 // Tandem leader slot
+	AttrCommander        AttrID = 3013 // Co-op commander
+	AttrCommanderLevel   AttrID = 3014 // Co-op commander level
+	AttrGameDuration     AttrID = 3015 // Game duration limit
+	AttrAIBuild          AttrID = 3104 // AI build: e.g. "AB00"
+	AttrPrivacy          AttrID = 4000 // Game privacy: "Norm", "NoBO" (no build order), "NoMH" (no match history)
+	AttrCustomObserverUI AttrID = 4001 // Using custom observer UI: "yes", "no"
+	AttrReady            AttrID = 4005 // Ready: "yes", "no"
 )
 
-// scopeGlobal is the global scope.
-const scopeGlobal = "16"
+// attrIDNames holds the names of the known attribute ids.
+var attrIDNames = map[AttrID]string{
+	AttrController:       "Controller",
+	AttrRules:            "Rules",
+	AttrPremadeGame:      "Premade Game",
+	AttrTeams:            "Teams",
+	AttrGameFormat:       "Game Format",
+	AttrGameSpeed:        "Game Speed",
+	AttrRace:             "Race",
+	AttrColor:            "Color",
+	AttrHandicap:         "Handicap",
+	AttrDifficulty:       "Difficulty",
+	AttrLobbyDelay:       "Lobby Delay",
+	AttrParticipantRole:  "Participant Role",
+	AttrObserverType:     "Observer Type",
+	AttrGameMode:         "Game Mode",
+	AttrLockedAlliances:  "Locked Alliances",
+	AttrPlayerLogo:       "Player Logo",
+	AttrTandemLeader:     "Tandem Leader",
+	AttrCommander:        "Commander",
+	AttrCommanderLevel:   "Commander Level",
+	AttrGameDuration:     "Game Duration",
+	AttrAIBuild:          "AI Build",
+	AttrPrivacy:          "Privacy",
+	AttrCustomObserverUI: "Custom Observer UI",
+	AttrReady:            "Ready",
+}
+
+// String returns the name of the attribute id, or the id itself if it's unknown.
+func (id AttrID) String() string {
+	if name, ok := attrIDNames[id]; ok {
+		return name
+	}
+	return strconv.Itoa(int(id))
+}
+
+// key returns the key of the attribute in the decoded scope Structs.
+func (id AttrID) key() string {
+	return strconv.Itoa(int(id))
+}
 
 // AttrEvts contains game attributes.
 type AttrEvts struct {
@@ -44,10 +113,121 @@ func (a *AttrEvts) MapNamespace() string {
 	return a.Stringv("mapNamespace")
 }
 
+// Attr returns the value of the specified attribute in the specified scope.
+// Values are padded with spaces in some cases (e.g. " 100"), those are trimmed.
+// Empty string is returned if the attribute is not present.
+func (a *AttrEvts) Attr(scope AttrScope, id AttrID) string {
+	if a.scopes == nil {
+		return ""
+	}
+	v, _ := a.scopes.Value(scope.key(), id.key(), "value").(string)
+	return strings.TrimSpace(v)
+}
+
+// Attrs returns all attributes of the specified scope.
+// Returns an empty map if there are no attributes in the scope.
+func (a *AttrEvts) Attrs(scope AttrScope) map[AttrID]string {
+	attrs := make(map[AttrID]string)
+	for k, v := range a.scopes.Structv(scope.key()) {
+		id, err := strconv.Atoi(k)
+		if err != nil {
+			continue
+		}
+		if attr, ok := v.(s2prot.Struct); ok {
+			attrs[AttrID(id)] = strings.TrimSpace(attr.Stringv("value"))
+		}
+	}
+	return attrs
+}
+
+// Scopes returns the scopes present in the attributes events, in increasing order.
+func (a *AttrEvts) Scopes() []AttrScope {
+	scopes := make([]AttrScope, 0, len(a.scopes))
+	for k := range a.scopes {
+		if n, err := strconv.Atoi(k); err == nil {
+			scopes = append(scopes, AttrScope(n))
+		}
+	}
+	sort.Slice(scopes, func(i, j int) bool { return scopes[i] < scopes[j] })
+	return scopes
+}
+
+// GlobalAttr returns the value of the specified global attribute.
+// Empty string is returned if the attribute is not present.
+func (a *AttrEvts) GlobalAttr(id AttrID) string {
+	return a.Attr(AttrScopeGlobal, id)
+}
+
+// PlayerAttr returns the value of the specified player attribute of the specified lobby slot (0-based).
+// Empty string is returned if the attribute is not present.
+func (a *AttrEvts) PlayerAttr(slot int, id AttrID) string {
+	return a.Attr(SlotAttrScope(slot), id)
+}
+
+// GlobalAttrs returns all global attributes.
+func (a *AttrEvts) GlobalAttrs() map[AttrID]string {
+	return a.Attrs(AttrScopeGlobal)
+}
+
+// PlayerAttrs returns all player attributes of the specified lobby slot (0-based).
+// Returns an empty map if there are no attributes for the slot.
+func (a *AttrEvts) PlayerAttrs(slot int) map[AttrID]string {
+	return a.Attrs(SlotAttrScope(slot))
+}
+
 // GameMode returns the game mode
 func (a *AttrEvts) GameMode() *GameMode {
 	if a.scopes == nil {
 		return GameModeUnknown
 	}
-	return gameModeByAttrValue(a.scopes.Stringv(scopeGlobal, attrGameMode, "value"))
+	return gameModeByAttrValue(a.GlobalAttr(AttrGameMode))
+}
+
+// GameSpeed returns the game speed.
+func (a *AttrEvts) GameSpeed() *GameSpeed {
+	return gameSpeedByAttrValue(a.GlobalAttr(AttrGameSpeed))
+}
+
+// LockedAlliances tells if alliances are locked.
+func (a *AttrEvts) LockedAlliances() bool {
+	return a.GlobalAttr(AttrLockedAlliances) == "yes"
+}
+
+// GameFormat returns the game format, e.g. "1v1", "2v2", "FFA".
+func (a *AttrEvts) GameFormat() string {
+	return a.GlobalAttr(AttrGameFormat)
+}
+
+// LobbyDelay returns the lobby start delay in seconds, 0 if not present.
+func (a *AttrEvts) LobbyDelay() int64 {
+	n, _ := strconv.ParseInt(a.GlobalAttr(AttrLobbyDelay), 10, 64)
+	return n
+}
+
+// PlayerController returns the controller of the specified lobby slot (0-based).
+func (a *AttrEvts) PlayerController(slot int) *Control {
+	return controlByAttrValue(a.PlayerAttr(slot, AttrController))
+}
+
+// PlayerRace returns the chosen race of the specified lobby slot (0-based).
+// RaceRandom is returned if random race was chosen.
+func (a *AttrEvts) PlayerRace(slot int) *Race {
+	return raceByAttrValue(a.PlayerAttr(slot, AttrRace))
+}
+
+// PlayerColor returns the color of the specified lobby slot (0-based).
+func (a *AttrEvts) PlayerColor(slot int) *Color {
+	return colorByAttrValue(a.PlayerAttr(slot, AttrColor))
+}
+
+// PlayerHandicap returns the handicap of the specified lobby slot (0-based), 0 if not present.
+func (a *AttrEvts) PlayerHandicap(slot int) int64 {
+	n, _ := strconv.ParseInt(a.PlayerAttr(slot, AttrHandicap), 10, 64)
+	return n
+}
+
+// PlayerDifficulty returns the AI difficulty of the specified lobby slot (0-based),
+// e.g. "Medi", "VyHd". Empty string is returned if not present.
+func (a *AttrEvts) PlayerDifficulty(slot int) string {
+	return a.PlayerAttr(slot, AttrDifficulty)
 }
@@ -0,0 +1,103 @@
+package rep
+
+import (
+	"testing"
+
+	"github.com/icza/s2prot"
+)
+
+// newTestAttrEvts creates attributes events from a scope => attribute id => value map.
+func newTestAttrEvts(scopes map[string]map[AttrID]string) AttrEvts {
+	ss := s2prot.Struct{}
+	for scope, attrs := range scopes {
+		s := s2prot.Struct{}
+		for id, v := range attrs {
+			s[id.key()] = s2prot.Struct{"attrid": int64(id), "value": v}
+		}
+		ss[scope] = s
+	}
+	return NewAttrEvts(s2prot.Struct{"scopes": ss})
+}
+
+func TestAttrEvts(t *testing.T) {
+	a := newTestAttrEvts(map[string]map[AttrID]string{
+		"16": {AttrGameMode: "Amm", AttrGameSpeed: "Fasr", AttrGameFormat: "1v1", AttrLobbyDelay: "10", AttrLockedAlliances: "yes"},
+		"1":  {AttrController: "Humn", AttrRace: "Prot", AttrColor: "tc01", AttrHandicap: " 100"},
+		"2":  {AttrController: "Comp", AttrRace: "RAND", AttrColor: "tc02", AttrHandicap: "  50", AttrDifficulty: "VyHd"},
+	})
+
+	if a.GameMode() != GameModeAutoMM || a.GameSpeed() != GameSpeedFaster || a.GameFormat() != "1v1" ||
+		a.LobbyDelay() != 10 || !a.LockedAlliances() {
+		t.Errorf("Unexpected global attributes: %v", a.GlobalAttrs())
+	}
+
+	if a.PlayerController(0) != ControlHuman || a.PlayerRace(0) != RaceProtoss ||
+		a.PlayerColor(0) != ColorRed || a.PlayerHandicap(0) != 100 {
+		t.Errorf("Unexpected slot 0 attributes: %v", a.PlayerAttrs(0))
+	}
+	if a.PlayerController(1) != ControlComputer || a.PlayerRace(1) != RaceRandom ||
+		a.PlayerColor(1) != ColorBlue || a.PlayerHandicap(1) != 50 || a.PlayerDifficulty(1) != "VyHd" {
+		t.Errorf("Unexpected slot 1 attributes: %v", a.PlayerAttrs(1))
+	}
+
+	if attrs := a.PlayerAttrs(1); len(attrs) != 5 || attrs[AttrHandicap] != "50" {
+		t.Errorf("Unexpected slot 1 attributes: %v", attrs)
+	}
+	if attrs := a.PlayerAttrs(5); len(attrs) != 0 {
+		t.Errorf("Expected no attributes, got: %v", attrs)
+	}
+	if a.PlayerRace(5) != RaceUnknown || a.PlayerAttr(5, AttrRace) != "" {
+		t.Errorf("Expected unknown race!")
+	}
+
+	if AttrRace.String() != "Race" || AttrID(12345).String() != "12345" {
+		t.Errorf("Unexpected attribute id names!")
+	}
+}
+
+func TestAttrScope(t *testing.T) {
+	if s := SlotAttrScope(0); s != AttrScopePlayer1 || s.Slot() != 0 || s.IsGlobal() || s.String() != "Player 1" {
+		t.Errorf("Unexpected scope: %v", s)
+	}
+	if s := AttrScopeGlobal; s.key() != "16" || s.Slot() != -1 || s.String() != "Global" {
+		t.Errorf("Unexpected scope: %v", s)
+	}
+}
+
+func TestPlayerSlots(t *testing.T) {
+	// Helper function to create a test replay with the specified working set slot ids
+	newRep := func(playerWSS, slotWSS []int64) *Rep {
+		r := &Rep{}
+		var players, slots []interface{}
+		for _, wss := range playerWSS {
+			players = append(players, s2prot.Struct{"workingSetSlotId": wss})
+		}
+		for _, wss := range slotWSS {
+			slots = append(slots, s2prot.Struct{"workingSetSlotId": wss, "control": int64(2), "observe": int64(0)})
+		}
+		r.Details = Details{Struct: s2prot.Struct{"playerList": players}}
+		r.InitData = NewInitData(s2prot.Struct{"syncLobbyState": s2prot.Struct{"lobbyState": s2prot.Struct{"slots": slots}}})
+		r.AttrEvts = newTestAttrEvts(map[string]map[AttrID]string{"3": {AttrRace: "Zerg"}})
+		return r
+	}
+
+	r := newRep([]int64{2, 0}, []int64{0, 1, 2})
+	if slots := r.PlayerSlots(); len(slots) != 2 || slots[0] != 2 || slots[1] != 0 {
+		t.Errorf("Unexpected slots: %v", slots)
+	}
+	if r.PlayerAttrScope(0) != AttrScopePlayer3 || r.PlayerAttrs(0)[AttrRace] != "Zerg" || len(r.PlayerAttrs(1)) != 0 {
+		t.Errorf("Unexpected player attributes!")
+	}
+	if p := r.AttrScopePlayer(AttrScopePlayer3); p == nil || p.WorkingSetSlotID() != 2 {
+		t.Errorf("Unexpected player: %v", p)
+	}
+	if p := r.AttrScopePlayer(AttrScopeGlobal); p != nil {
+		t.Errorf("Expected no player, got: %v", p)
+	}
+
+	// No usable working set slot ids: slots are matched in order
+	r = newRep([]int64{0, 0}, []int64{0, 0, 0})
+	if slots := r.PlayerSlots(); len(slots) != 2 || slots[0] != 0 || slots[1] != 1 {
+		t.Errorf("Unexpected slots: %v", slots)
+	}
+}
@@ -0,0 +1,75 @@
+/*
+
+Attribution of commands to the units they were given to.
+
+*/
+
+package rep
+
+// AttributedCommand is a command attributed to the units it was given to.
+type AttributedCommand struct {
+	*Command
+
+	// Units of the issuer's active selection at the time of the command, resolved by the unit tracker
+	// (see Units), in the order of the selection. Note that ability commands are only executed
+	// by the units having the ability.
+	Units []*Unit
+
+	// Unresolved is the number of selected units that could not be resolved: units unknown to the unit tracker,
+	// units not alive (e.g. tracked as selected after their death, see SelectionTracker)
+	// and units not controlled by the issuer.
+	Unresolved int
+}
+
+// UnitTypes returns the number of units the command was given to, grouped by unit type name
+// (at the time of the command).
+func (ac *AttributedCommand) UnitTypes() map[string]int {
+	types := map[string]int{}
+	for _, u := range ac.Units {
+		types[u.TypeAt(ac.Loop)]++
+	}
+	return types
+}
+
+// AttributeCommands attributes the effective commands of the game (see Commands) to the units they were given to,
+// combining the selection tracking (see CommandSelections) and the unit tracker (see Units).
+// Enables analyses like "which worker built the proxy pylon" or "which army attacked at 8:00".
+//
+// This is a costly analysis pass: it replays all selection events, builds the unit tracker, and resolves the selection
+// of each command. Results are not cached, call it once and reuse the result.
+//
+// nil is returned if game events or tracker events were not decoded.
+func (r *Rep) AttributeCommands() []*AttributedCommand {
+	units := r.Units()
+	if units == nil || r.GameEvts == nil {
+		return nil
+	}
+	tagUnits := make(map[int64][]*Unit, len(units)) // Tags are recycled, so a tag may denote multiple units
+	for _, u := range units {
+		tagUnits[u.Tag] = append(tagUnits[u.Tag], u)
+	}
+
+	userPlayerIdxs := r.userPlayerIdxs()
+	cmds, selections := r.CommandSelections()
+	acs := make([]*AttributedCommand, len(cmds))
+	for i, c := range cmds {
+		ac := &AttributedCommand{Command: c}
+		playerIdx, human := userPlayerIdxs[c.UserID]
+		for _, su := range selections[i] {
+			var unit *Unit
+			for _, u := range tagUnits[su.Tag] {
+				if u.Born <= c.Loop && (u.Died < 0 || c.Loop < u.Died) {
+					unit = u
+					break
+				}
+			}
+			if unit == nil || human && unit.OwnerAt(c.Loop) != int64(playerIdx)+1 {
+				ac.Unresolved++
+				continue
+			}
+			ac.Units = append(ac.Units, unit)
+		}
+		acs[i] = ac
+	}
+	return acs
+}
@@ -0,0 +1,56 @@
+package rep
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/icza/s2prot"
+)
+
+func TestAttributeCommands(t *testing.T) {
+	r := newTestRep(humanSlot(0))
+	if r.AttributeCommands() != nil {
+		t.Error("Expected nil attributed commands without events!")
+	}
+
+	r.TrackerEvts = &TrackerEvts{Evts: []s2prot.Event{
+		unitEvt(0, 1, "UnitBorn", s2prot.Struct{"unitTypeName": "Probe", "controlPlayerId": int64(1)}),
+		unitEvt(0, 2, "UnitBorn", s2prot.Struct{"unitTypeName": "Probe", "controlPlayerId": int64(1)}),
+		unitEvt(0, 3, "UnitBorn", s2prot.Struct{"unitTypeName": "Zealot", "controlPlayerId": int64(2)}),
+		unitEvt(50, 2, "UnitDied", nil),
+	}}
+
+	cmd := func(loop, seq int64) s2prot.Event {
+		return gameEvt(loop, 0, "Cmd", s2prot.Struct{"sequence": seq, "data": s2prot.Struct{"None": nil}})
+	}
+	r.GameEvts = []s2prot.Event{
+		gameEvt(10, 0, "SelectionDelta", s2prot.Struct{"controlGroupId": int64(ActiveSelectionID), "delta": s2prot.Struct{
+			"removeMask":   s2prot.Struct{"None": nil},
+			"addSubgroups": []interface{}{s2prot.Struct{"count": int64(4), "unitLink": int64(84)}},
+			// Unknown unit, 2 probes and an enemy zealot:
+			"addUnitTags": []interface{}{unitTag(9, 1), unitTag(1, 1), unitTag(2, 1), unitTag(3, 1)},
+		}}),
+		cmd(20, 1),
+		cmd(60, 2), // After the death of a probe
+	}
+
+	acs := r.AttributeCommands()
+	exp := []struct {
+		types      map[string]int
+		unresolved int
+	}{
+		{map[string]int{"Probe": 2}, 2},
+		{map[string]int{"Probe": 1}, 3},
+	}
+	if len(acs) != len(exp) {
+		t.Fatalf("Expected %d attributed commands, got: %d", len(exp), len(acs))
+	}
+	for i, ac := range acs {
+		if types := ac.UnitTypes(); !reflect.DeepEqual(types, exp[i].types) || ac.Unresolved != exp[i].unresolved {
+			t.Errorf("[%d] Expected: %v %d, got: %v %d", i, exp[i].types, exp[i].unresolved, types, ac.Unresolved)
+		}
+	}
+	if acs[1].Units[0].Tag != unitTag(1, 1) {
+		t.Errorf("Unexpected unit: %+v", acs[1].Units[0])
+	}
+}
@@ -0,0 +1,164 @@
+/*
+
+Attribute scopes and their mapping to lobby slots and players.
+
+*/
+
+package rep
+
+import "strconv"
+
+// AttrScope is the scope of attributes: either global or belonging to a lobby slot.
+type AttrScope int
+
+// Attribute scopes.
+//
+// Player attributes of lobby slot N (0-based) are in scope N+1. Note that the global scope (16)
+// coincides with the scope of the 16th lobby slot, so the latter's attributes are not distinguishable.
+const (
+	AttrScopePlayer1 AttrScope = iota + 1
+	AttrScopePlayer2
+	AttrScopePlayer3
+	AttrScopePlayer4
+	AttrScopePlayer5
+	AttrScopePlayer6
+	AttrScopePlayer7
+	AttrScopePlayer8
+	AttrScopePlayer9
+	AttrScopePlayer10
+	AttrScopePlayer11
+	AttrScopePlayer12
+	AttrScopePlayer13
+	AttrScopePlayer14
+	AttrScopePlayer15
+	AttrScopeGlobal
+)
+
+// SlotAttrScope returns the attribute scope of the specified lobby slot (0-based).
+func SlotAttrScope(slot int) AttrScope {
+	return AttrScope(slot + 1)
+}
+
+// IsGlobal tells if the scope is the global scope.
+func (s AttrScope) IsGlobal() bool {
+	return s == AttrScopeGlobal
+}
+
+// Slot returns the lobby slot (0-based) of the scope, -1 if this is the global scope.
+func (s AttrScope) Slot() int {
+	if s.IsGlobal() {
+		return -1
+	}
+	return int(s) - 1
+}
+
+// String returns "Global" for the global scope, and "Player N" for player scopes.
+func (s AttrScope) String() string {
+	if s.IsGlobal() {
+		return "Global"
+	}
+	return "Player " + strconv.Itoa(int(s))
+}
+
+// key returns the key of the scope in the decoded attributes events.
+func (s AttrScope) key() string {
+	return strconv.Itoa(int(s))
+}
+
+// PlayerSlots returns the lobby slots (0-based) of the players, index is the same as in Details.Players().
+// -1 is used for players whose slot cannot be determined.
+//
+// Players are matched to slots by working set slot id. Old replays have no working set slot ids,
+// in which case players are matched to the occupied participant slots in order.
+func (r *Rep) PlayerSlots() []int {
+	res, _ := r.playerSlots()
+	return res
+}
+
+// playerSlots returns the lobby slots of the players (see PlayerSlots),
+// and tells if players were matched by working set slot id.
+func (r *Rep) playerSlots() (res []int, byWorkingSetSlotID bool) {
+	players := r.Details.Players()
+	slots := r.InitData.LobbyState.Slots
+
+	res = make([]int, len(players))
+	for i := range res {
+		res[i] = -1
+	}
+
+	// Working set slot id => slot
+	wssSlots := make(map[int64]int, len(slots))
+	for i := range slots {
+		wssSlots[slots[i].WorkingSetSlotID()] = i
+	}
+	if len(wssSlots) == len(slots) {
+		for i := range players {
+			if slot, ok := wssSlots[players[i].WorkingSetSlotID()]; ok {
+				res[i] = slot
+			}
+		}
+		return res, true
+	}
+
+	// Working set slot ids are not usable, match occupied participant slots in order:
+	j := 0
+	for i := range slots {
+		s := &slots[i]
+		if (s.Control() != ControlHuman && s.Control() != ControlComputer) || s.Observe() != ObserveParticipant {
+			continue
+		}
+		if j == len(players) {
+			break
+		}
+		res[j] = i
+		j++
+	}
+	return res, false
+}
+
+// PlayerAttrScope returns the attribute scope of the player specified by its index in Details.Players().
+// 0 (an invalid scope) is returned if the player's slot cannot be determined.
+func (r *Rep) PlayerAttrScope(playerIdx int) AttrScope {
+	if slots := r.PlayerSlots(); playerIdx >= 0 && playerIdx < len(slots) && slots[playerIdx] >= 0 {
+		return SlotAttrScope(slots[playerIdx])
+	}
+	return 0
+}
+
+// AttrScopePlayer returns the player whose attributes are in the specified scope.
+// nil is returned for the global scope or if no player belongs to the scope.
+func (r *Rep) AttrScopePlayer(scope AttrScope) *Player {
+	if scope.IsGlobal() {
+		return nil
+	}
+	players := r.Details.Players()
+	for i, slot := range r.PlayerSlots() {
+		if slot == scope.Slot() {
+			return &players[i]
+		}
+	}
+	return nil
+}
+
+// PlayerAttrs returns all attributes of the player specified by its index in Details.Players().
+// Returns an empty map if the player's slot cannot be determined.
+func (r *Rep) PlayerAttrs(playerIdx int) map[AttrID]string {
+	return r.AttrEvts.Attrs(r.PlayerAttrScope(playerIdx))
+}
+
+// PlayerUser returns the user init data of the player specified by its index in Details.Players().
+// nil is returned if the player is not a human player or its user cannot be determined.
+func (r *Rep) PlayerUser(playerIdx int) *UserInitData {
+	slots := r.PlayerSlots()
+	if playerIdx < 0 || playerIdx >= len(slots) || slots[playerIdx] < 0 {
+		return nil
+	}
+	s := &r.InitData.LobbyState.Slots[slots[playerIdx]]
+	if s.Control() != ControlHuman {
+		return nil
+	}
+	if userID := s.UserID(); userID >= 0 && int(userID) < len(r.InitData.UserInitDatas) {
+		return &r.InitData.UserInitDatas[userID]
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+/*
+
+Balance data of units, used by the derived metrics.
+
+*/
+
+package rep
+
+import "strings"
+
+// unitBalance is the balance data of a unit type.
+type unitBalance struct {
+	minerals, gas int     // Cost
+	supply        float64 // Supply (food) used
+	buildTime     float64 // Build time in real seconds on Faster game speed, 0 if not trained in a production structure
+	worker        bool    // Tells if the unit is a worker
+}
+
+// unitBalances holds the balance data of units mapped from unit type name, based on Legacy of the Void values.
+// Structures and units not controlled directly (e.g. Larva, Broodling, MULE) are not included.
+var unitBalances = map[string]*unitBalance{
+	"SCV":           {50, 0, 1, 12, true},
+	"Marine":        {50, 0, 1, 18, false},
+	"Marauder":      {100, 25, 2, 21, false},
+	"Reaper":        {50, 50, 1, 32, false},
+	"Ghost":         {150, 125, 2, 29, false},
+	"Hellion":       {100, 0, 2, 21, false},
+	"HellionTank":   {100, 0, 2, 21, false},
+	"WidowMine":     {75, 25, 2, 21, false},
+	"SiegeTank":     {150, 125, 3, 32, false},
+	"Cyclone":       {150, 100, 3, 32, false},
+	"Thor":          {300, 200, 6, 43, false},
+	"VikingFighter": {150, 75, 2, 30, false},
+	"Medivac":       {100, 100, 2, 30, false},
+	"Liberator":     {150, 125, 3, 43, false},
+	"Raven":         {100, 150, 2, 34, false},
+	"Banshee":       {150, 100, 3, 43, false},
+	"Battlecruiser": {400, 300, 6, 64, false},
+
+	"Probe":          {50, 0, 1, 12, true},
+	"Zealot":         {100, 0, 2, 27, false},
+	"Stalker":        {125, 50, 2, 30, false},
+	"Sentry":         {50, 100, 2, 26, false},
+	"Adept":          {100, 25, 2, 30, false},
+	"HighTemplar":    {50, 150, 2, 39, false},
+	"DarkTemplar":    {125, 125, 2, 39, false},
+	"Archon":         {175, 275, 4, 0, false},
+	"Observer":       {25, 75, 1, 21, false},
+	"WarpPrism":      {250, 0, 2, 36, false},
+	"Immortal":       {275, 100, 4, 39, false},
+	"Colossus":       {300, 200, 6, 54, false},
+	"Disruptor":      {150, 150, 3, 36, false},
+	"Phoenix":        {150, 100, 2, 25, false},
+	"Oracle":         {150, 150, 3, 37, false},
+	"VoidRay":        {250, 150, 4, 43, false},
+	"Tempest":        {250, 175, 5, 43, false},
+	"Carrier":        {350, 250, 6, 64, false},
+	"Mothership":     {400, 400, 8, 0, false},
+	"MothershipCore": {100, 100, 2, 36, false},
+
+	"Drone":       {50, 0, 1, 0, true},
+	"Queen":       {150, 0, 2, 36, false},
+	"Zergling":    {25, 0, 0.5, 0, false},
+	"Baneling":    {50, 25, 0.5, 0, false},
+	"Roach":       {75, 25, 2, 0, false},
+	"Ravager":     {100, 100, 3, 0, false},
+	"Hydralisk":   {100, 50, 2, 0, false},
+	"LurkerMP":    {150, 150, 3, 0, false},
+	"Infestor":    {100, 150, 2, 0, false},
+	"SwarmHostMP": {100, 75, 3, 0, false},
+	"Ultralisk":   {275, 200, 6, 0, false},
+	"Mutalisk":    {100, 100, 2, 0, false},
+	"Corruptor":   {150, 100, 2, 0, false},
+	"BroodLord":   {300, 250, 4, 0, false},
+	"Viper":       {100, 200, 3, 0, false},
+}
+
+// unitTypeAliases maps alternate forms (modes) of units to their base unit type name.
+var unitTypeAliases = map[string]string{
+	"SiegeTankSieged":   "SiegeTank",
+	"VikingAssault":     "VikingFighter",
+	"LiberatorAG":       "Liberator",
+	"ThorAP":            "Thor",
+	"WarpPrismPhasing":  "WarpPrism",
+	"ObserverSiegeMode": "Observer",
+}
+
+// balanceOf returns the balance data of the unit type, nil if unknown.
+// Alternate forms (e.g. sieged or burrowed units) are resolved to their base unit type.
+func balanceOf(unitType string) (baseType string, ub *unitBalance) {
+	baseType = strings.TrimSuffix(unitType, "Burrowed")
+	if alias, ok := unitTypeAliases[baseType]; ok {
+		baseType = alias
+	}
+	return baseType, unitBalances[baseType]
+}
@@ -0,0 +1,148 @@
+package rep_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/icza/mpq"
+	"github.com/icza/s2prot"
+	"github.com/icza/s2prot/rep"
+	"github.com/icza/s2prot/rep/reptest"
+)
+
+// benchReplays returns representative synthetic replays for the benchmarks, mapped from name.
+func benchReplays(b *testing.B) map[string][]byte {
+	newReplay := func(players int, minutes int64, title string) *reptest.Replay {
+		r := reptest.New(80949)
+		r.Title = title
+		r.Loops = minutes * 1344
+		r.Players = nil
+		for i := 0; i < players; i++ {
+			r.Players = append(r.Players, reptest.Player{Name: "Player", Race: rep.RaceTerran, TeamID: int64(i % 2),
+				Result: rep.ResultVictory, Color: rep.Colors[1+i%(len(rep.Colors)-1)]})
+		}
+		for loop := int64(0); loop < r.Loops; loop += 8 {
+			userID := loop / 8 % int64(players)
+			r.GameEvt(loop, userID, "CameraUpdate", s2prot.Struct{"distance": nil})
+			if loop%16 == 0 {
+				r.GameEvt(loop, userID, "Cmd", nil)
+			}
+			if loop%160 == 0 {
+				r.TrackerEvt(loop, "PlayerStats", s2prot.Struct{"playerId": userID + 1})
+				r.TrackerEvt(loop, "UnitBorn", s2prot.Struct{"controlPlayerId": userID + 1, "unitTypeName": "SCV"})
+			}
+		}
+		return r
+	}
+
+	reps := map[string]*reptest.Replay{
+		"small1v1": newReplay(2, 10, "Small 1v1"),
+		"long4v4":  newReplay(8, 40, "Long 4v4"),
+		"arcade":   newReplay(6, 20, "Arcade"),
+	}
+	// Arcade games are chatty:
+	for loop := int64(0); loop < reps["arcade"].Loops; loop += 32 {
+		reps["arcade"].MessageEvt(loop, loop%6, "Chat", s2prot.Struct{"recipient": int64(0), "string": "-cmd go go go"})
+	}
+
+	datas := map[string][]byte{}
+	for name, r := range reps {
+		data, err := r.Bytes()
+		if err != nil {
+			b.Fatalf("Failed to create replay %s: %v", name, err)
+		}
+		datas[name] = data
+	}
+	return datas
+}
+
+// BenchmarkParse measures parsing representative replays as a whole and section by section.
+//
+// Run e.g. with:
+//
+//	go test -run=^$ -bench=Parse -benchmem ./rep
+func BenchmarkParse(b *testing.B) {
+	datas := benchReplays(b)
+	for _, name := range []string{"small1v1", "long4v4", "arcade"} {
+		data := datas[name]
+
+		b.Run(name+"/full", func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				r, err := rep.New(bytes.NewReader(data))
+				if err != nil {
+					b.Fatal(err)
+				}
+				r.Close()
+			}
+		})
+
+		m, err := mpq.New(bytes.NewReader(data))
+		if err != nil {
+			b.Fatal(err)
+		}
+		p := s2prot.GetProtocol(80949)
+		decoders := []struct {
+			section string
+			decode  func(data []byte)
+		}{
+			{"replay.details", func(data []byte) { p.DecodeDetails(data) }},
+			{"replay.initData", func(data []byte) { p.DecodeInitData(data) }},
+			{"replay.attributes.events", func(data []byte) { p.DecodeAttributesEvts(data) }},
+			{"replay.game.events", func(data []byte) { p.DecodeGameEvts(data) }},
+			{"replay.message.events", func(data []byte) { p.DecodeMessageEvts(data) }},
+			{"replay.tracker.events", func(data []byte) { p.DecodeTrackerEvts(data) }},
+		}
+		b.Run(name+"/header", func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				s2prot.DecodeHeader(m.UserData())
+			}
+		})
+		for _, d := range decoders {
+			secData, err := m.FileByName(d.section)
+			if err != nil || len(secData) == 0 {
+				continue
+			}
+			decode := d.decode
+			b.Run(name+"/"+d.section, func(b *testing.B) {
+				b.SetBytes(int64(len(secData)))
+				b.ReportAllocs()
+				for i := 0; i < b.N; i++ {
+					decode(secData)
+				}
+			})
+		}
+		m.Close()
+	}
+}
+
+func TestProfileSections(t *testing.T) {
+	r := reptest.New(80949)
+	r.MessageEvt(100, 0, "Chat", s2prot.Struct{"string": "gg"})
+	data, err := r.Bytes()
+	if err != nil {
+		t.Fatalf("Failed to create replay: %v", err)
+	}
+
+	profs, err := rep.ProfileSections(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Failed to profile: %v", err)
+	}
+	found := map[string]bool{}
+	for _, sp := range profs {
+		found[sp.Section] = true
+		if sp.Size <= 0 {
+			t.Errorf("[%s] Expected positive size, got: %d", sp.Section, sp.Size)
+		}
+	}
+	for _, section := range []string{rep.SectionHeader, "replay.details", "replay.initData", "replay.message.events"} {
+		if !found[section] {
+			t.Errorf("Expected profile of section %s", section)
+		}
+	}
+
+	if _, err := rep.ProfileSections(bytes.NewReader([]byte("invalid"))); err != rep.ErrInvalidRepFile {
+		t.Errorf("Expected: %v, got: %v", rep.ErrInvalidRepFile, err)
+	}
+}
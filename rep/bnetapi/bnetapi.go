@@ -0,0 +1,283 @@
+/*
+Package bnetapi implements an optional client of the Blizzard (Community) API
+to fetch current ladder, league and MMR data of players of replays.
+
+The API requires OAuth client credentials, which can be obtained at https://develop.battle.net/.
+
+Example:
+
+	c := bnetapi.NewClient("clientID", "clientSecret")
+	for _, p := range r.Details.Players() {
+		info, err := c.PlayerInfo(context.Background(), p.Toon.Handle())
+		if err != nil {
+			// Handle error
+		}
+		fmt.Println(p.Name, info.League1v1, info.MMR1v1)
+	}
+*/
+package bnetapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/icza/s2prot/rep"
+)
+
+// DefaultTokenURL is the default URL of the OAuth token endpoint.
+const DefaultTokenURL = "https://oauth.battle.net/token"
+
+// Client is a Blizzard API client. A Client is safe for concurrent use by multiple goroutines.
+type Client struct {
+	clientID, clientSecret string
+
+	// HTTPClient is used to perform requests. If nil, http.DefaultClient is used.
+	HTTPClient *http.Client
+
+	// TokenURL is the URL of the OAuth token endpoint, DefaultTokenURL by default.
+	TokenURL string
+
+	// BaseURL is an optional API base URL to use instead of the API host of the players' regions
+	// (see rep.ToonHandle.APIURL()).
+	BaseURL string
+
+	mu          sync.Mutex // Protects the access token
+	token       string     // Access token
+	tokenExpiry time.Time  // Expiry of the access token
+}
+
+// NewClient creates a new Client using the specified OAuth client credentials.
+func NewClient(clientID, clientSecret string) *Client {
+	return &Client{clientID: clientID, clientSecret: clientSecret, TokenURL: DefaultTokenURL}
+}
+
+// httpClient returns the HTTP client to use.
+func (c *Client) httpClient() *http.Client {
+	if c.HTTPClient != nil {
+		return c.HTTPClient
+	}
+	return http.DefaultClient
+}
+
+// accessToken returns a valid access token, acquiring a new one if needed.
+func (c *Client) accessToken(ctx context.Context) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.token != "" && time.Now().Before(c.tokenExpiry) {
+		return c.token, nil
+	}
+
+	req, err := http.NewRequest(http.MethodPost, c.TokenURL, strings.NewReader("grant_type=client_credentials"))
+	if err != nil {
+		return "", err
+	}
+	req = req.WithContext(ctx)
+	req.SetBasicAuth(c.clientID, c.clientSecret)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	var tr struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int64  `json:"expires_in"` // In seconds
+	}
+	if err = c.do(req, &tr); err != nil {
+		return "", err
+	}
+
+	// Renew the token a minute before it expires:
+	c.token, c.tokenExpiry = tr.AccessToken, time.Now().Add(time.Duration(tr.ExpiresIn-60)*time.Second)
+	return c.token, nil
+}
+
+// do performs the request and unmarshals the JSON response into v.
+func (c *Client) do(req *http.Request, v interface{}) error {
+	resp, err := c.httpClient().Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected response status for %s: %s", req.URL.Path, resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// get performs an authorized API GET request on the path of the player's profile.
+func (c *Client) get(ctx context.Context, h rep.ToonHandle, subPath string, v interface{}) error {
+	token, err := c.accessToken(ctx)
+	if err != nil {
+		return err
+	}
+
+	u := h.APIURL()
+	if c.BaseURL != "" {
+		u = strings.TrimSuffix(c.BaseURL, "/") + h.APIPath()
+	}
+	if u == "" {
+		return fmt.Errorf("no API host for region: %v", h.Region())
+	}
+	u += subPath
+
+	req, err := http.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	return c.do(req, v)
+}
+
+// Profile is the profile of a player.
+type Profile struct {
+	Summary struct {
+		ID          string `json:"id"`
+		Realm       int64  `json:"realm"`
+		DisplayName string `json:"displayName"`
+		ClanName    string `json:"clanName"`
+		ClanTag     string `json:"clanTag"`
+	} `json:"summary"`
+
+	Career struct {
+		TerranWins                int64  `json:"terranWins"`
+		ZergWins                  int64  `json:"zergWins"`
+		ProtossWins               int64  `json:"protossWins"`
+		TotalCareerGames          int64  `json:"totalCareerGames"`
+		TotalGamesThisSeason      int64  `json:"totalGamesThisSeason"`
+		Current1v1LeagueName      string `json:"current1v1LeagueName"`
+		CurrentBestTeamLeagueName string `json:"currentBestTeamLeagueName"`
+	} `json:"career"`
+}
+
+// Profile fetches the profile of the player.
+func (c *Client) Profile(ctx context.Context, h rep.ToonHandle) (*Profile, error) {
+	p := &Profile{}
+	if err := c.get(ctx, h, "", p); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// LadderSummary is the ladder summary of a player.
+type LadderSummary struct {
+	AllLadderMemberships []struct {
+		LadderID          string `json:"ladderId"`
+		LocalizedGameMode string `json:"localizedGameMode"`
+		Rank              int64  `json:"rank"`
+	} `json:"allLadderMemberships"`
+}
+
+// LadderSummary fetches the ladder summary of the player.
+func (c *Client) LadderSummary(ctx context.Context, h rep.ToonHandle) (*LadderSummary, error) {
+	ls := &LadderSummary{}
+	if err := c.get(ctx, h, "/ladder/summary", ls); err != nil {
+		return nil, err
+	}
+	return ls, nil
+}
+
+// Ladder is a ladder (division) of a player.
+type Ladder struct {
+	League      string `json:"league"`
+	LadderTeams []struct {
+		TeamMembers []struct {
+			ID           string `json:"id"`
+			Realm        int64  `json:"realm"`
+			Region       int64  `json:"region"`
+			DisplayName  string `json:"displayName"`
+			ClanTag      string `json:"clanTag"`
+			FavoriteRace string `json:"favoriteRace"`
+		} `json:"teamMembers"`
+		Points int64 `json:"points"`
+		Wins   int64 `json:"wins"`
+		Losses int64 `json:"losses"`
+		MMR    int64 `json:"mmr"`
+	} `json:"ladderTeams"`
+}
+
+// Ladder fetches the ladder of the player specified by its id (see LadderSummary).
+func (c *Client) Ladder(ctx context.Context, h rep.ToonHandle, ladderID string) (*Ladder, error) {
+	l := &Ladder{}
+	if err := c.get(ctx, h, "/ladder/"+url.PathEscape(ladderID), l); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// PlayerInfo contains the current ladder data of a player.
+type PlayerInfo struct {
+	Handle rep.ToonHandle // Toon handle of the player
+
+	DisplayName string // Display name of the player
+	ClanTag     string // Clan tag of the player
+
+	League1v1 string // Current 1v1 league name, empty if the player is not ranked
+	MMR1v1    int64  // Current 1v1 MMR, 0 if the player has no 1v1 ladder team
+	Points1v1 int64  // Current 1v1 ladder points
+	Wins1v1   int64  // 1v1 wins in the current season
+	Losses1v1 int64  // 1v1 losses in the current season
+}
+
+// PlayerInfo fetches the current ladder data of the player, including the 1v1 league and MMR.
+func (c *Client) PlayerInfo(ctx context.Context, h rep.ToonHandle) (*PlayerInfo, error) {
+	p, err := c.Profile(ctx, h)
+	if err != nil {
+		return nil, err
+	}
+	info := &PlayerInfo{
+		Handle:      h,
+		DisplayName: p.Summary.DisplayName,
+		ClanTag:     p.Summary.ClanTag,
+		League1v1:   p.Career.Current1v1LeagueName,
+	}
+
+	ls, err := c.LadderSummary(ctx, h)
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range ls.AllLadderMemberships {
+		if !strings.HasPrefix(m.LocalizedGameMode, "1v1") {
+			continue
+		}
+		l, err := c.Ladder(ctx, h, m.LadderID)
+		if err != nil {
+			return nil, err
+		}
+		id := fmt.Sprint(h.ID)
+		for _, t := range l.LadderTeams {
+			if len(t.TeamMembers) == 1 && t.TeamMembers[0].ID == id {
+				info.MMR1v1, info.Points1v1, info.Wins1v1, info.Losses1v1 = t.MMR, t.Points, t.Wins, t.Losses
+				if info.League1v1 == "" {
+					info.League1v1 = l.League
+				}
+			}
+		}
+		break
+	}
+
+	return info, nil
+}
+
+// Players fetches the current ladder data of the human players of the replay.
+// The returned map is keyed by player index (index in r.Details.Players()).
+func (c *Client) Players(ctx context.Context, r *rep.Rep) (map[int]*PlayerInfo, error) {
+	infos := map[int]*PlayerInfo{}
+	for i, p := range r.Details.Players() {
+		if p.Control() != rep.ControlHuman {
+			continue
+		}
+		info, err := c.PlayerInfo(ctx, p.Toon.Handle())
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch player info of %s: %w", p.Name, err)
+		}
+		infos[i] = info
+	}
+	return infos, nil
+}
@@ -0,0 +1,81 @@
+package bnetapi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/icza/s2prot/rep"
+)
+
+func TestPlayerInfo(t *testing.T) {
+	tokenRequests := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/token" {
+			if id, secret, ok := r.BasicAuth(); !ok || id != "id" || secret != "secret" {
+				http.Error(w, "unauthorized", http.StatusUnauthorized)
+				return
+			}
+			tokenRequests++
+			json.NewEncoder(w).Encode(map[string]interface{}{"access_token": "tok", "expires_in": 3600})
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer tok" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		var resp interface{}
+		switch r.URL.Path {
+		case "/sc2/profile/2/1/123":
+			resp = map[string]interface{}{
+				"summary": map[string]interface{}{"id": "123", "realm": 1, "displayName": "Foo", "clanTag": "BAR"},
+				"career":  map[string]interface{}{"current1v1LeagueName": "DIAMOND"},
+			}
+		case "/sc2/profile/2/1/123/ladder/summary":
+			resp = map[string]interface{}{"allLadderMemberships": []interface{}{
+				map[string]interface{}{"ladderId": "7", "localizedGameMode": "2v2 Random Diamond"},
+				map[string]interface{}{"ladderId": "9", "localizedGameMode": "1v1 Diamond"},
+			}}
+		case "/sc2/profile/2/1/123/ladder/9":
+			resp = map[string]interface{}{"league": "DIAMOND", "ladderTeams": []interface{}{
+				map[string]interface{}{"teamMembers": []interface{}{map[string]interface{}{"id": "456"}}, "mmr": 4000},
+				map[string]interface{}{"teamMembers": []interface{}{map[string]interface{}{"id": "123"}},
+					"mmr": 4321, "points": 500, "wins": 20, "losses": 10},
+			}}
+		default:
+			http.NotFound(w, r)
+			return
+		}
+		json.NewEncoder(w).Encode(resp)
+	}))
+	defer srv.Close()
+
+	c := NewClient("id", "secret")
+	c.TokenURL, c.BaseURL = srv.URL+"/token", srv.URL
+
+	h := rep.ToonHandle{RegionID: 2, ProgramID: "S2", RealmID: 1, ID: 123}
+	info, err := c.PlayerInfo(context.Background(), h)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	exp := PlayerInfo{Handle: h, DisplayName: "Foo", ClanTag: "BAR", League1v1: "DIAMOND",
+		MMR1v1: 4321, Points1v1: 500, Wins1v1: 20, Losses1v1: 10}
+	if *info != exp {
+		t.Errorf("Expected: %+v, got: %+v", exp, *info)
+	}
+	if tokenRequests != 1 {
+		t.Errorf("Expected 1 token request, got: %d", tokenRequests)
+	}
+
+	if _, err := c.Profile(context.Background(), rep.ToonHandle{RegionID: 2, ProgramID: "S2", RealmID: 1, ID: 1}); err == nil {
+		t.Errorf("Expected error for unknown profile")
+	}
+
+	c = NewClient("id", "bad")
+	c.TokenURL, c.BaseURL = srv.URL+"/token", srv.URL
+	if _, err := c.Profile(context.Background(), h); err == nil {
+		t.Errorf("Expected error for bad credentials")
+	}
+}
@@ -0,0 +1,214 @@
+/*
+
+Build order extraction and pluggable build order classification.
+
+*/
+
+package rep
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// BuildOrderItem is an item of a build order.
+type BuildOrderItem struct {
+	Loop    int64         // Game loop when the item was started (units) or placed (structures), or completed (upgrades)
+	Time    time.Duration // Real (wall clock) time of Loop
+	Supply  float64       // Supply used by the player at the time of the item
+	Name    string        // Unit type name or upgrade name
+	Upgrade bool          // Tells if the item is an upgrade
+}
+
+// BuildOrder is the build order of a player.
+type BuildOrder struct {
+	PlayerIdx int   // Index of the player in Details.Players()
+	Race      *Race // Assigned race of the player
+	Items     []BuildOrderItem
+}
+
+// Count returns the number of items with the given name started before the given real time
+// (use a non-positive value for no time limit).
+func (bo *BuildOrder) Count(name string, before time.Duration) (count int) {
+	for _, item := range bo.Items {
+		if before > 0 && item.Time >= before {
+			break
+		}
+		if item.Name == name {
+			count++
+		}
+	}
+	return
+}
+
+// notBuilt are unit types that are not built by players directly, they are excluded from build orders.
+var notBuilt = map[string]bool{
+	"Larva": true, "Egg": true, "Broodling": true, "BroodlingEscort": true, "Interceptor": true,
+	"MULE": true, "AdeptPhaseShift": true, "LocustMP": true, "LocustMPFlying": true, "AutoTurret": true,
+	"CreepTumor": true, "CreepTumorBurrowed": true, "CreepTumorQueen": true, "KD8Charge": true,
+}
+
+// buildMorphs are unit type changes (morphs) included in build orders.
+var buildMorphs = map[string]bool{
+	"OrbitalCommand": true, "PlanetaryFortress": true,
+	"Lair": true, "Hive": true, "GreaterSpire": true,
+}
+
+// BuildOrder returns the build order of the player specified by its index in Details.Players(),
+// up to the given real time (use a non-positive value to get the build order of the whole game).
+// Units are listed at the start of their production where their build time is known, at their birth otherwise.
+// Upgrades are listed at their completion. Cosmetic upgrades at loop 0 (e.g. sprays) are excluded.
+// nil is returned if tracker events were not decoded.
+func (r *Rep) BuildOrder(playerIdx int, until time.Duration) *BuildOrder {
+	if r.TrackerEvts == nil {
+		return nil
+	}
+	pid := int64(playerIdx + 1)
+	loopsPerSec := LoopsPerSecond(r.Details.GameSpeed())
+
+	units := map[int64]*Unit{} // Units mapped from tag, to check the owner of morphed units
+	for _, u := range r.Units() {
+		units[u.Tag] = u
+	}
+
+	bo := &BuildOrder{PlayerIdx: playerIdx, Race: r.PlayerAssignedRace(playerIdx)}
+	var supply float64
+	for _, e := range r.TrackerEvts.Evts {
+		loop := e.Loop()
+		if loop == 0 {
+			continue // Starting units and cosmetic upgrades
+		}
+		var item *BuildOrderItem
+		switch e.Name {
+		case "PlayerStats":
+			if e.Int("playerId") == pid {
+				stats := e.Structv("stats")
+				supply = float64(stats.Int("scoreValueFoodUsed")) / FoodScale
+			}
+		case "Upgrade":
+			if e.Int("playerId") == pid {
+				item = &BuildOrderItem{Name: e.Stringv("upgradeTypeName"), Upgrade: true}
+			}
+		case "UnitBorn", "UnitInit":
+			name := e.Stringv("unitTypeName")
+			if e.Int("controlPlayerId") != pid || notBuilt[name] || strings.HasPrefix(name, "Beacon") {
+				break
+			}
+			item = &BuildOrderItem{Name: name}
+			if _, ub := balanceOf(name); e.Name == "UnitBorn" && ub != nil && ub.buildTime > 0 {
+				loop -= int64(ub.buildTime * loopsPerSec)
+			}
+		case "UnitTypeChange":
+			if name := e.Stringv("unitTypeName"); buildMorphs[name] {
+				if u := units[evtUnitTag(e)]; u != nil && u.OwnerAt(loop) == pid {
+					item = &BuildOrderItem{Name: name}
+				}
+			}
+		}
+		if item == nil {
+			continue
+		}
+		item.Loop, item.Time, item.Supply = loop, r.loopDuration(loop), supply
+		bo.Items = append(bo.Items, *item)
+	}
+
+	sort.SliceStable(bo.Items, func(i, j int) bool { return bo.Items[i].Loop < bo.Items[j].Loop })
+	if until > 0 {
+		i := sort.Search(len(bo.Items), func(i int) bool { return bo.Items[i].Time > until })
+		bo.Items = bo.Items[:i]
+	}
+	return bo
+}
+
+// BuildClassifier classifies build orders, e.g. "2-Base Blink".
+// Implementations can be passed to Rep.ClassifyBuild() or set as DefaultBuildClassifier.
+type BuildClassifier interface {
+	// Classify returns the label of the build order, ok is false if the build order could not be classified.
+	Classify(bo *BuildOrder) (label string, ok bool)
+}
+
+// BuildClassifierFunc is a function implementing BuildClassifier.
+type BuildClassifierFunc func(bo *BuildOrder) (label string, ok bool)
+
+// Classify calls f(bo).
+func (f BuildClassifierFunc) Classify(bo *BuildOrder) (label string, ok bool) {
+	return f(bo)
+}
+
+// BuildReq is a requirement of a build rule: at least Count items of Name started before the real time Before.
+type BuildReq struct {
+	Name   string
+	Count  int           // Minimum count, values less than 1 are treated as 1
+	Before time.Duration // Time limit, non-positive means no limit
+}
+
+// BuildRule is a rule of a RuleClassifier: the build order is labeled Label if all requirements are met.
+type BuildRule struct {
+	Label string
+	Race  *Race // Race of the rule, nil matches all races
+	Reqs  []BuildReq
+}
+
+// RuleClassifier is a simple rule-based BuildClassifier: the label of the first matching rule is returned.
+type RuleClassifier struct {
+	Rules []BuildRule
+}
+
+// Classify implements BuildClassifier.
+func (rc *RuleClassifier) Classify(bo *BuildOrder) (label string, ok bool) {
+rules:
+	for _, rule := range rc.Rules {
+		if rule.Race != nil && rule.Race != bo.Race {
+			continue
+		}
+		for _, req := range rule.Reqs {
+			count := req.Count
+			if count < 1 {
+				count = 1
+			}
+			if bo.Count(req.Name, req.Before) < count {
+				continue rules
+			}
+		}
+		return rule.Label, true
+	}
+	return "", false
+}
+
+// DefaultBuildRules are the rules of the reference rule-based classifier, tuned for Legacy of the Void.
+var DefaultBuildRules = []BuildRule{
+	{"12 Pool", RaceZerg, []BuildReq{{"SpawningPool", 1, 30 * time.Second}}},
+	{"Roach Rush", RaceZerg, []BuildReq{{"RoachWarren", 1, 150 * time.Second}, {"Roach", 4, 210 * time.Second}}},
+	{"Ling/Bane", RaceZerg, []BuildReq{{"BanelingNest", 1, 210 * time.Second}}},
+	{"3 Hatch", RaceZerg, []BuildReq{{"Hatchery", 2, 120 * time.Second}}},
+
+	{"2-Rax", RaceTerran, []BuildReq{{"Barracks", 2, 90 * time.Second}}},
+	{"1-1-1", RaceTerran, []BuildReq{{"Barracks", 1, 240 * time.Second}, {"Factory", 1, 240 * time.Second}, {"Starport", 1, 240 * time.Second}}},
+	{"Reaper Expand", RaceTerran, []BuildReq{{"Reaper", 1, 120 * time.Second}, {"CommandCenter", 1, 150 * time.Second}}},
+	{"3 CC", RaceTerran, []BuildReq{{"CommandCenter", 2, 180 * time.Second}}},
+
+	{"Cannon Rush", RaceProtoss, []BuildReq{{"PhotonCannon", 1, 150 * time.Second}}},
+	{"DT Rush", RaceProtoss, []BuildReq{{"DarkShrine", 1, 330 * time.Second}}},
+	{"4 Gate", RaceProtoss, []BuildReq{{"Gateway", 4, 300 * time.Second}}},
+	{"2-Base Blink", RaceProtoss, []BuildReq{{"Nexus", 1, 150 * time.Second}, {"BlinkTech", 1, 420 * time.Second}}},
+	{"Stargate Opener", RaceProtoss, []BuildReq{{"Stargate", 1, 240 * time.Second}}},
+}
+
+// DefaultBuildClassifier is the classifier used by Rep.ClassifyBuild() if no classifier is specified.
+// It may be replaced to swap in other classifiers.
+var DefaultBuildClassifier BuildClassifier = &RuleClassifier{Rules: DefaultBuildRules}
+
+// ClassifyBuild classifies the build order of the player specified by its index in Details.Players()
+// using the given classifier, or DefaultBuildClassifier if c is nil.
+// ok is false if tracker events were not decoded or the build order could not be classified.
+func (r *Rep) ClassifyBuild(playerIdx int, c BuildClassifier) (label string, ok bool) {
+	bo := r.BuildOrder(playerIdx, 0)
+	if bo == nil {
+		return "", false
+	}
+	if c == nil {
+		c = DefaultBuildClassifier
+	}
+	return c.Classify(bo)
+}
@@ -0,0 +1,62 @@
+package rep
+
+import (
+	"testing"
+	"time"
+
+	"github.com/icza/s2prot"
+)
+
+func TestBuildOrder(t *testing.T) {
+	r := &Rep{}
+	r.Details = Details{Struct: s2prot.Struct{
+		"gameSpeed":  int64(4),
+		"playerList": []interface{}{s2prot.Struct{"race": "Zerg"}},
+	}}
+
+	if _, ok := r.ClassifyBuild(0, nil); ok {
+		t.Errorf("Expected no classification without tracker events")
+	}
+
+	r.TrackerEvts = &TrackerEvts{Evts: []s2prot.Event{
+		unitEvt(0, 1, "UnitBorn", s2prot.Struct{"unitTypeName": "Hatchery", "controlPlayerId": int64(1)}),
+		trackerEvt(0, "Upgrade", s2prot.Struct{"playerId": int64(1), "upgradeTypeName": "SprayZerg"}),
+		trackerEvt(160, "PlayerStats", s2prot.Struct{"playerId": int64(1), "stats": s2prot.Struct{"scoreValueFoodUsed": int64(13 * FoodScale)}}),
+		unitEvt(300, 2, "UnitBorn", s2prot.Struct{"unitTypeName": "Drone", "controlPlayerId": int64(1)}),
+		unitEvt(400, 3, "UnitInit", s2prot.Struct{"unitTypeName": "SpawningPool", "controlPlayerId": int64(1)}),
+		unitEvt(500, 4, "UnitBorn", s2prot.Struct{"unitTypeName": "Larva", "controlPlayerId": int64(1)}),
+		unitEvt(2000, 5, "UnitBorn", s2prot.Struct{"unitTypeName": "Queen", "controlPlayerId": int64(1)}), // Started at 2000-806
+		unitEvt(3000, 1, "UnitTypeChange", s2prot.Struct{"unitTypeName": "Lair"}),
+		trackerEvt(4000, "Upgrade", s2prot.Struct{"playerId": int64(1), "upgradeTypeName": "zerglingmovementspeed"}),
+	}}
+
+	bo := r.BuildOrder(0, 0)
+	exp := []struct {
+		loop    int64
+		name    string
+		upgrade bool
+	}{{300, "Drone", false}, {400, "SpawningPool", false}, {2000 - 806, "Queen", false}, {3000, "Lair", false}, {4000, "zerglingmovementspeed", true}}
+	if len(bo.Items) != len(exp) {
+		t.Fatalf("Expected %d items, got: %v", len(exp), bo.Items)
+	}
+	for i, e := range exp {
+		if item := bo.Items[i]; item.Loop != e.loop || item.Name != e.name || item.Upgrade != e.upgrade || item.Supply != 13 {
+			t.Errorf("[%d] Expected: %v, got: %v", i, e, item)
+		}
+	}
+	if bo := r.BuildOrder(0, time.Minute); len(bo.Items) != 3 {
+		t.Errorf("Expected 3 items in the first minute, got: %v", bo.Items)
+	}
+
+	if label, ok := r.ClassifyBuild(0, nil); !ok || label != "12 Pool" {
+		t.Errorf("Expected: %q, got: %q (%v)", "12 Pool", label, ok)
+	}
+	c := BuildClassifierFunc(func(bo *BuildOrder) (string, bool) { return "custom", bo.Count("Queen", 0) == 1 })
+	if label, ok := r.ClassifyBuild(0, c); !ok || label != "custom" {
+		t.Errorf("Expected: %q, got: %q (%v)", "custom", label, ok)
+	}
+	rc := &RuleClassifier{Rules: []BuildRule{{"Terran", RaceTerran, nil}, {"2 Queens", nil, []BuildReq{{Name: "Queen", Count: 2}}}}}
+	if label, ok := r.ClassifyBuild(0, rc); ok {
+		t.Errorf("Expected no classification, got: %q", label)
+	}
+}
@@ -0,0 +1,133 @@
+/*
+
+Camera and screen-time analytics.
+
+*/
+
+package rep
+
+import "math"
+
+// Parameters of the camera analytics.
+const (
+	// BaseRadius is the radius (in map cells) around start locations considered as "near" the base.
+	BaseRadius = 25.0
+
+	// CameraJumpDistance is the minimum distance (in map cells) of a single camera move to be considered a jump.
+	// Jumps are mostly minimap clicks (and camera location hotkeys); scrolling moves the camera in small steps.
+	CameraJumpDistance = 20.0
+)
+
+// CameraStats holds camera statistics of a player.
+type CameraStats struct {
+	Updates  int     // Number of camera updates
+	Distance float64 // Total distance of camera moves, in map cells
+
+	// Share of screen time (0..1) the camera was near the player's own start location,
+	// near an opponent's start location and elsewhere (e.g. following the army).
+	// Shares are only calculated if start locations are known (tracker events are decoded).
+	OwnBaseShare, OpponentBaseShare, ElsewhereShare float64
+
+	Jumps       int     // Number of camera jumps, see CameraJumpDistance
+	JumpsPerMin float64 // Camera jumps per real minute
+}
+
+// CameraStats calculates the camera statistics of the player specified by its index in Details.Players()
+// from the CameraUpdate game events.
+// nil is returned if game events were not decoded or the player is not a human player.
+func (r *Rep) CameraStats(playerIdx int) *CameraStats {
+	if r.GameEvts == nil {
+		return nil
+	}
+	human := false
+	for _, idx := range r.userPlayerIdxs() {
+		human = human || idx == playerIdx
+	}
+	if !human {
+		return nil
+	}
+
+	own, opponents := r.startLocations(playerIdx)
+	near := func(p Point, locs []Point) bool {
+		for _, loc := range locs {
+			if math.Hypot(p.X-loc.X, p.Y-loc.Y) <= BaseRadius {
+				return true
+			}
+		}
+		return false
+	}
+
+	cs := &CameraStats{}
+	var ownLoops, oppLoops, elseLoops int64
+	var prev Point
+	var prevLoop int64 = -1
+	account := func(untilLoop int64) {
+		if prevLoop < 0 || own == nil {
+			return
+		}
+		switch loops := untilLoop - prevLoop; {
+		case near(prev, []Point{*own}):
+			ownLoops += loops
+		case near(prev, opponents):
+			oppLoops += loops
+		default:
+			elseLoops += loops
+		}
+	}
+
+	for _, e := range r.GameEvtsOf(playerIdx) {
+		if e.Name != "CameraUpdate" {
+			continue
+		}
+		p, ok := EvtPoint(e)
+		if !ok {
+			continue
+		}
+		cs.Updates++
+		account(e.Loop())
+		if prevLoop >= 0 {
+			d := math.Hypot(p.X-prev.X, p.Y-prev.Y)
+			cs.Distance += d
+			if d >= CameraJumpDistance {
+				cs.Jumps++
+			}
+		}
+		prev, prevLoop = p, e.Loop()
+	}
+	account(r.Header.Loops())
+
+	if total := float64(ownLoops + oppLoops + elseLoops); total > 0 {
+		cs.OwnBaseShare = float64(ownLoops) / total
+		cs.OpponentBaseShare = float64(oppLoops) / total
+		cs.ElsewhereShare = float64(elseLoops) / total
+	}
+	if mins := r.RealDuration().Minutes(); mins > 0 {
+		cs.JumpsPerMin = float64(cs.Jumps) / mins
+	}
+
+	return cs
+}
+
+// startLocations returns the start location of the player specified by its index in Details.Players(),
+// and the start locations of its opponents (players of other teams).
+// own is nil if the start locations are unknown (tracker events are not decoded).
+func (r *Rep) startLocations(playerIdx int) (own *Point, opponents []Point) {
+	if r.TrackerEvts == nil {
+		return nil, nil
+	}
+	team := r.PlayerTeamID(playerIdx)
+	for i := range r.Details.Players() {
+		pd := r.TrackerEvts.PIDPlayerDescMap[int64(i+1)]
+		if pd == nil || (pd.StartLocX == 0 && pd.StartLocY == 0) {
+			continue
+		}
+		loc := TrackerPoint(pd.StartLocX, pd.StartLocY)
+		switch {
+		case i == playerIdx:
+			own = &loc
+		case r.PlayerTeamID(i) != team:
+			opponents = append(opponents, loc)
+		}
+	}
+	return
+}
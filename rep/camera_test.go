@@ -0,0 +1,45 @@
+package rep
+
+import (
+	"math"
+	"testing"
+
+	"github.com/icza/s2prot"
+)
+
+func TestCameraStats(t *testing.T) {
+	r := newTestRep(humanSlot(0, s2prot.Struct{"teamId": int64(0)}), humanSlot(1, s2prot.Struct{"teamId": int64(1)}))
+	r.Header = Header{Struct: s2prot.Struct{"elapsedGameLoops": int64(480)}}
+	r.Details.Struct["gameSpeed"] = int64(4)
+	r.TrackerEvts = &TrackerEvts{PIDPlayerDescMap: map[int64]*PlayerDesc{
+		1: {StartLocX: 30, StartLocY: 30},
+		2: {StartLocX: 130, StartLocY: 130},
+	}}
+	// Own base, opponent base, then middle of the map; 160 loops each:
+	for i, c := range []int64{30, 130, 80} {
+		r.GameEvts = append(r.GameEvts, gameEvt(int64(i*160), 0, "CameraUpdate",
+			s2prot.Struct{"target": s2prot.Struct{"x": c * CameraPointScale, "y": c * CameraPointScale}}))
+	}
+
+	cs := r.CameraStats(0)
+	if cs == nil {
+		t.Fatal("Expected camera stats, got nil")
+	}
+	near := func(a, b float64) bool { return math.Abs(a-b) < 0.001 }
+	if cs.Updates != 3 || cs.Jumps != 2 || !near(cs.Distance, 150*math.Sqrt2) {
+		t.Errorf("Unexpected updates, jumps or distance: %+v", cs)
+	}
+	if !near(cs.OwnBaseShare, 1.0/3) || !near(cs.OpponentBaseShare, 1.0/3) || !near(cs.ElsewhereShare, 1.0/3) {
+		t.Errorf("Unexpected screen time shares: %+v", cs)
+	}
+	if !near(cs.JumpsPerMin, 2/r.RealDuration().Minutes()) {
+		t.Errorf("Unexpected jumps per minute: %+v", cs)
+	}
+
+	if cs := r.CameraStats(1); cs == nil || cs.Updates != 0 {
+		t.Errorf("Expected empty camera stats, got: %+v", cs)
+	}
+	if cs := r.CameraStats(2); cs != nil {
+		t.Errorf("Expected nil for unknown player, got: %+v", cs)
+	}
+}
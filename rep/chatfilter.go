@@ -0,0 +1,134 @@
+/*
+
+Hook-based filtering of chat messages.
+
+*/
+
+package rep
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/icza/s2prot"
+)
+
+// RedactedChat is the text redacted chat messages are replaced with.
+const RedactedChat = "[redacted]"
+
+// ChatMessage is a chat message passed to ChatHooks.
+type ChatMessage struct {
+	Loop      int64  // Game loop of the message
+	UserID    int64  // User ID of the sender, -1 if unknown
+	Recipient int64  // Recipient of the message, e.g. 0 means all
+	Text      string // Text of the message, hooks may modify it
+}
+
+// ChatHook is a hook of ChatFilter. It may modify the text of the message,
+// and returns false if the message is to be dropped.
+type ChatHook func(m *ChatMessage) (keep bool)
+
+// ChatFilter is a filter of chat messages made up of hooks, e.g. to comply with privacy requirements
+// when publishing replays or datasets. Hooks are called in order, until one of them drops the message.
+//
+// The filter is used by the anonymizer (see the rewrite package) and by JSONDoc.FilterChat().
+type ChatFilter []ChatHook
+
+// Apply applies the hooks of the filter to the message, and tells if the message is to be kept.
+func (f ChatFilter) Apply(m *ChatMessage) (keep bool) {
+	for _, hook := range f {
+		if !hook(m) {
+			return false
+		}
+	}
+	return true
+}
+
+// Filter applies the filter to a message event, and tells if the event is to be kept.
+// Events other than chat messages are always kept.
+// The text of the event is updated in place if the hooks modify it.
+func (f ChatFilter) Filter(e *s2prot.Event) (keep bool) {
+	if e.Name != "Chat" {
+		return true
+	}
+	m := newChatMessage(*e)
+	if !f.Apply(m) {
+		return false
+	}
+	if m.Text != e.Stringv("string") {
+		e.Struct["string"] = m.Text
+	}
+	return true
+}
+
+// FilterEvts returns the message events kept by the filter.
+// The passed events are not modified: events whose text is modified are copied.
+func (f ChatFilter) FilterEvts(evts []s2prot.Event) []s2prot.Event {
+	if evts == nil {
+		return nil
+	}
+	kept := make([]s2prot.Event, 0, len(evts))
+	for _, e := range evts {
+		if e.Name != "Chat" {
+			kept = append(kept, e)
+			continue
+		}
+		m := newChatMessage(e)
+		if !f.Apply(m) {
+			continue
+		}
+		if m.Text != e.Stringv("string") {
+			s := make(s2prot.Struct, len(e.Struct))
+			for k, v := range e.Struct {
+				s[k] = v
+			}
+			s["string"] = m.Text
+			e.Struct = s
+		}
+		kept = append(kept, e)
+	}
+	return kept
+}
+
+// newChatMessage creates a ChatMessage from a chat message event.
+func newChatMessage(e s2prot.Event) *ChatMessage {
+	userID, ok := evtUserID(e)
+	if !ok {
+		userID = -1
+	}
+	return &ChatMessage{Loop: e.Loop(), UserID: userID, Recipient: e.Int("recipient"), Text: e.Stringv("string")}
+}
+
+// ChatDropMatching returns a hook that drops messages matching any of the given patterns.
+func ChatDropMatching(patterns ...*regexp.Regexp) ChatHook {
+	return func(m *ChatMessage) bool {
+		for _, p := range patterns {
+			if p.MatchString(m.Text) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// ChatDropCommands returns a hook that drops chat commands: messages starting with '/' or '-'
+// (e.g. "/dance" or arcade commands like "-ff").
+func ChatDropCommands() ChatHook {
+	return func(m *ChatMessage) bool {
+		return !strings.HasPrefix(m.Text, "/") && !strings.HasPrefix(m.Text, "-")
+	}
+}
+
+// ChatRedactUsers returns a hook that replaces the text of messages sent by the given users
+// with RedactedChat. Use Rep.SlotMapping() to get the user IDs of players.
+func ChatRedactUsers(userIDs ...int64) ChatHook {
+	return func(m *ChatMessage) bool {
+		for _, userID := range userIDs {
+			if m.UserID == userID {
+				m.Text = RedactedChat
+				break
+			}
+		}
+		return true
+	}
+}
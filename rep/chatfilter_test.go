@@ -0,0 +1,48 @@
+package rep
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/icza/s2prot"
+)
+
+func TestChatFilter(t *testing.T) {
+	chat := func(userID int64, text string) s2prot.Event {
+		return gameEvt(0, userID, "Chat", s2prot.Struct{"recipient": int64(0), "string": text})
+	}
+	evts := []s2prot.Event{
+		chat(0, "glhf"),
+		chat(1, "visit spam.example.com"),
+		gameEvt(0, 1, "Ping", nil),
+		chat(1, "my phone is 123"),
+		chat(0, "-ff"),
+		chat(0, "gg"),
+	}
+
+	f := ChatFilter{
+		ChatDropMatching(regexp.MustCompile(`\.com\b`)),
+		ChatDropCommands(),
+		ChatRedactUsers(1),
+	}
+	kept := f.FilterEvts(evts)
+
+	exp := []string{"glhf", "", RedactedChat, "gg"}
+	if len(kept) != len(exp) {
+		t.Fatalf("Expected %d events, got: %d", len(exp), len(kept))
+	}
+	for i, e := range kept {
+		if got := e.Stringv("string"); got != exp[i] {
+			t.Errorf("[%d] Expected: %q, got: %q", i, exp[i], got)
+		}
+	}
+	if got := evts[3].Stringv("string"); got != "my phone is 123" {
+		t.Errorf("Original event modified: %q", got)
+	}
+
+	// Filter updates in place:
+	e := chat(1, "hi")
+	if !f.Filter(&e) || e.Stringv("string") != RedactedChat {
+		t.Errorf("Expected redacted message, got: %v", e.Struct)
+	}
+}
@@ -0,0 +1,152 @@
+/*
+
+Proxy and cheese heuristics based on early building placement.
+
+*/
+
+package rep
+
+import (
+	"math"
+	"time"
+)
+
+// Parameters of the proxy and cheese heuristics.
+// Times are real (wall clock) times, default values are tuned for Legacy of the Void.
+var (
+	// ProxyTimeLimit is the time until structures are checked for being proxies.
+	ProxyTimeLimit = 4 * time.Minute
+
+	// ProxyMinDistance is the minimum distance (in map cells) of a proxy structure from its owner's start location.
+	// Structures are only considered proxies if they are also closer to an opponent's start location.
+	ProxyMinDistance = 40.0
+
+	// EarlyPoolTime is the time before which a Spawning Pool is reported as an early pool.
+	EarlyPoolTime = 45 * time.Second
+
+	// EarlyRaxTime is the time before which a second Barracks is reported as early rax.
+	EarlyRaxTime = 75 * time.Second
+
+	// EarlyGateTime is the time before which a second Gateway is reported as early gate.
+	EarlyGateTime = 75 * time.Second
+)
+
+// Finding is an early game finding, e.g. a proxy structure or an early pool.
+type Finding struct {
+	Kind      *FindingKind
+	PlayerIdx int    // Index of the player in Details.Players()
+	Loop      int64  // Game loop when the structure was started
+	Pos       Point  // Position of the structure, in map space
+	UnitType  string // Unit type name of the structure
+}
+
+// earlyRules describe the early structure timings to report: the count-th structure of the unit type
+// started before the time limit is reported.
+var earlyRules = []struct {
+	kind     *FindingKind
+	unitType string
+	count    int
+	before   *time.Duration
+}{
+	{FindingEarlyPool, "SpawningPool", 1, &EarlyPoolTime},
+	{FindingEarlyRax, "Barracks", 2, &EarlyRaxTime},
+	{FindingEarlyGate, "Gateway", 2, &EarlyGateTime},
+}
+
+// structureTypes is the set of structure unit types (of Legacy of the Void) checked for being proxies.
+// UnitInit events are also produced by units warped in by Protoss, those are not structures.
+var structureTypes = map[string]bool{
+	"CommandCenter": true, "OrbitalCommand": true, "PlanetaryFortress": true, "SupplyDepot": true,
+	"Refinery": true, "RefineryRich": true, "Barracks": true, "EngineeringBay": true, "Bunker": true,
+	"MissileTurret": true, "SensorTower": true, "Factory": true, "GhostAcademy": true, "Armory": true,
+	"Starport": true, "FusionCore": true, "TechLab": true, "Reactor": true,
+	"BarracksTechLab": true, "BarracksReactor": true, "FactoryTechLab": true, "FactoryReactor": true,
+	"StarportTechLab": true, "StarportReactor": true,
+
+	"Nexus": true, "Pylon": true, "Assimilator": true, "AssimilatorRich": true, "Gateway": true, "WarpGate": true,
+	"Forge": true, "CyberneticsCore": true, "PhotonCannon": true, "ShieldBattery": true, "TwilightCouncil": true,
+	"RoboticsFacility": true, "Stargate": true, "TemplarArchive": true, "DarkShrine": true, "RoboticsBay": true,
+	"FleetBeacon": true,
+
+	"Hatchery": true, "Lair": true, "Hive": true, "Extractor": true, "ExtractorRich": true, "SpawningPool": true,
+	"EvolutionChamber": true, "RoachWarren": true, "BanelingNest": true, "SpineCrawler": true, "SporeCrawler": true,
+	"HydraliskDen": true, "LurkerDenMP": true, "InfestationPit": true, "Spire": true, "GreaterSpire": true,
+	"NydusNetwork": true, "NydusCanal": true, "UltraliskCavern": true,
+	"CreepTumor": true, "CreepTumorBurrowed": true, "CreepTumorQueen": true,
+}
+
+// notProxyStructures are structures that are regularly built away from the main base.
+var notProxyStructures = map[string]bool{
+	"CommandCenter": true, "Nexus": true, "Hatchery": true,
+	"Refinery": true, "RefineryRich": true, "Assimilator": true, "AssimilatorRich": true,
+	"Extractor": true, "ExtractorRich": true,
+	"CreepTumor": true, "CreepTumorBurrowed": true, "CreepTumorQueen": true,
+}
+
+// CheeseFindings returns the proxy structures and early structure timings of all players,
+// detected from the UnitInit tracker events, in the order of their loop.
+// Units warped in (which also have UnitInit events) are not reported as proxies.
+// nil is returned if tracker events were not decoded.
+func (r *Rep) CheeseFindings() (findings []Finding) {
+	if r.TrackerEvts == nil {
+		return nil
+	}
+
+	type startLocs struct {
+		own       *Point
+		opponents []Point
+	}
+	locs := map[int]*startLocs{}
+	counts := map[int]map[string]int{} // Structure counts per player
+
+	for _, e := range r.TrackerEvts.Evts {
+		if e.Name != "UnitInit" {
+			continue
+		}
+		elapsed := r.loopDuration(e.Loop())
+		if elapsed > ProxyTimeLimit {
+			break
+		}
+		idx := int(e.Int("controlPlayerId")) - 1
+		if idx < 0 {
+			continue
+		}
+		f := Finding{PlayerIdx: idx, Loop: e.Loop(), Pos: TrackerPoint(e.Int("x"), e.Int("y")), UnitType: e.Stringv("unitTypeName")}
+
+		if counts[idx] == nil {
+			counts[idx] = map[string]int{}
+		}
+		counts[idx][f.UnitType]++
+		for _, rule := range earlyRules {
+			if rule.unitType == f.UnitType && rule.count == counts[idx][f.UnitType] && elapsed < *rule.before {
+				f.Kind = rule.kind
+				findings = append(findings, f)
+			}
+		}
+
+		if !structureTypes[f.UnitType] || notProxyStructures[f.UnitType] {
+			continue
+		}
+		sl := locs[idx]
+		if sl == nil {
+			sl = &startLocs{}
+			sl.own, sl.opponents = r.startLocations(idx)
+			locs[idx] = sl
+		}
+		if sl.own == nil {
+			continue
+		}
+		ownDist := math.Hypot(f.Pos.X-sl.own.X, f.Pos.Y-sl.own.Y)
+		if ownDist < ProxyMinDistance {
+			continue
+		}
+		for _, loc := range sl.opponents {
+			if math.Hypot(f.Pos.X-loc.X, f.Pos.Y-loc.Y) < ownDist {
+				f.Kind = FindingProxy
+				findings = append(findings, f)
+				break
+			}
+		}
+	}
+	return
+}
@@ -0,0 +1,51 @@
+package rep
+
+import (
+	"testing"
+
+	"github.com/icza/s2prot"
+)
+
+func TestCheeseFindings(t *testing.T) {
+	r := newTestRep(humanSlot(0, s2prot.Struct{"teamId": int64(0)}), humanSlot(1, s2prot.Struct{"teamId": int64(1)}))
+	r.Details.Struct["gameSpeed"] = int64(4)
+
+	if findings := r.CheeseFindings(); findings != nil {
+		t.Errorf("Expected no findings without tracker events, got: %v", findings)
+	}
+
+	evt := func(loop, pid int64, unitType string, x, y int64) s2prot.Event {
+		return trackerEvt(loop, "UnitInit", s2prot.Struct{"controlPlayerId": pid, "unitTypeName": unitType, "x": x, "y": y})
+	}
+	r.TrackerEvts = &TrackerEvts{
+		PIDPlayerDescMap: map[int64]*PlayerDesc{
+			1: {StartLocX: 30, StartLocY: 30},
+			2: {StartLocX: 130, StartLocY: 130},
+		},
+		Evts: []s2prot.Event{
+			evt(300, 2, "SpawningPool", 125, 125),
+			evt(900, 1, "Barracks", 35, 30),
+			evt(1000, 1, "Barracks", 110, 110),
+			evt(1100, 1, "CommandCenter", 90, 90),
+			evt(1150, 1, "Zealot", 115, 115), // Warp-in, not a proxy structure
+			evt(1160, 2, "CreepTumorQueen", 60, 60),
+			evt(1200, 2, "SpawningPool", 125, 120),
+			evt(6000, 1, "Barracks", 120, 120), // Too late for a proxy
+		},
+	}
+
+	exp := []Finding{
+		{FindingEarlyPool, 1, 300, Point{X: 125, Y: 125}, "SpawningPool"},
+		{FindingEarlyRax, 0, 1000, Point{X: 110, Y: 110}, "Barracks"},
+		{FindingProxy, 0, 1000, Point{X: 110, Y: 110}, "Barracks"},
+	}
+	findings := r.CheeseFindings()
+	if len(findings) != len(exp) {
+		t.Fatalf("Expected %d findings, got: %v", len(exp), findings)
+	}
+	for i := range exp {
+		if findings[i] != exp[i] {
+			t.Errorf("[%d] Expected: %v, got: %v", i, exp[i], findings[i])
+		}
+	}
+}
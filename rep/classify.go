@@ -0,0 +1,105 @@
+/*
+
+Classification of replays combining information from multiple sections.
+
+*/
+
+package rep
+
+// GameMode returns the game mode of the replay.
+//
+// The game mode attribute is used if present. If it's not (e.g. replays without attributes events,
+// or from builds where the attribute is missing), the following fallback chain is used:
+//   - the AMM flag of the game options denotes an AutoMM game
+//   - the single player flag of the lobby, max users being 1, a game not hosted on Battle.net,
+//     or an empty game cache name (which Battle.net hosted games always have) denote a single player game
+//
+// If none of the above can be applied, the result of AttrEvts.GameMode is returned.
+func (r *Rep) GameMode() *GameMode {
+	// Note: a missing game mode attribute is reported as GameModeSinglePlayer by AttrEvts.
+	gm := r.AttrEvts.GameMode()
+	if gm != GameModeUnknown && gm != GameModeSinglePlayer {
+		return gm
+	}
+
+	gd := &r.InitData.GameDescription
+	ls := &r.InitData.LobbyState
+
+	switch {
+	case gd.GameOptions.Amm():
+		return GameModeAutoMM
+	case ls.IsSinglePlayer(), ls.MaxUsers() == 1:
+		return GameModeSinglePlayer
+	case gd.GameOptions.Struct != nil && !gd.GameOptions.BattleNet():
+		return GameModeSinglePlayer
+	case gd.Struct != nil && gd.GameCacheName() == "":
+		return GameModeSinglePlayer
+	}
+
+	return gm
+}
+
+// Ranking returns the ranking classification of the game.
+//
+// The classification is done in the following order:
+//   - a non-zero campaign index denotes a campaign game
+//   - co-op mode denotes a co-op game
+//   - AMM games without observers are ladder games; ranked if the game is competitive (ranked in older builds)
+//   - games on Blizzard maps without non-Blizzard extension mods are custom melee games
+//   - all other games are arcade games
+//
+// RankingUnknown is returned if the init data is missing.
+func (r *Rep) Ranking() *Ranking {
+	gd := &r.InitData.GameDescription
+	if gd.Struct == nil {
+		return RankingUnknown
+	}
+
+	switch {
+	case r.Details.CampaignIndex() != 0:
+		return RankingCampaign
+	case gd.IsCoopMode() || gd.GameOptions.Cooperative():
+		return RankingCoop
+	case gd.GameOptions.Amm() && r.observerCount() == 0:
+		if gd.GameOptions.CompetitiveOrRanked() {
+			return RankingRankedLadder
+		}
+		return RankingUnrankedLadder
+	case gd.IsBlizzardMap() && !gd.HasNonBlizzardExtensionMod():
+		return RankingCustomMelee
+	}
+
+	return RankingArcade
+}
+
+// IsLadder tells if the game is a (ranked or unranked) ladder game.
+func (r *Rep) IsLadder() bool {
+	rk := r.Ranking()
+	return rk == RankingRankedLadder || rk == RankingUnrankedLadder
+}
+
+// observerCount returns the number of human observers (spectators and referees) in the lobby.
+func (r *Rep) observerCount() (count int) {
+	for i := range r.InitData.LobbyState.Slots {
+		s := &r.InitData.LobbyState.Slots[i]
+		if s.Control() == ControlHuman && s.Observe() != ObserveParticipant {
+			count++
+		}
+	}
+	return
+}
+
+// ExpansionLevel returns the expansion level of the game.
+//
+// Dependencies (cache handles) of the init data are inspected, and if they are missing, those of the details.
+// Games depend on the mods of all lower expansion levels too (e.g. a LotV game depends on
+// the WoL and HotS mods as well), and campaigns and mods are stacked on top of these,
+// so the highest expansion level found among the dependencies is returned.
+//
+// ExpLevelUnknown is returned if none of the dependencies identifies an expansion level.
+func (r *Rep) ExpansionLevel() *ExpLevel {
+	if exp := r.InitData.GameDescription.ExpLevel(); exp != ExpLevelUnknown {
+		return exp
+	}
+	return expLevelOf(r.Details.CacheHandles())
+}
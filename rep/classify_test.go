@@ -0,0 +1,132 @@
+package rep
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/icza/s2prot"
+)
+
+// newGameDescRep creates a replay for testing from the init data game description and lobby state,
+// and from the global attributes.
+func newGameDescRep(gameDesc, lobbyState s2prot.Struct, globalAttrs map[AttrID]string) *Rep {
+	r := &Rep{}
+	r.InitData = NewInitData(s2prot.Struct{"syncLobbyState": s2prot.Struct{
+		"gameDescription": gameDesc,
+		"lobbyState":      lobbyState,
+	}})
+	if globalAttrs != nil {
+		r.AttrEvts = newTestAttrEvts(map[string]map[AttrID]string{AttrScopeGlobal.key(): globalAttrs})
+	}
+	return r
+}
+
+func TestGameMode(t *testing.T) {
+	bnetDesc := s2prot.Struct{"gameCacheName": "Dflt", "gameOptions": s2prot.Struct{"battleNet": true}}
+
+	cases := []struct {
+		name       string
+		gameDesc   s2prot.Struct
+		lobbyState s2prot.Struct
+		attrs      map[AttrID]string
+		exp        *GameMode
+	}{
+		{"attribute", bnetDesc, nil, map[AttrID]string{AttrGameMode: "Priv"}, GameModePrivate},
+		{"amm", s2prot.Struct{"gameOptions": s2prot.Struct{"amm": true}}, nil, nil, GameModeAutoMM},
+		{"single player flag", bnetDesc, s2prot.Struct{"isSinglePlayer": true}, nil, GameModeSinglePlayer},
+		{"max users", bnetDesc, s2prot.Struct{"maxUsers": int64(1)}, nil, GameModeSinglePlayer},
+		{"no battle.net", s2prot.Struct{"gameCacheName": "Dflt", "gameOptions": s2prot.Struct{}}, nil, nil, GameModeSinglePlayer},
+		{"no game cache name", s2prot.Struct{"gameOptions": s2prot.Struct{"battleNet": true}}, nil, nil, GameModeSinglePlayer},
+		{"unknown", bnetDesc, s2prot.Struct{"maxUsers": int64(4)}, nil, GameModeUnknown},
+	}
+
+	for _, c := range cases {
+		r := newGameDescRep(c.gameDesc, c.lobbyState, c.attrs)
+		if got := r.GameMode(); got != c.exp {
+			t.Errorf("[%s] Expected: %v, got: %v", c.name, c.exp, got)
+		}
+	}
+}
+
+func TestRanking(t *testing.T) {
+	observer := s2prot.Struct{"control": int64(2), "observe": int64(1)}
+
+	cases := []struct {
+		name       string
+		gameDesc   s2prot.Struct
+		lobbyState s2prot.Struct
+		exp        *Ranking
+	}{
+		{"ranked", s2prot.Struct{"gameOptions": s2prot.Struct{"amm": true, "competitive": true}}, nil, RankingRankedLadder},
+		{"ranked old", s2prot.Struct{"gameOptions": s2prot.Struct{"amm": true, "ranked": true}}, nil, RankingRankedLadder},
+		{"unranked", s2prot.Struct{"gameOptions": s2prot.Struct{"amm": true, "competitive": false}}, nil, RankingUnrankedLadder},
+		{"amm observed", s2prot.Struct{"gameOptions": s2prot.Struct{"amm": true}, "isBlizzardMap": true},
+			s2prot.Struct{"slots": []interface{}{observer}}, RankingCustomMelee},
+		{"coop", s2prot.Struct{"gameOptions": s2prot.Struct{"amm": true}, "isCoopMode": true}, nil, RankingCoop},
+		{"custom", s2prot.Struct{"gameOptions": s2prot.Struct{}, "isBlizzardMap": true}, nil, RankingCustomMelee},
+		{"arcade", s2prot.Struct{"gameOptions": s2prot.Struct{}}, nil, RankingArcade},
+		{"arcade mod", s2prot.Struct{"gameOptions": s2prot.Struct{}, "isBlizzardMap": true, "hasNonBlizzardExtensionMod": true}, nil, RankingArcade},
+		{"unknown", nil, nil, RankingUnknown},
+	}
+
+	for _, c := range cases {
+		r := newGameDescRep(c.gameDesc, c.lobbyState, nil)
+		if got := r.Ranking(); got != c.exp {
+			t.Errorf("[%s] Expected: %v, got: %v", c.name, c.exp, got)
+		}
+		if isLadder := c.exp == RankingRankedLadder || c.exp == RankingUnrankedLadder; r.IsLadder() != isLadder {
+			t.Errorf("[%s] Expected IsLadder: %v", c.name, isLadder)
+		}
+	}
+
+	r := newGameDescRep(nil, nil, nil)
+	r.InitData.GameDescription.Struct = s2prot.Struct{}
+	r.Details.Struct = s2prot.Struct{"campaignIndex": int64(2)}
+	if got := r.Ranking(); got != RankingCampaign {
+		t.Errorf("Expected: %v, got: %v", RankingCampaign, got)
+	}
+}
+
+func TestExpansionLevel(t *testing.T) {
+	// Helper function to create a cache handle source string from a digest
+	chSrc := func(digest string) string {
+		d, err := hex.DecodeString(digest)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return "s2ma\x00\x00US" + string(d)
+	}
+
+	const otherDigest = "0000000000000000000000000000000000000000000000000000000000000000"
+
+	cases := []struct {
+		name    string
+		digests []string
+		exp     *ExpLevel
+	}{
+		{"none", nil, ExpLevelUnknown},
+		{"unknown", []string{otherDigest}, ExpLevelUnknown},
+		{"wol", []string{ExpLevelWoL.Digest, otherDigest}, ExpLevelWoL},
+		{"hots", []string{ExpLevelWoL.Digest, ExpLevelHotS.Digest}, ExpLevelHotS},
+		{"lotv", []string{ExpLevelWoL.Digest, ExpLevelHotS.Digest, ExpLevelLotV.Digest, otherDigest}, ExpLevelLotV},
+	}
+
+	for _, c := range cases {
+		var chs []interface{}
+		for _, d := range c.digests {
+			chs = append(chs, chSrc(d))
+		}
+
+		r := newGameDescRep(s2prot.Struct{"cacheHandles": chs}, nil, nil)
+		if got := r.ExpansionLevel(); got != c.exp {
+			t.Errorf("[%s] Expected: %v, got: %v", c.name, c.exp, got)
+		}
+
+		// Details only:
+		r = newGameDescRep(nil, nil, nil)
+		r.Details.Struct = s2prot.Struct{"cacheHandles": chs}
+		if got := r.ExpansionLevel(); got != c.exp {
+			t.Errorf("[%s] Expected: %v, got: %v", c.name, c.exp, got)
+		}
+	}
+}
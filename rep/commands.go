@@ -0,0 +1,109 @@
+/*
+
+Reconstruction of the effective command stream from Cmd, CmdUpdateTarget and CommandManagerState game events.
+
+*/
+
+package rep
+
+import "github.com/icza/s2prot"
+
+// Command is an effective command issued by a user.
+//
+// From base build 34784 repeating the previous command (same ability, e.g. spamming right click or move)
+// is not recorded as a Cmd event: a CommandManagerState event denotes the repetition,
+// preceded by a CmdUpdateTargetPoint or CmdUpdateTargetUnit event if the target changed.
+type Command struct {
+	Loop     int64         // Game loop of the command
+	UserID   int64         // User ID of the issuer
+	Sequence int64         // Sequence number of the command
+	Cmd      s2prot.Event  // The Cmd event of the command (the Cmd event being repeated for repeated commands)
+	Data     s2prot.Struct // Target data of the command, structured like the "data" of Cmd events (e.g. "TargetPoint" or "TargetUnit")
+	Repeated bool          // Tells if the command is a repetition of a previous command
+}
+
+// AbilLink returns the ability link of the command, 0 if the command has no ability (e.g. right click).
+func (c *Command) AbilLink() int64 {
+	return c.Cmd.Int("abil", "abilLink")
+}
+
+// AbilCmdIndex returns the ability command index of the command.
+func (c *Command) AbilCmdIndex() int64 {
+	return c.Cmd.Int("abil", "abilCmdIndex")
+}
+
+// Point returns the target point of the command converted to map space (the snapshot point of the target unit
+// for unit targets), and tells if the command has one.
+func (c *Command) Point() (Point, bool) {
+	return cmdDataPoint(c.Data)
+}
+
+// isRepeatCmd tells if the event is a CommandManagerState game event denoting a repeated command.
+func isRepeatCmd(e s2prot.Event) bool {
+	return e.Name == "CommandManagerState" && e.Int("state") == 1
+}
+
+// Commands returns the effective commands of the game in the order they were issued:
+// the Cmd events, and the commands repeated by CommandManagerState events
+// with their targets updated by CmdUpdateTargetPoint and CmdUpdateTargetUnit events.
+// nil is returned if game events were not decoded.
+func (r *Rep) Commands() []*Command {
+	if r.GameEvts == nil {
+		return nil
+	}
+
+	cmds := []*Command{}
+	last := map[int64]*Command{}         // Last command by user ID
+	targets := map[int64]s2prot.Struct{} // Updated target by user ID, for the next repeated command
+	for _, e := range r.GameEvts {
+		userID, ok := evtUserID(e)
+		if !ok {
+			continue
+		}
+		switch e.Name {
+		case "Cmd":
+			data, _ := e.Value("data").(s2prot.Struct)
+			c := &Command{Loop: e.Loop(), UserID: userID, Sequence: e.Int("sequence"), Cmd: e, Data: data}
+			cmds = append(cmds, c)
+			last[userID] = c
+			delete(targets, userID)
+		case "CmdUpdateTargetPoint":
+			targets[userID] = s2prot.Struct{"TargetPoint": e.Value("target")}
+		case "CmdUpdateTargetUnit":
+			targets[userID] = s2prot.Struct{"TargetUnit": e.Value("target")}
+		case "CommandManagerState":
+			prev := last[userID]
+			if !isRepeatCmd(e) || prev == nil {
+				delete(targets, userID)
+				continue
+			}
+			c := &Command{Loop: e.Loop(), UserID: userID, Sequence: e.Int("sequence"), Cmd: prev.Cmd, Data: prev.Data, Repeated: true}
+			if data, ok := targets[userID]; ok {
+				c.Data = data
+				delete(targets, userID)
+			}
+			cmds = append(cmds, c)
+			last[userID] = c
+		}
+	}
+
+	return cmds
+}
+
+// eachCommandEvt calls fn for the game events in order, passing the command created from the event
+// (nil if the event did not create a command). cmds must be the commands returned by Commands().
+func (r *Rep) eachCommandEvt(cmds []*Command, fn func(e s2prot.Event, c *Command)) {
+	i := 0 // Index of the next command
+	for _, e := range r.GameEvts {
+		var c *Command
+		// Commands are created from the events in order, check if this is the event of the next command:
+		if i < len(cmds) && (e.Name == "Cmd" || isRepeatCmd(e)) {
+			if userID, ok := evtUserID(e); ok && cmds[i].UserID == userID &&
+				cmds[i].Loop == e.Loop() && cmds[i].Sequence == e.Int("sequence") {
+				c = cmds[i]
+				i++
+			}
+		}
+		fn(e, c)
+	}
+}
@@ -0,0 +1,70 @@
+package rep
+
+import (
+	"testing"
+
+	"github.com/icza/s2prot"
+)
+
+func TestCommands(t *testing.T) {
+	r := &Rep{}
+	if r.Commands() != nil {
+		t.Error("Expected nil commands without game events!")
+	}
+
+	point := func(x int64) s2prot.Struct { return s2prot.Struct{"x": x * 4096, "y": int64(0), "z": int64(0)} }
+	repeat := func(loop, userID, seq int64) s2prot.Event {
+		return gameEvt(loop, userID, "CommandManagerState", s2prot.Struct{"state": int64(1), "sequence": seq})
+	}
+	r.GameEvts = []s2prot.Event{
+		repeat(5, 0, 1), // Nothing to repeat
+		gameEvt(10, 0, "Cmd", s2prot.Struct{
+			"sequence": int64(2),
+			"abil":     s2prot.Struct{"abilLink": int64(181), "abilCmdIndex": int64(0)},
+			"data":     s2prot.Struct{"TargetPoint": point(10)},
+		}),
+		gameEvt(12, 1, "Cmd", s2prot.Struct{"sequence": int64(1), "data": s2prot.Struct{"None": nil}}),
+		gameEvt(20, 0, "CmdUpdateTargetPoint", s2prot.Struct{"target": point(20)}),
+		repeat(20, 0, 3),
+		repeat(25, 0, 4), // Same target again
+		gameEvt(30, 0, "CmdUpdateTargetUnit", s2prot.Struct{"target": s2prot.Struct{"snapshotPoint": point(30)}}),
+		repeat(30, 0, 5),
+		gameEvt(35, 1, "CommandManagerState", s2prot.Struct{"state": int64(0), "sequence": int64(2)}),
+	}
+
+	cmds := r.Commands()
+	exp := []struct {
+		loop, userID, seq, x int64
+		repeated             bool
+	}{
+		{10, 0, 2, 10, false},
+		{12, 1, 1, -1, false},
+		{20, 0, 3, 20, true},
+		{25, 0, 4, 20, true},
+		{30, 0, 5, 30, true},
+	}
+	if len(cmds) != len(exp) {
+		t.Fatalf("Expected %d commands, got: %d", len(exp), len(cmds))
+	}
+	for i, c := range cmds {
+		e := exp[i]
+		p, ok := c.Point()
+		if c.Loop != e.loop || c.UserID != e.userID || c.Sequence != e.seq || c.Repeated != e.repeated ||
+			ok != (e.x >= 0) || ok && p.X != float64(e.x) {
+			t.Errorf("[%d] Expected: %+v, got: %+v (point: %v)", i, e, c, p)
+		}
+		if c.UserID == 0 && (c.AbilLink() != 181 || c.AbilCmdIndex() != 0) {
+			t.Errorf("[%d] Unexpected ability: %d, %d", i, c.AbilLink(), c.AbilCmdIndex())
+		}
+	}
+
+	actions := 0
+	for _, e := range r.GameEvts {
+		if isAction(e) {
+			actions++
+		}
+	}
+	if actions != 6 {
+		t.Errorf("Expected %d actions, got: %d", 6, actions)
+	}
+}
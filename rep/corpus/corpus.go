@@ -0,0 +1,141 @@
+/*
+Package corpus implements collecting and concurrently processing the replay files of replay collections (corpora),
+shared by the packages working on whole corpora (e.g. aggregate, dedup and verify).
+
+Example:
+
+	names, errs := corpus.Walk([]string{"replays"})
+	errs = append(errs, corpus.Process(names, 0, func(i int, name string) error {
+		r, err := rep.NewFromFile(name)
+		if err != nil {
+			return err
+		}
+		defer r.Close()
+		// Use r
+		return nil
+	})...)
+	for _, fe := range errs {
+		log.Printf("Failed to process %s: %v", fe.Name, fe.Err)
+	}
+*/
+package corpus
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// Ext is the extension of the replay files collected (compared case-insensitively).
+const Ext = ".SC2Replay"
+
+// FileError is an error of processing a replay file (or walking a path of a corpus).
+type FileError struct {
+	Name string // Name of the replay file
+	Err  error  // Error of processing the file
+}
+
+// Error returns the error message of the FileError.
+func (fe *FileError) Error() string {
+	return fe.Name + ": " + fe.Err.Error()
+}
+
+// ErrNoReplays is reported (as a FileError) for paths having no replay files.
+var ErrNoReplays = errors.New("no replay files")
+
+// Walk collects the replay files of the specified paths (directories are walked recursively).
+// Names are returned in the order of the paths. Files specified multiple times (e.g. by overlapping paths
+// or links) are returned only once. Errors of walking the paths and paths having no replay files
+// (reported with ErrNoReplays) are returned as FileErrors.
+func Walk(paths []string) (names []string, errs []*FileError) {
+	for _, path := range paths {
+		count := len(names)
+		err := filepath.Walk(path, func(name string, info os.FileInfo, err error) error {
+			if err != nil {
+				errs = append(errs, &FileError{Name: name, Err: err})
+				return nil
+			}
+			if !info.IsDir() && strings.EqualFold(filepath.Ext(name), Ext) {
+				names = append(names, name)
+			}
+			return nil
+		})
+		if err != nil {
+			errs = append(errs, &FileError{Name: path, Err: err})
+		} else if len(names) == count {
+			errs = append(errs, &FileError{Name: path, Err: ErrNoReplays})
+		}
+	}
+	return uniqueNames(names), errs
+}
+
+// uniqueNames returns the names without the ones denoting the same file as a preceding name
+// (checked by absolute path and os.SameFile, so links are detected too).
+func uniqueNames(names []string) []string {
+	seen := map[string]bool{}
+	bySize := map[int64][]os.FileInfo{} // Same files have the same size
+	unique := names[:0:0]
+	for _, name := range names {
+		if abs, err := filepath.Abs(name); err == nil {
+			if seen[abs] {
+				continue
+			}
+			seen[abs] = true
+		}
+		if info, err := os.Stat(name); err == nil {
+			same := false
+			for _, info2 := range bySize[info.Size()] {
+				if os.SameFile(info, info2) {
+					same = true
+					break
+				}
+			}
+			if same {
+				continue
+			}
+			bySize[info.Size()] = append(bySize[info.Size()], info)
+		}
+		unique = append(unique, name)
+	}
+	return unique
+}
+
+// Process calls f with the index and name of each file, using the given number of concurrent workers
+// (0 means runtime.NumCPU()). f may store its results by index (f is called once for each index).
+// Errors returned by f are returned as FileErrors in the order of the names.
+func Process(names []string, workers int, f func(i int, name string) error) []*FileError {
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
+	errs := make([]*FileError, len(names))
+	idxs := make(chan int)
+	wg := sync.WaitGroup{}
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for idx := range idxs {
+				if err := f(idx, names[idx]); err != nil {
+					errs[idx] = &FileError{Name: names[idx], Err: err}
+				}
+			}
+		}()
+	}
+	for i := range names {
+		idxs <- i
+	}
+	close(idxs)
+	wg.Wait()
+
+	var res []*FileError
+	for _, fe := range errs {
+		if fe != nil {
+			res = append(res, fe)
+		}
+	}
+	return res
+}
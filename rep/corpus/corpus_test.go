@@ -0,0 +1,58 @@
+package corpus
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestWalk(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, "sub"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"a.SC2Replay", "sub/b.sc2replay", "notes.txt"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(name), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	a := filepath.Join(dir, "a.SC2Replay")
+	if err := os.Link(a, filepath.Join(dir, "sub", "c.SC2Replay")); err != nil {
+		t.Fatal(err)
+	}
+	empty := t.TempDir()
+
+	// a.SC2Replay is specified 3 times, and is linked:
+	names, errs := Walk([]string{dir, a, filepath.Join(dir, ".", "a.SC2Replay"), empty})
+	if exp := []string{a, filepath.Join(dir, "sub", "b.sc2replay")}; !reflect.DeepEqual(names, exp) {
+		t.Errorf("Expected: %v, got: %v", exp, names)
+	}
+	if len(errs) != 1 || errs[0].Name != empty || errs[0].Err != ErrNoReplays {
+		t.Errorf("Expected no replays error, got: %v", errs)
+	}
+}
+
+func TestProcess(t *testing.T) {
+	names := []string{"a", "b", "c", "d", "e"}
+	errX := errors.New("x")
+
+	processed := make([]string, len(names))
+	errs := Process(names, 2, func(i int, name string) error {
+		processed[i] = name
+		if i%2 == 1 {
+			return errX
+		}
+		return nil
+	})
+	if !reflect.DeepEqual(processed, names) {
+		t.Errorf("Expected: %v, got: %v", names, processed)
+	}
+	if len(errs) != 2 || errs[0].Name != "b" || errs[1].Name != "d" || errs[0].Err != errX {
+		t.Errorf("Expected errors of b and d, got: %v", errs)
+	}
+	if exp := "b: x"; errs[0].Error() != exp {
+		t.Errorf("Expected: %s, got: %s", exp, errs[0].Error())
+	}
+}
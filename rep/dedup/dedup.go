@@ -0,0 +1,147 @@
+/*
+Package dedup implements finding and pruning duplicate replays in replay collections.
+
+Duplicates are replays of the same game (see rep.Rep.Fingerprint), e.g. saved by different
+players of the game or saved multiple times. Of each group of duplicates the most complete copy
+(the one with the most game loops, the largest file on ties) is kept.
+
+Example:
+
+	files, errs := dedup.Scan([]string{"replays"}, 0)
+	for _, fe := range errs {
+		log.Printf("Failed to parse %s: %v", fe.Name, fe.Err)
+	}
+	groups := dedup.Find(files)
+	for _, g := range groups {
+		fmt.Printf("Keeping %s, duplicates: %d\n", g.Keep.Path, len(g.Dups))
+	}
+	if err := dedup.Prune(groups); err != nil {
+		// Handle error
+	}
+*/
+package dedup
+
+import (
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/icza/s2prot/rep"
+	"github.com/icza/s2prot/rep/corpus"
+)
+
+// File is a scanned replay file.
+type File struct {
+	Path        string `json:"path"`        // Path of the file
+	Size        int64  `json:"size"`        // Size of the file in bytes
+	Fingerprint string `json:"fingerprint"` // Fingerprint of the game, see rep.Rep.Fingerprint
+	Loops       int64  `json:"loops"`       // Number of game loops (length of the replay)
+}
+
+// Group is a group of replay files of the same game.
+type Group struct {
+	Fingerprint string  `json:"fingerprint"` // Fingerprint of the game
+	Keep        *File   `json:"keep"`        // The most complete copy to keep
+	Dups        []*File `json:"dups"`        // Duplicates to prune
+}
+
+// Scan scans the specified paths for replay files (see corpus.Walk),
+// and computes their fingerprints and lengths using the given number of concurrent workers
+// (0 means runtime.NumCPU()). Files that fail to scan are returned as FileErrors.
+// Files are returned in the order of the paths. Files specified multiple times (e.g. by overlapping paths)
+// are scanned only once.
+func Scan(paths []string, workers int) (files []*File, errs []*corpus.FileError) {
+	names, errs := corpus.Walk(paths)
+	scanned := make([]*File, len(names))
+	errs = append(errs, corpus.Process(names, workers, func(i int, name string) (err error) {
+		scanned[i], err = ScanFile(name)
+		return
+	})...)
+	for _, f := range scanned {
+		if f != nil {
+			files = append(files, f)
+		}
+	}
+	return
+}
+
+// ScanFile scans a replay file. Only the header, details and init data are decoded.
+func ScanFile(name string) (*File, error) {
+	info, err := os.Stat(name)
+	if err != nil {
+		return nil, err
+	}
+	r, err := rep.NewFromFileEvts(name, false, false, false)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	return &File{
+		Path:        name,
+		Size:        info.Size(),
+		Fingerprint: r.Fingerprint(),
+		Loops:       r.Header.Loops(),
+	}, nil
+}
+
+// Find finds the duplicates among the files.
+// Groups are returned in the order of the first occurrence of their games, groups of games
+// with a single file are excluded. Duplicates in a group are ordered from the most complete.
+func Find(files []*File) []*Group {
+	byFp := map[string][]*File{}
+	var fps []string
+	for _, f := range files {
+		if byFp[f.Fingerprint] == nil {
+			fps = append(fps, f.Fingerprint)
+		}
+		byFp[f.Fingerprint] = append(byFp[f.Fingerprint], f)
+	}
+
+	var groups []*Group
+	for _, fp := range fps {
+		fs := byFp[fp]
+		if len(fs) < 2 {
+			continue
+		}
+		sort.SliceStable(fs, func(i, j int) bool {
+			if fs[i].Loops != fs[j].Loops {
+				return fs[i].Loops > fs[j].Loops
+			}
+			return fs[i].Size > fs[j].Size
+		})
+		groups = append(groups, &Group{Fingerprint: fp, Keep: fs[0], Dups: fs[1:]})
+	}
+	return groups
+}
+
+// Prune deletes the duplicates of the groups (the files to keep are left untouched).
+// Duplicates that are the same file as the file to keep of their group (or that cannot be checked)
+// are refused to be deleted, so the last copy of a game is never lost.
+// All duplicates are attempted to be deleted, the first error is returned.
+func Prune(groups []*Group) (err error) {
+	for _, g := range groups {
+		for _, f := range g.Dups {
+			if err2 := pruneDup(g.Keep, f); err2 != nil && err == nil {
+				err = err2
+			}
+		}
+	}
+	return
+}
+
+// pruneDup deletes the duplicate dup of the file to keep.
+func pruneDup(keep, dup *File) error {
+	keepInfo, err := os.Stat(keep.Path)
+	if err != nil {
+		return err
+	}
+	info, err := os.Stat(dup.Path)
+	if err != nil {
+		return err
+	}
+	if os.SameFile(keepInfo, info) {
+		return fmt.Errorf("refusing to delete %s: same file as the kept %s", dup.Path, keep.Path)
+	}
+	return os.Remove(dup.Path)
+}
@@ -0,0 +1,96 @@
+package dedup
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/icza/s2prot"
+	"github.com/icza/s2prot/rep/reptest"
+)
+
+func TestDedup(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, "sub"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	write := func(name string, randomValue, loops int64) {
+		r := reptest.New(80949)
+		r.Loops = loops
+		r.Customize = func(kind string, s s2prot.Struct) {
+			if kind == s2prot.KindInitData {
+				s.Structv("syncLobbyState", "gameDescription")["randomValue"] = randomValue
+			}
+		}
+		if err := r.WriteFile(filepath.Join(dir, name)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("a1.SC2Replay", 1, 1000)
+	write("b.SC2Replay", 2, 1000)
+	write("sub/a2.sc2replay", 1, 1344)
+	write("sub/a3.SC2Replay", 1, 500)
+	if err := os.WriteFile(filepath.Join(dir, "invalid.SC2Replay"), []byte("invalid"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("not a replay"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	files, errs := Scan([]string{dir}, 2)
+	if len(files) != 4 {
+		t.Errorf("Expected: %d files, got: %d", 4, len(files))
+	}
+	if len(errs) != 1 || filepath.Base(errs[0].Name) != "invalid.SC2Replay" {
+		t.Errorf("Expected 1 error for invalid.SC2Replay, got: %v", errs)
+	}
+
+	groups := Find(files)
+	if len(groups) != 1 {
+		t.Fatalf("Expected: %d groups, got: %d", 1, len(groups))
+	}
+	g := groups[0]
+	if filepath.Base(g.Keep.Path) != "a2.sc2replay" || g.Keep.Loops != 1344 || len(g.Dups) != 2 ||
+		filepath.Base(g.Dups[0].Path) != "a1.SC2Replay" || filepath.Base(g.Dups[1].Path) != "a3.SC2Replay" {
+		t.Errorf("Unexpected group: keep: %+v, dups: %+v %+v", g.Keep, g.Dups[0], g.Dups[1])
+	}
+
+	if err := Prune(groups); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	files, _ = Scan([]string{dir}, 0)
+	if len(files) != 2 || len(Find(files)) != 0 {
+		t.Errorf("Expected 2 files without duplicates, got: %d", len(files))
+	}
+}
+
+func TestOverlappingPaths(t *testing.T) {
+	dir := t.TempDir()
+	name := filepath.Join(dir, "a.SC2Replay")
+	if err := reptest.New(80949).WriteFile(name); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Link(name, filepath.Join(dir, "a-link.SC2Replay")); err != nil {
+		t.Fatal(err)
+	}
+
+	// The file is specified 3 times, and is linked:
+	files, errs := Scan([]string{dir, name, filepath.Join(dir, ".", "a.SC2Replay")}, 0)
+	if len(files) != 1 || len(errs) != 0 {
+		t.Fatalf("Expected 1 file and no errors, got: %d %v", len(files), errs)
+	}
+	if groups := Find(files); len(groups) != 0 {
+		t.Errorf("Expected no groups, got: %d", len(groups))
+	}
+
+	// Prune must refuse to delete the kept file:
+	keep, dup := *files[0], *files[0]
+	dup.Path = filepath.Join(dir, "a-link.SC2Replay")
+	if err := Prune([]*Group{{Keep: &keep, Dups: []*File{&dup}}}); err == nil {
+		t.Errorf("Expected error for pruning the kept file")
+	}
+	if _, err := os.Stat(name); err != nil {
+		t.Errorf("Kept file must exist: %v", err)
+	}
+}
@@ -0,0 +1,66 @@
+/*
+
+Dependency analysis: resolving the cache handles the replay depends on.
+
+*/
+
+package rep
+
+// Dependency is a resolved dependency of the replay.
+type Dependency struct {
+	*CacheHandle
+	Kind *DependencyKind // Kind of the dependency
+}
+
+// Dependencies describes the dependencies of the replay.
+type Dependencies struct {
+	// Chain is the dependency chain in load order: standard data first, the map last.
+	Chain []*Dependency
+
+	// Map is the inferred primary map handle: the last dependency that is not a standard data, nil if there is none.
+	Map *CacheHandle
+
+	// Mods are the dependencies that are not known standard data, excluding the map (e.g. extension mods).
+	Mods []*CacheHandle
+
+	HasExtensionMod            bool // Tells if the game was played with an extension mod
+	HasNonBlizzardExtensionMod bool // Tells if the game was played with an extension mod not made by Blizzard
+}
+
+// Dependencies returns the dependencies of the replay, resolved from the cache handles of the game description
+// (or of the details if the game description has none).
+//
+// Note that mods are classified by their digests, so new Blizzard mods are reported as Mods
+// unless they are registered with RegisterStandardData.
+func (r *Rep) Dependencies() *Dependencies {
+	chs := r.InitData.GameDescription.CacheHandles()
+	if len(chs) == 0 {
+		chs = r.Details.CacheHandles()
+	}
+
+	deps := &Dependencies{
+		Chain:                      make([]*Dependency, len(chs)),
+		HasExtensionMod:            r.InitData.GameDescription.HasExtensionMod(),
+		HasNonBlizzardExtensionMod: r.InitData.GameDescription.HasNonBlizzardExtensionMod(),
+	}
+	mapIdx := -1
+	for i, ch := range chs {
+		deps.Chain[i] = &Dependency{CacheHandle: ch, Kind: DependencyKindMod}
+		if ch.IsStandard() {
+			deps.Chain[i].Kind = DependencyKindStandard
+		} else {
+			mapIdx = i
+		}
+	}
+	if mapIdx >= 0 {
+		deps.Chain[mapIdx].Kind = DependencyKindMap
+		deps.Map = chs[mapIdx]
+	}
+	for _, d := range deps.Chain {
+		if d.Kind == DependencyKindMod {
+			deps.Mods = append(deps.Mods, d.CacheHandle)
+		}
+	}
+
+	return deps
+}
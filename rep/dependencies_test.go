@@ -0,0 +1,57 @@
+package rep
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/icza/s2prot"
+)
+
+func TestDependencies(t *testing.T) {
+	chs := func(digests ...string) (arr []interface{}) {
+		for _, d := range digests {
+			raw, _ := hex.DecodeString(d)
+			arr = append(arr, "s2ma\x00\x00EU"+string(raw))
+		}
+		return
+	}
+	core := "6de41503baccd05656360b6f027db88169fa1989bb6357b1b215a2547939f5fb"
+	teams := "658e520aa5deb48866dc2b21b023daa9a291be4cf22fd9d785ca67f178132a87"
+	mod := "0000000000000000000000000000000000000000000000000000000000000001"
+	mapDigest := "0000000000000000000000000000000000000000000000000000000000000002"
+
+	r := &Rep{}
+	r.InitData = NewInitData(s2prot.Struct{"syncLobbyState": s2prot.Struct{"gameDescription": s2prot.Struct{
+		"cacheHandles":               chs(core, mod, teams, mapDigest),
+		"hasExtensionMod":            true,
+		"hasNonBlizzardExtensionMod": true,
+	}}})
+
+	deps := r.Dependencies()
+	expKinds := []*DependencyKind{DependencyKindStandard, DependencyKindMod, DependencyKindStandard, DependencyKindMap}
+	if len(deps.Chain) != len(expKinds) {
+		t.Fatalf("Expected %d dependencies, got: %d", len(expKinds), len(deps.Chain))
+	}
+	for i, d := range deps.Chain {
+		if d.Kind != expKinds[i] {
+			t.Errorf("[%d] Expected: %v, got: %v", i, expKinds[i], d.Kind)
+		}
+	}
+	if deps.Map == nil || deps.Map.Digest != mapDigest {
+		t.Errorf("Expected map: %s, got: %v", mapDigest, deps.Map)
+	}
+	if len(deps.Mods) != 1 || deps.Mods[0].Digest != mod {
+		t.Errorf("Expected mods: [%s], got: %v", mod, deps.Mods)
+	}
+	if !deps.HasExtensionMod || !deps.HasNonBlizzardExtensionMod {
+		t.Errorf("Expected extension mod flags: %+v", deps)
+	}
+
+	// Fall back to details, no map:
+	r = &Rep{}
+	r.Details = Details{Struct: s2prot.Struct{"cacheHandles": chs(core, teams)}}
+	deps = r.Dependencies()
+	if len(deps.Chain) != 2 || deps.Map != nil || deps.Mods != nil {
+		t.Errorf("Unexpected dependencies: %+v", deps)
+	}
+}
@@ -0,0 +1,156 @@
+/*
+Package depot implements a client to download resources denoted by cache handles
+(e.g. map files and mod dependencies referenced by replays) from the Battle.net depot servers.
+
+Downloaded resources are stored in a local cache folder using the same layout the game uses,
+see rep.CacheHandle.RelativeFile(). Content of downloaded resources is verified against
+the digest of the cache handle.
+
+Example:
+
+	c := depot.NewClient("/path/to/cache")
+	for _, ch := range r.Details.CacheHandles() {
+		if ch.Type != "s2ma" {
+			continue
+		}
+		name, err := c.Fetch(context.Background(), ch)
+		if err != nil {
+			// Handle error
+		}
+		fmt.Println("Map file:", name)
+	}
+*/
+package depot
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/icza/s2prot/rep"
+)
+
+var (
+	// ErrUnknownRegion means the region of the cache handle is unknown, so there is no depot to download from.
+	ErrUnknownRegion = errors.New("unknown depot region")
+
+	// ErrDigestMismatch means the digest of the downloaded content does not match the cache handle.
+	ErrDigestMismatch = errors.New("digest mismatch")
+)
+
+// Client downloads resources denoted by cache handles into a local cache folder.
+// A Client is safe for concurrent use by multiple goroutines.
+type Client struct {
+	// CacheDir is the local cache folder.
+	CacheDir string
+
+	// HTTPClient is used to download resources. If nil, http.DefaultClient is used.
+	HTTPClient *http.Client
+}
+
+// NewClient creates a new Client using the specified local cache folder.
+func NewClient(cacheDir string) *Client {
+	return &Client{CacheDir: cacheDir}
+}
+
+// Path returns the name of the local file of the resource denoted by the cache handle.
+// The file might not exist.
+func (c *Client) Path(ch *rep.CacheHandle) string {
+	return filepath.Join(c.CacheDir, filepath.FromSlash(ch.RelativeFile()))
+}
+
+// Cached tells if the resource denoted by the cache handle is present in the local cache.
+func (c *Client) Cached(ch *rep.CacheHandle) bool {
+	fi, err := os.Stat(c.Path(ch))
+	return err == nil && fi.Mode().IsRegular()
+}
+
+// Fetch returns the name of the local file of the resource denoted by the cache handle,
+// downloading it first if it's not yet present in the local cache.
+// The downloaded content is verified against the digest of the cache handle,
+// ErrDigestMismatch is returned if they do not match (in which case nothing is stored).
+func (c *Client) Fetch(ctx context.Context, ch *rep.CacheHandle) (string, error) {
+	name := c.Path(ch)
+	if c.Cached(ch) {
+		return name, nil
+	}
+
+	if err := c.download(ctx, ch, name); err != nil {
+		return "", err
+	}
+	return name, nil
+}
+
+// resourceURL returns the depot URL of the resource denoted by the cache handle.
+func resourceURL(ch *rep.CacheHandle) (string, error) {
+	u := ch.DownloadURL()
+	if u == nil {
+		return "", ErrUnknownRegion
+	}
+	return u.String(), nil
+}
+
+// download downloads the resource denoted by the cache handle into the specified file.
+func (c *Client) download(ctx context.Context, ch *rep.CacheHandle, name string) (err error) {
+	u, err := resourceURL(ch)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+
+	hc := c.HTTPClient
+	if hc == nil {
+		hc = http.DefaultClient
+	}
+	resp, err := hc.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected response status downloading %s: %s", u, resp.Status)
+	}
+
+	if err = os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+		return err
+	}
+
+	// Download into a temp file first, so incomplete or invalid content never appears in the cache:
+	f, err := ioutil.TempFile(filepath.Dir(name), ".download-")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		f.Close() // Closing again is harmless
+		if err != nil {
+			os.Remove(f.Name())
+		}
+	}()
+
+	h := sha256.New()
+	if _, err = io.Copy(io.MultiWriter(f, h), resp.Body); err != nil {
+		return err
+	}
+	if err = f.Close(); err != nil {
+		return err
+	}
+
+	if hex.EncodeToString(h.Sum(nil)) != ch.Digest {
+		return ErrDigestMismatch
+	}
+
+	return os.Rename(f.Name(), name)
+}
@@ -0,0 +1,81 @@
+package depot
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/icza/s2prot/rep"
+)
+
+func TestFetch(t *testing.T) {
+	content := []byte("map content")
+	sum := sha256.Sum256(content)
+	digest := hex.EncodeToString(sum[:])
+
+	requests := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		switch r.URL.Path {
+		case "/" + digest + ".s2ma":
+			w.Write(content)
+		case "/" + strings.Repeat("0", 64) + ".s2ma":
+			w.Write([]byte("tampered"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	u, err := url.Parse(srv.URL + "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	region := &rep.Region{Enum: rep.Enum{Name: "Test"}, DepotURL: u}
+
+	c := NewClient(t.TempDir())
+	ctx := context.Background()
+
+	ch := &rep.CacheHandle{Type: "s2ma", Region: region, Digest: digest}
+	for i := 0; i < 2; i++ {
+		name, err := c.Fetch(ctx, ch)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if exp := filepath.Join(c.CacheDir, digest[:2], digest[2:4], digest+".s2ma"); name != exp {
+			t.Errorf("Expected: %s, got: %s", exp, name)
+		}
+		if data, err := ioutil.ReadFile(name); err != nil || string(data) != string(content) {
+			t.Errorf("Unexpected content: %q (err: %v)", data, err)
+		}
+	}
+	if requests != 1 {
+		t.Errorf("Expected 1 request (second fetch is cached), got: %d", requests)
+	}
+
+	ch = &rep.CacheHandle{Type: "s2ma", Region: region, Digest: strings.Repeat("0", 64)}
+	if _, err := c.Fetch(ctx, ch); err != ErrDigestMismatch {
+		t.Errorf("Expected: %v, got: %v", ErrDigestMismatch, err)
+	}
+	if c.Cached(ch) {
+		t.Errorf("Invalid content must not be cached!")
+	}
+
+	ch = &rep.CacheHandle{Type: "s2ma", Region: region, Digest: strings.Repeat("1", 64)}
+	if _, err := c.Fetch(ctx, ch); err == nil {
+		t.Errorf("Expected error for missing resource!")
+	}
+
+	ch = &rep.CacheHandle{Type: "s2ma", Region: rep.RegionUnknown, Digest: digest[:]}
+	c2 := NewClient(t.TempDir())
+	if _, err := c2.Fetch(ctx, ch); err != ErrUnknownRegion {
+		t.Errorf("Expected: %v, got: %v", ErrUnknownRegion, err)
+	}
+}
@@ -42,24 +42,34 @@ func (d *Details) ThumbnailFile() string {
 	return d.Stringv("thumbnail", "file")
 }
 
+// fileTimeUnixEpoch is the Unix epoch (1970-01-01 UTC) expressed in Windows file time:
+// the number of 100-nanosecond intervals since 1601-01-01 UTC.
+const fileTimeUnixEpoch = 116444736000000000
+
 // Time returns the replay date+time.
 func (d *Details) Time() time.Time {
-	// timeUTC is in 10 microsecond unit
-	return time.Unix(0, (d.Int("timeUTC")-116444736000000000)*100)
+	// timeUTC is a Windows file time, in 100 nanosecond unit
+	return time.Unix(0, (d.Int("timeUTC")-fileTimeUnixEpoch)*100)
 }
 
 // TimeUTC returns the replay date+time - localOffset
 func (d *Details) TimeUTC() time.Time {
-	// timeUTC is in 10 microsecond unit
-	return time.Unix(0, (d.Int("timeUTC")-116444736000000000-(d.Int("timeLocalOffset")))*100)
+	// timeUTC is a Windows file time, in 100 nanosecond unit
+	return time.Unix(0, (d.Int("timeUTC")-fileTimeUnixEpoch-(d.Int("timeLocalOffset")))*100)
 }
 
 // TimeLocalOffset returns the local time offset of the playing who saved the replay.
 func (d *Details) TimeLocalOffset() time.Duration {
-	// timeLocalOffset is in 10 microsecond unit
+	// timeLocalOffset is in 100 nanosecond unit
 	return time.Duration(d.Int("timeLocalOffset") * 100)
 }
 
+// LocalTime returns the replay date+time (see TimeUTC()) in the local time zone of the player who saved the replay
+// (a fixed zone with TimeLocalOffset() offset).
+func (d *Details) LocalTime() time.Time {
+	return d.TimeUTC().In(time.FixedZone("", int(d.TimeLocalOffset()/time.Second)))
+}
+
 // CacheHandles returns the array of cache handles.
 func (d *Details) CacheHandles() []*CacheHandle {
 	if d.cacheHandles == nil {
@@ -176,6 +186,8 @@ func (p *Player) Race() *Race {
 
 // TeamID returns the team ID.
 // Not always accurate! Team ID from slot (init data) should be used instead!
+//
+// Deprecated: Use Rep.PlayerTeamID() or Rep.Teams() for team logic.
 func (p *Player) TeamID() int64 {
 	return p.Int("teamId")
 }
@@ -0,0 +1,254 @@
+/*
+
+Dispatching events to handlers registered by event name, in loop order across the event streams.
+
+*/
+
+package rep
+
+import "github.com/icza/s2prot"
+
+// EvtKind is the kind of an event (the stream it comes from).
+type EvtKind int
+
+// Event kinds.
+const (
+	EvtKindGame    EvtKind = iota // Game event
+	EvtKindMessage                // Message event
+	EvtKindTracker                // Tracker event
+)
+
+// evtKindNames holds the names of the event kinds, index is the EvtKind.
+var evtKindNames = [...]string{"game", "message", "tracker"}
+
+// String returns the name of the event kind, e.g. "game".
+func (k EvtKind) String() string {
+	if k >= 0 && int(k) < len(evtKindNames) {
+		return evtKindNames[k]
+	}
+	return "unknown"
+}
+
+// EvtHandler is a handler of events of any name.
+type EvtHandler func(kind EvtKind, e s2prot.Event)
+
+// EventDispatcher dispatches events to handlers registered by event name (and kind).
+//
+// Events of the game, message and tracker streams are dispatched merged, in loop order.
+// Events of the same loop are dispatched in the order of their kinds (game events first, tracker events last),
+// events of the same stream in their original order.
+//
+// Handlers are called in the order of their registration.
+// The zero value is ready to use.
+type EventDispatcher struct {
+	handlers [3]map[string][]func(e s2prot.Event) // Handlers by event name, index is the EvtKind
+	any      []EvtHandler                         // Handlers of all events
+}
+
+// NewEventDispatcher returns a new EventDispatcher.
+func NewEventDispatcher() *EventDispatcher {
+	return &EventDispatcher{}
+}
+
+// On registers a handler for events of the given kind and name (e.g. EvtKindTracker, TrEvtUnitBorn).
+func (d *EventDispatcher) On(kind EvtKind, name string, h func(e s2prot.Event)) {
+	if d.handlers[kind] == nil {
+		d.handlers[kind] = map[string][]func(e s2prot.Event){}
+	}
+	d.handlers[kind][name] = append(d.handlers[kind][name], h)
+}
+
+// OnAny registers a handler for all events.
+// Handlers of all events are called before the handlers registered by name.
+func (d *EventDispatcher) OnAny(h EvtHandler) {
+	d.any = append(d.any, h)
+}
+
+// OnCmd registers a handler for Cmd game events.
+func (d *EventDispatcher) OnCmd(h func(e CmdEvt)) {
+	d.On(EvtKindGame, GmEvtCmd, func(e s2prot.Event) { h(CmdEvt{e}) })
+}
+
+// OnResourceTrade registers a handler for ResourceTrade game events.
+func (d *EventDispatcher) OnResourceTrade(h func(e ResourceTradeEvt)) {
+	d.On(EvtKindGame, GmEvtResourceTrade, func(e s2prot.Event) { h(ResourceTradeEvt{e}) })
+}
+
+// OnAlliance registers a handler for Alliance game events.
+func (d *EventDispatcher) OnAlliance(h func(e AllianceEvt)) {
+	d.On(EvtKindGame, GmEvtAlliance, func(e s2prot.Event) { h(AllianceEvt{e}) })
+}
+
+// OnChat registers a handler for Chat message events.
+func (d *EventDispatcher) OnChat(h func(e ChatEvt)) {
+	d.On(EvtKindMessage, MsgEvtChat, func(e s2prot.Event) { h(ChatEvt{e}) })
+}
+
+// OnPlayerStats registers a handler for PlayerStats tracker events.
+func (d *EventDispatcher) OnPlayerStats(h func(e PlayerStatsEvt)) {
+	d.On(EvtKindTracker, TrEvtPlayerStats, func(e s2prot.Event) { h(PlayerStatsEvt{e}) })
+}
+
+// OnUnitBorn registers a handler for UnitBorn tracker events.
+func (d *EventDispatcher) OnUnitBorn(h func(e UnitEvt)) {
+	d.On(EvtKindTracker, TrEvtUnitBorn, func(e s2prot.Event) { h(UnitEvt{e}) })
+}
+
+// OnUnitInit registers a handler for UnitInit tracker events.
+func (d *EventDispatcher) OnUnitInit(h func(e UnitEvt)) {
+	d.On(EvtKindTracker, TrEvtUnitInit, func(e s2prot.Event) { h(UnitEvt{e}) })
+}
+
+// OnUnitDone registers a handler for UnitDone tracker events.
+func (d *EventDispatcher) OnUnitDone(h func(e UnitEvt)) {
+	d.On(EvtKindTracker, TrEvtUnitDone, func(e s2prot.Event) { h(UnitEvt{e}) })
+}
+
+// OnUnitDied registers a handler for UnitDied tracker events.
+func (d *EventDispatcher) OnUnitDied(h func(e UnitEvt)) {
+	d.On(EvtKindTracker, TrEvtUnitDied, func(e s2prot.Event) { h(UnitEvt{e}) })
+}
+
+// OnUpgrade registers a handler for Upgrade tracker events.
+func (d *EventDispatcher) OnUpgrade(h func(e UpgradeEvt)) {
+	d.On(EvtKindTracker, TrEvtUpgrade, func(e s2prot.Event) { h(UpgradeEvt{e}) })
+}
+
+// Dispatch dispatches the decoded events of the replay.
+// Streams that were not decoded are skipped.
+func (d *EventDispatcher) Dispatch(r *Rep) {
+	d.DispatchIter(r.EvtIterator())
+}
+
+// DispatchEvts dispatches the specified game, message and tracker events.
+// Events of each stream must be in loop order (as decoded); any of the streams may be nil.
+func (d *EventDispatcher) DispatchEvts(gameEvts, messageEvts, trackerEvts []s2prot.Event) {
+	d.DispatchIter(NewEvtIterator(gameEvts, messageEvts, trackerEvts))
+}
+
+// DispatchIter dispatches the remaining events of the iterator.
+func (d *EventDispatcher) DispatchIter(it *EvtIterator) {
+	it.Each(func(me MergedEvt) {
+		for _, h := range d.any {
+			h(me.Kind, me.Event)
+		}
+		for _, h := range d.handlers[me.Kind][me.Name] {
+			h(me.Event)
+		}
+	})
+}
+
+// CmdEvt wraps a Cmd game event.
+type CmdEvt struct {
+	s2prot.Event
+}
+
+// Sequence returns the sequence number of the command.
+func (e CmdEvt) Sequence() int64 {
+	return e.Int("sequence")
+}
+
+// AbilLink returns the ability link of the command, 0 if the command has no ability (e.g. right click).
+func (e CmdEvt) AbilLink() int64 {
+	return e.Int("abil", "abilLink")
+}
+
+// AbilCmdIndex returns the ability command index of the command.
+func (e CmdEvt) AbilCmdIndex() int64 {
+	return e.Int("abil", "abilCmdIndex")
+}
+
+// TargetUnitTag returns the tag of the target unit, and tells if the command targets a unit.
+func (e CmdEvt) TargetUnitTag() (tag int64, ok bool) {
+	return e.LookupInt("data", "TargetUnit", "tag")
+}
+
+// Point returns the target point of the command converted to map space, see EvtPoint.
+func (e CmdEvt) Point() (Point, bool) {
+	return EvtPoint(e.Event)
+}
+
+// ChatEvt wraps a Chat message event.
+type ChatEvt struct {
+	s2prot.Event
+}
+
+// Recipient returns the recipient of the message (e.g. 0 for all, 2 for allies).
+func (e ChatEvt) Recipient() int64 {
+	return e.Int("recipient")
+}
+
+// Text returns the text of the message.
+func (e ChatEvt) Text() string {
+	return e.Stringv("string")
+}
+
+// PlayerStatsEvt wraps a PlayerStats tracker event.
+type PlayerStatsEvt struct {
+	s2prot.Event
+}
+
+// PlayerID returns the ID of the player (index in Details.Players() plus 1).
+func (e PlayerStatsEvt) PlayerID() int64 {
+	return e.Int("playerId")
+}
+
+// Stat returns the value of the named stat, e.g. "scoreValueMineralsCurrent".
+func (e PlayerStatsEvt) Stat(name string) int64 {
+	return e.Int("stats", name)
+}
+
+// StatOf returns the value of the stat field, and tells if the event has it. See StatFields.
+func (e PlayerStatsEvt) StatOf(f *StatField) (int64, bool) {
+	stats, _ := e.Value("stats").(s2prot.Struct)
+	return StatOf(stats, f)
+}
+
+// UnitEvt wraps a tracker unit event (UnitBorn, UnitInit, UnitDone or UnitDied).
+type UnitEvt struct {
+	s2prot.Event
+}
+
+// Tag returns the tag of the unit.
+func (e UnitEvt) Tag() int64 {
+	return evtUnitTag(e.Event)
+}
+
+// UnitTypeName returns the unit type name of the unit, empty string for UnitDone and UnitDied events.
+func (e UnitEvt) UnitTypeName() string {
+	return e.Stringv("unitTypeName")
+}
+
+// ControlPlayerID returns the ID of the player controlling the unit, 0 for UnitDone and UnitDied events.
+func (e UnitEvt) ControlPlayerID() int64 {
+	return e.Int("controlPlayerId")
+}
+
+// KillerPlayerID returns the ID of the player who killed the unit, and tells if it is known (UnitDied events).
+func (e UnitEvt) KillerPlayerID() (int64, bool) {
+	return e.LookupInt("killerPlayerId")
+}
+
+// Point returns the position of the unit converted to map space, and tells if the event has one.
+func (e UnitEvt) Point() (Point, bool) {
+	x, ok := e.LookupInt("x")
+	if !ok {
+		return Point{}, false
+	}
+	return TrackerPoint(x, e.Int("y")), true
+}
+
+// UpgradeEvt wraps an Upgrade tracker event.
+type UpgradeEvt struct {
+	s2prot.Event
+}
+
+// PlayerID returns the ID of the player (index in Details.Players() plus 1).
+func (e UpgradeEvt) PlayerID() int64 {
+	return e.Int("playerId")
+}
+
+// UpgradeTypeName returns the upgrade type name.
+func (e UpgradeEvt) UpgradeTypeName() string {
+	return e.Stringv("upgradeTypeName")
+}
@@ -0,0 +1,67 @@
+package rep
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/icza/s2prot"
+)
+
+func TestEventDispatcher(t *testing.T) {
+	r := &Rep{
+		GameEvts: []s2prot.Event{
+			gameEvt(10, 0, "Cmd", s2prot.Struct{"abil": s2prot.Struct{"abilLink": int64(181)}}),
+			gameEvt(20, 0, "CameraUpdate", nil),
+			gameEvt(30, 0, "Cmd", s2prot.Struct{"data": s2prot.Struct{"TargetUnit": s2prot.Struct{"tag": int64(5)}}}),
+		},
+		MessageEvts: []s2prot.Event{
+			gameEvt(20, 0, "Chat", s2prot.Struct{"string": "gl hf"}),
+		},
+		TrackerEvts: &TrackerEvts{Evts: []s2prot.Event{
+			unitEvt(0, 1, "UnitBorn", s2prot.Struct{"unitTypeName": "SCV", "x": int64(10), "y": int64(20)}),
+			unitEvt(20, 1, "UnitDied", s2prot.Struct{"killerPlayerId": int64(2)}),
+			trackerEvt(30, "Upgrade", s2prot.Struct{"playerId": int64(1), "upgradeTypeName": "Stimpack"}),
+		}},
+	}
+
+	var log []string
+	add := func(format string, a ...interface{}) { log = append(log, fmt.Sprintf(format, a...)) }
+
+	d := NewEventDispatcher()
+	d.OnAny(func(kind EvtKind, e s2prot.Event) { add("%d %s %s", e.Loop(), kind, e.Name) })
+	d.OnCmd(func(e CmdEvt) {
+		tag, ok := e.TargetUnitTag()
+		add("cmd %d %d %v", e.AbilLink(), tag, ok)
+	})
+	d.OnChat(func(e ChatEvt) { add("chat %s", e.Text()) })
+	d.OnUnitBorn(func(e UnitEvt) {
+		p, _ := e.Point()
+		add("born %d %s %v", e.Tag(), e.UnitTypeName(), p)
+	})
+	d.OnUnitDied(func(e UnitEvt) {
+		killer, ok := e.KillerPlayerID()
+		add("died %d %d %v", e.Tag(), killer, ok)
+	})
+	d.OnUpgrade(func(e UpgradeEvt) { add("upgrade %d %s", e.PlayerID(), e.UpgradeTypeName()) })
+	d.On(EvtKindGame, GmEvtCameraUpdate, func(e s2prot.Event) { add("camera") })
+	d.On(EvtKindTracker, GmEvtCameraUpdate, func(e s2prot.Event) { add("wrong kind") })
+
+	d.Dispatch(r)
+
+	exp := []string{
+		"0 tracker UnitBorn", "born 262145 SCV {10 20 0}",
+		"10 game Cmd", "cmd 181 0 false",
+		"20 game CameraUpdate", "camera",
+		"20 message Chat", "chat gl hf",
+		"20 tracker UnitDied", "died 262145 2 true",
+		"30 game Cmd", "cmd 0 5 true",
+		"30 tracker Upgrade", "upgrade 1 Stimpack",
+	}
+	if got, want := strings.Join(log, "\n"), strings.Join(exp, "\n"); got != want {
+		t.Errorf("Expected:\n%s\nGot:\n%s", want, got)
+	}
+
+	// Zero value dispatcher without handlers, replay without decoded events:
+	(&EventDispatcher{}).Dispatch(&Rep{})
+}
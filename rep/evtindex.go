@@ -0,0 +1,87 @@
+/*
+
+Loop-indexed event lookup.
+
+*/
+
+package rep
+
+import (
+	"sort"
+
+	"github.com/icza/s2prot"
+)
+
+// EventIndex is an index over events supporting efficient (binary search) queries by game loop.
+type EventIndex struct {
+	evts  []s2prot.Event // Events sorted by loop
+	loops []int64        // Loops of the events
+}
+
+// NewEventIndex creates a new EventIndex over the specified events.
+// Decoded event streams are ordered by loop; if evts is not, a sorted copy is indexed
+// (events with the same loop keep their original order).
+func NewEventIndex(evts []s2prot.Event) *EventIndex {
+	sorted := sort.SliceIsSorted(evts, func(i, j int) bool { return evts[i].Loop() < evts[j].Loop() })
+	if !sorted {
+		evts = append([]s2prot.Event(nil), evts...)
+		sort.SliceStable(evts, func(i, j int) bool { return evts[i].Loop() < evts[j].Loop() })
+	}
+
+	loops := make([]int64, len(evts))
+	for i := range evts {
+		loops[i] = evts[i].Loop()
+	}
+	return &EventIndex{evts: evts, loops: loops}
+}
+
+// Len returns the number of indexed events.
+func (x *EventIndex) Len() int {
+	return len(x.evts)
+}
+
+// Events returns the indexed events sorted by loop. The returned slice must not be modified.
+func (x *EventIndex) Events() []s2prot.Event {
+	return x.evts
+}
+
+// Search returns the index of the first event whose loop is not less than loop,
+// Len() if there is no such event.
+func (x *EventIndex) Search(loop int64) int {
+	return sort.Search(len(x.loops), func(i int) bool { return x.loops[i] >= loop })
+}
+
+// Between returns the events whose loop is in the range [from, to).
+// The returned slice is a subslice of the indexed events, it must not be modified.
+func (x *EventIndex) Between(from, to int64) []s2prot.Event {
+	i, j := x.Search(from), x.Search(to)
+	if j < i {
+		j = i
+	}
+	return x.evts[i:j]
+}
+
+// FirstAfter returns the first event at or after loop for which match returns true.
+// A nil match matches all events.
+// ok is false if there is no such event.
+func (x *EventIndex) FirstAfter(loop int64, match func(e s2prot.Event) bool) (e s2prot.Event, ok bool) {
+	for i := x.Search(loop); i < len(x.evts); i++ {
+		if match == nil || match(x.evts[i]) {
+			return x.evts[i], true
+		}
+	}
+	return
+}
+
+// MatchUserEvt returns a match function (to be used with EventIndex.FirstAfter()) matching
+// game events of the specified name (e.g. "Cmd") issued by the specified user.
+// An empty name matches all events of the user.
+func MatchUserEvt(name string, userID int64) func(e s2prot.Event) bool {
+	return func(e s2prot.Event) bool {
+		if name != "" && e.Name != name {
+			return false
+		}
+		id, ok := evtUserID(e)
+		return ok && id == userID
+	}
+}
@@ -0,0 +1,52 @@
+package rep
+
+import (
+	"testing"
+
+	"github.com/icza/s2prot"
+)
+
+func TestEventIndex(t *testing.T) {
+	evts := []s2prot.Event{
+		gameEvt(0, 0, "UserOptions", nil),
+		gameEvt(10, 0, "Cmd", nil),
+		gameEvt(10, 1, "Cmd", nil),
+		gameEvt(30, 1, "CameraUpdate", nil),
+		gameEvt(20, 1, "SelectionDelta", nil), // Out of order
+		gameEvt(40, 1, "Cmd", nil),
+	}
+
+	x := NewEventIndex(evts)
+	if x.Len() != len(evts) || evts[4].Loop() != 20 {
+		t.Errorf("Unexpected index or modified input!")
+	}
+
+	cases := []struct {
+		from, to int64
+		count    int
+	}{
+		{0, 100, 6},
+		{10, 11, 2},
+		{11, 30, 1},
+		{30, 10, 0},
+		{50, 60, 0},
+	}
+	for _, c := range cases {
+		if got := x.Between(c.from, c.to); len(got) != c.count {
+			t.Errorf("[%d, %d) Expected %d events, got: %d", c.from, c.to, c.count, len(got))
+		}
+	}
+
+	if e, ok := x.FirstAfter(11, MatchUserEvt("Cmd", 1)); !ok || e.Loop() != 40 {
+		t.Errorf("Unexpected event: %v, %v", e.Struct, ok)
+	}
+	if e, ok := x.FirstAfter(5, MatchUserEvt("", 1)); !ok || e.Loop() != 10 || e.Name != "Cmd" {
+		t.Errorf("Unexpected event: %v, %v", e.Struct, ok)
+	}
+	if _, ok := x.FirstAfter(11, MatchUserEvt("Cmd", 0)); ok {
+		t.Errorf("Expected no event!")
+	}
+	if e, ok := x.FirstAfter(25, nil); !ok || e.Name != "CameraUpdate" {
+		t.Errorf("Unexpected event: %v, %v", e.Struct, ok)
+	}
+}
@@ -0,0 +1,46 @@
+/*
+
+Event names and ids valid across builds, and the base builds supporting them.
+
+The constants and tables are generated from the protocols of the build package,
+see evtnames_gen.go and cmd/genevtnames.
+
+*/
+
+package rep
+
+//go:generate go run ../cmd/genevtnames
+
+// EvtSupport describes that an event type is supported by a range of base builds with a given event id.
+// An event type is described by multiple EvtSupports if its id changed over the builds.
+type EvtSupport struct {
+	Name         string // Name of the event type
+	ID           int    // Event id in the base builds
+	MinBaseBuild int64  // First base build supporting the event type with this id
+	MaxBaseBuild int64  // Last base build supporting the event type with this id, 0 if supported by the latest known base build
+}
+
+// Supports tells if the base build is in the range of the EvtSupport.
+// Base builds newer than the latest known one are supported if MaxBaseBuild is 0.
+func (es *EvtSupport) Supports(baseBuild int64) bool {
+	return baseBuild >= es.MinBaseBuild && (es.MaxBaseBuild == 0 || baseBuild <= es.MaxBaseBuild)
+}
+
+// EvtID returns the id of the named event type in the specified base build,
+// looked up in the specified support table (GameEvtSupports, MessageEvtSupports or TrackerEvtSupports).
+// ok is false if the event type is not supported by the base build.
+func EvtID(supports []EvtSupport, name string, baseBuild int64) (id int, ok bool) {
+	for i := range supports {
+		if es := &supports[i]; es.Name == name && es.Supports(baseBuild) {
+			return es.ID, true
+		}
+	}
+	return 0, false
+}
+
+// EvtSupported tells if the named event type is supported by the specified base build,
+// looked up in the specified support table (GameEvtSupports, MessageEvtSupports or TrackerEvtSupports).
+func EvtSupported(supports []EvtSupport, name string, baseBuild int64) bool {
+	_, ok := EvtID(supports, name, baseBuild)
+	return ok
+}
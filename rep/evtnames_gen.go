@@ -0,0 +1,400 @@
+// Code generated by genevtnames from the protocols of the build package; DO NOT EDIT.
+
+package rep
+
+// Names of the game events
+const (
+	GmEvtAICommunicate                                       = "AICommunicate"
+	GmEvtAchievementAwarded                                  = "AchievementAwarded"
+	GmEvtAddAbsoluteGameSpeed                                = "AddAbsoluteGameSpeed"
+	GmEvtAlliance                                            = "Alliance"
+	GmEvtBankFile                                            = "BankFile"
+	GmEvtBankKey                                             = "BankKey"
+	GmEvtBankSection                                         = "BankSection"
+	GmEvtBankSignature                                       = "BankSignature"
+	GmEvtBankValue                                           = "BankValue"
+	GmEvtBroadcastCheat                                      = "BroadcastCheat"
+	GmEvtCameraSave                                          = "CameraSave"
+	GmEvtCameraUpdate                                        = "CameraUpdate"
+	GmEvtCatalogModify                                       = "CatalogModify"
+	GmEvtCmd                                                 = "Cmd"
+	GmEvtCmdUpdateTargetPoint                                = "CmdUpdateTargetPoint"
+	GmEvtCmdUpdateTargetUnit                                 = "CmdUpdateTargetUnit"
+	GmEvtCommandManagerReset                                 = "CommandManagerReset"
+	GmEvtCommandManagerState                                 = "CommandManagerState"
+	GmEvtControlGroupUpdate                                  = "ControlGroupUpdate"
+	GmEvtDecrementGameTimeRemaining                          = "DecrementGameTimeRemaining"
+	GmEvtGameCheat                                           = "GameCheat"
+	GmEvtGameUserJoin                                        = "GameUserJoin"
+	GmEvtGameUserLeave                                       = "GameUserLeave"
+	GmEvtHeroTalentTreeSelected                              = "HeroTalentTreeSelected"
+	GmEvtHeroTalentTreeSelectionPanelToggled                 = "HeroTalentTreeSelectionPanelToggled"
+	GmEvtHijackReplayGame                                    = "HijackReplayGame"
+	GmEvtLagMessage                                          = "LagMessage"
+	GmEvtLoadGameDone                                        = "LoadGameDone"
+	GmEvtPeerSetSyncLoadingTime                              = "PeerSetSyncLoadingTime"
+	GmEvtPeerSetSyncPlayingTime                              = "PeerSetSyncPlayingTime"
+	GmEvtPlayerLeave                                         = "PlayerLeave"
+	GmEvtResourceRequest                                     = "ResourceRequest"
+	GmEvtResourceRequestCancel                               = "ResourceRequestCancel"
+	GmEvtResourceRequestFulfill                              = "ResourceRequestFulfill"
+	GmEvtResourceTrade                                       = "ResourceTrade"
+	GmEvtSaveGame                                            = "SaveGame"
+	GmEvtSaveGameDone                                        = "SaveGameDone"
+	GmEvtSelectionDelta                                      = "SelectionDelta"
+	GmEvtSelectionSyncCheck                                  = "SelectionSyncCheck"
+	GmEvtSetAbsoluteGameSpeed                                = "SetAbsoluteGameSpeed"
+	GmEvtSetSyncLoadingTime                                  = "SetSyncLoadingTime"
+	GmEvtSetSyncPlayingTime                                  = "SetSyncPlayingTime"
+	GmEvtTriggerAbortMission                                 = "TriggerAbortMission"
+	GmEvtTriggerAnimLengthQueryByName                        = "TriggerAnimLengthQueryByName"
+	GmEvtTriggerAnimLengthQueryByProps                       = "TriggerAnimLengthQueryByProps"
+	GmEvtTriggerAnimOffset                                   = "TriggerAnimOffset"
+	GmEvtTriggerBattleReportPanelExit                        = "TriggerBattleReportPanelExit"
+	GmEvtTriggerBattleReportPanelPlayMission                 = "TriggerBattleReportPanelPlayMission"
+	GmEvtTriggerBattleReportPanelPlayScene                   = "TriggerBattleReportPanelPlayScene"
+	GmEvtTriggerBattleReportPanelSelectionChanged            = "TriggerBattleReportPanelSelectionChanged"
+	GmEvtTriggerButtonPressed                                = "TriggerButtonPressed"
+	GmEvtTriggerCameraMove                                   = "TriggerCameraMove"
+	GmEvtTriggerChatMessage                                  = "TriggerChatMessage"
+	GmEvtTriggerCommandError                                 = "TriggerCommandError"
+	GmEvtTriggerConversationSkipped                          = "TriggerConversationSkipped"
+	GmEvtTriggerCustomDialogDismissed                        = "TriggerCustomDialogDismissed"
+	GmEvtTriggerCutsceneBookmarkFired                        = "TriggerCutsceneBookmarkFired"
+	GmEvtTriggerCutsceneConversationLine                     = "TriggerCutsceneConversationLine"
+	GmEvtTriggerCutsceneConversationLineMissing              = "TriggerCutsceneConversationLineMissing"
+	GmEvtTriggerCutsceneEndSceneFired                        = "TriggerCutsceneEndSceneFired"
+	GmEvtTriggerDialogControl                                = "TriggerDialogControl"
+	GmEvtTriggerGameCreditsFinished                          = "TriggerGameCreditsFinished"
+	GmEvtTriggerGameMenuItemSelected                         = "TriggerGameMenuItemSelected"
+	GmEvtTriggerHotkeyPressed                                = "TriggerHotkeyPressed"
+	GmEvtTriggerKeyPressed                                   = "TriggerKeyPressed"
+	GmEvtTriggerMercenaryPanelExit                           = "TriggerMercenaryPanelExit"
+	GmEvtTriggerMercenaryPanelPurchase                       = "TriggerMercenaryPanelPurchase"
+	GmEvtTriggerMercenaryPanelSelectionChanged               = "TriggerMercenaryPanelSelectionChanged"
+	GmEvtTriggerMouseClicked                                 = "TriggerMouseClicked"
+	GmEvtTriggerMouseMoved                                   = "TriggerMouseMoved"
+	GmEvtTriggerMouseWheel                                   = "TriggerMouseWheel"
+	GmEvtTriggerMovieFinished                                = "TriggerMovieFinished"
+	GmEvtTriggerMovieFunction                                = "TriggerMovieFunction"
+	GmEvtTriggerMovieStarted                                 = "TriggerMovieStarted"
+	GmEvtTriggerPing                                         = "TriggerPing"
+	GmEvtTriggerPlanetMissionLaunched                        = "TriggerPlanetMissionLaunched"
+	GmEvtTriggerPlanetMissionSelected                        = "TriggerPlanetMissionSelected"
+	GmEvtTriggerPlanetPanelBirthComplete                     = "TriggerPlanetPanelBirthComplete"
+	GmEvtTriggerPlanetPanelCanceled                          = "TriggerPlanetPanelCanceled"
+	GmEvtTriggerPlanetPanelDeathComplete                     = "TriggerPlanetPanelDeathComplete"
+	GmEvtTriggerPlanetPanelReplay                            = "TriggerPlanetPanelReplay"
+	GmEvtTriggerPortraitLoaded                               = "TriggerPortraitLoaded"
+	GmEvtTriggerProfilerLoggingFinished                      = "TriggerProfilerLoggingFinished"
+	GmEvtTriggerPurchaseExit                                 = "TriggerPurchaseExit"
+	GmEvtTriggerPurchaseMade                                 = "TriggerPurchaseMade"
+	GmEvtTriggerPurchasePanelSelectedPurchaseCategoryChanged = "TriggerPurchasePanelSelectedPurchaseCategoryChanged"
+	GmEvtTriggerPurchasePanelSelectedPurchaseItemChanged     = "TriggerPurchasePanelSelectedPurchaseItemChanged"
+	GmEvtTriggerReplySelected                                = "TriggerReplySelected"
+	GmEvtTriggerResearchPanelExit                            = "TriggerResearchPanelExit"
+	GmEvtTriggerResearchPanelPurchase                        = "TriggerResearchPanelPurchase"
+	GmEvtTriggerResearchPanelSelectionChanged                = "TriggerResearchPanelSelectionChanged"
+	GmEvtTriggerSkipped                                      = "TriggerSkipped"
+	GmEvtTriggerSoundLengthQuery                             = "TriggerSoundLengthQuery"
+	GmEvtTriggerSoundLengthSync                              = "TriggerSoundLengthSync"
+	GmEvtTriggerSoundOffset                                  = "TriggerSoundOffset"
+	GmEvtTriggerSoundtrackDone                               = "TriggerSoundtrackDone"
+	GmEvtTriggerTargetModeUpdate                             = "TriggerTargetModeUpdate"
+	GmEvtTriggerTransmissionComplete                         = "TriggerTransmissionComplete"
+	GmEvtTriggerTransmissionOffset                           = "TriggerTransmissionOffset"
+	GmEvtTriggerVictoryPanelExit                             = "TriggerVictoryPanelExit"
+	GmEvtTriggerVictoryPanelPlayMissionAgain                 = "TriggerVictoryPanelPlayMissionAgain"
+	GmEvtUnitClick                                           = "UnitClick"
+	GmEvtUnitHighlight                                       = "UnitHighlight"
+	GmEvtUserFinishedLoadingSync                             = "UserFinishedLoadingSync"
+	GmEvtUserOptions                                         = "UserOptions"
+)
+
+// Ids of the game events that have the same id in all base builds supporting them
+const (
+	GmEIdUserFinishedLoadingSync                             = 5   // UserFinishedLoadingSync game event id
+	GmEIdCameraSave                                          = 14  // CameraSave game event id [ONLY FROM BASEBUILD 24944]
+	GmEIdLoadGameDone                                        = 23  // LoadGameDone game event id [ONLY FROM BASEBUILD 24764]
+	GmEIdPlayerLeave                                         = 25  // PlayerLeave game event id [ONLY UP TO BASEBUILD 23260]
+	GmEIdCommandManagerReset                                 = 25  // CommandManagerReset game event id [ONLY FROM BASEBUILD 34784]
+	GmEIdGameCheat                                           = 26  // GameCheat game event id
+	GmEIdCmd                                                 = 27  // Cmd game event id
+	GmEIdSelectionDelta                                      = 28  // SelectionDelta game event id
+	GmEIdControlGroupUpdate                                  = 29  // ControlGroupUpdate game event id
+	GmEIdSelectionSyncCheck                                  = 30  // SelectionSyncCheck game event id
+	GmEIdResourceTrade                                       = 31  // ResourceTrade game event id
+	GmEIdTriggerChatMessage                                  = 32  // TriggerChatMessage game event id
+	GmEIdAICommunicate                                       = 33  // AICommunicate game event id
+	GmEIdSetAbsoluteGameSpeed                                = 34  // SetAbsoluteGameSpeed game event id
+	GmEIdAddAbsoluteGameSpeed                                = 35  // AddAbsoluteGameSpeed game event id
+	GmEIdTriggerPing                                         = 36  // TriggerPing game event id [ONLY FROM BASEBUILD 21995]
+	GmEIdBroadcastCheat                                      = 37  // BroadcastCheat game event id
+	GmEIdAlliance                                            = 38  // Alliance game event id
+	GmEIdUnitClick                                           = 39  // UnitClick game event id
+	GmEIdUnitHighlight                                       = 40  // UnitHighlight game event id
+	GmEIdTriggerReplySelected                                = 41  // TriggerReplySelected game event id
+	GmEIdHijackReplayGame                                    = 43  // HijackReplayGame game event id [ONLY FROM BASEBUILD 24764]
+	GmEIdTriggerSkipped                                      = 44  // TriggerSkipped game event id
+	GmEIdTriggerSoundLengthQuery                             = 45  // TriggerSoundLengthQuery game event id
+	GmEIdTriggerSoundOffset                                  = 46  // TriggerSoundOffset game event id
+	GmEIdTriggerTransmissionOffset                           = 47  // TriggerTransmissionOffset game event id
+	GmEIdTriggerTransmissionComplete                         = 48  // TriggerTransmissionComplete game event id
+	GmEIdCameraUpdate                                        = 49  // CameraUpdate game event id
+	GmEIdTriggerAbortMission                                 = 50  // TriggerAbortMission game event id
+	GmEIdTriggerPurchaseMade                                 = 51  // TriggerPurchaseMade game event id
+	GmEIdTriggerPurchaseExit                                 = 52  // TriggerPurchaseExit game event id
+	GmEIdTriggerPlanetMissionLaunched                        = 53  // TriggerPlanetMissionLaunched game event id
+	GmEIdTriggerPlanetPanelCanceled                          = 54  // TriggerPlanetPanelCanceled game event id
+	GmEIdTriggerDialogControl                                = 55  // TriggerDialogControl game event id
+	GmEIdTriggerSoundLengthSync                              = 56  // TriggerSoundLengthSync game event id
+	GmEIdTriggerConversationSkipped                          = 57  // TriggerConversationSkipped game event id
+	GmEIdTriggerMouseClicked                                 = 58  // TriggerMouseClicked game event id
+	GmEIdTriggerMouseMoved                                   = 59  // TriggerMouseMoved game event id [ONLY FROM BASEBUILD 17266]
+	GmEIdAchievementAwarded                                  = 60  // AchievementAwarded game event id [ONLY FROM BASEBUILD 21995]
+	GmEIdTriggerHotkeyPressed                                = 61  // TriggerHotkeyPressed game event id [ONLY FROM BASEBUILD 34784]
+	GmEIdTriggerTargetModeUpdate                             = 62  // TriggerTargetModeUpdate game event id [ONLY FROM BASEBUILD 24764]
+	GmEIdTriggerPlanetPanelReplay                            = 63  // TriggerPlanetPanelReplay game event id
+	GmEIdTriggerSoundtrackDone                               = 64  // TriggerSoundtrackDone game event id
+	GmEIdTriggerPlanetMissionSelected                        = 65  // TriggerPlanetMissionSelected game event id
+	GmEIdTriggerKeyPressed                                   = 66  // TriggerKeyPressed game event id
+	GmEIdTriggerMovieFunction                                = 67  // TriggerMovieFunction game event id
+	GmEIdTriggerPlanetPanelBirthComplete                     = 68  // TriggerPlanetPanelBirthComplete game event id
+	GmEIdTriggerPlanetPanelDeathComplete                     = 69  // TriggerPlanetPanelDeathComplete game event id
+	GmEIdResourceRequest                                     = 70  // ResourceRequest game event id
+	GmEIdResourceRequestFulfill                              = 71  // ResourceRequestFulfill game event id
+	GmEIdResourceRequestCancel                               = 72  // ResourceRequestCancel game event id
+	GmEIdTriggerResearchPanelExit                            = 73  // TriggerResearchPanelExit game event id
+	GmEIdTriggerResearchPanelPurchase                        = 74  // TriggerResearchPanelPurchase game event id
+	GmEIdTriggerResearchPanelSelectionChanged                = 75  // TriggerResearchPanelSelectionChanged game event id
+	GmEIdLagMessage                                          = 76  // LagMessage game event id [ONLY UP TO BASEBUILD 23260]
+	GmEIdTriggerCommandError                                 = 76  // TriggerCommandError game event id [ONLY FROM BASEBUILD 38215]
+	GmEIdTriggerMercenaryPanelExit                           = 77  // TriggerMercenaryPanelExit game event id
+	GmEIdTriggerMercenaryPanelPurchase                       = 78  // TriggerMercenaryPanelPurchase game event id
+	GmEIdTriggerMercenaryPanelSelectionChanged               = 79  // TriggerMercenaryPanelSelectionChanged game event id
+	GmEIdTriggerVictoryPanelExit                             = 80  // TriggerVictoryPanelExit game event id
+	GmEIdTriggerBattleReportPanelExit                        = 81  // TriggerBattleReportPanelExit game event id
+	GmEIdTriggerBattleReportPanelPlayMission                 = 82  // TriggerBattleReportPanelPlayMission game event id
+	GmEIdTriggerBattleReportPanelPlayScene                   = 83  // TriggerBattleReportPanelPlayScene game event id
+	GmEIdTriggerBattleReportPanelSelectionChanged            = 84  // TriggerBattleReportPanelSelectionChanged game event id
+	GmEIdTriggerVictoryPanelPlayMissionAgain                 = 85  // TriggerVictoryPanelPlayMissionAgain game event id
+	GmEIdTriggerMovieStarted                                 = 86  // TriggerMovieStarted game event id
+	GmEIdTriggerMovieFinished                                = 87  // TriggerMovieFinished game event id
+	GmEIdDecrementGameTimeRemaining                          = 88  // DecrementGameTimeRemaining game event id
+	GmEIdTriggerPortraitLoaded                               = 89  // TriggerPortraitLoaded game event id
+	GmEIdTriggerCustomDialogDismissed                        = 90  // TriggerCustomDialogDismissed game event id
+	GmEIdTriggerGameMenuItemSelected                         = 91  // TriggerGameMenuItemSelected game event id
+	GmEIdTriggerCameraMove                                   = 92  // TriggerCameraMove game event id [ONLY UP TO BASEBUILD 26490]
+	GmEIdTriggerMouseWheel                                   = 92  // TriggerMouseWheel game event id [ONLY FROM BASEBUILD 38215]
+	GmEIdTriggerPurchasePanelSelectedPurchaseItemChanged     = 93  // TriggerPurchasePanelSelectedPurchaseItemChanged game event id
+	GmEIdTriggerPurchasePanelSelectedPurchaseCategoryChanged = 94  // TriggerPurchasePanelSelectedPurchaseCategoryChanged game event id
+	GmEIdTriggerButtonPressed                                = 95  // TriggerButtonPressed game event id
+	GmEIdTriggerGameCreditsFinished                          = 96  // TriggerGameCreditsFinished game event id
+	GmEIdTriggerCutsceneBookmarkFired                        = 97  // TriggerCutsceneBookmarkFired game event id [ONLY FROM BASEBUILD 21995]
+	GmEIdTriggerCutsceneEndSceneFired                        = 98  // TriggerCutsceneEndSceneFired game event id [ONLY FROM BASEBUILD 21995]
+	GmEIdTriggerCutsceneConversationLine                     = 99  // TriggerCutsceneConversationLine game event id [ONLY FROM BASEBUILD 21995]
+	GmEIdTriggerCutsceneConversationLineMissing              = 100 // TriggerCutsceneConversationLineMissing game event id [ONLY FROM BASEBUILD 21995]
+	GmEIdGameUserLeave                                       = 101 // GameUserLeave game event id [ONLY FROM BASEBUILD 24764]
+	GmEIdGameUserJoin                                        = 102 // GameUserJoin game event id [ONLY FROM BASEBUILD 24764]
+	GmEIdCommandManagerState                                 = 103 // CommandManagerState game event id [ONLY FROM BASEBUILD 34784]
+	GmEIdCmdUpdateTargetPoint                                = 104 // CmdUpdateTargetPoint game event id [ONLY FROM BASEBUILD 34784]
+	GmEIdCmdUpdateTargetUnit                                 = 105 // CmdUpdateTargetUnit game event id [ONLY FROM BASEBUILD 34784]
+	GmEIdTriggerAnimLengthQueryByName                        = 106 // TriggerAnimLengthQueryByName game event id [ONLY FROM BASEBUILD 34784]
+	GmEIdTriggerAnimLengthQueryByProps                       = 107 // TriggerAnimLengthQueryByProps game event id [ONLY FROM BASEBUILD 34784]
+	GmEIdTriggerAnimOffset                                   = 108 // TriggerAnimOffset game event id [ONLY FROM BASEBUILD 34784]
+	GmEIdCatalogModify                                       = 109 // CatalogModify game event id [ONLY FROM BASEBUILD 34784]
+	GmEIdHeroTalentTreeSelected                              = 110 // HeroTalentTreeSelected game event id [ONLY FROM BASEBUILD 34784]
+	GmEIdTriggerProfilerLoggingFinished                      = 111 // TriggerProfilerLoggingFinished game event id [ONLY FROM BASEBUILD 34784]
+	GmEIdHeroTalentTreeSelectionPanelToggled                 = 112 // HeroTalentTreeSelectionPanelToggled game event id [ONLY FROM BASEBUILD 34784]
+	GmEIdSetSyncLoadingTime                                  = 116 // SetSyncLoadingTime game event id [ONLY FROM BASEBUILD 65895]
+	GmEIdSetSyncPlayingTime                                  = 117 // SetSyncPlayingTime game event id [ONLY FROM BASEBUILD 65895]
+	GmEIdPeerSetSyncLoadingTime                              = 118 // PeerSetSyncLoadingTime game event id [ONLY FROM BASEBUILD 65895]
+	GmEIdPeerSetSyncPlayingTime                              = 119 // PeerSetSyncPlayingTime game event id [ONLY FROM BASEBUILD 65895]
+)
+
+// GameEvtSupports describes the base builds supporting the game events and their ids.
+var GameEvtSupports = []EvtSupport{
+	{GmEvtUserFinishedLoadingSync, 5, 15405, 0},
+	{GmEvtBankFile, 7, 15405, 23260},
+	{GmEvtUserOptions, 7, 24764, 0},
+	{GmEvtBankSection, 8, 15405, 23260},
+	{GmEvtBankKey, 9, 15405, 23260},
+	{GmEvtBankFile, 9, 24764, 0},
+	{GmEvtBankValue, 10, 15405, 23260},
+	{GmEvtBankSection, 10, 24764, 0},
+	{GmEvtUserOptions, 11, 15405, 16939},
+	{GmEvtBankSignature, 11, 17266, 23260},
+	{GmEvtBankKey, 11, 24764, 0},
+	{GmEvtUserOptions, 12, 17266, 23260},
+	{GmEvtBankValue, 12, 24764, 0},
+	{GmEvtBankSignature, 13, 24764, 0},
+	{GmEvtCameraSave, 14, 24944, 0},
+	{GmEvtSaveGame, 21, 24764, 0},
+	{GmEvtSaveGame, 22, 15405, 23260},
+	{GmEvtSaveGameDone, 22, 24764, 0},
+	{GmEvtSaveGameDone, 23, 15405, 23260},
+	{GmEvtLoadGameDone, 23, 24764, 0},
+	{GmEvtPlayerLeave, 25, 15405, 23260},
+	{GmEvtCommandManagerReset, 25, 34784, 0},
+	{GmEvtGameCheat, 26, 15405, 0},
+	{GmEvtCmd, 27, 15405, 0},
+	{GmEvtSelectionDelta, 28, 15405, 0},
+	{GmEvtControlGroupUpdate, 29, 15405, 0},
+	{GmEvtSelectionSyncCheck, 30, 15405, 0},
+	{GmEvtResourceTrade, 31, 15405, 0},
+	{GmEvtTriggerChatMessage, 32, 15405, 0},
+	{GmEvtAICommunicate, 33, 15405, 0},
+	{GmEvtSetAbsoluteGameSpeed, 34, 15405, 0},
+	{GmEvtAddAbsoluteGameSpeed, 35, 15405, 0},
+	{GmEvtTriggerPing, 36, 21995, 0},
+	{GmEvtBroadcastCheat, 37, 15405, 0},
+	{GmEvtAlliance, 38, 15405, 0},
+	{GmEvtUnitClick, 39, 15405, 0},
+	{GmEvtUnitHighlight, 40, 15405, 0},
+	{GmEvtTriggerReplySelected, 41, 15405, 0},
+	{GmEvtHijackReplayGame, 43, 24764, 0},
+	{GmEvtTriggerSkipped, 44, 15405, 0},
+	{GmEvtTriggerSoundLengthQuery, 45, 15405, 0},
+	{GmEvtTriggerSoundOffset, 46, 15405, 0},
+	{GmEvtTriggerTransmissionOffset, 47, 15405, 0},
+	{GmEvtTriggerTransmissionComplete, 48, 15405, 0},
+	{GmEvtCameraUpdate, 49, 15405, 0},
+	{GmEvtTriggerAbortMission, 50, 15405, 0},
+	{GmEvtTriggerPurchaseMade, 51, 15405, 0},
+	{GmEvtTriggerPurchaseExit, 52, 15405, 0},
+	{GmEvtTriggerPlanetMissionLaunched, 53, 15405, 0},
+	{GmEvtTriggerPlanetPanelCanceled, 54, 15405, 0},
+	{GmEvtTriggerDialogControl, 55, 15405, 0},
+	{GmEvtTriggerSoundLengthSync, 56, 15405, 0},
+	{GmEvtTriggerConversationSkipped, 57, 15405, 0},
+	{GmEvtTriggerMouseClicked, 58, 15405, 0},
+	{GmEvtTriggerMouseMoved, 59, 17266, 0},
+	{GmEvtAchievementAwarded, 60, 21995, 0},
+	{GmEvtTriggerHotkeyPressed, 61, 34784, 0},
+	{GmEvtTriggerTargetModeUpdate, 62, 24764, 0},
+	{GmEvtTriggerPlanetPanelReplay, 63, 15405, 0},
+	{GmEvtTriggerSoundtrackDone, 64, 15405, 0},
+	{GmEvtTriggerPlanetMissionSelected, 65, 15405, 0},
+	{GmEvtTriggerKeyPressed, 66, 15405, 0},
+	{GmEvtTriggerMovieFunction, 67, 15405, 0},
+	{GmEvtTriggerPlanetPanelBirthComplete, 68, 15405, 0},
+	{GmEvtTriggerPlanetPanelDeathComplete, 69, 15405, 0},
+	{GmEvtResourceRequest, 70, 15405, 0},
+	{GmEvtResourceRequestFulfill, 71, 15405, 0},
+	{GmEvtResourceRequestCancel, 72, 15405, 0},
+	{GmEvtTriggerResearchPanelExit, 73, 15405, 0},
+	{GmEvtTriggerResearchPanelPurchase, 74, 15405, 0},
+	{GmEvtTriggerResearchPanelSelectionChanged, 75, 15405, 0},
+	{GmEvtLagMessage, 76, 15405, 23260},
+	{GmEvtTriggerCommandError, 76, 38215, 0},
+	{GmEvtTriggerMercenaryPanelExit, 77, 15405, 0},
+	{GmEvtTriggerMercenaryPanelPurchase, 78, 15405, 0},
+	{GmEvtTriggerMercenaryPanelSelectionChanged, 79, 15405, 0},
+	{GmEvtTriggerVictoryPanelExit, 80, 15405, 0},
+	{GmEvtTriggerBattleReportPanelExit, 81, 15405, 0},
+	{GmEvtTriggerBattleReportPanelPlayMission, 82, 15405, 0},
+	{GmEvtTriggerBattleReportPanelPlayScene, 83, 15405, 0},
+	{GmEvtTriggerBattleReportPanelSelectionChanged, 84, 15405, 0},
+	{GmEvtTriggerVictoryPanelPlayMissionAgain, 85, 15405, 0},
+	{GmEvtTriggerMovieStarted, 86, 15405, 0},
+	{GmEvtTriggerMovieFinished, 87, 15405, 0},
+	{GmEvtDecrementGameTimeRemaining, 88, 15405, 0},
+	{GmEvtTriggerPortraitLoaded, 89, 15405, 0},
+	{GmEvtTriggerCustomDialogDismissed, 90, 15405, 0},
+	{GmEvtTriggerGameMenuItemSelected, 91, 15405, 0},
+	{GmEvtTriggerCameraMove, 92, 15405, 26490},
+	{GmEvtTriggerMouseWheel, 92, 38215, 0},
+	{GmEvtTriggerPurchasePanelSelectedPurchaseItemChanged, 93, 15405, 0},
+	{GmEvtTriggerPurchasePanelSelectedPurchaseCategoryChanged, 94, 15405, 0},
+	{GmEvtTriggerButtonPressed, 95, 15405, 0},
+	{GmEvtTriggerGameCreditsFinished, 96, 15405, 0},
+	{GmEvtTriggerCutsceneBookmarkFired, 97, 21995, 0},
+	{GmEvtTriggerCutsceneEndSceneFired, 98, 21995, 0},
+	{GmEvtTriggerCutsceneConversationLine, 99, 21995, 0},
+	{GmEvtTriggerCutsceneConversationLineMissing, 100, 21995, 0},
+	{GmEvtGameUserLeave, 101, 24764, 0},
+	{GmEvtGameUserJoin, 102, 24764, 0},
+	{GmEvtCommandManagerState, 103, 34784, 0},
+	{GmEvtCmdUpdateTargetPoint, 104, 34784, 0},
+	{GmEvtCmdUpdateTargetUnit, 105, 34784, 0},
+	{GmEvtTriggerAnimLengthQueryByName, 106, 34784, 0},
+	{GmEvtTriggerAnimLengthQueryByProps, 107, 34784, 0},
+	{GmEvtTriggerAnimOffset, 108, 34784, 0},
+	{GmEvtCatalogModify, 109, 34784, 0},
+	{GmEvtHeroTalentTreeSelected, 110, 34784, 0},
+	{GmEvtTriggerProfilerLoggingFinished, 111, 34784, 0},
+	{GmEvtHeroTalentTreeSelectionPanelToggled, 112, 34784, 0},
+	{GmEvtSetSyncLoadingTime, 116, 65895, 0},
+	{GmEvtSetSyncPlayingTime, 117, 65895, 0},
+	{GmEvtPeerSetSyncLoadingTime, 118, 65895, 0},
+	{GmEvtPeerSetSyncPlayingTime, 119, 65895, 0},
+}
+
+// Names of the message events
+const (
+	MsgEvtChat            = "Chat"
+	MsgEvtLoadingProgress = "LoadingProgress"
+	MsgEvtPing            = "Ping"
+	MsgEvtReconnectNotify = "ReconnectNotify"
+	MsgEvtServerPing      = "ServerPing"
+)
+
+// Ids of the message events that have the same id in all base builds supporting them
+const (
+	MsgEIdChat            = 0 // Chat message event id
+	MsgEIdPing            = 1 // Ping message event id
+	MsgEIdLoadingProgress = 2 // LoadingProgress message event id
+	MsgEIdServerPing      = 3 // ServerPing message event id
+	MsgEIdReconnectNotify = 4 // ReconnectNotify message event id [ONLY FROM BASEBUILD 34784]
+)
+
+// MessageEvtSupports describes the base builds supporting the message events and their ids.
+var MessageEvtSupports = []EvtSupport{
+	{MsgEvtChat, 0, 15405, 0},
+	{MsgEvtPing, 1, 15405, 0},
+	{MsgEvtLoadingProgress, 2, 15405, 0},
+	{MsgEvtServerPing, 3, 15405, 0},
+	{MsgEvtReconnectNotify, 4, 34784, 0},
+}
+
+// Names of the tracker events
+const (
+	TrEvtPlayerSetup     = "PlayerSetup"
+	TrEvtPlayerStats     = "PlayerStats"
+	TrEvtUnitBorn        = "UnitBorn"
+	TrEvtUnitDied        = "UnitDied"
+	TrEvtUnitDone        = "UnitDone"
+	TrEvtUnitInit        = "UnitInit"
+	TrEvtUnitOwnerChange = "UnitOwnerChange"
+	TrEvtUnitPositions   = "UnitPositions"
+	TrEvtUnitTypeChange  = "UnitTypeChange"
+	TrEvtUpgrade         = "Upgrade"
+)
+
+// Ids of the tracker events that have the same id in all base builds supporting them
+const (
+	TrEIdPlayerStats     = 0 // PlayerStats tracker event id [ONLY FROM BASEBUILD 24944]
+	TrEIdUnitBorn        = 1 // UnitBorn tracker event id [ONLY FROM BASEBUILD 24944]
+	TrEIdUnitDied        = 2 // UnitDied tracker event id [ONLY FROM BASEBUILD 24944]
+	TrEIdUnitOwnerChange = 3 // UnitOwnerChange tracker event id [ONLY FROM BASEBUILD 24944]
+	TrEIdUnitTypeChange  = 4 // UnitTypeChange tracker event id [ONLY FROM BASEBUILD 24944]
+	TrEIdUpgrade         = 5 // Upgrade tracker event id [ONLY FROM BASEBUILD 24944]
+	TrEIdUnitInit        = 6 // UnitInit tracker event id [ONLY FROM BASEBUILD 24944]
+	TrEIdUnitDone        = 7 // UnitDone tracker event id [ONLY FROM BASEBUILD 24944]
+	TrEIdUnitPositions   = 8 // UnitPositions tracker event id [ONLY FROM BASEBUILD 24944]
+	TrEIdPlayerSetup     = 9 // PlayerSetup tracker event id [ONLY FROM BASEBUILD 27950]
+)
+
+// TrackerEvtSupports describes the base builds supporting the tracker events and their ids.
+var TrackerEvtSupports = []EvtSupport{
+	{TrEvtPlayerStats, 0, 24944, 0},
+	{TrEvtUnitBorn, 1, 24944, 0},
+	{TrEvtUnitDied, 2, 24944, 0},
+	{TrEvtUnitOwnerChange, 3, 24944, 0},
+	{TrEvtUnitTypeChange, 4, 24944, 0},
+	{TrEvtUpgrade, 5, 24944, 0},
+	{TrEvtUnitInit, 6, 24944, 0},
+	{TrEvtUnitDone, 7, 24944, 0},
+	{TrEvtUnitPositions, 8, 24944, 0},
+	{TrEvtPlayerSetup, 9, 27950, 0},
+}
@@ -0,0 +1,71 @@
+package rep
+
+import (
+	"testing"
+
+	"github.com/icza/s2prot"
+	"github.com/icza/s2prot/build"
+)
+
+// TestEvtSupports checks if the generated support tables are up-to-date with the protocols of the build package.
+func TestEvtSupports(t *testing.T) {
+	var baseBuilds []int
+	for bb := range build.Builds {
+		baseBuilds = append(baseBuilds, bb)
+	}
+	for bb := range build.Duplicates {
+		baseBuilds = append(baseBuilds, bb)
+	}
+
+	for _, bb := range baseBuilds {
+		p := s2prot.GetProtocol(bb)
+		for _, c := range []struct {
+			supports []EvtSupport
+			evtTypes []s2prot.EvtType
+		}{
+			{GameEvtSupports, p.GameEvtTypes()},
+			{MessageEvtSupports, p.MessageEvtTypes()},
+			{TrackerEvtSupports, p.TrackerEvtTypes()},
+		} {
+			for id, et := range c.evtTypes {
+				if et.Name == "" {
+					continue
+				}
+				if got, ok := EvtID(c.supports, et.Name, int64(bb)); !ok || got != id {
+					t.Errorf("[%d] Expected id %d for %s, got: %d, %v (run go generate!)", bb, id, et.Name, got, ok)
+				}
+			}
+		}
+	}
+}
+
+func TestEvtID(t *testing.T) {
+	cases := []struct {
+		supports  []EvtSupport
+		name      string
+		baseBuild int64
+		id        int
+		ok        bool
+	}{
+		{GameEvtSupports, GmEvtPlayerLeave, 15405, GmEIdPlayerLeave, true},
+		{GameEvtSupports, GmEvtPlayerLeave, 80949, 0, false},
+		{GameEvtSupports, GmEvtGameUserLeave, 80949, GmEIdGameUserLeave, true},
+		{GameEvtSupports, GmEvtGameUserLeave, 99999, GmEIdGameUserLeave, true}, // Newer than the latest known
+		{GameEvtSupports, GmEvtUserOptions, 15405, 11, true},
+		{GameEvtSupports, GmEvtUserOptions, 20000, 12, true},
+		{GameEvtSupports, GmEvtUserOptions, 80949, 7, true},
+		{MessageEvtSupports, MsgEvtChat, 80949, MsgEIdChat, true},
+		{TrackerEvtSupports, TrEvtPlayerSetup, 24944, 0, false},
+		{TrackerEvtSupports, TrEvtPlayerSetup, 27950, TrEIdPlayerSetup, true},
+		{TrackerEvtSupports, "Unknown", 80949, 0, false},
+	}
+	for _, c := range cases {
+		id, ok := EvtID(c.supports, c.name, c.baseBuild)
+		if id != c.id || ok != c.ok {
+			t.Errorf("[%s, %d] Expected: %d, %v, got: %d, %v", c.name, c.baseBuild, c.id, c.ok, id, ok)
+		}
+		if ok := EvtSupported(c.supports, c.name, c.baseBuild); ok != c.ok {
+			t.Errorf("[%s, %d] Expected: %v, got: %v", c.name, c.baseBuild, c.ok, ok)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+/*
+
+Typed access to points (target points, camera targets) of game and message events.
+
+*/
+
+package rep
+
+import "github.com/icza/s2prot"
+
+// EvtPoint returns the point of the event converted to map space, and tells if the event has one.
+//
+// Supported events and the points returned:
+//
+//	Cmd                   the target point, or the snapshot point of the target unit
+//	CmdUpdateTargetPoint  the target point
+//	CmdUpdateTargetUnit   the snapshot point of the target unit
+//	CameraUpdate          the camera target (missing in some events)
+//	TriggerPing           the pinged point
+//	Ping (message event)  the pinged point
+//
+// Z coordinate (height) is only present for target points and snapshot points.
+func EvtPoint(e s2prot.Event) (Point, bool) {
+	switch e.Name {
+	case "Cmd":
+		data, _ := e.Value("data").(s2prot.Struct)
+		return cmdDataPoint(data)
+	case "CmdUpdateTargetPoint":
+		return targetPoint(e.Value("target"))
+	case "CmdUpdateTargetUnit":
+		return targetPoint(e.Value("target", "snapshotPoint"))
+	case "CameraUpdate":
+		if s, ok := e.Value("target").(s2prot.Struct); ok {
+			return CameraPoint(s.Int("x"), s.Int("y")), true
+		}
+	case "TriggerPing", "Ping":
+		return targetPoint(e.Value("point"))
+	}
+	return Point{}, false
+}
+
+// cmdDataPoint returns the target point of the "data" of a Cmd event, or the snapshot point of the target unit.
+func cmdDataPoint(data s2prot.Struct) (Point, bool) {
+	if p, ok := targetPoint(data.Value("TargetPoint")); ok {
+		return p, true
+	}
+	return targetPoint(data.Value("TargetUnit", "snapshotPoint"))
+}
+
+// targetPoint converts a fixed-point target point Struct (having x, y and optionally z fields) to map space.
+func targetPoint(v interface{}) (Point, bool) {
+	s, ok := v.(s2prot.Struct)
+	if !ok {
+		return Point{}, false
+	}
+	return TargetPoint(s.Int("x"), s.Int("y"), s.Int("z")), true
+}
@@ -0,0 +1,204 @@
+/*
+Package features implements extracting fixed-interval per-player feature matrices from replays,
+to be used for training machine learning models (e.g. win prediction or build classification)
+on replay corpora.
+
+Features are sampled in fixed steps of real time; a matrix has a row for each sample
+and a column for each feature (see Names), stored in row-major order.
+Matrices can be written as NumPy-compatible .npz files.
+
+Example:
+
+	f, err := features.Extract(r, 10*time.Second)
+	if err != nil {
+		// Handle error
+	}
+	out, err := os.Create("features.npz")
+	if err != nil {
+		// Handle error
+	}
+	defer out.Close()
+	if err := f.WriteNPZ(out); err != nil {
+		// Handle error
+	}
+*/
+package features
+
+import (
+	"errors"
+	"time"
+
+	"github.com/icza/s2prot"
+	"github.com/icza/s2prot/rep"
+)
+
+var (
+	// ErrNoTrackerEvts is returned if the replay has no tracker events.
+	ErrNoTrackerEvts = errors.New("Tracker events are not available")
+
+	// ErrInvalidStep is returned if the sampling step is not positive.
+	ErrInvalidStep = errors.New("Invalid step")
+)
+
+// Columns of the features in the matrices.
+const (
+	ColMineralsCurrent = iota
+	ColVespeneCurrent
+	ColMineralsCollectionRate
+	ColVespeneCollectionRate
+	ColWorkersActive
+	ColFoodUsed
+	ColFoodMade
+	ColArmyMinerals
+	ColArmyVespene
+	ColArmySupply
+	ColUpgrades
+	ColAPM
+	ColCameraX
+	ColCameraY
+)
+
+// Names of the features, index is the column in the matrices.
+var Names = []string{
+	ColMineralsCurrent:        "mineralsCurrent",
+	ColVespeneCurrent:         "vespeneCurrent",
+	ColMineralsCollectionRate: "mineralsCollectionRate",
+	ColVespeneCollectionRate:  "vespeneCollectionRate",
+	ColWorkersActive:          "workersActive",
+	ColFoodUsed:               "foodUsed",
+	ColFoodMade:               "foodMade",
+	ColArmyMinerals:           "armyMinerals",
+	ColArmyVespene:            "armyVespene",
+	ColArmySupply:             "armySupply",
+	ColUpgrades:               "upgrades",
+	ColAPM:                    "apm",
+	ColCameraX:                "cameraX",
+	ColCameraY:                "cameraY",
+}
+
+// Features holds the feature matrices of the players of a replay.
+type Features struct {
+	Step  time.Duration   // Sampling step (real time)
+	Times []time.Duration // Real (wall clock) times of the samples, index is the row in the matrices
+	Loops []int64         // Game loops of the samples, index is the row in the matrices
+
+	// Players holds the feature matrices of the players, index is the same as in Details.Players().
+	// A matrix has len(Times) rows and len(Names) columns, stored in row-major order.
+	Players [][]float32
+}
+
+// Rows returns the number of rows (samples) of the matrices.
+func (f *Features) Rows() int {
+	return len(f.Times)
+}
+
+// Cols returns the number of columns (features) of the matrices.
+func (f *Features) Cols() int {
+	return len(Names)
+}
+
+// Extract extracts the feature matrices of the players of a replay, sampled in the given steps of real time.
+// Samples are taken at step, 2*step, ... and at the end of the game.
+// Tracker events are required; APM and camera features are 0 if game events were not decoded
+// and for non-human players.
+func Extract(r *rep.Rep, step time.Duration) (*Features, error) {
+	if step <= 0 {
+		return nil, ErrInvalidStep
+	}
+	if r.TrackerEvts == nil {
+		return nil, ErrNoTrackerEvts
+	}
+
+	f := &Features{Step: step}
+	loopsPerSec := rep.LoopsPerSecond(r.Details.GameSpeed())
+	duration := r.RealDuration()
+	for t := step; ; t += step {
+		if t > duration {
+			if t-step >= duration {
+				break
+			}
+			t = duration // Last sample at the end of the game
+		}
+		f.Times = append(f.Times, t)
+		f.Loops = append(f.Loops, int64(t.Seconds()*loopsPerSec))
+	}
+
+	players := r.Details.Players()
+	f.Players = make([][]float32, len(players))
+	cols := f.Cols()
+	for i := range players {
+		f.Players[i] = make([]float32, f.Rows()*cols)
+	}
+
+	// Army features:
+	for row, loop := range f.Loops {
+		for i, ac := range r.ArmyComposition(loop) {
+			m := f.Players[i][row*cols:]
+			m[ColArmyMinerals], m[ColArmyVespene], m[ColArmySupply] = float32(ac.Minerals), float32(ac.Gas), float32(ac.Supply)
+		}
+	}
+
+	for i := range players {
+		m := f.Players[i]
+		f.extractStats(m, r.PlayerTrackerEvts()[int64(i+1)])
+		f.extractActions(m, r.GameEvtsOf(i))
+		for row, p := range r.APMTimeline(i, rep.DefaultAPMWindow, step) {
+			if row < f.Rows() {
+				m[row*cols+ColAPM] = float32(p.APM)
+			}
+		}
+	}
+
+	return f, nil
+}
+
+// extractStats extracts the player stats and upgrade features from the tracker events of a player.
+func (f *Features) extractStats(m []float32, evts []s2prot.Event) {
+	cols := f.Cols()
+	var stats s2prot.Struct // Last player stats
+	upgrades := 0
+	i := 0
+	for row, loop := range f.Loops {
+		for ; i < len(evts) && evts[i].Loop() <= loop; i++ {
+			e := evts[i]
+			switch e.Name {
+			case "PlayerStats":
+				stats = e.Structv("stats")
+			case "Upgrade":
+				// Upgrades at loop 0 are cosmetic (e.g. sprays and rewards)
+				if e.Loop() > 0 {
+					upgrades += int(e.Int("count"))
+				}
+			}
+		}
+		v := m[row*cols:]
+		if stats != nil {
+			v[ColMineralsCurrent] = float32(stats.Int("scoreValueMineralsCurrent"))
+			v[ColVespeneCurrent] = float32(stats.Int("scoreValueVespeneCurrent"))
+			v[ColMineralsCollectionRate] = float32(stats.Int("scoreValueMineralsCollectionRate"))
+			v[ColVespeneCollectionRate] = float32(stats.Int("scoreValueVespeneCollectionRate"))
+			v[ColWorkersActive] = float32(stats.Int("scoreValueWorkersActiveCount"))
+			v[ColFoodUsed] = float32(stats.Int("scoreValueFoodUsed")) / rep.FoodScale
+			v[ColFoodMade] = float32(stats.Int("scoreValueFoodMade")) / rep.FoodScale
+		}
+		v[ColUpgrades] = float32(upgrades)
+	}
+}
+
+// extractActions extracts the camera position features from the game events of a player.
+func (f *Features) extractActions(m []float32, evts []s2prot.Event) {
+	cols := f.Cols()
+	var camera rep.Point
+	i := 0
+	for row, loop := range f.Loops {
+		for ; i < len(evts) && evts[i].Loop() <= loop; i++ {
+			if evts[i].Name != "CameraUpdate" {
+				continue
+			}
+			if p, ok := rep.EvtPoint(evts[i]); ok {
+				camera = p
+			}
+		}
+		m[row*cols+ColCameraX], m[row*cols+ColCameraY] = float32(camera.X), float32(camera.Y)
+	}
+}
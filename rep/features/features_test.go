@@ -0,0 +1,122 @@
+package features
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/binary"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/icza/s2prot"
+	"github.com/icza/s2prot/rep"
+	"github.com/icza/s2prot/rep/reptest"
+)
+
+// newTestRep returns a synthetic replay of 1 player, 100 real seconds long (on Faster).
+func newTestRep(t *testing.T) *rep.Rep {
+	rt := reptest.New(80949)
+	rt.Loops = 2240
+	rt.Players = rt.Players[:1]
+	rt.TrackerEvt(0, "Upgrade", s2prot.Struct{"playerId": int64(1), "count": int64(1), "upgradeTypeName": "SprayTerran"})
+	rt.TrackerEvt(160, "PlayerStats", s2prot.Struct{"playerId": int64(1), "stats": s2prot.Struct{
+		"scoreValueMineralsCurrent": int64(50), "scoreValueFoodUsed": int64(12 * rep.FoodScale), "scoreValueFoodMade": int64(15 * rep.FoodScale)}})
+	rt.TrackerEvt(500, "UnitBorn", s2prot.Struct{"unitTagIndex": int64(1), "unitTagRecycle": int64(1), "unitTypeName": "Marine", "controlPlayerId": int64(1)})
+	rt.TrackerEvt(1000, "Upgrade", s2prot.Struct{"playerId": int64(1), "count": int64(1), "upgradeTypeName": "Stimpack"})
+
+	r, err := rt.Rep()
+	if err != nil {
+		t.Fatalf("Failed to create replay: %v", err)
+	}
+	t.Cleanup(func() { r.Close() })
+	return r
+}
+
+func TestExtract(t *testing.T) {
+	r := newTestRep(t)
+	if _, err := Extract(r, 0); err != ErrInvalidStep {
+		t.Errorf("Expected: %v, got: %v", ErrInvalidStep, err)
+	}
+
+	f, err := Extract(r, 30*time.Second)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if f.Rows() != 4 || f.Times[3] != 100*time.Second || f.Loops[0] != 672 || len(f.Players) != 1 {
+		t.Fatalf("Unexpected samples: %v, %v", f.Times, f.Loops)
+	}
+
+	m := f.Players[0]
+	row := func(i int) []float32 { return m[i*f.Cols() : (i+1)*f.Cols()] }
+	if v := row(0); v[ColMineralsCurrent] != 50 || v[ColFoodUsed] != 12 || v[ColFoodMade] != 15 ||
+		v[ColArmyMinerals] != 50 || v[ColArmySupply] != 1 || v[ColUpgrades] != 0 {
+		t.Errorf("Unexpected row 0: %v", v)
+	}
+	if v := row(1); v[ColUpgrades] != 1 || v[ColAPM] != 0 {
+		t.Errorf("Unexpected row 1: %v", v)
+	}
+
+	r.TrackerEvts = nil
+	if _, err := Extract(r, time.Second); err != ErrNoTrackerEvts {
+		t.Errorf("Expected: %v, got: %v", ErrNoTrackerEvts, err)
+	}
+}
+
+func TestWriteNPZ(t *testing.T) {
+	f, err := Extract(newTestRep(t), 30*time.Second)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	buf := &bytes.Buffer{}
+	if err := f.WriteNPZ(buf); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	exp := map[string]struct {
+		header   string
+		dataSize int
+	}{
+		"names.npy":   {"{'descr': '<U22', 'fortran_order': False, 'shape': (14,), }", 14 * 22 * 4},
+		"times.npy":   {"{'descr': '<f8', 'fortran_order': False, 'shape': (4,), }", 4 * 8},
+		"loops.npy":   {"{'descr': '<i8', 'fortran_order': False, 'shape': (4,), }", 4 * 8},
+		"player0.npy": {"{'descr': '<f4', 'fortran_order': False, 'shape': (4, 14), }", 4 * 14 * 4},
+	}
+	if len(zr.File) != len(exp) {
+		t.Errorf("Expected %d files, got: %d", len(exp), len(zr.File))
+	}
+	for _, zf := range zr.File {
+		e, ok := exp[zf.Name]
+		if !ok {
+			t.Errorf("Unexpected file: %s", zf.Name)
+			continue
+		}
+		rc, err := zf.Open()
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		data, err := ioutil.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if string(data[:8]) != npyMagic {
+			t.Errorf("[%s] Invalid magic", zf.Name)
+			continue
+		}
+		hl := int(binary.LittleEndian.Uint16(data[8:]))
+		if (10+hl)%64 != 0 {
+			t.Errorf("[%s] Data is not aligned, header length: %d", zf.Name, hl)
+		}
+		if header := strings.TrimRight(string(data[10:10+hl]), " \n"); header != e.header {
+			t.Errorf("[%s] Expected header: %s, got: %s", zf.Name, e.header, header)
+		}
+		if size := len(data) - 10 - hl; size != e.dataSize {
+			t.Errorf("[%s] Expected data size: %d, got: %d", zf.Name, e.dataSize, size)
+		}
+	}
+}
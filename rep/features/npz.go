@@ -0,0 +1,124 @@
+/*
+
+Writing NumPy-compatible .npy and .npz files.
+
+*/
+
+package features
+
+import (
+	"archive/zip"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// npyArray is an array to be written as a .npy file.
+type npyArray struct {
+	name  string      // Name of the array (file name without extension)
+	descr string      // NumPy type descriptor, not used for string arrays
+	shape []int       // Shape of the array
+	data  interface{} // Slice of fixed-size values or []string
+}
+
+// WriteNPZ writes the features as a NumPy-compatible .npz archive (as written by numpy.savez) with the following arrays:
+//
+//	names      feature names, shape (cols,)
+//	times      real times of the samples in seconds, float64, shape (rows,)
+//	loops      game loops of the samples, int64, shape (rows,)
+//	player<i>  feature matrix of the i-th player, float32, shape (rows, cols)
+func (f *Features) WriteNPZ(w io.Writer) error {
+	zw := zip.NewWriter(w)
+
+	times := make([]float64, len(f.Times))
+	for i, t := range f.Times {
+		times[i] = t.Seconds()
+	}
+
+	arrays := []npyArray{
+		{"names", "", []int{len(Names)}, Names},
+		{"times", "<f8", []int{len(times)}, times},
+		{"loops", "<i8", []int{len(f.Loops)}, f.Loops},
+	}
+	for i, m := range f.Players {
+		arrays = append(arrays, npyArray{fmt.Sprint("player", i), "<f4", []int{f.Rows(), f.Cols()}, m})
+	}
+
+	for _, a := range arrays {
+		aw, err := zw.CreateHeader(&zip.FileHeader{Name: a.name + ".npy", Method: zip.Store})
+		if err != nil {
+			return err
+		}
+		if s, ok := a.data.([]string); ok {
+			err = writeNPYStrings(aw, s)
+		} else {
+			err = writeNPY(aw, a.descr, a.shape, a.data)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return zw.Close()
+}
+
+// npyMagic is the magic string of .npy files followed by the format version 1.0.
+const npyMagic = "\x93NUMPY\x01\x00"
+
+// writeNPY writes a .npy file. descr is the NumPy type descriptor of the data,
+// data must be a slice of fixed-size values matching the descriptor.
+func writeNPY(w io.Writer, descr string, shape []int, data interface{}) error {
+	if err := writeNPYHeader(w, descr, shape); err != nil {
+		return err
+	}
+	return binary.Write(w, binary.LittleEndian, data)
+}
+
+// writeNPYStrings writes a 1-dimensional .npy file of unicode strings.
+func writeNPYStrings(w io.Writer, ss []string) error {
+	maxLen := 1
+	for _, s := range ss {
+		if n := len([]rune(s)); n > maxLen {
+			maxLen = n
+		}
+	}
+	if err := writeNPYHeader(w, fmt.Sprintf("<U%d", maxLen), []int{len(ss)}); err != nil {
+		return err
+	}
+
+	data := make([]int32, 0, len(ss)*maxLen) // UTF-32, zero padded
+	for _, s := range ss {
+		rs := []rune(s)
+		data = append(data, rs...)
+		data = append(data, make([]int32, maxLen-len(rs))...)
+	}
+	return binary.Write(w, binary.LittleEndian, data)
+}
+
+// writeNPYHeader writes the magic, the version and the header of a .npy file.
+// The header is padded so that the data starts at a multiple of 64 bytes.
+func writeNPYHeader(w io.Writer, descr string, shape []int) error {
+	dims := make([]string, len(shape))
+	for i, d := range shape {
+		dims[i] = fmt.Sprint(d)
+	}
+	shapeStr := strings.Join(dims, ", ")
+	if len(shape) == 1 {
+		shapeStr += ","
+	}
+	header := fmt.Sprintf("{'descr': '%s', 'fortran_order': False, 'shape': (%s), }", descr, shapeStr)
+
+	// magic + version + header length (2 bytes) + header + newline
+	total := len(npyMagic) + 2 + len(header) + 1
+	header += strings.Repeat(" ", (64-total%64)%64) + "\n"
+
+	if _, err := io.WriteString(w, npyMagic); err != nil {
+		return err
+	}
+	if err := binary.Write(w, binary.LittleEndian, uint16(len(header))); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, header)
+	return err
+}
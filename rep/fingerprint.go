@@ -0,0 +1,39 @@
+/*
+
+Fingerprint identifying the game of a replay.
+
+*/
+
+package rep
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+)
+
+// Fingerprint returns a fingerprint identifying the game of the replay, as a hex encoded SHA-256 hash.
+//
+// Replays of the same game have the same fingerprint, even if they are saved by different clients
+// or at different times (e.g. a copy saved when a player left the game). The fingerprint is computed
+// from the random value of the game (shared by all participants), the map file sync checksum and the
+// names and toons of the players, so it only requires the init data and details to be decoded.
+func (r *Rep) Fingerprint() string {
+	gd := &r.InitData.GameDescription
+
+	players := r.Details.Players()
+	ids := make([]string, len(players))
+	for i := range players {
+		p := &players[i]
+		ids[i] = p.Toon.String() + "/" + p.Name
+	}
+	sort.Strings(ids)
+
+	h := sha256.New()
+	fmt.Fprintf(h, "%d\x00%d", gd.RandomValue(), gd.MapFileSyncChecksum())
+	for _, id := range ids {
+		fmt.Fprintf(h, "\x00%s", id)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,37 @@
+package rep
+
+import (
+	"testing"
+
+	"github.com/icza/s2prot"
+)
+
+func TestFingerprint(t *testing.T) {
+	newRep := func(randomValue int64, names ...string) *Rep {
+		r := &Rep{}
+		r.InitData = NewInitData(s2prot.Struct{"syncLobbyState": s2prot.Struct{"gameDescription": s2prot.Struct{
+			"randomValue":         randomValue,
+			"mapFileSyncChecksum": int64(1234),
+		}}})
+		var players []interface{}
+		for _, name := range names {
+			players = append(players, s2prot.Struct{"name": name, "toon": s2prot.Struct{"id": int64(len(name))}})
+		}
+		r.Details = Details{Struct: s2prot.Struct{"playerList": players}}
+		return r
+	}
+
+	fp := newRep(1, "Alice", "Bob").Fingerprint()
+	if len(fp) != 64 {
+		t.Errorf("Expected 64 hex digits, got: %s", fp)
+	}
+	if fp2 := newRep(1, "Bob", "Alice").Fingerprint(); fp2 != fp {
+		t.Errorf("Expected same fingerprint, got: %s, %s", fp, fp2)
+	}
+	if fp2 := newRep(2, "Alice", "Bob").Fingerprint(); fp2 == fp {
+		t.Errorf("Expected different fingerprint for different random value")
+	}
+	if fp2 := newRep(1, "Alice", "Carol").Fingerprint(); fp2 == fp {
+		t.Errorf("Expected different fingerprint for different players")
+	}
+}
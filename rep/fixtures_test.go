@@ -0,0 +1,83 @@
+package rep
+
+import "github.com/icza/s2prot"
+
+// newTestRep creates a replay for testing having a player in each of the given lobby slots:
+// the details list a player for each slot, and the working set slot ID of a slot and its player
+// is the index of the slot (unless the slot specifies one).
+// Fields of the details and its players may be set with setTestPlayers.
+func newTestRep(slots ...s2prot.Struct) *Rep {
+	players := make([]interface{}, len(slots))
+	slotList := make([]interface{}, len(slots))
+	for i, slot := range slots {
+		if slot.Value("workingSetSlotId") == nil {
+			slot["workingSetSlotId"] = int64(i)
+		}
+		players[i] = s2prot.Struct{"workingSetSlotId": slot["workingSetSlotId"]}
+		slotList[i] = slot
+	}
+
+	r := &Rep{}
+	r.Details = Details{Struct: s2prot.Struct{"playerList": players}}
+	r.InitData = NewInitData(s2prot.Struct{"syncLobbyState": s2prot.Struct{"lobbyState": s2prot.Struct{"slots": slotList}}})
+	return r
+}
+
+// humanSlot returns a lobby slot of a human player having the given user ID, with the fields added.
+func humanSlot(userID int64, fields ...s2prot.Struct) s2prot.Struct {
+	return addFields(s2prot.Struct{"control": int64(2), "userId": userID}, fields)
+}
+
+// computerSlot returns a lobby slot of a computer player, with the fields added.
+func computerSlot(fields ...s2prot.Struct) s2prot.Struct {
+	return addFields(s2prot.Struct{"control": int64(3)}, fields)
+}
+
+// addFields adds the fields to s, and returns s.
+func addFields(s s2prot.Struct, fields []s2prot.Struct) s2prot.Struct {
+	for _, f := range fields {
+		for k, v := range f {
+			s[k] = v
+		}
+	}
+	return s
+}
+
+// setTestPlayers adds the fields to the players of the details of a replay created by newTestRep, in order.
+// It must be called before the players are accessed.
+func setTestPlayers(r *Rep, fields ...s2prot.Struct) {
+	players := r.Details.Array("playerList")
+	for i, f := range fields {
+		addFields(players[i].(s2prot.Struct), []s2prot.Struct{f})
+	}
+}
+
+// setTestUsers sets the user init data of a replay created by newTestRep, in the order of user IDs.
+func setTestUsers(r *Rep, users ...s2prot.Struct) {
+	list := make([]interface{}, len(users))
+	for i, u := range users {
+		list[i] = u
+	}
+	r.InitData.Struct["userInitialData"] = list
+	r.InitData = NewInitData(s2prot.Struct{"syncLobbyState": r.InitData.Struct})
+}
+
+// trackerEvt returns an event of the given name at the loop, having the fields (which may be nil).
+// It is used for any event not bound to a user, e.g. tracker events.
+func trackerEvt(loop int64, name string, fields s2prot.Struct) s2prot.Event {
+	return s2prot.Event{Struct: addFields(s2prot.Struct{"loop": loop}, []s2prot.Struct{fields}), EvtType: &s2prot.EvtType{Name: name}}
+}
+
+// gameEvt returns an event of the given name at the loop, issued by the user, having the fields (which may be nil).
+// It is used for game and message events.
+func gameEvt(loop, userID int64, name string, fields s2prot.Struct) s2prot.Event {
+	e := trackerEvt(loop, name, fields)
+	e.Struct["userid"] = s2prot.Struct{"userId": userID}
+	return e
+}
+
+// unitEvt returns a tracker event of the given name at the loop, of the unit having the tag index (and recycle 1),
+// having the fields (which may be nil).
+func unitEvt(loop, tagIdx int64, name string, fields s2prot.Struct) s2prot.Event {
+	return trackerEvt(loop, name, addFields(s2prot.Struct{"unitTagIndex": tagIdx, "unitTagRecycle": int64(1)}, []s2prot.Struct{fields}))
+}
@@ -41,16 +41,32 @@ func (h *Header) Loops() int64 {
 
 // Duration returns the game duration.
 func (h *Header) Duration() time.Duration {
-	// 1 second = 16 loops => 1 loop = 1/16 second = 62,500,000 ns
-	return time.Duration(h.Loops() * 62500000)
+	return LoopsToDuration(h.Loops())
 }
 
-// Signature returns the header signature.
-// Should always be "StarCratII replay\u001b11".
+// Signature returns the header signature,
+// e.g. "StarCraft II replay\u001b11" (see Game).
 func (h *Header) Signature() string {
 	return h.Stringv("signature")
 }
 
+// Game returns the game the replay was recorded with, detected by the signature.
+// nil is returned if the game is unknown.
+func (h *Header) Game() *s2prot.Game {
+	return s2prot.GameOf(h.Struct)
+}
+
+// Protocol returns the protocol of the replay: the protocol of the base build of the game.
+// If the game is unknown, StarCraft II is assumed.
+// nil is returned if the base build is unknown / unsupported.
+func (h *Header) Protocol() *s2prot.Protocol {
+	game := h.Game()
+	if game == nil {
+		game = s2prot.GameSC2
+	}
+	return game.GetProtocol(int(h.BaseBuild()))
+}
+
 // UseScaledTime returns whether scaled time is used.
 func (h *Header) UseScaledTime() bool {
 	return h.Bool("useScaledTime")
@@ -67,15 +83,27 @@ func (h *Header) DataBuildNum() int64 {
 }
 
 // NgdpRootKey returns the data ngdp root key.
+// This is binary data, see NgdpRootKeyBytes.
 func (h *Header) NgdpRootKey() string {
 	return h.Stringv("ngdpRootKey", "data")
 }
 
+// NgdpRootKeyBytes returns the data ngdp root key as a byte slice.
+func (h *Header) NgdpRootKeyBytes() []byte {
+	return h.Blob("ngdpRootKey", "data")
+}
+
 // ReplayCompatibilityHash returns the replay compatibility hash.
+// This is binary data, see ReplayCompatibilityHashBytes.
 func (h *Header) ReplayCompatibilityHash() string {
 	return h.Stringv("replayCompatibilityHash", "data")
 }
 
+// ReplayCompatibilityHashBytes returns the replay compatibility hash as a byte slice.
+func (h *Header) ReplayCompatibilityHashBytes() []byte {
+	return h.Blob("replayCompatibilityHash", "data")
+}
+
 // Version returns the version of the replay.
 func (h *Header) Version() Version {
 	return Version{Struct: h.Structv("version")}
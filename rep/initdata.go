@@ -64,16 +64,7 @@ func (g *GameDescription) Region() *Region {
 
 // ExpLevel returns the expansion level of the replay.
 func (g *GameDescription) ExpLevel() *ExpLevel {
-	if chs := g.CacheHandles(); len(chs) > 0 {
-		for _, exp := range ExpLevels {
-			for _, ch := range chs {
-				if exp.Digest == ch.Digest {
-					return exp
-				}
-			}
-		}
-	}
-	return ExpLevelUnknown
+	return expLevelOf(g.CacheHandles())
 }
 
 // GameSpeed returns the game speed.
@@ -238,7 +229,7 @@ func (g *GameOptions) ClientDebugFlags() int64 {
 // Competitive means either ranked or unranked. Before competitive there was no unraked type (so ranked="ladder").
 func (g *GameOptions) CompetitiveOrRanked() bool {
 	// competitive is present from base version 24674, replaces ranked
-	if v, ok := g.Value("competitive").(bool); ok {
+	if v, ok := g.LookupBool("competitive"); ok {
 		return v
 	}
 	return g.Bool("ranked")
@@ -430,7 +421,7 @@ func (s *Slot) Handicap() int64 {
 }
 
 // Licenses returns the array of licenses.
-// The array has elements of type int64.
+// The array has elements of type int64, use s.Ints("licenses") to get them as []int64.
 func (s *Slot) Licenses() []interface{} {
 	return s.Array("licenses")
 }
@@ -457,21 +448,21 @@ func (s *Slot) Observe() *Observe {
 
 // RacePrefRace returns the race preference race. This may be RaceRandom.
 func (s *Slot) RacePrefRace() *Race {
-	if rp := s.Structv("racePref"); rp != nil {
-		r := rp.Value("race")
-		if r == nil {
-			return RaceRandom
-		}
-		if i, ok := r.(int64); ok {
+	if rp, ok := s.LookupStruct("racePref"); ok {
+		// race is optional, absent race means Random
+		if i, ok := rp.LookupInt("race"); ok {
 			return raceByID(i)
 		}
+		if rp.Value("race") == nil {
+			return RaceRandom
+		}
 	}
 
 	return RaceUnknown
 }
 
 // Rewards returns the array of rewards.
-// The array has elements of type int64.
+// The array has elements of type int64, use s.Ints("rewards") to get them as []int64.
 func (s *Slot) Rewards() []interface{} {
 	return s.Array("rewards")
 }
@@ -572,9 +563,18 @@ func (u *UserInitData) Examine() bool {
 
 // HighestLeague returns the highest league.
 func (u *UserInitData) HighestLeague() *League {
-	// If property doesn't exist, zero value 0 is returned which is LeagueUnknown
-	// which is exactly we would return anyway, so simply:
-	return leagueByID(u.Int("highestLeague"))
+	if id, ok := u.LookupInt("highestLeague"); ok {
+		return leagueByID(id)
+	}
+	return LeagueUnknown
+}
+
+// HasHighestLeague tells if the highest league is present.
+// It is absent in older replays, in which case HighestLeague returns LeagueUnknown
+// which is the same as a present "unknown" value.
+func (u *UserInitData) HasHighestLeague() bool {
+	_, ok := u.LookupInt("highestLeague")
+	return ok
 }
 
 // Name returns the name.
@@ -641,3 +641,10 @@ func (u *UserInitData) ToonHandle() string {
 func (u *UserInitData) MMR() int64 {
 	return u.Int("scaledRating")
 }
+
+// ScaledRating returns the scaled rating (MMR) of the user.
+// ok is false if the scaled rating is missing: older replays do not have it,
+// and it is also absent for unrated users (e.g. in custom games).
+func (u *UserInitData) ScaledRating() (rating int64, ok bool) {
+	return u.LookupInt("scaledRating")
+}
@@ -0,0 +1,184 @@
+/*
+
+JSON representation of the whole replay.
+
+*/
+
+package rep
+
+import (
+	"encoding/json"
+	"math"
+
+	"github.com/icza/s2prot"
+)
+
+// JSONSections tells which sections to include in the JSON representation of a replay.
+// Sections that were not decoded (or are missing from the replay) are always omitted.
+type JSONSections struct {
+	Header      bool // Replay header
+	Details     bool // Game details
+	InitData    bool // Init data
+	AttrEvts    bool // Attributes events
+	Metadata    bool // Game metadata
+	Players     bool // Computed player data, see JSONPlayer
+	GameEvts    bool // Game events
+	MessageEvts bool // Message events
+	TrackerEvts bool // Tracker events
+}
+
+// AllJSONSections includes all sections.
+var AllJSONSections = JSONSections{true, true, true, true, true, true, true, true, true}
+
+// JSONDoc is the JSON document of a replay. Omitted sections are absent from the document.
+//
+// The document has the following top-level keys:
+//
+//	parserVersion  version of the parser (ParserVersion)
+//	header         replay header
+//	details        game details
+//	initData       init data
+//	attributes     attributes events
+//	metadata       game metadata (synthesized for replays that have none)
+//	players        computed player data, array of JSONPlayer
+//	gameEvts       game events, present if game events were decoded
+//	messageEvts    message events, present if message events were decoded
+//	trackerEvts    tracker events, present if tracker events were decoded
+//	gameEvtsErr, messageEvtsErr, trackerEvtsErr    present and true if decoding the events had errors
+//
+// Events in the document have an additional, computed "realTimeSeconds" field: the real (wall clock) time
+// of the event in seconds, derived from the loop and the game speed (see RealTimeSeconds).
+type JSONDoc struct {
+	ParserVersion string         `json:"parserVersion"`
+	Header        s2prot.Struct  `json:"header,omitempty"`
+	Details       s2prot.Struct  `json:"details,omitempty"`
+	InitData      s2prot.Struct  `json:"initData,omitempty"`
+	Attributes    s2prot.Struct  `json:"attributes,omitempty"`
+	Metadata      s2prot.Struct  `json:"metadata,omitempty"`
+	Players       []JSONPlayer   `json:"players,omitempty"`
+	GameEvts      []s2prot.Event `json:"gameEvts,omitempty"`
+	MessageEvts   []s2prot.Event `json:"messageEvts,omitempty"`
+	TrackerEvts   []s2prot.Event `json:"trackerEvts,omitempty"`
+
+	GameEvtsErr    bool `json:"gameEvtsErr,omitempty"`
+	MessageEvtsErr bool `json:"messageEvtsErr,omitempty"`
+	TrackerEvtsErr bool `json:"trackerEvtsErr,omitempty"`
+}
+
+// JSONPlayer is the computed data of a player in the JSON document.
+type JSONPlayer struct {
+	Name         string   `json:"name"`              // Name without clan tag
+	ClanTag      string   `json:"clanTag,omitempty"` // Clan tag
+	Toon         string   `json:"toon"`              // Toon handle
+	Control      string   `json:"control"`           // Control, e.g. "Human"
+	TeamID       int64    `json:"teamId"`            // Team ID
+	Color        string   `json:"color,omitempty"`   // Color in hex notation, e.g. "#b4141e"
+	SelectedRace string   `json:"selectedRace"`      // Race selected in the lobby, may be "Random"
+	Race         string   `json:"race"`              // Assigned race
+	Result       string   `json:"result"`            // Reconciled result, e.g. "Victory"
+	APM          *float64 `json:"apm,omitempty"`     // APM, present if game events were decoded
+	MMR          *int64   `json:"mmr,omitempty"`     // MMR, present if available
+}
+
+// JSONDoc returns the JSON document of the replay including the specified sections.
+func (r *Rep) JSONDoc(sections JSONSections) *JSONDoc {
+	doc := &JSONDoc{ParserVersion: ParserVersion}
+
+	if sections.Header {
+		doc.Header = r.Header.Struct
+	}
+	if sections.Details {
+		doc.Details = r.Details.Struct
+	}
+	if sections.InitData {
+		doc.InitData = r.InitData.Struct
+	}
+	if sections.AttrEvts {
+		doc.Attributes = r.AttrEvts.Struct
+	}
+	if sections.Metadata {
+		doc.Metadata = r.Metadata.Struct
+	}
+	if sections.Players {
+		doc.Players = r.jsonPlayers()
+	}
+	if sections.GameEvts {
+		doc.GameEvts, doc.GameEvtsErr = r.withRealTimes(r.GameEvts), r.GameEvtsErr
+	}
+	if sections.MessageEvts {
+		doc.MessageEvts, doc.MessageEvtsErr = r.withRealTimes(r.MessageEvts), r.MessageEvtsErr
+	}
+	if sections.TrackerEvts && r.TrackerEvts != nil {
+		doc.TrackerEvts, doc.TrackerEvtsErr = r.withRealTimes(r.TrackerEvts.Evts), r.TrackerEvtsErr
+	}
+
+	return doc
+}
+
+// FilterChat applies the chat filter to the message events of the document.
+// The events of the replay are not modified.
+func (d *JSONDoc) FilterChat(f ChatFilter) {
+	d.MessageEvts = f.FilterEvts(d.MessageEvts)
+}
+
+// RealTimeSeconds returns the real (wall clock) time of a game loop in seconds, adjusted to the game speed,
+// rounded to milliseconds. This is the value of the "realTimeSeconds" field of events in the JSON document.
+func (r *Rep) RealTimeSeconds(loop int64) float64 {
+	return math.Round(r.loopDuration(loop).Seconds()*1000) / 1000
+}
+
+// withRealTimes returns copies of the events extended with the "realTimeSeconds" field.
+// The original events are not modified. nil is returned for nil events.
+func (r *Rep) withRealTimes(evts []s2prot.Event) []s2prot.Event {
+	if evts == nil {
+		return nil
+	}
+	copies := make([]s2prot.Event, len(evts))
+	for i, e := range evts {
+		s := make(s2prot.Struct, len(e.Struct)+1)
+		for k, v := range e.Struct {
+			s[k] = v
+		}
+		s["realTimeSeconds"] = r.RealTimeSeconds(e.Loop())
+		copies[i] = e
+		copies[i].Struct = s
+	}
+	return copies
+}
+
+// jsonPlayers returns the computed data of the players.
+func (r *Rep) jsonPlayers() []JSONPlayer {
+	apms, results := r.apms(), r.PlayerResults()
+	players := r.Details.Players()
+	jps := make([]JSONPlayer, len(players))
+	for i := range players {
+		p := &players[i]
+		jp := JSONPlayer{
+			ClanTag:      r.PlayerClanTag(i),
+			Name:         p.BareName(),
+			Toon:         p.Toon.String(),
+			Control:      p.Control().String(),
+			TeamID:       r.PlayerTeamID(i),
+			SelectedRace: r.PlayerSelectedRace(i).String(),
+			Race:         r.PlayerAssignedRace(i).String(),
+			Result:       results[i].String(),
+		}
+		if c := r.PlayerColor(i); c != ColorUnknown {
+			jp.Color = c.Hex()
+		}
+		if apm, ok := apms[i]; ok {
+			jp.APM = &apm
+		}
+		if mmr, ok := r.PlayerMMR(i); ok {
+			jp.MMR = &mmr
+		}
+		jps[i] = jp
+	}
+	return jps
+}
+
+// MarshalJSON marshals the replay including all sections, see JSONDoc for the document structure.
+// Use Rep.JSONDoc() to include only some sections.
+func (r *Rep) MarshalJSON() ([]byte, error) {
+	return json.Marshal(r.JSONDoc(AllJSONSections))
+}
@@ -0,0 +1,66 @@
+package rep
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/icza/s2prot"
+)
+
+func TestMarshalJSON(t *testing.T) {
+	r := &Rep{}
+	r.Header = Header{Struct: s2prot.Struct{"elapsedGameLoops": int64(100)}}
+	r.Details = Details{Struct: s2prot.Struct{"playerList": []interface{}{
+		s2prot.Struct{"name": "[ABC]<sp/>Foo", "race": "Zerg", "result": int64(1), "control": int64(2)},
+	}}}
+	r.GameEvts = []s2prot.Event{}
+
+	unmarshal := func(data []byte, err error) map[string]interface{} {
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		m := map[string]interface{}{}
+		if err := json.Unmarshal(data, &m); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		return m
+	}
+
+	m := unmarshal(json.Marshal(r))
+	for _, key := range []string{"parserVersion", "header", "details", "players"} {
+		if _, ok := m[key]; !ok {
+			t.Errorf("Expected key: %s", key)
+		}
+	}
+	// Sections not decoded / missing:
+	for _, key := range []string{"initData", "attributes", "metadata", "messageEvts", "trackerEvts"} {
+		if _, ok := m[key]; ok {
+			t.Errorf("Unexpected key: %s", key)
+		}
+	}
+	players, _ := m["players"].([]interface{})
+	if len(players) != 1 {
+		t.Fatalf("Expected 1 player, got: %v", m["players"])
+	}
+	if p := players[0].(map[string]interface{}); p["name"] != "Foo" || p["clanTag"] != "ABC" || p["race"] != "Zerg" ||
+		p["result"] != "Victory" || p["control"] != "Human" {
+		t.Errorf("Unexpected player: %v", p)
+	}
+
+	m = unmarshal(json.Marshal(r.JSONDoc(JSONSections{Details: true})))
+	if len(m) != 2 || m["details"] == nil {
+		t.Errorf("Unexpected document: %v", m)
+	}
+
+	// Real times of events, on Faster:
+	r.Details.Struct["gameSpeed"] = int64(4)
+	r.GameEvts = []s2prot.Event{gameEvt(224, 0, "Cmd", nil)}
+	m = unmarshal(json.Marshal(r.JSONDoc(JSONSections{GameEvts: true})))
+	evts, _ := m["gameEvts"].([]interface{})
+	if len(evts) != 1 || evts[0].(map[string]interface{})["realTimeSeconds"] != 10.0 {
+		t.Errorf("Unexpected game events: %v", m["gameEvts"])
+	}
+	if _, ok := r.GameEvts[0].Struct["realTimeSeconds"]; ok {
+		t.Error("Original event must not be modified!")
+	}
+}
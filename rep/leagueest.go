@@ -0,0 +1,119 @@
+/*
+
+League estimation from MMR.
+
+*/
+
+package rep
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// LeagueBound is the lower MMR bound of a league tier.
+type LeagueBound struct {
+	League *League // League
+	Tier   int     // Tier inside the league, 1 is the highest
+	MinMMR int64   // Minimum MMR of the tier
+}
+
+// LeagueTable is a table of 1v1 league boundaries in effect from a given date.
+type LeagueTable struct {
+	From   time.Time     // Date from which the table is in effect
+	Region *Region       // Region of the table, nil means all regions
+	Bounds []LeagueBound // Bounds in increasing MinMMR order
+}
+
+// LeagueEstimate is a league and tier estimated from MMR.
+// It is an estimate only: actual leagues are not assigned purely based on MMR.
+type LeagueEstimate struct {
+	League *League // Estimated league
+	Tier   int     // Estimated tier inside the league, 1 is the highest
+	MMR    int64   // MMR the estimate is based on
+}
+
+// String returns a string representation of the estimate, prefixed with '~' to mark it is an estimate,
+// e.g. "~Diamond 2".
+func (e *LeagueEstimate) String() string {
+	return fmt.Sprintf("~%s %d", e.League, e.Tier)
+}
+
+// defaultLeagueTable contains approximate league boundaries of recent seasons, valid for all regions.
+// Grandmaster is not included as it is not MMR-based (it's the top players of a region).
+var defaultLeagueTable = &LeagueTable{
+	Bounds: []LeagueBound{
+		{LeagueBronze, 3, 0}, {LeagueBronze, 2, 1200}, {LeagueBronze, 1, 1400},
+		{LeagueSilver, 3, 1600}, {LeagueSilver, 2, 1800}, {LeagueSilver, 1, 1950},
+		{LeagueGold, 3, 2100}, {LeagueGold, 2, 2250}, {LeagueGold, 1, 2400},
+		{LeaguePlatinum, 3, 2550}, {LeaguePlatinum, 2, 2700}, {LeaguePlatinum, 1, 2850},
+		{LeagueDiamond, 3, 3000}, {LeagueDiamond, 2, 3250}, {LeagueDiamond, 1, 3550},
+		{LeagueMaster, 3, 3900}, {LeagueMaster, 2, 4200}, {LeagueMaster, 1, 4500},
+	},
+}
+
+var (
+	leagueTablesMu sync.RWMutex                         // Protects leagueTables
+	leagueTables   = []*LeagueTable{defaultLeagueTable} // Registered league tables
+)
+
+// RegisterLeagueTable registers a league table to be used by EstimateLeague().
+// Tables registered later take precedence over earlier ones with the same From date and region.
+func RegisterLeagueTable(t *LeagueTable) {
+	leagueTablesMu.Lock()
+	leagueTables = append(leagueTables, t)
+	leagueTablesMu.Unlock()
+}
+
+// leagueTable returns the league table in effect at the specified date in the specified region.
+// The latest table not after the date is chosen; region-specific tables are preferred.
+func leagueTable(date time.Time, region *Region) (res *LeagueTable) {
+	leagueTablesMu.RLock()
+	defer leagueTablesMu.RUnlock()
+
+	for _, t := range leagueTables {
+		if t.From.After(date) || (t.Region != nil && t.Region != region) {
+			continue
+		}
+		if res == nil || t.From.After(res.From) || t.From.Equal(res.From) && (t.Region != nil || res.Region == nil) {
+			res = t
+		}
+	}
+	return
+}
+
+// EstimateLeague estimates the 1v1 league and tier of an MMR at the specified date in the specified region
+// using the registered league tables.
+// nil is returned if there is no applicable league table.
+func EstimateLeague(mmr int64, date time.Time, region *Region) *LeagueEstimate {
+	t := leagueTable(date, region)
+	if t == nil || len(t.Bounds) == 0 {
+		return nil
+	}
+
+	// Index of the first bound above mmr:
+	i := sort.Search(len(t.Bounds), func(i int) bool { return t.Bounds[i].MinMMR > mmr })
+	if i > 0 {
+		i--
+	}
+	b := t.Bounds[i]
+	return &LeagueEstimate{League: b.League, Tier: b.Tier, MMR: mmr}
+}
+
+// EstimatedLeague estimates the 1v1 league of the player specified by its index in Details.Players()
+// from its MMR (see PlayerMMR()), the replay date and the player's region.
+// Useful when the highest league of the user init data is missing or stale.
+// nil is returned if the player index is invalid or the MMR of the player is not available.
+func (r *Rep) EstimatedLeague(playerIdx int) *LeagueEstimate {
+	players := r.Details.Players()
+	if playerIdx < 0 || playerIdx >= len(players) {
+		return nil
+	}
+	mmr, ok := r.PlayerMMR(playerIdx)
+	if !ok {
+		return nil
+	}
+	return EstimateLeague(mmr, r.Details.TimeUTC(), players[playerIdx].Toon.Region())
+}
@@ -0,0 +1,72 @@
+package rep
+
+import (
+	"testing"
+	"time"
+
+	"github.com/icza/s2prot"
+)
+
+func TestEstimateLeague(t *testing.T) {
+	date := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		mmr    int64
+		league *League
+		tier   int
+	}{
+		{-100, LeagueBronze, 3},
+		{0, LeagueBronze, 3},
+		{2100, LeagueGold, 3},
+		{3300, LeagueDiamond, 2},
+		{7000, LeagueMaster, 1},
+	}
+
+	for _, c := range cases {
+		e := EstimateLeague(c.mmr, date, RegionEU)
+		if e == nil || e.League != c.league || e.Tier != c.tier || e.MMR != c.mmr {
+			t.Errorf("[%d] Expected: %v %d, got: %v", c.mmr, c.league, c.tier, e)
+		}
+	}
+
+	if s := EstimateLeague(3300, date, RegionEU).String(); s != "~Diamond 2" {
+		t.Errorf("Unexpected string: %s", s)
+	}
+
+	// Register a region specific table, make sure to restore registered tables:
+	defer func(tables []*LeagueTable) { leagueTables = tables }(leagueTables)
+	RegisterLeagueTable(&LeagueTable{
+		From:   date,
+		Region: RegionKR,
+		Bounds: []LeagueBound{{LeagueBronze, 1, 0}, {LeagueGrandmaster, 1, 3000}},
+	})
+
+	if e := EstimateLeague(3300, date.Add(time.Hour), RegionKR); e.League != LeagueGrandmaster {
+		t.Errorf("Expected region specific table, got: %v", e)
+	}
+	if e := EstimateLeague(3300, date.Add(-time.Hour), RegionKR); e.League != LeagueDiamond {
+		t.Errorf("Expected default table before its date, got: %v", e)
+	}
+	if e := EstimateLeague(3300, date.Add(time.Hour), RegionEU); e.League != LeagueDiamond {
+		t.Errorf("Expected default table for other region, got: %v", e)
+	}
+}
+
+func TestEstimatedLeague(t *testing.T) {
+	r := newTestRep(humanSlot(0))
+	setTestPlayers(r, s2prot.Struct{"toon": s2prot.Struct{"region": int64(2)}})
+	// Inconsistent metadata: it has a player missing from the details
+	r.Metadata = Metadata{Struct: s2prot.Struct{"Players": []interface{}{
+		map[string]interface{}{"PlayerID": 1.0, "MMR": 3300.0},
+		map[string]interface{}{"PlayerID": 2.0, "MMR": 4000.0},
+	}}}
+
+	if e := r.EstimatedLeague(0); e == nil || e.League != LeagueDiamond || e.MMR != 3300 {
+		t.Errorf("Expected Diamond estimate, got: %v", e)
+	}
+	for _, playerIdx := range []int{-1, 1} {
+		if e := r.EstimatedLeague(playerIdx); e != nil {
+			t.Errorf("[%d] Expected nil, got: %v", playerIdx, e)
+		}
+	}
+}
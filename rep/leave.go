@@ -0,0 +1,110 @@
+/*
+
+Detecting how players left the game.
+
+*/
+
+package rep
+
+import "github.com/icza/s2prot"
+
+// PlayerLeave describes a player leaving the game.
+type PlayerLeave struct {
+	Loop      int64        // Game loop of leaving
+	Reason    *LeaveReason // Reason of leaving
+	BeforeEnd bool         // Tells if the player left before the end of the game (before the replay ended)
+
+	// GameReason is the leave reason recorded in the replay, nil if not recorded (before base build 34784).
+	GameReason *GameUserLeaveReason
+
+	// GameReasonValue is the raw recorded value of the leave reason, valid if GameReason is not nil
+	// (useful if GameReason is GameUserLeaveReasonUnknown).
+	GameReasonValue int64
+}
+
+// leaveReasonMapping maps the values of the leaveReason field of GameUserLeave events
+// to GameUserLeaveReasons, from a base build.
+type leaveReasonMapping struct {
+	minBaseBuild int64                  // First base build the mapping is valid for
+	reasons      []*GameUserLeaveReason // Leave reasons, index is the recorded value
+}
+
+// leaveReasonMappings holds the value mappings of leave reasons, in decreasing order of base build.
+//
+// The values are not documented, only verified values are mapped: 0 (user left) is what replays
+// of regular games record (verified with replays of base build 42253).
+// Other values are reported as GameUserLeaveReasonUnknown.
+var leaveReasonMappings = []leaveReasonMapping{
+	{34784, []*GameUserLeaveReason{
+		GameUserLeaveReasonUserLeft,
+	}},
+}
+
+// gameUserLeaveReasonOf returns the GameUserLeaveReason denoted by the value of the leaveReason field
+// of GameUserLeave events in the specified base build.
+// GameUserLeaveReasonUnknown is returned for unknown values, nil if leave reasons are not recorded in the base build.
+func gameUserLeaveReasonOf(baseBuild, value int64) *GameUserLeaveReason {
+	for _, m := range leaveReasonMappings {
+		if baseBuild < m.minBaseBuild {
+			continue
+		}
+		if value >= 0 && value < int64(len(m.reasons)) {
+			return m.reasons[value]
+		}
+		return GameUserLeaveReasonUnknown
+	}
+	return nil
+}
+
+// GameUserLeaveReason returns the leave reason recorded in the specified GameUserLeave game event of the replay.
+// nil is returned if the event has no recorded leave reason (e.g. before base build 34784).
+// The raw recorded value is the "leaveReason" field of the event.
+func (r *Rep) GameUserLeaveReason(e s2prot.Event) *GameUserLeaveReason {
+	v, ok := e.LookupInt("leaveReason")
+	if !ok {
+		return nil
+	}
+	if glr := gameUserLeaveReasonOf(r.Header.BaseBuild(), v); glr != nil {
+		return glr
+	}
+	return GameUserLeaveReasonUnknown // Recorded, but no mapping for the base build
+}
+
+// PlayerLeaves returns how the players left the game, index is the same as in Details.Players().
+// Elements of players who did not leave (e.g. computer players) are nil.
+// Only the first leave event of a user is considered.
+//
+// The leave reason is recorded in replays from base build 34784, LeaveReasonQuit is reported for older replays.
+// Recorded values not known to denote a quit are reported as LeaveReasonUnknown.
+//
+// nil is returned if game events were not decoded.
+func (r *Rep) PlayerLeaves() []*PlayerLeave {
+	if r.GameEvts == nil {
+		return nil
+	}
+
+	leaves := make([]*PlayerLeave, len(r.Details.Players()))
+	userPlayerIdxs := r.userPlayerIdxs()
+	for _, e := range r.GameEvts {
+		if e.Name != "GameUserLeave" && e.Name != "PlayerLeave" {
+			continue
+		}
+		userID, ok := evtUserID(e)
+		if !ok {
+			continue
+		}
+		idx, ok := userPlayerIdxs[userID]
+		if !ok || leaves[idx] != nil {
+			continue
+		}
+
+		pl := &PlayerLeave{Loop: e.Loop(), Reason: LeaveReasonQuit, BeforeEnd: e.Loop() < r.Header.Loops()}
+		if pl.GameReason = r.GameUserLeaveReason(e); pl.GameReason != nil {
+			pl.Reason = pl.GameReason.Reason
+			pl.GameReasonValue = e.Int("leaveReason")
+		}
+		leaves[idx] = pl
+	}
+
+	return leaves
+}
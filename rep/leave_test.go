@@ -0,0 +1,65 @@
+package rep
+
+import (
+	"testing"
+
+	"github.com/icza/s2prot"
+)
+
+func TestPlayerLeaves(t *testing.T) {
+	r := newTestRep(humanSlot(0), humanSlot(1), humanSlot(2), computerSlot())
+	r.Header = Header{Struct: s2prot.Struct{"elapsedGameLoops": int64(1000), "version": s2prot.Struct{"baseBuild": int64(80949)}}}
+
+	if r.PlayerLeaves() != nil {
+		t.Error("Expected nil leaves without game events!")
+	}
+
+	leave := func(loop, userID int64, reason interface{}) s2prot.Event {
+		fields := s2prot.Struct{}
+		if reason != nil {
+			fields["leaveReason"] = reason
+		}
+		return gameEvt(loop, userID, "GameUserLeave", fields)
+	}
+	r.GameEvts = []s2prot.Event{
+		leave(300, 1, int64(1)),
+		leave(500, 0, nil),
+		leave(600, 0, int64(2)), // Only the first leave counts
+		leave(1000, 2, int64(0)),
+		leave(1000, 16, int64(0)), // Not a player
+	}
+
+	exp := []*PlayerLeave{
+		{Loop: 500, Reason: LeaveReasonQuit, BeforeEnd: true},
+		{Loop: 300, Reason: LeaveReasonUnknown, BeforeEnd: true, GameReason: GameUserLeaveReasonUnknown, GameReasonValue: 1},
+		{Loop: 1000, Reason: LeaveReasonQuit, GameReason: GameUserLeaveReasonUserLeft},
+		nil,
+	}
+	leaves := r.PlayerLeaves()
+	if len(leaves) != len(exp) {
+		t.Fatalf("Expected %d leaves, got: %d", len(exp), len(leaves))
+	}
+	for i, pl := range leaves {
+		if (pl == nil) != (exp[i] == nil) || pl != nil && *pl != *exp[i] {
+			t.Errorf("[%d] Expected: %+v, got: %+v", i, exp[i], pl)
+		}
+	}
+
+	cases := []struct {
+		baseBuild, value int64
+		exp              *GameUserLeaveReason
+	}{
+		{80949, 0, GameUserLeaveReasonUserLeft},
+		{42253, 0, GameUserLeaveReasonUserLeft},
+		{80949, 1, GameUserLeaveReasonUnknown},
+		{34784, 3, GameUserLeaveReasonUnknown},
+		{80949, 9, GameUserLeaveReasonUnknown},
+		{80949, -1, GameUserLeaveReasonUnknown},
+		{24944, 0, nil},
+	}
+	for _, c := range cases {
+		if got := gameUserLeaveReasonOf(c.baseBuild, c.value); got != c.exp {
+			t.Errorf("[%d, %d] Expected: %v, got: %v", c.baseBuild, c.value, c.exp, got)
+		}
+	}
+}
@@ -0,0 +1,194 @@
+/*
+
+Localized race names and detection of the locale of the game client that saved the replay.
+
+*/
+
+package rep
+
+import (
+	"sync"
+	"unicode"
+)
+
+// LocalRaceNames holds the localized race names of a locale.
+// Multiple variants of a name may be listed (e.g. singular and plural forms).
+type LocalRaceNames struct {
+	Locale  string   // Locale of the game client, e.g. "deDE"
+	Terran  []string // Localized names of the Terran race
+	Zerg    []string // Localized names of the Zerg race
+	Protoss []string // Localized names of the Protoss race
+}
+
+// builtinLocalRaceNames holds the localized race names of the official locales.
+var builtinLocalRaceNames = []LocalRaceNames{
+	{"enUS", []string{"Terran"}, []string{"Zerg"}, []string{"Protoss"}},
+	{"enGB", []string{"Terran"}, []string{"Zerg"}, []string{"Protoss"}},
+	{"deDE", []string{"Terraner"}, []string{"Zerg"}, []string{"Protoss"}},
+	{"esES", []string{"Terran"}, []string{"Zerg"}, []string{"Protoss"}},
+	{"esMX", []string{"Terran"}, []string{"Zerg"}, []string{"Protoss"}},
+	{"frFR", []string{"Terran"}, []string{"Zerg"}, []string{"Protoss"}},
+	{"itIT", []string{"Terran"}, []string{"Zerg"}, []string{"Protoss"}},
+	{"koKR", []string{"테란"}, []string{"저그"}, []string{"프로토스"}},
+	{"plPL", []string{"Terrani"}, []string{"Zergi"}, []string{"Protosi"}},
+	{"ptBR", []string{"Terrano"}, []string{"Zerg"}, []string{"Protoss"}},
+	{"ruRU", []string{"Терран", "Терраны"}, []string{"Зерг", "Зерги"}, []string{"Протосс", "Протоссы"}},
+	{"zhCN", []string{"人类"}, []string{"异虫"}, []string{"星灵"}},
+	{"zhTW", []string{"人類"}, []string{"蟲族"}, []string{"神族"}},
+}
+
+var (
+	// localRaceNames maps from localized race name to Race, used in Details["playerList"]["race"]
+	localRaceNames = map[string]*Race{}
+	// localRaceLocales maps from localized race name to the locales using it, in order of registration
+	localRaceLocales = map[string][]string{}
+	// localRaceNamesMu protects localRaceNames and localRaceLocales
+	localRaceNamesMu sync.RWMutex
+)
+
+func init() {
+	for _, names := range builtinLocalRaceNames {
+		RegisterLocalRaceNames(names)
+	}
+}
+
+// RegisterLocalRaceNames registers the localized race names of a locale, used to parse the race of players
+// in Details and to detect the locale of replays (see Rep.Locales).
+// Registering names of an already registered locale adds the names.
+//
+// Registration should be done before processing replays, but it is safe for concurrent use with lookups.
+func RegisterLocalRaceNames(names LocalRaceNames) {
+	localRaceNamesMu.Lock()
+	defer localRaceNamesMu.Unlock()
+
+	for _, rn := range []struct {
+		race  *Race
+		names []string
+	}{{RaceTerran, names.Terran}, {RaceZerg, names.Zerg}, {RaceProtoss, names.Protoss}} {
+		for _, name := range rn.names {
+			localRaceNames[name] = rn.race
+			if !containsString(localRaceLocales[name], names.Locale) {
+				localRaceLocales[name] = append(localRaceLocales[name], names.Locale)
+			}
+		}
+	}
+}
+
+// scriptLocales maps from Unicode scripts to the locales using them.
+var scriptLocales = []struct {
+	script  *unicode.RangeTable
+	locales []string
+}{
+	{unicode.Hangul, []string{"koKR"}},
+	{unicode.Cyrillic, []string{"ruRU"}},
+	{unicode.Han, []string{"zhCN", "zhTW"}},
+}
+
+// Locales returns the locales the game client that saved the replay may have used, most likely first.
+// Replays do not record the locale, it is detected from localized strings:
+//
+//   - the localized race names of players in Details (see RegisterLocalRaceNames),
+//   - the script of the localized map name (e.g. Hangul denotes "koKR"),
+//   - the languages of the region of the players (see Region.BnetLangs), used to order the candidates.
+//
+// Multiple locales are returned if the strings are the same in them (e.g. race names are the same in English and French).
+// nil is returned if no registered locale matches the localized strings.
+func (r *Rep) Locales() []string {
+	localRaceNamesMu.RLock()
+	var candidates []string
+	matched := false // Tells if a race name matched
+	for _, p := range r.Details.Players() {
+		locales, ok := localRaceLocales[p.Stringv("race")]
+		if !ok {
+			continue
+		}
+		if !matched {
+			candidates, matched = append([]string{}, locales...), true
+		} else {
+			candidates = intersectStrings(candidates, locales)
+		}
+	}
+	localRaceNamesMu.RUnlock()
+	if matched && len(candidates) == 0 {
+		return nil // Race names of different locales
+	}
+
+	// Filter by the script of the map name (if it narrows the candidates):
+	title := r.Details.Title()
+	for _, sl := range scriptLocales {
+		if !hasScript(title, sl.script) {
+			continue
+		}
+		if !matched {
+			candidates = append([]string{}, sl.locales...)
+		} else if filtered := intersectStrings(candidates, sl.locales); len(filtered) > 0 {
+			candidates = filtered
+		}
+		break
+	}
+	if len(candidates) < 2 {
+		return candidates
+	}
+
+	// Order by the languages of the region of the players:
+	for _, p := range r.Details.Players() {
+		if region := p.Toon.Region(); region != RegionUnknown {
+			ordered := make([]string, 0, len(candidates))
+			for _, lang := range region.BnetLangs {
+				for _, loc := range candidates {
+					if len(loc) >= 2 && loc[:2] == lang.Code && !containsString(ordered, loc) {
+						ordered = append(ordered, loc)
+					}
+				}
+			}
+			for _, loc := range candidates {
+				if !containsString(ordered, loc) {
+					ordered = append(ordered, loc)
+				}
+			}
+			candidates = ordered
+			break
+		}
+	}
+	return candidates
+}
+
+// Locale returns the most likely locale of the game client that saved the replay, see Locales.
+// Empty string is returned if the locale cannot be determined.
+func (r *Rep) Locale() string {
+	if locales := r.Locales(); len(locales) > 0 {
+		return locales[0]
+	}
+	return ""
+}
+
+// hasScript tells if s contains a letter of the given script.
+func hasScript(s string, script *unicode.RangeTable) bool {
+	for _, r := range s {
+		if unicode.Is(script, r) {
+			return true
+		}
+	}
+	return false
+}
+
+// containsString tells if ss contains s.
+func containsString(ss []string, s string) bool {
+	for _, v := range ss {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// intersectStrings returns the elements of a that are also in b, in the order of a.
+func intersectStrings(a, b []string) []string {
+	var is []string
+	for _, s := range a {
+		if containsString(b, s) {
+			is = append(is, s)
+		}
+	}
+	return is
+}
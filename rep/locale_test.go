@@ -0,0 +1,52 @@
+package rep
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/icza/s2prot"
+)
+
+func TestLocales(t *testing.T) {
+	newRep := func(title string, regionID int64, races ...string) *Rep {
+		players := []interface{}{}
+		for _, race := range races {
+			players = append(players, s2prot.Struct{"race": race, "toon": s2prot.Struct{"region": regionID, "realm": int64(1)}})
+		}
+		return &Rep{Details: Details{Struct: s2prot.Struct{"title": title, "playerList": players}}}
+	}
+
+	cases := []struct {
+		name string
+		r    *Rep
+		exp  []string
+	}{
+		{"german", newRep("Magmaminen", 2, "Terraner", "Protoss"), []string{"deDE"}},
+		{"russian variants", newRep("Магма", 2, "Зерги", "Протосс"), []string{"ruRU"}},
+		{"korean", newRep("마그마 광산", 3, "테란", "저그"), []string{"koKR"}},
+		{"han script", newRep("熔岩礦坑", 3), []string{"zhCN", "zhTW"}},
+		{"english eu", newRep("Magma Mines", 2, "Terran", "Zerg"), []string{"enUS", "enGB", "frFR", "esES", "esMX", "itIT"}},
+		{"english us", newRep("Magma Mines", 1, "Terran", "Zerg"), []string{"enUS", "enGB", "esES", "esMX", "frFR", "itIT"}},
+		{"mixed", newRep("Magma Mines", 2, "Terraner", "테란"), nil},
+		{"unknown", newRep("Magma Mines", 2, "Elf"), nil},
+	}
+	for _, c := range cases {
+		if got := c.r.Locales(); !reflect.DeepEqual(got, c.exp) {
+			t.Errorf("[%s] Expected: %v, got: %v", c.name, c.exp, got)
+		}
+	}
+	if loc := newRep("", 2, "Terrani").Locale(); loc != "plPL" {
+		t.Errorf("Expected: %s, got: %s", "plPL", loc)
+	}
+	if loc := newRep("", 2).Locale(); loc != "" {
+		t.Errorf("Expected no locale, got: %s", loc)
+	}
+
+	RegisterLocalRaceNames(LocalRaceNames{Locale: "xxXX", Terran: []string{"Teranno"}, Zerg: []string{"Zerg"}})
+	if race := raceFromLocalString("Teranno"); race != RaceTerran {
+		t.Errorf("Expected: %v, got: %v", RaceTerran, race)
+	}
+	if locales := newRep("", 2, "Teranno", "Zerg").Locales(); !reflect.DeepEqual(locales, []string{"xxXX"}) {
+		t.Errorf("Expected: %v, got: %v", []string{"xxXX"}, locales)
+	}
+}
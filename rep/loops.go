@@ -0,0 +1,78 @@
+/*
+
+Game loop math: conversions between game loops, game time and real time.
+
+*/
+
+package rep
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// LoopsPerSecondGame is the number of game loops per game second.
+// A game second equals a real second at Normal game speed.
+const LoopsPerSecondGame = 16
+
+// LoopsPerSecond returns the number of game loops per real (wall clock) second at the given game speed,
+// e.g. 22.4 at Faster.
+func LoopsPerSecond(speed *GameSpeed) float64 {
+	return LoopsPerSecondGame * speed.Factor()
+}
+
+// ClockFactor returns the factor of the in-game clock of the given expansion level compared to game time.
+// Since LotV the in-game clock shows real time, so the factor of the game speed is returned;
+// before that the in-game clock showed game time, so 1 is returned.
+// Unknown expansion level is treated as LotV.
+func ClockFactor(speed *GameSpeed, exp *ExpLevel) float64 {
+	if exp == ExpLevelWoL || exp == ExpLevelHotS {
+		return 1
+	}
+	return speed.Factor()
+}
+
+// LoopsToDuration converts a game loop count to game time.
+func LoopsToDuration(loops int64) time.Duration {
+	// 1 second = 16 loops => 1 loop = 1/16 second = 62,500,000 ns
+	return time.Duration(loops * int64(time.Second/LoopsPerSecondGame))
+}
+
+// DurationToLoops converts game time to game loop count (truncated).
+func DurationToLoops(d time.Duration) int64 {
+	return int64(d / (time.Second / LoopsPerSecondGame))
+}
+
+// LoopsToRealDuration converts a game loop count to real (wall clock) duration at the given game speed.
+func LoopsToRealDuration(loops int64, speed *GameSpeed) time.Duration {
+	return time.Duration(float64(loops) * float64(time.Second) / LoopsPerSecond(speed))
+}
+
+// RealDurationToLoops converts real (wall clock) duration to game loop count (truncated) at the given game speed.
+func RealDurationToLoops(d time.Duration, speed *GameSpeed) int64 {
+	return int64(d.Seconds() * LoopsPerSecond(speed))
+}
+
+// GameTime is a game time (see LoopsToDuration).
+type GameTime time.Duration
+
+// GameTimeOf returns the game time of a game loop.
+func GameTimeOf(loop int64) GameTime {
+	return GameTime(LoopsToDuration(loop))
+}
+
+// Loops returns the game loop of the game time.
+func (t GameTime) Loops() int64 {
+	return DurationToLoops(time.Duration(t))
+}
+
+// String returns the game time in the form of "mm:ss" (minutes are not capped at 59),
+// e.g. "12:05". Negative game times are prefixed with "-".
+func (t GameTime) String() string {
+	sign, secs := "", int64(math.Floor(time.Duration(t).Seconds()))
+	if t < 0 {
+		sign, secs = "-", int64(math.Floor(-time.Duration(t).Seconds()))
+	}
+	return fmt.Sprintf("%s%02d:%02d", sign, secs/60, secs%60)
+}
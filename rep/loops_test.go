@@ -0,0 +1,61 @@
+package rep
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLoopConversions(t *testing.T) {
+	if lps := LoopsPerSecond(GameSpeedFaster); lps != 22.4 {
+		t.Errorf("Expected: %v, got: %v", 22.4, lps)
+	}
+	if d := LoopsToDuration(1344); d != 84*time.Second {
+		t.Errorf("Expected: %v, got: %v", 84*time.Second, d)
+	}
+	if loops := DurationToLoops(84 * time.Second); loops != 1344 {
+		t.Errorf("Expected: %d, got: %d", 1344, loops)
+	}
+	if d := LoopsToRealDuration(1344, GameSpeedFaster); d != time.Minute {
+		t.Errorf("Expected: %v, got: %v", time.Minute, d)
+	}
+	if loops := RealDurationToLoops(time.Minute, GameSpeedFaster); loops != 1344 {
+		t.Errorf("Expected: %d, got: %d", 1344, loops)
+	}
+
+	cases := []struct {
+		speed *GameSpeed
+		exp   *ExpLevel
+		f     float64
+	}{
+		{GameSpeedFaster, ExpLevelLotV, 1.4},
+		{GameSpeedFaster, ExpLevelHotS, 1},
+		{GameSpeedNormal, ExpLevelUnknown, 1},
+	}
+	for i, c := range cases {
+		if f := ClockFactor(c.speed, c.exp); f != c.f {
+			t.Errorf("[%d] Expected: %v, got: %v", i, c.f, f)
+		}
+	}
+}
+
+func TestGameTime(t *testing.T) {
+	cases := []struct {
+		loop int64
+		s    string
+	}{
+		{0, "00:00"},
+		{15, "00:00"},
+		{16*65 + 8, "01:05"},
+		{16 * 3600 * 2, "120:00"},
+		{-16 * 61, "-01:01"},
+	}
+	for _, c := range cases {
+		gt := GameTimeOf(c.loop)
+		if s := gt.String(); s != c.s {
+			t.Errorf("[%d] Expected: %s, got: %s", c.loop, c.s, s)
+		}
+		if loops := gt.Loops(); loops != c.loop {
+			t.Errorf("[%d] Expected loops: %d, got: %d", c.loop, c.loop, loops)
+		}
+	}
+}
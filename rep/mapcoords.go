@@ -0,0 +1,103 @@
+/*
+
+Coordinate transformations between tracker event, game event and minimap space.
+
+*/
+
+package rep
+
+// Scales of fixed-point coordinates in game events.
+const (
+	// TargetPointScale is the scale of target points (e.g. Cmd target points): map coordinate = value / 4096.
+	TargetPointScale = 4096
+
+	// CameraPointScale is the scale of camera target points: map coordinate = value / 256.
+	CameraPointScale = 256
+
+	// UnitPositionsScale is the scale of unit positions in UnitPositions tracker events: map coordinate = value * 4.
+	UnitPositionsScale = 4
+)
+
+// Point is a point in map space, coordinates are in map cells.
+// The origin is the bottom-left corner of the map, Y grows upward.
+type Point struct {
+	X, Y, Z float64
+}
+
+// TrackerPoint returns the point of x and y coordinates of tracker events (e.g. UnitBorn, UnitInit).
+func TrackerPoint(x, y int64) Point {
+	return Point{X: float64(x), Y: float64(y)}
+}
+
+// UnitPositionsPoint returns the point of x and y coordinates of UnitPositions tracker events.
+func UnitPositionsPoint(x, y int64) Point {
+	return Point{X: float64(x * UnitPositionsScale), Y: float64(y * UnitPositionsScale)}
+}
+
+// TargetPoint returns the point of fixed-point x, y and z coordinates of target points of game events (e.g. Cmd).
+func TargetPoint(x, y, z int64) Point {
+	return Point{X: float64(x) / TargetPointScale, Y: float64(y) / TargetPointScale, Z: float64(z) / TargetPointScale}
+}
+
+// CameraPoint returns the point of fixed-point x and y coordinates of camera targets (CameraUpdate game events).
+func CameraPoint(x, y int64) Point {
+	return Point{X: float64(x) / CameraPointScale, Y: float64(y) / CameraPointScale}
+}
+
+// Area is a rectangle area in map space.
+type Area struct {
+	Left, Bottom, Right, Top float64
+}
+
+// Width returns the width of the area.
+func (a Area) Width() float64 {
+	return a.Right - a.Left
+}
+
+// Height returns the height of the area.
+func (a Area) Height() float64 {
+	return a.Top - a.Bottom
+}
+
+// MapCoords converts map space points into normalized and minimap coordinates.
+type MapCoords struct {
+	// Width and Height of the map
+	Width, Height float64
+
+	// PlayableArea is the playable area of the map.
+	// By default it's the whole map, a more accurate area may be set
+	// from the map file (see the s2map package).
+	PlayableArea Area
+}
+
+// NewMapCoords creates a new MapCoords using the map size of the game description.
+func NewMapCoords(gd *GameDescription) *MapCoords {
+	w, h := float64(gd.MapSizeX()), float64(gd.MapSizeY())
+	return &MapCoords{
+		Width:        w,
+		Height:       h,
+		PlayableArea: Area{Right: w, Top: h},
+	}
+}
+
+// Normalize returns the coordinates of the point relative to the playable area,
+// in the range of 0..1 for points inside the playable area.
+// The origin is the bottom-left corner, Y grows upward.
+func (mc *MapCoords) Normalize(p Point) (nx, ny float64) {
+	pa := &mc.PlayableArea
+	if w := pa.Width(); w > 0 {
+		nx = (p.X - pa.Left) / w
+	}
+	if h := pa.Height(); h > 0 {
+		ny = (p.Y - pa.Bottom) / h
+	}
+	return
+}
+
+// Minimap returns the pixel coordinates of the point on a minimap image of the specified size
+// which covers the playable area.
+// The origin of the image is the top-left corner, Y grows downward.
+func (mc *MapCoords) Minimap(p Point, width, height int) (px, py int) {
+	nx, ny := mc.Normalize(p)
+	return int(nx * float64(width)), int((1 - ny) * float64(height))
+}
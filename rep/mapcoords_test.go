@@ -0,0 +1,78 @@
+package rep
+
+import (
+	"testing"
+
+	"github.com/icza/s2prot"
+)
+
+func TestPointConversions(t *testing.T) {
+	cases := []struct {
+		name     string
+		got, exp Point
+	}{
+		{"tracker", TrackerPoint(40, 120), Point{40, 120, 0}},
+		{"unit positions", UnitPositionsPoint(10, 30), Point{40, 120, 0}},
+		{"target", TargetPoint(40*4096+2048, 120*4096, 8*4096), Point{40.5, 120, 8}},
+		{"camera", CameraPoint(40*256+64, 120*256), Point{40.25, 120, 0}},
+	}
+	for _, c := range cases {
+		if c.got != c.exp {
+			t.Errorf("[%s] Expected: %v, got: %v", c.name, c.exp, c.got)
+		}
+	}
+}
+
+func TestMapCoords(t *testing.T) {
+	gd := GameDescription{Struct: s2prot.Struct{"mapSizeX": int64(200), "mapSizeY": int64(100)}}
+	mc := NewMapCoords(&gd)
+
+	if nx, ny := mc.Normalize(Point{X: 50, Y: 25}); nx != 0.25 || ny != 0.25 {
+		t.Errorf("Unexpected normalized coordinates: %v, %v", nx, ny)
+	}
+	if px, py := mc.Minimap(Point{X: 50, Y: 25}, 400, 200); px != 100 || py != 150 {
+		t.Errorf("Unexpected minimap coordinates: %v, %v", px, py)
+	}
+
+	mc.PlayableArea = Area{Left: 20, Bottom: 10, Right: 180, Top: 90}
+	if nx, ny := mc.Normalize(Point{X: 100, Y: 10}); nx != 0.5 || ny != 0 {
+		t.Errorf("Unexpected normalized coordinates: %v, %v", nx, ny)
+	}
+	if px, py := mc.Minimap(Point{X: 100, Y: 90}, 160, 80); px != 80 || py != 0 {
+		t.Errorf("Unexpected minimap coordinates: %v, %v", px, py)
+	}
+
+	if nx, ny := (&MapCoords{}).Normalize(Point{X: 1, Y: 1}); nx != 0 || ny != 0 {
+		t.Errorf("Expected zero coordinates for empty playable area, got: %v, %v", nx, ny)
+	}
+}
+
+func TestEvtPoint(t *testing.T) {
+	tp := s2prot.Struct{"x": int64(40 * 4096), "y": int64(120 * 4096), "z": int64(8 * 4096)}
+	tpExp := Point{40, 120, 8}
+
+	cases := []struct {
+		name string
+		s    s2prot.Struct
+		ok   bool
+		exp  Point
+	}{
+		{"Cmd", s2prot.Struct{"data": s2prot.Struct{"TargetPoint": tp}}, true, tpExp},
+		{"Cmd", s2prot.Struct{"data": s2prot.Struct{"TargetUnit": s2prot.Struct{"snapshotPoint": tp}}}, true, tpExp},
+		{"Cmd", s2prot.Struct{"data": s2prot.Struct{"None": nil}}, false, Point{}},
+		{"CmdUpdateTargetPoint", s2prot.Struct{"target": tp}, true, tpExp},
+		{"CmdUpdateTargetUnit", s2prot.Struct{"target": s2prot.Struct{"snapshotPoint": tp}}, true, tpExp},
+		{"CameraUpdate", s2prot.Struct{"target": s2prot.Struct{"x": int64(40 * 256), "y": int64(120 * 256)}}, true, Point{40, 120, 0}},
+		{"CameraUpdate", s2prot.Struct{"target": nil}, false, Point{}},
+		{"TriggerPing", s2prot.Struct{"point": s2prot.Struct{"x": int64(4096), "y": int64(2048)}}, true, Point{1, 0.5, 0}},
+		{"SelectionDelta", s2prot.Struct{}, false, Point{}},
+	}
+
+	for _, c := range cases {
+		e := gameEvt(0, 0, c.name, c.s)
+		p, ok := EvtPoint(e)
+		if ok != c.ok || p != c.exp {
+			t.Errorf("[%s] Expected: %v, %v, got: %v, %v", c.name, c.exp, c.ok, p, ok)
+		}
+	}
+}
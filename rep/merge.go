@@ -0,0 +1,79 @@
+/*
+
+Merged iteration of the game, message and tracker event streams in loop order.
+
+*/
+
+package rep
+
+import "github.com/icza/s2prot"
+
+// MergedEvt is an event of the merged event streams.
+type MergedEvt struct {
+	s2prot.Event
+
+	Kind EvtKind // Kind of the event (the stream it comes from)
+	Loop int64   // Loop of the event on the game event timeline (tracker loop offset applied)
+}
+
+// EvtIterator iterates over the game, message and tracker events merged into a single stream ordered by loop.
+//
+// Tracker event loops are shifted by the tracker loop offset (see SetTrackerLoopOffset),
+// so they are ordered on the timeline of the game events.
+//
+// Ties are broken stably: events of the same loop are iterated in the order of their kinds
+// (game events first, tracker events last), events of the same stream in their original order.
+type EvtIterator struct {
+	streams [3][]s2prot.Event // Event streams, index is the EvtKind
+	offsets [3]int64          // Loop offsets of the streams, index is the EvtKind
+	pos     [3]int            // Positions of the next events of the streams
+}
+
+// NewEvtIterator returns a new EvtIterator over the specified events.
+// Events of each stream must be in loop order (as decoded); any of the streams may be nil.
+func NewEvtIterator(gameEvts, messageEvts, trackerEvts []s2prot.Event) *EvtIterator {
+	return &EvtIterator{streams: [3][]s2prot.Event{gameEvts, messageEvts, trackerEvts}}
+}
+
+// EvtIterator returns a new EvtIterator over the decoded events of the replay.
+// Streams that were not decoded are skipped.
+func (r *Rep) EvtIterator() *EvtIterator {
+	var trackerEvts []s2prot.Event
+	if r.TrackerEvts != nil {
+		trackerEvts = r.TrackerEvts.Evts
+	}
+	return NewEvtIterator(r.GameEvts, r.MessageEvts, trackerEvts)
+}
+
+// SetTrackerLoopOffset sets the offset (in loops) added to the loops of tracker events,
+// converting them to the game event timeline. It must be called before starting the iteration.
+func (it *EvtIterator) SetTrackerLoopOffset(offset int64) {
+	it.offsets[EvtKindTracker] = offset
+}
+
+// Next returns the next event, ok is false if there are no more events.
+func (it *EvtIterator) Next() (me MergedEvt, ok bool) {
+	kind := EvtKind(-1)
+	var loop int64
+	for k, evts := range it.streams {
+		if it.pos[k] < len(evts) {
+			if l := evts[it.pos[k]].Loop() + it.offsets[k]; kind < 0 || l < loop {
+				kind, loop = EvtKind(k), l
+			}
+		}
+	}
+	if kind < 0 {
+		return
+	}
+
+	me = MergedEvt{Event: it.streams[kind][it.pos[kind]], Kind: kind, Loop: loop}
+	it.pos[kind]++
+	return me, true
+}
+
+// Each calls fn for each remaining event.
+func (it *EvtIterator) Each(fn func(me MergedEvt)) {
+	for me, ok := it.Next(); ok; me, ok = it.Next() {
+		fn(me)
+	}
+}
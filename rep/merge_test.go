@@ -0,0 +1,49 @@
+package rep
+
+import (
+	"testing"
+
+	"github.com/icza/s2prot"
+)
+
+func TestEvtIterator(t *testing.T) {
+	gameEvts := []s2prot.Event{gameEvt(0, 0, "g1", nil), gameEvt(5, 0, "g2", nil), gameEvt(5, 0, "g3", nil), gameEvt(12, 0, "g4", nil)}
+	messageEvts := []s2prot.Event{gameEvt(5, 0, "m1", nil)}
+	trackerEvts := []s2prot.Event{trackerEvt(0, "t1", nil), trackerEvt(3, "t2", nil), trackerEvt(10, "t3", nil)}
+
+	cases := []struct {
+		offset int64
+		exp    []string
+		loops  []int64
+	}{
+		{0,
+			[]string{"g1", "t1", "t2", "g2", "g3", "m1", "t3", "g4"},
+			[]int64{0, 0, 3, 5, 5, 5, 10, 12}},
+		{2,
+			[]string{"g1", "t1", "g2", "g3", "m1", "t2", "g4", "t3"},
+			[]int64{0, 2, 5, 5, 5, 5, 12, 12}},
+	}
+	for _, c := range cases {
+		it := NewEvtIterator(gameEvts, messageEvts, trackerEvts)
+		it.SetTrackerLoopOffset(c.offset)
+		var names []string
+		var loops []int64
+		it.Each(func(me MergedEvt) {
+			names = append(names, me.Name)
+			loops = append(loops, me.Loop)
+		})
+		if len(names) != len(c.exp) {
+			t.Fatalf("[offset %d] Expected: %v, got: %v", c.offset, c.exp, names)
+		}
+		for i := range names {
+			if names[i] != c.exp[i] || loops[i] != c.loops[i] {
+				t.Errorf("[offset %d] Expected: %v %v, got: %v %v", c.offset, c.exp, c.loops, names, loops)
+				break
+			}
+		}
+	}
+
+	if _, ok := (&Rep{}).EvtIterator().Next(); ok {
+		t.Error("Expected no events!")
+	}
+}
@@ -12,7 +12,8 @@ import "github.com/icza/s2prot"
 type Metadata struct {
 	s2prot.Struct
 
-	players []MetaPlayer // Lazily initialized meta players
+	players     []MetaPlayer // Lazily initialized meta players
+	synthesized bool         // Tells if the metadata was synthesized
 }
 
 // Title returns the map name.
@@ -64,8 +65,8 @@ type MetaPlayer struct {
 
 // PlayerID returns the player ID.
 func (m *MetaPlayer) PlayerID() int64 {
-	// the metadata PlayerID is a float for some reason
-	return int64(m.Float("PlayerID"))
+	// the metadata PlayerID is a float (as all JSON numbers)
+	return int64(m.Number("PlayerID"))
 }
 
 // MMR returns the player's (race-specific) MMR value.
@@ -73,6 +74,12 @@ func (m *MetaPlayer) MMR() float64 {
 	return m.Float("MMR")
 }
 
+// LookupMMR returns the player's (race-specific) MMR value.
+// ok is false if the MMR is missing, which is the case e.g. for non-ladder games.
+func (m *MetaPlayer) LookupMMR() (mmr float64, ok bool) {
+	return m.LookupFloat("MMR")
+}
+
 // APM returns the player's APM value.
 func (m *MetaPlayer) APM() float64 {
 	return m.Float("APM")
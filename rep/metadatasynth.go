@@ -0,0 +1,78 @@
+/*
+
+Synthesizing game metadata for replays that do not contain it.
+
+*/
+
+package rep
+
+import (
+	"fmt"
+
+	"github.com/icza/s2prot"
+)
+
+// Synthesized tells if the metadata was synthesized from other sections of the replay
+// because the replay does not contain game metadata (it was added around 3.7).
+// Synthesized metadata does not contain MMR values.
+func (m *Metadata) Synthesized() bool {
+	return m.synthesized
+}
+
+// synthesizeMetadata synthesizes game metadata from the header, details and game events (if decoded),
+// using the same keys and value types as the game metadata of newer replays.
+func synthesizeMetadata(r *Rep) Metadata {
+	apms, results := r.apms(), r.PlayerResults()
+
+	mps := make([]interface{}, len(results))
+	for i := range results {
+		result := "Undecided"
+		switch results[i] {
+		case ResultVictory:
+			result = "Win"
+		case ResultDefeat:
+			result = "Loss"
+		}
+
+		mp := map[string]interface{}{
+			"PlayerID":     float64(i + 1),
+			"Result":       result,
+			"SelectedRace": metaRaceValue(r.PlayerSelectedRace(i)),
+			"AssignedRace": metaRaceValue(r.PlayerAssignedRace(i)),
+		}
+		if apm, ok := apms[i]; ok {
+			mp["APM"] = apm
+		}
+		mps[i] = mp
+	}
+
+	h := &r.Header
+	dataBuild := h.DataBuildNum()
+	if dataBuild == 0 {
+		v := h.Version()
+		dataBuild = v.Build()
+	}
+
+	return Metadata{
+		Struct: s2prot.Struct{
+			"Title":       r.Details.Title(),
+			"GameVersion": h.VersionString(),
+			"DataBuild":   fmt.Sprint(dataBuild),
+			"BaseBuild":   fmt.Sprint("Base", h.BaseBuild()),
+			"Duration":    float64(int64(r.RealDuration().Seconds())),
+			"Players":     mps,
+		},
+		synthesized: true,
+	}
+}
+
+// metaRaceValue returns the value of a race as used in the game metadata, e.g. "Prot" or "Rand".
+func metaRaceValue(race *Race) string {
+	switch race {
+	case RaceRandom:
+		return "Rand"
+	case RaceUnknown:
+		return ""
+	}
+	return race.attrValue
+}
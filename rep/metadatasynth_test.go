@@ -0,0 +1,38 @@
+package rep
+
+import (
+	"testing"
+
+	"github.com/icza/s2prot"
+)
+
+func TestSynthesizeMetadata(t *testing.T) {
+	r := newTestRep(
+		humanSlot(0, s2prot.Struct{"racePref": s2prot.Struct{"race": nil}}),
+		computerSlot(s2prot.Struct{"racePref": s2prot.Struct{"race": int64(1)}}),
+	)
+	r.Header = Header{Struct: s2prot.Struct{
+		"elapsedGameLoops": int64(16 * 84), // 1 real minute on Faster
+		"version":          s2prot.Struct{"major": int64(2), "minor": int64(1), "revision": int64(9), "build": int64(34644), "baseBuild": int64(32283)},
+	}}
+	r.Details.Struct["title"], r.Details.Struct["gameSpeed"] = "Test Map", int64(4)
+	setTestPlayers(r, s2prot.Struct{"race": "Terran", "result": int64(1)}, s2prot.Struct{"race": "Zerg", "result": int64(2)})
+	r.GameEvts = []s2prot.Event{gameEvt(0, 0, "Cmd", nil), gameEvt(0, 0, "SelectionDelta", nil), gameEvt(0, 0, "CameraUpdate", nil), gameEvt(0, 1, "Cmd", nil)}
+
+	m := synthesizeMetadata(r)
+	if !m.Synthesized() || m.Title() != "Test Map" || m.GameVersion() != "2.1.9.34644" || m.DataBuild() != "34644" ||
+		m.BaseBuild() != "Base32283" || m.DurationSec() != 60 {
+		t.Errorf("Unexpected metadata: %v", m.Struct)
+	}
+
+	mps := m.Players()
+	if len(mps) != 2 {
+		t.Fatalf("Expected 2 players, got: %d", len(mps))
+	}
+	if mp := mps[0]; mp.PlayerID() != 1 || mp.Result() != "Win" || mp.APM() != 2 || mp.SelectedRace() != "Rand" || mp.AssignedRace() != "Terr" {
+		t.Errorf("Unexpected player: %v", mp.Struct)
+	}
+	if mp := mps[1]; mp.PlayerID() != 2 || mp.Result() != "Loss" || mp.Has("APM") || mp.SelectedRace() != "Zerg" || mp.AssignedRace() != "Zerg" {
+		t.Errorf("Unexpected player: %v", mp.Struct)
+	}
+}
@@ -0,0 +1,74 @@
+/*
+
+Hook receiving metrics of replay parsing.
+
+*/
+
+package rep
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ParseStat describes a completed (successful or failed) replay parsing.
+type ParseStat struct {
+	BaseBuild int64         // Base build of the replay, 0 if the header could not be decoded
+	Duration  time.Duration // Duration of the parsing
+	Err       error         // Error of the parsing, nil if succeeded
+}
+
+// ParseMetrics is a hook receiving metrics of replay parsing, e.g. to monitor ingestion pipelines.
+// Implementations must be safe for concurrent use.
+type ParseMetrics interface {
+	// ParseDone is called when parsing a replay with any of the New... functions completes.
+	ParseDone(ps ParseStat)
+}
+
+var (
+	parseMetrics   ParseMetrics
+	parseMetricsMu sync.RWMutex // Protects parseMetrics
+)
+
+// SetParseMetrics sets the metrics hook of replay parsing, nil removes it.
+// It is safe for concurrent use.
+func SetParseMetrics(m ParseMetrics) {
+	parseMetricsMu.Lock()
+	defer parseMetricsMu.Unlock()
+	parseMetrics = m
+}
+
+// observeParse reports a completed parsing to the metrics hook (if set).
+func observeParse(start time.Time, baseBuild int64, err error) {
+	parseMetricsMu.RLock()
+	m := parseMetrics
+	parseMetricsMu.RUnlock()
+	if m != nil {
+		m.ParseDone(ParseStat{BaseBuild: baseBuild, Duration: time.Since(start), Err: err})
+	}
+}
+
+// Error types returned by ErrorType.
+const (
+	ErrTypeInvalidRepFile        = "invalid_file"
+	ErrTypeUnsupportedRepVersion = "unsupported_version"
+	ErrTypeDecoding              = "decoding"
+	ErrTypeOther                 = "other"
+)
+
+// ErrorType returns the type of a parsing error, suitable to be used as a metric label:
+// one of the ErrType... constants, empty string for a nil error.
+func ErrorType(err error) string {
+	switch {
+	case err == nil:
+		return ""
+	case errors.Is(err, ErrInvalidRepFile):
+		return ErrTypeInvalidRepFile
+	case errors.Is(err, ErrUnsupportedRepVersion):
+		return ErrTypeUnsupportedRepVersion
+	case errors.Is(err, ErrDecoding):
+		return ErrTypeDecoding
+	}
+	return ErrTypeOther
+}
@@ -0,0 +1,134 @@
+/*
+Package metrics implements a Prometheus adapter for the replay parsing metrics hook (see rep.SetParseMetrics).
+
+The adapter collects parse counters, error counters by error type (see rep.ErrorType),
+a histogram of parse durations and the number of parsed replays by base build (protocol version),
+and exposes them in the Prometheus text exposition format. It does not depend on the Prometheus
+client library: mount it as an HTTP handler on the metrics endpoint to be scraped.
+
+Example:
+
+	p := metrics.NewPrometheus(nil)
+	rep.SetParseMetrics(p)
+	http.Handle("/metrics", p)
+*/
+package metrics
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/icza/s2prot/rep"
+)
+
+// DefBuckets are the default upper bounds of the parse duration histogram buckets, in seconds.
+var DefBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// Prometheus is a rep.ParseMetrics implementation exposing the metrics in the Prometheus text exposition format.
+// It is safe for concurrent use.
+type Prometheus struct {
+	buckets []float64 // Upper bounds of the duration histogram buckets, in seconds
+
+	mu           sync.Mutex        // Protects the fields below
+	parses       uint64            // Number of parses
+	bucketCounts []uint64          // Number of parses in the duration buckets (not cumulative)
+	durationSum  float64           // Sum of the parse durations, in seconds
+	errs         map[string]uint64 // Number of errors by error type
+	baseBuilds   map[int64]uint64  // Number of parses by base build
+}
+
+// NewPrometheus returns a new Prometheus with the given upper bounds of the parse duration histogram buckets
+// (in seconds, in increasing order). If buckets is nil, DefBuckets is used.
+func NewPrometheus(buckets []float64) *Prometheus {
+	if buckets == nil {
+		buckets = DefBuckets
+	}
+	return &Prometheus{
+		buckets:      buckets,
+		bucketCounts: make([]uint64, len(buckets)+1), // Last one is the +Inf bucket
+		errs:         map[string]uint64{},
+		baseBuilds:   map[int64]uint64{},
+	}
+}
+
+// ParseDone implements rep.ParseMetrics.
+func (p *Prometheus) ParseDone(ps rep.ParseStat) {
+	secs := ps.Duration.Seconds()
+	bucket := sort.SearchFloat64s(p.buckets, secs)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.parses++
+	p.bucketCounts[bucket]++
+	p.durationSum += secs
+	if ps.Err != nil {
+		p.errs[rep.ErrorType(ps.Err)]++
+	}
+	if ps.BaseBuild != 0 {
+		p.baseBuilds[ps.BaseBuild]++
+	}
+}
+
+// Write writes the metrics in the Prometheus text exposition format.
+func (p *Prometheus) Write(w io.Writer) error {
+	buf := &bytes.Buffer{}
+
+	p.mu.Lock()
+
+	fmt.Fprintln(buf, "# HELP s2prot_parses_total Number of parsed replays (including failed parses).")
+	fmt.Fprintln(buf, "# TYPE s2prot_parses_total counter")
+	fmt.Fprintln(buf, "s2prot_parses_total", p.parses)
+
+	fmt.Fprintln(buf, "# HELP s2prot_parse_errors_total Number of failed parses by error type.")
+	fmt.Fprintln(buf, "# TYPE s2prot_parse_errors_total counter")
+	errTypes := make([]string, 0, len(p.errs))
+	for errType := range p.errs {
+		errTypes = append(errTypes, errType)
+	}
+	sort.Strings(errTypes)
+	for _, errType := range errTypes {
+		fmt.Fprintf(buf, "s2prot_parse_errors_total{type=%q} %d\n", errType, p.errs[errType])
+	}
+
+	fmt.Fprintln(buf, "# HELP s2prot_parse_duration_seconds Duration of parsing replays.")
+	fmt.Fprintln(buf, "# TYPE s2prot_parse_duration_seconds histogram")
+	var cumulative uint64
+	for i, count := range p.bucketCounts {
+		cumulative += count
+		le := "+Inf"
+		if i < len(p.buckets) {
+			le = strconv.FormatFloat(p.buckets[i], 'g', -1, 64)
+		}
+		fmt.Fprintf(buf, "s2prot_parse_duration_seconds_bucket{le=%q} %d\n", le, cumulative)
+	}
+	fmt.Fprintln(buf, "s2prot_parse_duration_seconds_sum", strconv.FormatFloat(p.durationSum, 'g', -1, 64))
+	fmt.Fprintln(buf, "s2prot_parse_duration_seconds_count", p.parses)
+
+	fmt.Fprintln(buf, "# HELP s2prot_parses_by_base_build_total Number of parsed replays by base build (protocol version).")
+	fmt.Fprintln(buf, "# TYPE s2prot_parses_by_base_build_total counter")
+	baseBuilds := make([]int64, 0, len(p.baseBuilds))
+	for bb := range p.baseBuilds {
+		baseBuilds = append(baseBuilds, bb)
+	}
+	sort.Slice(baseBuilds, func(i, j int) bool { return baseBuilds[i] < baseBuilds[j] })
+	for _, bb := range baseBuilds {
+		fmt.Fprintf(buf, "s2prot_parses_by_base_build_total{base_build=\"%d\"} %d\n", bb, p.baseBuilds[bb])
+	}
+
+	p.mu.Unlock()
+
+	_, err := buf.WriteTo(w)
+	return err
+}
+
+// ServeHTTP implements http.Handler, serving the metrics in the Prometheus text exposition format.
+func (p *Prometheus) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	p.Write(w)
+}
@@ -0,0 +1,75 @@
+package metrics
+
+import (
+	"bytes"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/icza/s2prot/rep"
+	"github.com/icza/s2prot/rep/reptest"
+)
+
+func TestPrometheus(t *testing.T) {
+	p := NewPrometheus([]float64{0.1, 1})
+	p.ParseDone(rep.ParseStat{BaseBuild: 80949, Duration: 50 * time.Millisecond})
+	p.ParseDone(rep.ParseStat{BaseBuild: 80949, Duration: 500 * time.Millisecond})
+	p.ParseDone(rep.ParseStat{BaseBuild: 99999, Duration: 2 * time.Second, Err: rep.ErrUnsupportedRepVersion})
+	p.ParseDone(rep.ParseStat{Err: &rep.SectionError{Section: "replay.details", Err: rep.ErrInvalidRepFile}})
+
+	rec := httptest.NewRecorder()
+	p.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	out := rec.Body.String()
+
+	for _, exp := range []string{
+		"s2prot_parses_total 4\n",
+		`s2prot_parse_errors_total{type="invalid_file"} 1` + "\n",
+		`s2prot_parse_errors_total{type="unsupported_version"} 1` + "\n",
+		`s2prot_parse_duration_seconds_bucket{le="0.1"} 2` + "\n",
+		`s2prot_parse_duration_seconds_bucket{le="1"} 3` + "\n",
+		`s2prot_parse_duration_seconds_bucket{le="+Inf"} 4` + "\n",
+		"s2prot_parse_duration_seconds_sum 2.55\n",
+		"s2prot_parse_duration_seconds_count 4\n",
+		`s2prot_parses_by_base_build_total{base_build="80949"} 2` + "\n",
+		`s2prot_parses_by_base_build_total{base_build="99999"} 1` + "\n",
+	} {
+		if !strings.Contains(out, exp) {
+			t.Errorf("Expected output to contain %q, got:\n%s", exp, out)
+		}
+	}
+}
+
+func TestParseHook(t *testing.T) {
+	p := NewPrometheus(nil)
+	rep.SetParseMetrics(p)
+	defer rep.SetParseMetrics(nil)
+
+	data, err := reptest.New(80949).Bytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := rep.New(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Close()
+	if _, err := rep.New(bytes.NewReader([]byte("invalid"))); err == nil {
+		t.Error("Expected error")
+	}
+
+	buf := &bytes.Buffer{}
+	if err := p.Write(buf); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, exp := range []string{
+		"s2prot_parses_total 2\n",
+		`s2prot_parse_errors_total{type="invalid_file"} 1` + "\n",
+		`s2prot_parses_by_base_build_total{base_build="80949"} 1` + "\n",
+	} {
+		if !strings.Contains(out, exp) {
+			t.Errorf("Expected output to contain %q, got:\n%s", exp, out)
+		}
+	}
+}
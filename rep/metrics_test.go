@@ -0,0 +1,25 @@
+package rep
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestErrorType(t *testing.T) {
+	cases := []struct {
+		err error
+		exp string
+	}{
+		{nil, ""},
+		{ErrInvalidRepFile, ErrTypeInvalidRepFile},
+		{secDetails.err(), ErrTypeInvalidRepFile},
+		{ErrUnsupportedRepVersion, ErrTypeUnsupportedRepVersion},
+		{ErrDecoding, ErrTypeDecoding},
+		{errors.New("other"), ErrTypeOther},
+	}
+	for _, c := range cases {
+		if got := ErrorType(c.err); got != c.exp {
+			t.Errorf("[%v] Expected: %q, got: %q", c.err, c.exp, got)
+		}
+	}
+}
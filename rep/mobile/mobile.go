@@ -0,0 +1,250 @@
+/*
+Package mobile is a simplified facade of the rep package suitable for gomobile binding,
+so Android and iOS replay apps can embed s2prot.
+
+The API only uses types supported by gomobile: strings, integers, floats, booleans, byte slices
+and pointers to structs having fields of these types. Lists are exposed with count and getter methods.
+Times are Unix timestamps in milliseconds, durations are in milliseconds.
+
+Generate the bindings with:
+
+	gomobile bind -target=android github.com/icza/s2prot/rep/mobile
+	gomobile bind -target=ios github.com/icza/s2prot/rep/mobile
+
+Example (Go):
+
+	s, err := mobile.ParseSummary(data)
+	if err != nil {
+		// Handle error
+	}
+	for i := 0; i < s.PlayerCount(); i++ {
+		p := s.Player(i)
+		fmt.Println(p.Name, p.Race, p.Result)
+	}
+*/
+package mobile
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/icza/s2prot/rep"
+)
+
+var (
+	// ErrInvalidPlayerIdx is returned if the player index is out of range.
+	ErrInvalidPlayerIdx = errors.New("Invalid player index")
+
+	// ErrNoTrackerEvts is returned if the replay has no tracker events.
+	ErrNoTrackerEvts = errors.New("Tracker events are not available")
+)
+
+// Replay is a parsed replay, for apps needing multiple pieces of information of a replay
+// without parsing it multiple times. It must be closed with Close.
+type Replay struct {
+	r *rep.Rep
+}
+
+// Parse parses the replay file content.
+func Parse(data []byte) (*Replay, error) {
+	r, err := rep.NewEvts(bytes.NewReader(data), true, false, true)
+	if err != nil {
+		return nil, err
+	}
+	return &Replay{r: r}, nil
+}
+
+// Summary returns the summary of the replay.
+func (rp *Replay) Summary() *Summary {
+	return summaryOf(rp.r.Summary())
+}
+
+// BuildOrder returns the build order of the player specified by its index (as in Summary),
+// up to the given real time in milliseconds (use 0 for the whole game).
+func (rp *Replay) BuildOrder(playerIdx int, untilMs int64) (*BuildOrder, error) {
+	return buildOrderOf(rp.r, playerIdx, time.Duration(untilMs)*time.Millisecond)
+}
+
+// Close closes the replay.
+func (rp *Replay) Close() error {
+	return rp.r.Close()
+}
+
+// Summary is the summary of a replay, see rep.Summary.
+type Summary struct {
+	Map        string // Map name
+	Date       int64  // Date+time of the game, Unix timestamp in milliseconds
+	DurationMs int64  // Real (wall clock) duration of the game in milliseconds
+	Format     string // Game format, e.g. "1v1", "2v2", "FFA"
+	Matchup    string // Matchup, e.g. "PvT"
+	Region     string // 2-letter region code, e.g. "EU"
+	Expansion  string // Expansion level, e.g. "LotV"
+	Version    string // Public game version, e.g. "4.10.1"
+
+	players []Player
+}
+
+// Player is a player of the Summary, see rep.SummaryPlayer.
+type Player struct {
+	Name      string  // Name without clan tag
+	ClanTag   string  // Clan tag, empty string if the player has none
+	Toon      string  // Toon handle, empty string for computer players
+	Team      int64   // Team ID
+	Race      string  // Assigned race
+	WasRandom bool    // Tells if Random race was selected
+	Result    string  // Reconciled result, e.g. "Victory"
+	APM       float64 // APM
+	MMR       int64   // MMR, 0 if not available
+}
+
+// PlayerCount returns the number of players.
+func (s *Summary) PlayerCount() int {
+	return len(s.players)
+}
+
+// Player returns the player specified by its index, nil if the index is out of range.
+func (s *Summary) Player(idx int) *Player {
+	if idx < 0 || idx >= len(s.players) {
+		return nil
+	}
+	p := s.players[idx]
+	return &p
+}
+
+// ParseSummary parses the replay file content and returns its summary.
+func ParseSummary(data []byte) (*Summary, error) {
+	r, err := rep.NewEvts(bytes.NewReader(data), true, false, true)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	return summaryOf(r.Summary()), nil
+}
+
+// ParseSummaryFile parses the replay file and returns its summary.
+func ParseSummaryFile(name string) (*Summary, error) {
+	r, err := rep.NewFromFileEvts(name, true, false, true)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	return summaryOf(r.Summary()), nil
+}
+
+// SummaryJSON parses the replay file content and returns its summary as JSON (see rep.Summary).
+func SummaryJSON(data []byte) (string, error) {
+	r, err := rep.NewEvts(bytes.NewReader(data), true, false, true)
+	if err != nil {
+		return "", err
+	}
+	defer r.Close()
+
+	js, err := json.Marshal(r.Summary())
+	return string(js), err
+}
+
+// summaryOf converts a rep.Summary.
+func summaryOf(rs *rep.Summary) *Summary {
+	s := &Summary{
+		Map:        rs.Map,
+		Date:       millis(rs.Date),
+		DurationMs: rs.Duration.Milliseconds(),
+		Format:     rs.Format,
+		Matchup:    rs.Matchup,
+		Region:     rs.Region,
+		Expansion:  rs.Expansion,
+		Version:    rs.Version,
+		players:    make([]Player, len(rs.Players)),
+	}
+	for i, sp := range rs.Players {
+		s.players[i] = Player{
+			Name:      sp.Name,
+			ClanTag:   sp.ClanTag,
+			Toon:      sp.Toon,
+			Team:      sp.Team,
+			Race:      sp.Race,
+			WasRandom: sp.WasRandom,
+			Result:    sp.Result,
+			APM:       sp.APM,
+			MMR:       sp.MMR,
+		}
+	}
+	return s
+}
+
+// BuildOrder is the build order of a player, see rep.BuildOrder.
+type BuildOrder struct {
+	PlayerIdx int    // Index of the player
+	Race      string // Assigned race of the player
+	Label     string // Label of the build by the default classifier (e.g. "2-Base Blink"), empty if not classified
+
+	items []BuildOrderItem
+}
+
+// BuildOrderItem is an item of a build order, see rep.BuildOrderItem.
+type BuildOrderItem struct {
+	Loop    int64   // Game loop of the item
+	TimeMs  int64   // Real (wall clock) time of the item in milliseconds
+	Supply  float64 // Supply used by the player at the time of the item
+	Name    string  // Unit type name or upgrade name
+	Upgrade bool    // Tells if the item is an upgrade
+}
+
+// ItemCount returns the number of items.
+func (bo *BuildOrder) ItemCount() int {
+	return len(bo.items)
+}
+
+// Item returns the item specified by its index, nil if the index is out of range.
+func (bo *BuildOrder) Item(idx int) *BuildOrderItem {
+	if idx < 0 || idx >= len(bo.items) {
+		return nil
+	}
+	item := bo.items[idx]
+	return &item
+}
+
+// ParseBuildOrder parses the replay file content and returns the build order of the player specified
+// by its index (as in Summary), up to the given real time in milliseconds (use 0 for the whole game).
+func ParseBuildOrder(data []byte, playerIdx int, untilMs int64) (*BuildOrder, error) {
+	r, err := rep.NewEvts(bytes.NewReader(data), false, false, true)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	return buildOrderOf(r, playerIdx, time.Duration(untilMs)*time.Millisecond)
+}
+
+// buildOrderOf returns the build order of the player of the replay.
+func buildOrderOf(r *rep.Rep, playerIdx int, until time.Duration) (*BuildOrder, error) {
+	if playerIdx < 0 || playerIdx >= len(r.Details.Players()) {
+		return nil, ErrInvalidPlayerIdx
+	}
+	rbo := r.BuildOrder(playerIdx, until)
+	if rbo == nil {
+		return nil, ErrNoTrackerEvts
+	}
+
+	bo := &BuildOrder{PlayerIdx: playerIdx, Race: rbo.Race.String(), items: make([]BuildOrderItem, len(rbo.Items))}
+	bo.Label, _ = rep.DefaultBuildClassifier.Classify(rbo)
+	for i, item := range rbo.Items {
+		bo.items[i] = BuildOrderItem{
+			Loop:    item.Loop,
+			TimeMs:  item.Time.Milliseconds(),
+			Supply:  item.Supply,
+			Name:    item.Name,
+			Upgrade: item.Upgrade,
+		}
+	}
+	return bo, nil
+}
+
+// millis returns the Unix timestamp of t in milliseconds.
+func millis(t time.Time) int64 {
+	return t.UnixNano() / int64(time.Millisecond)
+}
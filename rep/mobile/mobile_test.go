@@ -0,0 +1,87 @@
+package mobile
+
+import (
+	"testing"
+
+	"github.com/icza/s2prot"
+	"github.com/icza/s2prot/rep/reptest"
+)
+
+func testReplay(t *testing.T) []byte {
+	r := reptest.New(80949)
+	r.Title = "Test Map"
+	born := func(loop, tagIndex int64, name string) {
+		r.TrackerEvt(loop, "UnitBorn", s2prot.Struct{
+			"unitTagIndex": tagIndex, "unitTagRecycle": int64(1), "unitTypeName": name,
+			"controlPlayerId": int64(1), "upkeepPlayerId": int64(1),
+		})
+	}
+	born(0, 1, "SCV")
+	born(672, 2, "Marine")
+	r.TrackerEvt(896, "Upgrade", s2prot.Struct{"playerId": int64(1), "upgradeTypeName": "Stimpack", "count": int64(1)})
+
+	data, err := r.Bytes()
+	if err != nil {
+		t.Fatalf("Failed to create replay: %v", err)
+	}
+	return data
+}
+
+func TestParse(t *testing.T) {
+	data := testReplay(t)
+
+	s, err := ParseSummary(data)
+	if err != nil {
+		t.Fatalf("Failed to parse summary: %v", err)
+	}
+	if s.Map != "Test Map" || s.DurationMs != 60000 || s.PlayerCount() != 2 {
+		t.Errorf("Unexpected summary: %+v", s)
+	}
+	if p := s.Player(0); p == nil || p.Name != "Player 1" || p.Race != "Terran" || p.Result != "Victory" {
+		t.Errorf("Unexpected player: %+v", p)
+	}
+	if p := s.Player(2); p != nil {
+		t.Errorf("Expected no player, got: %+v", p)
+	}
+
+	js, err := SummaryJSON(data)
+	if err != nil || len(js) == 0 || js[0] != '{' {
+		t.Errorf("Unexpected summary JSON: %s, %v", js, err)
+	}
+
+	bo, err := ParseBuildOrder(data, 0, 0)
+	if err != nil {
+		t.Fatalf("Failed to parse build order: %v", err)
+	}
+	if bo.Race != "Terran" || bo.ItemCount() != 2 || bo.Item(2) != nil {
+		t.Fatalf("Unexpected build order: %+v", bo)
+	}
+	if item := bo.Item(0); item.Name != "Marine" || item.Upgrade {
+		t.Errorf("Unexpected item: %+v", item)
+	}
+	if item := bo.Item(1); item.Name != "Stimpack" || !item.Upgrade || item.TimeMs != 40000 {
+		t.Errorf("Unexpected item: %+v", item)
+	}
+
+	if _, err := ParseBuildOrder(data, 5, 0); err != ErrInvalidPlayerIdx {
+		t.Errorf("Expected: %v, got: %v", ErrInvalidPlayerIdx, err)
+	}
+	if _, err := ParseSummary([]byte("invalid")); err == nil {
+		t.Errorf("Expected error for invalid replay")
+	}
+}
+
+func TestReplay(t *testing.T) {
+	rp, err := Parse(testReplay(t))
+	if err != nil {
+		t.Fatalf("Failed to parse replay: %v", err)
+	}
+	defer rp.Close()
+
+	if s := rp.Summary(); s.PlayerCount() != 2 {
+		t.Errorf("Expected: %d players, got: %d", 2, s.PlayerCount())
+	}
+	if bo, err := rp.BuildOrder(0, 35000); err != nil || bo.ItemCount() != 1 {
+		t.Errorf("Unexpected build order: %+v, %v", bo, err)
+	}
+}
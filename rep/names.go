@@ -0,0 +1,113 @@
+/*
+
+Clan tags and player name normalization.
+
+*/
+
+package rep
+
+import (
+	"strings"
+	"unicode"
+)
+
+// SplitClanTag splits a player name (as it appears in Details) into clan tag and bare name.
+//
+// Clan tags may be enclosed in square brackets (older replays) or angle brackets (newer replays,
+// possibly HTML escaped as "&lt;" and "&gt;"), and may be separated from the name by a "<sp/>" markup.
+// If the name has no clan tag, clanTag is empty and bareName is the name.
+func SplitClanTag(name string) (clanTag, bareName string) {
+	name = strings.Replace(name, "&lt;", "<", -1)
+	name = strings.Replace(name, "&gt;", ">", -1)
+
+	for _, br := range [...]string{"[]", "<>"} {
+		if !strings.HasPrefix(name, br[:1]) {
+			continue
+		}
+		if end := strings.Index(name, br[1:]); end > 0 && name[1:end] != "sp/" {
+			return name[1:end], strings.Replace(name[end+1:], "<sp/>", "", -1)
+		}
+	}
+
+	return "", strings.Replace(name, "<sp/>", "", -1)
+}
+
+// ClanTag returns the clan tag of the player parsed from the name, empty string if the player has none.
+func (p *Player) ClanTag() string {
+	clanTag, _ := SplitClanTag(p.Stringv("name"))
+	return clanTag
+}
+
+// BareName returns the name of the player without the clan tag.
+func (p *Player) BareName() string {
+	_, bareName := SplitClanTag(p.Stringv("name"))
+	return bareName
+}
+
+// PlayerClanTag returns the clan tag of the player specified by its index in Details.Players().
+// The clan tag of the user init data is preferred, falls back to the one parsed from the name.
+func (r *Rep) PlayerClanTag(playerIdx int) string {
+	if u := r.PlayerUser(playerIdx); u != nil && u.ClanTag() != "" {
+		return u.ClanTag()
+	}
+	players := r.Details.Players()
+	if playerIdx < 0 || playerIdx >= len(players) {
+		return ""
+	}
+	return players[playerIdx].ClanTag()
+}
+
+// lookalikes maps characters to the (lowercase latin) character they look like.
+var lookalikes = map[rune]rune{
+	'i': 'l', '1': 'l', '|': 'l', 'ı': 'l', 'ǀ': 'l', 'ӏ': 'l', 'і': 'l',
+	'0': 'o',
+	// Cyrillic
+	'а': 'a', 'в': 'b', 'е': 'e', 'ё': 'e', 'к': 'k', 'м': 'm', 'н': 'h', 'о': 'o', 'р': 'p',
+	'с': 'c', 'т': 't', 'у': 'y', 'х': 'x', 'ѕ': 's', 'ј': 'j', 'һ': 'h', 'ԁ': 'd', 'ԛ': 'q', 'ԝ': 'w',
+	// Greek
+	'α': 'a', 'β': 'b', 'ε': 'e', 'η': 'n', 'ι': 'l', 'κ': 'k', 'ν': 'v', 'ο': 'o', 'ρ': 'p',
+	'τ': 't', 'υ': 'u', 'χ': 'x',
+}
+
+// NormalizeName returns a normalized form of a player name which can be used to deduplicate players by name.
+//
+// The clan tag is removed, the name is lowercased, and characters that look alike
+// (e.g. 'i', 'l' and '1', or Cyrillic and Greek letters resembling Latin ones) are mapped
+// to the same character. Whitespace and control characters are removed.
+//
+// Barcode names (see IsBarcodeName()) are returned without the clan tag but otherwise unchanged,
+// as folding would make all barcode names of the same length equal.
+func NormalizeName(name string) string {
+	_, name = SplitClanTag(name)
+	if IsBarcodeName(name) {
+		return name
+	}
+
+	return strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) || unicode.IsControl(r) || unicode.Is(unicode.Cf, r) {
+			return -1
+		}
+		r = unicode.ToLower(r)
+		if l, ok := lookalikes[r]; ok {
+			return l
+		}
+		return r
+	}, name)
+}
+
+// IsBarcodeName tells if the name (without clan tag) is a "barcode" name,
+// a name consisting only of characters that look like vertical bars (e.g. "IlIlIlIl").
+func IsBarcodeName(name string) bool {
+	_, name = SplitClanTag(name)
+	if name == "" {
+		return false
+	}
+	for _, r := range name {
+		switch r {
+		case 'I', 'l', '|', '1', 'i':
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,64 @@
+package rep
+
+import "testing"
+
+func TestSplitClanTag(t *testing.T) {
+	cases := []struct {
+		name, clanTag, bareName string
+	}{
+		{"Pietra", "", "Pietra"},
+		{"[9KingS]<sp/>DakotaFannin", "9KingS", "DakotaFannin"},
+		{"[9KingS]DakotaFannin", "9KingS", "DakotaFannin"},
+		{"<NoGy><sp/>IMBarabba", "NoGy", "IMBarabba"},
+		{"&lt;NoGy&gt;<sp/>Nova", "NoGy", "Nova"},
+		{"<sp/>Foo", "", "Foo"},
+		{"[Foo", "", "[Foo"},
+	}
+
+	for _, c := range cases {
+		if clanTag, bareName := SplitClanTag(c.name); clanTag != c.clanTag || bareName != c.bareName {
+			t.Errorf("[%s] Expected: %q, %q, got: %q, %q", c.name, c.clanTag, c.bareName, clanTag, bareName)
+		}
+	}
+}
+
+func TestNormalizeName(t *testing.T) {
+	cases := []struct {
+		a, b  string
+		equal bool
+	}{
+		{"Foo", "foo", true},
+		{"[ABC]<sp/>Foo", "Foo", true},
+		{"IlIlIl", "lIlIlI", false},
+		{"IlIlIl", "lllIII", false},
+		{"IlIlIl", "IlIlIl", true},
+		{"[ABC]IlIl", "IlIl", true},
+		{"Ilidan", "lIidan", true},
+		{"Nоvа", "Nova", true}, // Cyrillic о and а
+		{"Foo", "Bar", false},
+	}
+
+	for _, c := range cases {
+		if equal := NormalizeName(c.a) == NormalizeName(c.b); equal != c.equal {
+			t.Errorf("[%s, %s] Expected equal: %v, got: %v (%q, %q)", c.a, c.b, c.equal, equal, NormalizeName(c.a), NormalizeName(c.b))
+		}
+	}
+}
+
+func TestIsBarcodeName(t *testing.T) {
+	cases := []struct {
+		name string
+		exp  bool
+	}{
+		{"IlIlIlIl", true},
+		{"[ABC]<sp/>lllIIl", true},
+		{"", false},
+		{"Illidan", false},
+	}
+
+	for _, c := range cases {
+		if got := IsBarcodeName(c.name); got != c.exp {
+			t.Errorf("[%s] Expected: %v, got: %v", c.name, c.exp, got)
+		}
+	}
+}
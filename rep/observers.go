@@ -0,0 +1,40 @@
+/*
+
+Observers (spectators and referees) of the game.
+
+*/
+
+package rep
+
+// Observer is a human user who was in the lobby as a spectator or referee.
+// Observers are not included in Details.Players().
+type Observer struct {
+	Name       string     // Name of the observer (without clan tag)
+	ClanTag    string     // Clan tag of the observer, empty string if the observer has none
+	ToonHandle ToonHandle // Toon handle of the observer, zero value if unknown
+	Observe    *Observe   // ObserveSpectator or ObserveReferee
+	SlotID     int        // Lobby slot of the observer (0-based)
+	UserID     int64      // User ID of the observer
+}
+
+// Observers returns the observers of the game: users who were in the lobby as spectators or referees.
+func (r *Rep) Observers() (obs []Observer) {
+	for i := range r.InitData.LobbyState.Slots {
+		s := &r.InitData.LobbyState.Slots[i]
+		if s.Control() != ControlHuman || (s.Observe() != ObserveSpectator && s.Observe() != ObserveReferee) {
+			continue
+		}
+
+		o := Observer{Observe: s.Observe(), SlotID: i, UserID: s.UserID()}
+		o.ToonHandle, _ = ParseToonHandle(s.ToonHandle())
+		if userID := s.UserID(); userID >= 0 && int(userID) < len(r.InitData.UserInitDatas) {
+			u := &r.InitData.UserInitDatas[userID]
+			o.Name, o.ClanTag = u.Name(), u.ClanTag()
+			if o.ToonHandle == (ToonHandle{}) {
+				o.ToonHandle, _ = ParseToonHandle(u.ToonHandle())
+			}
+		}
+		obs = append(obs, o)
+	}
+	return
+}
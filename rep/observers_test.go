@@ -0,0 +1,31 @@
+package rep
+
+import (
+	"testing"
+
+	"github.com/icza/s2prot"
+)
+
+func TestObservers(t *testing.T) {
+	r := newTestRep(
+		humanSlot(0, s2prot.Struct{"observe": int64(0), "toonHandle": "2-S2-1-1"}),
+		computerSlot(s2prot.Struct{"observe": int64(0)}),
+		humanSlot(1, s2prot.Struct{"observe": int64(1), "toonHandle": "2-S2-1-7"}),
+		humanSlot(2, s2prot.Struct{"observe": int64(2), "toonHandle": ""}),
+	)
+	setTestUsers(r, s2prot.Struct{"name": "Player"}, s2prot.Struct{"name": "Caster", "clanTag": "TV"}, s2prot.Struct{"name": "Ref", "toonHandle": "1-S2-1-42"})
+
+	exp := []Observer{
+		{Name: "Caster", ClanTag: "TV", ToonHandle: ToonHandle{2, "S2", 1, 7}, Observe: ObserveSpectator, SlotID: 2, UserID: 1},
+		{Name: "Ref", ToonHandle: ToonHandle{1, "S2", 1, 42}, Observe: ObserveReferee, SlotID: 3, UserID: 2},
+	}
+	obs := r.Observers()
+	if len(obs) != len(exp) {
+		t.Fatalf("Expected %d observers, got: %v", len(exp), obs)
+	}
+	for i := range exp {
+		if obs[i] != exp[i] {
+			t.Errorf("[%d] Expected: %+v, got: %+v", i, exp[i], obs[i])
+		}
+	}
+}
@@ -0,0 +1,106 @@
+/*
+
+Grouping of the effective commands into logical orders, and EPM (effective actions per minute) calculation.
+
+*/
+
+package rep
+
+import "github.com/icza/s2prot"
+
+// cmdFlagQueued is the bit of the "cmdFlags" field of Cmd events denoting a queued (shift) command.
+const cmdFlagQueued = 0x2
+
+// Queued tells if the command was queued (issued with shift) after the previous commands of the units.
+func (c *Command) Queued() bool {
+	return c.Cmd.Int("cmdFlags")&cmdFlagQueued != 0
+}
+
+// Order is a logical order of a user: a group of consecutive commands of the user which were
+// issued in the same game loop (e.g. rapid-fire spam), or which were queued (shift-queued sequences)
+// after the first command of the order.
+// Selection changes of the user (SelectionDelta and ControlGroupUpdate events) end the order.
+type Order struct {
+	Loop     int64      // Game loop of the first command
+	UserID   int64      // User ID of the issuer
+	Commands []*Command // Commands of the order in the order they were issued, has at least 1 element
+}
+
+// AbilLink returns the ability link of the first command of the order, see Command.AbilLink().
+func (o *Order) AbilLink() int64 {
+	return o.Commands[0].AbilLink()
+}
+
+// LastLoop returns the game loop of the last command of the order.
+func (o *Order) LastLoop() int64 {
+	return o.Commands[len(o.Commands)-1].Loop
+}
+
+// Queued tells if the order is a shift-queued sequence (it has queued commands).
+func (o *Order) Queued() bool {
+	for _, c := range o.Commands[1:] {
+		if c.Queued() {
+			return true
+		}
+	}
+	return false
+}
+
+// Orders returns the logical orders of the game in the order they were started,
+// grouped from the effective commands (see Commands).
+// nil is returned if game events were not decoded.
+func (r *Rep) Orders() []*Order {
+	cmds := r.Commands()
+	if cmds == nil {
+		return nil
+	}
+
+	orders := []*Order{}
+	open := map[int64]*Order{} // Open order by user ID
+	r.eachCommandEvt(cmds, func(e s2prot.Event, c *Command) {
+		if c == nil {
+			if e.Name == GmEvtSelectionDelta || e.Name == GmEvtControlGroupUpdate {
+				if userID, ok := evtUserID(e); ok {
+					delete(open, userID)
+				}
+			}
+			return
+		}
+		if o := open[c.UserID]; o != nil && (c.Loop == o.LastLoop() || c.Queued()) {
+			o.Commands = append(o.Commands, c)
+			return
+		}
+		o := &Order{Loop: c.Loop, UserID: c.UserID, Commands: []*Command{c}}
+		orders = append(orders, o)
+		open[c.UserID] = o
+	})
+
+	return orders
+}
+
+// PlayerEPM returns the average EPM (effective actions per minute) of the player specified by its index in Details.Players(),
+// calculated from the logical orders (see Orders) using real (wall clock) minutes,
+// so spammed and shift-queued commands are counted once.
+// ok is false if game events were not decoded or the player is not a human player.
+func (r *Rep) PlayerEPM(playerIdx int) (epm float64, ok bool) {
+	orders := r.Orders()
+	if orders == nil {
+		return 0, false
+	}
+
+	userID, ok := r.SlotMapping().PlayerUserID(playerIdx)
+	if !ok {
+		return
+	}
+
+	count := 0
+	for _, o := range orders {
+		if o.UserID == userID {
+			count++
+		}
+	}
+	if mins := r.RealDuration().Minutes(); mins > 0 {
+		epm = float64(count) / mins
+	}
+	return
+}
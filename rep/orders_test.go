@@ -0,0 +1,79 @@
+package rep
+
+import (
+	"testing"
+
+	"github.com/icza/s2prot"
+)
+
+func TestOrders(t *testing.T) {
+	r := newTestRep(humanSlot(0), humanSlot(1))
+	if r.Orders() != nil {
+		t.Error("Expected nil orders without game events!")
+	}
+	if _, ok := r.PlayerEPM(0); ok {
+		t.Error("Expected no EPM without game events!")
+	}
+
+	r.Header = Header{Struct: s2prot.Struct{"elapsedGameLoops": int64(16 * 1.4 * 60)}} // 1 real minute on Faster
+	r.Details.Struct["gameSpeed"] = int64(4)
+
+	cmd := func(loop, userID, seq, abilLink int64, queued bool) s2prot.Event {
+		flags := int64(0x100)
+		if queued {
+			flags |= cmdFlagQueued
+		}
+		return gameEvt(loop, userID, "Cmd", s2prot.Struct{
+			"sequence": seq,
+			"cmdFlags": flags,
+			"abil":     s2prot.Struct{"abilLink": abilLink, "abilCmdIndex": int64(0)},
+			"data":     s2prot.Struct{"None": nil},
+		})
+	}
+	r.GameEvts = []s2prot.Event{
+		cmd(10, 0, 1, 181, false),
+		cmd(10, 1, 1, 50, false), // Other user, does not break the order of user 0
+		cmd(10, 0, 2, 181, false),
+		gameEvt(10, 0, "CommandManagerState", s2prot.Struct{"state": int64(1), "sequence": int64(3)}), // Spam
+		cmd(20, 0, 4, 60, false),
+		cmd(30, 0, 5, 61, true), // Shift-queued
+		cmd(40, 0, 6, 62, true), // Shift-queued
+		gameEvt(45, 0, "SelectionDelta", nil),
+		cmd(50, 0, 7, 63, true), // Queued, but to a new selection
+		cmd(60, 1, 2, 50, false),
+	}
+
+	orders := r.Orders()
+	exp := []struct {
+		loop, userID, abilLink int64
+		cmds                   int
+		queued                 bool
+	}{
+		{10, 0, 181, 3, false},
+		{10, 1, 50, 1, false},
+		{20, 0, 60, 3, true},
+		{50, 0, 63, 1, false},
+		{60, 1, 50, 1, false},
+	}
+	if len(orders) != len(exp) {
+		t.Fatalf("Expected %d orders, got: %d", len(exp), len(orders))
+	}
+	for i, o := range orders {
+		e := exp[i]
+		if o.Loop != e.loop || o.UserID != e.userID || o.AbilLink() != e.abilLink || len(o.Commands) != e.cmds || o.Queued() != e.queued {
+			t.Errorf("[%d] Expected: %+v, got: %+v (abilLink: %d, queued: %v)", i, e, o, o.AbilLink(), o.Queued())
+		}
+	}
+	if ll := orders[2].LastLoop(); ll != 40 {
+		t.Errorf("Expected last loop: %d, got: %d", 40, ll)
+	}
+
+	for playerIdx, expEPM := range []float64{3, 2} {
+		if epm, ok := r.PlayerEPM(playerIdx); !ok || epm < expEPM-0.01 || epm > expEPM+0.01 {
+			t.Errorf("[%d] Expected EPM: %v, got: %v (ok: %v)", playerIdx, expEPM, epm, ok)
+		}
+	}
+	if _, ok := r.PlayerEPM(2); ok {
+		t.Error("Expected no EPM for unknown player!")
+	}
+}
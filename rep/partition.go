@@ -0,0 +1,67 @@
+/*
+
+Partitioning events by user / player.
+
+*/
+
+package rep
+
+import "github.com/icza/s2prot"
+
+// UserGameEvts returns the game events partitioned by user ID (the "userid" of the events).
+// The partitions are built once, on the first call; events keep their original order.
+// The returned map and slices must not be modified.
+func (r *Rep) UserGameEvts() map[int64][]s2prot.Event {
+	if r.userGameEvts == nil {
+		m := map[int64][]s2prot.Event{}
+		for _, e := range r.GameEvts {
+			if userID, ok := evtUserID(e); ok {
+				m[userID] = append(m[userID], e)
+			}
+		}
+		r.userGameEvts = m
+	}
+	return r.userGameEvts
+}
+
+// PlayerTrackerEvts returns the tracker events partitioned by player ID.
+// Player ID is the "playerId" of the events, or the "controlPlayerId" for unit events
+// (e.g. UnitBorn, UnitInit, UnitTypeChange); events that do not belong to a player
+// (e.g. UnitDied, UnitPositions) are not included.
+// Player ID is the index in Details.Players() plus 1; 0 denotes neutral.
+// The partitions are built once, on the first call; events keep their original order.
+// The returned map and slices must not be modified.
+func (r *Rep) PlayerTrackerEvts() map[int64][]s2prot.Event {
+	if r.playerTrackerEvts == nil {
+		m := map[int64][]s2prot.Event{}
+		if r.TrackerEvts != nil {
+			for _, e := range r.TrackerEvts.Evts {
+				pid, ok := e.LookupInt("playerId")
+				if !ok {
+					if pid, ok = e.LookupInt("controlPlayerId"); !ok {
+						continue
+					}
+				}
+				m[pid] = append(m[pid], e)
+			}
+		}
+		r.playerTrackerEvts = m
+	}
+	return r.playerTrackerEvts
+}
+
+// GameEvtsOf returns the game events of the player specified by its index in Details.Players().
+// nil is returned if the player is not a human player.
+func (r *Rep) GameEvtsOf(playerIdx int) []s2prot.Event {
+	for userID, idx := range r.userPlayerIdxs() {
+		if idx == playerIdx {
+			return r.UserGameEvts()[userID]
+		}
+	}
+	return nil
+}
+
+// TrackerEvtsOf returns the tracker events of the player specified by its index in Details.Players().
+func (r *Rep) TrackerEvtsOf(playerIdx int) []s2prot.Event {
+	return r.PlayerTrackerEvts()[int64(playerIdx+1)]
+}
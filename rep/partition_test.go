@@ -0,0 +1,36 @@
+package rep
+
+import (
+	"testing"
+
+	"github.com/icza/s2prot"
+)
+
+func TestPartition(t *testing.T) {
+	r := newTestRep(humanSlot(1), humanSlot(0))
+	r.GameEvts = []s2prot.Event{gameEvt(0, 0, "Cmd", nil), gameEvt(0, 1, "Cmd", nil), gameEvt(0, 1, "Cmd", nil), gameEvt(0, 16, "Cmd", nil)}
+	r.TrackerEvts = &TrackerEvts{Evts: []s2prot.Event{
+		trackerEvt(0, "PlayerStats", s2prot.Struct{"playerId": int64(1)}),
+		trackerEvt(0, "UnitBorn", s2prot.Struct{"controlPlayerId": int64(2)}),
+		trackerEvt(0, "UnitBorn", s2prot.Struct{"controlPlayerId": int64(0)}),
+		trackerEvt(0, "UnitDied", s2prot.Struct{"killerPlayerId": int64(1)}),
+		trackerEvt(0, "PlayerStats", s2prot.Struct{"playerId": int64(1)}),
+	}}
+
+	if m := r.UserGameEvts(); len(m) != 3 || len(m[0]) != 1 || len(m[1]) != 2 || len(m[16]) != 1 {
+		t.Errorf("Unexpected game events partitions: %v", m)
+	}
+	if evts := r.GameEvtsOf(0); len(evts) != 2 {
+		t.Errorf("Expected 2 game events, got: %d", len(evts))
+	}
+	if evts := r.GameEvtsOf(2); evts != nil {
+		t.Errorf("Expected no game events, got: %v", evts)
+	}
+
+	if m := r.PlayerTrackerEvts(); len(m) != 3 || len(m[0]) != 1 || len(m[1]) != 2 || len(m[2]) != 1 {
+		t.Errorf("Unexpected tracker events partitions: %v", m)
+	}
+	if evts := r.TrackerEvtsOf(1); len(evts) != 1 {
+		t.Errorf("Expected 1 tracker event, got: %d", len(evts))
+	}
+}
@@ -0,0 +1,21 @@
+/*
+
+Public game version (patch) of the replay.
+
+*/
+
+package rep
+
+import (
+	"fmt"
+)
+
+// PatchVersionName returns the public game version name of the replay in the form of "major.minor.revision",
+// e.g. "4.10.1", taken from the version of the header.
+// Empty string is returned if the header has no version.
+func (r *Rep) PatchVersionName() string {
+	if v := r.Header.Version(); v.Major() > 0 {
+		return fmt.Sprintf("%d.%d.%d", v.Major(), v.Minor(), v.Revision())
+	}
+	return ""
+}
@@ -0,0 +1,120 @@
+/*
+
+Heuristic segmentation of the game into phases.
+
+*/
+
+package rep
+
+// Parameters of the game phase segmentation.
+//
+// A phase begins when any player meets at least 2 of the 3 criteria of the phase:
+// the number of completed bases, having a completed tech structure of the phase
+// and the army value (see ArmyComposition.Value()).
+var (
+	MidGameBases     = 2    // Number of bases that is a criterion of the mid game
+	MidGameArmyValue = 1500 // Army value that is a criterion of the mid game
+
+	LateGameBases     = 4    // Number of bases that is a criterion of the late game
+	LateGameArmyValue = 6000 // Army value that is a criterion of the late game
+)
+
+// phaseCheckLoops is the interval of checking the criteria of the phases, in game loops.
+const phaseCheckLoops = 160
+
+// Town hall structures counted as bases.
+var baseStructures = map[string]bool{
+	"CommandCenter": true, "CommandCenterFlying": true, "OrbitalCommand": true, "OrbitalCommandFlying": true, "PlanetaryFortress": true,
+	"Nexus": true, "Hatchery": true, "Lair": true, "Hive": true,
+}
+
+// Tech structures of the mid game.
+var midGameTech = map[string]bool{
+	"Starport": true, "StarportFlying": true, "Armory": true,
+	"TwilightCouncil": true, "RoboticsFacility": true, "Stargate": true,
+	"Lair": true, "Hive": true,
+}
+
+// Tech structures of the late game.
+var lateGameTech = map[string]bool{
+	"FusionCore":  true,
+	"FleetBeacon": true, "TemplarArchive": true, "RoboticsBay": true,
+	"Hive": true, "GreaterSpire": true, "UltraliskCavern": true,
+}
+
+// PhaseRange is a phase of the game expressed as a range of game loops: [From, To).
+type PhaseRange struct {
+	Phase *GamePhase
+	From  int64 // First loop of the phase
+	To    int64 // Loop where the next phase begins, or the end of the game
+}
+
+// GamePhases returns the phases of the game as loop ranges, in chronological order.
+// Phases the game did not reach are not included, so the early game is always the first.
+// nil is returned if tracker events were not decoded.
+func (r *Rep) GamePhases() (phases []PhaseRange) {
+	units := r.Units()
+	if units == nil {
+		return nil
+	}
+
+	endLoop := r.Header.Loops()
+	phases = []PhaseRange{{Phase: GamePhaseEarly, From: 0, To: endLoop}}
+
+	criteria := []struct {
+		bases     int
+		tech      map[string]bool
+		armyValue *int
+	}{
+		{MidGameBases, midGameTech, &MidGameArmyValue},
+		{LateGameBases, lateGameTech, &LateGameArmyValue},
+	}
+
+	for loop := int64(0); loop < endLoop && len(phases) < len(GamePhases); loop += phaseCheckLoops {
+		c := criteria[len(phases)-1]
+
+		n := len(r.Details.Players())
+		bases, tech := make([]int, n), make([]bool, n)
+		for _, u := range units {
+			if u.Born > loop {
+				break // Units are in the order of their birth
+			}
+			idx := int(u.OwnerAt(loop)) - 1
+			if idx < 0 || idx >= n || !u.AliveAt(loop) {
+				continue
+			}
+			typeName := u.TypeAt(loop)
+			if baseStructures[typeName] {
+				bases[idx]++
+			}
+			tech[idx] = tech[idx] || c.tech[typeName]
+		}
+
+		for idx, ac := range r.ArmyComposition(loop) {
+			met := 0
+			for _, ok := range []bool{bases[idx] >= c.bases, tech[idx], ac.Value() >= *c.armyValue} {
+				if ok {
+					met++
+				}
+			}
+			if met >= 2 {
+				phases[len(phases)-1].To = loop
+				phases = append(phases, PhaseRange{Phase: GamePhases[len(phases)], From: loop, To: endLoop})
+				break
+			}
+		}
+	}
+	return
+}
+
+// GamePhaseAt returns the phase of the game at the given loop.
+// nil is returned if tracker events were not decoded.
+func (r *Rep) GamePhaseAt(loop int64) *GamePhase {
+	phases := r.GamePhases()
+	for i := len(phases) - 1; i >= 0; i-- {
+		if loop >= phases[i].From {
+			return phases[i].Phase
+		}
+	}
+	return nil
+}
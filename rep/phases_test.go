@@ -0,0 +1,48 @@
+package rep
+
+import (
+	"testing"
+
+	"github.com/icza/s2prot"
+)
+
+func TestGamePhases(t *testing.T) {
+	r := &Rep{}
+	r.Header = Header{Struct: s2prot.Struct{"elapsedGameLoops": int64(4000)}}
+	r.Details = Details{Struct: s2prot.Struct{
+		"gameSpeed":  int64(4),
+		"playerList": []interface{}{s2prot.Struct{}, s2prot.Struct{}},
+	}}
+
+	if phases := r.GamePhases(); phases != nil {
+		t.Errorf("Expected no phases without tracker events, got: %v", phases)
+	}
+
+	evt := func(loop, tagIdx int64, name, unitType string) s2prot.Event {
+		return unitEvt(loop, tagIdx, name, s2prot.Struct{"unitTypeName": unitType, "controlPlayerId": int64(1)})
+	}
+	r.TrackerEvts = &TrackerEvts{Evts: []s2prot.Event{
+		evt(0, 1, "UnitBorn", "CommandCenter"),
+		evt(500, 2, "UnitInit", "CommandCenter"),
+		evt(1000, 2, "UnitDone", ""),
+		evt(1500, 3, "UnitInit", "Starport"),
+		evt(2000, 3, "UnitDone", ""),
+	}}
+
+	phases := r.GamePhases()
+	exp := []PhaseRange{{GamePhaseEarly, 0, 2080}, {GamePhaseMid, 2080, 4000}}
+	if len(phases) != len(exp) {
+		t.Fatalf("Expected %d phases, got: %v", len(exp), phases)
+	}
+	for i := range exp {
+		if phases[i] != exp[i] {
+			t.Errorf("[%d] Expected: %v, got: %v", i, exp[i], phases[i])
+		}
+	}
+
+	for loop, exp := range map[int64]*GamePhase{0: GamePhaseEarly, 2079: GamePhaseEarly, 2080: GamePhaseMid, 3999: GamePhaseMid} {
+		if got := r.GamePhaseAt(loop); got != exp {
+			t.Errorf("[loop %d] Expected: %v, got: %v", loop, exp, got)
+		}
+	}
+}
@@ -0,0 +1,280 @@
+/*
+
+Production structure idle time estimation.
+
+The unit queues are reconstructed from tracker events: the producer of a unit is the creator unit
+of its UnitBorn event, and the production is assumed to have started the unit's build time before it was born.
+If game events are decoded, the start is refined by the Cmd events given to the producer: production can't start
+before the command ordering it. Abilities of Cmd game events are not resolved to names in this package,
+so commands having an ability and no target (like train commands) are considered production commands.
+Warp-ins (WarpGate) and larva-based (Zerg) production are not covered.
+
+*/
+
+package rep
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/icza/s2prot"
+)
+
+// productionStructures is the set of production structure unit types whose idle time is estimated.
+var productionStructures = map[string]bool{
+	"CommandCenter": true, "OrbitalCommand": true, "PlanetaryFortress": true,
+	"Barracks": true, "Factory": true, "Starport": true,
+	"Nexus": true, "Gateway": true, "RoboticsFacility": true, "Stargate": true,
+}
+
+// buildTimeLoopsPerSec is the number of game loops per build time second:
+// build times are specified in real seconds on Faster game speed, regardless of the game speed of the replay.
+const buildTimeLoopsPerSec = 22.4
+
+// ProductionStats holds the production structure statistics of a player.
+type ProductionStats struct {
+	Structures int           // Number of production structures the player had during the game
+	Available  time.Duration // Total time production structures existed, summed over the structures
+	Idle       time.Duration // Total time production structures were idle, summed over the structures
+}
+
+// IdleShare returns the share (0..1) of the idle time in the available time.
+func (ps *ProductionStats) IdleShare() float64 {
+	if ps.Available <= 0 {
+		return 0
+	}
+	return float64(ps.Idle) / float64(ps.Available)
+}
+
+// ProductionPoint is a data point of a production timeline.
+type ProductionPoint struct {
+	Time       time.Duration // Real (wall clock) time of the data point
+	Structures int           // Number of existing production structures
+	Idle       int           // Number of idle production structures
+}
+
+// loopRange is a range of game loops: [from, to).
+type loopRange struct {
+	from, to int64
+}
+
+// prodStructure is a production structure.
+type prodStructure struct {
+	life loopRange   // Lifetime: from completion to death (or to the end of the game)
+	busy []loopRange // Merged, sorted ranges of production
+}
+
+// ProductionIdle returns the estimated production structure idle time of the player
+// specified by its index in Details.Players().
+// nil is returned if tracker events were not decoded or they do not contain unit creator info
+// (replays older than base build 54724).
+func (r *Rep) ProductionIdle(playerIdx int) *ProductionStats {
+	structs := r.prodStructures(playerIdx)
+	if structs == nil {
+		return nil
+	}
+
+	ps := &ProductionStats{Structures: len(structs)}
+	for _, s := range structs {
+		ps.Available += r.loopDuration(s.life.to - s.life.from)
+		idle := s.life.to - s.life.from
+		for _, b := range s.busy {
+			idle -= b.to - b.from
+		}
+		ps.Idle += r.loopDuration(idle)
+	}
+	return ps
+}
+
+// ProductionTimeline returns the number of existing and idle production structures of the player
+// specified by its index in Details.Players() sampled in the given steps of real time.
+// nil is returned if tracker events were not decoded, they do not contain unit creator info (old replays)
+// or step is not positive.
+func (r *Rep) ProductionTimeline(playerIdx int, step time.Duration) (points []ProductionPoint) {
+	if step <= 0 {
+		return nil
+	}
+	structs := r.prodStructures(playerIdx)
+	if structs == nil {
+		return nil
+	}
+
+	loopsPerSec := LoopsPerSecond(r.Details.GameSpeed())
+	duration := r.RealDuration()
+	for t := time.Duration(0); t <= duration; t += step {
+		loop := int64(t.Seconds() * loopsPerSec)
+		p := ProductionPoint{Time: t}
+		for _, s := range structs {
+			if !s.life.contains(loop) {
+				continue
+			}
+			p.Structures++
+			idle := true
+			for _, b := range s.busy {
+				if b.contains(loop) {
+					idle = false
+					break
+				}
+			}
+			if idle {
+				p.Idle++
+			}
+		}
+		points = append(points, p)
+	}
+	return
+}
+
+// contains tells if the loop is in the range.
+func (lr loopRange) contains(loop int64) bool {
+	return loop >= lr.from && loop < lr.to
+}
+
+// prodStructures reconstructs the production structures of the player specified by its index in Details.Players().
+// nil is returned if tracker events were not decoded or they do not contain unit creator info.
+func (r *Rep) prodStructures(playerIdx int) []*prodStructure {
+	if r.TrackerEvts == nil {
+		return nil
+	}
+	pid := int64(playerIdx + 1)
+	endLoop := r.Header.Loops()
+	cmdLoops := r.prodCmdLoops()
+
+	var structs []*prodStructure
+	alive := map[int64]*prodStructure{}    // Tracked structures mapped from unit tag
+	pending := map[int64]bool{}            // Structures under construction
+	lastBorn := map[*prodStructure]int64{} // Loop of the last unit produced by the structures
+	hasCreator := false
+
+	start := func(tag, loop int64) {
+		s := &prodStructure{life: loopRange{loop, endLoop}}
+		structs = append(structs, s)
+		alive[tag] = s
+	}
+	end := func(tag, loop int64) {
+		if s := alive[tag]; s != nil {
+			s.life.to = loop
+			delete(alive, tag)
+		}
+	}
+
+	for _, e := range r.TrackerEvts.Evts {
+		tag := evtUnitTag(e)
+		switch e.Name {
+		case "UnitInit":
+			if e.Int("controlPlayerId") == pid && productionStructures[e.Stringv("unitTypeName")] {
+				pending[tag] = true
+			}
+		case "UnitDone":
+			if pending[tag] {
+				delete(pending, tag)
+				start(tag, e.Loop())
+			}
+		case "UnitBorn":
+			creatorIdx, ok := e.LookupInt("creatorUnitTagIndex")
+			hasCreator = hasCreator || ok
+			typeName := e.Stringv("unitTypeName")
+			if e.Int("controlPlayerId") != pid {
+				break
+			}
+			if productionStructures[typeName] {
+				start(tag, e.Loop()) // Starting main building
+				break
+			}
+			creatorTag := unitTag(creatorIdx, e.Int("creatorUnitTagRecycle"))
+			s := alive[creatorTag]
+			if _, ub := balanceOf(typeName); ok && s != nil && ub != nil && ub.buildTime > 0 {
+				from := e.Loop() - int64(ub.buildTime*buildTimeLoopsPerSec)
+				prev, produced := lastBorn[s]
+				if !produced {
+					prev = s.life.from
+				}
+				// The first production command since the previous unit:
+				for _, loop := range cmdLoops[creatorTag] {
+					if loop > prev {
+						if loop <= e.Loop() && loop > from {
+							from = loop
+						}
+						break
+					}
+				}
+				if from < s.life.from {
+					from = s.life.from
+				}
+				s.busy = append(s.busy, loopRange{from, e.Loop()})
+				lastBorn[s] = e.Loop()
+			}
+		case "UnitTypeChange":
+			// Lifted off structures remain tracked (idle), others no longer produce (e.g. WarpGate):
+			if typeName := e.Stringv("unitTypeName"); !productionStructures[typeName] && !strings.HasSuffix(typeName, "Flying") {
+				end(tag, e.Loop())
+			}
+		case "UnitDied":
+			delete(pending, tag)
+			end(tag, e.Loop())
+		}
+	}
+	if !hasCreator {
+		return nil
+	}
+
+	for _, s := range structs {
+		s.busy = mergeLoopRanges(s.busy, s.life)
+	}
+	return structs
+}
+
+// prodCmdLoops returns the game loops of the production commands (commands having an ability and no target)
+// given to units, mapped from unit tag.
+// nil is returned if game events were not decoded.
+func (r *Rep) prodCmdLoops() map[int64][]int64 {
+	cmds, selections := r.CommandSelections()
+	if cmds == nil {
+		return nil
+	}
+	loops := map[int64][]int64{}
+	for i, c := range cmds {
+		if _, none := c.Data["None"]; !none || c.AbilLink() == 0 {
+			continue
+		}
+		for _, su := range selections[i] {
+			loops[su.Tag] = append(loops[su.Tag], c.Loop)
+		}
+	}
+	return loops
+}
+
+// mergeLoopRanges sorts and merges overlapping ranges, clipped to the given bounds.
+func mergeLoopRanges(ranges []loopRange, bounds loopRange) (merged []loopRange) {
+	sort.Slice(ranges, func(i, j int) bool { return ranges[i].from < ranges[j].from })
+	for _, lr := range ranges {
+		if lr.from < bounds.from {
+			lr.from = bounds.from
+		}
+		if lr.to > bounds.to {
+			lr.to = bounds.to
+		}
+		if lr.from >= lr.to {
+			continue
+		}
+		if n := len(merged); n > 0 && lr.from <= merged[n-1].to {
+			if lr.to > merged[n-1].to {
+				merged[n-1].to = lr.to
+			}
+			continue
+		}
+		merged = append(merged, lr)
+	}
+	return
+}
+
+// evtUnitTag returns the unit tag of a tracker unit event.
+func evtUnitTag(e s2prot.Event) int64 {
+	return unitTag(e.Int("unitTagIndex"), e.Int("unitTagRecycle"))
+}
+
+// unitTag composes a unit tag from its index and recycle parts.
+func unitTag(index, recycle int64) int64 {
+	return index<<18 + recycle
+}
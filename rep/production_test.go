@@ -0,0 +1,89 @@
+package rep
+
+import (
+	"testing"
+	"time"
+
+	"github.com/icza/s2prot"
+)
+
+func TestProductionIdle(t *testing.T) {
+	r := &Rep{}
+	r.Header = Header{Struct: s2prot.Struct{"elapsedGameLoops": int64(2240)}} // 100 real seconds on Faster
+	r.Details = Details{Struct: s2prot.Struct{"gameSpeed": int64(4)}}
+
+	r.TrackerEvts = &TrackerEvts{Evts: []s2prot.Event{
+		unitEvt(0, 10, "UnitInit", s2prot.Struct{"unitTypeName": "Barracks", "controlPlayerId": int64(1)}),
+		unitEvt(0, 11, "UnitInit", s2prot.Struct{"unitTypeName": "Barracks", "controlPlayerId": int64(2)}),
+		unitEvt(224, 10, "UnitDone", nil),
+		unitEvt(224, 11, "UnitDone", nil),
+		unitEvt(1120, 20, "UnitBorn", s2prot.Struct{"unitTypeName": "Marine", "controlPlayerId": int64(1),
+			"creatorUnitTagIndex": int64(10), "creatorUnitTagRecycle": int64(1)}),
+		unitEvt(2016, 11, "UnitDied", nil),
+	}}
+
+	near := func(d, exp time.Duration) bool { return d > exp-100*time.Millisecond && d < exp+100*time.Millisecond }
+
+	ps := r.ProductionIdle(0)
+	if ps == nil || ps.Structures != 1 || !near(ps.Available, 90*time.Second) || !near(ps.Idle, 72*time.Second) {
+		t.Errorf("Unexpected production stats: %+v", ps)
+	}
+	ps = r.ProductionIdle(1)
+	if ps == nil || ps.Structures != 1 || !near(ps.Available, 80*time.Second) || ps.IdleShare() != 1 {
+		t.Errorf("Unexpected production stats: %+v", ps)
+	}
+
+	points := r.ProductionTimeline(0, 20*time.Second)
+	exp := []ProductionPoint{{0, 0, 0}, {20 * time.Second, 1, 1}, {40 * time.Second, 1, 0}, {60 * time.Second, 1, 1},
+		{80 * time.Second, 1, 1}, {100 * time.Second, 0, 0}}
+	if len(points) != len(exp) {
+		t.Fatalf("Expected %d points, got: %v", len(exp), points)
+	}
+	for i := range exp {
+		if points[i] != exp[i] {
+			t.Errorf("[%d] Expected: %v, got: %v", i, exp[i], points[i])
+		}
+	}
+
+	// Without unit creator info:
+	r.TrackerEvts.Evts = r.TrackerEvts.Evts[:4]
+	if ps := r.ProductionIdle(0); ps != nil {
+		t.Errorf("Expected nil without creator info, got: %+v", ps)
+	}
+}
+
+func TestProductionIdleSpeedAndCmds(t *testing.T) {
+	r := &Rep{}
+	r.Header = Header{Struct: s2prot.Struct{"elapsedGameLoops": int64(2240)}}
+	r.Details = Details{Struct: s2prot.Struct{"gameSpeed": int64(2)}} // Normal: 16 loops per real second
+
+	r.TrackerEvts = &TrackerEvts{Evts: []s2prot.Event{
+		unitEvt(0, 10, "UnitInit", s2prot.Struct{"unitTypeName": "Barracks", "controlPlayerId": int64(1)}),
+		unitEvt(224, 10, "UnitDone", nil),
+		unitEvt(1120, 20, "UnitBorn", s2prot.Struct{"unitTypeName": "Marine", "controlPlayerId": int64(1),
+			"creatorUnitTagIndex": int64(10), "creatorUnitTagRecycle": int64(1)}),
+	}}
+
+	// Build times are in Faster seconds regardless of the game speed: the marine takes 18*22.4 = 403 loops.
+	ps := r.ProductionIdle(0)
+	if exp := LoopsToDuration(2016 - 403); ps == nil || ps.Available != LoopsToDuration(2016) || ps.Idle != exp {
+		t.Errorf("Expected idle: %v, got: %+v", exp, ps)
+	}
+
+	// The marine is ordered after its estimated production start:
+	r.GameEvts = []s2prot.Event{
+		gameEvt(800, 0, "SelectionDelta", s2prot.Struct{"controlGroupId": int64(ActiveSelectionID), "delta": s2prot.Struct{
+			"removeMask":   s2prot.Struct{"None": nil},
+			"addSubgroups": []interface{}{s2prot.Struct{"count": int64(1), "unitLink": int64(21)}},
+			"addUnitTags":  []interface{}{unitTag(10, 1)},
+		}}),
+		gameEvt(810, 0, "Cmd", s2prot.Struct{"sequence": int64(1), "abil": s2prot.Struct{"abilLink": int64(3)},
+			"data": s2prot.Struct{"TargetPoint": s2prot.Struct{"x": int64(0), "y": int64(0)}}}), // Rally
+		gameEvt(900, 0, "Cmd", s2prot.Struct{"sequence": int64(2), "abil": s2prot.Struct{"abilLink": int64(170)},
+			"data": s2prot.Struct{"None": nil}}), // Train
+	}
+	ps = r.ProductionIdle(0)
+	if exp := LoopsToDuration(2016 - 220); ps == nil || ps.Idle != exp {
+		t.Errorf("Expected idle: %v, got: %+v", exp, ps)
+	}
+}
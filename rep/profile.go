@@ -0,0 +1,58 @@
+/*
+
+Profile URLs and identifiers of players used by Battle.net and popular third-party services.
+
+*/
+
+package rep
+
+import (
+	"fmt"
+	"net/url"
+)
+
+// ProfileURL returns the player's Battle.net (starcraft2.blizzard.com) profile URL
+// in the specified language, e.g. "en-us". Empty language means "en-us".
+func (h ToonHandle) ProfileURL(lang string) string {
+	if lang == "" {
+		lang = "en-us"
+	}
+	return fmt.Sprintf("https://starcraft2.blizzard.com/%s/profile/%d/%d/%d", lang, h.RegionID, h.RealmID, h.ID)
+}
+
+// Hosts of the Blizzard API, mapped from region code.
+var apiHosts = map[string]string{
+	"US": "https://us.api.blizzard.com",
+	"EU": "https://eu.api.blizzard.com",
+	"KR": "https://kr.api.blizzard.com",
+	"CN": "https://gateway.battlenet.com.cn",
+}
+
+// APIPath returns the path of the player's profile in the Blizzard (Community) API,
+// in the form of "/sc2/profile/regionId/realmId/id".
+func (h ToonHandle) APIPath() string {
+	return fmt.Sprintf("/sc2/profile/%d/%d/%d", h.RegionID, h.RealmID, h.ID)
+}
+
+// APIURL returns the URL of the player's profile in the Blizzard (Community) API,
+// which is the API host of the player's region and APIPath.
+// Empty string is returned if the region has no API host.
+func (h ToonHandle) APIURL() string {
+	host, ok := apiHosts[h.Region().Code]
+	if !ok {
+		return ""
+	}
+	return host + h.APIPath()
+}
+
+// PulseID returns the identifier of the player's character used by SC2Pulse (sc2pulse.nephest.com),
+// in the form of "regionId/realmId/id".
+func (h ToonHandle) PulseID() string {
+	return fmt.Sprintf("%d/%d/%d", h.RegionID, h.RealmID, h.ID)
+}
+
+// PulseSearchURL returns the SC2Pulse (sc2pulse.nephest.com) URL which searches for the player's character
+// (by the player's Battle.net profile URL).
+func (h ToonHandle) PulseSearchURL() string {
+	return "https://sc2pulse.nephest.com/sc2/?type=search&name=" + url.QueryEscape(h.ProfileURL(""))
+}
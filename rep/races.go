@@ -0,0 +1,94 @@
+/*
+
+Selected and assigned races of players.
+
+*/
+
+package rep
+
+// PlayerSelectedRace returns the race selected in the lobby by the player specified by its index
+// in Details.Players(). This may be RaceRandom.
+//
+// The race preference of the lobby slot is used, falling back to the attributes events
+// and to the game metadata. RaceUnknown is returned if the selected race cannot be determined.
+func (r *Rep) PlayerSelectedRace(playerIdx int) *Race {
+	if slots := r.PlayerSlots(); playerIdx >= 0 && playerIdx < len(slots) && slots[playerIdx] >= 0 {
+		if race := r.InitData.LobbyState.Slots[slots[playerIdx]].RacePrefRace(); race != RaceUnknown {
+			return race
+		}
+		if race := r.AttrEvts.PlayerRace(slots[playerIdx]); race != RaceUnknown {
+			return race
+		}
+	}
+
+	if mp := r.metaPlayer(playerIdx); mp != nil {
+		return raceByMetaValue(mp.SelectedRace())
+	}
+
+	return RaceUnknown
+}
+
+// PlayerAssignedRace returns the race the player specified by its index in Details.Players()
+// actually played with. This is never RaceRandom.
+//
+// The assigned race of the game metadata is used, falling back to the race of the main building
+// the player started with (from tracker events), and to the race in Details.
+func (r *Rep) PlayerAssignedRace(playerIdx int) *Race {
+	if mp := r.metaPlayer(playerIdx); mp != nil {
+		if race := raceByMetaValue(mp.AssignedRace()); race != RaceUnknown && race != RaceRandom {
+			return race
+		}
+	}
+
+	if r.TrackerEvts != nil {
+		pid := int64(playerIdx + 1)
+		for _, e := range r.TrackerEvts.Evts {
+			if e.Loop() > 0 {
+				break
+			}
+			if e.ID == TrackerEvtIDUnitBorn && e.Int("controlPlayerId") == pid {
+				if race := mainBuildingRace(e.Stringv("unitTypeName")); race != RaceUnknown {
+					return race
+				}
+			}
+		}
+	}
+
+	if players := r.Details.Players(); playerIdx >= 0 && playerIdx < len(players) {
+		return players[playerIdx].Race()
+	}
+
+	return RaceUnknown
+}
+
+// PlayerWasRandom tells if the player specified by its index in Details.Players() selected Random race.
+func (r *Rep) PlayerWasRandom(playerIdx int) bool {
+	return r.PlayerSelectedRace(playerIdx) == RaceRandom
+}
+
+// metaPlayer returns the meta player of the player specified by its index in Details.Players().
+// Player IDs in the metadata are 1-based indices into Details.Players().
+// nil is returned if the replay has no metadata or it does not contain the player.
+func (r *Rep) metaPlayer(playerIdx int) *MetaPlayer {
+	players := r.Metadata.Players()
+	for i := range players {
+		if players[i].PlayerID() == int64(playerIdx+1) {
+			return &players[i]
+		}
+	}
+	return nil
+}
+
+// mainBuildingRace returns the race of a main building specified by its unit type name.
+// RaceUnknown is returned if the unit type is not a main building.
+func mainBuildingRace(unitTypeName string) *Race {
+	switch unitTypeName {
+	case "CommandCenter":
+		return RaceTerran
+	case "Hatchery":
+		return RaceZerg
+	case "Nexus":
+		return RaceProtoss
+	}
+	return RaceUnknown
+}
@@ -0,0 +1,39 @@
+package rep
+
+import (
+	"testing"
+
+	"github.com/icza/s2prot"
+)
+
+func TestPlayerRaces(t *testing.T) {
+	r := newTestRep(
+		s2prot.Struct{"racePref": s2prot.Struct{"race": nil}},
+		s2prot.Struct{"racePref": s2prot.Struct{"race": int64(1)}},
+		s2prot.Struct{},
+	)
+	setTestPlayers(r, s2prot.Struct{"race": "Terran"}, s2prot.Struct{"race": "Zerg"}, s2prot.Struct{"race": "Protoss"})
+	r.Metadata = Metadata{Struct: s2prot.Struct{"Players": []interface{}{
+		map[string]interface{}{"PlayerID": 3.0, "SelectedRace": "Rand", "AssignedRace": "Prot"},
+	}}}
+	r.TrackerEvts = &TrackerEvts{Evts: []s2prot.Event{
+		{Struct: s2prot.Struct{"loop": int64(0), "controlPlayerId": int64(1), "unitTypeName": "SCV"}, EvtType: &s2prot.EvtType{ID: TrackerEvtIDUnitBorn}},
+		{Struct: s2prot.Struct{"loop": int64(0), "controlPlayerId": int64(1), "unitTypeName": "CommandCenter"}, EvtType: &s2prot.EvtType{ID: TrackerEvtIDUnitBorn}},
+	}}
+
+	cases := []struct {
+		selected, assigned *Race
+		wasRandom          bool
+	}{
+		{RaceRandom, RaceTerran, true},    // Lobby slot, tracker events
+		{RaceZerg, RaceZerg, false},       // Lobby slot, details
+		{RaceRandom, RaceProtoss, true},   // Metadata
+		{RaceUnknown, RaceUnknown, false}, // Invalid player
+	}
+
+	for i, c := range cases {
+		if selected, assigned, wasRandom := r.PlayerSelectedRace(i), r.PlayerAssignedRace(i), r.PlayerWasRandom(i); selected != c.selected || assigned != c.assigned || wasRandom != c.wasRandom {
+			t.Errorf("[%d] Expected: %v, %v, %v, got: %v, %v, %v", i, c.selected, c.assigned, c.wasRandom, selected, assigned, wasRandom)
+		}
+	}
+}
@@ -0,0 +1,28 @@
+/*
+
+Ratings (MMR) of players.
+
+*/
+
+package rep
+
+// PlayerMMR returns the MMR of the player specified by its index in Details.Players().
+//
+// The scaled rating of the user init data is used, falling back to the MMR of the game metadata,
+// as either may be missing depending on the replay version and type.
+// ok is false if the MMR is not available from either source.
+func (r *Rep) PlayerMMR(playerIdx int) (mmr int64, ok bool) {
+	if u := r.PlayerUser(playerIdx); u != nil {
+		if mmr, ok = u.ScaledRating(); ok {
+			return
+		}
+	}
+
+	if mp := r.metaPlayer(playerIdx); mp != nil {
+		if f, ok := mp.LookupMMR(); ok {
+			return int64(f), true
+		}
+	}
+
+	return 0, false
+}
@@ -0,0 +1,32 @@
+package rep
+
+import (
+	"testing"
+
+	"github.com/icza/s2prot"
+)
+
+func TestPlayerMMR(t *testing.T) {
+	r := newTestRep(humanSlot(0), humanSlot(1), humanSlot(2))
+	setTestUsers(r, s2prot.Struct{"scaledRating": int64(4321)}, s2prot.Struct{"scaledRating": nil}, s2prot.Struct{})
+	r.Metadata = Metadata{Struct: s2prot.Struct{"Players": []interface{}{
+		map[string]interface{}{"PlayerID": 1.0, "MMR": 4000.0},
+		map[string]interface{}{"PlayerID": 2.0, "MMR": 3000.0},
+	}}}
+
+	cases := []struct {
+		mmr int64
+		ok  bool
+	}{
+		{4321, true}, // User init data
+		{3000, true}, // Metadata
+		{0, false},   // Missing
+		{0, false},   // Invalid player
+	}
+
+	for i, c := range cases {
+		if mmr, ok := r.PlayerMMR(i); mmr != c.mmr || ok != c.ok {
+			t.Errorf("[%d] Expected: %d, %v, got: %d, %v", i, c.mmr, c.ok, mmr, ok)
+		}
+	}
+}
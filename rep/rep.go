@@ -10,6 +10,7 @@ import (
 	"encoding/json"
 	"errors"
 	"io"
+	"time"
 
 	"github.com/icza/mpq"
 	"github.com/icza/s2prot"
@@ -52,6 +53,11 @@ type Rep struct {
 	GameEvtsErr    bool // Tells if decoding game events had errors
 	MessageEvtsErr bool // Tells if decoding message events had errors
 	TrackerEvtsErr bool // Tells if decoding tracker events had errors
+
+	userGameEvts      map[int64][]s2prot.Event // Lazily initialized game events by user ID
+	playerTrackerEvts map[int64][]s2prot.Event // Lazily initialized tracker events by player ID
+	units             []*Unit                  // Lazily initialized units reconstructed from tracker events
+	userOptions       map[int64]*UserOptions   // Lazily initialized user options by user ID
 }
 
 // NewFromFile returns a new Rep constructed from a file.
@@ -59,6 +65,8 @@ type Rep struct {
 // The returned Rep must be closed with the Close method!
 //
 // ErrInvalidRepFile is returned if the specified name does not denote a valid SC2Replay file.
+// If a section of the replay is missing or invalid, the returned error is a *SectionError wrapping ErrInvalidRepFile.
+// If the replay header is invalid (e.g. its signature is unknown), the returned error is a *HeaderError wrapping ErrInvalidRepFile.
 //
 // ErrUnsupportedRepVersion is returned if the file exists and is a valid SC2Replay file but its version is not supported.
 //
@@ -73,16 +81,20 @@ func NewFromFile(name string) (*Rep, error) {
 // The returned Rep must be closed with the Close method!
 //
 // ErrInvalidRepFile is returned if the specified name does not denote a valid SC2Replay file.
+// If a section of the replay is missing or invalid, the returned error is a *SectionError wrapping ErrInvalidRepFile.
+// If the replay header is invalid (e.g. its signature is unknown), the returned error is a *HeaderError wrapping ErrInvalidRepFile.
 //
 // ErrUnsupportedRepVersion is returned if the file exists and is a valid SC2Replay file but its version is not supported.
 //
 // ErrDecoding is returned if decoding the replay fails. This is most likely because the replay file is invalid, but also might be due to an implementation bug.
 func NewFromFileEvts(name string, game, message, tracker bool) (*Rep, error) {
+	start := time.Now()
 	m, err := mpq.NewFromFile(name)
 	if err != nil {
+		observeParse(start, 0, ErrInvalidRepFile)
 		return nil, ErrInvalidRepFile
 	}
-	return newRep(m, game, message, tracker)
+	return newRep(m, start, game, message, tracker)
 }
 
 // New returns a new Rep using the specified io.ReadSeeker as the SC2Replay file source.
@@ -90,6 +102,8 @@ func NewFromFileEvts(name string, game, message, tracker bool) (*Rep, error) {
 // The returned Rep must be closed with the Close method!
 //
 // ErrInvalidRepFile is returned if the input is not a valid SC2Replay file content.
+// If a section of the replay is missing or invalid, the returned error is a *SectionError wrapping ErrInvalidRepFile.
+// If the replay header is invalid (e.g. its signature is unknown), the returned error is a *HeaderError wrapping ErrInvalidRepFile.
 //
 // ErrUnsupportedRepVersion is returned if the input is a valid SC2Replay file but its version is not supported.
 //
@@ -104,16 +118,20 @@ func New(input io.ReadSeeker) (*Rep, error) {
 // The returned Rep must be closed with the Close method!
 //
 // ErrInvalidRepFile is returned if the input is not a valid SC2Replay file content.
+// If a section of the replay is missing or invalid, the returned error is a *SectionError wrapping ErrInvalidRepFile.
+// If the replay header is invalid (e.g. its signature is unknown), the returned error is a *HeaderError wrapping ErrInvalidRepFile.
 //
 // ErrUnsupportedRepVersion is returned if the input is a valid SC2Replay file but its version is not supported.
 //
 // ErrDecoding is returned if decoding the replay fails. This is most likely because the input is invalid, but also might be due to an implementation bug.
 func NewEvts(input io.ReadSeeker, game, message, tracker bool) (*Rep, error) {
+	start := time.Now()
 	m, err := mpq.New(input)
 	if err != nil {
+		observeParse(start, 0, ErrInvalidRepFile)
 		return nil, ErrInvalidRepFile
 	}
-	return newRep(m, game, message, tracker)
+	return newRep(m, start, game, message, tracker)
 }
 
 // newRep returns a new Rep constructed using the specified mpq.MPQ handler of the SC2Replay file, only the specified types of events decoded.
@@ -122,11 +140,17 @@ func NewEvts(input io.ReadSeeker, game, message, tracker bool) (*Rep, error) {
 // The returned Rep must be closed with the Close method!
 //
 // ErrInvalidRepFile is returned if the specified name does not denote a valid SC2Replay file.
+// If a section of the replay is missing or invalid, the returned error is a *SectionError wrapping ErrInvalidRepFile.
+// If the replay header is invalid (e.g. its signature is unknown), the returned error is a *HeaderError wrapping ErrInvalidRepFile.
 //
 // ErrUnsupportedRepVersion is returned if the input is a valid SC2Replay file but its version is not supported.
 //
 // ErrDecoding is returned if decoding the replay fails. This is most likely because the input is invalid, but also might be due to an implementation bug.
-func newRep(m *mpq.MPQ, game, message, tracker bool) (parsedRep *Rep, errRes error) {
+//
+// The completed parsing started at start is reported to the metrics hook, see SetParseMetrics.
+func newRep(m *mpq.MPQ, start time.Time, game, message, tracker bool) (parsedRep *Rep, errRes error) {
+	rep := Rep{m: m}
+
 	closeMPQ := true
 	defer func() {
 		// If returning due to an error, MPQ must be closed!
@@ -137,82 +161,83 @@ func newRep(m *mpq.MPQ, game, message, tracker bool) (parsedRep *Rep, errRes err
 		// The input is completely untrusted and the decoding implementation omits error checks for efficiency:
 		// Protect replay decoding:
 		if r := recover(); r != nil {
-			errRes = ErrDecoding
+			parsedRep, errRes = nil, ErrDecoding
 		}
-	}()
 
-	rep := Rep{m: m}
+		var baseBuild int64
+		if rep.Header.Struct != nil {
+			baseBuild = rep.Header.BaseBuild()
+		}
+		observeParse(start, baseBuild, errRes)
+	}()
 
-	rep.Header = Header{Struct: s2prot.DecodeHeader(m.UserData())}
-	if rep.Header.Struct == nil {
-		return nil, ErrInvalidRepFile
+	if rep.Header, errRes = ParseHeader(m.UserData()); errRes != nil {
+		return nil, errRes
 	}
 
-	bb := rep.Header.BaseBuild()
-	p := s2prot.GetProtocol(int(bb))
+	p := rep.Header.Protocol()
 	if p == nil {
 		return nil, ErrUnsupportedRepVersion
 	}
 	rep.protocol = p
 
-	data, err := m.FileByHash(620083690, 3548627612, 4013960850) // "replay.details"
+	sr := newSectionReader(m)
+	data, err := sr.read(secDetails)
 	if err != nil || len(data) == 0 {
 		// Attempt to open the anonymized version
-		data, err = m.FileByHash(1421087648, 3590964654, 3400061273) // "replay.details.backup"
+		data, err = sr.read(secDetailsBackup)
 		if err != nil || len(data) == 0 {
-			return nil, ErrInvalidRepFile
+			return nil, secDetails.err()
 		}
 	}
 	rep.Details = Details{Struct: p.DecodeDetails(data)}
 
-	data, err = m.FileByHash(3544165653, 1518242780, 4280631132) // "replay.initData"
+	data, err = sr.read(secInitData)
 	if err != nil || len(data) == 0 {
 		// Attempt to open the anonymized version
-		data, err = m.FileByHash(868899905, 1282002788, 1614930827) // "replay.initData.backup"
+		data, err = sr.read(secInitDataBackup)
 		if err != nil || len(data) == 0 {
-			return nil, ErrInvalidRepFile
+			return nil, secInitData.err()
 		}
 	}
 	rep.InitData = NewInitData(p.DecodeInitData(data))
 
-	data, err = m.FileByHash(1306016990, 497594575, 2731474728) // "replay.attributes.events"
+	data, err = sr.read(secAttributesEvts)
 	if err != nil {
-		return nil, ErrInvalidRepFile
+		return nil, secAttributesEvts.err()
 	}
 	rep.AttrEvts = NewAttrEvts(p.DecodeAttributesEvts(data))
 
-	data, err = m.FileByHash(3675439372, 3912155403, 1108615308) // "replay.gamemetadata.json"
-	if err != nil {
-		return nil, ErrInvalidRepFile
-	}
-	if data != nil { // Might not be present, was added around 3.7
+	// Game metadata might not be present (was added around 3.7), it is synthesized if missing or invalid
+	data, err = sr.read(secGameMetadata)
+	if err == nil && data != nil {
 		if err = json.Unmarshal(data, &rep.Metadata.Struct); err != nil {
-			return nil, ErrInvalidRepFile
+			rep.Metadata.Struct = nil // Synthesized below
 		}
 	}
 
 	if game {
-		data, err = m.FileByHash(496563520, 2864883019, 4101385109) // "replay.game.events"
+		data, err = sr.read(secGameEvts)
 		if err != nil {
-			return nil, ErrInvalidRepFile
+			return nil, secGameEvts.err()
 		}
 		rep.GameEvts, err = p.DecodeGameEvts(data)
 		rep.GameEvtsErr = err != nil
 	}
 
 	if message {
-		data, err = m.FileByHash(1089231967, 831857289, 1784674979) // "replay.message.events"
+		data, err = sr.read(secMessageEvts)
 		if err != nil {
-			return nil, ErrInvalidRepFile
+			return nil, secMessageEvts.err()
 		}
 		rep.MessageEvts, err = p.DecodeMessageEvts(data)
 		rep.MessageEvtsErr = err != nil
 	}
 
 	if tracker {
-		data, err = m.FileByHash(1501940595, 4263103390, 1648390237) // "replay.tracker.events"
+		data, err = sr.read(secTrackerEvts)
 		if err != nil {
-			return nil, ErrInvalidRepFile
+			return nil, secTrackerEvts.err()
 		}
 		evts, err := p.DecodeTrackerEvts(data)
 		rep.TrackerEvts = &TrackerEvts{Evts: evts}
@@ -220,6 +245,10 @@ func newRep(m *mpq.MPQ, game, message, tracker bool) (parsedRep *Rep, errRes err
 		rep.TrackerEvtsErr = err != nil
 	}
 
+	if rep.Metadata.Struct == nil {
+		rep.Metadata = synthesizeMetadata(&rep)
+	}
+
 	// Everything went well, Rep is about to be returned, do not close MPQ
 	// (it will be the caller's responsibility, done via Rep.Close()).
 	closeMPQ = false
@@ -0,0 +1,439 @@
+/*
+Package reptest constructs minimal, valid synthetic replays (*.SC2Replay) for tests.
+
+Replays are built using the encoders of the s2prot package with the protocol of the chosen base build,
+so they can be parsed with the rep package (or any other replay parser) just like real replays.
+This allows unit testing replay processing pipelines without committing large binary replay files.
+
+All data not specified explicitly is filled with the zero values of the protocol
+(see s2prot.Protocol.ZeroValue).
+
+Example:
+
+	r := reptest.New(80949)
+	r.Title = "Test Map"
+	r.GameEvt(16, 0, "CameraUpdate", s2prot.Struct{"distance": nil})
+	r.MessageEvt(100, 1, "Chat", s2prot.Struct{"string": "gg"})
+	r.TrackerEvt(0, "PlayerSetup", s2prot.Struct{"playerId": int64(1), "userId": int64(0)})
+
+	data, err := r.Bytes()
+	if err != nil {
+		// Handle error
+	}
+	parsed, err := rep.New(bytes.NewReader(data))
+	if err != nil {
+		// Handle error
+	}
+	defer parsed.Close()
+*/
+package reptest
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/icza/s2prot"
+	"github.com/icza/s2prot/rep"
+	"github.com/icza/s2prot/rep/rewrite"
+)
+
+// Player describes a player of the synthetic replay.
+type Player struct {
+	Name   string      // Name of the player, may contain a clan tag, e.g. "[TAG]Name"
+	Race   *rep.Race   // Race of the player
+	TeamID int64       // Team of the player (0-based)
+	Result *rep.Result // Result of the player
+	Color  *rep.Color  // Color of the player
+	ToonID int64       // ID of the player's toon (if 0, the 1-based index of the player is used)
+
+	Computer bool // Tells if the player is a computer (AI) player, having no user and toon
+}
+
+// Replay describes a synthetic replay.
+//
+// Fields may be modified freely before calling Bytes or WriteFile.
+type Replay struct {
+	BaseBuild int      // Base build of the replay, selects the protocol
+	Version   [3]int64 // Major, minor and revision parts of the game version
+	Loops     int64    // Length of the game in game loops
+	Title     string   // Title (name) of the map
+	Region    int64    // Region ID of the players' toons (1: US, 2: EU, 3: KR etc.)
+	TimeUTC   int64    // Time of the game (Windows FILETIME, 100-nanoseconds since 1601-01-01)
+	Players   []Player // Players of the replay, their index is also their slot and working set slot ID; user IDs are assigned to human players in order
+
+	// Customize, if not nil, is called with the header, details and init data before they are encoded,
+	// kind being s2prot.KindHeader, s2prot.KindDetails or s2prot.KindInitData.
+	// It may modify the Struct, e.g. to set fields not covered by Replay.
+	Customize func(kind string, s s2prot.Struct)
+
+	evts [3][]evt // Added game, message and tracker events
+}
+
+// Event groups, indices of Replay.evts.
+const (
+	groupGame = iota
+	groupMessage
+	groupTracker
+)
+
+// evt describes an event to be added.
+type evt struct {
+	loop   int64
+	userID int64 // User ID of game and message events
+	name   string
+	fields s2prot.Struct
+}
+
+// New creates a new Replay with the specified base build,
+// having 2 players (a Terran winner and a Zerg loser on separate teams), 1 minute long.
+func New(baseBuild int) *Replay {
+	return &Replay{
+		BaseBuild: baseBuild,
+		Loops:     1344, // 1 minute (22.4 loops per second)
+		Title:     "Synthetic Map",
+		Region:    1,
+		TimeUTC:   132000000000000000,
+		Players: []Player{
+			{Name: "Player 1", Race: rep.RaceTerran, TeamID: 0, Result: rep.ResultVictory, Color: rep.ColorRed},
+			{Name: "Player 2", Race: rep.RaceZerg, TeamID: 1, Result: rep.ResultDefeat, Color: rep.ColorBlue},
+		},
+	}
+}
+
+// GameEvt adds a game event of the named type issued by the user at the game loop.
+// Fields not specified are set to their zero values.
+// Before base build 24764 the 1-based player ID (userID+1) is stored instead of the user ID.
+func (r *Replay) GameEvt(loop, userID int64, name string, fields s2prot.Struct) {
+	r.evts[groupGame] = append(r.evts[groupGame], evt{loop, userID, name, fields})
+}
+
+// MessageEvt adds a message event of the named type issued by the user at the game loop.
+// Fields not specified are set to their zero values. See GameEvt about user IDs.
+func (r *Replay) MessageEvt(loop, userID int64, name string, fields s2prot.Struct) {
+	r.evts[groupMessage] = append(r.evts[groupMessage], evt{loop, userID, name, fields})
+}
+
+// TrackerEvt adds a tracker event of the named type at the game loop.
+// Fields not specified are set to their zero values.
+// Tracker events are omitted if the protocol of the base build has no tracker events.
+func (r *Replay) TrackerEvt(loop int64, name string, fields s2prot.Struct) {
+	r.evts[groupTracker] = append(r.evts[groupTracker], evt{loop: loop, name: name, fields: fields})
+}
+
+// Bytes returns the content of the replay file.
+func (r *Replay) Bytes() ([]byte, error) {
+	p := s2prot.GetProtocol(r.BaseBuild)
+	if p == nil {
+		return nil, fmt.Errorf("Unsupported base build: %d", r.BaseBuild)
+	}
+
+	header, err := s2prot.EncodeHeaderWith(p, r.customize(s2prot.KindHeader, r.header(p)))
+	if err != nil {
+		return nil, fmt.Errorf("Failed to encode header: %v", err)
+	}
+
+	files := map[string][]byte{}
+	if files[rewrite.SectionDetails], err = p.EncodeDetails(r.customize(s2prot.KindDetails, r.details(p))); err != nil {
+		return nil, fmt.Errorf("Failed to encode details: %v", err)
+	}
+	if files[rewrite.SectionInitData], err = p.EncodeInitData(r.customize(s2prot.KindInitData, r.initData(p))); err != nil {
+		return nil, fmt.Errorf("Failed to encode init data: %v", err)
+	}
+	if files[rewrite.SectionAttributesEvts], err = p.EncodeAttributesEvts(r.attrEvts(p)); err != nil {
+		return nil, fmt.Errorf("Failed to encode attributes events: %v", err)
+	}
+
+	groups := []struct {
+		section string
+		etypes  []s2prot.EvtType
+		encode  func([]s2prot.Event) ([]byte, error)
+	}{
+		{rewrite.SectionGameEvts, p.GameEvtTypes(), p.EncodeGameEvts},
+		{rewrite.SectionMessageEvts, p.MessageEvtTypes(), p.EncodeMessageEvts},
+		{rewrite.SectionTrackerEvts, p.TrackerEvtTypes(), p.EncodeTrackerEvts},
+	}
+	for i, g := range groups {
+		if i == groupTracker && !p.HasTrackerEvents() {
+			continue
+		}
+		evts, err := events(p, g.etypes, r.evts[i], i != groupTracker)
+		if err != nil {
+			return nil, err
+		}
+		if files[g.section], err = g.encode(evts); err != nil {
+			return nil, fmt.Errorf("Failed to encode %s: %v", g.section, err)
+		}
+	}
+
+	buf := &bytes.Buffer{}
+	if err := rewrite.WriteArchive(buf, header, files); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// WriteFile writes the replay to the named file.
+func (r *Replay) WriteFile(name string) error {
+	data, err := r.Bytes()
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(name, data, 0644)
+}
+
+// Rep returns the replay parsed by the rep package.
+// It's the caller's responsibility to close the returned Rep.
+func (r *Replay) Rep() (*rep.Rep, error) {
+	data, err := r.Bytes()
+	if err != nil {
+		return nil, err
+	}
+	return rep.New(bytes.NewReader(data))
+}
+
+// customize calls Customize (if set) with the Struct, and returns the Struct.
+func (r *Replay) customize(kind string, s s2prot.Struct) s2prot.Struct {
+	if r.Customize != nil {
+		r.Customize(kind, s)
+	}
+	return s
+}
+
+// header returns the replay header.
+func (r *Replay) header(p *s2prot.Protocol) s2prot.Struct {
+	header := zero(p, s2prot.KindHeader, "")
+	header["signature"] = "StarCraft II replay\x1b11"
+	set(header, "version", s2prot.Struct{
+		"flags":     int64(1),
+		"major":     r.Version[0],
+		"minor":     r.Version[1],
+		"revision":  r.Version[2],
+		"build":     int64(r.BaseBuild),
+		"baseBuild": int64(r.BaseBuild),
+	})
+	set(header, "type", int64(2))
+	header["elapsedGameLoops"] = r.Loops
+	set(header, "useScaledTime", true)
+	set(header, "dataBuildNum", int64(r.BaseBuild))
+	return header
+}
+
+// details returns the game details.
+func (r *Replay) details(p *s2prot.Protocol) s2prot.Struct {
+	details := zero(p, s2prot.KindDetails, "")
+	details["title"] = r.Title
+	set(details, "gameSpeed", int64(4)) // Faster
+	set(details, "timeUTC", r.TimeUTC)
+
+	players := make([]interface{}, len(r.Players))
+	for i := range r.Players {
+		pl := &r.Players[i]
+		s := zero(p, s2prot.KindDetails, "playerList.#")
+		s["name"] = pl.Name
+		if !pl.Computer {
+			set(s, "toon", s2prot.Struct{
+				"region":    r.Region,
+				"programId": "\x00\x00S2",
+				"realm":     int64(1),
+				"id":        pl.toonID(i),
+			})
+		}
+		if pl.Race != nil {
+			set(s, "race", pl.Race.Name)
+		}
+		set(s, "control", indexOf(len(rep.Controls), func(j int) bool { return rep.Controls[j] == pl.control() }))
+		set(s, "teamId", pl.TeamID)
+		set(s, "handicap", int64(100))
+		set(s, "result", indexOf(len(rep.Results), func(j int) bool { return rep.Results[j] == pl.Result }))
+		if pl.Color != nil {
+			set(s, "color", s2prot.Struct{
+				"a": int64(255), "r": int64(pl.Color.RGB[0]), "g": int64(pl.Color.RGB[1]), "b": int64(pl.Color.RGB[2]),
+			})
+		}
+		set(s, "workingSetSlotId", int64(i))
+		players[i] = s
+	}
+	details["playerList"] = players
+
+	return details
+}
+
+// initData returns the replay init data.
+func (r *Replay) initData(p *s2prot.Protocol) s2prot.Struct {
+	initData := zero(p, s2prot.KindInitData, "")
+	lobby := initData.Structv("syncLobbyState")
+
+	var users []interface{}
+	for i := range r.Players {
+		if r.Players[i].Computer {
+			continue
+		}
+		s := zero(p, s2prot.KindInitData, "syncLobbyState.userInitialData.#")
+		s["name"] = r.Players[i].Name
+		users = append(users, s)
+	}
+	lobby["userInitialData"] = users
+
+	if desc := lobby.Structv("gameDescription"); desc != nil {
+		set(desc, "gameSpeed", int64(4))
+		set(desc, "maxUsers", int64(len(r.Players)))
+		set(desc, "maxPlayers", int64(len(r.Players)))
+	}
+
+	if state := lobby.Structv("lobbyState"); state != nil {
+		slots := make([]interface{}, len(r.Players))
+		userID := int64(0)
+		for i := range r.Players {
+			pl := &r.Players[i]
+			s := zero(p, s2prot.KindInitData, "syncLobbyState.lobbyState.slots.#")
+			set(s, "control", indexOf(len(rep.Controls), func(j int) bool { return rep.Controls[j] == pl.control() }))
+			if !pl.Computer {
+				set(s, "userId", userID)
+				set(s, "toonHandle", rep.ToonHandle{RegionID: r.Region, ProgramID: "S2", RealmID: 1, ID: pl.toonID(i)}.String())
+				userID++
+			}
+			set(s, "teamId", pl.TeamID)
+			set(s, "handicap", int64(100))
+			set(s, "workingSetSlotId", int64(i))
+			if pl.Race != nil {
+				set(s, "racePref", s2prot.Struct{
+					"race": indexOf(len(rep.Races), func(j int) bool { return rep.Races[j] == pl.Race }),
+				})
+			}
+			if pl.Color != nil {
+				set(s, "colorPref", s2prot.Struct{
+					"color": indexOf(len(rep.Colors), func(j int) bool { return rep.Colors[j] == pl.Color }),
+				})
+			}
+			slots[i] = s
+		}
+		state["slots"] = slots
+	}
+
+	return initData
+}
+
+// attrEvts returns the attributes events, holding the controller of the player slots.
+func (r *Replay) attrEvts(p *s2prot.Protocol) s2prot.Struct {
+	const namespace = 999
+
+	scopes := s2prot.Struct{}
+	for i := range r.Players {
+		controller := "Humn"
+		if r.Players[i].Computer {
+			controller = "Comp"
+		}
+		scopes[fmt.Sprint(int(rep.SlotAttrScope(i)))] = s2prot.Struct{
+			fmt.Sprint(int(rep.AttrController)): s2prot.Struct{
+				"namespace": int64(namespace), "attrid": int64(rep.AttrController), "value": controller,
+			},
+		}
+	}
+
+	attrEvts := s2prot.Struct{"mapNamespace": int64(namespace), "scopes": scopes}
+	if p.BaseBuild() >= 17326 {
+		attrEvts["source"] = int64(0)
+	}
+	return attrEvts
+}
+
+// control returns the control of the player.
+func (pl *Player) control() *rep.Control {
+	if pl.Computer {
+		return rep.ControlComputer
+	}
+	return rep.ControlHuman
+}
+
+// toonID returns the toon ID of the i-th player.
+func (pl *Player) toonID(i int) int64 {
+	if pl.ToonID != 0 {
+		return pl.ToonID
+	}
+	return int64(i + 1)
+}
+
+// events returns the events of a group to be encoded, ordered by game loop.
+// Fields not specified are set to their zero values.
+func events(p *s2prot.Protocol, etypes []s2prot.EvtType, added []evt, userID bool) ([]s2prot.Event, error) {
+	sorted := append([]evt(nil), added...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].loop < sorted[j].loop })
+
+	evts := make([]s2prot.Event, len(sorted))
+	for i, e := range sorted {
+		id := -1
+		for j := range etypes {
+			if etypes[j].Name == e.name {
+				id = j
+				break
+			}
+		}
+		if id < 0 {
+			return nil, fmt.Errorf("Unknown event type: %s", e.name)
+		}
+		et := &etypes[id]
+
+		v, _ := p.ZeroEvt(et, "")
+		s, _ := v.(s2prot.Struct)
+		if s == nil {
+			s = s2prot.Struct{}
+		}
+		for k, v := range e.fields {
+			s[k] = v
+		}
+		s["id"] = int64(id)
+		s["evtTypeName"] = et.Name
+		s["loop"] = e.loop
+		if userID {
+			if p.BaseBuild() < 24764 {
+				s["userid"] = s2prot.Struct{"playerId": e.userID + 1} // 1-based player ID was stored instead of user ID
+			} else {
+				s["userid"] = s2prot.Struct{"userId": e.userID}
+			}
+		}
+		evts[i] = s2prot.Event{Struct: s, EvtType: et}
+	}
+
+	return evts, nil
+}
+
+// zero returns the zero value of a part of a top-level data structure which is known to be a Struct.
+func zero(p *s2prot.Protocol, kind, path string) s2prot.Struct {
+	v, _ := p.ZeroValue(kind, path)
+	s, _ := v.(s2prot.Struct)
+	if s == nil {
+		s = s2prot.Struct{}
+	}
+	return s
+}
+
+// set sets the value of a field if the field exists in the Struct (fields vary between protocols).
+// Struct values are merged into existing Struct fields.
+func set(s s2prot.Struct, key string, v interface{}) {
+	old, ok := s[key]
+	if !ok {
+		return
+	}
+	if vs, ok := v.(s2prot.Struct); ok {
+		if olds, ok := old.(s2prot.Struct); ok {
+			for k, v := range vs {
+				set(olds, k, v)
+			}
+			return
+		}
+	}
+	s[key] = v
+}
+
+// indexOf returns the index of the first element of a slice of length n for which f returns true,
+// 0 if there is no such element.
+func indexOf(n int, f func(i int) bool) int64 {
+	for i := 0; i < n; i++ {
+		if f(i) {
+			return int64(i)
+		}
+	}
+	return 0
+}
@@ -0,0 +1,133 @@
+package reptest
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/icza/s2prot"
+	"github.com/icza/s2prot/rep"
+)
+
+func TestReplay(t *testing.T) {
+	for _, bb := range []int{15405, 39576, 47185, 80949} {
+		r := New(bb)
+		r.Title = "Test Map"
+		r.Loops = 2000
+		r.Players[1].Name = "[TAG]Second"
+		r.GameEvt(500, 1, "UserFinishedLoadingSync", nil)
+		r.GameEvt(16, 0, "UserFinishedLoadingSync", nil)
+		r.MessageEvt(100, 1, "Chat", s2prot.Struct{"string": "gl hf"})
+		r.TrackerEvt(0, "PlayerSetup", s2prot.Struct{"playerId": int64(1)})
+
+		parsed, err := r.Rep()
+		if err != nil {
+			t.Fatalf("[%d] Failed to parse replay: %v", bb, err)
+		}
+
+		if got := parsed.Header.BaseBuild(); got != int64(bb) {
+			t.Errorf("[%d] Expected base build: %d, got: %d", bb, bb, got)
+		}
+		if got := parsed.Header.Loops(); got != 2000 {
+			t.Errorf("[%d] Expected loops: %d, got: %d", bb, 2000, got)
+		}
+		if got := parsed.Details.Title(); got != "Test Map" {
+			t.Errorf("[%d] Expected title: %s, got: %s", bb, "Test Map", got)
+		}
+
+		players := parsed.Details.Players()
+		if len(players) != 2 {
+			t.Fatalf("[%d] Expected %d players, got: %d", bb, 2, len(players))
+		}
+		for i, pl := range players {
+			exp := &r.Players[i]
+			if pl.Name != exp.Name || pl.Race() != exp.Race || pl.TeamID() != exp.TeamID || pl.Result() != exp.Result {
+				t.Errorf("[%d] Player mismatch, expected: %+v, got: %v", bb, exp, pl)
+			}
+		}
+		if got := len(parsed.InitData.LobbyState.Slots); got != 2 {
+			t.Errorf("[%d] Expected %d slots, got: %d", bb, 2, got)
+		}
+
+		if len(parsed.GameEvts) != 2 || parsed.GameEvts[0].Loop() != 16 || parsed.GameEvts[1].Loop() != 500 {
+			t.Errorf("[%d] Expected 2 game events ordered by loop, got: %v", bb, parsed.GameEvts)
+		}
+		if len(parsed.MessageEvts) != 1 || parsed.MessageEvts[0].Stringv("string") != "gl hf" {
+			t.Errorf("[%d] Expected 1 chat message, got: %v", bb, parsed.MessageEvts)
+		}
+		expTracker := 0
+		if s2prot.GetProtocol(bb).HasTrackerEvents() {
+			expTracker = 1
+		}
+		if got := len(parsed.TrackerEvts.Evts); got != expTracker {
+			t.Errorf("[%d] Expected %d tracker events, got: %d", bb, expTracker, got)
+		}
+		if parsed.GameEvtsErr || parsed.MessageEvtsErr || parsed.TrackerEvtsErr {
+			t.Errorf("[%d] Unexpected event decoding errors!", bb)
+		}
+
+		parsed.Close()
+	}
+}
+
+func TestReplayErrors(t *testing.T) {
+	if _, err := New(1).Bytes(); err == nil {
+		t.Error("Expected error for unsupported base build!")
+	}
+
+	r := New(80949)
+	r.GameEvt(0, 0, "NoSuchEvent", nil)
+	if _, err := r.Bytes(); err == nil {
+		t.Error("Expected error for unknown event type!")
+	}
+}
+
+func TestSet(t *testing.T) {
+	s := s2prot.Struct{"a": int64(1), "b": s2prot.Struct{"c": nil}}
+	set(s, "a", int64(2))
+	set(s, "x", int64(3))
+	set(s, "b", s2prot.Struct{"c": "v", "d": true})
+
+	if s.Int("a") != 2 {
+		t.Errorf("Expected: %d, got: %d", 2, s.Int("a"))
+	}
+	if _, ok := s["x"]; ok {
+		t.Error("Expected missing field to remain missing!")
+	}
+	b := s.Structv("b")
+	if b.Stringv("c") != "v" || len(b) != 1 {
+		t.Errorf("Expected merged Struct, got: %v", b)
+	}
+	if got := indexOf(len(rep.Races), func(i int) bool { return rep.Races[i] == rep.RaceProtoss }); got != 2 {
+		t.Errorf("Expected: %d, got: %d", 2, got)
+	}
+}
+
+func TestOldBuildUserIDs(t *testing.T) {
+	r := New(23260)
+	r.GameEvt(16, 0, "Cmd", nil)
+	r.GameEvt(32, 1, "Cmd", nil)
+	data, err := r.Bytes()
+	if err != nil {
+		t.Fatalf("Failed to create replay: %v", err)
+	}
+	parsed, err := rep.New(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Failed to parse replay: %v", err)
+	}
+	defer parsed.Close()
+
+	for i, e := range parsed.GameEvts {
+		if got := e.Int("userid", "playerId"); got != int64(i+1) {
+			t.Errorf("[%d] Expected player ID: %d, got: %d", i, i+1, got)
+		}
+	}
+	cmds := parsed.Commands()
+	if len(cmds) != 2 {
+		t.Fatalf("Expected %d commands, got: %d", 2, len(cmds))
+	}
+	for i, c := range cmds {
+		if c.UserID != int64(i) {
+			t.Errorf("[%d] Expected user ID: %d, got: %d", i, i, c.UserID)
+		}
+	}
+}
@@ -0,0 +1,268 @@
+/*
+
+Anonymizing replays.
+
+*/
+
+package rewrite
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/icza/s2prot"
+	"github.com/icza/s2prot/rep"
+)
+
+// Options holds the options of the anonymization.
+type Options struct {
+	// Name returns the name to use for the n-th (1-based) distinct human participant.
+	// If nil, names are "Player 1", "Player 2" etc.
+	Name func(n int) string
+
+	// KeepChat tells to keep chat messages. By default chat messages are removed.
+	KeepChat bool
+
+	// ChatFilter is applied to the kept chat messages (if KeepChat is set), e.g. to drop or redact
+	// some of them. Optional.
+	ChatFilter rep.ChatFilter
+}
+
+// Anonymize writes an anonymized version of the replay read from src to dst.
+//
+// Names, clan tags and clan logos of human participants are replaced / removed, and their toon handles
+// are replaced with pseudo handles (same region and realm, ID being the participant's sequence number).
+// The same player gets the same pseudo name and toon in all sections of the replay, including the
+// GameUserJoin and BankSignature game events (bank signatures are removed). Hotkey profile names of
+// UserOptions game events (which are chosen by users) are removed. Sections not decoded by s2prot
+// that contain player names (the battle lobby and the resumable events) are removed.
+// Names of computer players are kept (unless they are named after a participating user). Chat messages are removed unless opts.KeepChat is set
+// (kept chat messages are filtered by opts.ChatFilter).
+// opts may be nil, in which case the default options are used.
+//
+// Errors of the rep package (e.g. rep.ErrInvalidRepFile) are returned if src is not a valid, supported replay.
+func Anonymize(dst io.Writer, src io.ReadSeeker, opts *Options) error {
+	if opts == nil {
+		opts = &Options{}
+	}
+
+	return rewrite(dst, src, func(a *archive, p *s2prot.Protocol) error {
+		// Decode all affected sections first: user names of init data are needed to anonymize details.
+		decoded := map[string]s2prot.Struct{}
+		userNames := map[string]bool{}
+		for _, name := range []string{SectionDetails, SectionDetailsBackup, SectionInitData, SectionInitDataBackup} {
+			data, ok := a.files[name]
+			if !ok {
+				continue
+			}
+			if name == SectionDetails || name == SectionDetailsBackup {
+				decoded[name] = p.DecodeDetails(data)
+				continue
+			}
+			initData := p.DecodeInitData(data)
+			decoded[name] = initData
+			for _, v := range initData.Query("syncLobbyState.userInitialData.#.name").Array() {
+				if userName, _ := v.(string); userName != "" {
+					userNames[userName] = true
+				}
+			}
+		}
+
+		ps := newPseudonyms(opts.Name)
+		for _, name := range []string{SectionDetails, SectionDetailsBackup, SectionInitData, SectionInitDataBackup} {
+			s, ok := decoded[name]
+			if !ok {
+				continue
+			}
+			var data []byte
+			var err error
+			if name == SectionDetails || name == SectionDetailsBackup {
+				anonymizeDetails(s, ps, userNames)
+				data, err = p.EncodeDetails(s)
+			} else {
+				anonymizeInitData(s, ps)
+				data, err = p.EncodeInitData(s)
+			}
+			if err != nil {
+				return fmt.Errorf("Failed to encode %s: %v", name, err)
+			}
+			a.files[name] = data
+		}
+
+		anonymizeEvt := func(e *s2prot.Event) bool {
+			anonymizeGameEvt(e, ps)
+			return true
+		}
+		if err := a.filterEvts(SectionGameEvts, p.DecodeGameEvts, p.EncodeGameEvts, anonymizeEvt); err != nil {
+			return err
+		}
+
+		switch {
+		case !opts.KeepChat:
+			if err := a.filterEvts(SectionMessageEvts, p.DecodeMessageEvts, p.EncodeMessageEvts, isNotChat); err != nil {
+				return err
+			}
+		case len(opts.ChatFilter) > 0:
+			if err := a.filterEvts(SectionMessageEvts, p.DecodeMessageEvts, p.EncodeMessageEvts, opts.ChatFilter.Filter); err != nil {
+				return err
+			}
+		}
+
+		// Contain player names:
+		a.remove(SectionBattleLobby)
+		a.remove(SectionResumableEvts)
+
+		return nil
+	})
+}
+
+// AnonymizeFile anonymizes the replay file src and writes the result to the file dst.
+// See Anonymize for details.
+func AnonymizeFile(dst, src string, opts *Options) error {
+	return rewriteFile(dst, src, func(w io.Writer, r io.ReadSeeker) error {
+		return Anonymize(w, r, opts)
+	})
+}
+
+// pseudonyms assigns pseudo names and toon IDs to participants.
+type pseudonyms struct {
+	name  func(n int) string       // Name of the n-th participant
+	count int                      // Number of participants having a pseudonym
+	ids   map[rep.ToonHandle]int64 // Pseudo IDs mapped from real toon handles
+	names map[string]int64         // Pseudo IDs mapped from real (bare) names
+}
+
+// newPseudonyms creates a new pseudonyms.
+func newPseudonyms(name func(n int) string) *pseudonyms {
+	if name == nil {
+		name = func(n int) string { return fmt.Sprint("Player ", n) }
+	}
+	return &pseudonyms{name: name, ids: map[rep.ToonHandle]int64{}, names: map[string]int64{}}
+}
+
+// next returns the next pseudo ID.
+func (ps *pseudonyms) next() int64 {
+	ps.count++
+	return int64(ps.count)
+}
+
+// id returns the pseudo ID of a toon handle, assigning a new one if needed.
+func (ps *pseudonyms) id(h rep.ToonHandle) int64 {
+	id, ok := ps.ids[h]
+	if !ok {
+		id = ps.next()
+		ps.ids[h] = id
+	}
+	return id
+}
+
+// handle returns the pseudo toon handle of a toon handle string (as it appears in init data).
+// The pseudo ID is also returned. Empty string is returned as-is with a 0 ID.
+func (ps *pseudonyms) handle(s string) (string, int64) {
+	if s == "" {
+		return s, 0
+	}
+	h, err := rep.ParseToonHandle(s)
+	if err != nil {
+		return "", ps.next() // Unknown format, do not keep it
+	}
+	id := ps.id(h)
+	h.ID = id
+	return h.String(), id
+}
+
+// anonymizeDetails anonymizes the human players of the game details.
+// Computer players named after a user (userNames holds the names of users) are also anonymized.
+func anonymizeDetails(details s2prot.Struct, ps *pseudonyms, userNames map[string]bool) {
+	d := rep.Details{Struct: details}
+	for _, pl := range d.Players() {
+		_, bareName := rep.SplitClanTag(pl.Name)
+		if pl.Control() != rep.ControlHuman && !userNames[bareName] {
+			continue
+		}
+		h, _ := rep.ParseToonHandle(pl.Toon.String())
+		id := ps.id(h)
+		ps.names[bareName] = id
+		pl.Struct["name"] = ps.name(int(id))
+		pl.Toon.Struct["id"] = id
+	}
+}
+
+// anonymizeInitData anonymizes the lobby slots and the user initial data of the init data.
+func anonymizeInitData(initData s2prot.Struct, ps *pseudonyms) {
+	userIDs := map[int64]int64{} // Pseudo IDs mapped from user IDs
+
+	for _, v := range initData.Query("syncLobbyState.lobbyState.slots").Array() {
+		slot := v.(s2prot.Struct)
+		handle, id := ps.handle(slot.Stringv("toonHandle"))
+		if id == 0 {
+			continue
+		}
+		slot["toonHandle"] = handle
+		if userID, ok := slot["userId"].(int64); ok {
+			userIDs[userID] = id
+		}
+	}
+
+	for userID, v := range initData.Query("syncLobbyState.userInitialData").Array() {
+		user := v.(s2prot.Struct)
+		if user.Stringv("name") == "" {
+			continue // Unused entry
+		}
+		handle, id := ps.handle(user.Stringv("toonHandle"))
+		if id == 0 {
+			// No toon handle: match by user ID (slots) or by name (details)
+			if id = userIDs[int64(userID)]; id == 0 {
+				if id = ps.names[user.Stringv("name")]; id == 0 {
+					id = ps.next()
+				}
+			}
+		}
+		if _, ok := user["toonHandle"]; ok {
+			user["toonHandle"] = handle
+		}
+		user["name"] = ps.name(int(id))
+		for _, key := range []string{"clanTag", "clanLogo"} {
+			if _, ok := user[key]; ok {
+				user[key] = nil
+			}
+		}
+	}
+}
+
+// anonymizeGameEvt anonymizes a game event carrying identities of users.
+func anonymizeGameEvt(e *s2prot.Event, ps *pseudonyms) {
+	switch e.Name {
+	case "GameUserJoin":
+		handle, id := ps.handle(e.Stringv("toonHandle"))
+		if id == 0 {
+			// No toon handle: match by name (details)
+			if id = ps.names[e.Stringv("name")]; id == 0 {
+				id = ps.next()
+			}
+		}
+		if e.Struct["toonHandle"] != nil {
+			e.Struct["toonHandle"] = handle
+		}
+		e.Struct["name"] = ps.name(int(id))
+		for _, key := range []string{"clanTag", "clanLogo"} {
+			if _, ok := e.Struct[key]; ok {
+				e.Struct[key] = nil
+			}
+		}
+	case "BankSignature":
+		if _, ok := e.Struct["toonHandle"]; ok {
+			e.Struct["toonHandle"], _ = ps.handle(e.Stringv("toonHandle"))
+		}
+		e.Struct["signature"] = []interface{}{}
+	case "UserOptions":
+		if _, ok := e.Struct["hotkeyProfile"]; ok {
+			e.Struct["hotkeyProfile"] = ""
+		}
+	}
+}
+
+// isNotChat tells if an event is not a chat message.
+func isNotChat(e *s2prot.Event) bool {
+	return e.Name != "Chat"
+}
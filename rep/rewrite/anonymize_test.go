@@ -0,0 +1,116 @@
+package rewrite
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/icza/mpq"
+	"github.com/icza/s2prot"
+	"github.com/icza/s2prot/rep"
+)
+
+func TestAnonymizeStructs(t *testing.T) {
+	toon := func(id int64) s2prot.Struct {
+		return s2prot.Struct{"region": int64(2), "programId": "\x00\x00S2", "realm": int64(1), "id": id}
+	}
+	details := s2prot.Struct{"playerList": []interface{}{
+		s2prot.Struct{"name": "&lt;CLAN&gt;<sp/>Alice", "control": int64(2), "toon": toon(111)},
+		s2prot.Struct{"name": "Bob", "control": int64(2), "toon": toon(222)},
+		s2prot.Struct{"name": "A.I. 1 (Elite)", "control": int64(3), "toon": toon(0)},
+	}}
+	initData := s2prot.Struct{"syncLobbyState": s2prot.Struct{
+		"lobbyState": s2prot.Struct{"slots": []interface{}{
+			s2prot.Struct{"toonHandle": "2-S2-1-222", "userId": int64(1)},
+			s2prot.Struct{"toonHandle": "2-S2-1-111", "userId": int64(0)},
+			s2prot.Struct{"toonHandle": "", "userId": nil},
+		}},
+		"userInitialData": []interface{}{
+			s2prot.Struct{"name": "Alice", "clanTag": "CLAN", "clanLogo": "logo", "toonHandle": ""},
+			s2prot.Struct{"name": "Bob", "clanTag": nil, "clanLogo": nil, "toonHandle": ""},
+			s2prot.Struct{"name": "Observer", "clanTag": nil, "clanLogo": nil, "toonHandle": ""},
+			s2prot.Struct{"name": "", "clanTag": nil, "clanLogo": nil, "toonHandle": ""},
+		},
+	}}
+
+	ps := newPseudonyms(func(n int) string { return fmt.Sprint("P", n) })
+	anonymizeDetails(details, ps, map[string]bool{"Alice": true, "Bob": true, "Observer": true})
+	anonymizeInitData(initData, ps)
+
+	for i, exp := range []string{"P1", "P2", "A.I. 1 (Elite)"} {
+		if got := details.Query(fmt.Sprint("playerList.", i, ".name")).String(); got != exp {
+			t.Errorf("[%d] Expected player name: %q, got: %q", i, exp, got)
+		}
+	}
+	for i, exp := range []int64{1, 2, 0} {
+		if got := details.Query(fmt.Sprint("playerList.", i, ".toon.id")).Int(); got != exp {
+			t.Errorf("[%d] Expected toon id: %d, got: %d", i, exp, got)
+		}
+	}
+	for i, exp := range []string{"2-S2-1-2", "2-S2-1-1", ""} {
+		if got := initData.Query(fmt.Sprint("syncLobbyState.lobbyState.slots.", i, ".toonHandle")).String(); got != exp {
+			t.Errorf("[%d] Expected toon handle: %q, got: %q", i, exp, got)
+		}
+	}
+	for i, exp := range []string{"P1", "P2", "P3", ""} {
+		user := initData.Query(fmt.Sprint("syncLobbyState.userInitialData.", i)).Struct()
+		if got := user.Stringv("name"); got != exp {
+			t.Errorf("[%d] Expected user name: %q, got: %q", i, exp, got)
+		}
+		if user["clanTag"] != nil || user["clanLogo"] != nil {
+			t.Errorf("[%d] Expected no clan tag and logo, got: %v, %v", i, user["clanTag"], user["clanLogo"])
+		}
+	}
+}
+
+func TestAnonymizeComputerNamedAfterUser(t *testing.T) {
+	details := s2prot.Struct{"playerList": []interface{}{
+		s2prot.Struct{"name": "Host", "control": int64(3), "toon": s2prot.Struct{"region": int64(1), "programId": "S2", "realm": int64(1), "id": int64(5)}},
+	}}
+	anonymizeDetails(details, newPseudonyms(nil), map[string]bool{"Host": true})
+	if got := details.Query("playerList.0.name").String(); got != "Player 1" {
+		t.Errorf("Expected: %q, got: %q", "Player 1", got)
+	}
+}
+
+func TestIsNotChat(t *testing.T) {
+	for name, exp := range map[string]bool{"Chat": false, "Ping": true} {
+		if got := isNotChat(&s2prot.Event{EvtType: &s2prot.EvtType{Name: name}}); got != exp {
+			t.Errorf("[%s] Expected: %v, got: %v", name, exp, got)
+		}
+	}
+}
+
+func TestAnonymizeInvalid(t *testing.T) {
+	if err := Anonymize(&bytes.Buffer{}, bytes.NewReader([]byte("invalid")), nil); err != rep.ErrInvalidRepFile {
+		t.Errorf("Expected: %v, got: %v", rep.ErrInvalidRepFile, err)
+	}
+}
+
+func TestAnonymizeChatFilter(t *testing.T) {
+	p := s2prot.GetProtocol(80949)
+	src := testReplay(t, p)
+
+	buf := &bytes.Buffer{}
+	opts := &Options{KeepChat: true, ChatFilter: rep.ChatFilter{rep.ChatRedactUsers(0)}}
+	if err := Anonymize(buf, bytes.NewReader(src), opts); err != nil {
+		t.Fatalf("Failed to anonymize: %v", err)
+	}
+
+	m, err := mpq.New(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatalf("Failed to open anonymized replay: %v", err)
+	}
+	defer m.Close()
+
+	data, _ := m.FileByName(SectionMessageEvts)
+	evts, err := p.DecodeMessageEvts(data)
+	if err != nil || len(evts) != 2 {
+		t.Fatalf("Expected 2 message events, got: %d, %v", len(evts), err)
+	}
+	for i, e := range evts {
+		if got := e.Stringv("string"); got != rep.RedactedChat {
+			t.Errorf("[%d] Expected: %q, got: %q", i, rep.RedactedChat, got)
+		}
+	}
+}
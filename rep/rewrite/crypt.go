@@ -0,0 +1,45 @@
+/*
+
+Encryption of the MPQ hash and block tables.
+
+*/
+
+package rewrite
+
+// A number table used by the encryption algorithm.
+var cryptTable = make([]uint32, 0x500)
+
+func init() {
+	// Same table as used by the MPQ decryption and hashing algorithms.
+	var seed uint32 = 0x00100001
+	for index1 := uint32(0); index1 < 0x100; index1++ {
+		for i, index2 := 0, index1; i < 5; i, index2 = i+1, index2+0x100 {
+			seed = (seed*125 + 3) % 0x2aaaab
+			temp := (seed & 0xffff) << 0x10
+			seed = (seed*125 + 3) % 0x2aaaab
+			cryptTable[index2] = temp | (seed & 0xffff)
+		}
+	}
+}
+
+// encrypt encrypts the given data with the specified key, the inverse of the MPQ decryption.
+// The result is written back into the input data slice. Length of data must be a multiple of 4.
+func encrypt(data []byte, key uint32) {
+	seed1, seed2 := key, uint32(0xeeeeeeee)
+
+	for i := 0; i+3 < len(data); i += 4 {
+		seed2 += cryptTable[0x400+(seed1&0xff)]
+
+		// littleEndian byte order:
+		plain := uint32(data[i]) | uint32(data[i+1])<<8 | uint32(data[i+2])<<16 | uint32(data[i+3])<<24
+		ch := plain ^ (seed1 + seed2)
+
+		seed1 = ((^seed1 << 0x15) + 0x11111111) | (seed1 >> 0x0B)
+		seed2 = plain + seed2 + (seed2 << 5) + 3
+
+		data[i] = byte(ch)
+		data[i+1] = byte(ch >> 8)
+		data[i+2] = byte(ch >> 16)
+		data[i+3] = byte(ch >> 24)
+	}
+}
@@ -0,0 +1,102 @@
+package rewrite_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/icza/mpq"
+	"github.com/icza/s2prot"
+	"github.com/icza/s2prot/rep"
+	"github.com/icza/s2prot/rep/reptest"
+	"github.com/icza/s2prot/rep/rewrite"
+)
+
+// readFiles returns the user data and the files listed in the list file of the archive.
+func readFiles(t *testing.T, data []byte) (userData []byte, files map[string][]byte) {
+	m, err := mpq.New(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Failed to open archive: %v", err)
+	}
+	defer m.Close()
+
+	list, err := m.FileByName("(listfile)")
+	if err != nil {
+		t.Fatalf("Failed to read list file: %v", err)
+	}
+	files = map[string][]byte{}
+	for _, name := range strings.Fields(string(list)) {
+		if files[name], err = m.FileByName(name); err != nil {
+			t.Fatalf("Failed to read %s: %v", name, err)
+		}
+	}
+	return m.UserData(), files
+}
+
+func TestAnonymizeNoLeaks(t *testing.T) {
+	r := reptest.New(80949)
+	r.Players[0].Name, r.Players[0].ToonID = "[CLNX]Alicezyx", 987654321
+	r.Players[1].Name, r.Players[1].ToonID = "Bobqwv", 876543219
+	r.GameEvt(0, 0, "UserOptions", s2prot.Struct{"hotkeyProfile": "Alicezyx Keys"})
+	r.GameEvt(10, 2, "GameUserJoin", s2prot.Struct{"name": "Carolzyx", "toonHandle": "1-S2-1-765432198", "clanTag": "CLNX"})
+	r.GameEvt(20, 0, "BankSignature", s2prot.Struct{"toonHandle": "1-S2-1-987654321", "signature": []interface{}{int64(42)}})
+	data, err := r.Bytes()
+	if err != nil {
+		t.Fatalf("Failed to create replay: %v", err)
+	}
+
+	// Add a section not decoded by s2prot, containing names:
+	userData, files := readFiles(t, data)
+	files[rewrite.SectionResumableEvts] = []byte("Alicezyx Bobqwv")
+	buf := &bytes.Buffer{}
+	if err := rewrite.WriteArchive(buf, userData, files); err != nil {
+		t.Fatalf("Failed to write archive: %v", err)
+	}
+
+	anon := &bytes.Buffer{}
+	if err := rewrite.Anonymize(anon, bytes.NewReader(buf.Bytes()), nil); err != nil {
+		t.Fatalf("Failed to anonymize: %v", err)
+	}
+
+	// No original name, clan tag or toon ID may remain in any section:
+	userData, files = readFiles(t, anon.Bytes())
+	files["(user data)"] = userData
+	for name, content := range files {
+		for _, s := range []string{"Alicezyx", "Bobqwv", "Carolzyx", "CLNX", "987654321", "876543219", "765432198"} {
+			if bytes.Contains(content, []byte(s)) {
+				t.Errorf("Section %s contains %q", name, s)
+			}
+		}
+	}
+	if _, ok := files[rewrite.SectionResumableEvts]; ok {
+		t.Errorf("Expected no %s section", rewrite.SectionResumableEvts)
+	}
+
+	// Pseudonyms must be consistent across sections:
+	parsed, err := rep.New(bytes.NewReader(anon.Bytes()))
+	if err != nil {
+		t.Fatalf("Failed to parse anonymized replay: %v", err)
+	}
+	defer parsed.Close()
+	for i, pl := range parsed.Details.Players() {
+		if exp := int64(i + 1); pl.Toon.ID() != exp {
+			t.Errorf("[%d] Expected toon ID: %d, got: %d", i, exp, pl.Toon.ID())
+		}
+	}
+	for _, e := range parsed.GameEvts {
+		switch e.Name {
+		case "GameUserJoin":
+			if e.Stringv("name") != "Player 3" || e.Stringv("toonHandle") != "1-S2-1-3" || e.Struct["clanTag"] != nil {
+				t.Errorf("Unexpected GameUserJoin: %v", e.Struct)
+			}
+		case "BankSignature":
+			if e.Stringv("toonHandle") != "1-S2-1-1" || len(e.Array("signature")) != 0 {
+				t.Errorf("Unexpected BankSignature: %v", e.Struct)
+			}
+		case "UserOptions":
+			if e.Stringv("hotkeyProfile") != "" {
+				t.Errorf("Unexpected UserOptions: %v", e.Struct)
+			}
+		}
+	}
+}
@@ -0,0 +1,282 @@
+/*
+
+A minimal MPQ archive writer, producing archives in the layout of SC2Replay files.
+
+*/
+
+package rewrite
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/icza/mpq"
+)
+
+// Block table entry flags used by the writer.
+const (
+	flagFile            = 0x80000000 // Block is a file
+	flagCompressedMulti = 0x00000200 // File is compressed (sectors are prefixed with the compression method)
+)
+
+// Encryption keys of the hash and block tables: hashes of "(hash table)" and "(block table)".
+const (
+	hashTableKey  = 0xc3af3770
+	blockTableKey = 0xec83b3a3
+)
+
+const (
+	sectorSizeShift = 5                      // Sector size exponent used by SC2Replay files
+	sectorSize      = 512 << sectorSizeShift // Size of the file sectors
+	headerSize      = 0xd0                   // Size of the archive header (format version 3)
+	userDataAlign   = 0x200                  // Alignment of the archive header following the user data
+	minHeaderOffset = 0x400                  // Min offset of the archive header (as written by the game)
+	compressZlib    = 0x02                   // Compression method prefix of zlib compressed sectors
+	emptyHashEntry  = 0xffffffff             // Block index of empty hash table entries
+
+	listFileName   = "(listfile)"   // Name of the file listing the names of the files
+	listFileSep    = "\r\n"         // Separator of names in the list file
+	attributesName = "(attributes)" // Name of the file attributes file (not written)
+)
+
+// Magic bytes of the user data section and the archive header.
+var (
+	userDataMagic = []byte("MPQ\x1b")
+	headerMagic   = []byte("MPQ\x1a")
+)
+
+// archive holds the content of an MPQ archive.
+type archive struct {
+	userData []byte            // Data of the user data section, the replay header
+	names    []string          // Names of the files, in order
+	files    map[string][]byte // Content of the files, mapped from name
+}
+
+// readArchive reads all files of an MPQ archive listed in its list file.
+// The list file itself and the attributes file are not included.
+func readArchive(m *mpq.MPQ) (*archive, error) {
+	list, err := m.FileByName(listFileName)
+	if err != nil {
+		return nil, err
+	}
+
+	a := &archive{userData: m.UserData(), files: map[string][]byte{}}
+	for _, name := range strings.Split(string(list), listFileSep) {
+		if name = strings.TrimSpace(name); name == "" || name == listFileName || name == attributesName {
+			continue
+		}
+		data, err := m.FileByName(name)
+		if err != nil {
+			return nil, err
+		}
+		if data == nil {
+			continue // Listed but not present
+		}
+		a.add(name, data)
+	}
+
+	return a, nil
+}
+
+// add adds or replaces a file.
+func (a *archive) add(name string, data []byte) {
+	if _, ok := a.files[name]; !ok {
+		a.names = append(a.names, name)
+	}
+	a.files[name] = data
+}
+
+// remove removes a file.
+func (a *archive) remove(name string) {
+	if _, ok := a.files[name]; !ok {
+		return
+	}
+	delete(a.files, name)
+	for i, n := range a.names {
+		if n == name {
+			a.names = append(a.names[:i], a.names[i+1:]...)
+			break
+		}
+	}
+}
+
+// WriteArchive writes a new MPQ archive having the specified user data (the replay header)
+// and files (mapped from name), in the layout of SC2Replay files.
+// Files are stored in the order of their names.
+func WriteArchive(w io.Writer, userData []byte, files map[string][]byte) error {
+	a := &archive{userData: userData, files: map[string][]byte{}}
+
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		a.add(name, files[name])
+	}
+
+	return a.writeTo(w)
+}
+
+// header is the archive header (format version 3), excluding the magic.
+type header struct {
+	Size, ArchiveSize                   uint32
+	FormatVersion, SectorSizeShift      uint16
+	HashTableOffset, BlockTableOffset   uint32
+	HashTableEntries, BlockTableEntries uint32
+	ExtBlockTableOffset                 uint64
+	HashTableOffsetHigh                 uint16
+	BlockTableOffsetHigh                uint16
+	ArchiveSize64                       uint64
+	BetTableOffset, HetTableOffset      uint64
+	HashTableSize64, BlockTableSize64   uint64
+	ExtBlockTableSize64                 uint64
+	HetTableSize64, BetTableSize64      uint64
+	RawChunkSize                        uint32
+	MD5s                                [6][16]byte // MD5 checksums of the tables, not verified by the game
+}
+
+// blockEntry is an entry of the block table.
+type blockEntry struct {
+	Offset, Size, FileSize, Flags uint32
+}
+
+// hashEntry is an entry of the hash table.
+type hashEntry struct {
+	NameA, NameB       uint32
+	Language, Platform uint16
+	BlockIndex         uint32
+}
+
+// writeTo writes the archive, preceded by the user data section.
+// A list file is generated and included in the archive.
+func (a *archive) writeTo(w io.Writer) error {
+	names := append(append([]string(nil), a.names...), listFileName)
+	list := strings.Join(a.names, listFileSep) + listFileSep
+
+	// Data section: file blocks following the header
+	data := &bytes.Buffer{}
+	blocks := make([]blockEntry, len(names))
+	for i, name := range names {
+		content := a.files[name]
+		if name == listFileName {
+			content = []byte(list)
+		}
+		be := &blocks[i]
+		be.Offset = uint32(headerSize + data.Len())
+		be.FileSize = uint32(len(content))
+		be.Flags = flagFile
+		if len(content) > 0 {
+			be.Flags |= flagCompressedMulti
+			if err := writeSectors(data, content); err != nil {
+				return err
+			}
+		}
+		be.Size = uint32(headerSize+data.Len()) - be.Offset
+	}
+
+	// Hash table: size must be a power of 2, keep it at most half full
+	hashCount := 16
+	for hashCount < 2*len(names) {
+		hashCount *= 2
+	}
+	hashes := make([]hashEntry, hashCount)
+	for i := range hashes {
+		hashes[i] = hashEntry{emptyHashEntry, emptyHashEntry, 0xffff, 0xffff, emptyHashEntry}
+	}
+	for i, name := range names {
+		h1, h2, h3 := mpq.FileNameHash(name)
+		j := int(h1) & (hashCount - 1)
+		for hashes[j].BlockIndex != emptyHashEntry {
+			j = (j + 1) & (hashCount - 1)
+		}
+		hashes[j] = hashEntry{NameA: h2, NameB: h3, BlockIndex: uint32(i)}
+	}
+
+	hashTable := &bytes.Buffer{}
+	binary.Write(hashTable, binary.LittleEndian, hashes)
+	blockTable := &bytes.Buffer{}
+	binary.Write(blockTable, binary.LittleEndian, blocks)
+	encrypt(hashTable.Bytes(), hashTableKey)
+	encrypt(blockTable.Bytes(), blockTableKey)
+
+	hashTableOffset := uint32(headerSize + data.Len())
+	blockTableOffset := hashTableOffset + uint32(hashTable.Len())
+	archiveSize := uint64(blockTableOffset) + uint64(blockTable.Len())
+
+	// User data section
+	headerOffset := uint32(0)
+	out := &bytes.Buffer{}
+	if a.userData != nil {
+		headerOffset = uint32(12+len(a.userData)+userDataAlign-1) / userDataAlign * userDataAlign
+		if headerOffset < minHeaderOffset {
+			headerOffset = minHeaderOffset
+		}
+		out.Write(userDataMagic)
+		binary.Write(out, binary.LittleEndian, []uint32{uint32(len(a.userData)), headerOffset})
+		out.Write(a.userData)
+		out.Write(make([]byte, int(headerOffset)-out.Len()))
+	}
+
+	// Archive header
+	out.Write(headerMagic)
+	binary.Write(out, binary.LittleEndian, &header{
+		Size:              headerSize,
+		ArchiveSize:       uint32(archiveSize),
+		FormatVersion:     3,
+		SectorSizeShift:   sectorSizeShift,
+		HashTableOffset:   hashTableOffset,
+		BlockTableOffset:  blockTableOffset,
+		HashTableEntries:  uint32(hashCount),
+		BlockTableEntries: uint32(len(blocks)),
+		ArchiveSize64:     archiveSize,
+		HashTableSize64:   uint64(hashTable.Len()),
+		BlockTableSize64:  uint64(blockTable.Len()),
+	})
+
+	for _, b := range [][]byte{data.Bytes(), hashTable.Bytes(), blockTable.Bytes()} {
+		out.Write(b)
+	}
+
+	_, err := w.Write(out.Bytes())
+	return err
+}
+
+// writeSectors writes the content of a file split into sectors, preceded by the sector offset table.
+// Sectors are zlib compressed, or stored as-is if compression does not reduce their size.
+func writeSectors(w *bytes.Buffer, content []byte) error {
+	count := (len(content) + sectorSize - 1) / sectorSize
+	offsets := make([]uint32, count+1)
+	sectors := &bytes.Buffer{}
+	for i := 0; i < count; i++ {
+		offsets[i] = uint32(4*len(offsets) + sectors.Len())
+		sector := content[i*sectorSize:]
+		if len(sector) > sectorSize {
+			sector = sector[:sectorSize]
+		}
+
+		compressed := &bytes.Buffer{}
+		compressed.WriteByte(compressZlib)
+		zw, _ := zlib.NewWriterLevel(compressed, zlib.BestCompression)
+		if _, err := zw.Write(sector); err != nil {
+			return err
+		}
+		if err := zw.Close(); err != nil {
+			return err
+		}
+		if compressed.Len() < len(sector) {
+			sectors.Write(compressed.Bytes())
+		} else {
+			sectors.Write(sector)
+		}
+	}
+	offsets[count] = uint32(4*len(offsets) + sectors.Len())
+
+	binary.Write(w, binary.LittleEndian, offsets)
+	w.Write(sectors.Bytes())
+	return nil
+}
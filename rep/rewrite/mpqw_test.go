@@ -0,0 +1,78 @@
+package rewrite
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+
+	"github.com/icza/mpq"
+)
+
+func TestArchiveRoundTrip(t *testing.T) {
+	big := make([]byte, 3*sectorSize+123)
+	rand.New(rand.NewSource(1)).Read(big[:sectorSize]) // Incompressible first sector
+
+	a := &archive{userData: []byte("\x3c\x00\x00\x00user data"), files: map[string][]byte{}}
+	a.add("replay.details", []byte("details"))
+	a.add("replay.sync.history", []byte{})
+	a.add("replay.game.events", big)
+	a.add("replay.removed", []byte("removed"))
+	a.add("replay.details", []byte("new details"))
+	a.remove("replay.removed")
+
+	buf := &bytes.Buffer{}
+	if err := a.writeTo(buf); err != nil {
+		t.Fatalf("Failed to write archive: %v", err)
+	}
+
+	m, err := mpq.New(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatalf("Failed to open written archive: %v", err)
+	}
+	defer m.Close()
+
+	if got := m.UserData(); !bytes.Equal(got, a.userData) {
+		t.Errorf("Expected user data: %q, got: %q", a.userData, got)
+	}
+	if got := m.FilesCount(); got != 4 { // Including the list file
+		t.Errorf("Expected files count: %d, got: %d", 4, got)
+	}
+
+	a2, err := readArchive(m)
+	if err != nil {
+		t.Fatalf("Failed to read archive: %v", err)
+	}
+	if exp := []string{"replay.details", "replay.sync.history", "replay.game.events"}; len(a2.names) != len(exp) {
+		t.Errorf("Expected names: %v, got: %v", exp, a2.names)
+	}
+	for name, exp := range a.files {
+		if got := a2.files[name]; !bytes.Equal(got, exp) {
+			t.Errorf("[%s] Content mismatch, expected length: %d, got length: %d", name, len(exp), len(got))
+		}
+	}
+	if data, _ := m.FileByName("replay.removed"); data != nil {
+		t.Error("Expected removed file to be missing!")
+	}
+}
+
+func TestArchiveWithoutUserData(t *testing.T) {
+	a := &archive{files: map[string][]byte{}}
+	a.add("a", []byte("content"))
+
+	buf := &bytes.Buffer{}
+	if err := a.writeTo(buf); err != nil {
+		t.Fatalf("Failed to write archive: %v", err)
+	}
+	m, err := mpq.New(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatalf("Failed to open written archive: %v", err)
+	}
+	defer m.Close()
+
+	if got := m.UserData(); got != nil {
+		t.Errorf("Expected no user data, got: %q", got)
+	}
+	if got, _ := m.FileByName("a"); string(got) != "content" {
+		t.Errorf("Expected: %q, got: %q", "content", got)
+	}
+}
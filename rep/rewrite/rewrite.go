@@ -0,0 +1,133 @@
+/*
+Package rewrite implements producing modified .SC2Replay files,
+e.g. anonymized replays for tournament organizers and dataset publishers,
+or slimmed replays with only selected sections.
+
+Affected sections of the replay are decoded, modified and re-encoded using the protocol
+of the replay, all other sections are copied as-is. The output is a new MPQ archive.
+
+Example:
+
+	in, err := os.Open("game.SC2Replay")
+	if err != nil {
+		// Handle error
+	}
+	defer in.Close()
+	out, err := os.Create("anon.SC2Replay")
+	if err != nil {
+		// Handle error
+	}
+	defer out.Close()
+	if err := rewrite.Anonymize(out, in, nil); err != nil {
+		// Handle error
+	}
+*/
+package rewrite
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/icza/mpq"
+	"github.com/icza/s2prot"
+	"github.com/icza/s2prot/rep"
+)
+
+// Names of replay sections (files in the MPQ archive).
+const (
+	SectionDetails        = "replay.details"
+	SectionDetailsBackup  = "replay.details.backup"
+	SectionInitData       = "replay.initData"
+	SectionInitDataBackup = "replay.initData.backup"
+	SectionAttributesEvts = "replay.attributes.events"
+	SectionGameEvts       = "replay.game.events"
+	SectionMessageEvts    = "replay.message.events"
+	SectionTrackerEvts    = "replay.tracker.events"
+	SectionGameMetadata   = "replay.gamemetadata.json"
+	SectionBattleLobby    = "replay.server.battlelobby"
+	SectionResumableEvts  = "replay.resumable.events"
+)
+
+// rewrite reads the replay from src, calls modify with its content and protocol,
+// and writes the (modified) content to dst.
+//
+// Errors of the rep package (e.g. rep.ErrInvalidRepFile) are returned if src is not a valid, supported replay.
+func rewrite(dst io.Writer, src io.ReadSeeker, modify func(a *archive, p *s2prot.Protocol) error) (err error) {
+	m, err := mpq.New(src)
+	if err != nil {
+		return rep.ErrInvalidRepFile
+	}
+	defer m.Close()
+
+	// Decoding panics on invalid input:
+	defer func() {
+		if r := recover(); r != nil {
+			err = rep.ErrDecoding
+		}
+	}()
+
+	a, err := readArchive(m)
+	if err != nil {
+		return rep.ErrInvalidRepFile
+	}
+
+	h := rep.Header{Struct: s2prot.DecodeHeader(a.userData)}
+	if h.Struct == nil {
+		return rep.ErrInvalidRepFile
+	}
+	p := h.Protocol()
+	if p == nil {
+		return rep.ErrUnsupportedRepVersion
+	}
+
+	if err := modify(a, p); err != nil {
+		return err
+	}
+
+	return a.writeTo(dst)
+}
+
+// rewriteFile rewrites the replay file src to the file dst using the rewriter function f.
+// dst is only created if rewriting succeeds.
+func rewriteFile(dst, src string, f func(w io.Writer, r io.ReadSeeker) error) error {
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return err
+	}
+
+	buf := &bytes.Buffer{}
+	if err := f(buf, bytes.NewReader(data)); err != nil {
+		return err
+	}
+
+	return os.WriteFile(dst, buf.Bytes(), 0644)
+}
+
+// filterEvts decodes the events of a section, keeps those for which keep returns true,
+// and re-encodes the kept events. It's a no-op if the section is not present.
+func (a *archive) filterEvts(name string, decode func([]byte) ([]s2prot.Event, error),
+	encode func([]s2prot.Event) ([]byte, error), keep func(e *s2prot.Event) bool) error {
+
+	data, ok := a.files[name]
+	if !ok {
+		return nil
+	}
+	evts, err := decode(data)
+	if err != nil {
+		return rep.ErrDecoding
+	}
+
+	kept := evts[:0]
+	for i := range evts {
+		if keep(&evts[i]) {
+			kept = append(kept, evts[i])
+		}
+	}
+
+	if a.files[name], err = encode(kept); err != nil {
+		return fmt.Errorf("Failed to encode %s: %v", name, err)
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+/*
+
+Trimming replays: dropping sections and truncating events.
+
+*/
+
+package rewrite
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/icza/s2prot"
+)
+
+// TrimOptions holds the options of trimming.
+type TrimOptions struct {
+	// Drop lists the names of sections to drop, e.g. SectionGameEvts.
+	// Dropped event sections are kept with empty content (no events),
+	// so parsers expecting them still accept the replay.
+	Drop []string
+
+	// MaxLoop, if positive, tells to drop game, message and tracker events after this game loop.
+	// The game length recorded in the replay header is also adjusted.
+	MaxLoop int64
+}
+
+// Sections that are kept with empty content when dropped.
+var emptiedSections = map[string]bool{
+	SectionGameEvts:    true,
+	SectionMessageEvts: true,
+	SectionTrackerEvts: true,
+}
+
+// Trim writes a slimmed version of the replay read from src to dst,
+// containing only the sections not dropped, and optionally only events up to a game loop.
+// All other content is copied as-is.
+//
+// Errors of the rep package (e.g. rep.ErrInvalidRepFile) are returned if src is not a valid, supported replay.
+func Trim(dst io.Writer, src io.ReadSeeker, opts *TrimOptions) error {
+	if opts == nil {
+		opts = &TrimOptions{}
+	}
+
+	return rewrite(dst, src, func(a *archive, p *s2prot.Protocol) error {
+		for _, name := range opts.Drop {
+			if emptiedSections[name] {
+				if _, ok := a.files[name]; ok {
+					a.files[name] = []byte{}
+				}
+				continue
+			}
+			a.remove(name)
+		}
+
+		if opts.MaxLoop <= 0 {
+			return nil
+		}
+
+		keep := func(e *s2prot.Event) bool { return e.Loop() <= opts.MaxLoop }
+		if err := a.filterEvts(SectionGameEvts, p.DecodeGameEvts, p.EncodeGameEvts, keep); err != nil {
+			return err
+		}
+		if err := a.filterEvts(SectionMessageEvts, p.DecodeMessageEvts, p.EncodeMessageEvts, keep); err != nil {
+			return err
+		}
+		if p.HasTrackerEvents() {
+			if err := a.filterEvts(SectionTrackerEvts, p.DecodeTrackerEvts, p.EncodeTrackerEvts, keep); err != nil {
+				return err
+			}
+		}
+
+		return a.truncateHeader(p, opts.MaxLoop)
+	})
+}
+
+// TrimFile trims the replay file src and writes the result to the file dst.
+// See Trim for details.
+func TrimFile(dst, src string, opts *TrimOptions) error {
+	return rewriteFile(dst, src, func(w io.Writer, r io.ReadSeeker) error {
+		return Trim(w, r, opts)
+	})
+}
+
+// truncateHeader limits the game length recorded in the replay header (the user data) to maxLoop.
+// The re-encoded header is padded to the original size of the user data.
+func (a *archive) truncateHeader(p *s2prot.Protocol, maxLoop int64) error {
+	header := s2prot.DecodeHeaderWith(p, a.userData)
+	if header.Int("elapsedGameLoops") <= maxLoop {
+		return nil
+	}
+	header["elapsedGameLoops"] = maxLoop
+
+	data, err := s2prot.EncodeHeaderWith(p, header)
+	if err != nil {
+		return fmt.Errorf("Failed to encode header: %v", err)
+	}
+	if len(data) < len(a.userData) {
+		data = append(data, make([]byte, len(a.userData)-len(data))...)
+	}
+	a.userData = data
+
+	return nil
+}
@@ -0,0 +1,101 @@
+package rewrite
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/icza/mpq"
+	"github.com/icza/s2prot"
+)
+
+// testReplay returns the content of a minimal replay having a header, details and events.
+func testReplay(t *testing.T, p *s2prot.Protocol) []byte {
+	header, err := s2prot.EncodeHeaderWith(p, s2prot.Struct{
+		"version":          s2prot.Struct{"baseBuild": int64(p.BaseBuild())},
+		"elapsedGameLoops": int64(1000),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	details, err := p.EncodeDetails(s2prot.Struct{"title": "Test Map"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	evts := func(name string, loops ...int64) (evts []s2prot.Event) {
+		for _, loop := range loops {
+			s := s2prot.Struct{"loop": loop, "userid": s2prot.Struct{"userId": int64(0)}, "recipient": int64(0), "string": "gl hf"}
+			evts = append(evts, s2prot.Event{Struct: s, EvtType: &s2prot.EvtType{Name: name}})
+		}
+		return
+	}
+	gameEvts, err := p.EncodeGameEvts(evts("UserFinishedLoadingSync", 0, 500, 1000))
+	if err != nil {
+		t.Fatal(err)
+	}
+	messageEvts, err := p.EncodeMessageEvts(evts("Chat", 10, 600))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	a := &archive{userData: header, files: map[string][]byte{}}
+	a.add(SectionDetails, details)
+	a.add(SectionGameEvts, gameEvts)
+	a.add(SectionMessageEvts, messageEvts)
+	a.add("replay.smartcam.events", []byte{1, 2, 3})
+
+	buf := &bytes.Buffer{}
+	if err := a.writeTo(buf); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestTrim(t *testing.T) {
+	p := s2prot.GetProtocol(80949)
+	src := testReplay(t, p)
+
+	buf := &bytes.Buffer{}
+	opts := &TrimOptions{Drop: []string{SectionGameEvts, "replay.smartcam.events"}, MaxLoop: 300}
+	if err := Trim(buf, bytes.NewReader(src), opts); err != nil {
+		t.Fatalf("Failed to trim: %v", err)
+	}
+
+	m, err := mpq.New(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatalf("Failed to open trimmed replay: %v", err)
+	}
+	defer m.Close()
+
+	header := s2prot.DecodeHeaderWith(p, m.UserData())
+	if got := header.Int("elapsedGameLoops"); got != 300 {
+		t.Errorf("Expected game loops: %d, got: %d", 300, got)
+	}
+	data, _ := m.FileByName(SectionDetails)
+	if details := p.DecodeDetails(data); details.Stringv("title") != "Test Map" {
+		t.Errorf("Expected unchanged details, got: %v", details)
+	}
+	if data, _ = m.FileByName(SectionGameEvts); data == nil || len(data) != 0 {
+		t.Errorf("Expected present but empty game events, got: %v", data)
+	}
+	if data, _ := m.FileByName("replay.smartcam.events"); data != nil {
+		t.Error("Expected dropped section!")
+	}
+	data, _ = m.FileByName(SectionMessageEvts)
+	if evts, err := p.DecodeMessageEvts(data); err != nil || len(evts) != 1 || evts[0].Loop() != 10 {
+		t.Errorf("Expected 1 message event at loop 10, got: %v (%v)", evts, err)
+	}
+}
+
+func TestTrimNoop(t *testing.T) {
+	p := s2prot.GetProtocol(80949)
+	src := testReplay(t, p)
+
+	buf := &bytes.Buffer{}
+	if err := Trim(buf, bytes.NewReader(src), nil); err != nil {
+		t.Fatalf("Failed to trim: %v", err)
+	}
+	if !bytes.Equal(buf.Bytes(), src) {
+		t.Error("Expected unchanged replay!")
+	}
+}
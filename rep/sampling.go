@@ -0,0 +1,253 @@
+/*
+
+Downsampling of high-frequency events (camera updates, unit positions).
+
+*/
+
+package rep
+
+import (
+	"math"
+
+	"github.com/icza/s2prot"
+)
+
+// Sample is a sample of a series (e.g. the camera updates of a user or the positions of a unit)
+// passed to SampleStrategies.
+type Sample struct {
+	Loop     int64 // Game loop of the sample
+	Point    Point // Point of the sample in map space, valid if HasPoint is true
+	HasPoint bool  // Tells if the sample has a point
+}
+
+// SampleStrategy decides if a sample is to be kept, given the last kept sample of the same series.
+type SampleStrategy func(s, last *Sample) (keep bool)
+
+// SampleFixedRate returns a strategy that keeps samples at most at the given rate:
+// a sample is kept if at least the given number of loops elapsed since the last kept sample.
+// Use DurationToLoops() to convert a game time interval to loops.
+func SampleFixedRate(loops int64) SampleStrategy {
+	return func(s, last *Sample) bool {
+		return s.Loop-last.Loop >= loops
+	}
+}
+
+// SampleSignificant returns a strategy that keeps significant changes only:
+// a sample is kept if its point is at least the given distance (in map cells) away from the point
+// of the last kept sample. Samples without points (e.g. camera updates that only change the zoom)
+// are kept if the last kept sample had a point, and dropped otherwise.
+func SampleSignificant(minDistance float64) SampleStrategy {
+	return func(s, last *Sample) bool {
+		if !s.HasPoint || !last.HasPoint {
+			return s.HasPoint != last.HasPoint
+		}
+		return math.Hypot(s.Point.X-last.Point.X, s.Point.Y-last.Point.Y) >= minDistance
+	}
+}
+
+// SampleAny returns a strategy that keeps a sample if any of the given strategies keeps it.
+// E.g. SampleAny(SampleSignificant(5), SampleFixedRate(160)) keeps significant changes,
+// and at least one sample in every 10 seconds (game time) of the series.
+func SampleAny(strategies ...SampleStrategy) SampleStrategy {
+	return func(s, last *Sample) bool {
+		for _, strategy := range strategies {
+			if strategy(s, last) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// Downsampler downsamples events.
+//
+// Events are sampled in series: a series is made up of the events of the same name and the same user
+// (the "userid" of game events, or the "playerId" or "controlPlayerId" of tracker events).
+// The first event of each series is always kept.
+type Downsampler struct {
+	// Names of the events to downsample, e.g. "CameraUpdate"; events of other names are always kept.
+	// All events are downsampled if empty.
+	Names []string
+
+	// Strategy deciding which events to keep. Points of the samples are provided by EvtPoint().
+	// All events are kept if nil.
+	Strategy SampleStrategy
+
+	// KeepLast tells to keep the last event of each series even if the strategy drops it,
+	// so the final state of the series is preserved.
+	KeepLast bool
+}
+
+// sampleSeries is the key of a series of events.
+type sampleSeries struct {
+	name string
+	id   int64
+}
+
+// Downsample returns the events kept by the downsampler, in their original order.
+// The passed events are not modified.
+func (d *Downsampler) Downsample(evts []s2prot.Event) []s2prot.Event {
+	if evts == nil {
+		return nil
+	}
+
+	type seriesState struct {
+		last    Sample // Last kept sample
+		lastIdx int    // Index of the last event in evts
+	}
+	states := map[sampleSeries]*seriesState{}
+	keep := make([]bool, len(evts))
+
+	for i := range evts {
+		e := &evts[i]
+		if !d.samples(e.Name) {
+			keep[i] = true
+			continue
+		}
+		key := sampleSeries{name: e.Name, id: evtSeriesID(*e)}
+		s := Sample{Loop: e.Loop()}
+		s.Point, s.HasPoint = EvtPoint(*e)
+
+		st := states[key]
+		if st == nil {
+			states[key] = &seriesState{last: s, lastIdx: i}
+			keep[i] = true
+			continue
+		}
+		st.lastIdx = i
+		if d.Strategy == nil || d.Strategy(&s, &st.last) {
+			st.last = s
+			keep[i] = true
+		}
+	}
+
+	if d.KeepLast {
+		for _, st := range states {
+			keep[st.lastIdx] = true
+		}
+	}
+
+	kept := make([]s2prot.Event, 0, len(evts))
+	for i, e := range evts {
+		if keep[i] {
+			kept = append(kept, e)
+		}
+	}
+	return kept
+}
+
+// samples tells if events of the given name are downsampled.
+func (d *Downsampler) samples(name string) bool {
+	if len(d.Names) == 0 {
+		return true
+	}
+	for _, n := range d.Names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// evtSeriesID returns the ID of the user or player the event belongs to, -1 if none.
+func evtSeriesID(e s2prot.Event) int64 {
+	if userID, ok := evtUserID(e); ok {
+		return userID
+	}
+	if pid, ok := e.LookupInt("playerId"); ok {
+		return pid
+	}
+	if pid, ok := e.LookupInt("controlPlayerId"); ok {
+		return pid
+	}
+	return -1
+}
+
+// UnitPosition is the position of a unit from a UnitPositions tracker event.
+type UnitPosition struct {
+	Loop      int64 // Game loop of the event
+	UnitIndex int64 // Unit tag index of the unit ("unitTagIndex" of unit events)
+	Point     Point // Position of the unit in map space
+}
+
+// UnitPositions returns the unit positions of a UnitPositions tracker event.
+// nil is returned for other events.
+func UnitPositions(e s2prot.Event) []UnitPosition {
+	if e.Name != TrEvtUnitPositions {
+		return nil
+	}
+	items := e.Array("items")
+	poss := make([]UnitPosition, 0, len(items)/3)
+	unitIndex := e.Int("firstUnitIndex")
+	for i := 0; i+2 < len(items); i += 3 {
+		delta, _ := items[i].(int64)
+		x, _ := items[i+1].(int64)
+		y, _ := items[i+2].(int64)
+		unitIndex += delta
+		poss = append(poss, UnitPosition{Loop: e.Loop(), UnitIndex: unitIndex, Point: UnitPositionsPoint(x, y)})
+	}
+	return poss
+}
+
+// DownsampleUnitPositions returns the unit positions of the UnitPositions tracker events
+// downsampled per unit with the given strategy (nil keeps all positions), in their original order.
+// The first (and if keepLast is true, the last) position of each unit is always kept.
+//
+// Unit tag indices are recycled when units die, and unit positions only identify units by their index.
+// So evts should contain the unit events too (e.g. pass all tracker events): the series of a unit index
+// ends when a unit of the index dies or is created (UnitDied, UnitBorn and UnitInit events).
+func DownsampleUnitPositions(evts []s2prot.Event, strategy SampleStrategy, keepLast bool) []UnitPosition {
+	type unitState struct {
+		last    Sample // Last kept sample
+		lastIdx int    // Index of the last position of the unit
+	}
+	states := map[int64]*unitState{}
+	var poss []UnitPosition
+	var keep []bool
+
+	// endSeries ends the series of the unit index.
+	endSeries := func(unitIndex int64) {
+		if st := states[unitIndex]; st != nil {
+			if keepLast {
+				keep[st.lastIdx] = true
+			}
+			delete(states, unitIndex)
+		}
+	}
+
+	for _, e := range evts {
+		switch e.Name {
+		case TrEvtUnitDied, TrEvtUnitBorn, TrEvtUnitInit:
+			endSeries(e.Int("unitTagIndex"))
+			continue
+		}
+		for _, up := range UnitPositions(e) {
+			s := Sample{Loop: up.Loop, Point: up.Point, HasPoint: true}
+			st := states[up.UnitIndex]
+			switch {
+			case st == nil:
+				states[up.UnitIndex] = &unitState{last: s, lastIdx: len(poss)}
+				keep = append(keep, true)
+			case strategy == nil || strategy(&s, &st.last):
+				st.last, st.lastIdx = s, len(poss)
+				keep = append(keep, true)
+			default:
+				st.lastIdx = len(poss)
+				keep = append(keep, false)
+			}
+			poss = append(poss, up)
+		}
+	}
+
+	for unitIndex := range states {
+		endSeries(unitIndex)
+	}
+
+	kept := poss[:0]
+	for i, up := range poss {
+		if keep[i] {
+			kept = append(kept, up)
+		}
+	}
+	return kept
+}
@@ -0,0 +1,118 @@
+package rep
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/icza/s2prot"
+)
+
+func TestDownsampler(t *testing.T) {
+	// Camera update at loop of user to map point (x, 0)
+	camera := func(loop, userID, x int64) s2prot.Event {
+		return gameEvt(loop, userID, "CameraUpdate", s2prot.Struct{"target": s2prot.Struct{"x": x * 256, "y": int64(0)}})
+	}
+	evts := []s2prot.Event{
+		camera(0, 0, 10), camera(0, 1, 10), camera(4, 0, 11), gameEvt(5, 0, "SelectionDelta", nil), camera(8, 0, 20),
+		camera(16, 1, 12), camera(20, 0, 21), camera(40, 0, 22),
+	}
+
+	loops := func(evts []s2prot.Event) (ls []int64) {
+		for _, e := range evts {
+			ls = append(ls, e.Loop()*10+e.UserID())
+		}
+		return
+	}
+
+	cases := []struct {
+		name string
+		d    Downsampler
+		exp  []int64 // loop*10 + userID
+	}{
+		{"fixed rate", Downsampler{Strategy: SampleFixedRate(16)}, []int64{0, 1, 50, 161, 200, 400}},
+		{"fixed rate, camera only", Downsampler{Names: []string{"CameraUpdate"}, Strategy: SampleFixedRate(16)}, []int64{0, 1, 50, 161, 200, 400}},
+		{"significant", Downsampler{Names: []string{"CameraUpdate"}, Strategy: SampleSignificant(5)}, []int64{0, 1, 50, 80}},
+		{"significant, keep last", Downsampler{Names: []string{"CameraUpdate"}, Strategy: SampleSignificant(5), KeepLast: true},
+			[]int64{0, 1, 50, 80, 161, 400}},
+		{"any", Downsampler{Names: []string{"CameraUpdate"}, Strategy: SampleAny(SampleSignificant(5), SampleFixedRate(30))},
+			[]int64{0, 1, 50, 80, 400}},
+	}
+	for _, c := range cases {
+		if got := loops(c.d.Downsample(evts)); !reflect.DeepEqual(got, c.exp) {
+			t.Errorf("[%s] Expected: %v, got: %v", c.name, c.exp, got)
+		}
+	}
+
+	if (&Downsampler{}).Downsample(nil) != nil {
+		t.Error("Expected nil for nil events")
+	}
+	if got := (&Downsampler{}).Downsample(evts); len(got) != len(evts) {
+		t.Errorf("Expected all %d events without strategy, got: %d", len(evts), len(got))
+	}
+}
+
+func TestSampleSignificant(t *testing.T) {
+	strategy := SampleSignificant(1)
+	p := &Sample{HasPoint: true}
+	np := &Sample{}
+	if strategy(p, p) || strategy(np, np) || !strategy(np, p) || !strategy(p, np) {
+		t.Error("Unexpected decisions for samples without points")
+	}
+}
+
+func TestDownsampleUnitPositions(t *testing.T) {
+	evt := func(loop int64, items ...interface{}) s2prot.Event {
+		return trackerEvt(loop, TrEvtUnitPositions, s2prot.Struct{"firstUnitIndex": int64(10), "items": items})
+	}
+	i := func(v int64) interface{} { return v }
+	evts := []s2prot.Event{
+		evt(240, i(0), i(1), i(1), i(2), i(5), i(5)), // units 10 and 12
+		evt(480, i(0), i(1), i(1), i(2), i(6), i(5)), // unit 12 moved 4 cells
+		evt(720, i(2), i(8), i(8)),                   // unit 12 moved 12 cells
+		evt(960, i(0), i(1), i(2)),                   // unit 10 moved 4 cells
+		trackerEvt(960, TrEvtUnitBorn, nil),
+	}
+
+	poss := UnitPositions(evts[0])
+	exp := []UnitPosition{{240, 10, Point{X: 4, Y: 4}}, {240, 12, Point{X: 20, Y: 20}}}
+	if !reflect.DeepEqual(poss, exp) {
+		t.Errorf("Expected: %v, got: %v", exp, poss)
+	}
+	if UnitPositions(evts[4]) != nil {
+		t.Error("Expected nil for non-UnitPositions event")
+	}
+
+	key := func(poss []UnitPosition) (ks []int64) {
+		for _, up := range poss {
+			ks = append(ks, up.Loop*100+up.UnitIndex)
+		}
+		return
+	}
+	cases := []struct {
+		name     string
+		strategy SampleStrategy
+		keepLast bool
+		exp      []int64 // loop*100 + unit index
+	}{
+		{"significant", SampleSignificant(10), false, []int64{24010, 24012, 72012}},
+		{"significant, keep last", SampleSignificant(10), true, []int64{24010, 24012, 72012, 96010}},
+		{"fixed rate", SampleFixedRate(480), false, []int64{24010, 24012, 72012, 96010}},
+		{"all", SampleFixedRate(0), false, []int64{24010, 24012, 48010, 48012, 72012, 96010}},
+	}
+	for _, c := range cases {
+		if got := key(DownsampleUnitPositions(evts, c.strategy, c.keepLast)); !reflect.DeepEqual(got, c.exp) {
+			t.Errorf("[%s] Expected: %v, got: %v", c.name, c.exp, got)
+		}
+	}
+
+	// Unit 10 dies, and its index is recycled by a new unit at about the same position:
+	died := trackerEvt(1000, TrEvtUnitDied, s2prot.Struct{"unitTagIndex": int64(10)})
+	evts = append(evts, died, evt(1200, i(0), i(2), i(2)))
+	exp2 := []int64{24010, 24012, 72012, 96010, 120010}
+	if got := key(DownsampleUnitPositions(evts, SampleSignificant(10), true)); !reflect.DeepEqual(got, exp2) {
+		t.Errorf("Expected: %v, got: %v", exp2, got)
+	}
+	if got := DownsampleUnitPositions(evts, nil, false); len(got) != 7 {
+		t.Errorf("Expected all %d positions without strategy, got: %d", 7, len(got))
+	}
+}
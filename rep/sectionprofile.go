@@ -0,0 +1,111 @@
+/*
+
+Profiling the decoding of replay sections.
+
+*/
+
+package rep
+
+import (
+	"encoding/json"
+	"io"
+	"runtime"
+	"time"
+
+	"github.com/icza/mpq"
+	"github.com/icza/s2prot"
+)
+
+// SectionHeader is the section name of the replay header (the MPQ user data) in SectionProfiles.
+const SectionHeader = "header"
+
+// SectionProfile is the cost of decoding a section of a replay, see ProfileSections.
+type SectionProfile struct {
+	Section    string        // Name of the section, e.g. "replay.details", or SectionHeader
+	Size       int           // Size of the (decompressed) section data in bytes
+	Read       time.Duration // Time of reading (decompressing) the section
+	Decode     time.Duration // Time of decoding the section
+	Allocs     uint64        // Number of heap allocations of decoding
+	AllocBytes uint64        // Bytes allocated by decoding
+}
+
+// ProfileSections reads and decodes the sections of a replay one by one (just like New does),
+// measuring the time and the heap allocations of decoding each section.
+// Missing sections are omitted (except the header, details and init data which are mandatory).
+//
+// Allocations are measured with runtime.ReadMemStats, so allocations of concurrently running goroutines
+// are also included; profile on an otherwise idle process for reliable results.
+//
+// Errors are reported like by New.
+func ProfileSections(input io.ReadSeeker) (profs []*SectionProfile, err error) {
+	m, err := mpq.New(input)
+	if err != nil {
+		return nil, ErrInvalidRepFile
+	}
+	defer m.Close()
+
+	defer func() {
+		// Protect replay decoding, see newRep
+		if r := recover(); r != nil {
+			profs, err = nil, ErrDecoding
+		}
+	}()
+
+	var header s2prot.Struct
+	userData := m.UserData()
+	prof := &SectionProfile{Section: SectionHeader, Size: len(userData)}
+	prof.Decode, prof.Allocs, prof.AllocBytes = measure(func() { header = s2prot.DecodeHeader(userData) })
+	profs = append(profs, prof)
+	if header == nil {
+		return nil, ErrInvalidRepFile
+	}
+	p := (&Header{Struct: header}).Protocol()
+	if p == nil {
+		return nil, ErrUnsupportedRepVersion
+	}
+
+	decoders := []struct {
+		sec       section
+		decode    func(data []byte)
+		mandatory bool
+	}{
+		{secDetails, func(data []byte) { p.DecodeDetails(data) }, true},
+		{secInitData, func(data []byte) { p.DecodeInitData(data) }, true},
+		{secAttributesEvts, func(data []byte) { p.DecodeAttributesEvts(data) }, false},
+		{secGameMetadata, func(data []byte) { json.Unmarshal(data, &s2prot.Struct{}) }, false},
+		{secGameEvts, func(data []byte) { p.DecodeGameEvts(data) }, false},
+		{secMessageEvts, func(data []byte) { p.DecodeMessageEvts(data) }, false},
+		{secTrackerEvts, func(data []byte) { p.DecodeTrackerEvts(data) }, false},
+	}
+	sr := newSectionReader(m)
+	for _, d := range decoders {
+		prof := &SectionProfile{Section: d.sec.name}
+		var data []byte
+		start := time.Now()
+		data, err = sr.read(d.sec)
+		prof.Read = time.Since(start)
+		if err != nil || len(data) == 0 {
+			if d.mandatory {
+				return nil, d.sec.err()
+			}
+			err = nil
+			continue
+		}
+		prof.Size = len(data)
+		prof.Decode, prof.Allocs, prof.AllocBytes = measure(func() { d.decode(data) })
+		profs = append(profs, prof)
+	}
+
+	return profs, nil
+}
+
+// measure calls f, and returns the time it took and the number and bytes of heap allocations it made.
+func measure(f func()) (d time.Duration, allocs, allocBytes uint64) {
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	start := time.Now()
+	f()
+	d = time.Since(start)
+	runtime.ReadMemStats(&after)
+	return d, after.Mallocs - before.Mallocs, after.TotalAlloc - before.TotalAlloc
+}
@@ -0,0 +1,143 @@
+/*
+
+Reading sections (files of the MPQ archive) of replays.
+
+*/
+
+package rep
+
+import (
+	"strings"
+
+	"github.com/icza/mpq"
+	"github.com/icza/s2prot"
+)
+
+// SectionError is returned if a section (a file of the MPQ archive) of the replay is missing or invalid.
+// Err is ErrInvalidRepFile, so errors.Is(err, ErrInvalidRepFile) reports true for SectionErrors.
+type SectionError struct {
+	Section string // Name of the section, e.g. "replay.details"
+	Err     error  // Underlying error
+}
+
+// Error returns the error message, including the name of the section.
+func (e *SectionError) Error() string {
+	return e.Err.Error() + ": missing or invalid section " + e.Section
+}
+
+// Unwrap returns the underlying error.
+func (e *SectionError) Unwrap() error {
+	return e.Err
+}
+
+// HeaderError is returned if the replay header (the MPQ user data) is invalid, e.g. its signature is unknown.
+// Err is ErrInvalidRepFile, so errors.Is(err, ErrInvalidRepFile) reports true for HeaderErrors.
+type HeaderError struct {
+	Cause *s2prot.HeaderError // Details of what was found in the user data
+	Err   error               // Underlying error
+}
+
+// Error returns the error message, including the details of the header.
+func (e *HeaderError) Error() string {
+	return e.Err.Error() + ": " + e.Cause.Error()
+}
+
+// Unwrap returns the underlying error.
+func (e *HeaderError) Unwrap() error {
+	return e.Err
+}
+
+// ParseHeader parses and validates the replay header from the MPQ user data (see s2prot.ParseHeader).
+// A *HeaderError is returned if the header is invalid.
+func ParseHeader(userData []byte) (Header, error) {
+	header, err := s2prot.ParseHeader(userData)
+	if err != nil {
+		he, _ := err.(*s2prot.HeaderError)
+		return Header{}, &HeaderError{Cause: he, Err: ErrInvalidRepFile}
+	}
+	return Header{Struct: header}, nil
+}
+
+// section describes a section of the replay.
+type section struct {
+	name       string // Name of the file
+	h1, h2, h3 uint32 // Precomputed hashes of the name
+}
+
+// Sections read from replays.
+var (
+	secDetails        = section{"replay.details", 620083690, 3548627612, 4013960850}
+	secDetailsBackup  = section{"replay.details.backup", 1421087648, 3590964654, 3400061273}
+	secInitData       = section{"replay.initData", 3544165653, 1518242780, 4280631132}
+	secInitDataBackup = section{"replay.initData.backup", 868899905, 1282002788, 1614930827}
+	secAttributesEvts = section{"replay.attributes.events", 1306016990, 497594575, 2731474728}
+	secGameMetadata   = section{"replay.gamemetadata.json", 3675439372, 3912155403, 1108615308}
+	secGameEvts       = section{"replay.game.events", 496563520, 2864883019, 4101385109}
+	secMessageEvts    = section{"replay.message.events", 1089231967, 831857289, 1784674979}
+	secTrackerEvts    = section{"replay.tracker.events", 1501940595, 4263103390, 1648390237}
+)
+
+// err returns a SectionError for the section.
+func (s section) err() error {
+	return &SectionError{Section: s.name, Err: ErrInvalidRepFile}
+}
+
+// sectionReader reads the sections of a replay archive.
+type sectionReader struct {
+	m *mpq.MPQ
+
+	listRead bool     // Tells if the list file has been read
+	listed   []string // Names listed in the list file of the archive
+}
+
+// newSectionReader returns a new sectionReader reading the sections of the archive.
+func newSectionReader(m *mpq.MPQ) *sectionReader {
+	return &sectionReader{m: m}
+}
+
+// read reads a section of the replay.
+//
+// Lookup by the precomputed hashes is attempted first. Some repacked / repaired replays have rebuilt
+// hash tables where the sections are stored under different paths (e.g. "Replay\replay.details"),
+// so if the section is not found, a name listed in the list file of the archive matching the
+// section name (ignoring case and path) is looked up.
+// The list file is only read (once) if a section is not found by its hashes.
+//
+// nil slice and nil error is returned if the section cannot be found.
+func (sr *sectionReader) read(s section) ([]byte, error) {
+	data, err := sr.m.FileByHash(s.h1, s.h2, s.h3)
+	if err == nil && data != nil {
+		return data, nil
+	}
+
+	if name := sr.listedName(s.name); name != "" {
+		if data2, err2 := sr.m.FileByName(name); err2 == nil && data2 != nil {
+			return data2, nil
+		}
+	}
+
+	return data, err
+}
+
+// listedName returns the name listed in the list file of the archive which refers to the specified section,
+// ignoring case and path. Empty string is returned if there is no such name.
+func (sr *sectionReader) listedName(secName string) string {
+	if !sr.listRead {
+		sr.listRead = true
+		if list, err := sr.m.FileByName("(listfile)"); err == nil && list != nil {
+			sr.listed = strings.FieldsFunc(string(list), func(r rune) bool { return r == '\r' || r == '\n' || r == ';' })
+		}
+	}
+
+	for _, name := range sr.listed {
+		base := name
+		if i := strings.LastIndexAny(base, `\/`); i >= 0 {
+			base = base[i+1:]
+		}
+		if strings.EqualFold(base, secName) && name != secName {
+			return name
+		}
+	}
+
+	return ""
+}
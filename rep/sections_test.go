@@ -0,0 +1,103 @@
+package rep_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/icza/mpq"
+	"github.com/icza/s2prot/rep"
+	"github.com/icza/s2prot/rep/reptest"
+	"github.com/icza/s2prot/rep/rewrite"
+)
+
+// repack returns the synthetic test replay repacked with the renamed files, dropping files renamed to "".
+func repack(t *testing.T, rename map[string]string) []byte {
+	return repackUserData(t, rename, nil)
+}
+
+// repackUserData is like repack, but the user data (replay header) is replaced with the result of alter if not nil.
+func repackUserData(t *testing.T, rename map[string]string, alter func(userData []byte) []byte) []byte {
+	data, err := reptest.New(80949).Bytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := mpq.New(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+
+	files := map[string][]byte{}
+	for _, name := range []string{rewrite.SectionDetails, rewrite.SectionInitData, rewrite.SectionAttributesEvts,
+		rewrite.SectionGameEvts, rewrite.SectionMessageEvts, rewrite.SectionTrackerEvts} {
+		content, err := m.FileByName(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if newName, ok := rename[name]; ok {
+			name = newName
+		}
+		if name != "" {
+			files[name] = content
+		}
+	}
+
+	userData := m.UserData()
+	if alter != nil {
+		userData = alter(append([]byte(nil), userData...))
+	}
+	buf := &bytes.Buffer{}
+	if err := rewrite.WriteArchive(buf, userData, files); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestListedSectionFallback(t *testing.T) {
+	data := repack(t, map[string]string{
+		rewrite.SectionDetails:  `Replay\replay.details`,
+		rewrite.SectionGameEvts: "REPLAY.GAME.EVENTS",
+	})
+	r, err := rep.New(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Failed to parse repacked replay: %v", err)
+	}
+	defer r.Close()
+
+	if got := len(r.Details.Players()); got != 2 {
+		t.Errorf("Expected %d players, got: %d", 2, got)
+	}
+}
+
+func TestSectionError(t *testing.T) {
+	_, err := rep.New(bytes.NewReader(repack(t, map[string]string{rewrite.SectionInitData: ""})))
+
+	var secErr *rep.SectionError
+	if !errors.As(err, &secErr) || secErr.Section != rewrite.SectionInitData {
+		t.Errorf("Expected section error of %s, got: %v", rewrite.SectionInitData, err)
+	}
+	if !errors.Is(err, rep.ErrInvalidRepFile) {
+		t.Errorf("Expected error wrapping %v, got: %v", rep.ErrInvalidRepFile, err)
+	}
+}
+
+func TestHeaderError(t *testing.T) {
+	// User data without the size prefix is accepted:
+	r, err := rep.New(bytes.NewReader(repackUserData(t, nil, func(userData []byte) []byte { return userData[4:] })))
+	if err != nil {
+		t.Fatalf("Failed to parse replay without user data prefix: %v", err)
+	}
+	r.Close()
+
+	_, err = rep.New(bytes.NewReader(repackUserData(t, nil, func(userData []byte) []byte {
+		return bytes.Replace(userData, []byte("StarCraft II"), []byte("StarCraft IX"), 1)
+	})))
+	var he *rep.HeaderError
+	if !errors.As(err, &he) || he.Cause.Signature != "StarCraft IX replay\x1b11" {
+		t.Errorf("Expected header error with altered signature, got: %v", err)
+	}
+	if !errors.Is(err, rep.ErrInvalidRepFile) {
+		t.Errorf("Expected error wrapping %v, got: %v", rep.ErrInvalidRepFile, err)
+	}
+}
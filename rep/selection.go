@@ -0,0 +1,256 @@
+/*
+
+Tracking the selections and control groups of users by replaying SelectionDelta and ControlGroupUpdate game events.
+
+*/
+
+package rep
+
+import (
+	"sort"
+
+	"github.com/icza/s2prot"
+)
+
+// ActiveSelectionID is the control group ID of SelectionDelta events denoting the active selection
+// (IDs 0..9 denote the control groups).
+const ActiveSelectionID = 10
+
+// Update types of ControlGroupUpdate events.
+const (
+	cgUpdateSet            = 0 // Set the control group to the active selection
+	cgUpdateAppend         = 1 // Append the active selection to the control group
+	cgUpdateRecall         = 2 // Select the control group
+	cgUpdateClear          = 3 // Clear the control group
+	cgUpdateSetAndSteal    = 4 // Like cgUpdateSet, removing the units from other control groups
+	cgUpdateAppendAndSteal = 5 // Like cgUpdateAppend, removing the units from other control groups
+)
+
+// SelectedUnit is a unit in a selection or control group.
+type SelectedUnit struct {
+	Tag                   int64 // Unit tag, see Unit.Tag
+	UnitLink              int64 // Unit link (unit type ID of the protocol)
+	SubgroupPriority      int64 // Priority of the unit's subgroup, units of higher priority come first
+	IntraSubgroupPriority int64 // Priority of the unit inside its subgroup
+}
+
+// selectionState is the selection state of a user.
+type selectionState struct {
+	groups     [ActiveSelectionID + 1][]SelectedUnit // Control groups, the last is the active selection
+	mismatches int                                   // Number of masks inconsistent with the tracked state
+}
+
+// SelectionTracker maintains the active selection and the control groups of users
+// by replaying SelectionDelta and ControlGroupUpdate game events (see Update).
+//
+// Selections are reconstructed from the deltas recorded in the replay; units removed by the game itself
+// (e.g. units dying or loaded into transports) are only removed when a later event's mask removes them,
+// so the tracked state is an approximation. Masks that are inconsistent with the tracked state
+// are counted, see Mismatches.
+//
+// The zero value is not ready to use, create one with NewSelectionTracker.
+type SelectionTracker struct {
+	users map[int64]*selectionState // Selection state by user ID
+}
+
+// NewSelectionTracker returns a new SelectionTracker.
+func NewSelectionTracker() *SelectionTracker {
+	return &SelectionTracker{users: map[int64]*selectionState{}}
+}
+
+// Selection returns the active selection of the user, ordered as in the game
+// (by subgroup priority, unit link, intra subgroup priority and unit tag).
+// The returned slice must not be modified.
+func (t *SelectionTracker) Selection(userID int64) []SelectedUnit {
+	if s := t.users[userID]; s != nil {
+		return s.groups[ActiveSelectionID]
+	}
+	return nil
+}
+
+// ControlGroup returns the units of a control group (0..9) of the user.
+// The returned slice must not be modified.
+func (t *SelectionTracker) ControlGroup(userID int64, idx int) []SelectedUnit {
+	if s := t.users[userID]; s != nil && idx >= 0 && idx < ActiveSelectionID {
+		return s.groups[idx]
+	}
+	return nil
+}
+
+// Mismatches returns the number of masks of the user's events which were inconsistent
+// with the tracked state (referring to more units than tracked).
+func (t *SelectionTracker) Mismatches(userID int64) int {
+	if s := t.users[userID]; s != nil {
+		return s.mismatches
+	}
+	return 0
+}
+
+// Update updates the state by a game event, and tells if it was a SelectionDelta or ControlGroupUpdate event.
+// Game events must be passed in their original order.
+func (t *SelectionTracker) Update(e s2prot.Event) bool {
+	if e.Name != GmEvtSelectionDelta && e.Name != GmEvtControlGroupUpdate {
+		return false
+	}
+	userID, ok := evtUserID(e)
+	if !ok {
+		return true
+	}
+	s := t.users[userID]
+	if s == nil {
+		s = &selectionState{}
+		t.users[userID] = s
+	}
+
+	if e.Name == GmEvtSelectionDelta {
+		id := e.Int("controlGroupId")
+		if id < 0 || id > ActiveSelectionID {
+			return true
+		}
+		delta := e.Structv("delta")
+		units := s.remove(s.groups[id], delta.Structv("removeMask"))
+		tags := delta.Array("addUnitTags")
+		for _, sg := range delta.Array("addSubgroups") {
+			sg, _ := sg.(s2prot.Struct)
+			for n := sg.Int("count"); n > 0 && len(tags) > 0; n-- {
+				tag, _ := tags[0].(int64)
+				tags = tags[1:]
+				units = append(units, SelectedUnit{
+					Tag:                   tag,
+					UnitLink:              sg.Int("unitLink"),
+					SubgroupPriority:      sg.Int("subgroupPriority"),
+					IntraSubgroupPriority: sg.Int("intraSubgroupPriority"),
+				})
+			}
+		}
+		s.groups[id] = sortSelection(units)
+		return true
+	}
+
+	idx := e.Int("controlGroupIndex")
+	if idx < 0 || idx >= ActiveSelectionID {
+		return true
+	}
+	active := s.groups[ActiveSelectionID]
+	switch e.Int("controlGroupUpdate") {
+	case cgUpdateSet, cgUpdateSetAndSteal:
+		s.groups[idx] = append([]SelectedUnit(nil), active...)
+	case cgUpdateAppend, cgUpdateAppendAndSteal:
+		s.groups[idx] = sortSelection(unionSelection(s.groups[idx], active))
+	case cgUpdateRecall:
+		s.groups[idx] = s.remove(s.groups[idx], e.Structv("mask"))
+		s.groups[ActiveSelectionID] = append([]SelectedUnit(nil), s.groups[idx]...)
+	case cgUpdateClear:
+		s.groups[idx] = nil
+	}
+	if u := e.Int("controlGroupUpdate"); u == cgUpdateSetAndSteal || u == cgUpdateAppendAndSteal {
+		for i := range s.groups[:ActiveSelectionID] {
+			if i != int(idx) {
+				s.groups[i] = subtractSelection(s.groups[i], active)
+			}
+		}
+	}
+	return true
+}
+
+// CommandSelections returns the effective commands of the game (see Commands), and the active selection
+// of the issuer at the time of each command (see SelectionTracker), the units commands without an explicit
+// target unit were given to. Elements of selections are in the order of cmds.
+// nil is returned if game events were not decoded.
+func (r *Rep) CommandSelections() (cmds []*Command, selections [][]SelectedUnit) {
+	if cmds = r.Commands(); cmds == nil {
+		return nil, nil
+	}
+
+	selections = make([][]SelectedUnit, 0, len(cmds))
+	t := NewSelectionTracker()
+	r.eachCommandEvt(cmds, func(e s2prot.Event, c *Command) {
+		if c != nil {
+			selections = append(selections, t.Selection(c.UserID))
+		} else {
+			t.Update(e)
+		}
+	})
+	return
+}
+
+// remove returns the units remaining after applying a remove mask (the value of the "removeMask" field of
+// SelectionDelta events or the "mask" field of ControlGroupUpdate events).
+// The input slice is not modified.
+func (s *selectionState) remove(units []SelectedUnit, mask s2prot.Struct) []SelectedUnit {
+	remaining := make([]SelectedUnit, 0, len(units))
+	switch {
+	case mask.Value("Mask") != nil:
+		// Bits of the units to remove, trailing units (not to be removed) may be omitted
+		bits := mask.BitArr("Mask")
+		if bits.Count > len(units) {
+			s.mismatches++
+		}
+		for i, u := range units {
+			if i >= bits.Count || !bits.Bit(i) {
+				remaining = append(remaining, u)
+			}
+		}
+	case mask.Value("OneIndices") != nil, mask.Value("ZeroIndices") != nil:
+		// Indices of the units to remove (OneIndices) or to keep (ZeroIndices)
+		indices, keep := mask.Array("OneIndices"), false
+		if indices == nil {
+			indices, keep = mask.Array("ZeroIndices"), true
+		}
+		listed := make(map[int64]bool, len(indices))
+		for _, v := range indices {
+			i, _ := v.(int64)
+			if i < 0 || i >= int64(len(units)) {
+				s.mismatches++
+			}
+			listed[i] = true
+		}
+		for i, u := range units {
+			if listed[int64(i)] == keep {
+				remaining = append(remaining, u)
+			}
+		}
+	default: // "None"
+		remaining = append(remaining, units...)
+	}
+	return remaining
+}
+
+// sortSelection sorts the units in the order of the game, and returns the slice.
+func sortSelection(units []SelectedUnit) []SelectedUnit {
+	sort.Slice(units, func(i, j int) bool {
+		a, b := &units[i], &units[j]
+		if a.SubgroupPriority != b.SubgroupPriority {
+			return a.SubgroupPriority > b.SubgroupPriority
+		}
+		if a.UnitLink != b.UnitLink {
+			return a.UnitLink < b.UnitLink
+		}
+		if a.IntraSubgroupPriority != b.IntraSubgroupPriority {
+			return a.IntraSubgroupPriority > b.IntraSubgroupPriority
+		}
+		return a.Tag < b.Tag
+	})
+	return units
+}
+
+// unionSelection returns a new slice containing the units of a and the units of b not in a.
+func unionSelection(a, b []SelectedUnit) []SelectedUnit {
+	union := append([]SelectedUnit(nil), a...)
+	return append(union, subtractSelection(b, a)...)
+}
+
+// subtractSelection returns a new slice containing the units of a not in b.
+func subtractSelection(a, b []SelectedUnit) []SelectedUnit {
+	tags := make(map[int64]bool, len(b))
+	for _, u := range b {
+		tags[u.Tag] = true
+	}
+	var diff []SelectedUnit
+	for _, u := range a {
+		if !tags[u.Tag] {
+			diff = append(diff, u)
+		}
+	}
+	return diff
+}
@@ -0,0 +1,114 @@
+package rep
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/icza/s2prot"
+)
+
+func TestSelectionTracker(t *testing.T) {
+	// sel creates a SelectionDelta event adding units of the given link and tags.
+	sel := func(loop, id int64, removeMask s2prot.Struct, link, prio int64, tags ...int64) s2prot.Event {
+		addTags := []interface{}{}
+		for _, tag := range tags {
+			addTags = append(addTags, tag)
+		}
+		subgroups := []interface{}{}
+		if len(tags) > 0 {
+			subgroups = append(subgroups, s2prot.Struct{"count": int64(len(tags)), "unitLink": link,
+				"subgroupPriority": prio, "intraSubgroupPriority": int64(1)})
+		}
+		return gameEvt(loop, 1, "SelectionDelta", s2prot.Struct{"controlGroupId": id, "delta": s2prot.Struct{
+			"removeMask": removeMask, "addSubgroups": subgroups, "addUnitTags": addTags, "subgroupIndex": int64(0),
+		}})
+	}
+	cgu := func(loop, idx, update int64, mask s2prot.Struct) s2prot.Event {
+		return gameEvt(loop, 1, "ControlGroupUpdate", s2prot.Struct{"controlGroupIndex": idx, "controlGroupUpdate": update, "mask": mask})
+	}
+	none := s2prot.Struct{"None": nil}
+	indices := func(name string, idxs ...int64) s2prot.Struct {
+		a := []interface{}{}
+		for _, i := range idxs {
+			a = append(a, i)
+		}
+		return s2prot.Struct{name: a}
+	}
+	tagsOf := func(units []SelectedUnit) (tags []int64) {
+		for _, u := range units {
+			tags = append(tags, u.Tag)
+		}
+		return
+	}
+
+	st := NewSelectionTracker()
+	steps := []struct {
+		e      s2prot.Event
+		active []int64
+		group  int // Control group to check
+		tags   []int64
+	}{
+		// Marines (lower priority) are ordered after the SCVs
+		{sel(10, 10, none, 48, 10, 5, 3), []int64{3, 5}, 1, nil},
+		{sel(11, 10, none, 45, 20, 9), []int64{9, 3, 5}, 1, nil},
+		{cgu(12, 1, cgUpdateSet, none), []int64{9, 3, 5}, 1, []int64{9, 3, 5}},
+		// Mask: remove the 1st and 3rd units, trailing bits omitted
+		{sel(13, 10, s2prot.Struct{"Mask": s2prot.BitArr{Count: 3, Data: []byte{0x05}}}, 0, 0), []int64{3}, 1, []int64{9, 3, 5}},
+		{sel(14, 10, indices("ZeroIndices"), 48, 10, 7), []int64{7}, 1, []int64{9, 3, 5}},
+		{cgu(15, 1, cgUpdateAppend, none), []int64{7}, 1, []int64{9, 3, 5, 7}},
+		{cgu(16, 2, cgUpdateSetAndSteal, none), []int64{7}, 1, []int64{9, 3, 5}},
+		// Recall with a mask removing the 2nd unit (e.g. a dead unit)
+		{cgu(17, 1, cgUpdateRecall, indices("OneIndices", 1)), []int64{9, 5}, 1, []int64{9, 5}},
+		{cgu(18, 2, cgUpdateClear, none), []int64{9, 5}, 2, nil},
+		{sel(19, 1, indices("ZeroIndices", 1), 0, 0), []int64{9, 5}, 1, []int64{5}},
+		{gameEvt(20, 1, "Cmd", nil), []int64{9, 5}, 1, []int64{5}},
+	}
+	for i, s := range steps {
+		if ok := st.Update(s.e); ok != (s.e.Name != "Cmd") {
+			t.Errorf("[%d] Unexpected Update() result: %v", i, ok)
+		}
+		if active := tagsOf(st.Selection(1)); !reflect.DeepEqual(active, s.active) {
+			t.Errorf("[%d] Expected active selection: %v, got: %v", i, s.active, active)
+		}
+		if tags := tagsOf(st.ControlGroup(1, s.group)); !reflect.DeepEqual(tags, s.tags) {
+			t.Errorf("[%d] Expected control group %d: %v, got: %v", i, s.group, s.tags, tags)
+		}
+	}
+	if m := st.Mismatches(1); m != 0 {
+		t.Errorf("Expected no mismatches, got: %d", m)
+	}
+
+	st.Update(sel(21, 10, indices("OneIndices", 5), 0, 0))
+	st.Update(sel(22, 10, s2prot.Struct{"Mask": s2prot.BitArr{Count: 9, Data: []byte{0x00, 0x01}}}, 0, 0))
+	if m := st.Mismatches(1); m != 2 {
+		t.Errorf("Expected mismatches: %d, got: %d", 2, m)
+	}
+	if st.Selection(2) != nil || st.ControlGroup(1, ActiveSelectionID) != nil || st.Mismatches(2) != 0 {
+		t.Error("Expected no state for unknown user or invalid control group!")
+	}
+
+	r := &Rep{}
+	if cmds, sels := r.CommandSelections(); cmds != nil || sels != nil {
+		t.Error("Expected nil commands and selections without game events!")
+	}
+	cmd := func(loop, seq int64) s2prot.Event {
+		return gameEvt(loop, 1, "Cmd", s2prot.Struct{"sequence": seq, "data": s2prot.Struct{"None": nil}})
+	}
+	r.GameEvts = []s2prot.Event{
+		cmd(5, 1),
+		sel(10, 10, none, 48, 10, 5, 3),
+		cmd(10, 2),
+		sel(20, 10, indices("ZeroIndices", 0), 0, 0),
+		gameEvt(20, 1, "CommandManagerState", s2prot.Struct{"state": int64(1), "sequence": int64(3)}),
+	}
+	cmds, sels := r.CommandSelections()
+	exp := [][]int64{nil, {3, 5}, {3}}
+	if len(cmds) != len(exp) || len(sels) != len(exp) {
+		t.Fatalf("Expected %d commands and selections, got: %d, %d", len(exp), len(cmds), len(sels))
+	}
+	for i, s := range sels {
+		if tags := tagsOf(s); !reflect.DeepEqual(tags, exp[i]) {
+			t.Errorf("[%d] Expected selection: %v, got: %v", i, exp[i], tags)
+		}
+	}
+}
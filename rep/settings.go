@@ -0,0 +1,132 @@
+/*
+
+Reconciled lobby settings (handicap, color and difficulty) of players.
+
+*/
+
+package rep
+
+// PlayerSettings holds the reconciled lobby settings of a player, along with the source of each value.
+type PlayerSettings struct {
+	Handicap       int64        // Handicap in percent (e.g. 100), 0 if unknown
+	HandicapSource *ValueSource // Source of Handicap
+
+	Color       *Color       // Color of the player, ColorUnknown if unknown
+	ColorSource *ValueSource // Source of Color
+
+	Difficulty       *Difficulty  // AI difficulty (meaningful for computer players), DifficultyUnknown if unknown
+	DifficultySource *ValueSource // Source of Difficulty
+
+	Conflict bool // Tells if the sources disagreed on any of the values
+}
+
+// PlayerSettings returns the reconciled lobby settings of the player specified by its index in Details.Players().
+// nil is returned if the player index is invalid.
+//
+// The lobby slot of the init data, the player of the game details and the attributes events may disagree
+// (mostly in old replays). The first valid value is used from these sources, in this order:
+//   - handicap: lobby slot, Details, attributes events (valid if in the range 1..100),
+//   - color: lobby slot, attributes events, Details (the color whose RGB matches the player's color),
+//   - difficulty: attributes events (difficulty IDs of lobby slots are not consistent across versions).
+//
+// Conflict is set if valid values of different sources disagree.
+func (r *Rep) PlayerSettings(playerIdx int) *PlayerSettings {
+	players := r.Details.Players()
+	if playerIdx < 0 || playerIdx >= len(players) {
+		return nil
+	}
+	p := &players[playerIdx]
+	slotIdx := r.PlayerSlots()[playerIdx]
+	var slot *Slot
+	if slotIdx >= 0 {
+		slot = &r.InitData.LobbyState.Slots[slotIdx]
+	}
+
+	ps := &PlayerSettings{HandicapSource: ValueSourceNone, Color: ColorUnknown, ColorSource: ValueSourceNone,
+		Difficulty: DifficultyUnknown, DifficultySource: ValueSourceNone}
+
+	// Handicap
+	handicaps := []struct {
+		value  int64
+		source *ValueSource
+	}{{-1, ValueSourceInitData}, {p.Handicap(), ValueSourceDetails}, {-1, ValueSourceAttrEvts}}
+	if slot != nil {
+		handicaps[0].value = slot.Handicap()
+	}
+	if slotIdx >= 0 {
+		handicaps[2].value = r.AttrEvts.PlayerHandicap(slotIdx)
+	}
+	for _, h := range handicaps {
+		if h.value < 1 || h.value > 100 {
+			continue
+		}
+		if ps.HandicapSource == ValueSourceNone {
+			ps.Handicap, ps.HandicapSource = h.value, h.source
+		} else if ps.Handicap != h.value {
+			ps.Conflict = true
+		}
+	}
+
+	// Color
+	colors := []struct {
+		value  *Color
+		source *ValueSource
+	}{{ColorUnknown, ValueSourceInitData}, {ColorUnknown, ValueSourceAttrEvts}, {colorByRGB(p.Color), ValueSourceDetails}}
+	if slot != nil {
+		colors[0].value = slot.ColorPrefColor()
+	}
+	if slotIdx >= 0 {
+		colors[1].value = r.AttrEvts.PlayerColor(slotIdx)
+	}
+	for _, c := range colors {
+		if c.value == ColorUnknown {
+			continue
+		}
+		if ps.ColorSource == ValueSourceNone {
+			ps.Color, ps.ColorSource = c.value, c.source
+		} else if ps.Color != c.value {
+			ps.Conflict = true
+		}
+	}
+
+	// Difficulty
+	if slotIdx >= 0 {
+		if d := difficultyByAttrValue(r.AttrEvts.PlayerDifficulty(slotIdx)); d != DifficultyUnknown {
+			ps.Difficulty, ps.DifficultySource = d, ValueSourceAttrEvts
+		}
+	}
+
+	return ps
+}
+
+// PlayerColor returns the color of the specified player (0-based), the one the player was rendered with.
+//
+// The color is taken from the Details players: the matching color of Colors if there is one,
+// else a custom color (see NewCustomColor). If Details has no color for the player,
+// the color of PlayerSettings() is returned.
+func (r *Rep) PlayerColor(playerIdx int) *Color {
+	players := r.Details.Players()
+	if playerIdx < 0 || playerIdx >= len(players) {
+		return ColorUnknown
+	}
+	argb := players[playerIdx].Color
+	if argb[0] == 0 {
+		// Transparent (or missing) color
+		return r.PlayerSettings(playerIdx).Color
+	}
+	if c := colorByRGB(argb); c != ColorUnknown {
+		return c
+	}
+	return NewCustomColor([3]byte{argb[1], argb[2], argb[3]})
+}
+
+// colorByRGB returns the Color whose RGB components match the given ARGB color (of Details players).
+// ColorUnknown is returned if no color matches.
+func colorByRGB(argb [4]byte) *Color {
+	for _, c := range Colors[1:] {
+		if c.RGB == [3]byte{argb[1], argb[2], argb[3]} {
+			return c
+		}
+	}
+	return ColorUnknown
+}
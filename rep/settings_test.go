@@ -0,0 +1,73 @@
+package rep
+
+import (
+	"testing"
+
+	"github.com/icza/s2prot"
+)
+
+func TestPlayerSettings(t *testing.T) {
+	r := newTestRep(
+		s2prot.Struct{"control": int64(2), "handicap": int64(80), "colorPref": s2prot.Struct{"color": int64(1)}},
+		computerSlot(s2prot.Struct{"handicap": int64(0), "colorPref": s2prot.Struct{"color": int64(0)}}),
+	)
+	setTestPlayers(r,
+		s2prot.Struct{"handicap": int64(100), "color": s2prot.Struct{"a": int64(255), "r": int64(180), "g": int64(20), "b": int64(30)}},
+		s2prot.Struct{"handicap": int64(0), "color": s2prot.Struct{"a": int64(255), "r": int64(0), "g": int64(66), "b": int64(255)}},
+	)
+	// A player without a lobby slot:
+	r.Details.Struct["playerList"] = append(r.Details.Array("playerList"), s2prot.Struct{"workingSetSlotId": int64(5), "handicap": int64(90),
+		"color": s2prot.Struct{"a": int64(255), "r": int64(1), "g": int64(2), "b": int64(3)}})
+	r.AttrEvts = newTestAttrEvts(map[string]map[AttrID]string{
+		"2": {AttrColor: "tc02", AttrHandicap: " 75", AttrDifficulty: "VyHd"},
+	})
+
+	cases := []struct {
+		handicap  int64
+		hSource   *ValueSource
+		color     *Color
+		cSource   *ValueSource
+		diff      *Difficulty
+		dSource   *ValueSource
+		conflicts bool
+	}{
+		{80, ValueSourceInitData, ColorRed, ValueSourceInitData, DifficultyUnknown, ValueSourceNone, true},
+		{75, ValueSourceAttrEvts, ColorBlue, ValueSourceAttrEvts, DifficultyVeryHard, ValueSourceAttrEvts, false},
+		{90, ValueSourceDetails, ColorUnknown, ValueSourceNone, DifficultyUnknown, ValueSourceNone, false},
+	}
+	for i, c := range cases {
+		ps := r.PlayerSettings(i)
+		if ps.Handicap != c.handicap || ps.HandicapSource != c.hSource || ps.Color != c.color || ps.ColorSource != c.cSource ||
+			ps.Difficulty != c.diff || ps.DifficultySource != c.dSource || ps.Conflict != c.conflicts {
+			t.Errorf("[%d] Expected: %+v, got: %+v", i, c, ps)
+		}
+	}
+	if r.PlayerSettings(3) != nil {
+		t.Error("Expected nil settings for invalid player index!")
+	}
+}
+
+func TestPlayerColor(t *testing.T) {
+	r := newTestRep(
+		s2prot.Struct{"colorPref": s2prot.Struct{"color": int64(1)}},
+		s2prot.Struct{"colorPref": s2prot.Struct{"color": int64(2)}},
+		s2prot.Struct{"colorPref": s2prot.Struct{"color": int64(3)}},
+	)
+	setTestPlayers(r,
+		s2prot.Struct{"color": s2prot.Struct{"a": int64(255), "r": int64(180), "g": int64(20), "b": int64(30)}},
+		s2prot.Struct{"color": s2prot.Struct{"a": int64(255), "r": int64(1), "g": int64(2), "b": int64(3)}},
+	)
+
+	if c := r.PlayerColor(0); c != ColorRed {
+		t.Errorf("Expected: %v, got: %v", ColorRed, c)
+	}
+	if c := r.PlayerColor(1); !c.Custom() || c.RGB != [3]byte{1, 2, 3} {
+		t.Errorf("Expected custom color, got: %v %v", c, c.RGB)
+	}
+	if c := r.PlayerColor(2); c != ColorTeal {
+		t.Errorf("Expected: %v, got: %v", ColorTeal, c)
+	}
+	if c := r.PlayerColor(3); c != ColorUnknown {
+		t.Errorf("Expected: %v, got: %v", ColorUnknown, c)
+	}
+}
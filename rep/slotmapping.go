@@ -0,0 +1,97 @@
+/*
+
+Explicit mapping between player indices, lobby slots and user IDs.
+
+*/
+
+package rep
+
+// SlotMapping is the mapping between the indices of players in Details.Players(), the lobby slots (0-based)
+// of the init data (see InitData.LobbyState.Slots) and user IDs (used in game and message events).
+//
+// Players are matched to slots by working set slot id, see Rep.PlayerSlots.
+// User IDs belong to human slots (including observers); computer players have no user ID.
+type SlotMapping struct {
+	// ByWorkingSetSlotID tells if players were matched to slots by working set slot id.
+	// Old replays have no working set slot ids, players are matched to the occupied participant slots in order.
+	ByWorkingSetSlotID bool
+
+	playerSlots []int         // Slots by player index, -1 if unknown
+	slotPlayers map[int]int   // Player indices by slot
+	slotUsers   map[int]int64 // User IDs by slot
+	userSlots   map[int64]int // Slots by user ID
+}
+
+// SlotMapping returns the mapping between player indices, lobby slots and user IDs.
+func (r *Rep) SlotMapping() *SlotMapping {
+	m := &SlotMapping{slotPlayers: map[int]int{}, slotUsers: map[int]int64{}, userSlots: map[int64]int{}}
+	m.playerSlots, m.ByWorkingSetSlotID = r.playerSlots()
+
+	for playerIdx, slot := range m.playerSlots {
+		if slot >= 0 {
+			m.slotPlayers[slot] = playerIdx
+		}
+	}
+	slots := r.InitData.LobbyState.Slots
+	for i := range slots {
+		if slots[i].Control() != ControlHuman {
+			continue
+		}
+		if userID, ok := slots[i].LookupInt("userId"); ok {
+			m.slotUsers[i] = userID
+			m.userSlots[userID] = i
+		}
+	}
+	return m
+}
+
+// PlayerSlot returns the lobby slot of the player specified by its index in Details.Players().
+func (m *SlotMapping) PlayerSlot(playerIdx int) (slot int, ok bool) {
+	if playerIdx >= 0 && playerIdx < len(m.playerSlots) && m.playerSlots[playerIdx] >= 0 {
+		return m.playerSlots[playerIdx], true
+	}
+	return -1, false
+}
+
+// SlotPlayer returns the index in Details.Players() of the player occupying the lobby slot.
+// ok is false for slots of observers and empty slots.
+func (m *SlotMapping) SlotPlayer(slot int) (playerIdx int, ok bool) {
+	if playerIdx, ok = m.slotPlayers[slot]; !ok {
+		playerIdx = -1
+	}
+	return
+}
+
+// SlotUserID returns the user ID of the human occupying the lobby slot.
+func (m *SlotMapping) SlotUserID(slot int) (userID int64, ok bool) {
+	if userID, ok = m.slotUsers[slot]; !ok {
+		userID = -1
+	}
+	return
+}
+
+// UserSlot returns the lobby slot of the user.
+func (m *SlotMapping) UserSlot(userID int64) (slot int, ok bool) {
+	if slot, ok = m.userSlots[userID]; !ok {
+		slot = -1
+	}
+	return
+}
+
+// PlayerUserID returns the user ID of the player specified by its index in Details.Players().
+// ok is false for computer players.
+func (m *SlotMapping) PlayerUserID(playerIdx int) (userID int64, ok bool) {
+	if slot, ok := m.PlayerSlot(playerIdx); ok {
+		return m.SlotUserID(slot)
+	}
+	return -1, false
+}
+
+// UserPlayer returns the index in Details.Players() of the player of the user.
+// ok is false for observers.
+func (m *SlotMapping) UserPlayer(userID int64) (playerIdx int, ok bool) {
+	if slot, ok := m.UserSlot(userID); ok {
+		return m.SlotPlayer(slot)
+	}
+	return -1, false
+}
@@ -0,0 +1,72 @@
+package rep
+
+import (
+	"testing"
+
+	"github.com/icza/s2prot"
+)
+
+func TestSlotMapping(t *testing.T) {
+	newRep := func(wss bool) *Rep {
+		r := &Rep{}
+		// Players in a different order than slots, a computer player and an observer:
+		r.Details = Details{Struct: s2prot.Struct{"playerList": []interface{}{
+			s2prot.Struct{"workingSetSlotId": int64(2)},
+			s2prot.Struct{"workingSetSlotId": int64(0)},
+		}}}
+		slots := []interface{}{
+			s2prot.Struct{"workingSetSlotId": int64(0), "control": int64(2), "observe": int64(0), "userId": int64(4)},
+			s2prot.Struct{"workingSetSlotId": int64(1), "control": int64(2), "observe": int64(1), "userId": int64(5)},
+			s2prot.Struct{"workingSetSlotId": int64(2), "control": int64(3), "observe": int64(0), "userId": nil},
+		}
+		if !wss {
+			for _, s := range slots {
+				delete(s.(s2prot.Struct), "workingSetSlotId")
+			}
+		}
+		r.InitData = NewInitData(s2prot.Struct{"syncLobbyState": s2prot.Struct{"lobbyState": s2prot.Struct{"slots": slots}}})
+		return r
+	}
+
+	m := newRep(true).SlotMapping()
+	if !m.ByWorkingSetSlotID {
+		t.Error("Expected mapping by working set slot id!")
+	}
+	if slot, ok := m.PlayerSlot(0); !ok || slot != 2 {
+		t.Errorf("Expected slot: %d, got: %d", 2, slot)
+	}
+	if playerIdx, ok := m.SlotPlayer(0); !ok || playerIdx != 1 {
+		t.Errorf("Expected player: %d, got: %d", 1, playerIdx)
+	}
+	if userID, ok := m.PlayerUserID(1); !ok || userID != 4 {
+		t.Errorf("Expected user ID: %d, got: %d", 4, userID)
+	}
+	if playerIdx, ok := m.UserPlayer(4); !ok || playerIdx != 1 {
+		t.Errorf("Expected player: %d, got: %d", 1, playerIdx)
+	}
+	if slot, ok := m.UserSlot(5); !ok || slot != 1 {
+		t.Errorf("Expected slot: %d, got: %d", 1, slot)
+	}
+	// Computer player has no user, observer is not a player:
+	if _, ok := m.PlayerUserID(0); ok {
+		t.Error("Expected no user ID for computer player!")
+	}
+	if _, ok := m.UserPlayer(5); ok {
+		t.Error("Expected no player for observer!")
+	}
+	if _, ok := m.PlayerSlot(2); ok {
+		t.Error("Expected no slot for invalid player index!")
+	}
+
+	// Old replay, participant slots are matched in order:
+	m = newRep(false).SlotMapping()
+	if m.ByWorkingSetSlotID {
+		t.Error("Expected mapping in order!")
+	}
+	if userID, ok := m.PlayerUserID(0); !ok || userID != 4 {
+		t.Errorf("Expected user ID: %d, got: %d", 4, userID)
+	}
+	if playerIdx, ok := m.SlotPlayer(2); !ok || playerIdx != 1 {
+		t.Errorf("Expected player: %d, got: %d", 1, playerIdx)
+	}
+}
@@ -0,0 +1,106 @@
+/*
+
+Cheap pre-parse validation of replay files.
+
+*/
+
+package rep
+
+import (
+	"encoding/binary"
+	"io"
+
+	"github.com/icza/s2prot"
+)
+
+// Magic bytes of the MPQ user data and the MPQ header.
+var (
+	mpqUserDataMagic = []byte{'M', 'P', 'Q', 0x1b}
+	mpqHeaderMagic   = []byte{'M', 'P', 'Q', 0x1a}
+)
+
+// maxUserDataSize is the max size of the MPQ user data Sniff accepts (replay headers are less than 1 KB,
+// the allocated user data is usually 512 bytes).
+const maxUserDataSize = 64 * 1024
+
+// SniffResult is the result of Sniff.
+type SniffResult struct {
+	Kind      *FileKind    // Kind of the file
+	Game      *s2prot.Game // Game of the replay, nil if not a replay
+	Signature string       // Signature of the replay header, e.g. "StarCraft II replay\x1b11"
+
+	Major, Minor, Revision, Build int64 // Parts of the game version
+	BaseBuild                     int64 // Base build, selects the protocol
+
+	Type int64 // Type of the replay (the "type" field of the header), 2 for standard replays
+
+	Supported bool // Tells if a protocol is available for the base build
+}
+
+// Sniff checks if the input is a replay file without fully decoding it:
+// it checks the MPQ magic bytes and reads only the signature, version and type from the replay header
+// (see s2prot.ProbeVersion, which does not depend on the availability of protocols),
+// returning the kind of the file and the version of the replay.
+// It is cheap compared to parsing the replay, so it is suitable as the first gate e.g. in upload endpoints.
+//
+// If the input is not a replay (Kind is not FileKindReplay or FileKindOtherReplay), ErrInvalidRepFile is returned
+// (along with the result). ErrUnsupportedRepVersion is returned if the replay's version is not supported.
+// Errors of reading the input are returned as-is.
+func Sniff(input io.ReaderAt) (res *SniffResult, err error) {
+	res = &SniffResult{Kind: FileKindUnknown}
+
+	buf := make([]byte, 12)
+	if _, err = input.ReadAt(buf, 0); err != nil {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			err = ErrInvalidRepFile
+		}
+		return
+	}
+
+	switch {
+	case string(buf[:4]) == string(mpqHeaderMagic):
+		res.Kind = FileKindMPQ
+		return res, ErrInvalidRepFile // No user data, no replay header
+	case string(buf[:4]) != string(mpqUserDataMagic):
+		return res, ErrInvalidRepFile
+	}
+
+	userDataSize := binary.LittleEndian.Uint32(buf[4:])
+	headerOffset := binary.LittleEndian.Uint32(buf[8:])
+	magic := make([]byte, 4)
+	if _, err = input.ReadAt(magic, int64(headerOffset)); err != nil || string(magic) != string(mpqHeaderMagic) {
+		return res, ErrInvalidRepFile
+	}
+	res.Kind = FileKindMPQ
+	if userDataSize <= 4 || userDataSize > maxUserDataSize {
+		return res, ErrInvalidRepFile
+	}
+
+	userData := make([]byte, userDataSize)
+	if _, err = input.ReadAt(userData, 12); err != nil {
+		return res, ErrInvalidRepFile
+	}
+	v, err := s2prot.ProbeVersion(userData)
+	if err != nil {
+		return res, ErrInvalidRepFile
+	}
+	res.Game = s2prot.GameOf(s2prot.Struct{"signature": v.Signature})
+	if res.Game == nil {
+		return res, ErrInvalidRepFile
+	}
+	if res.Game == s2prot.GameSC2 {
+		res.Kind = FileKindReplay
+	} else {
+		res.Kind = FileKindOtherReplay
+	}
+
+	res.Signature = v.Signature
+	res.Major, res.Minor, res.Revision, res.Build, res.BaseBuild = v.Major, v.Minor, v.Revision, v.Build, v.BaseBuild
+	res.Type = v.Type
+
+	res.Supported = res.Game.GetProtocol(int(res.BaseBuild)) != nil
+	if !res.Supported {
+		return res, ErrUnsupportedRepVersion
+	}
+	return res, nil
+}
@@ -0,0 +1,53 @@
+package rep_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/icza/s2prot"
+	"github.com/icza/s2prot/rep"
+	"github.com/icza/s2prot/rep/reptest"
+)
+
+func TestSniff(t *testing.T) {
+	replay := func(baseBuild int64) []byte {
+		r := reptest.New(80949)
+		r.Version = [3]int64{5, 0, 3}
+		r.Customize = func(kind string, s s2prot.Struct) {
+			if kind == s2prot.KindHeader {
+				s.Structv("version")["baseBuild"] = baseBuild
+			}
+		}
+		data, err := r.Bytes()
+		if err != nil {
+			t.Fatalf("Failed to create replay: %v", err)
+		}
+		return data
+	}
+
+	valid := replay(80949)
+	cases := []struct {
+		name      string
+		data      []byte
+		kind      *rep.FileKind
+		baseBuild int64
+		err       error
+	}{
+		{"valid", valid, rep.FileKindReplay, 80949, nil},
+		{"unsupported", replay(99999), rep.FileKindReplay, 99999, rep.ErrUnsupportedRepVersion},
+		{"truncated", valid[:10], rep.FileKindUnknown, 0, rep.ErrInvalidRepFile},
+		{"not mpq", []byte("not a replay file"), rep.FileKindUnknown, 0, rep.ErrInvalidRepFile},
+		{"mpq without user data", append([]byte("MPQ\x1a"), make([]byte, 28)...), rep.FileKindMPQ, 0, rep.ErrInvalidRepFile},
+	}
+	for _, c := range cases {
+		res, err := rep.Sniff(bytes.NewReader(c.data))
+		if err != c.err || res.Kind != c.kind || res.BaseBuild != c.baseBuild {
+			t.Errorf("[%s] Expected: %v %d %v, got: %v %d %v", c.name, c.kind, c.baseBuild, c.err, res.Kind, res.BaseBuild, err)
+		}
+	}
+
+	res, _ := rep.Sniff(bytes.NewReader(valid))
+	if res.Game != s2prot.GameSC2 || !res.Supported || res.Major != 5 || res.Minor != 0 || res.Revision != 3 || res.Type != 2 {
+		t.Errorf("Unexpected result: %+v", res)
+	}
+}
@@ -0,0 +1,237 @@
+/*
+
+Canonical stat fields of PlayerStats tracker events.
+
+*/
+
+package rep
+
+import (
+	"sync"
+
+	"github.com/icza/s2prot"
+)
+
+// StatField is a canonical field of the stats of PlayerStats tracker events.
+// Its name (e.g. "MineralsCurrent") is stable, while the names of the field in the protocols may vary by build.
+type StatField struct {
+	Enum
+	Names        []string // Names of the field in the protocols, e.g. "scoreValueMineralsCurrent"
+	MinBaseBuild int64    // First base build whose replays have the field
+}
+
+// Available tells if replays of the given base build have the field.
+func (f *StatField) Available(baseBuild int64) bool {
+	return baseBuild >= f.MinBaseBuild
+}
+
+// FoodScale is the scale of the food values of stats (FoodUsed and FoodMade), which are fixed-point numbers
+// with 12 fraction bits: food = value / 4096.
+const FoodScale = 4096
+
+// StatFields is the slice of all canonical stat fields.
+//
+// Tracker events (and so PlayerStats events) are present from base build 24944;
+// all fields are available from there, except the FriendlyFire fields which are available from base build 26490.
+//
+// Names of fields in custom protocols (or in future builds) may be registered with RegisterStatFieldName.
+var StatFields = []*StatField{
+	{Enum{"MineralsCurrent"}, []string{"scoreValueMineralsCurrent"}, 24944},
+	{Enum{"VespeneCurrent"}, []string{"scoreValueVespeneCurrent"}, 24944},
+	{Enum{"MineralsCollectionRate"}, []string{"scoreValueMineralsCollectionRate"}, 24944},
+	{Enum{"VespeneCollectionRate"}, []string{"scoreValueVespeneCollectionRate"}, 24944},
+	{Enum{"WorkersActiveCount"}, []string{"scoreValueWorkersActiveCount"}, 24944},
+	{Enum{"MineralsUsedInProgressArmy"}, []string{"scoreValueMineralsUsedInProgressArmy"}, 24944},
+	{Enum{"MineralsUsedInProgressEconomy"}, []string{"scoreValueMineralsUsedInProgressEconomy"}, 24944},
+	{Enum{"MineralsUsedInProgressTechnology"}, []string{"scoreValueMineralsUsedInProgressTechnology"}, 24944},
+	{Enum{"VespeneUsedInProgressArmy"}, []string{"scoreValueVespeneUsedInProgressArmy"}, 24944},
+	{Enum{"VespeneUsedInProgressEconomy"}, []string{"scoreValueVespeneUsedInProgressEconomy"}, 24944},
+	{Enum{"VespeneUsedInProgressTechnology"}, []string{"scoreValueVespeneUsedInProgressTechnology"}, 24944},
+	{Enum{"MineralsUsedCurrentArmy"}, []string{"scoreValueMineralsUsedCurrentArmy"}, 24944},
+	{Enum{"MineralsUsedCurrentEconomy"}, []string{"scoreValueMineralsUsedCurrentEconomy"}, 24944},
+	{Enum{"MineralsUsedCurrentTechnology"}, []string{"scoreValueMineralsUsedCurrentTechnology"}, 24944},
+	{Enum{"VespeneUsedCurrentArmy"}, []string{"scoreValueVespeneUsedCurrentArmy"}, 24944},
+	{Enum{"VespeneUsedCurrentEconomy"}, []string{"scoreValueVespeneUsedCurrentEconomy"}, 24944},
+	{Enum{"VespeneUsedCurrentTechnology"}, []string{"scoreValueVespeneUsedCurrentTechnology"}, 24944},
+	{Enum{"MineralsLostArmy"}, []string{"scoreValueMineralsLostArmy"}, 24944},
+	{Enum{"MineralsLostEconomy"}, []string{"scoreValueMineralsLostEconomy"}, 24944},
+	{Enum{"MineralsLostTechnology"}, []string{"scoreValueMineralsLostTechnology"}, 24944},
+	{Enum{"VespeneLostArmy"}, []string{"scoreValueVespeneLostArmy"}, 24944},
+	{Enum{"VespeneLostEconomy"}, []string{"scoreValueVespeneLostEconomy"}, 24944},
+	{Enum{"VespeneLostTechnology"}, []string{"scoreValueVespeneLostTechnology"}, 24944},
+	{Enum{"MineralsKilledArmy"}, []string{"scoreValueMineralsKilledArmy"}, 24944},
+	{Enum{"MineralsKilledEconomy"}, []string{"scoreValueMineralsKilledEconomy"}, 24944},
+	{Enum{"MineralsKilledTechnology"}, []string{"scoreValueMineralsKilledTechnology"}, 24944},
+	{Enum{"VespeneKilledArmy"}, []string{"scoreValueVespeneKilledArmy"}, 24944},
+	{Enum{"VespeneKilledEconomy"}, []string{"scoreValueVespeneKilledEconomy"}, 24944},
+	{Enum{"VespeneKilledTechnology"}, []string{"scoreValueVespeneKilledTechnology"}, 24944},
+	{Enum{"FoodUsed"}, []string{"scoreValueFoodUsed"}, 24944},
+	{Enum{"FoodMade"}, []string{"scoreValueFoodMade"}, 24944},
+	{Enum{"MineralsUsedActiveForces"}, []string{"scoreValueMineralsUsedActiveForces"}, 24944},
+	{Enum{"VespeneUsedActiveForces"}, []string{"scoreValueVespeneUsedActiveForces"}, 24944},
+	{Enum{"MineralsFriendlyFireArmy"}, []string{"scoreValueMineralsFriendlyFireArmy"}, 26490},
+	{Enum{"MineralsFriendlyFireEconomy"}, []string{"scoreValueMineralsFriendlyFireEconomy"}, 26490},
+	{Enum{"MineralsFriendlyFireTechnology"}, []string{"scoreValueMineralsFriendlyFireTechnology"}, 26490},
+	{Enum{"VespeneFriendlyFireArmy"}, []string{"scoreValueVespeneFriendlyFireArmy"}, 26490},
+	{Enum{"VespeneFriendlyFireEconomy"}, []string{"scoreValueVespeneFriendlyFireEconomy"}, 26490},
+	{Enum{"VespeneFriendlyFireTechnology"}, []string{"scoreValueVespeneFriendlyFireTechnology"}, 26490},
+}
+
+// Named stat fields.
+var (
+	StatMineralsCurrent                  = StatFields[0]
+	StatVespeneCurrent                   = StatFields[1]
+	StatMineralsCollectionRate           = StatFields[2]
+	StatVespeneCollectionRate            = StatFields[3]
+	StatWorkersActiveCount               = StatFields[4]
+	StatMineralsUsedInProgressArmy       = StatFields[5]
+	StatMineralsUsedInProgressEconomy    = StatFields[6]
+	StatMineralsUsedInProgressTechnology = StatFields[7]
+	StatVespeneUsedInProgressArmy        = StatFields[8]
+	StatVespeneUsedInProgressEconomy     = StatFields[9]
+	StatVespeneUsedInProgressTechnology  = StatFields[10]
+	StatMineralsUsedCurrentArmy          = StatFields[11]
+	StatMineralsUsedCurrentEconomy       = StatFields[12]
+	StatMineralsUsedCurrentTechnology    = StatFields[13]
+	StatVespeneUsedCurrentArmy           = StatFields[14]
+	StatVespeneUsedCurrentEconomy        = StatFields[15]
+	StatVespeneUsedCurrentTechnology     = StatFields[16]
+	StatMineralsLostArmy                 = StatFields[17]
+	StatMineralsLostEconomy              = StatFields[18]
+	StatMineralsLostTechnology           = StatFields[19]
+	StatVespeneLostArmy                  = StatFields[20]
+	StatVespeneLostEconomy               = StatFields[21]
+	StatVespeneLostTechnology            = StatFields[22]
+	StatMineralsKilledArmy               = StatFields[23]
+	StatMineralsKilledEconomy            = StatFields[24]
+	StatMineralsKilledTechnology         = StatFields[25]
+	StatVespeneKilledArmy                = StatFields[26]
+	StatVespeneKilledEconomy             = StatFields[27]
+	StatVespeneKilledTechnology          = StatFields[28]
+	StatFoodUsed                         = StatFields[29]
+	StatFoodMade                         = StatFields[30]
+	StatMineralsUsedActiveForces         = StatFields[31]
+	StatVespeneUsedActiveForces          = StatFields[32]
+	StatMineralsFriendlyFireArmy         = StatFields[33]
+	StatMineralsFriendlyFireEconomy      = StatFields[34]
+	StatMineralsFriendlyFireTechnology   = StatFields[35]
+	StatVespeneFriendlyFireArmy          = StatFields[36]
+	StatVespeneFriendlyFireEconomy       = StatFields[37]
+	StatVespeneFriendlyFireTechnology    = StatFields[38]
+)
+
+var (
+	// statFieldMap maps from canonical and protocol field names to stat fields
+	statFieldMap = map[string]*StatField{}
+	// statFieldsMu protects statFieldMap and the Names of stat fields
+	statFieldsMu sync.RWMutex
+)
+
+func init() {
+	// Build the statFieldMap map
+	for _, f := range StatFields {
+		statFieldMap[f.Name] = f
+		for _, name := range f.Names {
+			statFieldMap[name] = f
+		}
+	}
+}
+
+// RegisterStatFieldName registers a protocol field name of a stat field, e.g. if the field is renamed
+// in a new build. Registering an already registered name remaps it.
+//
+// Registration should be done before processing replays, but it is safe for concurrent use with lookups.
+func RegisterStatFieldName(f *StatField, name string) {
+	statFieldsMu.Lock()
+	defer statFieldsMu.Unlock()
+
+	if old := statFieldMap[name]; old != nil && old != f {
+		for i, n := range old.Names {
+			if n == name {
+				old.Names = append(old.Names[:i:i], old.Names[i+1:]...)
+				break
+			}
+		}
+	}
+	if !containsString(f.Names, name) {
+		f.Names = append(f.Names[:len(f.Names):len(f.Names)], name)
+	}
+	statFieldMap[name] = f
+}
+
+// StatFieldByName returns the stat field specified by its canonical or protocol field name.
+// nil is returned if the name is unknown.
+func StatFieldByName(name string) *StatField {
+	statFieldsMu.RLock()
+	defer statFieldsMu.RUnlock()
+	return statFieldMap[name]
+}
+
+// AvailableStatFields returns the stat fields available in replays of the given base build.
+// The result is empty for base builds having no tracker events.
+func AvailableStatFields(baseBuild int64) []*StatField {
+	var fields []*StatField
+	for _, f := range StatFields {
+		if f.Available(baseBuild) {
+			fields = append(fields, f)
+		}
+	}
+	return fields
+}
+
+// NormalizeStats returns the stats of a PlayerStats tracker event (the "stats" Struct)
+// mapped to stat fields. Fields unknown to the registry are omitted.
+func NormalizeStats(stats s2prot.Struct) map[*StatField]int64 {
+	statFieldsMu.RLock()
+	defer statFieldsMu.RUnlock()
+
+	m := make(map[*StatField]int64, len(stats))
+	for name, v := range stats {
+		if f := statFieldMap[name]; f != nil {
+			if i, ok := v.(int64); ok {
+				m[f] = i
+			}
+		}
+	}
+	return m
+}
+
+// StatOf returns the value of the stat field of the stats, and tells if the stats have the field.
+func StatOf(stats s2prot.Struct, f *StatField) (int64, bool) {
+	statFieldsMu.RLock()
+	defer statFieldsMu.RUnlock()
+
+	for _, name := range f.Names {
+		if v, ok := stats[name].(int64); ok {
+			return v, true
+		}
+	}
+	return 0, false
+}
+
+// StatPoint is a value of a stat at a game loop.
+type StatPoint struct {
+	Loop  int64 // Game loop of the PlayerStats event
+	Value int64 // Value of the stat
+}
+
+// StatSeries returns the values of the stat field from the PlayerStats tracker events of the player
+// specified by its index in Details.Players().
+// nil is returned if tracker events were not decoded or the field is not available in the replay's base build
+// (so it can be told apart from a series of zeros).
+func (r *Rep) StatSeries(playerIdx int, f *StatField) []StatPoint {
+	if r.TrackerEvts == nil || !f.Available(r.Header.BaseBuild()) {
+		return nil
+	}
+	series := []StatPoint{}
+	for _, e := range r.TrackerEvtsOf(playerIdx) {
+		if e.Name != TrEvtPlayerStats {
+			continue
+		}
+		stats, _ := e.Value("stats").(s2prot.Struct)
+		if v, ok := StatOf(stats, f); ok {
+			series = append(series, StatPoint{Loop: e.Loop(), Value: v})
+		}
+	}
+	return series
+}
@@ -0,0 +1,103 @@
+package rep
+
+import (
+	"reflect"
+	"regexp"
+	"sort"
+	"testing"
+
+	"github.com/icza/s2prot"
+	"github.com/icza/s2prot/build"
+)
+
+// TestStatFieldsAvailability checks if the documented availability of stat fields
+// matches the protocols of the build package.
+func TestStatFieldsAvailability(t *testing.T) {
+	fieldName := regexp.MustCompile(`(\w+)#\d+:`)
+	for bb := range build.Builds {
+		p := s2prot.GetProtocol(bb)
+		var exp []string
+		for _, et := range p.TrackerEvtTypes() {
+			if et.Name != TrEvtPlayerStats {
+				continue
+			}
+			for _, fi := range p.EvtFields(&et) {
+				if fi.Name == "stats" {
+					for _, m := range fieldName.FindAllStringSubmatch(fi.Type, -1) {
+						exp = append(exp, m[1])
+					}
+				}
+			}
+		}
+
+		var got []string
+		for _, f := range AvailableStatFields(int64(bb)) {
+			got = append(got, f.Names[0])
+		}
+		sort.Strings(exp)
+		sort.Strings(got)
+		if !reflect.DeepEqual(got, exp) {
+			t.Errorf("[%d] Expected: %v, got: %v", bb, exp, got)
+		}
+	}
+}
+
+func TestNormalizeStats(t *testing.T) {
+	stats := s2prot.Struct{"scoreValueMineralsCurrent": int64(50), "scoreValueFoodUsed": int64(12 * 4096), "unknown": int64(1)}
+	exp := map[*StatField]int64{StatMineralsCurrent: 50, StatFoodUsed: 12 * 4096}
+	if got := NormalizeStats(stats); !reflect.DeepEqual(got, exp) {
+		t.Errorf("Expected: %v, got: %v", exp, got)
+	}
+
+	if f := StatFieldByName("MineralsCurrent"); f != StatMineralsCurrent {
+		t.Errorf("Expected: %v, got: %v", StatMineralsCurrent, f)
+	}
+	if f := StatFieldByName("scoreValueVespeneCurrent"); f != StatVespeneCurrent {
+		t.Errorf("Expected: %v, got: %v", StatVespeneCurrent, f)
+	}
+	if f := StatFieldByName("unknown"); f != nil {
+		t.Errorf("Expected nil, got: %v", f)
+	}
+
+	// Register a new name (e.g. a renamed field in a custom protocol):
+	f := &StatField{Enum{"Test"}, []string{"scoreValueTest"}, 24944}
+	RegisterStatFieldName(f, "testRenamed")
+	defer func() {
+		statFieldsMu.Lock()
+		delete(statFieldMap, "testRenamed")
+		statFieldsMu.Unlock()
+	}()
+	if got := StatFieldByName("testRenamed"); got != f {
+		t.Errorf("Expected: %v, got: %v", f, got)
+	}
+	if v, ok := StatOf(s2prot.Struct{"testRenamed": int64(3)}, f); !ok || v != 3 {
+		t.Errorf("Expected: 3, true, got: %v, %v", v, ok)
+	}
+	if _, ok := StatOf(stats, StatVespeneCurrent); ok {
+		t.Error("Expected missing stat")
+	}
+}
+
+func TestStatSeries(t *testing.T) {
+	r := &Rep{}
+	r.Header = Header{Struct: s2prot.Struct{"version": s2prot.Struct{"baseBuild": int64(25604)}}}
+	evt := func(loop, pid, minerals int64) s2prot.Event {
+		return trackerEvt(loop, TrEvtPlayerStats, s2prot.Struct{"playerId": pid, "stats": s2prot.Struct{"scoreValueMineralsCurrent": minerals}})
+	}
+
+	if r.StatSeries(0, StatMineralsCurrent) != nil {
+		t.Error("Expected nil series without tracker events")
+	}
+
+	r.TrackerEvts = &TrackerEvts{Evts: []s2prot.Event{evt(0, 1, 50), evt(0, 2, 50), evt(160, 1, 120)}}
+	exp := []StatPoint{{0, 50}, {160, 120}}
+	if got := r.StatSeries(0, StatMineralsCurrent); !reflect.DeepEqual(got, exp) {
+		t.Errorf("Expected: %v, got: %v", exp, got)
+	}
+	if got := r.StatSeries(0, StatMineralsFriendlyFireArmy); got != nil {
+		t.Errorf("Expected nil series for unavailable field, got: %v", got)
+	}
+	if got := r.StatSeries(0, StatVespeneCurrent); got == nil || len(got) != 0 {
+		t.Errorf("Expected empty series, got: %v", got)
+	}
+}
@@ -0,0 +1,117 @@
+/*
+
+Computed summary of the replay for quick consumption.
+
+*/
+
+package rep
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Summary is a small, flat summary of the replay, designed for databases and list views.
+type Summary struct {
+	Map       string          `json:"map"`       // Map name
+	Date      time.Time       `json:"date"`      // Date+time of the game
+	Duration  time.Duration   `json:"duration"`  // Real (wall clock) duration of the game
+	Format    string          `json:"format"`    // Game format, e.g. "1v1", "2v2", "FFA"
+	Matchup   string          `json:"matchup"`   // Matchup, e.g. "PvT"
+	Region    string          `json:"region"`    // 2-letter region code, e.g. "EU"
+	Expansion string          `json:"expansion"` // Expansion level, e.g. "LotV"
+	Version   string          `json:"version"`   // Public game version, e.g. "4.10.1"
+	Players   []SummaryPlayer `json:"players"`   // Players (computer players included, observers excluded)
+}
+
+// SummaryPlayer is a player in the Summary.
+type SummaryPlayer struct {
+	Name      string  `json:"name"`      // Name without clan tag
+	ClanTag   string  `json:"clanTag"`   // Clan tag, empty string if the player has none
+	Toon      string  `json:"toon"`      // Toon handle, empty string for computer players
+	Team      int64   `json:"team"`      // Team ID
+	Race      string  `json:"race"`      // Assigned race
+	WasRandom bool    `json:"wasRandom"` // Tells if Random race was selected
+	Result    string  `json:"result"`    // Reconciled result, e.g. "Victory"
+	APM       float64 `json:"apm"`       // APM, 0 if game events were not decoded
+	MMR       int64   `json:"mmr"`       // MMR, 0 if not available
+}
+
+// Summary returns a computed summary of the replay.
+func (r *Rep) Summary() *Summary {
+	s := &Summary{
+		Map:       r.Details.Title(),
+		Date:      r.Details.Time(),
+		Duration:  r.RealDuration(),
+		Format:    r.gameFormat(),
+		Matchup:   r.Details.Matchup(),
+		Region:    r.InitData.GameDescription.Region().Code,
+		Expansion: r.ExpansionLevel().String(),
+		Version:   r.PatchVersionName(),
+	}
+
+	apms, results := r.apms(), r.PlayerResults()
+	players := r.Details.Players()
+	s.Players = make([]SummaryPlayer, len(players))
+	for i := range players {
+		p := &players[i]
+		sp := SummaryPlayer{
+			Name:      p.BareName(),
+			ClanTag:   r.PlayerClanTag(i),
+			Team:      r.PlayerTeamID(i),
+			Race:      r.PlayerAssignedRace(i).String(),
+			WasRandom: r.PlayerWasRandom(i),
+			Result:    results[i].String(),
+			APM:       apms[i],
+		}
+		if p.Control() == ControlHuman {
+			sp.Toon = p.Toon.String()
+		}
+		sp.MMR, _ = r.PlayerMMR(i)
+		s.Players[i] = sp
+	}
+
+	return s
+}
+
+// gameFormat returns the game format. Campaign games are reported as "Campaign".
+// The game format attribute is used if present. Arcade games without the attribute are reported as "Arcade"
+// (their team setup is arbitrary), else it is derived from the team sizes,
+// e.g. "2v2" or "FFA" (if all teams have 1 player and there are more than 2 teams).
+func (r *Rep) gameFormat() string {
+	rk := r.Ranking()
+	if rk == RankingCampaign {
+		return "Campaign"
+	}
+	if f := r.AttrEvts.GameFormat(); f != "" {
+		return f
+	}
+	if rk == RankingArcade {
+		return "Arcade"
+	}
+
+	players := r.Details.Players()
+	if len(players) == 0 {
+		return ""
+	}
+	teamSizes := map[int64]int{}
+	for i := range players {
+		teamSizes[r.PlayerTeamID(i)]++
+	}
+	if len(teamSizes) > 2 && len(teamSizes) == len(players) {
+		return "FFA"
+	}
+
+	teamIDs := make([]int64, 0, len(teamSizes))
+	for teamID := range teamSizes {
+		teamIDs = append(teamIDs, teamID)
+	}
+	sort.Slice(teamIDs, func(i, j int) bool { return teamIDs[i] < teamIDs[j] })
+	sizes := make([]string, len(teamIDs))
+	for i, teamID := range teamIDs {
+		sizes[i] = strconv.Itoa(teamSizes[teamID])
+	}
+	return strings.Join(sizes, "v")
+}
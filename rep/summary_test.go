@@ -0,0 +1,65 @@
+package rep
+
+import (
+	"testing"
+
+	"github.com/icza/s2prot"
+)
+
+func TestSummary(t *testing.T) {
+	r := &Rep{}
+	r.Header = Header{Struct: s2prot.Struct{
+		"elapsedGameLoops": int64(16 * 84),
+		"version":          s2prot.Struct{"major": int64(4), "minor": int64(10), "revision": int64(1), "baseBuild": int64(75800)},
+	}}
+	toon := s2prot.Struct{"region": int64(2), "programId": "S2", "realm": int64(1), "id": int64(42)}
+	r.Details = Details{Struct: s2prot.Struct{
+		"title":     "Test Map",
+		"gameSpeed": int64(4),
+		"playerList": []interface{}{
+			s2prot.Struct{"name": "[ABC]<sp/>Foo", "toon": toon, "race": "Terran", "result": int64(1), "control": int64(2), "teamId": int64(0)},
+			s2prot.Struct{"name": "Bar", "toon": s2prot.Struct{}, "race": "Zerg", "result": int64(2), "control": int64(3), "teamId": int64(1)},
+		},
+	}}
+
+	s := r.Summary()
+	if s.Map != "Test Map" || s.Duration.Minutes() != 1 || s.Format != "1v1" || s.Matchup != "TvZ" || s.Version != "4.10.1" {
+		t.Errorf("Unexpected summary: %+v", s)
+	}
+	exp := []SummaryPlayer{
+		{Name: "Foo", ClanTag: "ABC", Toon: "2-S2-1-42", Team: 0, Race: "Terran", Result: "Victory"},
+		{Name: "Bar", Team: 1, Race: "Zerg", Result: "Defeat"},
+	}
+	if len(s.Players) != len(exp) {
+		t.Fatalf("Expected %d players, got: %v", len(exp), s.Players)
+	}
+	for i := range exp {
+		if s.Players[i] != exp[i] {
+			t.Errorf("[%d] Expected: %+v, got: %+v", i, exp[i], s.Players[i])
+		}
+	}
+}
+
+func TestGameFormat(t *testing.T) {
+	cases := []struct {
+		teamIDs []int64
+		exp     string
+	}{
+		{nil, ""},
+		{[]int64{0, 1}, "1v1"},
+		{[]int64{1, 0, 1, 0}, "2v2"},
+		{[]int64{0, 0, 1}, "2v1"},
+		{[]int64{0, 1, 2, 3}, "FFA"},
+	}
+
+	for _, c := range cases {
+		var players []interface{}
+		for _, teamID := range c.teamIDs {
+			players = append(players, s2prot.Struct{"teamId": teamID})
+		}
+		r := &Rep{Details: Details{Struct: s2prot.Struct{"playerList": players}}}
+		if got := r.gameFormat(); got != c.exp {
+			t.Errorf("[%v] Expected: %q, got: %q", c.teamIDs, c.exp, got)
+		}
+	}
+}
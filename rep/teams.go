@@ -0,0 +1,87 @@
+/*
+
+Teams of the game rebuilt from the lobby slots.
+
+*/
+
+package rep
+
+import "sort"
+
+// PlayerTeamID returns the team ID of the player specified by its index in Details.Players().
+// Team ID of the lobby slot is used if available as the one in Details is not always accurate.
+// -1 is returned if the player index is invalid.
+func (r *Rep) PlayerTeamID(playerIdx int) int64 {
+	return r.playerTeamID(r.PlayerSlots(), playerIdx)
+}
+
+// playerTeamID returns the team ID of the player (see PlayerTeamID), slots being the result of PlayerSlots.
+func (r *Rep) playerTeamID(slots []int, playerIdx int) int64 {
+	if playerIdx >= 0 && playerIdx < len(slots) && slots[playerIdx] >= 0 {
+		return r.InitData.LobbyState.Slots[slots[playerIdx]].TeamID()
+	}
+	players := r.Details.Players()
+	if playerIdx < 0 || playerIdx >= len(players) {
+		return -1
+	}
+	return players[playerIdx].TeamID()
+}
+
+// Team is a team of the game.
+type Team struct {
+	ID         int64   // Team ID (of the lobby slots)
+	PlayerIdxs []int   // Indices of the members in Details.Players(), in increasing order
+	Races      string  // Assigned race letters of the members, in the order of PlayerIdxs, e.g. "PT"
+	Result     *Result // Reconciled result of the team (see Rep.PlayerResults)
+	APM        float64 // Average APM of the human members, 0 if game events were not decoded
+	MMR        float64 // Average MMR of the members whose MMR is available, 0 if not available
+}
+
+// Size returns the number of members of the team.
+func (t *Team) Size() int {
+	return len(t.PlayerIdxs)
+}
+
+// Teams returns the teams of the game in increasing team ID order, built from the team IDs of the lobby slots
+// (see PlayerTeamID). Observers are not members of any team.
+func (r *Rep) Teams() []*Team {
+	players := r.Details.Players()
+	apms, results, slots := r.apms(), r.PlayerResults(), r.PlayerSlots()
+
+	teamMap := map[int64]*Team{}
+	var teams []*Team
+	apmCounts, mmrCounts := map[*Team]int{}, map[*Team]int{}
+	for i := range players {
+		teamID := r.playerTeamID(slots, i)
+		t := teamMap[teamID]
+		if t == nil {
+			t = &Team{ID: teamID, Result: ResultUnknown}
+			teamMap[teamID] = t
+			teams = append(teams, t)
+		}
+		t.PlayerIdxs = append(t.PlayerIdxs, i)
+		t.Races += string(r.PlayerAssignedRace(i).Letter)
+		if t.Result == ResultUnknown || results[i] == ResultVictory {
+			t.Result = results[i]
+		}
+		if apm, ok := apms[i]; ok {
+			t.APM += apm
+			apmCounts[t]++
+		}
+		if mmr, ok := r.PlayerMMR(i); ok {
+			t.MMR += float64(mmr)
+			mmrCounts[t]++
+		}
+	}
+
+	for _, t := range teams {
+		if n := apmCounts[t]; n > 0 {
+			t.APM /= float64(n)
+		}
+		if n := mmrCounts[t]; n > 0 {
+			t.MMR /= float64(n)
+		}
+	}
+	sort.Slice(teams, func(i, j int) bool { return teams[i].ID < teams[j].ID })
+	return teams
+}
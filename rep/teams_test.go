@@ -0,0 +1,48 @@
+package rep
+
+import (
+	"testing"
+
+	"github.com/icza/s2prot"
+)
+
+func TestTeams(t *testing.T) {
+	r := newTestRep(
+		humanSlot(0, s2prot.Struct{"teamId": int64(1)}),
+		humanSlot(1, s2prot.Struct{"teamId": int64(0)}),
+		computerSlot(s2prot.Struct{"teamId": int64(0)}),
+	)
+	// Team IDs of Details are wrong, the lobby slots are authoritative:
+	setTestPlayers(r,
+		s2prot.Struct{"teamId": int64(0), "race": "Zerg", "result": int64(2)},
+		s2prot.Struct{"teamId": int64(0), "race": "Protoss", "result": int64(1)},
+		s2prot.Struct{"teamId": int64(1), "race": "Terran", "result": int64(1)},
+	)
+
+	teams := r.Teams()
+	exp := []struct {
+		id     int64
+		idxs   []int
+		races  string
+		result *Result
+	}{
+		{0, []int{1, 2}, "PT", ResultVictory},
+		{1, []int{0}, "Z", ResultDefeat},
+	}
+	if len(teams) != len(exp) {
+		t.Fatalf("Expected %d teams, got: %d", len(exp), len(teams))
+	}
+	for i, team := range teams {
+		e := exp[i]
+		if team.ID != e.id || team.Size() != len(e.idxs) || team.PlayerIdxs[0] != e.idxs[0] || team.Races != e.races || team.Result != e.result {
+			t.Errorf("[%d] Expected: %+v, got: %+v", i, e, team)
+		}
+	}
+
+	if id := r.PlayerTeamID(0); id != 1 {
+		t.Errorf("Expected team ID: %d, got: %d", 1, id)
+	}
+	if id := r.PlayerTeamID(3); id != -1 {
+		t.Errorf("Expected team ID: %d, got: %d", -1, id)
+	}
+}
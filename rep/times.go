@@ -0,0 +1,34 @@
+/*
+
+Real duration, start and end times of the game.
+
+*/
+
+package rep
+
+import "time"
+
+// RealDuration returns the real (wall clock) duration of the game,
+// which is the game duration (see Header.Duration()) adjusted to the game speed.
+func (r *Rep) RealDuration() time.Duration {
+	return r.loopDuration(r.Header.Loops())
+}
+
+// StartTimeLocal returns the start time of the game in the local time zone of the player who saved the replay:
+// the end time minus the real duration (see EndTimeUTC()).
+func (r *Rep) StartTimeLocal() time.Time {
+	return r.Details.LocalTime().Add(-r.RealDuration())
+}
+
+// EndTimeUTC returns the end time of the game in UTC.
+//
+// The replay timestamp (see Details.TimeUTC()) is the time the replay was saved, which happens when the game ends,
+// so it is the end time of the game (and not the start time).
+func (r *Rep) EndTimeUTC() time.Time {
+	return r.Details.TimeUTC().UTC()
+}
+
+// loopDuration converts a game loop count to real (wall clock) duration, adjusted to the game speed.
+func (r *Rep) loopDuration(loops int64) time.Duration {
+	return LoopsToRealDuration(loops, r.Details.GameSpeed())
+}
@@ -0,0 +1,33 @@
+package rep
+
+import (
+	"testing"
+	"time"
+
+	"github.com/icza/s2prot"
+)
+
+func TestTimes(t *testing.T) {
+	end := time.Date(2020, 1, 2, 10, 0, 0, 0, time.UTC)
+	offset := 2 * time.Hour
+
+	r := &Rep{}
+	r.Header = Header{Struct: s2prot.Struct{"elapsedGameLoops": int64(16 * 140)}} // 140 game seconds
+	r.Details = Details{Struct: s2prot.Struct{
+		// timeUTC holds the local time of the player who saved the replay
+		"timeUTC":         end.Add(offset).UnixNano()/100 + fileTimeUnixEpoch,
+		"timeLocalOffset": int64(offset / 100),
+		"gameSpeed":       int64(4), // Faster
+	}}
+
+	if d := r.RealDuration(); d != 100*time.Second {
+		t.Errorf("Expected real duration: %v, got: %v", 100*time.Second, d)
+	}
+	start := end.Add(-100 * time.Second)
+	if lt := r.StartTimeLocal(); !lt.Equal(start) || lt.Hour() != 11 || lt.Minute() != 58 {
+		t.Errorf("Expected local start time: %v, got: %v", start.Add(offset), lt)
+	}
+	if et := r.EndTimeUTC(); !et.Equal(end) || !et.Equal(r.Details.TimeUTC()) || et.Location() != time.UTC {
+		t.Errorf("Expected end time: %v, got: %v", end, et)
+	}
+}
@@ -0,0 +1,80 @@
+package rep_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/icza/s2prot"
+	"github.com/icza/s2prot/rep"
+	"github.com/icza/s2prot/rep/reptest"
+)
+
+func TestCampaignReplay(t *testing.T) {
+	r := reptest.New(80949)
+	r.Players = r.Players[:1]
+	r.Customize = func(kind string, s s2prot.Struct) {
+		if kind == s2prot.KindDetails {
+			s["campaignIndex"] = int64(2)
+		}
+	}
+	// Campaign replays have no player stats, and may refer to slots not present in the lobby:
+	r.TrackerEvt(0, "PlayerSetup", s2prot.Struct{"playerId": int64(1), "slotId": int64(0), "userId": int64(0)})
+	r.TrackerEvt(0, "PlayerSetup", s2prot.Struct{"playerId": int64(5), "slotId": int64(20), "userId": int64(5)})
+
+	parsed, err := r.Rep()
+	if err != nil {
+		t.Fatalf("Failed to parse replay: %v", err)
+	}
+	defer parsed.Close()
+
+	if rk := parsed.Ranking(); rk != rep.RankingCampaign {
+		t.Errorf("Expected ranking: %v, got: %v", rep.RankingCampaign, rk)
+	}
+	if f := parsed.Summary().Format; f != "Campaign" {
+		t.Errorf("Expected format: %s, got: %s", "Campaign", f)
+	}
+	if got := len(parsed.TrackerEvts.PIDPlayerDescMap); got != 2 {
+		t.Errorf("Expected %d player descs, got: %d", 2, got)
+	}
+	if pd := parsed.TrackerEvts.PIDPlayerDescMap[1]; pd == nil || pd.SQ != 0 {
+		t.Errorf("Expected player desc without SQ, got: %+v", pd)
+	}
+}
+
+func TestArcadeReplay(t *testing.T) {
+	r := reptest.New(80949)
+	r.Players = nil
+	for i := 0; i < 20; i++ {
+		r.Players = append(r.Players, reptest.Player{
+			Name: fmt.Sprint("Player ", i+1), Race: rep.RaceProtoss, TeamID: int64(i % 2), Computer: i >= 4,
+		})
+	}
+	// Stats without resource fields:
+	r.TrackerEvt(0, "PlayerSetup", s2prot.Struct{"playerId": int64(2), "slotId": int64(1), "userId": int64(1)})
+	r.TrackerEvt(0, "PlayerSetup", s2prot.Struct{"playerId": int64(18), "slotId": int64(17)})
+	r.TrackerEvt(160, "PlayerStats", s2prot.Struct{"playerId": int64(2)})
+	r.TrackerEvt(160, "PlayerStats", s2prot.Struct{"playerId": int64(18)})
+
+	parsed, err := r.Rep()
+	if err != nil {
+		t.Fatalf("Failed to parse replay: %v", err)
+	}
+	defer parsed.Close()
+
+	if got := len(parsed.Details.Players()); got != 20 {
+		t.Errorf("Expected %d players, got: %d", 20, got)
+	}
+	if rk := parsed.Ranking(); rk != rep.RankingArcade {
+		t.Errorf("Expected ranking: %v, got: %v", rep.RankingArcade, rk)
+	}
+	s := parsed.Summary()
+	if s.Format != "Arcade" || len(s.Players) != 20 {
+		t.Errorf("Expected arcade format with %d players, got: %+v", 20, s)
+	}
+	if pd := parsed.TrackerEvts.ToonPlayerDescMap["1-S2-1-2"]; pd == nil || pd.PlayerID != 2 {
+		t.Errorf("Expected player desc of player %d, got: %+v", 2, pd)
+	}
+	if pd := parsed.TrackerEvts.PIDPlayerDescMap[18]; pd == nil || pd.SlotID != 17 {
+		t.Errorf("Expected player desc of player %d, got: %+v", 18, pd)
+	}
+}
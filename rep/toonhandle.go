@@ -0,0 +1,68 @@
+/*
+
+Parsed, comparable toon handles.
+
+*/
+
+package rep
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidToonHandle means the toon handle is not in the form of "regionId-programId-realmId-id".
+var ErrInvalidToonHandle = errors.New("Invalid toon handle")
+
+// ToonHandle is a parsed toon handle, a unique identifier of a player.
+// It's comparable, so it can be used as a map key.
+type ToonHandle struct {
+	RegionID  int64  // Region ID
+	ProgramID string // Program ID, e.g. "S2"
+	RealmID   int64  // Realm ID
+	ID        int64  // ID of the player in the realm
+}
+
+// ParseToonHandle parses a toon handle in the form of "regionId-programId-realmId-id",
+// e.g. "2-S2-1-1234567", which is the format used in InitData["lobbyState"]["slots"]["toonHandle"].
+func ParseToonHandle(s string) (h ToonHandle, err error) {
+	parts := strings.Split(s, "-")
+	if len(parts) != 4 || parts[1] == "" {
+		return h, ErrInvalidToonHandle
+	}
+
+	h.ProgramID = parts[1]
+	for i, dst := range []*int64{&h.RegionID, nil, &h.RealmID, &h.ID} {
+		if dst == nil {
+			continue
+		}
+		if *dst, err = strconv.ParseInt(parts[i], 10, 64); err != nil {
+			return ToonHandle{}, ErrInvalidToonHandle
+		}
+	}
+
+	return h, nil
+}
+
+// String returns the canonical form of the toon handle: "regionId-programId-realmId-id".
+func (h ToonHandle) String() string {
+	return fmt.Sprintf("%d-%s-%d-%d", h.RegionID, h.ProgramID, h.RealmID, h.ID)
+}
+
+// Region returns the region.
+func (h ToonHandle) Region() *Region {
+	return regionByID(h.RegionID)
+}
+
+// Realm returns the realm.
+func (h ToonHandle) Realm() *Realm {
+	return h.Region().Realm(h.RealmID)
+}
+
+// Handle returns the toon handle of the toon.
+// Its canonical form (String()) is the same as the string representation of the Toon.
+func (t *Toon) Handle() ToonHandle {
+	return ToonHandle{RegionID: t.RegionID(), ProgramID: t.ProgramID(), RealmID: t.RealmID(), ID: t.ID()}
+}
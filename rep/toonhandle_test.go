@@ -0,0 +1,69 @@
+package rep
+
+import (
+	"testing"
+
+	"github.com/icza/s2prot"
+)
+
+func TestParseToonHandle(t *testing.T) {
+	cases := []struct {
+		s   string
+		exp ToonHandle
+		err error
+	}{
+		{"2-S2-1-1234567", ToonHandle{2, "S2", 1, 1234567}, nil},
+		{"1-S2-2-42", ToonHandle{1, "S2", 2, 42}, nil},
+		{"", ToonHandle{}, ErrInvalidToonHandle},
+		{"2-S2-1", ToonHandle{}, ErrInvalidToonHandle},
+		{"2--1-1234567", ToonHandle{}, ErrInvalidToonHandle},
+		{"x-S2-1-1234567", ToonHandle{}, ErrInvalidToonHandle},
+		{"2-S2-1-1234567-1", ToonHandle{}, ErrInvalidToonHandle},
+	}
+
+	for _, c := range cases {
+		h, err := ParseToonHandle(c.s)
+		if h != c.exp || err != c.err {
+			t.Errorf("[%s] Expected: %v, %v, got: %v, %v", c.s, c.exp, c.err, h, err)
+		}
+		if err == nil && h.String() != c.s {
+			t.Errorf("[%s] Expected canonical form: %s, got: %s", c.s, c.s, h.String())
+		}
+	}
+
+	if h, _ := ParseToonHandle("2-S2-1-1234567"); h.Region() != RegionEU || h.Realm() != RealmEurope {
+		t.Errorf("Unexpected region or realm: %v, %v", h.Region(), h.Realm())
+	}
+}
+
+func TestToonHandle(t *testing.T) {
+	toon := Toon{Struct: s2prot.Struct{"region": int64(2), "programId": "\x00\x00S2", "realm": int64(1), "id": int64(1234567)}}
+
+	h := toon.Handle()
+	if h.String() != toon.String() {
+		t.Errorf("Expected: %s, got: %s", toon.String(), h.String())
+	}
+	if h2, err := ParseToonHandle(toon.String()); err != nil || h2 != h {
+		t.Errorf("Expected: %v, got: %v (err: %v)", h, h2, err)
+	}
+}
+
+func TestProfileURLs(t *testing.T) {
+	h := ToonHandle{RegionID: 2, ProgramID: "S2", RealmID: 1, ID: 1234567}
+
+	cases := []struct{ name, got, exp string }{
+		{"ProfileURL", h.ProfileURL(""), "https://starcraft2.blizzard.com/en-us/profile/2/1/1234567"},
+		{"ProfileURL de", h.ProfileURL("de-de"), "https://starcraft2.blizzard.com/de-de/profile/2/1/1234567"},
+		{"APIPath", h.APIPath(), "/sc2/profile/2/1/1234567"},
+		{"APIURL", h.APIURL(), "https://eu.api.blizzard.com/sc2/profile/2/1/1234567"},
+		{"APIURL unknown", ToonHandle{RegionID: 99}.APIURL(), ""},
+		{"PulseID", h.PulseID(), "2/1/1234567"},
+		{"PulseSearchURL", h.PulseSearchURL(),
+			"https://sc2pulse.nephest.com/sc2/?type=search&name=https%3A%2F%2Fstarcraft2.blizzard.com%2Fen-us%2Fprofile%2F2%2F1%2F1234567"},
+	}
+	for _, c := range cases {
+		if c.got != c.exp {
+			t.Errorf("[%s] Expected: %s, got: %s", c.name, c.exp, c.got)
+		}
+	}
+}
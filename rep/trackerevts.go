@@ -136,9 +136,13 @@ func (t *TrackerEvts) init(rep *Rep) {
 
 	// Fill ToonPlayerDescMap
 	t.ToonPlayerDescMap = make(map[string]*PlayerDesc)
+	// Slot IDs are not validated by the game (e.g. campaign and arcade replays), check them:
+	slots := rep.InitData.LobbyState.Slots
 	for _, pd := range pidPlayerDescMap {
-		slot := rep.InitData.LobbyState.Slots[pd.SlotID]
-		t.ToonPlayerDescMap[slot.ToonHandle()] = pd
+		if pd.SlotID < 0 || pd.SlotID >= int64(len(slots)) {
+			continue
+		}
+		t.ToonPlayerDescMap[slots[pd.SlotID].ToonHandle()] = pd
 	}
 }
 
@@ -191,6 +195,11 @@ func angleToClock(angle float64) int32 {
 // and samples are taken up to the loop of the last cmd game event of the user.
 //
 // Source: Do you macro like a pro? http://www.teamliquid.net/forum/viewmessage.php?topic_id=266019
+//
+// Unspent resources less than 1 are treated as 1 (e.g. when the resource stats are absent).
 func calcSQ(unspentResources, income int64) int32 {
+	if unspentResources < 1 {
+		unspentResources = 1
+	}
 	return int32(35*(0.00137*float64(income)-math.Log(float64(unspentResources))) + 240 + 0.5)
 }
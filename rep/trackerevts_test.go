@@ -72,6 +72,7 @@ func TestCalcSQ(t *testing.T) {
 		{556, 1486, 90},
 		{1580, 2078, 82},
 		{547, 1219, 78},
+		{0, 0, 240}, // Absent resource stats
 	}
 
 	for _, c := range cases {
@@ -0,0 +1,143 @@
+/*
+
+Typed wrappers of the resource trade and alliance game events.
+
+*/
+
+package rep
+
+import "github.com/icza/s2prot"
+
+// Resources holds amounts of resources, as recorded in resource trade and request events.
+type Resources struct {
+	Minerals, Vespene, Terrazine, Custom int64
+}
+
+// resourcesOf returns the Resources of a "resources" event field (an array of amounts).
+func resourcesOf(v interface{}) (res Resources) {
+	arr, _ := v.([]interface{})
+	for i, ptr := range []*int64{&res.Minerals, &res.Vespene, &res.Terrazine, &res.Custom} {
+		if i < len(arr) {
+			*ptr, _ = arr[i].(int64)
+		}
+	}
+	return
+}
+
+// ResourceTradeEvt wraps a ResourceTrade game event: a player sending resources to another player.
+type ResourceTradeEvt struct {
+	s2prot.Event
+}
+
+// RecipientID returns the player ID of the recipient (index in Details.Players() plus 1).
+func (e ResourceTradeEvt) RecipientID() int64 {
+	return e.Int("recipientId")
+}
+
+// Resources returns the sent resources.
+func (e ResourceTradeEvt) Resources() Resources {
+	return resourcesOf(e.Value("resources"))
+}
+
+// ResourceRequestEvt wraps a ResourceRequest game event: a player requesting resources from the allies.
+type ResourceRequestEvt struct {
+	s2prot.Event
+}
+
+// Resources returns the requested resources.
+func (e ResourceRequestEvt) Resources() Resources {
+	return resourcesOf(e.Value("resources"))
+}
+
+// ResourceRequestFulfillEvt wraps a ResourceRequestFulfill game event: a player fulfilling a resource request.
+type ResourceRequestFulfillEvt struct {
+	s2prot.Event
+}
+
+// RequestID returns the ID of the fulfilled request.
+func (e ResourceRequestFulfillEvt) RequestID() int64 {
+	return e.Int("fulfillRequestId")
+}
+
+// ResourceRequestCancelEvt wraps a ResourceRequestCancel game event: a player cancelling its resource request.
+type ResourceRequestCancelEvt struct {
+	s2prot.Event
+}
+
+// RequestID returns the ID of the cancelled request.
+func (e ResourceRequestCancelEvt) RequestID() int64 {
+	return e.Int("cancelRequestId")
+}
+
+// AllianceEvt wraps an Alliance game event: a player changing its alliance settings (e.g. in FFA games).
+type AllianceEvt struct {
+	s2prot.Event
+}
+
+// Alliance returns the alliance bit field.
+func (e AllianceEvt) Alliance() int64 {
+	return e.Int("alliance")
+}
+
+// Control returns the bit field of the alliance settings the event changes.
+func (e AllianceEvt) Control() int64 {
+	return e.Int("control")
+}
+
+// ResourceTrade describes resources sent from a player to another.
+type ResourceTrade struct {
+	Loop         int64     // Game loop of the trade
+	SenderIdx    int       // Index of the sender in Details.Players()
+	RecipientIdx int       // Index of the recipient in Details.Players()
+	Resources    Resources // Sent resources
+}
+
+// ResourceTrades returns the resource trades of the game, in the order they happened.
+// Trades of users who are not players are excluded.
+// nil is returned if game events were not decoded.
+func (r *Rep) ResourceTrades() []ResourceTrade {
+	if r.GameEvts == nil {
+		return nil
+	}
+
+	trades := []ResourceTrade{}
+	userPlayerIdxs := r.userPlayerIdxs()
+	for _, e := range r.GameEvts {
+		if e.Name != "ResourceTrade" {
+			continue
+		}
+		userID, ok := evtUserID(e)
+		if !ok {
+			continue
+		}
+		senderIdx, ok := userPlayerIdxs[userID]
+		if !ok {
+			continue
+		}
+		te := ResourceTradeEvt{e}
+		trades = append(trades, ResourceTrade{
+			Loop:         e.Loop(),
+			SenderIdx:    senderIdx,
+			RecipientIdx: int(te.RecipientID() - 1),
+			Resources:    te.Resources(),
+		})
+	}
+
+	return trades
+}
+
+// AllianceEvts returns the Alliance game events, in the order they happened.
+// nil is returned if game events were not decoded.
+func (r *Rep) AllianceEvts() []AllianceEvt {
+	if r.GameEvts == nil {
+		return nil
+	}
+
+	evts := []AllianceEvt{}
+	for _, e := range r.GameEvts {
+		if e.Name == "Alliance" {
+			evts = append(evts, AllianceEvt{e})
+		}
+	}
+	return evts
+}
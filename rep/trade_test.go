@@ -0,0 +1,51 @@
+package rep
+
+import (
+	"testing"
+
+	"github.com/icza/s2prot"
+)
+
+func TestResourceTrades(t *testing.T) {
+	r := newTestRep(humanSlot(1), humanSlot(0))
+
+	if r.ResourceTrades() != nil || r.AllianceEvts() != nil {
+		t.Error("Expected nil without game events!")
+	}
+
+	r.GameEvts = []s2prot.Event{
+		gameEvt(100, 1, "ResourceTrade", s2prot.Struct{"recipientId": int64(2), "resources": []interface{}{int64(200), int64(50)}}),
+		gameEvt(150, 0, "Alliance", s2prot.Struct{"alliance": int64(3), "control": int64(1)}),
+		gameEvt(200, 0, "ResourceTrade", s2prot.Struct{"recipientId": int64(1), "resources": []interface{}{int64(0), int64(100), int64(0), int64(0)}}),
+		gameEvt(300, 5, "ResourceTrade", s2prot.Struct{"recipientId": int64(1), "resources": []interface{}{int64(100)}}), // Not a player
+	}
+
+	exp := []ResourceTrade{
+		{Loop: 100, SenderIdx: 0, RecipientIdx: 1, Resources: Resources{Minerals: 200, Vespene: 50}},
+		{Loop: 200, SenderIdx: 1, RecipientIdx: 0, Resources: Resources{Vespene: 100}},
+	}
+	trades := r.ResourceTrades()
+	if len(trades) != len(exp) {
+		t.Fatalf("Expected %d trades, got: %+v", len(exp), trades)
+	}
+	for i, tr := range trades {
+		if tr != exp[i] {
+			t.Errorf("[%d] Expected: %+v, got: %+v", i, exp[i], tr)
+		}
+	}
+
+	aes := r.AllianceEvts()
+	if len(aes) != 1 || aes[0].Alliance() != 3 || aes[0].Control() != 1 {
+		t.Errorf("Unexpected alliance events: %v", aes)
+	}
+
+	req := ResourceRequestEvt{gameEvt(0, 0, "ResourceRequest", s2prot.Struct{"resources": []interface{}{int64(400)}})}
+	if res := req.Resources(); res != (Resources{Minerals: 400}) {
+		t.Errorf("Unexpected requested resources: %+v", res)
+	}
+	ful := ResourceRequestFulfillEvt{gameEvt(0, 0, "ResourceRequestFulfill", s2prot.Struct{"fulfillRequestId": int64(7)})}
+	cnl := ResourceRequestCancelEvt{gameEvt(0, 0, "ResourceRequestCancel", s2prot.Struct{"cancelRequestId": int64(8)})}
+	if ful.RequestID() != 7 || cnl.RequestID() != 8 {
+		t.Errorf("Unexpected request IDs: %d, %d", ful.RequestID(), cnl.RequestID())
+	}
+}
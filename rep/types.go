@@ -9,9 +9,11 @@ package rep
 import (
 	"encoding/hex"
 	"fmt"
+	"math"
 	"net/url"
 	"path"
 	"strings"
+	"sync"
 )
 
 // Enum is the base of enum-like types.
@@ -95,6 +97,22 @@ var (
 	GameSpeedUnknown = GameSpeeds[5]
 )
 
+// Factor returns the speed factor of the game speed compared to Normal, e.g. 1.4 for Faster.
+// Unknown is treated as Faster.
+func (g *GameSpeed) Factor() float64 {
+	switch g {
+	case GameSpeedSlower:
+		return 0.6
+	case GameSpeedSlow:
+		return 0.8
+	case GameSpeedNormal:
+		return 1
+	case GameSpeedFast:
+		return 1.2
+	}
+	return 1.4
+}
+
 // gameSpeedByID returns the GameSpeed specified by its ID.
 // GameSpeedUnknown is returned if ID is unknown.
 func gameSpeedByID(gameSpeedID int64) *GameSpeed {
@@ -104,19 +122,31 @@ func gameSpeedByID(gameSpeedID int64) *GameSpeed {
 	return GameSpeedUnknown
 }
 
+// gameSpeedByAttrValue returns the GameSpeed specified by its attribute value.
+// GameSpeedUnknown is returned if attribute value is unknown.
+func gameSpeedByAttrValue(attrValue string) *GameSpeed {
+	for _, gs := range GameSpeeds {
+		if gs.attrValue == attrValue {
+			return gs
+		}
+	}
+	return GameSpeedUnknown
+}
+
 // Race type.
 type Race struct {
 	Enum
-	Letter rune // Race letter (first character of the English name)
+	Letter    rune   // Race letter (first character of the English name)
+	attrValue string // Race value used in attributes events
 }
 
 // Races is the slice of all races.
 var Races = []*Race{
-	{Enum{"Terran"}, 'T'},
-	{Enum{"Zerg"}, 'Z'},
-	{Enum{"Protoss"}, 'P'},
-	{Enum{"Random"}, 'R'},
-	{Enum{"Unknown"}, '-'},
+	{Enum{"Terran"}, 'T', "Terr"},
+	{Enum{"Zerg"}, 'Z', "Zerg"},
+	{Enum{"Protoss"}, 'P', "Prot"},
+	{Enum{"Random"}, 'R', "RAND"},
+	{Enum{"Unknown"}, '-', ""},
 }
 
 // Named races.
@@ -128,28 +158,12 @@ var (
 	RaceUnknown = Races[4]
 )
 
-// Map of localized race names, maps from localized name to Race, used in Details["playerList"]["race"]
-var localRaceNames = make(map[string]*Race)
-
-func init() {
-	// Build the localRaceNames map
-	// English, German, Portuguese, Korean, Chinese, Russian, Russian (variant?), Polish, Mandarin (Chinese)
-	for _, s := range []string{"Terran", "Terraner", "Terrano", "테란", "人類", "Терран", "Терраны", "Terrani", "人类"} {
-		localRaceNames[s] = RaceTerran
-	}
-	// English, Korean, Chinese, Russian, Russian (variant?), Polish, Mandarin (Chinese)
-	for _, s := range []string{"Zerg", "저그", "蟲族", "Зерг", "Зерги", "Zergi", "异虫"} {
-		localRaceNames[s] = RaceZerg
-	}
-	// English, Korean, Chinese, Russian, Russian (variant?), Polish, Mandarin (Chinese)
-	for _, s := range []string{"Protoss", "프로토스", "神族", "Протосс", "Протоссы", "Protosi", "星灵"} {
-		localRaceNames[s] = RaceProtoss
-	}
-}
-
-// RaceFromLocalString returns the race specified by a localized name.
+// RaceFromLocalString returns the race specified by a localized name, see RegisterLocalRaceNames.
 func raceFromLocalString(s string) *Race {
-	if r, ok := localRaceNames[s]; ok {
+	localRaceNamesMu.RLock()
+	r, ok := localRaceNames[s]
+	localRaceNamesMu.RUnlock()
+	if ok {
 		return r
 	}
 
@@ -175,6 +189,29 @@ func raceByID(raceID int64) *Race {
 	return RaceUnknown
 }
 
+// raceByMetaValue returns the Race specified by its value used in the game metadata,
+// which is the same as the attribute value except for the letter case (e.g. "Rand").
+// RaceUnknown is returned if the value is unknown.
+func raceByMetaValue(metaValue string) *Race {
+	for _, r := range Races {
+		if r.attrValue != "" && strings.EqualFold(r.attrValue, metaValue) {
+			return r
+		}
+	}
+	return RaceUnknown
+}
+
+// raceByAttrValue returns the Race specified by its attribute value.
+// RaceUnknown is returned if attribute value is unknown.
+func raceByAttrValue(attrValue string) *Race {
+	for _, r := range Races {
+		if r.attrValue == attrValue {
+			return r
+		}
+	}
+	return RaceUnknown
+}
+
 // Result type.
 type Result struct {
 	Enum
@@ -239,6 +276,64 @@ func controlByID(controlID int64) *Control {
 	return ControlUnknown
 }
 
+// controlByAttrValue returns the Control specified by its attribute value.
+// ControlUnknown is returned if attribute value is unknown.
+func controlByAttrValue(attrValue string) *Control {
+	for _, c := range Controls {
+		if c.attrValue == attrValue {
+			return c
+		}
+	}
+	return ControlUnknown
+}
+
+// Difficulty type: difficulty of computer players.
+type Difficulty struct {
+	Enum
+	attrValue string // Difficulty value used in attributes events
+}
+
+// Difficulties is the slice of all difficulties.
+var Difficulties = []*Difficulty{
+	{Enum{"Very Easy"}, "VyEy"},
+	{Enum{"Easy"}, "Easy"},
+	{Enum{"Medium"}, "Medi"},
+	{Enum{"Hard"}, "HdVH"},
+	{Enum{"Harder"}, "Hard"},
+	{Enum{"Very Hard"}, "VyHd"},
+	{Enum{"Elite"}, "Insa"},
+	{Enum{"Cheater 1 (Vision)"}, "ChVi"},
+	{Enum{"Cheater 2 (Resources)"}, "ChRe"},
+	{Enum{"Cheater 3 (Insane)"}, "ChIn"},
+	{Enum{"Unknown"}, ""},
+}
+
+// Named difficulties.
+var (
+	DifficultyVeryEasy        = Difficulties[0]
+	DifficultyEasy            = Difficulties[1]
+	DifficultyMedium          = Difficulties[2]
+	DifficultyHard            = Difficulties[3]
+	DifficultyHarder          = Difficulties[4]
+	DifficultyVeryHard        = Difficulties[5]
+	DifficultyElite           = Difficulties[6]
+	DifficultyCheaterVision   = Difficulties[7]
+	DifficultyCheaterResource = Difficulties[8]
+	DifficultyCheaterInsane   = Difficulties[9]
+	DifficultyUnknown         = Difficulties[10]
+)
+
+// difficultyByAttrValue returns the Difficulty specified by its attribute value.
+// DifficultyUnknown is returned if attribute value is unknown.
+func difficultyByAttrValue(attrValue string) *Difficulty {
+	for _, d := range Difficulties {
+		if d.attrValue == attrValue {
+			return d
+		}
+	}
+	return DifficultyUnknown
+}
+
 // Observe type.
 type Observe struct {
 	Enum
@@ -276,6 +371,7 @@ type Color struct {
 	Darker    [3]byte // Darker version of the color's RGB values.
 	Lighter   [3]byte // Lighter versions of the color's RGB values.
 	attrValue string  // Color value used in attributes events
+	custom    bool    // Tells if this is a custom color, not part of Colors
 }
 
 // Colors is the slice of all colors, index used in InitData["lobbyState"]["slots"]["colorPref"]["color"]
@@ -301,10 +397,72 @@ var Colors = []*Color{
 func init() {
 	// Init calculated / derivative fields of Color.
 	for i, c := range Colors {
-		c.attrValue = fmt.Sprintf("tc%02d", i+1)
-		c.Darker = [3]byte{c.RGB[0] / 2, c.RGB[1] / 2, c.RGB[2] / 2}
-		c.Lighter = [3]byte{128 + c.Darker[0], 128 + c.Darker[1], 128 + c.Darker[2]}
+		c.attrValue = fmt.Sprintf("tc%02d", i) // Attribute values are the indices in Colors, e.g. "tc01" is ColorRed
+		c.initShades()
+	}
+}
+
+// initShades calculates the darker and lighter versions of the color.
+func (c *Color) initShades() {
+	c.Darker = [3]byte{c.RGB[0] / 2, c.RGB[1] / 2, c.RGB[2] / 2}
+	c.Lighter = [3]byte{128 + c.Darker[0], 128 + c.Darker[1], 128 + c.Darker[2]}
+}
+
+// NewCustomColor returns a custom Color having the given RGB components, which is not part of Colors.
+//
+// Player colors are not restricted to the palette of Colors in newer lobbies (and in custom games);
+// such colors are only available as the RGB components of the Details players.
+func NewCustomColor(rgb [3]byte) *Color {
+	c := &Color{Enum: Enum{"Custom"}, RGB: rgb, custom: true}
+	c.initShades()
+	return c
+}
+
+// Custom tells if the color is a custom color, not part of Colors.
+func (c *Color) Custom() bool {
+	return c.custom
+}
+
+// Hex returns the color in hex notation, e.g. "#b4141e".
+func (c *Color) Hex() string {
+	return fmt.Sprintf("#%02x%02x%02x", c.RGB[0], c.RGB[1], c.RGB[2])
+}
+
+// CSS returns the color in CSS functional notation, e.g. "rgb(180, 20, 30)".
+func (c *Color) CSS() string {
+	return fmt.Sprintf("rgb(%d, %d, %d)", c.RGB[0], c.RGB[1], c.RGB[2])
+}
+
+// minContrastOnWhite is the min contrast ratio of OnWhite(), the WCAG AA level for normal text.
+const minContrastOnWhite = 4.5
+
+// OnWhite returns a variant of the color's RGB values which has a contrast ratio of at least 4.5:1
+// on white background, e.g. to render player names on white.
+// The color is darkened as little as needed; it is returned as-is if it already has enough contrast.
+func (c *Color) OnWhite() [3]byte {
+	rgb := c.RGB
+	for f := 1.0; f > 0 && contrastOnWhite(rgb) < minContrastOnWhite; f -= 0.02 {
+		for i, v := range c.RGB {
+			rgb[i] = byte(float64(v) * f)
+		}
+	}
+	return rgb
+}
+
+// contrastOnWhite returns the contrast ratio of the given color on white background
+// as defined by WCAG 2.
+func contrastOnWhite(rgb [3]byte) float64 {
+	var lum float64
+	for i, w := range [3]float64{0.2126, 0.7152, 0.0722} {
+		v := float64(rgb[i]) / 255
+		if v <= 0.03928 {
+			v /= 12.92
+		} else {
+			v = math.Pow((v+0.055)/1.055, 2.4)
+		}
+		lum += w * v
 	}
+	return 1.05 / (lum + 0.05)
 }
 
 // Named colors.
@@ -336,6 +494,17 @@ func colorByID(colorID int64) *Color {
 	return ColorUnknown
 }
 
+// colorByAttrValue returns the Color specified by its attribute value.
+// ColorUnknown is returned if attribute value is unknown.
+func colorByAttrValue(attrValue string) *Color {
+	for _, c := range Colors[1:] {
+		if c.attrValue == attrValue {
+			return c
+		}
+	}
+	return ColorUnknown
+}
+
 // League type.
 type League struct {
 	Enum
@@ -453,29 +622,32 @@ type Region struct {
 }
 
 // Regions is the slice of all regions, index used in Details["playerList"]["toon"]["region"]
+//
+// Regions may be registered or overridden with RegisterRegion.
 var Regions = []*Region{
 	{Enum{"Unknown"}, "", mustPU("http://unknown.depot.battle.net:1119/"), mustPU("http://unknown.battle.net/"),
 		[]*Realm{},
 		[]*BnetLang{BnetLangEnglish}},
-	{Enum{"US"}, "US", mustPU("https://us-s2-depot.classic.blizzard.com/"), mustPU("http://us.battle.net/"),
+	{Enum{"US"}, "US", mustPU("https://us-s2-depot.classic.blizzard.com/"), mustPU("https://us.battle.net/"),
 		[]*Realm{RealmNorthAmerica, RealmLatinAmerica},
 		[]*BnetLang{BnetLangEnglish, BnetLangSpanish, BnetLangPortuguese}},
-	{Enum{"Europe"}, "EU", mustPU("https://eu-s2-depot.classic.blizzard.com/"), mustPU("http://eu.battle.net/"),
+	{Enum{"Europe"}, "EU", mustPU("https://eu-s2-depot.classic.blizzard.com/"), mustPU("https://eu.battle.net/"),
 		[]*Realm{RealmEurope, RealmRussia},
 		[]*BnetLang{BnetLangEnglish, BnetLangGerman, BnetLangFrench, BnetLangSpanish, BnetLangRussian, BnetLangItalian, BnetLangPolish}},
-	{Enum{"Korea"}, "KR", mustPU("https://kr-s2-depot.classic.blizzard.com/"), mustPU("http://kr.battle.net/"),
+	{Enum{"Korea"}, "KR", mustPU("https://kr-s2-depot.classic.blizzard.com/"), mustPU("https://kr.battle.net/"),
 		[]*Realm{RealmKorea, RealmTaiwan},
 		[]*BnetLang{BnetLangKorean, BnetLangChineseTraditional}},
-	{Enum{"SEA"}, "SG", mustPU("http://sg.depot.battle.net:1119/"), mustPU("http://sea.battle.net/"),
+	// SEA was merged into US, its depot and website are the ones of US:
+	{Enum{"SEA"}, "SG", mustPU("https://us-s2-depot.classic.blizzard.com/"), mustPU("https://us.battle.net/"),
 		[]*Realm{RealmSEA},
 		[]*BnetLang{BnetLangEnglish}},
-	{Enum{"China"}, "CN", mustPU("http://cn-s2-depot.battlenet.com.cn/"), mustPU("http://www.battlenet.com.cn/"),
+	{Enum{"China"}, "CN", mustPU("http://cn-s2-depot.battlenet.com.cn/"), mustPU("https://www.battlenet.com.cn/"),
 		[]*Realm{RealmChina},
 		[]*BnetLang{BnetLangChineseTraditional}},
-	{Enum{"Public Test"}, "XX", mustPU("http://xx.depot.battle.net:1119/"), mustPU("http://us.battle.net/"),
+	{Enum{"Public Test"}, "XX", mustPU("http://xx.depot.battle.net:1119/"), mustPU("https://us.battle.net/"),
 		[]*Realm{},
 		[]*BnetLang{BnetLangEnglish}},
-	{Enum{"Public Test"}, "XX", mustPU("http://xx.depot.battle.net:1119/"), mustPU("http://us.battle.net/"),
+	{Enum{"Public Test"}, "XX", mustPU("http://xx.depot.battle.net:1119/"), mustPU("https://us.battle.net/"),
 		[]*Realm{},
 		[]*BnetLang{BnetLangEnglish}},
 }
@@ -491,12 +663,30 @@ func mustPU(rawurl string) *url.URL {
 
 // Realm returns the realm of the region specified by its code.
 func (r *Region) Realm(realmID int64) *Realm {
+	regionsMu.RLock()
+	defer regionsMu.RUnlock()
 	if id := int(realmID) - 1; id >= 0 && id < len(r.Realms) {
 		return r.Realms[id]
 	}
 	return RealmUnknown
 }
 
+// DepotFileURL returns the URL of the named file in the depot of the region.
+// The scheme and host (and optional base path) are the ones of the region's DepotURL,
+// which may be configured by modifying the region or registering a new one with RegisterRegion.
+// nil is returned if the region has no depot.
+func (r *Region) DepotFileURL(name string) *url.URL {
+	regionsMu.RLock()
+	defer regionsMu.RUnlock()
+	if r.DepotURL == nil {
+		return nil
+	}
+	u := *r.DepotURL
+	u.Path = strings.TrimSuffix(u.Path, "/") + "/" + name
+	u.RawPath = ""
+	return &u
+}
+
 // Named regions.
 var (
 	RegionUnknown     = Regions[0]
@@ -512,6 +702,9 @@ var (
 // Map of regions, mapped from the 2-letter region code.
 var regionMap = make(map[string]*Region)
 
+// regionsMu protects Regions, regionMap and the realms of regions during registration.
+var regionsMu sync.RWMutex
+
 func init() {
 	// Build the regionMap map
 	for _, r := range Regions {
@@ -522,6 +715,8 @@ func init() {
 // regionByCode returns the Region specified by its 2-letter code.
 // RegionUnknown is returned if code is unknown.
 func regionByCode(code string) *Region {
+	regionsMu.RLock()
+	defer regionsMu.RUnlock()
 	if r, ok := regionMap[code]; ok {
 		return r
 	}
@@ -531,12 +726,72 @@ func regionByCode(code string) *Region {
 // regionByID returns the Region specified by its ID.
 // RegionUnknown is returned if ID is unknown.
 func regionByID(regionID int64) *Region {
+	regionsMu.RLock()
+	defer regionsMu.RUnlock()
 	if id := int(regionID); id >= 0 && id < len(Regions) {
 		return Regions[id]
 	}
 	return RegionUnknown
 }
 
+// RegionByCode returns the Region specified by its 2-letter code.
+// RegionUnknown is returned if code is unknown.
+func RegionByCode(code string) *Region {
+	return regionByCode(code)
+}
+
+// RegionByID returns the Region specified by its ID.
+// RegionUnknown is returned if ID is unknown.
+func RegionByID(regionID int64) *Region {
+	return regionByID(regionID)
+}
+
+// RegisterRegion registers a region with the specified ID (index in Regions), overriding the one registered
+// with the same ID. The region is also registered for its code, overriding the one registered with the same code.
+// Gaps created by registering IDs beyond the current ones are filled with RegionUnknown.
+//
+// Named region variables (e.g. RegionUS) are not changed by overrides; to change the data of a predefined region
+// (e.g. its DepotURL), modifying its fields is also an option.
+//
+// Registration should be done before processing replays, but it is safe for concurrent use with region lookups.
+func RegisterRegion(regionID int64, r *Region) {
+	if regionID < 0 {
+		return
+	}
+	regionsMu.Lock()
+	defer regionsMu.Unlock()
+	for int64(len(Regions)) <= regionID {
+		Regions = append(Regions, RegionUnknown)
+	}
+	Regions[regionID] = r
+	regionMap[r.Code] = r
+}
+
+// RegisterRealm registers a realm for the region with the specified realm ID (1-based),
+// overriding the one registered with the same ID. Gaps are filled with RealmUnknown.
+// The realm is also added to Realms if not yet listed.
+//
+// It is safe for concurrent use with region lookups, but realms of a region (Region.Realms) must not be accessed
+// directly concurrently.
+func RegisterRealm(r *Region, realmID int64, realm *Realm) {
+	if realmID < 1 {
+		return
+	}
+	regionsMu.Lock()
+	defer regionsMu.Unlock()
+	for int64(len(r.Realms)) < realmID {
+		r.Realms = append(r.Realms, RealmUnknown)
+	}
+	r.Realms[realmID-1] = realm
+
+	for _, rlm := range Realms {
+		if rlm == realm {
+			return
+		}
+	}
+	Realms = append(Realms, realm)
+}
+
 // ExpLevel is the type of Expansion level.
 type ExpLevel struct {
 	Enum
@@ -560,6 +815,182 @@ var (
 	ExpLevelUnknown = ExpLevels[3]
 )
 
+// expLevelOf returns the highest expansion level whose cache handle is present in the specified cache handles.
+// ExpLevelUnknown is returned if none of the cache handles identifies an expansion level.
+func expLevelOf(chs []*CacheHandle) *ExpLevel {
+	for _, exp := range ExpLevels[:len(ExpLevels)-1] {
+		for _, ch := range chs {
+			if exp.Digest == ch.Digest {
+				return exp
+			}
+		}
+	}
+	return ExpLevelUnknown
+}
+
+// Ranking is the type of the game's ranking classification.
+type Ranking struct {
+	Enum
+}
+
+// Rankings is the slice of all rankings.
+var Rankings = []*Ranking{
+	{Enum{"Ranked Ladder"}},
+	{Enum{"Unranked Ladder"}},
+	{Enum{"Custom Melee"}},
+	{Enum{"Co-op"}},
+	{Enum{"Arcade"}},
+	{Enum{"Campaign"}},
+	{Enum{"Unknown"}},
+}
+
+// Named rankings.
+var (
+	RankingRankedLadder   = Rankings[0]
+	RankingUnrankedLadder = Rankings[1]
+	RankingCustomMelee    = Rankings[2]
+	RankingCoop           = Rankings[3]
+	RankingArcade         = Rankings[4]
+	RankingCampaign       = Rankings[5]
+	RankingUnknown        = Rankings[6]
+)
+
+// FindingKind is the type of early game findings (proxies and cheeses).
+type FindingKind struct {
+	Enum
+}
+
+// FindingKinds is the slice of all finding kinds.
+var FindingKinds = []*FindingKind{
+	{Enum{"Proxy"}},
+	{Enum{"Early Pool"}},
+	{Enum{"Early Rax"}},
+	{Enum{"Early Gate"}},
+}
+
+// Named finding kinds.
+var (
+	FindingProxy     = FindingKinds[0]
+	FindingEarlyPool = FindingKinds[1]
+	FindingEarlyRax  = FindingKinds[2]
+	FindingEarlyGate = FindingKinds[3]
+)
+
+// GamePhase is the type of the game phases.
+type GamePhase struct {
+	Enum
+}
+
+// GamePhases is the slice of all game phases, in chronological order.
+var GamePhases = []*GamePhase{
+	{Enum{"Early Game"}},
+	{Enum{"Mid Game"}},
+	{Enum{"Late Game"}},
+}
+
+// Named game phases.
+var (
+	GamePhaseEarly = GamePhases[0]
+	GamePhaseMid   = GamePhases[1]
+	GamePhaseLate  = GamePhases[2]
+)
+
+// LeaveReason is the type of the reasons of players leaving the game.
+type LeaveReason struct {
+	Enum
+}
+
+// LeaveReasons is the slice of all leave reasons.
+var LeaveReasons = []*LeaveReason{
+	{Enum{"Quit"}},
+	{Enum{"Unknown"}},
+}
+
+// Named leave reasons.
+var (
+	LeaveReasonQuit    = LeaveReasons[0]
+	LeaveReasonUnknown = LeaveReasons[1]
+)
+
+// GameUserLeaveReason is the type of the leave reasons recorded in GameUserLeave game events (the leaveReason field).
+type GameUserLeaveReason struct {
+	Enum
+	Reason *LeaveReason // Leave reason the recorded value denotes
+}
+
+// GameUserLeaveReasons is the slice of all game user leave reasons.
+var GameUserLeaveReasons = []*GameUserLeaveReason{
+	{Enum{"User Left"}, LeaveReasonQuit},
+	{Enum{"Unknown"}, LeaveReasonUnknown},
+}
+
+// Named game user leave reasons.
+var (
+	GameUserLeaveReasonUserLeft = GameUserLeaveReasons[0]
+	GameUserLeaveReasonUnknown  = GameUserLeaveReasons[1]
+)
+
+// DependencyKind is the type of the kinds of dependencies (cache handles) of replays.
+type DependencyKind struct {
+	Enum
+}
+
+// DependencyKinds is the slice of all dependency kinds.
+var DependencyKinds = []*DependencyKind{
+	{Enum{"Standard"}},
+	{Enum{"Mod"}},
+	{Enum{"Map"}},
+}
+
+// Named dependency kinds.
+var (
+	DependencyKindStandard = DependencyKinds[0] // Standard (known Blizzard) data, see CacheHandle.IsStandard
+	DependencyKindMod      = DependencyKinds[1] // Mod that is not a known standard data (e.g. an extension mod)
+	DependencyKindMap      = DependencyKinds[2] // The map
+)
+
+// FileKind is the type of the kinds of files detected by Sniff.
+type FileKind struct {
+	Enum
+}
+
+// FileKinds is the slice of all file kinds.
+var FileKinds = []*FileKind{
+	{Enum{"StarCraft II replay"}},
+	{Enum{"Other replay"}},
+	{Enum{"MPQ archive"}},
+	{Enum{"Unknown"}},
+}
+
+// Named file kinds.
+var (
+	FileKindReplay      = FileKinds[0] // StarCraft II replay
+	FileKindOtherReplay = FileKinds[1] // Replay of another game using the s2protocol framework, e.g. Heroes of the Storm
+	FileKindMPQ         = FileKinds[2] // MPQ archive that is not a replay, e.g. a map
+	FileKindUnknown     = FileKinds[3] // Not an MPQ archive
+)
+
+// ValueSource is the type of the sources of reconciled values, see Rep.PlayerSettings.
+type ValueSource struct {
+	Enum
+}
+
+// ValueSources is the slice of all value sources.
+var ValueSources = []*ValueSource{
+	{Enum{"Init Data"}},
+	{Enum{"Details"}},
+	{Enum{"Attributes"}},
+	{Enum{"None"}},
+}
+
+// Named value sources.
+var (
+	ValueSourceInitData = ValueSources[0] // Lobby slot of the init data
+	ValueSourceDetails  = ValueSources[1] // Player of the game details
+	ValueSourceAttrEvts = ValueSources[2] // Attributes events
+	ValueSourceNone     = ValueSources[3] // Value is not available
+)
+
 // CacheHandle is the identifier of a remote resource. A cache hande is a dependency.
 type CacheHandle struct {
 	Type   string  // Type of the resource, file extension.
@@ -594,11 +1025,55 @@ func (c *CacheHandle) RelativeFile() string {
 	return path.Join(c.Digest[0:2], c.Digest[2:4], c.FileName())
 }
 
+// DownloadURL returns the URL the resource denoted by the cache handle can be downloaded from:
+// the file name in the depot of its region (see Region.DepotFileURL).
+// nil is returned if the region of the cache handle is unknown.
+func (c *CacheHandle) DownloadURL() *url.URL {
+	if c.Region == nil || c.Region == RegionUnknown {
+		return nil
+	}
+	return c.Region.DepotFileURL(c.FileName())
+}
+
 // StandardData returns the content of the resource denoted by the cache handle if this is a standard data.
 func (c *CacheHandle) StandardData() string {
-	return standardCHData[c.Digest]
+	return StandardData(c.Digest)
+}
+
+// IsStandard tells if the resource denoted by the cache handle is a standard data (a known Blizzard resource).
+func (c *CacheHandle) IsStandard() bool {
+	return IsStandardDigest(c.Digest)
+}
+
+// StandardData returns the content of the standard resource denoted by the digest,
+// empty string if the digest does not denote a known standard data.
+func StandardData(digest string) string {
+	standardCHDataMu.RLock()
+	defer standardCHDataMu.RUnlock()
+	return standardCHData[digest]
+}
+
+// IsStandardDigest tells if the digest denotes a known standard data.
+func IsStandardDigest(digest string) bool {
+	return StandardData(digest) != ""
+}
+
+// RegisterStandardData registers a standard data: the content of the resource denoted by the digest,
+// e.g. "Standard Data: Teams17.SC2Mod". Registering a digest already known overrides its content.
+// The digest must be the lowercase hexadecimal representation (as in CacheHandle.Digest), data must not be empty.
+// It is safe for concurrent use.
+func RegisterStandardData(digest, data string) {
+	if data == "" {
+		return
+	}
+	standardCHDataMu.Lock()
+	defer standardCHDataMu.Unlock()
+	standardCHData[strings.ToLower(digest)] = data
 }
 
+// standardCHDataMu protects standardCHData.
+var standardCHDataMu sync.RWMutex
+
 // Standard Cache Handle data. Maps from digest to the content of the denoted resource.
 var standardCHData = map[string]string{
 	"6de41503baccd05656360b6f027db88169fa1989bb6357b1b215a2547939f5fb": "Standard Data: Core.SC2Mod",
@@ -638,23 +1113,15 @@ var standardCHData = map[string]string{
 	"d50705d5859b6c52aead440f2a0bcedbfd811f06b259cc1733e5cefdf38aed82": "Standard Data: Teams16.SC2Mod",
 }
 
-// Game event ids
-const (
-	GmEIdPlayerLeave     = 25  // PlayerLeave game event id [ONLY UP TO BASEBUILD 23260; REPLACED BY USERLEAVE]
-	GmEIdCmd             = 27  // CmdEvent game event id
-	GmEIdSelDelta        = 28  // SelectionDelta game event id
-	GmEIdCtrlGroupUpdate = 29  // ControlGroupUpdate game event id
-	GmEIdCamUpdate       = 49  // CameraUpdate game event id
-	GmEIdUsrLeave        = 101 // UserLeave game event id [ONLY FROM BASEBUILD 24764; REPLACES PLAYERLEAVE]
-)
-
-// Message event ids
-const (
-	MsgEIdChat = 0 // ChatMessage message event id
-	MsgEIdPing = 1 // PingMessage message event id
-)
-
-// Tracker event ids
+// Abbreviated names of generated event id constants, kept for compatibility.
+// Event name and id constants are generated, see evtnames_gen.go.
 const (
-	TrEIdPlayerSetup = 9 // PlayerSetup tracker event id [ONLY FROM BASEBUILD 27950]
+	// Deprecated: Use GmEIdSelectionDelta.
+	GmEIdSelDelta = GmEIdSelectionDelta
+	// Deprecated: Use GmEIdControlGroupUpdate.
+	GmEIdCtrlGroupUpdate = GmEIdControlGroupUpdate
+	// Deprecated: Use GmEIdCameraUpdate.
+	GmEIdCamUpdate = GmEIdCameraUpdate
+	// Deprecated: Use GmEIdGameUserLeave.
+	GmEIdUsrLeave = GmEIdGameUserLeave
 )
@@ -0,0 +1,157 @@
+package rep
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRegionRegistry(t *testing.T) {
+	defer func(regions []*Region, realms []*Realm) {
+		Regions, Realms = regions, realms
+		regionMap = map[string]*Region{}
+		for _, r := range Regions {
+			regionMap[r.Code] = r
+		}
+	}(append([]*Region(nil), Regions...), append([]*Realm(nil), Realms...))
+
+	// Defaults:
+	if r := RegionByCode("SG"); r != RegionSEA || r.DepotURL.String() != RegionUS.DepotURL.String() {
+		t.Errorf("Unexpected SEA region: %v %v", r, r.DepotURL)
+	}
+	if r := RegionByID(2); r != RegionEU {
+		t.Errorf("Expected: %v, got: %v", RegionEU, r)
+	}
+	if r := RegionByID(99); r != RegionUnknown {
+		t.Errorf("Expected: %v, got: %v", RegionUnknown, r)
+	}
+
+	realm := &Realm{Enum{"Test Realm"}}
+	test := &Region{Enum: Enum{"Test"}, Code: "TT", DepotURL: mustPU("https://tt-s2-depot.example.com/")}
+	RegisterRegion(10, test)
+	RegisterRealm(test, 2, realm)
+
+	if r := RegionByID(10); r != test {
+		t.Errorf("Expected: %v, got: %v", test, r)
+	}
+	if r := RegionByID(9); r != RegionUnknown {
+		t.Errorf("Expected: %v, got: %v", RegionUnknown, r)
+	}
+	if r := RegionByCode("TT"); r != test {
+		t.Errorf("Expected: %v, got: %v", test, r)
+	}
+	if rlm := test.Realm(2); rlm != realm {
+		t.Errorf("Expected: %v, got: %v", realm, rlm)
+	}
+	if rlm := test.Realm(1); rlm != RealmUnknown {
+		t.Errorf("Expected: %v, got: %v", RealmUnknown, rlm)
+	}
+	if Realms[len(Realms)-1] != realm {
+		t.Errorf("Expected realm to be listed in Realms!")
+	}
+	if ch := newCacheHandle("s2ma\x00\x00TT" + string(make([]byte, 32))); ch.Region != test {
+		t.Errorf("Expected: %v, got: %v", test, ch.Region)
+	}
+
+	// Override:
+	us := &Region{Enum: Enum{"US"}, Code: "US"}
+	RegisterRegion(1, us)
+	if RegionByID(1) != us || RegionByCode("US") != us {
+		t.Errorf("Expected overridden region!")
+	}
+}
+
+func TestCacheHandleDownloadURL(t *testing.T) {
+	digest := "421c8aa0f3619e57ef53f31e9c3a5d8e1a5b4c5d6e7f8091a2b3c4d5e6f70819"
+	cases := []struct {
+		region *Region
+		exp    string
+	}{
+		{RegionEU, "https://eu-s2-depot.classic.blizzard.com/" + digest + ".s2ma"},
+		{RegionSEA, "https://us-s2-depot.classic.blizzard.com/" + digest + ".s2ma"},
+		{&Region{DepotURL: mustPU("http://cdn.example.com/depot")}, "http://cdn.example.com/depot/" + digest + ".s2ma"},
+		{&Region{}, ""},
+		{RegionUnknown, ""},
+		{nil, ""},
+	}
+	for _, c := range cases {
+		ch := &CacheHandle{Type: "s2ma", Region: c.region, Digest: digest}
+		got := ""
+		if u := ch.DownloadURL(); u != nil {
+			got = u.String()
+		}
+		if got != c.exp {
+			t.Errorf("[%v] Expected: %q, got: %q", c.region, c.exp, got)
+		}
+	}
+}
+
+func TestStandardData(t *testing.T) {
+	core := "6de41503baccd05656360b6f027db88169fa1989bb6357b1b215a2547939f5fb"
+	custom := "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+	defer func() {
+		standardCHDataMu.Lock()
+		delete(standardCHData, custom)
+		standardCHDataMu.Unlock()
+	}()
+
+	if ch := (&CacheHandle{Digest: core}); !ch.IsStandard() || ch.StandardData() != "Standard Data: Core.SC2Mod" {
+		t.Errorf("Expected standard data, got: %q", ch.StandardData())
+	}
+	ch := &CacheHandle{Digest: custom}
+	if ch.IsStandard() || ch.StandardData() != "" {
+		t.Errorf("Expected non-standard data, got: %q", ch.StandardData())
+	}
+
+	RegisterStandardData(strings.ToUpper(custom), "Standard Data: Custom.SC2Mod")
+	RegisterStandardData(core, "") // Ignored
+	if !ch.IsStandard() || ch.StandardData() != "Standard Data: Custom.SC2Mod" {
+		t.Errorf("Expected registered standard data, got: %q", ch.StandardData())
+	}
+	if !IsStandardDigest(core) {
+		t.Errorf("Expected standard data!")
+	}
+}
+
+func TestColor(t *testing.T) {
+	if s := ColorRed.Hex(); s != "#b4141e" {
+		t.Errorf("Expected: %s, got: %s", "#b4141e", s)
+	}
+	if s := ColorRed.CSS(); s != "rgb(180, 20, 30)" {
+		t.Errorf("Expected: %s, got: %s", "rgb(180, 20, 30)", s)
+	}
+	if ColorRed.Custom() {
+		t.Error("Expected palette color!")
+	}
+
+	// Dark colors have enough contrast, light ones must be darkened:
+	if rgb := ColorBlue.OnWhite(); rgb != ColorBlue.RGB {
+		t.Errorf("Expected: %v, got: %v", ColorBlue.RGB, rgb)
+	}
+	for _, c := range append(Colors[1:], NewCustomColor([3]byte{255, 255, 255})) {
+		if rgb := c.OnWhite(); contrastOnWhite(rgb) < minContrastOnWhite {
+			t.Errorf("[%s] Insufficient contrast: %v", c, rgb)
+		}
+	}
+	if rgb := ColorYellow.OnWhite(); rgb == ColorYellow.RGB {
+		t.Errorf("Expected darkened color, got: %v", rgb)
+	}
+}
+
+func TestColorByAttrValue(t *testing.T) {
+	cases := []struct {
+		attrValue string
+		exp       *Color
+	}{
+		{"tc01", ColorRed},
+		{"tc02", ColorBlue},
+		{"tc15", ColorPink},
+		{"tc00", ColorUnknown},
+		{"tc16", ColorUnknown},
+		{"", ColorUnknown},
+	}
+	for _, c := range cases {
+		if got := colorByAttrValue(c.attrValue); got != c.exp {
+			t.Errorf("[%q] Expected: %v, got: %v", c.attrValue, c.exp, got)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+/*
+
+Unit tracker: reconstructing the units of the game from tracker events.
+
+*/
+
+package rep
+
+// Unit is a unit (including structures) reconstructed from tracker events.
+type Unit struct {
+	Tag  int64 // Unit tag, composed from the unit tag index and recycle
+	Born int64 // Loop when the unit was born or its construction / warp-in started
+	Done int64 // Loop when the unit was completed, -1 if it was never completed
+	Died int64 // Loop when the unit died, -1 if it was alive at the end of the game
+
+	KillerPlayerID int64 // ID of the player who killed the unit, -1 if unknown (or the unit did not die)
+	KillerTag      int64 // Tag of the unit that killed the unit, -1 if unknown (or the unit did not die)
+
+	types  []unitTypeChange  // Type history, the first is the initial type
+	owners []unitOwnerChange // Control player history, the first is the initial owner
+}
+
+// unitTypeChange is an entry of a unit's type history.
+type unitTypeChange struct {
+	loop     int64
+	typeName string
+}
+
+// unitOwnerChange is an entry of a unit's control player history.
+type unitOwnerChange struct {
+	loop     int64
+	playerID int64
+}
+
+// Type returns the (last) unit type name of the unit.
+func (u *Unit) Type() string {
+	return u.types[len(u.types)-1].typeName
+}
+
+// TypeAt returns the unit type name of the unit at the given loop.
+func (u *Unit) TypeAt(loop int64) string {
+	name := u.types[0].typeName
+	for _, tc := range u.types[1:] {
+		if tc.loop > loop {
+			break
+		}
+		name = tc.typeName
+	}
+	return name
+}
+
+// OwnerAt returns the ID of the control player of the unit at the given loop.
+// Player ID is the index in Details.Players() plus 1; 0 denotes neutral.
+func (u *Unit) OwnerAt(loop int64) int64 {
+	pid := u.owners[0].playerID
+	for _, oc := range u.owners[1:] {
+		if oc.loop > loop {
+			break
+		}
+		pid = oc.playerID
+	}
+	return pid
+}
+
+// AliveAt tells if the unit was completed and alive at the given loop.
+func (u *Unit) AliveAt(loop int64) bool {
+	return u.Done >= 0 && u.Done <= loop && (u.Died < 0 || loop < u.Died)
+}
+
+// Units returns the units of the game reconstructed from the tracker events, in the order of their birth.
+// The units are built once, on the first call; the returned slice must not be modified.
+// nil is returned if tracker events were not decoded.
+func (r *Rep) Units() []*Unit {
+	if r.units == nil && r.TrackerEvts != nil {
+		units := []*Unit{}
+		tagUnits := map[int64]*Unit{}
+		for _, e := range r.TrackerEvts.Evts {
+			switch e.Name {
+			case "UnitBorn", "UnitInit":
+				u := &Unit{Tag: evtUnitTag(e), Born: e.Loop(), Done: -1, Died: -1, KillerPlayerID: -1, KillerTag: -1,
+					types:  []unitTypeChange{{e.Loop(), e.Stringv("unitTypeName")}},
+					owners: []unitOwnerChange{{e.Loop(), e.Int("controlPlayerId")}},
+				}
+				if e.Name == "UnitBorn" {
+					u.Done = e.Loop()
+				}
+				units = append(units, u)
+				tagUnits[u.Tag] = u
+			case "UnitDone":
+				if u := tagUnits[evtUnitTag(e)]; u != nil {
+					u.Done = e.Loop()
+				}
+			case "UnitTypeChange":
+				if u := tagUnits[evtUnitTag(e)]; u != nil {
+					u.types = append(u.types, unitTypeChange{e.Loop(), e.Stringv("unitTypeName")})
+				}
+			case "UnitOwnerChange":
+				if u := tagUnits[evtUnitTag(e)]; u != nil {
+					u.owners = append(u.owners, unitOwnerChange{e.Loop(), e.Int("controlPlayerId")})
+				}
+			case "UnitDied":
+				if u := tagUnits[evtUnitTag(e)]; u != nil {
+					u.Died = e.Loop()
+					if pid, ok := e.LookupInt("killerPlayerId"); ok {
+						u.KillerPlayerID = pid
+					}
+					if idx, ok := e.LookupInt("killerUnitTagIndex"); ok {
+						u.KillerTag = unitTag(idx, e.Int("killerUnitTagRecycle"))
+					}
+					delete(tagUnits, u.Tag)
+				}
+			}
+		}
+		r.units = units
+	}
+	return r.units
+}
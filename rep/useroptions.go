@@ -0,0 +1,141 @@
+/*
+
+Per-user options and settings reported by the game events.
+
+*/
+
+package rep
+
+import "github.com/icza/s2prot"
+
+// UserOptions describes the options of a user, reported by the UserOptions game event at the start of the game.
+// Some options are only present in newer builds, their accessors return the zero value if missing.
+type UserOptions struct {
+	s2prot.Struct // The UserOptions game event
+
+	// Synced tells if the client of the user synced with the game at start (sent a TriggerSoundLengthSync event).
+	Synced bool
+}
+
+// GameFullyDownloaded tells if the game was fully downloaded.
+func (u *UserOptions) GameFullyDownloaded() bool {
+	return u.Bool("gameFullyDownloaded")
+}
+
+// DevelopmentCheatsEnabled tells if development cheats were enabled.
+func (u *UserOptions) DevelopmentCheatsEnabled() bool {
+	return u.Bool("developmentCheatsEnabled")
+}
+
+// TestCheatsEnabled tells if test cheats were enabled.
+func (u *UserOptions) TestCheatsEnabled() bool {
+	return u.Bool("testCheatsEnabled")
+}
+
+// MultiplayerCheatsEnabled tells if multiplayer cheats were enabled.
+func (u *UserOptions) MultiplayerCheatsEnabled() bool {
+	return u.Bool("multiplayerCheatsEnabled")
+}
+
+// SyncChecksummingEnabled tells if sync checksumming was enabled.
+func (u *UserOptions) SyncChecksummingEnabled() bool {
+	return u.Bool("syncChecksummingEnabled")
+}
+
+// IsMapToMapTransition tells if the game was started by a map to map transition (e.g. in campaigns).
+func (u *UserOptions) IsMapToMapTransition() bool {
+	return u.Bool("isMapToMapTransition")
+}
+
+// DebugPauseEnabled tells if debug pause was enabled.
+func (u *UserOptions) DebugPauseEnabled() bool {
+	return u.Bool("debugPauseEnabled")
+}
+
+// UseGalaxyAsserts tells if Galaxy (script) asserts were used.
+func (u *UserOptions) UseGalaxyAsserts() bool {
+	return u.Bool("useGalaxyAsserts")
+}
+
+// PlatformMac tells if the user played on Mac.
+func (u *UserOptions) PlatformMac() bool {
+	return u.Bool("platformMac")
+}
+
+// CameraFollow tells if camera follow was enabled.
+func (u *UserOptions) CameraFollow() bool {
+	return u.Bool("cameraFollow")
+}
+
+// BaseBuildNum returns the base build number of the user's game client.
+func (u *UserOptions) BaseBuildNum() int64 {
+	return u.Int("baseBuildNum")
+}
+
+// BuildNum returns the build number of the user's game client.
+func (u *UserOptions) BuildNum() int64 {
+	return u.Int("buildNum")
+}
+
+// VersionFlags returns the version flags of the user's game client.
+func (u *UserOptions) VersionFlags() int64 {
+	return u.Int("versionFlags")
+}
+
+// HotkeyProfile returns the name of the hotkey profile of the user, e.g. "0_Default".
+func (u *UserOptions) HotkeyProfile() string {
+	return u.Stringv("hotkeyProfile")
+}
+
+// TestMode tells if any debug / test options were enabled, that is if the replay was recorded in a test mode:
+// development, test or multiplayer cheats, debug pause or Galaxy asserts.
+func (u *UserOptions) TestMode() bool {
+	return u.DevelopmentCheatsEnabled() || u.TestCheatsEnabled() || u.MultiplayerCheatsEnabled() ||
+		u.DebugPauseEnabled() || u.UseGalaxyAsserts()
+}
+
+// UserOptions returns the options of users mapped from user ID, collected from the game events.
+// Users who did not send a UserOptions event are not included.
+// nil is returned if game events were not decoded.
+func (r *Rep) UserOptions() map[int64]*UserOptions {
+	if r.GameEvts == nil {
+		return nil
+	}
+	if r.userOptions != nil {
+		return r.userOptions
+	}
+
+	m := map[int64]*UserOptions{}
+	synced := map[int64]bool{}
+	for _, e := range r.GameEvts {
+		userID, ok := evtUserID(e)
+		if !ok {
+			continue
+		}
+		switch e.Name {
+		case "UserOptions":
+			if m[userID] == nil {
+				m[userID] = &UserOptions{Struct: e.Struct}
+			}
+		case "TriggerSoundLengthSync":
+			synced[userID] = true
+		}
+	}
+	for userID, uo := range m {
+		uo.Synced = synced[userID]
+	}
+	r.userOptions = m
+
+	return m
+}
+
+// PlayerUserOptions returns the options of the player specified by its index in Details.Players().
+// nil is returned if game events were not decoded, the player is not a human player or has no options.
+func (r *Rep) PlayerUserOptions(playerIdx int) *UserOptions {
+	for userID, idx := range r.userPlayerIdxs() {
+		if idx == playerIdx {
+			return r.UserOptions()[userID]
+		}
+	}
+	return nil
+}
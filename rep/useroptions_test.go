@@ -0,0 +1,41 @@
+package rep
+
+import (
+	"testing"
+
+	"github.com/icza/s2prot"
+)
+
+func TestUserOptions(t *testing.T) {
+	r := newTestRep(humanSlot(1), humanSlot(0))
+
+	if r.UserOptions() != nil {
+		t.Error("Expected nil user options without game events!")
+	}
+
+	r.GameEvts = []s2prot.Event{
+		gameEvt(0, 0, "UserOptions", s2prot.Struct{"buildNum": int64(80949), "hotkeyProfile": "0_Default", "cameraFollow": true}),
+		gameEvt(0, 1, "UserOptions", s2prot.Struct{"buildNum": int64(80949), "testCheatsEnabled": true}),
+		gameEvt(0, 0, "TriggerSoundLengthSync", nil),
+		gameEvt(0, 0, "UserOptions", s2prot.Struct{"buildNum": int64(1)}), // Only the first one counts
+	}
+
+	m := r.UserOptions()
+	if len(m) != 2 {
+		t.Fatalf("Expected %d user options, got: %v", 2, m)
+	}
+	uo := m[0]
+	if uo.BuildNum() != 80949 || uo.HotkeyProfile() != "0_Default" || !uo.CameraFollow() || !uo.Synced || uo.TestMode() {
+		t.Errorf("Unexpected user options: %v (%v)", uo, uo.Synced)
+	}
+	if uo = m[1]; !uo.TestMode() || uo.Synced {
+		t.Errorf("Unexpected user options: %v (%v)", uo, uo.Synced)
+	}
+
+	if uo := r.PlayerUserOptions(1); uo != m[0] {
+		t.Errorf("Expected: %v, got: %v", m[0], uo)
+	}
+	if uo := r.PlayerUserOptions(2); uo != nil {
+		t.Errorf("Expected nil, got: %v", uo)
+	}
+}
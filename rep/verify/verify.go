@@ -0,0 +1,227 @@
+/*
+Package verify verifies that the replays of a corpus decode cleanly with the protocols of their builds.
+
+All sections of the replays are decoded in strict validation mode (see s2prot.Protocol.WithStrict),
+so unknown choice tags, unknown struct fields (which are otherwise silently skipped) and trailing bytes
+are reported as failures. The results are summarized per base build in a coverage report,
+so regressions in protocol parsing surface before users run into decoding errors.
+
+Example:
+
+	report := verify.Corpus([]string{"replays"}, 0)
+	for _, b := range report.Builds {
+		fmt.Printf("Base build %d: %d/%d clean\n", b.BaseBuild, b.Clean, b.Replays)
+	}
+	if !report.OK() {
+		// Handle failures, see report.Results and report.Errs
+	}
+*/
+package verify
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/icza/mpq"
+	"github.com/icza/s2prot"
+	"github.com/icza/s2prot/rep"
+	"github.com/icza/s2prot/rep/corpus"
+)
+
+// Section names used in SectionChecks.
+const (
+	SectionHeader         = "header"
+	SectionDetails        = "replay.details"
+	SectionInitData       = "replay.initData"
+	SectionAttributesEvts = "replay.attributes.events"
+	SectionGameMetadata   = "replay.gamemetadata.json"
+	SectionGameEvts       = "replay.game.events"
+	SectionMessageEvts    = "replay.message.events"
+	SectionTrackerEvts    = "replay.tracker.events"
+)
+
+// SectionCheck is the result of verifying a section of a replay.
+type SectionCheck struct {
+	Section string `json:"section"`       // Name of the section, e.g. "replay.details"
+	Err     error  `json:"-"`             // Decoding error, nil if the section decoded cleanly
+	ErrMsg  string `json:"err,omitempty"` // Message of Err
+}
+
+// Result is the result of verifying a replay.
+type Result struct {
+	Path      string          `json:"path,omitempty"` // Path of the replay file (if verified from a file)
+	BaseBuild int64           `json:"baseBuild"`      // Base build of the replay
+	Sections  []*SectionCheck `json:"sections"`       // Checks of the present sections
+}
+
+// OK tells if all sections of the replay decoded cleanly.
+func (r *Result) OK() bool {
+	for _, sc := range r.Sections {
+		if sc.Err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// Verify verifies the replay read from input: all present sections are decoded in strict validation mode.
+//
+// Errors of the rep package (e.g. rep.ErrInvalidRepFile, *rep.HeaderError or rep.ErrUnsupportedRepVersion) are returned
+// if input is not a valid replay or its version is not supported; decoding errors of the sections
+// are reported in the result.
+func Verify(input io.ReadSeeker) (*Result, error) {
+	m, err := mpq.New(input)
+	if err != nil {
+		return nil, rep.ErrInvalidRepFile
+	}
+	defer m.Close()
+
+	header, err := rep.ParseHeader(m.UserData())
+	if err != nil {
+		return nil, err
+	}
+	p := header.Protocol()
+	if p == nil {
+		return nil, rep.ErrUnsupportedRepVersion
+	}
+	p = p.WithStrict(true)
+
+	res := &Result{BaseBuild: header.BaseBuild()}
+	check := func(name string, decode func(data []byte) error) {
+		var data []byte
+		if name == SectionHeader {
+			data = m.UserData()
+		} else if data, _ = m.FileByName(name); data == nil {
+			return // Section not present
+		}
+		sc := &SectionCheck{Section: name}
+		sc.Err = protect(func() error { return decode(data) })
+		if sc.Err != nil {
+			sc.ErrMsg = sc.Err.Error()
+		}
+		res.Sections = append(res.Sections, sc)
+	}
+	decodeEvts := func(decode func([]byte) ([]s2prot.Event, error)) func(data []byte) error {
+		return func(data []byte) error {
+			_, err := decode(data)
+			return err
+		}
+	}
+
+	check(SectionHeader, func(data []byte) error { s2prot.DecodeHeaderWith(p, data); return nil })
+	check(SectionDetails, func(data []byte) error { p.DecodeDetails(data); return nil })
+	check(SectionInitData, func(data []byte) error { p.DecodeInitData(data); return nil })
+	check(SectionAttributesEvts, func(data []byte) error { p.DecodeAttributesEvts(data); return nil })
+	check(SectionGameMetadata, func(data []byte) error { return json.Unmarshal(data, &s2prot.Struct{}) })
+	check(SectionGameEvts, decodeEvts(p.DecodeGameEvts))
+	check(SectionMessageEvts, decodeEvts(p.DecodeMessageEvts))
+	if p.HasTrackerEvents() {
+		check(SectionTrackerEvts, decodeEvts(p.DecodeTrackerEvts))
+	}
+
+	return res, nil
+}
+
+// protect calls f, and returns its error, or the error it panicked with.
+func protect(f func() error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			if ve, ok := r.(*s2prot.ValidationError); ok {
+				err = ve
+			} else {
+				err = fmt.Errorf("panic: %v", r)
+			}
+		}
+	}()
+	return f()
+}
+
+// VerifyFile verifies the named replay file, see Verify.
+func VerifyFile(name string) (*Result, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	res, err := Verify(f)
+	if err != nil {
+		return nil, err
+	}
+	res.Path = name
+	return res, nil
+}
+
+// BuildCoverage is the verification summary of the replays of a base build.
+type BuildCoverage struct {
+	BaseBuild       int64          `json:"baseBuild"`                 // Base build
+	Replays         int            `json:"replays"`                   // Number of verified replays
+	Clean           int            `json:"clean"`                     // Number of replays whose all sections decoded cleanly
+	SectionFailures map[string]int `json:"sectionFailures,omitempty"` // Number of failures by section name
+}
+
+// Report is the verification report of a replay corpus.
+type Report struct {
+	Builds  []*BuildCoverage    `json:"builds"`  // Coverage of the base builds, ordered by base build
+	Results []*Result           `json:"results"` // Results of the verified replays, in the order of the paths
+	Errs    []*corpus.FileError `json:"-"`       // Errors of files that could not be verified
+}
+
+// OK tells if all replays were verified and all their sections decoded cleanly.
+func (r *Report) OK() bool {
+	if len(r.Errs) > 0 {
+		return false
+	}
+	for _, res := range r.Results {
+		if !res.OK() {
+			return false
+		}
+	}
+	return true
+}
+
+// Corpus verifies the replay files of the specified paths (see corpus.Walk)
+// using the given number of concurrent workers (0 means runtime.NumCPU()), see Verify.
+func Corpus(paths []string, workers int) *Report {
+	report := &Report{}
+
+	names, errs := corpus.Walk(paths)
+	results := make([]*Result, len(names))
+	report.Errs = append(errs, corpus.Process(names, workers, func(i int, name string) (err error) {
+		results[i], err = VerifyFile(name)
+		return
+	})...)
+
+	builds := map[int64]*BuildCoverage{}
+	for _, res := range results {
+		if res == nil {
+			continue
+		}
+		report.Results = append(report.Results, res)
+
+		bc := builds[res.BaseBuild]
+		if bc == nil {
+			bc = &BuildCoverage{BaseBuild: res.BaseBuild}
+			builds[res.BaseBuild] = bc
+			report.Builds = append(report.Builds, bc)
+		}
+		bc.Replays++
+		if res.OK() {
+			bc.Clean++
+		}
+		for _, sc := range res.Sections {
+			if sc.Err != nil {
+				if bc.SectionFailures == nil {
+					bc.SectionFailures = map[string]int{}
+				}
+				bc.SectionFailures[sc.Section]++
+			}
+		}
+	}
+	sort.Slice(report.Builds, func(i, j int) bool { return report.Builds[i].BaseBuild < report.Builds[j].BaseBuild })
+
+	return report
+}
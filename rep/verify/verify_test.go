@@ -0,0 +1,136 @@
+package verify
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/icza/mpq"
+	"github.com/icza/s2prot"
+	"github.com/icza/s2prot/rep"
+	"github.com/icza/s2prot/rep/corpus"
+	"github.com/icza/s2prot/rep/reptest"
+	"github.com/icza/s2prot/rep/rewrite"
+)
+
+func TestCorpusSynthetic(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, "sub"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	write := func(name string, baseBuild int) {
+		if err := reptest.New(baseBuild).WriteFile(filepath.Join(dir, name)); err != nil {
+			t.Fatalf("Failed to write replay: %v", err)
+		}
+	}
+	write("a.SC2Replay", 80949)
+	write("b.SC2Replay", 15405)
+	write("sub/c.sc2replay", 80949)
+	if err := os.WriteFile(filepath.Join(dir, "invalid.SC2Replay"), []byte("invalid"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	empty := t.TempDir()
+
+	report := Corpus([]string{dir, empty}, 2)
+	if report.OK() {
+		t.Error("Expected not OK report!")
+	}
+	if len(report.Results) != 3 {
+		t.Errorf("Expected %d results, got: %d", 3, len(report.Results))
+	}
+	for _, res := range report.Results {
+		if !res.OK() {
+			t.Errorf("[%s] Expected clean result, got: %+v", res.Path, res.Sections)
+		}
+	}
+
+	exp := []BuildCoverage{{BaseBuild: 15405, Replays: 1, Clean: 1}, {BaseBuild: 80949, Replays: 2, Clean: 2}}
+	if len(report.Builds) != len(exp) {
+		t.Fatalf("Expected %d builds, got: %d", len(exp), len(report.Builds))
+	}
+	for i, bc := range report.Builds {
+		if bc.BaseBuild != exp[i].BaseBuild || bc.Replays != exp[i].Replays || bc.Clean != exp[i].Clean || bc.SectionFailures != nil {
+			t.Errorf("[%d] Expected: %+v, got: %+v", i, exp[i], bc)
+		}
+	}
+
+	if len(report.Errs) != 2 {
+		t.Fatalf("Expected %d errors, got: %v", 2, report.Errs)
+	}
+	if fe := report.Errs[0]; fe.Name != empty || fe.Err != corpus.ErrNoReplays {
+		t.Errorf("Expected no replays error, got: %v", fe)
+	}
+	if fe := report.Errs[1]; filepath.Base(fe.Name) != "invalid.SC2Replay" || fe.Err != rep.ErrInvalidRepFile {
+		t.Errorf("Expected invalid replay error, got: %v", fe)
+	}
+}
+
+// TestCorpus verifies the replays of the corpus specified by the S2PROT_CORPUS environment variable
+// (a list of replay folders and files separated by the OS path list separator), e.g.:
+//
+//	S2PROT_CORPUS=/path/to/replays go test -run TestCorpus -v ./rep/verify
+//
+// The test is skipped if S2PROT_CORPUS is not set.
+func TestCorpus(t *testing.T) {
+	corpus := os.Getenv("S2PROT_CORPUS")
+	if corpus == "" {
+		t.Skip("S2PROT_CORPUS is not set")
+	}
+
+	report := Corpus(strings.Split(corpus, string(os.PathListSeparator)), 0)
+	for _, bc := range report.Builds {
+		t.Logf("Base build %6d: %4d/%4d clean, failures: %v", bc.BaseBuild, bc.Clean, bc.Replays, bc.SectionFailures)
+	}
+	for _, fe := range report.Errs {
+		t.Errorf("Failed to verify: %v", fe)
+	}
+	for _, res := range report.Results {
+		for _, sc := range res.Sections {
+			if sc.Err != nil {
+				t.Errorf("[%s] Section %s: %v", res.Path, sc.Section, sc.Err)
+			}
+		}
+	}
+}
+
+func TestVerifyTrailing(t *testing.T) {
+	data, err := reptest.New(80949).Bytes()
+	if err != nil {
+		t.Fatalf("Failed to create replay: %v", err)
+	}
+	m, err := mpq.New(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := map[string][]byte{}
+	for _, name := range []string{SectionDetails, SectionInitData, SectionAttributesEvts, SectionGameEvts} {
+		if files[name], err = m.FileByName(name); err != nil {
+			t.Fatal(err)
+		}
+	}
+	files[SectionDetails] = append(files[SectionDetails], 0xff) // Trailing garbage
+	buf := &bytes.Buffer{}
+	if err := rewrite.WriteArchive(buf, m.UserData(), files); err != nil {
+		t.Fatal(err)
+	}
+	m.Close()
+
+	res, err := Verify(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatalf("Failed to verify: %v", err)
+	}
+	if res.OK() || res.BaseBuild != 80949 {
+		t.Errorf("Expected failure, got: %+v", res)
+	}
+	for _, sc := range res.Sections {
+		_, isVE := sc.Err.(*s2prot.ValidationError)
+		if failed := sc.Section == SectionDetails; isVE != failed {
+			t.Errorf("[%s] Unexpected error: %v", sc.Section, sc.Err)
+		}
+	}
+	if len(res.Sections) != len(files)+1 {
+		t.Errorf("Expected %d sections, got: %d", len(files)+1, len(res.Sections))
+	}
+}
@@ -0,0 +1,97 @@
+/*
+
+Reconciled game results and winners.
+
+*/
+
+package rep
+
+// TeamUndecided is the team ID returned by Rep.WinnerTeam() if the winner cannot be decided
+// (e.g. the game was a tie or players dropped).
+const TeamUndecided int64 = -1
+
+// PlayerResults returns the reconciled results of the players, index is the same as in Details.Players().
+//
+// Results of Details are used. Unknown results are taken from the game metadata (if it is not synthesized),
+// then results are shared inside teams (a team wins or loses together), and if all teams but one are defeated,
+// the remaining team is victorious.
+func (r *Rep) PlayerResults() []*Result {
+	players := r.Details.Players()
+	results := make([]*Result, len(players))
+	for i := range players {
+		results[i] = players[i].Result()
+		if results[i] != ResultUnknown || r.Metadata.Synthesized() {
+			continue
+		}
+		if mp := r.metaPlayer(i); mp != nil {
+			switch mp.Result() {
+			case "Win":
+				results[i] = ResultVictory
+			case "Loss":
+				results[i] = ResultDefeat
+			}
+		}
+	}
+
+	// Team results: a team is victorious if any member is, defeated if any member is (and none victorious).
+	teamResults := map[int64]*Result{}
+	for i := range players {
+		teamID, res := r.PlayerTeamID(i), results[i]
+		if tr := teamResults[teamID]; tr == nil || tr == ResultUnknown || res == ResultVictory {
+			teamResults[teamID] = res
+		}
+	}
+
+	// If all teams but one are defeated, the remaining one is victorious:
+	undefeated := int64(TeamUndecided)
+	undefeatedCount := 0
+	for teamID, tr := range teamResults {
+		if tr != ResultDefeat {
+			undefeated = teamID
+			undefeatedCount++
+		}
+	}
+	if undefeatedCount == 1 && len(teamResults) > 1 && teamResults[undefeated] == ResultUnknown {
+		teamResults[undefeated] = ResultVictory
+	}
+
+	for i := range players {
+		if results[i] == ResultUnknown {
+			results[i] = teamResults[r.PlayerTeamID(i)]
+		}
+	}
+	return results
+}
+
+// WinnerTeam returns the team ID of the winners based on the reconciled results (see PlayerResults()).
+// TeamUndecided is returned if there is no single victorious team.
+func (r *Rep) WinnerTeam() int64 {
+	winner := TeamUndecided
+	for i, res := range r.PlayerResults() {
+		if res != ResultVictory {
+			continue
+		}
+		teamID := r.PlayerTeamID(i)
+		if winner != TeamUndecided && winner != teamID {
+			return TeamUndecided
+		}
+		winner = teamID
+	}
+	return winner
+}
+
+// Winners returns the players of the winner team.
+// nil is returned if the winner is undecided (see WinnerTeam()).
+func (r *Rep) Winners() (winners []*Player) {
+	winner := r.WinnerTeam()
+	if winner == TeamUndecided {
+		return nil
+	}
+	players := r.Details.Players()
+	for i := range players {
+		if r.PlayerTeamID(i) == winner {
+			winners = append(winners, &players[i])
+		}
+	}
+	return
+}
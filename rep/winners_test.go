@@ -0,0 +1,54 @@
+package rep
+
+import (
+	"testing"
+
+	"github.com/icza/s2prot"
+)
+
+func TestWinners(t *testing.T) {
+	type pl struct {
+		teamID, result int64 // result: 0 unknown, 1 victory, 2 defeat, 3 tie
+	}
+
+	cases := []struct {
+		name    string
+		players []pl
+		results []*Result
+		winner  int64
+	}{
+		{"1v1", []pl{{0, 1}, {1, 2}}, []*Result{ResultVictory, ResultDefeat}, 0},
+		{"team shares result", []pl{{0, 2}, {0, 0}, {1, 1}, {1, 0}}, []*Result{ResultDefeat, ResultDefeat, ResultVictory, ResultVictory}, 1},
+		{"last undefeated team", []pl{{0, 2}, {1, 0}, {2, 2}}, []*Result{ResultDefeat, ResultVictory, ResultDefeat}, 1},
+		{"all unknown", []pl{{0, 0}, {1, 0}}, []*Result{ResultUnknown, ResultUnknown}, TeamUndecided},
+		{"tie", []pl{{0, 3}, {1, 3}}, []*Result{ResultTie, ResultTie}, TeamUndecided},
+	}
+
+	for _, c := range cases {
+		var players []interface{}
+		for _, p := range c.players {
+			players = append(players, s2prot.Struct{"teamId": p.teamID, "result": p.result})
+		}
+		r := &Rep{Details: Details{Struct: s2prot.Struct{"playerList": players}}}
+
+		results := r.PlayerResults()
+		for i := range c.results {
+			if results[i] != c.results[i] {
+				t.Errorf("[%s] Expected results: %v, got: %v", c.name, c.results, results)
+				break
+			}
+		}
+		if winner := r.WinnerTeam(); winner != c.winner {
+			t.Errorf("[%s] Expected winner: %d, got: %d", c.name, c.winner, winner)
+		}
+		winners := r.Winners()
+		if c.winner == TeamUndecided && winners != nil {
+			t.Errorf("[%s] Expected no winners, got: %v", c.name, winners)
+		}
+		for _, p := range winners {
+			if p.TeamID() != c.winner {
+				t.Errorf("[%s] Unexpected winner: %v", c.name, p)
+			}
+		}
+	}
+}
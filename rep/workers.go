@@ -0,0 +1,88 @@
+/*
+
+Worker harassment metrics.
+
+*/
+
+package rep
+
+import (
+	"sort"
+	"time"
+)
+
+// WorkerLoss describes the death of a worker.
+type WorkerLoss struct {
+	Loop      int64         // Game loop of the death
+	Time      time.Duration // Real (wall clock) time of the death
+	UnitType  string        // Unit type name of the worker
+	PlayerIdx int           // Index of the owner of the worker in Details.Players()
+	KillerIdx int           // Index of the killer player in Details.Players(), -1 if unknown
+}
+
+// WorkerStats holds the worker losses and kills of a player.
+type WorkerStats struct {
+	Lost   int // Number of own workers that died
+	Killed int // Number of workers of other players killed by the player
+}
+
+// WorkerLosses returns the worker deaths of the game in the order of their loop.
+// The killer is taken from the killerPlayerId of the UnitDied tracker events where present.
+// Drones that died without a killer are not included, they morphed into structures.
+// nil is returned if tracker events were not decoded.
+func (r *Rep) WorkerLosses() (losses []WorkerLoss) {
+	units := r.Units()
+	if units == nil {
+		return nil
+	}
+
+	losses = []WorkerLoss{}
+	for _, u := range units {
+		if u.Died < 0 {
+			continue
+		}
+		typeName := u.TypeAt(u.Died)
+		if ub := unitBalances[typeName]; ub == nil || !ub.worker {
+			continue
+		}
+		if typeName == "Drone" && u.KillerPlayerID < 0 {
+			continue
+		}
+		losses = append(losses, WorkerLoss{
+			Loop:      u.Died,
+			Time:      r.loopDuration(u.Died),
+			UnitType:  typeName,
+			PlayerIdx: int(u.OwnerAt(u.Died)) - 1,
+			KillerIdx: int(u.KillerPlayerID) - 1,
+		})
+	}
+	// Units are in the order of their birth, sort losses by death:
+	sort.SliceStable(losses, func(i, j int) bool { return losses[i].Loop < losses[j].Loop })
+	return
+}
+
+// WorkerHarassment returns the number of workers lost and killed per player before the given real time
+// (e.g. "workers lost before 6:00"), index is the same as in Details.Players().
+// Workers killed by their owner (or with unknown killer) are only counted as lost.
+// Use a negative value to count until the end of the game.
+// nil is returned if tracker events were not decoded.
+func (r *Rep) WorkerHarassment(before time.Duration) []WorkerStats {
+	losses := r.WorkerLosses()
+	if losses == nil {
+		return nil
+	}
+
+	stats := make([]WorkerStats, len(r.Details.Players()))
+	for _, wl := range losses {
+		if before >= 0 && wl.Time >= before {
+			break
+		}
+		if wl.PlayerIdx >= 0 && wl.PlayerIdx < len(stats) {
+			stats[wl.PlayerIdx].Lost++
+		}
+		if wl.KillerIdx >= 0 && wl.KillerIdx < len(stats) && wl.KillerIdx != wl.PlayerIdx {
+			stats[wl.KillerIdx].Killed++
+		}
+	}
+	return stats
+}
@@ -0,0 +1,57 @@
+package rep
+
+import (
+	"testing"
+	"time"
+
+	"github.com/icza/s2prot"
+)
+
+func TestWorkerHarassment(t *testing.T) {
+	r := &Rep{}
+	r.Details = Details{Struct: s2prot.Struct{
+		"gameSpeed":  int64(4),
+		"playerList": []interface{}{s2prot.Struct{}, s2prot.Struct{}},
+	}}
+
+	if losses := r.WorkerLosses(); losses != nil {
+		t.Errorf("Expected no losses without tracker events, got: %v", losses)
+	}
+
+	born := func(tagIdx, pid int64, unitType string) s2prot.Event {
+		return unitEvt(0, tagIdx, "UnitBorn", s2prot.Struct{"unitTypeName": unitType, "controlPlayerId": pid})
+	}
+	died := func(loop, tagIdx int64, killerPID interface{}) s2prot.Event {
+		return unitEvt(loop, tagIdx, "UnitDied", s2prot.Struct{"killerPlayerId": killerPID})
+	}
+	r.TrackerEvts = &TrackerEvts{Evts: []s2prot.Event{
+		born(1, 1, "SCV"), born(2, 1, "SCV"), born(3, 2, "Drone"), born(4, 2, "Drone"), born(5, 2, "Zergling"),
+		died(1344, 2, int64(2)), // 1:00
+		died(2000, 3, nil),      // Morphed into a structure
+		died(4000, 5, int64(1)), // Not a worker
+		died(8064, 1, int64(2)), // 6:00
+		died(9000, 4, int64(1)),
+	}}
+
+	losses := r.WorkerLosses()
+	exp := []WorkerLoss{
+		{1344, time.Minute, "SCV", 0, 1},
+		{8064, 6 * time.Minute, "SCV", 0, 1},
+		{9000, r.loopDuration(9000), "Drone", 1, 0},
+	}
+	if len(losses) != len(exp) {
+		t.Fatalf("Expected %d losses, got: %v", len(exp), losses)
+	}
+	for i := range exp {
+		if losses[i] != exp[i] {
+			t.Errorf("[%d] Expected: %v, got: %v", i, exp[i], losses[i])
+		}
+	}
+
+	if stats := r.WorkerHarassment(6 * time.Minute); len(stats) != 2 || stats[0] != (WorkerStats{1, 0}) || stats[1] != (WorkerStats{0, 1}) {
+		t.Errorf("Unexpected stats before 6:00: %v", stats)
+	}
+	if stats := r.WorkerHarassment(-1); len(stats) != 2 || stats[0] != (WorkerStats{2, 1}) || stats[1] != (WorkerStats{1, 2}) {
+		t.Errorf("Unexpected stats: %v", stats)
+	}
+}
@@ -0,0 +1,130 @@
+package s2map
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+)
+
+// MapInfo contains the general info of the map, parsed from the MapInfo file.
+type MapInfo struct {
+	Version uint32 // Version of the MapInfo file format
+
+	Width  int // Width of the map
+	Height int // Height of the map
+
+	FogType string // Fog type
+	TileSet string // Tile set, e.g. "Char"
+
+	PlayableArea Rect // Playable area (camera bounds)
+
+	BaseHeight float64 // Base height of the terrain
+}
+
+// Rect is a rectangle in map coordinates.
+type Rect struct {
+	Left, Bottom, Right, Top int
+}
+
+// String returns the string representation of the rectangle in the form of "(left,bottom)-(right,top)".
+func (r Rect) String() string {
+	return fmt.Sprintf("(%d,%d)-(%d,%d)", r.Left, r.Bottom, r.Right, r.Top)
+}
+
+// Width returns the width of the rectangle.
+func (r Rect) Width() int {
+	return r.Right - r.Left
+}
+
+// Height returns the height of the rectangle.
+func (r Rect) Height() int {
+	return r.Top - r.Bottom
+}
+
+// previewTypeCustom is the preview image type denoting a custom image (whose path follows).
+const previewTypeCustom = 2
+
+// reader is a little endian binary reader with a sticky error.
+type reader struct {
+	data []byte
+	pos  int
+	err  error
+}
+
+// uint32 reads an uint32 value.
+func (r *reader) uint32() uint32 {
+	if r.err != nil {
+		return 0
+	}
+	if r.pos+4 > len(r.data) {
+		r.err = ErrDecoding
+		return 0
+	}
+	v := binary.LittleEndian.Uint32(r.data[r.pos:])
+	r.pos += 4
+	return v
+}
+
+// cstring reads a zero-terminated string.
+func (r *reader) cstring() string {
+	if r.err != nil {
+		return ""
+	}
+	i := bytes.IndexByte(r.data[r.pos:], 0)
+	if i < 0 {
+		r.err = ErrDecoding
+		return ""
+	}
+	s := string(r.data[r.pos : r.pos+i])
+	r.pos += i + 1
+	return s
+}
+
+// parseMapInfo parses the content of the MapInfo file.
+// Only the beginning of the file is parsed which contains the fields of MapInfo.
+func parseMapInfo(data []byte, mi *MapInfo) error {
+	if !bytes.HasPrefix(data, []byte("IpaM")) { // "MapI" reversed
+		return ErrInvalidMapFile
+	}
+	r := &reader{data: data, pos: 4}
+
+	mi.Version = r.uint32()
+	if mi.Version >= 0x18 {
+		r.uint32() // Unknown
+		r.uint32() // Unknown
+	}
+
+	mi.Width = int(r.uint32())
+	mi.Height = int(r.uint32())
+
+	// Small and large preview images:
+	for i := 0; i < 2; i++ {
+		if r.uint32() == previewTypeCustom {
+			r.cstring() // Preview image path
+		}
+	}
+
+	if mi.Version >= 0x1f {
+		r.cstring() // Unknown
+	}
+	if mi.Version >= 0x26 {
+		r.cstring() // Unknown
+	}
+	if mi.Version >= 0x1f {
+		r.uint32() // Unknown
+	}
+	r.uint32() // Unknown
+
+	mi.FogType = r.cstring()
+	mi.TileSet = r.cstring()
+
+	mi.PlayableArea = Rect{
+		Left:   int(r.uint32()),
+		Bottom: int(r.uint32()),
+		Right:  int(r.uint32()),
+		Top:    int(r.uint32()),
+	}
+	mi.BaseHeight = float64(r.uint32()) / 4096
+
+	return r.err
+}
@@ -0,0 +1,185 @@
+/*
+Package s2map implements parsing StarCraft II map files (*.s2ma).
+
+Map files are MPQ archives, just like replays. Map files referenced by replays can be downloaded
+using the depot package, see rep.Details.CacheHandles() and the depot.Client type.
+
+Example:
+
+	m, err := s2map.NewFromFile("/path/to/map.s2ma")
+	if err != nil {
+		// Handle error
+	}
+	defer m.Close()
+
+	fmt.Println("Name:", m.Name("enUS"))
+	fmt.Println("Tile set:", m.Info.TileSet)
+	fmt.Println("Playable area:", m.Info.PlayableArea)
+	fmt.Println("Start locations:", m.StartLocations)
+*/
+package s2map
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/icza/mpq"
+)
+
+var (
+	// ErrInvalidMapFile means invalid map file.
+	ErrInvalidMapFile = errors.New("invalid s2ma file")
+
+	// ErrDecoding means decoding the map file failed.
+	ErrDecoding = errors.New("decoding error")
+)
+
+// Locales is the list of locales whose localized data is looked for in map files.
+var Locales = []string{"enUS", "enGB", "deDE", "esES", "esMX", "frFR", "itIT", "koKR", "plPL", "ptBR", "ruRU", "zhCN", "zhTW"}
+
+// Map describes a map file.
+type Map struct {
+	m *mpq.MPQ // MPQ parser for reading the file
+
+	Info MapInfo // Map info (size, playable area, tile set etc.)
+
+	Names map[string]string // Localized map names, mapped from locale (e.g. "enUS")
+
+	StartLocations []Point // Start locations
+
+	Minimap []byte // Minimap image, TGA format, nil if not present
+}
+
+// Point is a point in map coordinates.
+type Point struct {
+	X, Y, Z float64
+}
+
+// NewFromFile returns a new Map constructed from a file.
+// The returned Map must be closed with the Close method!
+func NewFromFile(name string) (*Map, error) {
+	m, err := mpq.NewFromFile(name)
+	if err != nil {
+		return nil, ErrInvalidMapFile
+	}
+	return newMap(m)
+}
+
+// New returns a new Map using the specified io.ReadSeeker as the map file source.
+// The returned Map must be closed with the Close method!
+func New(input io.ReadSeeker) (*Map, error) {
+	m, err := mpq.New(input)
+	if err != nil {
+		return nil, ErrInvalidMapFile
+	}
+	return newMap(m)
+}
+
+// newMap returns a new Map constructed using the specified mpq.MPQ handler of the map file.
+func newMap(m *mpq.MPQ) (*Map, error) {
+	data, err := m.FileByName("MapInfo")
+	if err != nil || data == nil {
+		m.Close()
+		return nil, ErrInvalidMapFile
+	}
+
+	mp := &Map{m: m}
+	if err = parseMapInfo(data, &mp.Info); err != nil {
+		m.Close()
+		return nil, err
+	}
+
+	mp.Names = make(map[string]string)
+	for _, loc := range Locales {
+		data, err := m.FileByName(loc + ".SC2Data\\LocalizedData\\GameStrings.txt")
+		if err != nil || data == nil {
+			continue
+		}
+		if name := parseGameStrings(data)["DocInfo/Name"]; name != "" {
+			mp.Names[loc] = name
+		}
+	}
+
+	if data, err = m.FileByName("Objects"); err == nil && data != nil {
+		mp.StartLocations = parseStartLocations(data)
+	}
+
+	if data, err = m.FileByName("Minimap.tga"); err == nil {
+		mp.Minimap = data
+	}
+
+	return mp, nil
+}
+
+// Close closes the Map and its resources.
+func (m *Map) Close() error {
+	if m.m == nil {
+		return nil
+	}
+	return m.m.Close()
+}
+
+// Name returns the map name in the specified locale.
+// If the map has no name in the specified locale, the name in "enUS" is returned,
+// and if that's missing too, any name.
+func (m *Map) Name(locale string) string {
+	if name, ok := m.Names[locale]; ok {
+		return name
+	}
+	if name, ok := m.Names["enUS"]; ok {
+		return name
+	}
+	for _, loc := range Locales {
+		if name, ok := m.Names[loc]; ok {
+			return name
+		}
+	}
+	return ""
+}
+
+// parseGameStrings parses the content of a GameStrings.txt file,
+// which consists of lines in the form of "key=value".
+func parseGameStrings(data []byte) map[string]string {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")) // UTF-8 BOM
+
+	strs := make(map[string]string)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(nil, 1<<20)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if i := strings.IndexByte(line, '='); i > 0 {
+			strs[line[:i]] = line[i+1:]
+		}
+	}
+	return strs
+}
+
+// parseStartLocations parses the start locations from the content of the Objects file.
+func parseStartLocations(data []byte) (locs []Point) {
+	var objs struct {
+		Points []struct {
+			Type     string `xml:"Type,attr"`
+			Position string `xml:"Position,attr"`
+		} `xml:"ObjectPoint"`
+	}
+	if err := xml.Unmarshal(data, &objs); err != nil {
+		return nil
+	}
+
+	for _, p := range objs.Points {
+		if p.Type != "StartLoc" {
+			continue
+		}
+		var coords [3]float64
+		for i, s := range strings.SplitN(p.Position, ",", 3) {
+			coords[i], _ = strconv.ParseFloat(strings.TrimSpace(s), 64)
+		}
+		locs = append(locs, Point{X: coords[0], Y: coords[1], Z: coords[2]})
+	}
+	return
+}
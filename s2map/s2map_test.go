@@ -0,0 +1,93 @@
+package s2map
+
+import (
+	"bytes"
+	"encoding/binary"
+	"reflect"
+	"testing"
+)
+
+func TestParseMapInfo(t *testing.T) {
+	buf := &bytes.Buffer{}
+	u32 := func(vs ...uint32) {
+		for _, v := range vs {
+			binary.Write(buf, binary.LittleEndian, v)
+		}
+	}
+	cstr := func(s string) {
+		buf.WriteString(s)
+		buf.WriteByte(0)
+	}
+
+	buf.WriteString("IpaM")
+	u32(0x26, 0, 0) // Version, unknowns
+	u32(176, 152)   // Width, height
+	u32(1)          // Small preview: minimap
+	u32(2)          // Large preview: custom
+	cstr("Preview.tga")
+	cstr("")
+	cstr("")
+	u32(0, 0)
+	cstr("Dark")
+	cstr("Char")
+	u32(8, 4, 168, 148) // Camera bounds
+	u32(8 * 4096)       // Base height
+	u32(0, 0, 0)        // Rest of the file
+
+	var mi MapInfo
+	if err := parseMapInfo(buf.Bytes(), &mi); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	exp := MapInfo{
+		Version: 0x26, Width: 176, Height: 152, FogType: "Dark", TileSet: "Char",
+		PlayableArea: Rect{8, 4, 168, 148}, BaseHeight: 8,
+	}
+	if mi != exp {
+		t.Errorf("Expected: %+v, got: %+v", exp, mi)
+	}
+	if s := mi.PlayableArea.String(); s != "(8,4)-(168,148)" || mi.PlayableArea.Width() != 160 || mi.PlayableArea.Height() != 144 {
+		t.Errorf("Unexpected playable area: %s", s)
+	}
+
+	if err := parseMapInfo(buf.Bytes()[:40], &mi); err != ErrDecoding {
+		t.Errorf("Expected: %v, got: %v", ErrDecoding, err)
+	}
+	if err := parseMapInfo([]byte("invalid"), &mi); err != ErrInvalidMapFile {
+		t.Errorf("Expected: %v, got: %v", ErrInvalidMapFile, err)
+	}
+}
+
+func TestParseGameStrings(t *testing.T) {
+	strs := parseGameStrings([]byte("\xef\xbb\xbfDocInfo/Name=Ever Dream LE\r\nDocInfo/DescLong=a=b\r\ninvalid\r\n"))
+	exp := map[string]string{"DocInfo/Name": "Ever Dream LE", "DocInfo/DescLong": "a=b"}
+	if !reflect.DeepEqual(strs, exp) {
+		t.Errorf("Expected: %v, got: %v", exp, strs)
+	}
+}
+
+func TestParseStartLocations(t *testing.T) {
+	locs := parseStartLocations([]byte(`<?xml version="1.0" encoding="utf-8"?>
+<PlacedObjects Version="26">
+    <ObjectUnit Id="1" Position="10,20,8" UnitType="MineralField"/>
+    <ObjectPoint Id="2" Position="40.5,120.5,8" Type="StartLoc" Name="Start Location 001"/>
+    <ObjectPoint Id="3" Position="1,2,3" Type="Normal"/>
+    <ObjectPoint Id="4" Position="135.5,31.5,8" Type="StartLoc" Name="Start Location 002"/>
+</PlacedObjects>`))
+	exp := []Point{{40.5, 120.5, 8}, {135.5, 31.5, 8}}
+	if !reflect.DeepEqual(locs, exp) {
+		t.Errorf("Expected: %v, got: %v", exp, locs)
+	}
+}
+
+func TestName(t *testing.T) {
+	m := &Map{Names: map[string]string{"enUS": "Map", "deDE": "Karte"}}
+	cases := []struct{ locale, exp string }{{"deDE", "Karte"}, {"enUS", "Map"}, {"koKR", "Map"}}
+	for _, c := range cases {
+		if got := m.Name(c.locale); got != c.exp {
+			t.Errorf("[%s] Expected: %s, got: %s", c.locale, c.exp, got)
+		}
+	}
+	if got := (&Map{}).Name("enUS"); got != "" {
+		t.Errorf("Expected empty name, got: %s", got)
+	}
+}
@@ -1,6 +1,11 @@
 package s2prot
 
 import (
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"reflect"
 	"testing"
 
 	"github.com/icza/s2prot/build"
@@ -23,3 +28,107 @@ func BenchmarkParseProtocol(b *testing.B) {
 		parseProtocol(build.Builds[baseBuild], baseBuild)
 	}
 }
+
+func TestSetProtocolDir(t *testing.T) {
+	dir, err := ioutil.TempDir("", "s2prot")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	defer SetProtocolDir(ProtocolDir())
+
+	const baseBuild = 999999
+	if err := ioutil.WriteFile(filepath.Join(dir, "protocol999999.py"), []byte(build.Builds[80949]), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	SetProtocolDir(dir)
+	if p := GetProtocol(baseBuild); p == nil || p.BaseBuild() != baseBuild {
+		t.Errorf("Expected protocol %d from external folder!", baseBuild)
+	}
+	if GetProtocol(80949) == nil {
+		t.Error("Expected embedded protocol!")
+	}
+
+	SetProtocolDir("")
+	if GetProtocol(baseBuild) != nil {
+		t.Errorf("Unexpected protocol %d after clearing external folder!", baseBuild)
+	}
+}
+
+func TestMinHeaderProtocol(t *testing.T) {
+	// The minimal header types must match the header types of the latest protocol:
+	p := GetProtocol(MaxBaseBuild)
+	exp := p.TypeSignature(p.replayHeaderTypeid)
+	if got := minHeaderProtocol.TypeSignature(minHeaderProtocol.replayHeaderTypeid); got != exp {
+		t.Errorf("Expected: %s, got: %s", exp, got)
+	}
+}
+
+func TestWithEvtKeys(t *testing.T) {
+	p := GetProtocol(80949)
+	g := &instanceGen{p: p, r: rand.New(rand.NewSource(1))}
+	data, err := p.EncodeGameEvts(g.randomEvts(p.gameEvtTypes, true))
+	if err != nil {
+		t.Fatalf("Failed to encode events: %v", err)
+	}
+
+	if !p.EvtKeys() {
+		t.Error("Expected embedded keys by default!")
+	}
+	p2 := p.WithEvtKeys(false)
+	if p2.EvtKeys() || !p.EvtKeys() {
+		t.Error("Expected omitted keys only in the copy!")
+	}
+
+	embedded, err := p.DecodeGameEvts(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	omitted, err := p2.DecodeGameEvts(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(embedded) != len(omitted) || len(embedded) == 0 {
+		t.Fatalf("Expected %d events, got: %d", len(embedded), len(omitted))
+	}
+	for i := range omitted {
+		e, o := &embedded[i], &omitted[i]
+		for _, key := range []string{"id", "evtTypeName", "loop", "userid"} {
+			if _, ok := o.Struct[key]; ok {
+				t.Errorf("[%d] Unexpected key: %s", i, key)
+			}
+		}
+		if e.Loop() != o.Loop() || e.UserID() != o.UserID() || e.EvtType != o.EvtType {
+			t.Errorf("[%d] Mismatch: %d %d, got: %d %d", i, e.Loop(), e.UserID(), o.Loop(), o.UserID())
+		}
+	}
+
+	// Events with omitted keys can be encoded:
+	data2, err := p.EncodeGameEvts(omitted)
+	if err != nil || !reflect.DeepEqual(data, data2) {
+		t.Errorf("Expected identical encoding, got: %v", err)
+	}
+
+	for i := range omitted {
+		omitted[i].EmbedKeys()
+	}
+	if !reflect.DeepEqual(embedded, omitted) {
+		t.Error("Expected identical events after embedding keys!")
+	}
+}
+
+func TestParseProtocol(t *testing.T) {
+	src := build.Builds[80949]
+	p, err := ParseProtocol([]byte(src), 80949)
+	if err != nil {
+		t.Fatalf("Failed to parse protocol: %v", err)
+	}
+	if !reflect.DeepEqual(parseProtocol(src, 80949), p) {
+		t.Errorf("Parsed protocol differs from python protocol!")
+	}
+
+	if _, err := ParseProtocol([]byte("{invalid"), 80949); err == nil {
+		t.Errorf("Expected error for invalid protocol!")
+	}
+}
@@ -12,6 +12,7 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"unicode/utf8"
 )
 
 // S2protocol type
@@ -201,33 +202,102 @@ func parseTypeInfo(s string) typeInfo {
 //
 // Tip: the Struct type defines a String() method which returns a nicely formatted JSON representation,
 // so simply printing a Struct results in a nice JSON text.
+//
+// Structs may be of mixed origin: values decoded by the protocol are of type int64, Struct, []interface{} etc.,
+// but values unmarshaled from JSON (e.g. the game metadata) are of type float64 (all numbers),
+// map[string]interface{} etc. Paths traverse both Struct and map[string]interface{} values.
+// Int() and Float() only return values of type int64 and float64 respectively,
+// use Number() to get a numeric value regardless of its origin.
 type Struct map[string]interface{}
 
 // Value returns the value specified by the path.
 // zero value is returned if path is invalid.
 func (s *Struct) Value(path ...string) interface{} {
+	v, _ := s.lookup(path)
+	return v
+}
+
+// lookup returns the value specified by the path, and tells if it exists.
+func (s *Struct) lookup(path []string) (interface{}, bool) {
 	if len(path) == 0 {
-		return nil
+		return nil, false
 	}
 
-	var ok bool
 	ss := *s
 
 	last := len(path) - 1
 	for i := 0; i < last; i++ {
-		if ss, ok = ss[path[i]].(Struct); !ok {
-			return nil
+		if ss = asStruct(ss[path[i]]); ss == nil {
+			return nil, false
 		}
 	}
 
-	return ss[path[last]]
+	v, ok := ss[path[last]]
+	return v, ok
+}
+
+// Has tells if the value specified by the path exists.
+// Note that the value of an existing key may be nil (e.g. in case of an absent optional value).
+func (s *Struct) Has(path ...string) bool {
+	_, ok := s.lookup(path)
+	return ok
+}
+
+// LookupStruct returns the (sub) Struct specified by the path.
+// ok tells if the value exists and is a Struct (or a map[string]interface{}).
+func (s *Struct) LookupStruct(path ...string) (v Struct, ok bool) {
+	v = asStruct(s.Value(path...))
+	return v, v != nil
+}
+
+// LookupInt returns the integer specified by the path.
+// ok tells if the value exists and is an integer.
+func (s *Struct) LookupInt(path ...string) (v int64, ok bool) {
+	v, ok = s.Value(path...).(int64)
+	return
+}
+
+// LookupFloat returns the floating point number specified by the path.
+// ok tells if the value exists and is a floating point number.
+func (s *Struct) LookupFloat(path ...string) (v float64, ok bool) {
+	v, ok = s.Value(path...).(float64)
+	return
+}
+
+// LookupNumber returns the number specified by the path as a floating point number.
+// Integer values are converted, so this can be used regardless of the origin of the value
+// (decoded integers or numbers unmarshaled from JSON).
+// ok tells if the value exists and is a number.
+func (s *Struct) LookupNumber(path ...string) (v float64, ok bool) {
+	return toFloat(s.Value(path...))
+}
+
+// LookupBool returns the bool specified by the path.
+// ok tells if the value exists and is a bool.
+func (s *Struct) LookupBool(path ...string) (v bool, ok bool) {
+	v, ok = s.Value(path...).(bool)
+	return
+}
+
+// LookupString returns the string specified by the path.
+// ok tells if the value exists and is a string.
+func (s *Struct) LookupString(path ...string) (v string, ok bool) {
+	v, ok = s.Value(path...).(string)
+	return
+}
+
+// LookupArray returns the array (of empty interfaces) specified by the path.
+// ok tells if the value exists and is an array.
+func (s *Struct) LookupArray(path ...string) (v []interface{}, ok bool) {
+	v, ok = s.Value(path...).([]interface{})
+	return
 }
 
 // Structv returns the (sub) Struct specified by the path.
+// Values of type map[string]interface{} are also returned as Struct.
 // zero value is returned if path is invalid.
 func (s *Struct) Structv(path ...string) (v Struct) {
-	v, _ = s.Value(path...).(Struct)
-	return
+	return asStruct(s.Value(path...))
 }
 
 // Int returns the integer specified by the path.
@@ -244,6 +314,15 @@ func (s *Struct) Float(path ...string) (v float64) {
 	return
 }
 
+// Number returns the number specified by the path as a floating point number.
+// Integer values are converted, so this can be used regardless of the origin of the value
+// (decoded integers or numbers unmarshaled from JSON).
+// zero value is returned if path is invalid or the value is not a number.
+func (s *Struct) Number(path ...string) float64 {
+	v, _ := toFloat(s.Value(path...))
+	return v
+}
+
 // Bool returns the bool specified by the path.
 // zero value is returned if path is invalid.
 func (s *Struct) Bool(path ...string) (v bool) {
@@ -258,6 +337,19 @@ func (s *Struct) Bytes(path ...string) (v []byte) {
 	return
 }
 
+// Blob returns the binary data specified by the path.
+// Blobs are decoded as strings, this returns them as []byte ([]byte values are returned as-is).
+// zero value is returned if path is invalid.
+func (s *Struct) Blob(path ...string) []byte {
+	switch v := s.Value(path...).(type) {
+	case string:
+		return []byte(v)
+	case []byte:
+		return v
+	}
+	return nil
+}
+
 // Text returns the []byte specified by the path converted to string.
 // zero value is returned if path is invalid.
 func (s *Struct) Text(path ...string) string {
@@ -282,6 +374,60 @@ func (s *Struct) Array(path ...string) (v []interface{}) {
 	return
 }
 
+// Ints returns the array specified by the path as a slice of integers.
+// Elements that are not integers are represented by zero values.
+// zero value is returned if path is invalid.
+func (s *Struct) Ints(path ...string) (v []int64) {
+	arr := s.Array(path...)
+	if arr == nil {
+		return nil
+	}
+	v = make([]int64, len(arr))
+	for i, e := range arr {
+		v[i], _ = e.(int64)
+	}
+	return
+}
+
+// Strings returns the array specified by the path as a slice of strings.
+// Elements that are not strings are represented by zero values.
+// zero value is returned if path is invalid.
+func (s *Struct) Strings(path ...string) (v []string) {
+	arr := s.Array(path...)
+	if arr == nil {
+		return nil
+	}
+	v = make([]string, len(arr))
+	for i, e := range arr {
+		v[i], _ = e.(string)
+	}
+	return
+}
+
+// Structs returns the array specified by the path as a slice of Structs.
+// Elements that are not Structs are represented by zero values.
+// zero value is returned if path is invalid.
+func (s *Struct) Structs(path ...string) (v []Struct) {
+	arr := s.Array(path...)
+	if arr == nil {
+		return nil
+	}
+	v = make([]Struct, len(arr))
+	for i, e := range arr {
+		v[i], _ = e.(Struct)
+	}
+	return
+}
+
+// IntAt returns the integer element at the specified index of the array specified by the path.
+// zero value is returned if path or index is invalid.
+func (s *Struct) IntAt(index int, path ...string) (v int64) {
+	if arr := s.Array(path...); index >= 0 && index < len(arr) {
+		v, _ = arr[index].(int64)
+	}
+	return
+}
+
 // BitArr returns the bit array specified by the path.
 // zero value is returned if path is invalid.
 func (s *Struct) BitArr(path ...string) (v BitArr) {
@@ -289,6 +435,46 @@ func (s *Struct) BitArr(path ...string) (v BitArr) {
 	return
 }
 
+// MarshalJSON marshals the Struct as a JSON object.
+// Binary blobs (string values that are not valid UTF-8, e.g. cache handles or the ngdp root key)
+// are presented in hex format, e.g. "0x1fa4", just like the data of BitArr
+// (the default encoding would replace invalid bytes with the Unicode replacement character, losing data).
+func (s Struct) MarshalJSON() ([]byte, error) {
+	m := make(map[string]interface{}, len(s))
+	for k, v := range s {
+		m[k], _ = jsonValue(v)
+	}
+	return json.Marshal(m)
+}
+
+// jsonValue returns the value to be marshaled in place of v, and tells if it differs from v:
+// binary blobs are converted to hex format (arrays are processed recursively),
+// other values are returned as-is.
+func jsonValue(v interface{}) (interface{}, bool) {
+	switch x := v.(type) {
+	case string:
+		if !utf8.ValidString(x) {
+			return "0x" + hex.EncodeToString([]byte(x)), true
+		}
+	case []interface{}:
+		var arr []interface{} // Lazily allocated copy, only if an element changes
+		for i, e := range x {
+			je, changed := jsonValue(e)
+			if changed && arr == nil {
+				arr = make([]interface{}, len(x))
+				copy(arr, x[:i])
+			}
+			if arr != nil {
+				arr[i] = je
+			}
+		}
+		if arr != nil {
+			return arr, true
+		}
+	}
+	return v, false
+}
+
 // String returns the indented JSON string representation of the Struct.
 // Defined with value receiver so this gets called even if a non-pointer is printed.
 func (s Struct) String() string {
@@ -300,16 +486,50 @@ func (s Struct) String() string {
 type Event struct {
 	Struct
 	*EvtType // Pointer only to avoid copying
+
+	// Bookkeeping values of the event, set if they are omitted from Struct (see Protocol.WithEvtKeys)
+	keysOmitted bool
+	loop        int64
+	userid      interface{}
 }
 
 // Loop returns the loop (time) of the event.
 func (e *Event) Loop() int64 {
+	if e.keysOmitted {
+		return e.loop
+	}
 	return e.Int("loop")
 }
 
+// UserIDValue returns the "userid" value of the event: a Struct holding the "userId"
+// (or the "playerId" in case of early versions). nil is returned if the event has no user ID
+// (e.g. tracker events).
+func (e *Event) UserIDValue() interface{} {
+	if e.keysOmitted {
+		return e.userid
+	}
+	return e.Struct["userid"]
+}
+
 // UserID returns the ID of the user that issued the event.
 func (e *Event) UserID() int64 {
-	return e.Int("userid", "userId")
+	userid, _ := e.UserIDValue().(Struct)
+	return userid.Int("userId")
+}
+
+// EmbedKeys copies the bookkeeping keys ("id", "evtTypeName", "loop" and "userid") into Struct
+// if they were omitted during decoding (see Protocol.WithEvtKeys). It's a no-op otherwise.
+func (e *Event) EmbedKeys() {
+	if !e.keysOmitted {
+		return
+	}
+	e.Struct["id"] = int64(e.ID)
+	e.Struct["evtTypeName"] = e.Name
+	e.Struct["loop"] = e.loop
+	if e.userid != nil {
+		e.Struct["userid"] = e.userid
+	}
+	e.keysOmitted, e.loop, e.userid = false, 0, nil
 }
 
 // BitArr is a bit array which stores the bits in a byte slice.
@@ -350,6 +570,26 @@ func (b *BitArr) Ones() (c int) {
 	return
 }
 
+// SetBits returns the (zero-based) positions of the 1 bits in increasing order.
+func (b *BitArr) SetBits() []int {
+	bits := make([]int, 0, b.Ones())
+	for n := 0; n < b.Count; n++ {
+		if b.Bit(n) {
+			bits = append(bits, n)
+		}
+	}
+	return bits
+}
+
+// ToBools returns the bits as a bool slice, the element at index n tells if the bit at position n is 1.
+func (b *BitArr) ToBools() []bool {
+	bools := make([]bool, b.Count)
+	for n := range bools {
+		bools[n] = b.Bit(n)
+	}
+	return bools
+}
+
 // String returns the string representation of the bit array in hexadecimal form.
 // Using value receiver so printing a BitArr value will call this method.
 func (b BitArr) String() string {
@@ -361,3 +601,26 @@ func (b BitArr) String() string {
 func (b BitArr) MarshalJSON() ([]byte, error) {
 	return []byte(fmt.Sprintf(`{"Count":%d,"Data": "0x%s"}`, b.Count, hex.EncodeToString(b.Data))), nil
 }
+
+// UnmarshalJSON parses the JSON representation produced by MarshalJSON.
+func (b *BitArr) UnmarshalJSON(data []byte) error {
+	var v struct {
+		Count int
+		Data  string
+	}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	if !strings.HasPrefix(v.Data, "0x") {
+		return fmt.Errorf("invalid BitArr data: %q", v.Data)
+	}
+	d, err := hex.DecodeString(v.Data[2:])
+	if err != nil {
+		return err
+	}
+	if v.Count < 0 || len(d) != (v.Count+7)/8 {
+		return fmt.Errorf("invalid BitArr count %d for %d bytes of data", v.Count, len(d))
+	}
+	b.Count, b.Data = v.Count, d
+	return nil
+}
@@ -0,0 +1,37 @@
+package s2prot
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestBitArr(t *testing.T) {
+	b := BitArr{Count: 11, Data: []byte{0x05, 0x04}}
+
+	if got, exp := b.SetBits(), []int{0, 2, 10}; !reflect.DeepEqual(got, exp) {
+		t.Errorf("Expected: %v, got: %v", exp, got)
+	}
+	exp := []bool{true, false, true, false, false, false, false, false, false, false, true}
+	if got := b.ToBools(); !reflect.DeepEqual(got, exp) {
+		t.Errorf("Expected: %v, got: %v", exp, got)
+	}
+
+	data, err := json.Marshal(b)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	var b2 BitArr
+	if err := json.Unmarshal(data, &b2); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(b, b2) {
+		t.Errorf("Expected: %v, got: %v", b, b2)
+	}
+
+	for _, s := range []string{`{"Count":9,"Data":"0xff"}`, `{"Count":8,"Data":"ff"}`, `{"Count":8,"Data":"0xfg"}`, `[]`} {
+		if err := json.Unmarshal([]byte(s), &b2); err == nil {
+			t.Errorf("Expected error for: %s", s)
+		}
+	}
+}
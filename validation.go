@@ -0,0 +1,87 @@
+/*
+
+Strict validation mode: reporting data the protocol does not describe.
+
+*/
+
+package s2prot
+
+import "fmt"
+
+// ValidationError is the error reported in strict validation mode
+// when the decoded data does not match the protocol (e.g. because of protocol drift).
+type ValidationError struct {
+	Typeid int    // Typeid of the type being decoded (-1 if not applicable)
+	Pos    int    // Byte position in the decoded data where the mismatch was detected
+	Reason string // Description of the mismatch
+}
+
+// Error implements error.
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("validation error at byte %d (typeid %d): %s", e.Pos, e.Typeid, e.Reason)
+}
+
+// newValidationError creates a new ValidationError.
+func newValidationError(typeid int, b *bitPackedBuff, format string, a ...interface{}) *ValidationError {
+	return &ValidationError{Typeid: typeid, Pos: b.idx, Reason: fmt.Sprintf(format, a...)}
+}
+
+// WithStrict returns a copy of the protocol whose decoding methods use the specified validation mode.
+//
+// In strict mode the following are not silently ignored but cause decoding to fail
+// with a *ValidationError (decoding methods returning error return it, others panic with it):
+//   - unknown choice tags
+//   - unknown struct field tags (in case of the versioned encoding)
+//   - trailing bytes after the decoded header (except zero padding), details or init data
+func (p *Protocol) WithStrict(strict bool) *Protocol {
+	p2 := new(Protocol)
+	*p2 = *p
+	p2.strict = strict
+	return p2
+}
+
+// Strict tells if strict validation mode is on, see WithStrict.
+func (p *Protocol) Strict() bool {
+	return p.strict
+}
+
+// newBitPackedDec creates a new bit-packed decoder using the validation mode of the protocol.
+func (p *Protocol) newBitPackedDec(contents []byte) *bitPackedDec {
+	d := newBitPackedDec(contents, p.typeInfos)
+	d.strict = p.strict
+	return d
+}
+
+// newVersionedDec creates a new versioned decoder using the validation mode of the protocol.
+func (p *Protocol) newVersionedDec(contents []byte) *versionedDec {
+	d := newVersionedDec(contents, p.typeInfos)
+	d.strict = p.strict
+	return d
+}
+
+// checkTrailing panics with a *ValidationError in strict mode
+// if there are unread bytes after the decoded value of the specified typeid.
+func (p *Protocol) checkTrailing(b *bitPackedBuff, typeid int) {
+	if !p.strict {
+		return
+	}
+	b.byteAlign()
+	if !b.EOF() {
+		panic(newValidationError(typeid, b, "%d trailing bytes", len(b.contents)-b.idx))
+	}
+}
+
+// checkTrailingPadding is like checkTrailing, but trailing zero bytes are allowed
+// (the MPQ user data holding the replay header is padded with zeros).
+func (p *Protocol) checkTrailingPadding(b *bitPackedBuff, typeid int) {
+	if !p.strict {
+		return
+	}
+	b.byteAlign()
+	for i, v := range b.contents[b.idx:] {
+		if v != 0 {
+			b.idx += i
+			panic(newValidationError(typeid, b, "%d trailing bytes", len(b.contents)-b.idx))
+		}
+	}
+}
@@ -10,9 +10,10 @@ package s2prot
 type versionedDec struct {
 	*bitPackedBuff            // Data source: bit-packed buffer
 	typeInfos      []typeInfo // Type descriptors
+	strict         bool       // Tells if strict validation mode is on
 }
 
-// newBitPackedDec creates a new bit-packed decoder.
+// newVersionedDec creates a new versioned decoder.
 func newVersionedDec(contents []byte, typeInfos []typeInfo) *versionedDec {
 	return &versionedDec{
 		bitPackedBuff: &bitPackedBuff{
@@ -48,6 +49,9 @@ func (d *versionedDec) instance(typeid int) interface{} {
 				}
 			}
 			if f == nil {
+				if d.strict {
+					panic(newValidationError(typeid, b, "unknown struct field tag %d", tag))
+				}
 				// We don't have info about the field, skip it
 				skipInstance(b)
 				continue
@@ -72,7 +76,12 @@ func (d *versionedDec) instance(typeid int) interface{} {
 	case s2pChoice:
 		b.readBits8() // Field type (3)
 		tag := int(readVarInt(b))
-		if tag > len(ti.fields) {
+		if tag < 0 || tag >= len(ti.fields) {
+			if d.strict {
+				panic(newValidationError(typeid, b, "unknown choice tag %d", tag))
+			}
+			// We don't have info about the value, skip it
+			skipInstance(b)
 			return nil
 		}
 		f := ti.fields[tag]
@@ -112,6 +121,30 @@ func (d *versionedDec) instance(typeid int) interface{} {
 	return nil
 }
 
+// choiceInt decodes a choice of ints (such as the SVarUint32 of gameloop deltas) specified by its type id,
+// and returns the chosen int value. It is an allocation-free fast path of instance():
+// the value is not wrapped into a Struct.
+// Unknown choice tags, non-int choices and unexpected field types are reported with a *ValidationError panic.
+func (d *versionedDec) choiceInt(typeid int) int64 {
+	b := d.bitPackedBuff // Local var for efficiency and more compact code
+
+	ti := &d.typeInfos[typeid] // Pointer to avoid copying the struct
+	if ft := b.readBits8(); ft != vfChoice {
+		panic(newValidationError(typeid, b, "unexpected field type %d, expected choice", ft))
+	}
+	tag := int(readVarInt(b))
+	if tag < 0 || tag >= len(ti.fields) {
+		panic(newValidationError(typeid, b, "unknown choice tag %d", tag))
+	}
+	if d.typeInfos[ti.fields[tag].typeid].s2pType != s2pInt {
+		panic(newValidationError(typeid, b, "not an int choice"))
+	}
+	if ft := b.readBits8(); ft != vfVarInt {
+		panic(newValidationError(typeid, b, "unexpected field type %d, expected int", ft))
+	}
+	return readVarInt(b)
+}
+
 // readVarInt reads a variable-length int value.
 // Format: read from input by 8 bits. Highest bit tells if have to read more bytes,
 // lowest bit of the firt byte (first 8 bits) is not data but tells if the number is negative.
@@ -0,0 +1,168 @@
+/*
+
+Implementation of the versioned encoder, the inverse of the versioned decoder.
+
+*/
+
+package s2prot
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Versioned encoder.
+type versionedEnc struct {
+	*bitPackedWriter            // Data target: bit-packed writer
+	typeInfos        []typeInfo // Type descriptors
+}
+
+// newVersionedEnc creates a new versioned encoder.
+func newVersionedEnc(typeInfos []typeInfo) *versionedEnc {
+	return &versionedEnc{bitPackedWriter: &bitPackedWriter{}, typeInfos: typeInfos}
+}
+
+// instance encodes a value specified by its type id.
+func (e *versionedEnc) instance(typeid int, v interface{}) error {
+	w := e.bitPackedWriter // Local var for more compact code
+
+	ti := &e.typeInfos[typeid] // Pointer to avoid copying the struct
+
+	switch ti.s2pType {
+	case s2pInt:
+		n, ok := toInt(v)
+		if !ok {
+			return typeMismatch(typeid, v)
+		}
+		w.writeBits(9, 8) // Field type
+		writeVarInt(w, n)
+		return nil
+	case s2pStruct:
+		if parent, ok := soleParent(e.typeInfos, ti); ok {
+			if _, isStruct := v.(Struct); !isStruct {
+				return e.instance(parent.typeid, v)
+			}
+		}
+		s := asStruct(v)
+		if s == nil {
+			return typeMismatch(typeid, v)
+		}
+		// Only fields present in the Struct are written (parent structs are always written):
+		var fields []*field
+		for i := range ti.fields {
+			f := &ti.fields[i]
+			if _, ok := s[f.name]; ok || (f.isNameParent && e.typeInfos[f.typeid].s2pType == s2pStruct) {
+				fields = append(fields, f)
+			}
+		}
+		// Fields are written in the order of their tags:
+		sort.Slice(fields, func(i, j int) bool { return fields[i].tag < fields[j].tag })
+		w.writeBits(5, 8) // Field type
+		writeVarInt(w, int64(len(fields)))
+		for _, f := range fields {
+			writeVarInt(w, int64(f.tag))
+			var err error
+			if f.isNameParent && e.typeInfos[f.typeid].s2pType == s2pStruct {
+				err = e.instance(f.typeid, s)
+			} else {
+				err = e.instance(f.typeid, s[f.name])
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	case s2pChoice:
+		tag, fv, err := choiceOf(ti, typeid, v)
+		if err != nil {
+			return err
+		}
+		w.writeBits(3, 8) // Field type
+		writeVarInt(w, int64(tag))
+		return e.instance(ti.fields[tag].typeid, fv)
+	case s2pArr:
+		arr, ok := v.([]interface{})
+		if !ok {
+			return typeMismatch(typeid, v)
+		}
+		w.writeBits(0, 8) // Field type
+		writeVarInt(w, int64(len(arr)))
+		for _, elem := range arr {
+			if err := e.instance(ti.typeid, elem); err != nil {
+				return err
+			}
+		}
+		return nil
+	case s2pBitArr:
+		ba, ok := v.(BitArr)
+		if !ok || len(ba.Data) < (ba.Count+7)/8 {
+			return typeMismatch(typeid, v)
+		}
+		w.writeBits(1, 8) // Field type
+		writeVarInt(w, int64(ba.Count))
+		w.writeAligned(ba.Data[:(ba.Count+7)/8])
+		return nil
+	case s2pBlob:
+		data, ok := toBytes(v)
+		if !ok {
+			return typeMismatch(typeid, v)
+		}
+		w.writeBits(2, 8) // Field type
+		writeVarInt(w, int64(len(data)))
+		w.writeAligned(data)
+		return nil
+	case s2pOptional:
+		w.writeBits(4, 8) // Field type
+		if v == nil {
+			w.writeBits(0, 8)
+			return nil
+		}
+		w.writeBits(1, 8)
+		return e.instance(ti.typeid, v)
+	case s2pBool:
+		b, ok := v.(bool)
+		if !ok {
+			return typeMismatch(typeid, v)
+		}
+		w.writeBits(6, 8) // Field type
+		if b {
+			w.writeBits(1, 8)
+		} else {
+			w.writeBits(0, 8)
+		}
+		return nil
+	case s2pFourCC:
+		data, ok := toBytes(v)
+		if !ok || len(data) != 4 {
+			return typeMismatch(typeid, v)
+		}
+		w.writeBits(7, 8) // Field type
+		w.writeAligned(data)
+		return nil
+	case s2pNull:
+		return nil
+	}
+
+	return fmt.Errorf("unknown type (typeid %d)", typeid)
+}
+
+// writeVarInt writes a variable-length int value, the inverse of readVarInt.
+func writeVarInt(w *bitPackedWriter, n int64) {
+	var value uint64
+	if n < 0 {
+		value = uint64(-n)<<1 | 1
+	} else {
+		value = uint64(n) << 1
+	}
+	for {
+		data := byte(value & 0x7f)
+		value >>= 7
+		if value != 0 {
+			data |= 0x80
+		}
+		w.writeBits(int64(data), 8)
+		if value == 0 {
+			return
+		}
+	}
+}
@@ -0,0 +1,58 @@
+/*
+
+Walking and flattening Structs.
+
+*/
+
+package s2prot
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Walk walks the Struct recursively, and calls fn for every leaf value
+// (values that are neither Structs nor arrays), in a deterministic order (keys are sorted).
+//
+// The path contains the keys leading to the value; array elements are denoted by their index.
+// The path slice is reused between calls, fn must not retain it (copy it if needed).
+func (s *Struct) Walk(fn func(path []string, value interface{})) {
+	walk(make([]string, 0, 8), *s, fn)
+}
+
+// walk walks the value recursively.
+func walk(path []string, v interface{}, fn func(path []string, value interface{})) {
+	if s := asStruct(v); s != nil {
+		keys := make([]string, 0, len(s))
+		for k := range s {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			walk(append(path, k), s[k], fn)
+		}
+		return
+	}
+
+	if arr, ok := v.([]interface{}); ok {
+		for i, e := range arr {
+			walk(append(path, strconv.Itoa(i)), e, fn)
+		}
+		return
+	}
+
+	fn(path, v)
+}
+
+// Flatten returns the leaf values of the Struct (see Walk) in a flat map,
+// keys are the paths of the values joined with dots, e.g. "playerList.0.toon.region".
+//
+// Keys of the returned map are valid Query paths.
+func (s *Struct) Flatten() map[string]interface{} {
+	m := make(map[string]interface{})
+	s.Walk(func(path []string, value interface{}) {
+		m[strings.Join(path, ".")] = value
+	})
+	return m
+}
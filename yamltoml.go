@@ -0,0 +1,291 @@
+/*
+
+YAML and TOML encoding of Structs and other decoded values.
+
+*/
+
+package s2prot
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+)
+
+// MarshalYAML returns the YAML encoding of v.
+//
+// v is first encoded canonically (see MarshalCanonical), so the YAML document has the same content
+// and key ordering (sorted keys) as the canonical JSON encoding. Strings are always double-quoted.
+func MarshalYAML(v interface{}) ([]byte, error) {
+	generic, err := canonicalGeneric(v)
+	if err != nil {
+		return nil, err
+	}
+	buf := &bytes.Buffer{}
+	switch x := generic.(type) {
+	case map[string]interface{}:
+		if len(x) == 0 {
+			buf.WriteString("{}\n")
+		} else {
+			writeYAMLMap(buf, x, "")
+		}
+	case []interface{}:
+		if len(x) == 0 {
+			buf.WriteString("[]\n")
+		} else {
+			writeYAMLArr(buf, x, "")
+		}
+	default:
+		writeYAMLScalar(buf, x)
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes(), nil
+}
+
+// MarshalTOML returns the TOML encoding of v, which must be encoded as a JSON object (e.g. a Struct).
+//
+// v is first encoded canonically (see MarshalCanonical), so the TOML document has the same content
+// and key ordering (sorted keys) as the canonical JSON encoding, except that in each table key-value pairs
+// precede sub-tables (as required by TOML). TOML has no null value: null values of tables are omitted,
+// null elements of arrays are encoded as empty inline tables.
+// Arrays whose elements are all tables are encoded as arrays of tables.
+func MarshalTOML(v interface{}) ([]byte, error) {
+	generic, err := canonicalGeneric(v)
+	if err != nil {
+		return nil, err
+	}
+	m, ok := generic.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("TOML document must be a table, got: %T", generic)
+	}
+	buf := &bytes.Buffer{}
+	writeTOMLTable(buf, m, "")
+	return buf.Bytes(), nil
+}
+
+// canonicalGeneric returns the generic representation of the canonical JSON encoding of v:
+// objects are map[string]interface{}, arrays are []interface{} and numbers are json.Number values.
+func canonicalGeneric(v interface{}) (interface{}, error) {
+	b, err := MarshalCanonical(v)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	var generic interface{}
+	if err := dec.Decode(&generic); err != nil {
+		return nil, err
+	}
+	return generic, nil
+}
+
+// sortedKeys returns the keys of the map, sorted.
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Keys that need no quoting in YAML and TOML.
+var (
+	yamlPlainKey = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_-]*$`)
+	tomlBareKey  = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+)
+
+// writeYAMLKey writes a YAML key followed by a colon, quoted if needed.
+func writeYAMLKey(buf *bytes.Buffer, k string) {
+	if yamlPlainKey.MatchString(k) && k != "true" && k != "false" && k != "null" {
+		buf.WriteString(k)
+	} else {
+		writeString(buf, k)
+	}
+	buf.WriteByte(':')
+}
+
+// writeYAMLScalar writes a YAML scalar (or an empty collection in flow style).
+func writeYAMLScalar(buf *bytes.Buffer, v interface{}) {
+	switch x := v.(type) {
+	case nil:
+		buf.WriteString("null")
+	case bool:
+		fmt.Fprint(buf, x)
+	case json.Number:
+		buf.WriteString(string(x))
+	case string:
+		writeString(buf, x) // JSON strings are valid YAML double-quoted scalars
+	case map[string]interface{}:
+		buf.WriteString("{}")
+	case []interface{}:
+		buf.WriteString("[]")
+	}
+}
+
+// isYAMLBlock tells if v is to be written in block style (a non-empty collection).
+func isYAMLBlock(v interface{}) bool {
+	switch x := v.(type) {
+	case map[string]interface{}:
+		return len(x) > 0
+	case []interface{}:
+		return len(x) > 0
+	}
+	return false
+}
+
+// writeYAMLMap writes a non-empty map in block style, each line prefixed with indent.
+// The first line is not prefixed if the map is an element of an array (the "- " is already written).
+func writeYAMLMap(buf *bytes.Buffer, m map[string]interface{}, indent string) {
+	for i, k := range sortedKeys(m) {
+		if i > 0 || !bytes.HasSuffix(buf.Bytes(), []byte("- ")) {
+			buf.WriteString(indent)
+		}
+		writeYAMLKey(buf, k)
+		writeYAMLValue(buf, m[k], indent)
+	}
+}
+
+// writeYAMLArr writes a non-empty array in block style, each line prefixed with indent.
+func writeYAMLArr(buf *bytes.Buffer, arr []interface{}, indent string) {
+	for _, e := range arr {
+		buf.WriteString(indent)
+		if x, ok := e.([]interface{}); ok && len(x) > 0 {
+			buf.WriteString("-\n")
+			writeYAMLArr(buf, x, indent+"  ")
+			continue
+		}
+		buf.WriteString("- ")
+		if x, ok := e.(map[string]interface{}); ok && len(x) > 0 {
+			writeYAMLMap(buf, x, indent+"  ")
+			continue
+		}
+		writeYAMLScalar(buf, e)
+		buf.WriteByte('\n')
+	}
+}
+
+// writeYAMLValue writes the value of a map entry (after the key) whose key is prefixed with indent.
+func writeYAMLValue(buf *bytes.Buffer, v interface{}, indent string) {
+	if !isYAMLBlock(v) {
+		buf.WriteByte(' ')
+		writeYAMLScalar(buf, v)
+		buf.WriteByte('\n')
+		return
+	}
+	buf.WriteByte('\n')
+	switch x := v.(type) {
+	case map[string]interface{}:
+		writeYAMLMap(buf, x, indent+"  ")
+	case []interface{}:
+		writeYAMLArr(buf, x, indent+"  ")
+	}
+}
+
+// writeTOMLKey writes a TOML key, quoted if needed.
+func writeTOMLKey(buf *bytes.Buffer, k string) {
+	if tomlBareKey.MatchString(k) {
+		buf.WriteString(k)
+	} else {
+		writeString(buf, k) // JSON strings are valid TOML basic strings
+	}
+}
+
+// tomlPath returns the dotted key path of a sub-table.
+func tomlPath(path, k string) string {
+	buf := &bytes.Buffer{}
+	if path != "" {
+		buf.WriteString(path)
+		buf.WriteByte('.')
+	}
+	writeTOMLKey(buf, k)
+	return buf.String()
+}
+
+// isTOMLTableArr tells if v is a non-empty array of tables.
+func isTOMLTableArr(v interface{}) bool {
+	arr, ok := v.([]interface{})
+	if !ok || len(arr) == 0 {
+		return false
+	}
+	for _, e := range arr {
+		if _, ok := e.(map[string]interface{}); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// writeTOMLTable writes the content of a table whose dotted key path is path:
+// key-value pairs first, then the sub-tables and the arrays of tables.
+func writeTOMLTable(buf *bytes.Buffer, m map[string]interface{}, path string) {
+	keys := sortedKeys(m)
+	for _, k := range keys {
+		v := m[k]
+		if _, ok := v.(map[string]interface{}); ok || v == nil || isTOMLTableArr(v) {
+			continue
+		}
+		writeTOMLKey(buf, k)
+		buf.WriteString(" = ")
+		writeTOMLValue(buf, v)
+		buf.WriteByte('\n')
+	}
+	for _, k := range keys {
+		switch x := m[k].(type) {
+		case map[string]interface{}:
+			p := tomlPath(path, k)
+			fmt.Fprintf(buf, "\n[%s]\n", p)
+			writeTOMLTable(buf, x, p)
+		case []interface{}:
+			if !isTOMLTableArr(x) {
+				continue
+			}
+			p := tomlPath(path, k)
+			for _, e := range x {
+				fmt.Fprintf(buf, "\n[[%s]]\n", p)
+				writeTOMLTable(buf, e.(map[string]interface{}), p)
+			}
+		}
+	}
+}
+
+// writeTOMLValue writes a TOML value in inline form.
+func writeTOMLValue(buf *bytes.Buffer, v interface{}) {
+	switch x := v.(type) {
+	case nil:
+		buf.WriteString("{}")
+	case bool:
+		fmt.Fprint(buf, x)
+	case json.Number:
+		buf.WriteString(string(x))
+	case string:
+		writeString(buf, x)
+	case []interface{}:
+		buf.WriteByte('[')
+		for i, e := range x {
+			if i > 0 {
+				buf.WriteString(", ")
+			}
+			writeTOMLValue(buf, e)
+		}
+		buf.WriteByte(']')
+	case map[string]interface{}:
+		buf.WriteByte('{')
+		i := 0
+		for _, k := range sortedKeys(x) {
+			if x[k] == nil {
+				continue
+			}
+			if i > 0 {
+				buf.WriteString(", ")
+			}
+			i++
+			writeTOMLKey(buf, k)
+			buf.WriteString(" = ")
+			writeTOMLValue(buf, x[k])
+		}
+		buf.WriteByte('}')
+	}
+}
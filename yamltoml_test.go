@@ -0,0 +1,106 @@
+package s2prot
+
+import (
+	"math"
+	"testing"
+)
+
+// yamlTOMLTestStruct is the Struct encoded in the YAML and TOML tests.
+var yamlTOMLTestStruct = Struct{
+	"z":      int64(-3),
+	"blob":   "\xff\x00",
+	"name":   "a: <b>\n",
+	"null":   nil,
+	"empty":  Struct{},
+	"arr":    []interface{}{1.5, nil, "x", []interface{}{true}, []interface{}{}},
+	"tables": []interface{}{Struct{"b": int64(1), "a": Struct{"c": false}}, Struct{}},
+	"m":      Struct{"k y": "v", "n": Struct{"x": int64(1)}, "nil": nil},
+}
+
+func TestMarshalYAML(t *testing.T) {
+	exp := `arr:
+  - 1.5
+  - null
+  - "x"
+  -
+    - true
+  - []
+blob: "0xff00"
+empty: {}
+m:
+  "k y": "v"
+  n:
+    x: 1
+  nil: null
+name: "a: <b>\n"
+"null": null
+tables:
+  - a:
+      c: false
+    b: 1
+  - {}
+z: -3
+`
+	got, err := MarshalYAML(yamlTOMLTestStruct)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if string(got) != exp {
+		t.Errorf("Expected:\n%s\ngot:\n%s", exp, got)
+	}
+
+	for _, c := range []struct {
+		v   interface{}
+		exp string
+	}{
+		{"s", "\"s\"\n"},
+		{nil, "null\n"},
+		{[]interface{}{}, "[]\n"},
+		{[]interface{}{int64(1), Struct{}}, "- 1\n- {}\n"},
+	} {
+		if got, err := MarshalYAML(c.v); err != nil || string(got) != c.exp {
+			t.Errorf("[v: %v] Expected: %q, got: %q (err: %v)", c.v, c.exp, got, err)
+		}
+	}
+
+	if _, err := MarshalYAML(math.NaN()); err == nil {
+		t.Error("Expected error for NaN")
+	}
+}
+
+func TestMarshalTOML(t *testing.T) {
+	exp := `arr = [1.5, {}, "x", [true], []]
+blob = "0xff00"
+name = "a: <b>\n"
+z = -3
+
+[empty]
+
+[m]
+"k y" = "v"
+
+[m.n]
+x = 1
+
+[[tables]]
+b = 1
+
+[tables.a]
+c = false
+
+[[tables]]
+`
+	got, err := MarshalTOML(yamlTOMLTestStruct)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if string(got) != exp {
+		t.Errorf("Expected:\n%s\ngot:\n%s", exp, got)
+	}
+
+	for _, v := range []interface{}{"s", nil, []interface{}{Struct{}}, math.Inf(1)} {
+		if _, err := MarshalTOML(v); err == nil {
+			t.Errorf("[v: %v] Expected error", v)
+		}
+	}
+}
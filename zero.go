@@ -0,0 +1,164 @@
+/*
+
+Zero values of the data structures of the protocol, to construct data to be encoded.
+
+*/
+
+package s2prot
+
+import "strings"
+
+// Top-level data structures of the protocol, to be used with Protocol.ZeroValue.
+const (
+	KindHeader   = "header"   // Replay header
+	KindDetails  = "details"  // Game details
+	KindInitData = "initData" // Replay init data
+)
+
+// ZeroValue returns the zero value of a top-level data structure of the protocol (or a part of it),
+// e.g. to construct data to be encoded. kind is one of KindHeader, KindDetails and KindInitData.
+//
+// The optional path selects a part of the data structure: field names separated by dots,
+// "#" denotes the element of an array, e.g. "syncLobbyState.lobbyState.slots.#".
+// Optional values are transparent in paths.
+//
+// Zero values are: 0 (or the closest valid value) for ints, arrays, bit arrays and blobs of min length,
+// nil for optional values, the first alternative for choices, false for bools and 4 zero bytes for FourCCs.
+// The returned value can be freely modified.
+//
+// false is returned if kind or path is invalid.
+func (p *Protocol) ZeroValue(kind, path string) (interface{}, bool) {
+	var typeid int
+	switch kind {
+	case KindHeader:
+		typeid = p.replayHeaderTypeid
+	case KindDetails:
+		typeid = p.gameDetailsTypeid
+	case KindInitData:
+		typeid = p.replayInitdataTypeid
+	default:
+		return nil, false
+	}
+	return p.zeroValueAt(typeid, path)
+}
+
+// ZeroEvt returns the zero value of the data structure of the specified event type (or a part of it).
+// The event type must be one obtained from this protocol. See ZeroValue for details.
+func (p *Protocol) ZeroEvt(e *EvtType, path string) (interface{}, bool) {
+	return p.zeroValueAt(e.typeid, path)
+}
+
+// zeroValueAt returns the zero value of the type at the path inside the specified type.
+func (p *Protocol) zeroValueAt(typeid int, path string) (interface{}, bool) {
+	if path != "" {
+		for _, part := range strings.Split(path, ".") {
+			var ok bool
+			if typeid, ok = p.elemTypeid(typeid, part); !ok {
+				return nil, false
+			}
+		}
+	}
+	return p.zeroInstance(typeid, 0), true
+}
+
+// elemTypeid returns the typeid of a path element inside the specified type.
+func (p *Protocol) elemTypeid(typeid int, part string) (int, bool) {
+	ti := &p.typeInfos[typeid]
+	for ti.s2pType == s2pOptional {
+		typeid = ti.typeid
+		ti = &p.typeInfos[typeid]
+	}
+
+	switch ti.s2pType {
+	case s2pArr:
+		if part == "#" {
+			return ti.typeid, true
+		}
+	case s2pStruct, s2pChoice:
+		for _, f := range ti.fields {
+			if f.name == part {
+				return f.typeid, true
+			}
+		}
+		// Fields of parent structs are inlined:
+		for _, f := range ti.fields {
+			if f.isNameParent && p.typeInfos[f.typeid].s2pType == s2pStruct {
+				if id, ok := p.elemTypeid(f.typeid, part); ok {
+					return id, true
+				}
+			}
+		}
+	}
+
+	return 0, false
+}
+
+// Max depth of zero instances; optional values are always nil, so this is just a safety net.
+const maxZeroDepth = 64
+
+// zeroInstance returns the zero value of a type, having the same form as decoded values.
+func (p *Protocol) zeroInstance(typeid, depth int) interface{} {
+	ti := &p.typeInfos[typeid]
+
+	// Helper function to return the int value closest to 0 in the range of the type
+	zeroInt := func() int64 {
+		switch {
+		case ti.offset64 > 0:
+			return ti.offset64
+		case ti.bits < 64 && ti.offset64+1<<uint(ti.bits) <= 0:
+			return ti.offset64 + 1<<uint(ti.bits) - 1
+		}
+		return 0
+	}
+
+	if depth > maxZeroDepth {
+		return nil
+	}
+
+	switch ti.s2pType {
+	case s2pInt:
+		return zeroInt()
+	case s2pStruct:
+		if parent, ok := soleParent(p.typeInfos, ti); ok {
+			return p.zeroInstance(parent.typeid, depth+1)
+		}
+		s := Struct{}
+		for _, f := range ti.fields {
+			v := p.zeroInstance(f.typeid, depth+1)
+			if f.isNameParent {
+				if s2, ok := v.(Struct); ok {
+					for k, v := range s2 {
+						s[k] = v
+					}
+					continue
+				}
+			}
+			s[f.name] = v
+		}
+		return s
+	case s2pChoice:
+		if len(ti.fields) == 0 {
+			return nil
+		}
+		f := ti.fields[0]
+		return Struct{f.name: p.zeroInstance(f.typeid, depth+1)}
+	case s2pArr:
+		arr := make([]interface{}, zeroInt())
+		for i := range arr {
+			arr[i] = p.zeroInstance(ti.typeid, depth+1)
+		}
+		return arr
+	case s2pBitArr:
+		count := int(zeroInt())
+		return BitArr{Count: count, Data: make([]byte, (count+7)/8)}
+	case s2pBlob:
+		return string(make([]byte, zeroInt()))
+	case s2pBool:
+		return false
+	case s2pFourCC:
+		return "\x00\x00\x00\x00"
+	}
+
+	// s2pOptional, s2pNull
+	return nil
+}
@@ -0,0 +1,80 @@
+package s2prot
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestZeroValue(t *testing.T) {
+	for _, bb := range encodeTestBuilds {
+		p := GetProtocol(bb)
+
+		for _, kind := range []string{KindHeader, KindDetails, KindInitData} {
+			v, ok := p.ZeroValue(kind, "")
+			if !ok {
+				t.Fatalf("[%d] Expected zero value for %s!", bb, kind)
+			}
+			s := v.(Struct)
+			var data []byte
+			var err error
+			var got Struct
+			switch kind {
+			case KindHeader:
+				if data, err = EncodeHeaderWith(p, s); err == nil {
+					got = DecodeHeaderWith(p, data)
+				}
+			case KindDetails:
+				if data, err = p.EncodeDetails(s); err == nil {
+					got = p.DecodeDetails(data)
+				}
+			case KindInitData:
+				if data, err = p.EncodeInitData(s); err == nil {
+					got = p.DecodeInitData(data)
+				}
+			}
+			if err != nil {
+				t.Errorf("[%d] Failed to encode zero %s: %v", bb, kind, err)
+			} else if !reflect.DeepEqual(got, s) {
+				t.Errorf("[%d] Zero %s mismatch, expected: %v, got: %v", bb, kind, s, got)
+			}
+		}
+
+		for _, etypes := range [][]EvtType{p.gameEvtTypes, p.messageEvtTypes, p.trackerEvtTypes} {
+			for i := range etypes {
+				if etypes[i].Name == "" {
+					continue
+				}
+				if _, ok := p.ZeroEvt(&etypes[i], ""); !ok {
+					t.Errorf("[%d] Expected zero value for event %s!", bb, etypes[i].Name)
+				}
+			}
+		}
+	}
+}
+
+func TestZeroValuePath(t *testing.T) {
+	p := GetProtocol(80949)
+
+	slot, ok := p.ZeroValue(KindInitData, "syncLobbyState.lobbyState.slots.#")
+	if !ok {
+		t.Fatal("Expected zero slot!")
+	}
+	if s, ok := slot.(Struct); !ok || s["toonHandle"] != "" || s["userId"] != nil {
+		t.Errorf("Unexpected zero slot: %v", slot)
+	}
+
+	if v, ok := p.ZeroValue(KindDetails, "playerList.#.toon.id"); !ok || v != int64(0) {
+		t.Errorf("Expected: %v, got: %v (%v)", int64(0), v, ok)
+	}
+
+	for _, c := range []struct{ kind, path string }{
+		{"unknown", ""},
+		{KindDetails, "noSuchField"},
+		{KindDetails, "playerList.0"},
+		{KindDetails, "title.#"},
+	} {
+		if _, ok := p.ZeroValue(c.kind, c.path); ok {
+			t.Errorf("[%s, %s] Expected invalid kind or path!", c.kind, c.path)
+		}
+	}
+}